@@ -70,6 +70,10 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/workload-list/short", getShortWorkloadList).Methods("GET")
 	r.HandleFunc("/workload-list/verbose", getVerboseWorkloadList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
+	r.HandleFunc("/forwarder/queue", listForwarderQueues).Methods("GET")
+	r.HandleFunc("/forwarder/queue/transactions", inspectForwarderQueue).Methods("GET")
+	r.HandleFunc("/forwarder/queue/purge", purgeForwarderQueue).Methods("POST")
+	r.HandleFunc("/forwarder/queue/replay", replayForwarderQueue).Methods("POST")
 
 	return r
 }
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func listForwarderQueues(w http.ResponseWriter, r *http.Request) {
+	if !checkForwarderQueues(w) {
+		return
+	}
+	writeForwarderQueueResponse(w, common.RetryQueues.ListRetryQueues(), nil)
+}
+
+func inspectForwarderQueue(w http.ResponseWriter, r *http.Request) {
+	if !checkForwarderQueues(w) {
+		return
+	}
+	transactions, err := common.RetryQueues.InspectRetryQueue(r.URL.Query().Get("domain"))
+	writeForwarderQueueResponse(w, transactions, err)
+}
+
+func purgeForwarderQueue(w http.ResponseWriter, r *http.Request) {
+	if !checkForwarderQueues(w) {
+		return
+	}
+
+	var olderThan time.Duration
+	if value := r.URL.Query().Get("older_than"); value != "" {
+		var err error
+		if olderThan, err = time.ParseDuration(value); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": "invalid older_than duration: " + err.Error()})
+			http.Error(w, string(body), 400)
+			return
+		}
+	}
+
+	log.Infof("Purging the forwarder retry queue (domain: %q, older than: %v)", r.URL.Query().Get("domain"), olderThan)
+	results, err := common.RetryQueues.PurgeRetryQueue(r.URL.Query().Get("domain"), olderThan)
+	writeForwarderQueueResponse(w, results, err)
+}
+
+func replayForwarderQueue(w http.ResponseWriter, r *http.Request) {
+	if !checkForwarderQueues(w) {
+		return
+	}
+
+	log.Infof("Replaying the forwarder retry queue (domain: %q)", r.URL.Query().Get("domain"))
+	results, err := common.RetryQueues.ReplayRetryQueue(r.URL.Query().Get("domain"))
+	writeForwarderQueueResponse(w, results, err)
+}
+
+// checkForwarderQueues writes an error and returns false when the forwarder
+// has no retry queue.
+func checkForwarderQueues(w http.ResponseWriter) bool {
+	if common.RetryQueues == nil {
+		log.Errorf("Trying to use the forwarder retry queues before the agent has been initialized.")
+		body, _ := json.Marshal(map[string]string{"error": "the forwarder retry queues are not available"})
+		http.Error(w, string(body), 503)
+		return false
+	}
+	return true
+}
+
+func writeForwarderQueueResponse(w http.ResponseWriter, response interface{}, err error) {
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal the forwarder retry queue response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonResponse)
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/util/input"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queueDomain    string
+	queueOlderThan time.Duration
+	queueForce     bool
+)
+
+func init() {
+	AgentCmd.AddCommand(forwarderCmd)
+	forwarderCmd.AddCommand(forwarderQueueCmd)
+	forwarderQueueCmd.AddCommand(forwarderQueueListCmd, forwarderQueueInspectCmd, forwarderQueuePurgeCmd, forwarderQueueReplayCmd)
+
+	forwarderQueueCmd.PersistentFlags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
+	forwarderQueueCmd.PersistentFlags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+	for _, cmd := range []*cobra.Command{forwarderQueueInspectCmd, forwarderQueuePurgeCmd, forwarderQueueReplayCmd} {
+		cmd.Flags().StringVarP(&queueDomain, "domain", "d", "", "only target the retry queue of this domain (default: every domain)")
+	}
+	forwarderQueuePurgeCmd.Flags().DurationVar(&queueOlderThan, "older-than", 0, "only remove the transactions older than this duration, for instance 1h (default: every transaction)")
+	forwarderQueuePurgeCmd.Flags().BoolVarP(&queueForce, "force", "f", false, "do not ask for a confirmation")
+}
+
+var forwarderCmd = &cobra.Command{
+	Use:   "forwarder",
+	Short: "Forwarder related commands",
+	Long:  ``,
+}
+
+var forwarderQueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Inspect and manage the transactions waiting in the forwarder retry queues of a running agent",
+	Long:  ``,
+}
+
+var forwarderQueueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the size and the age of the retry queue of every domain",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return requestForwarderQueue("GET", "", nil, func(r []byte) error {
+			var summaries []forwarder.RetryQueueSummary
+			if err := json.Unmarshal(r, &summaries); err != nil {
+				return err
+			}
+			printRetryQueueSummaries(color.Output, summaries)
+			return nil
+		})
+	},
+}
+
+var forwarderQueueInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Print the transactions stored in memory and on disk by the retry queues",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return requestForwarderQueue("GET", "/transactions", domainQuery(), func(r []byte) error {
+			var transactions []forwarder.RetryQueueTransaction
+			if err := json.Unmarshal(r, &transactions); err != nil {
+				return err
+			}
+			printRetryQueueTransactions(color.Output, transactions)
+			return nil
+		})
+	},
+}
+
+var forwarderQueuePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove transactions from the retry queues",
+	Long:  `Remove the transactions stored in memory and on disk by the retry queues. The payloads removed are never sent.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !queueForce && !input.AskForConfirmation("The transactions removed are lost, do you want to continue? [y/N]") {
+			fmt.Println("Canceling.")
+			return nil
+		}
+
+		query := domainQuery()
+		if queueOlderThan > 0 {
+			query.Set("older_than", queueOlderThan.String())
+		}
+		return requestForwarderQueue("POST", "/purge", query, func(r []byte) error {
+			var results []forwarder.RetryQueuePurgeResult
+			if err := json.Unmarshal(r, &results); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(color.Output, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DOMAIN\tTRANSACTIONS REMOVED\tFILES REMOVED\tERROR")
+			for _, result := range results {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", result.Domain, result.Transactions, result.Files, result.Error)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var forwarderQueueReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Retry the transactions of the retry queues now",
+	Long:  `Send the transactions of the retry queues to the forwarder workers now, even for the endpoints blocked after too many errors. The transactions that do not fit in the workers queue stay in the retry queue.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return requestForwarderQueue("POST", "/replay", domainQuery(), func(r []byte) error {
+			var results []forwarder.RetryQueueReplayResult
+			if err := json.Unmarshal(r, &results); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(color.Output, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DOMAIN\tTRANSACTIONS REPLAYED\tERROR")
+			for _, result := range results {
+				fmt.Fprintf(w, "%s\t%d\t%s\n", result.Domain, result.Transactions, result.Error)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+func domainQuery() url.Values {
+	query := url.Values{}
+	if queueDomain != "" {
+		query.Set("domain", queueDomain)
+	}
+	return query
+}
+
+// requestForwarderQueue sends a request to the forwarder queue endpoints of
+// the agent and prints the response as JSON or with `print`.
+func requestForwarderQueue(method string, path string, query url.Values, print func([]byte) error) error {
+	if err := setupConfig(); err != nil {
+		return err
+	}
+
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/forwarder/queue%s", ipcAddress, config.Datadog.GetInt("cmd_port"), path)
+	if len(query) > 0 {
+		urlstr += "?" + query.Encode()
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	var r []byte
+	if method == "POST" {
+		r, err = util.DoPost(c, urlstr, "application/json", bytes.NewReader(nil))
+	} else {
+		r, err = util.DoGet(c, urlstr, util.LeaveConnectionOpen)
+	}
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf(e)
+		}
+		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the forwarder retry queues and contact support if you continue having issues. \n", err)
+		return err
+	}
+
+	if prettyPrintJSON {
+		var prettyJSON bytes.Buffer
+		json.Indent(&prettyJSON, r, "", "  ") //nolint:errcheck
+		fmt.Println(prettyJSON.String())
+		return nil
+	}
+	if jsonStatus {
+		fmt.Println(string(r))
+		return nil
+	}
+	return print(r)
+}
+
+func printRetryQueueSummaries(out io.Writer, summaries []forwarder.RetryQueueSummary) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tIN MEMORY\tMEMORY SIZE\tOLDEST IN MEMORY\tON DISK FILES\tDISK SIZE\tOLDEST FILE")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d B\t%s\t%d\t%d B\t%s\n",
+			s.Domain, s.InMemoryTransactions, s.InMemorySizeInBytes, formatAge(s.OldestInMemory),
+			s.OnDiskFiles, s.OnDiskSizeInBytes, formatAge(s.OldestOnDisk))
+		if s.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", color.RedString(s.Error))
+		}
+	}
+	w.Flush()
+}
+
+func printRetryQueueTransactions(out io.Writer, transactions []forwarder.RetryQueueTransaction) {
+	if len(transactions) == 0 {
+		fmt.Fprintln(out, "The retry queues are empty.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tENDPOINT\tSTORAGE\tPAYLOAD SIZE\tPRIORITY\tERRORS\tCREATED AT")
+	for _, t := range transactions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d B\t%s\t%d\t%s (%s)\n",
+			t.Domain, t.Endpoint, t.Storage, t.PayloadSize, t.Priority, t.ErrorCount,
+			t.CreatedAt.Format(time.RFC3339), formatAge(&t.CreatedAt))
+	}
+	w.Flush()
+}
+
+func formatAge(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return time.Since(*t).Round(time.Second).String() + " ago"
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
+	"github.com/DataDog/datadog-agent/pkg/netflow"
 	"github.com/DataDog/datadog-agent/pkg/otlp"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
@@ -370,8 +371,11 @@ func StartAgent() error {
 	forwarderOpts.EnabledFeatures = forwarder.SetFeature(forwarderOpts.EnabledFeatures, forwarder.CoreFeatures)
 	opts := aggregator.DefaultDemultiplexerOptions(forwarderOpts)
 	opts.UseContainerLifecycleForwarder = config.Datadog.GetBool("container_lifecycle.enabled")
+	opts.UseOpenMetricsExposition = config.Datadog.GetBool("openmetrics_exposition.enabled")
+	opts.UsePrometheusRemoteWrite = config.Datadog.GetBool("prometheus_remote_write.enabled")
 	demux = aggregator.InitAndStartAgentDemultiplexer(opts, hostname)
 	demux.AddAgentStartupTelemetry(version.AgentVersion)
+	common.RetryQueues = demux.RetryQueueManager()
 
 	// start dogstatsd
 	if config.Datadog.GetBool("use_dogstatsd") {
@@ -405,7 +409,10 @@ func StartAgent() error {
 	// Start SNMP trap server
 	if traps.IsEnabled() {
 		if config.Datadog.GetBool("logs_enabled") {
-			err = traps.StartServer(hostname)
+			sender, err := demux.GetDefaultSender()
+			if err == nil {
+				err = traps.StartServer(hostname, sender)
+			}
 			if err != nil {
 				log.Errorf("Failed to start snmp-traps server: %s", err)
 			}
@@ -417,6 +424,17 @@ func StartAgent() error {
 		}
 	}
 
+	// Start NetFlow server
+	if netflow.IsEnabled() {
+		sender, err := demux.GetDefaultSender()
+		if err == nil {
+			err = netflow.StartServer(hostname, sender)
+		}
+		if err != nil {
+			log.Errorf("Failed to start NetFlow server: %s", err)
+		}
+	}
+
 	// start logs-agent
 	if config.Datadog.GetBool("logs_enabled") || config.Datadog.GetBool("log_enabled") {
 		if config.Datadog.GetBool("log_enabled") {
@@ -489,6 +507,7 @@ func StopAgent() {
 		common.MetadataScheduler.Stop()
 	}
 	traps.StopServer()
+	netflow.StopServer()
 	api.StopServer()
 	clcrunnerapi.StopCLCRunnerServer()
 	jmx.StopJmxfetch()
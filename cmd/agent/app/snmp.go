@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/app/standalone"
+	"github.com/DataDog/datadog-agent/cmd/agent/common/commands"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mib"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+var (
+	snmpWalkConfig     snmp.WalkConfig
+	snmpWalkOutputPath string
+
+	snmpSimulatePath    string
+	snmpProfile         string
+	snmpIPAddress       string
+	snmpNamespace       string
+	snmpCollectTopology bool
+	snmpCheckLogLevel   string
+	snmpProfileFiles    []string
+
+	snmpMibDirs        []string
+	snmpTrapsDBPath    string
+	snmpProfilesOutDir string
+)
+
+func init() {
+	AgentCmd.AddCommand(snmpCmd)
+	snmpCmd.AddCommand(snmpWalkCmd)
+	snmpCmd.AddCommand(snmpCheckCmd)
+	snmpCmd.AddCommand(snmpCompileMibsCmd)
+
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.SnmpVersion, "snmp-version", "v", "2c", "SNMP version: 1, 2c or 3")
+	snmpWalkCmd.Flags().Uint16VarP(&snmpWalkConfig.Port, "port", "P", 161, "SNMP port")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.CommunityString, "community-string", "C", "", "Community string, for SNMP v1 and v2c")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.User, "user", "u", "", "Security name, for SNMP v3")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.AuthProtocol, "auth-protocol", "a", "", "Authentication protocol, for SNMP v3: MD5, SHA, SHA224, SHA256, SHA384 or SHA512")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.AuthKey, "auth-key", "A", "", "Authentication key, for SNMP v3")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.PrivProtocol, "priv-protocol", "x", "", "Privacy protocol, for SNMP v3: DES, AES, AES192, AES192C, AES256 or AES256C")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.PrivKey, "priv-key", "X", "", "Privacy key, for SNMP v3")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkConfig.ContextName, "context", "n", "", "Context name, for SNMP v3")
+	snmpWalkCmd.Flags().IntVarP(&snmpWalkConfig.Timeout, "timeout", "t", 2, "Request timeout, in seconds")
+	snmpWalkCmd.Flags().IntVarP(&snmpWalkConfig.Retries, "retries", "r", 3, "Number of retries of a request")
+	snmpWalkCmd.Flags().Uint32VarP(&snmpWalkConfig.BulkMaxRepetitions, "bulk-max-repetitions", "m", 10, "Number of values retrieved by each GetBulk request")
+	snmpWalkCmd.Flags().BoolVar(&snmpWalkConfig.UseGetNext, "use-getnext", false, "Use GetNext requests instead of GetBulk requests")
+	snmpWalkCmd.Flags().StringVarP(&snmpWalkOutputPath, "output", "o", "", "Write the recording to this file instead of stdout")
+
+	snmpCheckCmd.Flags().StringVar(&snmpSimulatePath, "simulate", "", "Replay this snmprec recording, e.g. recorded with the walk command, instead of querying a device")
+	snmpCheckCmd.Flags().StringVar(&snmpProfile, "profile", "", "Profile to use, autodetected using the sysObjectID of the recording if not set")
+	snmpCheckCmd.Flags().StringSliceVar(&snmpProfileFiles, "profile-file", nil, "Load the profile from this definition file instead of the default profiles, as <name>:<path>")
+	snmpCheckCmd.Flags().StringVar(&snmpIPAddress, "ip-address", "127.0.0.1", "IP address of the simulated device, used for the device ID")
+	snmpCheckCmd.Flags().StringVar(&snmpNamespace, "namespace", "", "Namespace of the simulated device")
+	snmpCheckCmd.Flags().BoolVar(&snmpCollectTopology, "collect-topology", true, "Collect the LLDP and CDP topology links")
+	snmpCheckCmd.Flags().StringVarP(&snmpCheckLogLevel, "log-level", "l", "", "Set the log level (default 'off') (deprecated, use the env var DD_LOG_LEVEL instead)")
+	snmpCheckCmd.MarkFlagRequired("simulate") //nolint:errcheck
+
+	snmpCompileMibsCmd.Flags().StringSliceVarP(&snmpMibDirs, "mib-dir", "M", nil, "Look for the imported MIBs in this directory, the directories of the compiled MIBs are always searched")
+	snmpCompileMibsCmd.Flags().StringVar(&snmpTrapsDBPath, "traps-db", "", "Write the trap db entries to this file, as JSON if its extension is .json and as YAML otherwise")
+	snmpCompileMibsCmd.Flags().StringVar(&snmpProfilesOutDir, "profiles-dir", "", "Write a profile skeleton for each compiled MIB to this directory")
+}
+
+var snmpCmd = &cobra.Command{
+	Use:   "snmp",
+	Short: "SNMP tools",
+	Long:  ``,
+}
+
+var snmpWalkCmd = &cobra.Command{
+	Use:   "walk <ip_address> [<oid>]",
+	Short: "Record the OID tree of a device",
+	Long: `Walk the OID tree of a device, or the tree under <oid>, and record the values in the snmprec format.
+The recording can be replayed with the 'snmp check --simulate' command.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := standalone.SetupCLI(loggerName, confFilePath, "", "", "", "off"); err != nil {
+			fmt.Printf("Cannot initialize command: %v\n", err)
+			return err
+		}
+
+		snmpWalkConfig.IPAddress = args[0]
+		var rootOid string
+		if len(args) > 1 {
+			rootOid = args[1]
+		}
+
+		var w io.Writer = os.Stdout
+		if snmpWalkOutputPath != "" {
+			f, err := os.Create(snmpWalkOutputPath)
+			if err != nil {
+				return fmt.Errorf("unable to create the output file: %v", err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		count, err := snmp.Walk(snmpWalkConfig, rootOid, w)
+		if err != nil {
+			return fmt.Errorf("walk failed after %d values: %v", count, err)
+		}
+		if snmpWalkOutputPath != "" {
+			fmt.Printf("%d values written to %s\n", count, snmpWalkOutputPath)
+		}
+		return nil
+	},
+}
+
+var snmpCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Show the metrics, tags and metadata produced by a profile for a recorded device",
+	Long: `Run the SNMP check against a snmprec recording instead of a device, and print the metrics, service checks
+and device metadata it produces. This is used to validate a profile without the device.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := standalone.SetupCLI(loggerName, confFilePath, "", "", snmpCheckLogLevel, "off"); err != nil {
+			fmt.Printf("Cannot initialize command: %v\n", err)
+			return err
+		}
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		return runSimulatedSnmpCheck()
+	},
+}
+
+var snmpCompileMibsCmd = &cobra.Command{
+	Use:   "compile-mibs <mib_file>...",
+	Short: "Generate trap db entries and profile skeletons from MIB files",
+	Long: `Parse SMIv1 or SMIv2 MIB files, and the MIBs they import, to generate:
+- the trap db entries of their traps: trap OIDs, variable names and enum mappings. The file can be dropped
+  into the snmp.d/traps_db directory to resolve the traps received by the traps listener.
+- a profile skeleton for each MIB, reporting its numeric scalar and table objects. The profiles can be dropped
+  into the snmp.d/profiles directory once the sysobjectid of the devices they apply to is added.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := standalone.SetupCLI(loggerName, confFilePath, "", "", "", "off"); err != nil {
+			fmt.Printf("Cannot initialize command: %v\n", err)
+			return err
+		}
+		if snmpTrapsDBPath == "" && snmpProfilesOutDir == "" {
+			return fmt.Errorf("at least one of --traps-db or --profiles-dir is required")
+		}
+		return compileMibs(args)
+	},
+}
+
+func compileMibs(mibFiles []string) error {
+	mibDirs := append([]string(nil), snmpMibDirs...)
+	for _, mibFile := range mibFiles {
+		mibDirs = append(mibDirs, filepath.Dir(mibFile))
+	}
+	compiler := mib.NewCompiler(mibDirs)
+
+	var modules []*mib.Module
+	for _, mibFile := range mibFiles {
+		fileModules, err := compiler.LoadFile(mibFile)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, fileModules...)
+	}
+
+	if snmpTrapsDBPath != "" {
+		trapDB, err := compiler.TrapDB(modules)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		if strings.HasSuffix(snmpTrapsDBPath, ".json") {
+			data, err = json.Marshal(trapDB)
+		} else {
+			data, err = yaml.Marshal(trapDB)
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(snmpTrapsDBPath, data, 0644); err != nil {
+			return fmt.Errorf("unable to write the trap db: %v", err)
+		}
+		fmt.Printf("%d traps written to %s\n", len(trapDB.Traps), snmpTrapsDBPath)
+	}
+
+	if snmpProfilesOutDir != "" {
+		if err := os.MkdirAll(snmpProfilesOutDir, 0755); err != nil {
+			return fmt.Errorf("unable to create the profiles directory: %v", err)
+		}
+		for _, module := range modules {
+			profile, err := compiler.Profile(module)
+			if err != nil {
+				return fmt.Errorf("unable to generate the profile of %s: %v", module.Name, err)
+			}
+			if len(profile.Metrics) == 0 {
+				fmt.Printf("%s has no numeric objects, no profile written\n", module.Name)
+				continue
+			}
+			data, err := yaml.Marshal(profile)
+			if err != nil {
+				return err
+			}
+			header := fmt.Sprintf("# Profile skeleton generated from %s\n# Add the sysobjectid of the devices it applies to, and review the metrics and tags.\n#\n", module.Name)
+			path := filepath.Join(snmpProfilesOutDir, strings.ToLower(module.Name)+".yaml")
+			if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+				return fmt.Errorf("unable to write the profile: %v", err)
+			}
+			fmt.Printf("Profile of %s written to %s\n", module.Name, path)
+		}
+	}
+	return nil
+}
+
+func runSimulatedSnmpCheck() error {
+	instance := map[string]interface{}{
+		"ip_address":              snmpIPAddress,
+		"collect_device_metadata": true,
+		"collect_topology":        snmpCollectTopology,
+	}
+	if snmpProfile != "" {
+		instance["profile"] = snmpProfile
+	}
+	if snmpNamespace != "" {
+		instance["namespace"] = snmpNamespace
+	}
+	initConfig := map[string]interface{}{}
+	if len(snmpProfileFiles) > 0 {
+		profiles := map[string]interface{}{}
+		for _, profile := range snmpProfileFiles {
+			name, path, err := splitProfileFlag(profile)
+			if err != nil {
+				return err
+			}
+			profiles[name] = map[string]string{"definition_file": path}
+		}
+		initConfig["profiles"] = profiles
+	}
+	rawInstance, err := yaml.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	rawInitConfig, err := yaml.Marshal(initConfig)
+	if err != nil {
+		return err
+	}
+
+	hostname, err := util.GetHostname(context.TODO())
+	if err != nil {
+		fmt.Printf("Cannot get hostname, exiting: %v\n", err)
+		return err
+	}
+
+	// Initializing the aggregator with a flush interval of 0 (to disable the flush goroutines)
+	opts := aggregator.DefaultDemultiplexerOptions(nil)
+	opts.FlushInterval = 0
+	opts.UseNoopForwarder = true
+	opts.UseNoopEventPlatformForwarder = true
+	opts.UseOrchestratorForwarder = false
+	demux := aggregator.InitAndStartAgentDemultiplexer(opts, hostname)
+
+	c, err := snmp.NewSimulatedCheck(snmpSimulatePath)
+	if err != nil {
+		return err
+	}
+	if err := c.Configure(rawInstance, rawInitConfig, "simulate"); err != nil {
+		return fmt.Errorf("unable to configure the check: %v", err)
+	}
+
+	checkErr := c.Run()
+	commands.PrintMetrics(demux)
+	if checkErr != nil {
+		color.Red("Error: %s", checkErr)
+	}
+	return nil
+}
+
+// splitProfileFlag splits a `<name>:<path>` profile flag, the path might contain `:` on Windows
+func splitProfileFlag(flag string) (string, string, error) {
+	i := strings.Index(flag, ":")
+	if i <= 0 || i == len(flag)-1 {
+		return "", "", fmt.Errorf("invalid profile file `%s`, expected <name>:<path>", flag)
+	}
+	return flag[:i], flag[i+1:], nil
+}
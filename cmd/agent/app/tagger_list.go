@@ -87,24 +87,18 @@ var taggerListCommand = &cobra.Command{
 
 			for _, source := range sources {
 				fmt.Fprintln(color.Output, fmt.Sprintf("== Source %s ==", source))
+				printTags(tagItem.Tags[source])
+			}
 
-				fmt.Fprint(color.Output, "Tags: [")
-
-				// sort tags for easy comparison
-				tags := tagItem.Tags[source]
-				sort.Slice(tags, func(i, j int) bool {
-					return tags[i] < tags[j]
-				})
-
-				for i, tag := range tags {
-					tagInfo := strings.Split(tag, ":")
-					fmt.Fprintf(color.Output, fmt.Sprintf("%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":"))))
-					if i != len(tags)-1 {
-						fmt.Fprintf(color.Output, " ")
-					}
-				}
+			rules := make([]string, 0, len(tagItem.Rules))
+			for rule := range tagItem.Rules {
+				rules = append(rules, rule)
+			}
+			sort.Strings(rules)
 
-				fmt.Fprintln(color.Output, "]")
+			for _, rule := range rules {
+				fmt.Fprintln(color.Output, fmt.Sprintf("== Rule %s ==", rule))
+				printTags(tagItem.Rules[rule])
 			}
 
 			fmt.Fprintln(color.Output, "===")
@@ -113,3 +107,22 @@ var taggerListCommand = &cobra.Command{
 		return nil
 	},
 }
+
+func printTags(tags []string) {
+	fmt.Fprint(color.Output, "Tags: [")
+
+	// sort tags for easy comparison
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i] < tags[j]
+	})
+
+	for i, tag := range tags {
+		tagInfo := strings.Split(tag, ":")
+		fmt.Fprintf(color.Output, fmt.Sprintf("%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":"))))
+		if i != len(tags)-1 {
+			fmt.Fprintf(color.Output, " ")
+		}
+	}
+
+	fmt.Fprintln(color.Output, "]")
+}
@@ -467,6 +467,13 @@ func runCheck(c check.Check, demux aggregator.Demultiplexer) *check.Stats {
 	return s
 }
 
+// PrintMetrics prints the series, sketches, service checks, events and event platform events submitted to the
+// aggregator, like the check command does
+func PrintMetrics(demux aggregator.Demultiplexer) {
+	var output bytes.Buffer
+	printMetrics(demux, &output)
+}
+
 func printMetrics(demux aggregator.Demultiplexer, checkFileOutput *bytes.Buffer) {
 	agg := demux.Aggregator()
 	series, sketches := agg.GetSeriesAndSketches(time.Now())
@@ -11,6 +11,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -66,63 +67,25 @@ func CheckCmd(confPathArrayGetter func() []string) *cobra.Command {
 }
 
 func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
-	err := configureLogger()
+	err := configureLogger(os.Stdout, checkArgs.verbose)
 	if err != nil {
 		return err
 	}
 
-	// We need to set before calling `SetupConfig`
-	configName := "datadog"
-	if flavor.GetFlavor() == flavor.ClusterAgent {
-		configName = "datadog-cluster"
-	}
-
-	// Read configuration files received from the command line arguments '-c'
-	if err := common.MergeConfigurationFiles(configName, confPathArray, cmd.Flags().Lookup("cfgpath").Changed); err != nil {
+	if err := mergeCheckConfiguration(cmd, confPathArray); err != nil {
 		return err
 	}
 
-	options := []checks.BuilderOption{}
-
-	if flavor.GetFlavor() == flavor.ClusterAgent {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		log.Info("Waiting for APIClient")
-		apiCl, err := apiserver.WaitForAPIClient(ctx)
-		if err != nil {
-			return err
-		}
-		options = append(options, checks.MayFail(checks.WithKubernetesClient(apiCl.DynamicCl, "")))
-	} else {
-		options = append(options, []checks.BuilderOption{
-			checks.WithHostRootMount(os.Getenv("HOST_ROOT")),
-			checks.MayFail(checks.WithDocker()),
-			checks.MayFail(checks.WithAudit()),
-		}...)
-
-		if config.IsKubernetes() {
-			nodeLabels, err := agent.WaitGetNodeLabels()
-			if err != nil {
-				log.Error(err)
-			} else {
-				options = append(options, checks.WithNodeLabels(nodeLabels))
-			}
-		}
-	}
-
 	var ruleID string
 	if len(args) != 0 {
 		ruleID = args[0]
 	}
 
-	hostname, err := util.GetHostname(context.TODO())
+	options, _, err := hostBuilderOptions()
 	if err != nil {
 		return err
 	}
 
-	options = append(options, checks.WithHostname(hostname))
-
 	stopper = startstop.NewSerialStopper()
 	defer stopper.Stop()
 
@@ -170,17 +133,68 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 	return nil
 }
 
-func configureLogger() error {
+// mergeCheckConfiguration reads the configuration files received from the command line arguments '-c'
+func mergeCheckConfiguration(cmd *cobra.Command, confPathArray []string) error {
+	// We need to set before calling `SetupConfig`
+	configName := "datadog"
+	if flavor.GetFlavor() == flavor.ClusterAgent {
+		configName = "datadog-cluster"
+	}
+
+	return common.MergeConfigurationFiles(configName, confPathArray, cmd.Flags().Lookup("cfgpath").Changed)
+}
+
+// hostBuilderOptions returns the options of the checks builder describing the host the checks
+// run on, along with its hostname
+func hostBuilderOptions() ([]checks.BuilderOption, string, error) {
+	options := []checks.BuilderOption{}
+
+	if flavor.GetFlavor() == flavor.ClusterAgent {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		log.Info("Waiting for APIClient")
+		apiCl, err := apiserver.WaitForAPIClient(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		options = append(options, checks.MayFail(checks.WithKubernetesClient(apiCl.DynamicCl, "")))
+	} else {
+		options = append(options, []checks.BuilderOption{
+			checks.WithHostRootMount(os.Getenv("HOST_ROOT")),
+			checks.MayFail(checks.WithDocker()),
+			checks.MayFail(checks.WithAudit()),
+		}...)
+
+		if config.IsKubernetes() {
+			nodeLabels, err := agent.WaitGetNodeLabels()
+			if err != nil {
+				log.Error(err)
+			} else {
+				options = append(options, checks.WithNodeLabels(nodeLabels))
+			}
+		}
+	}
+
+	hostname, err := util.GetHostname(context.TODO())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append(options, checks.WithHostname(hostname)), hostname, nil
+}
+
+func configureLogger(w io.Writer, verbose bool) error {
 	var (
 		logFormat = "%LEVEL | %Msg%n"
 		logLevel  = "info"
 	)
-	if checkArgs.verbose {
+	if verbose {
 		const logDateFormat = "2006-01-02 15:04:05 MST"
 		logFormat = fmt.Sprintf("%%Date(%s) | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat)
 		logLevel = "trace"
 	}
-	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(os.Stdout, seelog.DebugLvl, logFormat)
+	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(w, seelog.DebugLvl, logFormat)
 	if err != nil {
 		return err
 	}
@@ -117,6 +117,11 @@ func startCompliance(hostname string, stopper startstop.Stopper, statsdClient *d
 		return nil, err
 	}
 
+	reporter, err = wrapSinksReporter(stopper, reporter, hostname, "compliance-agent", "compliance_config.sinks")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the compliance local sinks: %w", err)
+	}
+
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
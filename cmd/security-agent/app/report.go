@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows && kubeapiserver
+// +build !windows,kubeapiserver
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/benchmark"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	reportArgs = struct {
+		framework string
+		files     []string
+		formats   []string
+		output    string
+		minScore  float64
+		verbose   bool
+	}{}
+)
+
+func setupReportCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&reportArgs.framework, "framework", "", "", "Framework to run the checks from")
+	cmd.Flags().StringSliceVarP(&reportArgs.files, "file", "f", []string{}, "Compliance suite files to read rules from, defaults to the suites of the compliance configuration directory")
+	cmd.Flags().StringSliceVarP(&reportArgs.formats, "format", "", []string{string(benchmark.JSONFormat)}, "Report formats: json, sarif or junit")
+	cmd.Flags().StringVarP(&reportArgs.output, "output", "o", "compliance-report", "Path of the reports, without extension, or - to write a single report to the standard output")
+	cmd.Flags().Float64VarP(&reportArgs.minScore, "min-score", "", 0, "Exit with an error when the score, the percentage of evaluated rules that passed, is lower")
+	cmd.Flags().BoolVarP(&reportArgs.verbose, "verbose", "v", false, "Include verbose details")
+}
+
+// ReportCmd returns a cobra command running compliance suites and writing reports of the results
+func ReportCmd(confPathArrayGetter func() []string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [rule-id]",
+		Short: "Run compliance suites and write JSON, SARIF or JUnit reports",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, confPathArrayGetter(), args)
+		},
+	}
+	setupReportCmd(cmd)
+	return cmd
+}
+
+func runReport(cmd *cobra.Command, confPathArray []string, args []string) error {
+	formats := make([]benchmark.Format, 0, len(reportArgs.formats))
+	for _, name := range reportArgs.formats {
+		format, err := benchmark.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+		formats = append(formats, format)
+	}
+
+	if reportArgs.output == "-" && len(formats) != 1 {
+		return fmt.Errorf("a single report format is expected when writing to the standard output")
+	}
+
+	// the logs go to the standard error, for the standard output to hold the report
+	if err := configureLogger(os.Stderr, reportArgs.verbose); err != nil {
+		return err
+	}
+
+	if err := mergeCheckConfiguration(cmd, confPathArray); err != nil {
+		return err
+	}
+
+	options, hostname, err := hostBuilderOptions()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		log.Infof("Looking for rule with ID=%s", args[0])
+		options = append(options, checks.WithMatchRule(checks.IsRuleID(args[0])))
+	}
+
+	if reportArgs.framework != "" {
+		log.Infof("Looking for rules with framework=%s", reportArgs.framework)
+		options = append(options, checks.WithMatchSuite(checks.IsFramework(reportArgs.framework)))
+	}
+
+	files := reportArgs.files
+	if len(files) == 0 {
+		configDir := config.Datadog.GetString("compliance_config.dir")
+		if files, err = filepath.Glob(filepath.Join(configDir, "*.yaml")); err != nil {
+			return err
+		}
+	}
+
+	collector := benchmark.NewCollector(nil)
+	builder, err := checks.NewBuilder(collector, options...)
+	if err != nil {
+		return err
+	}
+	defer builder.Close()
+
+	for _, file := range files {
+		log.Infof("Loading compliance rules from %s", file)
+		if err := collector.RunFile(builder, file); err != nil {
+			return fmt.Errorf("failed to run checks from %s: %w", file, err)
+		}
+	}
+
+	report := collector.Build(hostname)
+	for _, format := range formats {
+		if err := writeReport(report, format, reportArgs.output); err != nil {
+			return err
+		}
+	}
+
+	summary := report.Summary
+	log.Infof("%d rules: %d passed, %d failed, %d errors, %d skipped, score %.2f%%", summary.Total, summary.Passed, summary.Failed, summary.Errors, summary.Skipped, summary.Score)
+
+	if summary.Score < reportArgs.minScore {
+		return fmt.Errorf("compliance score %.2f%% is lower than %.2f%%", summary.Score, reportArgs.minScore)
+	}
+	return nil
+}
+
+func writeReport(report *benchmark.Report, format benchmark.Format, output string) error {
+	if output == "-" {
+		return benchmark.Write(os.Stdout, format, report)
+	}
+
+	path := output + format.Extension()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := benchmark.Write(f, format, report); err != nil {
+		return fmt.Errorf("failed to write %s report to %s: %w", format, path, err)
+	}
+
+	log.Infof("Wrote %s report to %s", format, path)
+	return f.Close()
+}
+
+func init() {
+	complianceCmd.AddCommand(ReportCmd(func() []string {
+		return confPathArray
+	}))
+}
@@ -15,6 +15,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/api"
 	secconfig "github.com/DataDog/datadog-agent/pkg/security/config"
 	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/policytest"
 	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
@@ -172,6 +174,23 @@ var (
 		RunE:  checkPolicies,
 	}
 
+	testPoliciesCmd = &cobra.Command{
+		Use:   "test <fixtures file>...",
+		Short: "Evaluate the policies against event fixtures",
+		Long: `Evaluate the rules of the policies against the events described by JSON or YAML fixture files, without a running probe.
+The command fails if a rule expected to match an event does not, or if a rule expected not to match an event does.`,
+		Args: cobra.MinimumNArgs(1),
+		// the policies are evaluated offline, in a CI for instance, the
+		// configuration of the agent is not required.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              testPolicies,
+	}
+
+	testPoliciesArgs = struct {
+		dir  string
+		json bool
+	}{}
+
 	downloadPolicyCmd = &cobra.Command{
 		Use:   "download",
 		Short: "Download policies",
@@ -291,6 +310,10 @@ func init() {
 	commonCheckPoliciesCmd.Flags().StringVar(&checkPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
 	commonPolicyCmd.AddCommand(commonCheckPoliciesCmd)
 
+	testPoliciesCmd.Flags().StringVar(&testPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testPoliciesCmd.Flags().BoolVar(&testPoliciesArgs.json, "json", false, "Print the report in JSON")
+	commonPolicyCmd.AddCommand(testPoliciesCmd)
+
 	commonPolicyCmd.AddCommand(commonReloadPoliciesCmd)
 	runtimeCmd.AddCommand(commonPolicyCmd)
 
@@ -575,6 +598,69 @@ func checkPolicies(cmd *cobra.Command, args []string) error {
 	return checkPoliciesInner(checkPoliciesArgs.dir)
 }
 
+func testPolicies(cmd *cobra.Command, args []string) error {
+	harness, err := policytest.NewHarness(testPoliciesArgs.dir)
+	if err != nil {
+		return err
+	}
+
+	var fixtures []policytest.Fixture
+	for _, filename := range args {
+		fileFixtures, err := policytest.LoadFixtures(filename)
+		if err != nil {
+			return err
+		}
+		fixtures = append(fixtures, fileFixtures...)
+	}
+
+	report, err := harness.Run(fixtures)
+	if err != nil {
+		return err
+	}
+
+	if testPoliciesArgs.json {
+		content, _ := json.MarshalIndent(report, "", "\t")
+		fmt.Printf("%s\n", string(content))
+	} else {
+		printPolicyTestReport(os.Stdout, report)
+	}
+
+	if failures := report.Failures(); failures > 0 {
+		return fmt.Errorf("%d expectation(s) failed", failures)
+	}
+	return nil
+}
+
+func printPolicyTestReport(w io.Writer, report *policytest.Report) {
+	for _, result := range report.Results {
+		status := "PASS"
+		if len(result.Failures) > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s %s (%s)\n", status, result.Fixture, result.EventType)
+
+		for _, failure := range result.Failures {
+			fmt.Fprintf(w, "    %s\n", failure)
+		}
+		for _, rule := range result.Rules {
+			matched := "no match"
+			if rule.Matched {
+				matched = "match"
+			}
+			fmt.Fprintf(w, "    %s: %s\n", rule.ID, matched)
+
+			fields := make([]string, 0, len(rule.Fields))
+			for field := range rule.Fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				fmt.Fprintf(w, "        %s = %v\n", field, rule.Fields[field])
+			}
+		}
+	}
+}
+
 func runRuntimeSelfTest(cmd *cobra.Command, args []string) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -657,6 +743,11 @@ func startRuntimeSecurity(hostname string, stopper startstop.Stopper, statsdClie
 		return nil, err
 	}
 
+	reporter, err = wrapSinksReporter(stopper, reporter, hostname, "runtime-security-agent", "runtime_security_config.sinks")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the runtime security local sinks")
+	}
+
 	agent, err := secagent.NewRuntimeSecurityAgent(hostname, reporter, endpoints)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create a runtime security agent instance")
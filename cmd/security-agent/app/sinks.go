@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event/sinks"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+// wrapSinksReporter returns a reporter forwarding the events to the local sinks configured under
+// the given key, in addition to the given reporter
+func wrapSinksReporter(stopper startstop.Stopper, reporter event.Reporter, hostname, appName, configKey string) (event.Reporter, error) {
+	configs, err := sinks.LoadConfigs(configKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configs) == 0 {
+		return reporter, nil
+	}
+
+	sinksReporter, err := sinks.NewReporter(reporter, hostname, appName, configs)
+	if err != nil {
+		return nil, err
+	}
+	stopper.Add(sinksReporter)
+
+	return sinksReporter, nil
+}
@@ -144,6 +144,7 @@ func init() {
 	newFlushTimeStats("ServiceCheckFlushTime")
 	newFlushTimeStats("EventFlushTime")
 	newFlushTimeStats("MainFlushTime")
+	newFlushTimeStats("StaggeredFlushTime")
 	newFlushTimeStats("MetricSketchFlushTime")
 	aggregatorExpvars.Set("Flush", expvar.Func(expStatsMap(flushTimeStats)))
 
@@ -14,6 +14,7 @@ import (
 	"expvar"
 	"fmt"
 	"sort"
+	"strings"
 
 	"sync/atomic"
 	"testing"
@@ -571,6 +572,55 @@ func TestTimeSamplerFlush(t *testing.T) {
 	}
 }
 
+func TestDemuxStaggeredFlushBatching(t *testing.T) {
+	require := require.New(t)
+	defer config.Datadog.Set("dogstatsd_flush_shards", nil)
+	config.Datadog.Set("dogstatsd_flush_shards", 3)
+
+	opts := demuxTestOptions()
+	opts.UseNoopForwarder = true
+	demux := initAgentDemultiplexer(opts, "")
+	demux.Aggregator().tlmContainerTagsEnabled = false
+	s := &MockSerializerIterableSerie{}
+	demux.sharedSerializer = s
+	demux.statsd.batchPoints = 10
+
+	go demux.Run()
+	defer demux.Stop(false)
+
+	shard := func(count int) flushedShard {
+		var series metrics.Series
+		for i := 0; i < count; i++ {
+			series = append(series, &metrics.Serie{
+				Name:   fmt.Sprintf("my.dogstatsd.gauge.%d", i),
+				Points: []metrics.Point{{Ts: 12340, Value: 1}},
+			})
+		}
+		return flushedShard{start: time.Now(), series: series}
+	}
+	dogstatsdSeries := func() int {
+		count := 0
+		for _, serie := range s.series {
+			if strings.HasPrefix(serie.Name, "my.dogstatsd.") {
+				count++
+			}
+		}
+		return count
+	}
+
+	// the shards are sent once they fill a payload
+	demux.sendFlushedShard(shard(4))
+	require.Equal(0, dogstatsdSeries())
+	demux.sendFlushedShard(shard(8))
+	require.Equal(12, dogstatsdSeries())
+
+	// the remaining shards are sent on the next flush
+	demux.sendFlushedShard(shard(3))
+	require.Equal(12, dogstatsdSeries())
+	demux.ForceFlushToSerializer(time.Now(), true)
+	require.Equal(15, dogstatsdSeries())
+}
+
 // The implementation of MockSerializer.SendIterableSeries uses `s.Called(series).Error(0)`.
 // It calls internaly `Printf` on each field of the real type of `IterableStreamJSONMarshaler` which is `IterableSeries`.
 // It can lead to a race condition, if another goruntine call `IterableSeries.Append` which modifies `series.count`.
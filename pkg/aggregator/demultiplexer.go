@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/openmetrics"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
@@ -117,6 +118,8 @@ type DemultiplexerOptions struct {
 	UseEventPlatformForwarder      bool
 	UseOrchestratorForwarder       bool
 	UseContainerLifecycleForwarder bool
+	UseOpenMetricsExposition       bool
+	UsePrometheusRemoteWrite       bool
 	FlushInterval                  time.Duration
 
 	DontStartForwarders bool // unit tests don't need the forwarders to be instanciated
@@ -131,6 +134,13 @@ type statsd struct {
 	workers        []*timeSamplerWorker
 	// shared metric sample pool between the dogstatsd server & the time sampler
 	metricSamplePool *metrics.MetricSamplePool
+	// flushedShards receives the shards flushed by the workers when the flush
+	// of the time samplers is staggered, nil otherwise.
+	flushedShards chan flushedShard
+	// pendingShards holds the flushed shards not sent yet, they are sent
+	// once they contain `batchPoints` points or on the next flush.
+	pendingShards shardBatch
+	batchPoints   int
 }
 
 type forwarders struct {
@@ -138,11 +148,16 @@ type forwarders struct {
 	orchestrator       *forwarder.DefaultForwarder
 	eventPlatform      epforwarder.EventPlatformForwarder
 	containerLifecycle *forwarder.DefaultForwarder
+	remoteWrite        *forwarder.RemoteWriteForwarder
 }
 
 type dataOutputs struct {
 	forwarders       forwarders
 	sharedSerializer serializer.MetricSerializer
+
+	// sinks receive the same flushed series and sketches as the shared serializer.
+	sinks             flushSinks
+	openMetricsServer *openmetrics.Server
 }
 
 // trigger be used to trigger something in the TimeSampler or the BufferedAggregator.
@@ -241,6 +256,16 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 
 	sharedSerializer := serializer.NewSerializer(sharedForwarder, orchestratorForwarder, containerLifecycleForwarder)
 
+	var sinks flushSinks
+	if options.UseOpenMetricsExposition {
+		sinks.openMetrics = buildOpenMetricsStore()
+	}
+
+	var remoteWriteForwarder *forwarder.RemoteWriteForwarder
+	if options.UsePrometheusRemoteWrite {
+		remoteWriteForwarder, sinks.remoteWrite = buildRemoteWrite(options.SharedForwarderOptions)
+	}
+
 	// prepare the embedded aggregator
 	// --
 
@@ -257,15 +282,27 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 
 	statsdWorkers := make([]*timeSamplerWorker, statsdPipelinesCount)
 
+	// when the flush is staggered, every worker flushes a shard of its time
+	// sampler at a different time of the flush interval instead of flushing
+	// every context on the demultiplexer flush.
+	flushShards := config.Datadog.GetInt("dogstatsd_flush_shards")
+	var flushedShards chan flushedShard
+	if flushShards > 1 && options.FlushInterval > 0 {
+		log.Debugf("the flush of the time samplers is staggered over %d shards", flushShards)
+		flushedShards = make(chan flushedShard, statsdPipelinesCount*flushShards)
+	} else {
+		flushShards = 1
+	}
+
 	for i := 0; i < statsdPipelinesCount; i++ {
 		// the sampler
 		tagsStore := tags.NewStore(config.Datadog.GetBool("aggregator_use_tags_store"), fmt.Sprintf("timesampler #%d", i))
-		statsdSampler := NewTimeSampler(TimeSamplerID(i), bucketSize, tagsStore)
+		statsdSampler := NewTimeSamplerWithFlushShards(TimeSamplerID(i), bucketSize, flushShards, tagsStore)
 
 		// its worker (process loop + flush/serialization mechanism)
 
 		statsdWorkers[i] = newTimeSamplerWorker(statsdSampler, options.FlushInterval,
-			bufferSize, metricSamplePool, agg.flushAndSerializeInParallel, tagsStore)
+			bufferSize, metricSamplePool, agg.flushAndSerializeInParallel, tagsStore, flushedShards)
 	}
 
 	// --
@@ -286,9 +323,11 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 				orchestrator:       orchestratorForwarder,
 				eventPlatform:      eventPlatformForwarder,
 				containerLifecycle: containerLifecycleForwarder,
+				remoteWrite:        remoteWriteForwarder,
 			},
 
 			sharedSerializer: sharedSerializer,
+			sinks:            sinks,
 		},
 
 		senders: newSenders(agg),
@@ -298,6 +337,8 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 			pipelinesCount:   statsdPipelinesCount,
 			workers:          statsdWorkers,
 			metricSamplePool: metricSamplePool,
+			flushedShards:    flushedShards,
+			batchPoints:      config.Datadog.GetInt("serializer_max_series_points_per_payload"),
 		},
 	}
 
@@ -364,9 +405,20 @@ func (d *AgentDemultiplexer) Run() {
 		} else {
 			log.Debug("not starting the shared forwarder")
 		}
+
+		// prometheus remote-write forwarder
+		if d.forwarders.remoteWrite != nil {
+			d.forwarders.remoteWrite.Start() //nolint:errcheck
+		} else {
+			log.Debug("not starting the remote-write forwarder")
+		}
 		log.Debug("Forwarders started")
 	}
 
+	if d.dataOutputs.sinks.openMetrics != nil {
+		d.dataOutputs.openMetricsServer = startOpenMetricsServer(d.dataOutputs.sinks.openMetrics)
+	}
+
 	if d.options.UseContainerLifecycleForwarder {
 		d.aggregator.contLcycleDequeueOnce.Do(func() { go d.aggregator.dequeueContainerLifecycleEvents() })
 	}
@@ -394,13 +446,17 @@ func (d *AgentDemultiplexer) flushLoop() {
 			return
 		// manual flush sequence
 		case trigger := <-d.flushChan:
-			d.flushToSerializer(trigger.time, trigger.waitForSerializer)
+			d.flushToSerializer(trigger.time, trigger.waitForSerializer, true)
 			if trigger.blockChan != nil {
 				trigger.blockChan <- struct{}{}
 			}
-		// automatic flush sequence
+		// automatic flush sequence, the time samplers flush themselves
+		// when their flush is staggered.
 		case t := <-flushTicker:
-			d.flushToSerializer(t, false)
+			d.flushToSerializer(t, false, d.statsd.flushedShards == nil)
+		// staggered flush of the time samplers
+		case shard := <-d.statsd.flushedShards:
+			d.sendFlushedShard(shard)
 		}
 	}
 }
@@ -461,6 +517,15 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 			d.dataOutputs.forwarders.shared.Stop()
 			d.dataOutputs.forwarders.shared = nil
 		}
+		if d.dataOutputs.forwarders.remoteWrite != nil {
+			d.dataOutputs.forwarders.remoteWrite.Stop()
+			d.dataOutputs.forwarders.remoteWrite = nil
+		}
+	}
+
+	if d.dataOutputs.openMetricsServer != nil {
+		d.dataOutputs.openMetricsServer.Stop()
+		d.dataOutputs.openMetricsServer = nil
 	}
 
 	// misc
@@ -483,8 +548,8 @@ func (d *AgentDemultiplexer) ForceFlushToSerializer(start time.Time, waitForSeri
 	<-trigger.blockChan
 }
 
-// flushToSerializer flushes all data from the aggregator and time samplers
-// to the serializer.
+// flushToSerializer flushes all data from the aggregator and, if
+// `flushTimeSamplers` is true, the time samplers to the serializer.
 //
 // Best practice is that this method is *only* called by the flushLoop routine.
 // It technically works if called from outside of this routine, but beware of
@@ -495,7 +560,7 @@ func (d *AgentDemultiplexer) ForceFlushToSerializer(start time.Time, waitForSeri
 // If one day a better (faster?) solution is needed, we could either consider:
 // - to have an implementation of SendIterableSeries listening on multiple sinks in parallel, or,
 // - to have a thread-safe implementation of the underlying `util.BufferedChan`.
-func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerializer bool) {
+func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerializer bool, flushTimeSamplers bool) {
 	d.m.Lock()
 	defer d.m.Unlock()
 
@@ -517,13 +582,19 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 			d.sharedSerializer,
 			d.aggregator.flushAndSerializeInParallel,
 			logPayloads,
-			start)
+			start,
+			&d.dataOutputs.sinks)
 	}
 
 	// flush DogStatsD pipelines (statsd/time samplers)
 	// ------------------------------------------------
 
-	for _, worker := range d.statsd.workers {
+	workers := d.statsd.workers
+	if !flushTimeSamplers {
+		workers = nil
+	}
+
+	for _, worker := range workers {
 		// order the flush to the time sampler, and wait, in a different routine
 		t := flushTrigger{
 			trigger: trigger{
@@ -535,10 +606,25 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 			seriesSink:      seriesSink,
 		}
 
-		worker.flushChan <- t
+		// the shards flushed by the worker before the trigger are sent with
+		// this flush, the worker could be blocked handing them off otherwise.
+		for sent := false; !sent; {
+			select {
+			case worker.flushChan <- t:
+				sent = true
+			case shard := <-d.statsd.flushedShards:
+				d.statsd.pendingShards.add(shard)
+			}
+		}
 		<-t.trigger.blockChan
 	}
 
+	// the flushed shards not sent yet are sent with this flush
+	if _, series, sketches := d.statsd.pendingShards.take(); len(series) > 0 || len(sketches) > 0 {
+		flushedSeries = append(flushedSeries, series)
+		flushedSketches = append(flushedSketches, sketches)
+	}
+
 	// flush the aggregator (check samplers)
 	// -------------------------------------
 
@@ -575,6 +661,41 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 		sketches = append(sketches, s...)
 	}
 
+	d.sendSeriesAndSketches(start, series, sketches, logPayloads)
+
+	// the sinks send what they received since the previous flush at once,
+	// the staggered shards included
+	d.dataOutputs.sinks.flush()
+
+	addFlushTime("MainFlushTime", int64(time.Since(start)))
+	aggregatorNumberOfFlush.Add(1)
+}
+
+// sendFlushedShard adds a shard flushed by a time sampler worker to the
+// pending shards, and sends them to the serializer once they fill a series
+// payload. The remaining shards are sent on the next flush of the
+// demultiplexer.
+func (d *AgentDemultiplexer) sendFlushedShard(shard flushedShard) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.aggregator == nil {
+		return
+	}
+
+	d.statsd.pendingShards.add(shard)
+	if d.statsd.pendingShards.points < d.statsd.batchPoints {
+		return
+	}
+
+	start, series, sketches := d.statsd.pendingShards.take()
+	d.sendSeriesAndSketches(start, series, sketches, config.Datadog.GetBool("log_payloads"))
+	addFlushTime("StaggeredFlushTime", int64(time.Since(start)))
+}
+
+// sendSeriesAndSketches sends the flushed series and sketches to the sinks
+// and to the serializer. The sinks are flushed by flushToSerializer.
+func (d *AgentDemultiplexer) sendSeriesAndSketches(start time.Time, series metrics.Series, sketches metrics.SketchSeriesList, logPayloads bool) {
 	// debug flag to log payloads
 	// --------------------------
 
@@ -590,6 +711,14 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 		}
 	}
 
+	// send these to the other sinks
+	// -----------------------------
+
+	for _, s := range series {
+		d.dataOutputs.sinks.appendSerie(s)
+	}
+	d.dataOutputs.sinks.appendSketches(sketches)
+
 	// send these to the serializer
 	// ----------------------------
 
@@ -608,21 +737,22 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 		updateSketchTelemetry(start, uint64(len(sketches)), err)
 		tagsetTlm.updateHugeSketchesTelemetry(&sketches)
 	}
-
-	addFlushTime("MainFlushTime", int64(time.Since(start)))
-	aggregatorNumberOfFlush.Add(1)
 }
 
 func startSendingIterableSeries(
 	serializer serializer.MetricSerializer,
 	flushAndSerializeInParallel FlushAndSerializeInParallel,
 	logPayloads bool,
-	start time.Time) (*metrics.IterableSeries, chan struct{}) {
+	start time.Time,
+	sinks *flushSinks) (*metrics.IterableSeries, chan struct{}) {
 	seriesSink := metrics.NewIterableSeries(func(se *metrics.Serie) {
 		if logPayloads {
 			log.Debugf("Flushing serie: %s", se)
 		}
 		tagsetTlm.updateHugeSerieTelemetry(se)
+		if sinks != nil {
+			sinks.appendSerie(se)
+		}
 	}, flushAndSerializeInParallel.BufferSize, flushAndSerializeInParallel.ChannelSize)
 	done := make(chan struct{})
 	go sendIterableSeries(serializer, start, seriesSink, done)
@@ -687,6 +817,15 @@ func (d *AgentDemultiplexer) Serializer() serializer.MetricSerializer {
 	return d.dataOutputs.sharedSerializer
 }
 
+// RetryQueueManager returns the manager of the retry queues of the shared
+// forwarder, nil when the shared forwarder has no retry queue.
+func (d *AgentDemultiplexer) RetryQueueManager() forwarder.RetryQueueManager {
+	if manager, ok := d.dataOutputs.forwarders.shared.(forwarder.RetryQueueManager); ok {
+		return manager
+	}
+	return nil
+}
+
 // Aggregator returns an aggregator that anyone can use. This method exists
 // to keep compatibility with existing code while introducing the Demultiplexer,
 // however, the plan is to remove it anytime soon.
@@ -800,7 +939,7 @@ func InitAndStartServerlessDemultiplexer(domainResolvers map[string]resolver.Dom
 
 	statsdSampler := NewTimeSampler(TimeSamplerID(0), bucketSize, tagsStore)
 	flushAndSerializeInParallel := NewFlushAndSerializeInParallel(serializer, config.Datadog)
-	statsdWorker := newTimeSamplerWorker(statsdSampler, DefaultFlushInterval, bufferSize, metricSamplePool, flushAndSerializeInParallel, tagsStore)
+	statsdWorker := newTimeSamplerWorker(statsdSampler, DefaultFlushInterval, bufferSize, metricSamplePool, flushAndSerializeInParallel, tagsStore, nil)
 
 	demux := &ServerlessDemultiplexer{
 		forwarder:        forwarder,
@@ -865,7 +1004,8 @@ func (d *ServerlessDemultiplexer) ForceFlushToSerializer(start time.Time, waitFo
 			d.serializer,
 			d.flushAndSerializeInParallel,
 			logPayloads,
-			start)
+			start,
+			nil)
 	}
 
 	flushedSeries := make([]metrics.Series, 0)
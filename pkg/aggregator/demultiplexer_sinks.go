@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/openmetrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/remotewrite"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var defaultSinkQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// flushSinks are the outputs receiving the flushed series and sketches in
+// addition to the shared serializer. Every sink is optional.
+type flushSinks struct {
+	openMetrics *openmetrics.Store
+	remoteWrite *remotewrite.Sink
+}
+
+// appendSerie is called for every flushed serie, including the ones sent
+// through the iterable series when flushing and serializing in parallel.
+func (s *flushSinks) appendSerie(serie *metrics.Serie) {
+	if s.openMetrics != nil {
+		s.openMetrics.AppendSerie(serie)
+	}
+	if s.remoteWrite != nil {
+		s.remoteWrite.AppendSerie(serie)
+	}
+}
+
+func (s *flushSinks) appendSketches(sketches metrics.SketchSeriesList) {
+	if len(sketches) == 0 {
+		return
+	}
+	if s.openMetrics != nil {
+		s.openMetrics.AppendSketches(sketches)
+	}
+	if s.remoteWrite != nil {
+		s.remoteWrite.AppendSketches(sketches)
+	}
+}
+
+// flush is called once all the series and sketches of a flush have been appended.
+func (s *flushSinks) flush() {
+	if s.remoteWrite != nil {
+		s.remoteWrite.Flush()
+	}
+}
+
+// sinkQuantiles returns the quantiles configured under the given key.
+func sinkQuantiles(key string) []float64 {
+	quantiles, err := config.Datadog.GetFloat64SliceE(key)
+	if err != nil {
+		log.Errorf("Invalid %s, using the defaults: %v", key, err)
+		return defaultSinkQuantiles
+	}
+	return quantiles
+}
+
+// buildOpenMetricsStore creates the store receiving the flushed series and
+// sketches for the local OpenMetrics exposition.
+func buildOpenMetricsStore() *openmetrics.Store {
+	return openmetrics.NewStore(openmetrics.Config{
+		Namespace:        config.Datadog.GetString("openmetrics_exposition.namespace"),
+		SanitizeLabels:   config.Datadog.GetBool("openmetrics_exposition.sanitize_labels"),
+		StalenessTimeout: config.Datadog.GetDuration("openmetrics_exposition.staleness_timeout"),
+		Quantiles:        sinkQuantiles("openmetrics_exposition.quantiles"),
+	})
+}
+
+// startOpenMetricsServer starts the HTTP server exposing the given store.
+func startOpenMetricsServer(store *openmetrics.Store) *openmetrics.Server {
+	addr := net.JoinHostPort(
+		config.Datadog.GetString("openmetrics_exposition.bind_host"),
+		strconv.Itoa(config.Datadog.GetInt("openmetrics_exposition.port")),
+	)
+
+	srv, err := openmetrics.NewServer(addr, store)
+	if err != nil {
+		log.Errorf("Could not start the OpenMetrics exposition server on %s: %v", addr, err)
+		return nil
+	}
+	srv.Start()
+	log.Infof("OpenMetrics exposition of the aggregated metrics listening on %s", srv.Addr())
+	return srv
+}
+
+// buildRemoteWrite creates the forwarder and the sink sending the flushed
+// series and sketches to the Prometheus remote-write endpoints. Both are nil
+// if the configuration is invalid.
+func buildRemoteWrite(options *forwarder.Options) (*forwarder.RemoteWriteForwarder, *remotewrite.Sink) {
+	endpoints, err := remotewrite.EndpointsFromConfig(config.Datadog)
+	if err != nil {
+		log.Errorf("Prometheus remote-write disabled: %v", err)
+		return nil, nil
+	}
+	if len(endpoints) == 0 {
+		log.Warn("Prometheus remote-write is enabled but no endpoints are configured")
+		return nil, nil
+	}
+
+	fwd, err := forwarder.NewRemoteWriteForwarder(remotewrite.ForwarderEndpoints(endpoints), options)
+	if err != nil {
+		log.Errorf("Prometheus remote-write disabled: %v", err)
+		return nil, nil
+	}
+
+	sink := remotewrite.NewSink(fwd, endpoints,
+		sinkQuantiles("prometheus_remote_write.quantiles"),
+		config.Datadog.GetInt("prometheus_remote_write.max_series_per_payload"))
+	return fwd, sink
+}
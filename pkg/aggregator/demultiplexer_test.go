@@ -6,11 +6,15 @@
 package aggregator
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
 	providerMocks "github.com/DataDog/datadog-agent/pkg/util/containers/providers/mock"
 
@@ -188,6 +192,96 @@ func TestDemuxFlushAggregatorToSerializer(t *testing.T) {
 	require.Len(sketches, 0)
 }
 
+func TestDemuxFlushToOpenMetricsExposition(t *testing.T) {
+	defer config.Datadog.Set("aggregator_flush_metrics_and_serialize_in_parallel", nil)
+
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel=%v", parallel), func(t *testing.T) {
+			require := require.New(t)
+			config.Datadog.Set("aggregator_flush_metrics_and_serialize_in_parallel", parallel)
+
+			opts := demuxTestOptions()
+			opts.UseNoopForwarder = true
+			opts.UseOpenMetricsExposition = true
+			demux := initAgentDemultiplexer(opts, "")
+			demux.Aggregator().tlmContainerTagsEnabled = false
+			require.NotNil(demux.sinks.openMetrics)
+
+			for _, w := range demux.statsd.workers {
+				go w.run()
+			}
+			go demux.aggregator.run()
+			defer func() {
+				for _, w := range demux.statsd.workers {
+					w.stop()
+				}
+				demux.aggregator.Stop()
+			}()
+
+			sender, err := demux.GetDefaultSender()
+			require.NoError(err)
+			sender.Gauge("my.check.metric", 1.0, "", []string{"team:agent-core"})
+			sender.Commit()
+			demux.AddTimeSample(metrics.MetricSample{
+				Name:       "my.dogstatsd.distribution",
+				Value:      2.0,
+				Mtype:      metrics.DistributionType,
+				Tags:       []string{"team:agent-core"},
+				SampleRate: 1,
+				Timestamp:  float64(time.Now().Add(-time.Minute).Unix()),
+			})
+
+			require.Eventually(func() bool {
+				demux.flushToSerializer(time.Now(), false, true)
+				return demux.sinks.openMetrics.Len() >= 2
+			}, 5*time.Second, 100*time.Millisecond)
+
+			var b strings.Builder
+			_, err = demux.sinks.openMetrics.WriteTo(&b)
+			require.NoError(err)
+			require.Contains(b.String(), "# TYPE my_check_metric gauge\n")
+			require.Contains(b.String(), "# TYPE my_dogstatsd_distribution summary\n")
+		})
+	}
+}
+
+func TestDemuxStaggeredFlush(t *testing.T) {
+	require := require.New(t)
+	defer config.Datadog.Set("dogstatsd_flush_shards", nil)
+	config.Datadog.Set("dogstatsd_flush_shards", 3)
+
+	opts := demuxTestOptions()
+	opts.FlushInterval = 60 * time.Millisecond
+	opts.UseNoopForwarder = true
+	opts.UseOpenMetricsExposition = true
+	demux := initAgentDemultiplexer(opts, "")
+	demux.Aggregator().tlmContainerTagsEnabled = false
+	require.NotNil(demux.statsd.flushedShards)
+	for _, w := range demux.statsd.workers {
+		require.Len(w.sampler.shards, 3)
+	}
+
+	go demux.Run()
+	defer demux.Stop(false)
+
+	// the samples are in buckets closed for every shard
+	timestamp := float64(time.Now().Add(-time.Minute).Unix())
+	for i := 0; i < 30; i++ {
+		demux.AddTimeSample(metrics.MetricSample{
+			Name:       fmt.Sprintf("my.dogstatsd.gauge.%d", i),
+			Value:      1.0,
+			Mtype:      metrics.GaugeType,
+			Tags:       []string{"team:agent-core"},
+			SampleRate: 1,
+			Timestamp:  timestamp,
+		})
+	}
+
+	require.Eventually(func() bool {
+		return demux.sinks.openMetrics.Len() >= 30
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
 func TestGetDogStatsDWorkerAndPipelineCount(t *testing.T) {
 	pc := config.Datadog.GetInt("dogstatsd_pipeline_count")
 	aa := config.Datadog.GetInt("dogstatsd_pipeline_autoadjust")
@@ -256,3 +350,78 @@ func TestGetDogStatsDWorkerAndPipelineCount(t *testing.T) {
 	assert.Equal(2, dsdWorkers)
 	assert.Equal(4, pipelines)
 }
+
+// benchmarkDemuxFlush reports the p99 latency of the steps of the flush of the
+// DogStatsD metrics, from the flush of the time sampler to the serialization
+// of the payloads. The time sampler does not process any sample during a step.
+// The total time spent flushing a bucket is reported as ns/op.
+func benchmarkDemuxFlush(b *testing.B, contexts int, flushShards int) {
+	defer config.Datadog.Set("dogstatsd_flush_shards", nil)
+	config.Datadog.Set("dogstatsd_flush_shards", flushShards)
+
+	opts := demuxTestOptions()
+	opts.UseNoopForwarder = true
+	demux := initAgentDemultiplexer(opts, "")
+	demux.Aggregator().tlmContainerTagsEnabled = false
+
+	// the flush loop is not started, the benchmark receives the flushed shards
+	worker := demux.statsd.workers[0]
+	go worker.run()
+	defer worker.stop()
+	go demux.aggregator.run()
+	defer demux.aggregator.Stop()
+
+	samples := make([]metrics.MetricSample, contexts)
+	for i := range samples {
+		samples[i] = metrics.MetricSample{
+			Name:       fmt.Sprintf("my.metric.%d", i),
+			Value:      1,
+			Mtype:      metrics.GaugeType,
+			Tags:       []string{"foo", "bar"},
+			SampleRate: 1,
+		}
+	}
+
+	latencies := make([]time.Duration, 0, b.N*(flushShards+1))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		bucket := time.Unix(int64(12340+10*n), 0)
+		for i := range samples {
+			worker.sampler.sample(&samples[i], float64(bucket.Unix()))
+		}
+		flushTime := bucket.Add(10 * time.Second)
+		b.StartTimer()
+
+		// the shards are flushed by the worker and handed off to the
+		// demultiplexer, which serializes them once they fill a payload
+		if flushShards > 1 {
+			for shard := 0; shard < flushShards; shard++ {
+				start := time.Now()
+				worker.flushShard(shard, flushTime)
+				demux.sendFlushedShard(<-demux.statsd.flushedShards)
+				latencies = append(latencies, time.Since(start))
+			}
+		}
+
+		// the flush of the demultiplexer flushes the time samplers when the
+		// flush is not staggered, and sends the remaining shards otherwise
+		start := time.Now()
+		demux.flushToSerializer(flushTime, false, flushShards <= 1)
+		latencies = append(latencies, time.Since(start))
+	}
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns/flush")
+}
+
+func BenchmarkDemuxFlush(b *testing.B) {
+	for _, contexts := range []int{1000, 50000} {
+		for _, flushShards := range []int{1, 3, 15} {
+			b.Run(fmt.Sprintf("contexts=%d/shards=%d", contexts, flushShards), func(b *testing.B) {
+				benchmarkDemuxFlush(b, contexts, flushShards)
+			})
+		}
+	}
+}
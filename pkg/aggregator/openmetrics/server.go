@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	contentType    = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	defaultTimeout = 5 * time.Second
+)
+
+// ServeHTTP exposes the content of the store.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if _, err := s.WriteTo(w); err != nil {
+		log.Debugf("Error while writing the OpenMetrics exposition: %v", err)
+	}
+}
+
+// Server is the HTTP server exposing a Store.
+type Server struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// NewServer starts listening on the given address and serves the store on
+// the `/metrics` path.
+func NewServer(addr string, store *Store) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", store)
+
+	return &Server{
+		srv: &http.Server{
+			Handler:           mux,
+			ReadTimeout:       defaultTimeout,
+			ReadHeaderTimeout: defaultTimeout,
+			WriteTimeout:      defaultTimeout,
+		},
+		ln: ln,
+	}, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Start serves the requests in a goroutine.
+func (s *Server) Start() {
+	go func() {
+		if err := s.srv.Serve(s.ln); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Error while serving the OpenMetrics exposition: %v", err)
+		}
+	}()
+}
+
+// Stop shuts the server down.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.srv.Shutdown(ctx) //nolint:errcheck
+}
@@ -0,0 +1,295 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/labels"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+// Config holds the options of a Store.
+type Config struct {
+	// Namespace is prepended to every exposed metric name when not empty.
+	Namespace string
+	// SanitizeLabels turns tag keys into valid OpenMetrics label names.
+	SanitizeLabels bool
+	// StalenessTimeout is the duration after which a context that has not
+	// been flushed again is removed from the exposition. 0 disables it.
+	StalenessTimeout time.Duration
+	// Quantiles are the quantiles exposed for each sketch.
+	Quantiles []float64
+}
+
+type sample struct {
+	labels    string
+	value     float64
+	sum       float64
+	count     float64
+	quantiles []float64
+	timestamp float64
+	updated   time.Time
+}
+
+type family struct {
+	typ     string
+	samples map[string]*sample
+}
+
+// Store keeps the latest flushed value of every series and sketch and
+// renders them in the OpenMetrics text format.
+type Store struct {
+	m        sync.Mutex
+	config   Config
+	families map[string]*family
+	now      func() time.Time
+}
+
+// NewStore returns an empty Store.
+func NewStore(config Config) *Store {
+	return &Store{
+		config:   config,
+		families: make(map[string]*family),
+		now:      time.Now,
+	}
+}
+
+// AppendSerie records the last point of the given serie. It has the
+// signature of the callback given to metrics.NewIterableSeries so that it
+// can be called from the same flush hook as the serializer.
+func (s *Store) AppendSerie(serie *metrics.Serie) {
+	if serie == nil || len(serie.Points) == 0 {
+		return
+	}
+
+	set := labels.NewLabelSet(s.config.SanitizeLabels)
+	serie.Tags.ForEach(set.AddTag)
+	if serie.Host != "" {
+		set.Add("host", serie.Host)
+	}
+	if serie.Device != "" {
+		set.Add("device", serie.Device)
+	}
+
+	point := serie.Points[len(serie.Points)-1]
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	smp := s.getSample(s.metricName(serie.Name), typeGauge, set.String())
+	if smp == nil {
+		return
+	}
+	smp.value = point.Value
+	smp.timestamp = point.Ts
+	smp.updated = s.now()
+}
+
+// AppendSketches records the last point of every sketch, exposed as
+// OpenMetrics summaries.
+func (s *Store) AppendSketches(sketches metrics.SketchSeriesList) {
+	if len(sketches) == 0 {
+		return
+	}
+
+	config := quantile.Default()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, sketch := range sketches {
+		if len(sketch.Points) == 0 {
+			continue
+		}
+		point := sketch.Points[len(sketch.Points)-1]
+		if point.Sketch == nil {
+			continue
+		}
+
+		set := labels.NewLabelSet(s.config.SanitizeLabels)
+		sketch.Tags.ForEach(set.AddTag)
+		if sketch.Host != "" {
+			set.Add("host", sketch.Host)
+		}
+
+		smp := s.getSample(s.metricName(sketch.Name), typeSummary, set.String())
+		if smp == nil {
+			continue
+		}
+		smp.sum = point.Sketch.Basic.Sum
+		smp.count = float64(point.Sketch.Basic.Cnt)
+		smp.quantiles = smp.quantiles[:0]
+		for _, q := range s.config.Quantiles {
+			smp.quantiles = append(smp.quantiles, point.Sketch.Quantile(config, q))
+		}
+		smp.timestamp = float64(point.Ts)
+		smp.updated = s.now()
+	}
+}
+
+func (s *Store) metricName(name string) string {
+	if s.config.Namespace != "" {
+		name = s.config.Namespace + "_" + name
+	}
+	return labels.SanitizeName(name, true)
+}
+
+// getSample returns the sample for the given family and labels, creating
+// it if needed. It returns nil if the family already exists with another
+// type. The caller must hold the lock.
+func (s *Store) getSample(name, typ, labels string) *sample {
+	fam, found := s.families[name]
+	if !found {
+		fam = &family{
+			typ:     typ,
+			samples: make(map[string]*sample),
+		}
+		s.families[name] = fam
+	} else if fam.typ != typ {
+		log.Debugf("Not exposing %s as a %s, it is already exposed as a %s", name, typ, fam.typ)
+		return nil
+	}
+
+	smp, found := fam.samples[labels]
+	if !found {
+		smp = &sample{labels: labels}
+		fam.samples[labels] = smp
+	}
+	return smp
+}
+
+// expireStale removes the samples that were not updated during the
+// staleness timeout. The caller must hold the lock.
+func (s *Store) expireStale() {
+	if s.config.StalenessTimeout <= 0 {
+		return
+	}
+
+	deadline := s.now().Add(-s.config.StalenessTimeout)
+	for name, fam := range s.families {
+		for labels, smp := range fam.samples {
+			if smp.updated.Before(deadline) {
+				delete(fam.samples, labels)
+			}
+		}
+		if len(fam.samples) == 0 {
+			delete(s.families, name)
+		}
+	}
+}
+
+// Len returns the number of exposed samples.
+func (s *Store) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.expireStale()
+	n := 0
+	for _, fam := range s.families {
+		n += len(fam.samples)
+	}
+	return n
+}
+
+// WriteTo writes the content of the store in the OpenMetrics text format.
+// Families and samples are sorted to produce a stable output.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.expireStale()
+
+	names := make([]string, 0, len(s.families))
+	for name := range s.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, name := range names {
+		fam := s.families[name]
+		cw.writeString("# TYPE " + name + " " + fam.typ + "\n")
+
+		labels := make([]string, 0, len(fam.samples))
+		for l := range fam.samples {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+
+		for _, l := range labels {
+			smp := fam.samples[l]
+			switch fam.typ {
+			case typeGauge:
+				cw.writeSample(name, smp.labels, "", smp.value, smp.timestamp)
+			case typeSummary:
+				for i, q := range s.config.Quantiles {
+					if i >= len(smp.quantiles) {
+						break
+					}
+					cw.writeSample(name, smp.labels, `quantile="`+formatFloat(q)+`"`, smp.quantiles[i], smp.timestamp)
+				}
+				cw.writeSample(name+"_sum", smp.labels, "", smp.sum, smp.timestamp)
+				cw.writeSample(name+"_count", smp.labels, "", smp.count, smp.timestamp)
+			}
+		}
+	}
+	cw.writeString("# EOF\n")
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) writeString(str string) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.WriteString(str)
+	c.n += int64(n)
+	c.err = err
+}
+
+func (c *countingWriter) writeSample(name, labels, extraLabel string, value, timestamp float64) {
+	c.writeString(name)
+	if labels != "" || extraLabel != "" {
+		c.writeString("{")
+		c.writeString(labels)
+		if labels != "" && extraLabel != "" {
+			c.writeString(",")
+		}
+		c.writeString(extraLabel)
+		c.writeString("}")
+	}
+	c.writeString(" " + formatFloat(value))
+	if timestamp > 0 {
+		c.writeString(" " + formatFloat(timestamp))
+	}
+	c.writeString("\n")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
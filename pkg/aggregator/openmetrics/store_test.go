@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func render(t *testing.T, s *Store) string {
+	var b strings.Builder
+	_, err := s.WriteTo(&b)
+	require.NoError(t, err)
+	return b.String()
+}
+
+func TestAppendSerie(t *testing.T) {
+	s := NewStore(Config{SanitizeLabels: true})
+
+	s.AppendSerie(&metrics.Serie{
+		Name:   "my.gauge",
+		Points: []metrics.Point{{Ts: 10, Value: 1}, {Ts: 20, Value: 2}},
+		Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "kube.app:web", "flag", "env:staging"}),
+		Host:   "myhost",
+		MType:  metrics.APIGaugeType,
+	})
+	s.AppendSerie(&metrics.Serie{
+		Name:   "my.count",
+		Points: []metrics.Point{{Ts: 20, Value: 3}},
+		MType:  metrics.APICountType,
+	})
+	// no points, ignored
+	s.AppendSerie(&metrics.Serie{Name: "empty"})
+
+	assert.Equal(t, `# TYPE my_count gauge
+my_count 3 20
+# TYPE my_gauge gauge
+my_gauge{env="prod,staging",flag="true",host="myhost",kube_app="web"} 2 20
+# EOF
+`, render(t, s))
+	assert.Equal(t, 2, s.Len())
+}
+
+func TestAppendSerieNoSanitize(t *testing.T) {
+	s := NewStore(Config{Namespace: "datadog"})
+
+	s.AppendSerie(&metrics.Serie{
+		Name:   "my.gauge",
+		Points: []metrics.Point{{Ts: 10, Value: 1}},
+		Tags:   tagset.CompositeTagsFromSlice([]string{"kube.app:web"}),
+	})
+
+	assert.Equal(t, `# TYPE datadog_my_gauge gauge
+datadog_my_gauge{kube.app="web"} 1 10
+# EOF
+`, render(t, s))
+}
+
+func TestAppendSketches(t *testing.T) {
+	s := NewStore(Config{SanitizeLabels: true, Quantiles: []float64{0, 1}})
+
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1, 2, 3, 4)
+
+	s.AppendSketches(metrics.SketchSeriesList{
+		{
+			Name:   "my.dist",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+			Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}},
+		},
+	})
+
+	assert.Equal(t, `# TYPE my_dist summary
+my_dist{env="prod",quantile="0"} 1 10
+my_dist{env="prod",quantile="1"} 4 10
+my_dist_sum{env="prod"} 10 10
+my_dist_count{env="prod"} 4 10
+# EOF
+`, render(t, s))
+}
+
+func TestTypeConflict(t *testing.T) {
+	s := NewStore(Config{})
+
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1)
+
+	s.AppendSerie(&metrics.Serie{Name: "metric", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+	s.AppendSketches(metrics.SketchSeriesList{
+		{Name: "metric", Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}}},
+	})
+
+	assert.Equal(t, 1, s.Len())
+	assert.Contains(t, render(t, s), "# TYPE metric gauge\n")
+}
+
+func TestStaleness(t *testing.T) {
+	now := time.Now()
+	s := NewStore(Config{StalenessTimeout: time.Minute})
+	s.now = func() time.Time { return now }
+
+	s.AppendSerie(&metrics.Serie{Name: "old", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+	now = now.Add(30 * time.Second)
+	s.AppendSerie(&metrics.Serie{Name: "new", Points: []metrics.Point{{Ts: 40, Value: 1}}})
+	assert.Equal(t, 2, s.Len())
+
+	now = now.Add(45 * time.Second)
+	assert.Equal(t, 1, s.Len())
+	assert.Equal(t, "# TYPE new gauge\nnew 1 40\n# EOF\n", render(t, s))
+
+	// the context comes back once flushed again
+	s.AppendSerie(&metrics.Serie{Name: "old", Points: []metrics.Point{{Ts: 85, Value: 2}}})
+	assert.Equal(t, 2, s.Len())
+}
+
+func TestServer(t *testing.T) {
+	s := NewStore(Config{})
+	s.AppendSerie(&metrics.Serie{Name: "metric", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+
+	srv, err := NewServer("127.0.0.1:0", s)
+	require.NoError(t, err)
+	srv.Start()
+	defer srv.Stop()
+
+	resp, err := http.Get("http://" + srv.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, contentType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "# TYPE metric gauge\nmetric 1 10\n# EOF\n", string(body))
+}
@@ -25,38 +25,72 @@ type TimeSamplerID int
 
 // TimeSampler aggregates metrics by buckets of 'interval' seconds
 type TimeSampler struct {
-	interval                    int64
-	contextResolver             *timestampContextResolver
-	metricsByTimestamp          map[int64]metrics.ContextMetrics
-	counterLastSampledByContext map[ckey.ContextKey]float64
-	lastCutOffTime              int64
-	sketchMap                   sketchMap
+	interval        int64
+	contextResolver *timestampContextResolver
+
+	// shards split the contexts so that they can be flushed at different
+	// times, see flushShard. There is a single shard unless the flush of
+	// the time sampler is staggered.
+	shards []*timeSamplerShard
 
 	// id is a number to differentiate multiple time samplers
 	// since we start running more than one with the demultiplexer introduction
 	id TimeSamplerID
 }
 
+// timeSamplerShard holds the buckets of a subset of the contexts of a TimeSampler.
+type timeSamplerShard struct {
+	metricsByTimestamp          map[int64]metrics.ContextMetrics
+	counterLastSampledByContext map[ckey.ContextKey]float64
+	lastCutOffTime              int64
+	sketchMap                   sketchMap
+}
+
 // NewTimeSampler returns a newly initialized TimeSampler
 func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store) *TimeSampler {
+	return NewTimeSamplerWithFlushShards(id, interval, 1, cache)
+}
+
+// NewTimeSamplerWithFlushShards returns a newly initialized TimeSampler
+// splitting its contexts in `flushShards` shards which can be flushed
+// independently.
+func NewTimeSamplerWithFlushShards(id TimeSamplerID, interval int64, flushShards int, cache *tags.Store) *TimeSampler {
 	if interval == 0 {
 		interval = bucketSize
 	}
+	if flushShards < 1 {
+		flushShards = 1
+	}
 
 	log.Infof("Creating TimeSampler #%d", id)
 
 	s := &TimeSampler{
-		interval:                    interval,
-		contextResolver:             newTimestampContextResolver(cache),
-		metricsByTimestamp:          map[int64]metrics.ContextMetrics{},
-		counterLastSampledByContext: map[ckey.ContextKey]float64{},
-		sketchMap:                   make(sketchMap),
-		id:                          id,
+		interval:        interval,
+		contextResolver: newTimestampContextResolver(cache),
+		shards:          make([]*timeSamplerShard, flushShards),
+		id:              id,
+	}
+	for i := range s.shards {
+		s.shards[i] = &timeSamplerShard{
+			metricsByTimestamp:          map[int64]metrics.ContextMetrics{},
+			counterLastSampledByContext: map[ckey.ContextKey]float64{},
+			sketchMap:                   make(sketchMap),
+		}
 	}
 
 	return s
 }
 
+// shardOf returns the shard storing the buckets of a context. The DogStatsD
+// pipelines are chosen with the high bits of the context key, the low bits
+// are used here so that every shard of every pipeline gets contexts.
+func (s *TimeSampler) shardOf(contextKey ckey.ContextKey) *timeSamplerShard {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	return s.shards[uint32(contextKey)%uint32(len(s.shards))]
+}
+
 func (s *TimeSampler) calculateBucketStart(timestamp float64) int64 {
 	return int64(timestamp) - int64(timestamp)%s.interval
 }
@@ -74,20 +108,21 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 	// Keep track of the context
 	contextKey := s.contextResolver.trackContext(metricSample, timestamp)
 	bucketStart := s.calculateBucketStart(timestamp)
+	shard := s.shardOf(contextKey)
 
 	switch metricSample.Mtype {
 	case metrics.DistributionType:
-		s.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
+		shard.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
 	default:
 		// If it's a new bucket, initialize it
-		bucketMetrics, ok := s.metricsByTimestamp[bucketStart]
+		bucketMetrics, ok := shard.metricsByTimestamp[bucketStart]
 		if !ok {
 			bucketMetrics = metrics.MakeContextMetrics()
-			s.metricsByTimestamp[bucketStart] = bucketMetrics
+			shard.metricsByTimestamp[bucketStart] = bucketMetrics
 		}
 		// Update LastSampled timestamp for counters
 		if metricSample.Mtype == metrics.CounterType {
-			s.counterLastSampledByContext[contextKey] = timestamp
+			shard.counterLastSampledByContext[contextKey] = timestamp
 		}
 
 		// Add sample to bucket
@@ -110,13 +145,13 @@ func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.Sketc
 	return ss
 }
 
-func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
+func (s *TimeSampler) flushSeries(shard *timeSamplerShard, cutoffTime int64, series metrics.SerieSink) {
 	// Map to hold the expired contexts that will need to be deleted after the flush so that we stop sending zeros
 	counterContextsToDelete := map[ckey.ContextKey]struct{}{}
 	contextMetricsFlusher := metrics.NewContextMetricsFlusher()
 
-	if len(s.metricsByTimestamp) > 0 {
-		for bucketTimestamp, contextMetrics := range s.metricsByTimestamp {
+	if len(shard.metricsByTimestamp) > 0 {
+		for bucketTimestamp, contextMetrics := range shard.metricsByTimestamp {
 			// disregard when the timestamp is too recent
 			if s.isBucketStillOpen(bucketTimestamp, cutoffTime) {
 				continue
@@ -124,18 +159,18 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 
 			// Add a 0 sample to all the counters that are not expired.
 			// It is ok to add 0 samples to a counter that was already sampled for real in the bucket, since it won't change its value
-			s.countersSampleZeroValue(bucketTimestamp, contextMetrics, counterContextsToDelete)
+			s.countersSampleZeroValue(shard, bucketTimestamp, contextMetrics, counterContextsToDelete)
 			contextMetricsFlusher.Append(float64(bucketTimestamp), contextMetrics)
 
-			delete(s.metricsByTimestamp, bucketTimestamp)
+			delete(shard.metricsByTimestamp, bucketTimestamp)
 		}
-	} else if s.lastCutOffTime+s.interval <= cutoffTime {
+	} else if shard.lastCutOffTime+s.interval <= cutoffTime {
 		// Even if there is no metric in this flush, recreate empty counters,
 		// but only if we've passed an interval since the last flush
 
 		contextMetrics := metrics.MakeContextMetrics()
 
-		s.countersSampleZeroValue(cutoffTime-s.interval, contextMetrics, counterContextsToDelete)
+		s.countersSampleZeroValue(shard, cutoffTime-s.interval, contextMetrics, counterContextsToDelete)
 		contextMetricsFlusher.Append(float64(cutoffTime-s.interval), contextMetrics)
 	}
 
@@ -148,7 +183,7 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 
 	// Delete the contexts associated to an expired counter
 	for context := range counterContextsToDelete {
-		delete(s.counterLastSampledByContext, context)
+		delete(shard.counterLastSampledByContext, context)
 	}
 }
 
@@ -189,11 +224,11 @@ func (s *TimeSampler) dedupSerieBySerieSignature(
 	}
 }
 
-func (s *TimeSampler) flushSketches(cutoffTime int64) metrics.SketchSeriesList {
+func (s *TimeSampler) flushSketches(shard *timeSamplerShard, cutoffTime int64) metrics.SketchSeriesList {
 	pointsByCtx := make(map[ckey.ContextKey][]metrics.SketchPoint)
 	sketches := make(metrics.SketchSeriesList, 0, len(pointsByCtx))
 
-	s.sketchMap.flushBefore(cutoffTime, func(ck ckey.ContextKey, p metrics.SketchPoint) {
+	shard.sketchMap.flushBefore(cutoffTime, func(ck ckey.ContextKey, p metrics.SketchPoint) {
 		if p.Sketch == nil {
 			return
 		}
@@ -210,12 +245,38 @@ func (s *TimeSampler) flush(timestamp float64, series metrics.SerieSink) metrics
 	// Compute a limit timestamp
 	cutoffTime := s.calculateBucketStart(timestamp)
 
-	s.flushSeries(cutoffTime, series)
-	sketches := s.flushSketches(cutoffTime)
+	var sketches metrics.SketchSeriesList
+	for _, shard := range s.shards {
+		sketches = append(sketches, s.flushShardBefore(shard, cutoffTime, series)...)
+	}
+
+	s.expireContexts(timestamp)
+	return sketches
+}
+
+// flushShard flushes the closed buckets of a single shard. The buckets keep
+// their timestamps: a shard flushed later than the others only delays the
+// sending of its series.
+func (s *TimeSampler) flushShard(shardID int, timestamp float64, series metrics.SerieSink) metrics.SketchSeriesList {
+	sketches := s.flushShardBefore(s.shards[shardID], s.calculateBucketStart(timestamp), series)
+
+	// expiring the contexts once per round of the shards is enough, the
+	// expiry delay is much longer than the flush interval.
+	if shardID == 0 {
+		s.expireContexts(timestamp)
+	}
+	return sketches
+}
+
+func (s *TimeSampler) flushShardBefore(shard *timeSamplerShard, cutoffTime int64, series metrics.SerieSink) metrics.SketchSeriesList {
+	s.flushSeries(shard, cutoffTime, series)
+	sketches := s.flushSketches(shard, cutoffTime)
+	shard.lastCutOffTime = cutoffTime
+	return sketches
+}
 
-	// expiring contexts
+func (s *TimeSampler) expireContexts(timestamp float64) {
 	s.contextResolver.expireContexts(timestamp - config.Datadog.GetFloat64("dogstatsd_context_expiry_seconds"))
-	s.lastCutOffTime = cutoffTime
 
 	totalContexts := s.contextResolver.length()
 	aggregatorDogstatsdContexts.Set(int64(totalContexts))
@@ -227,8 +288,6 @@ func (s *TimeSampler) flush(timestamp float64, series metrics.SerieSink) metrics
 		aggregatorDogstatsdContextsByMtype[i].Set(int64(count))
 		tlmDogstatsdContextsByMtype.Set(float64(count), mtype)
 	}
-
-	return sketches
 }
 
 // flushContextMetrics flushes the contextMetrics inside contextMetricsFlusher, handles its errors,
@@ -245,9 +304,9 @@ func (s *TimeSampler) flushContextMetrics(contextMetricsFlusher *metrics.Context
 	}
 }
 
-func (s *TimeSampler) countersSampleZeroValue(timestamp int64, contextMetrics metrics.ContextMetrics, counterContextsToDelete map[ckey.ContextKey]struct{}) {
+func (s *TimeSampler) countersSampleZeroValue(shard *timeSamplerShard, timestamp int64, contextMetrics metrics.ContextMetrics, counterContextsToDelete map[ckey.ContextKey]struct{}) {
 	expirySeconds := config.Datadog.GetFloat64("dogstatsd_expiry_seconds")
-	for counterContext, lastSampled := range s.counterLastSampledByContext {
+	for counterContext, lastSampled := range shard.counterLastSampledByContext {
 		if expirySeconds+lastSampled > float64(timestamp) {
 			sample := &metrics.MetricSample{
 				Name:       "",
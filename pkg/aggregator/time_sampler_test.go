@@ -9,6 +9,7 @@
 package aggregator
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"testing"
@@ -70,7 +71,7 @@ func testBucketSampling(t *testing.T, store *tags.Store) {
 		NameSuffix: "",
 	}
 
-	assert.Equal(t, 1, len(sampler.metricsByTimestamp))
+	assert.Equal(t, 1, len(sampler.shards[0].metricsByTimestamp))
 	if assert.Equal(t, 1, len(series)) {
 		metrics.AssertSerieEqual(t, expectedSerie, series[0])
 	}
@@ -180,7 +181,7 @@ func testCounterExpirySeconds(t *testing.T, store *tags.Store) {
 	sampler.sample(sampleCounter2, 1002.0)
 	sampler.sample(sampleGauge3, 1003.0)
 	// counterLastSampledByContext should be populated when a sample is added
-	assert.Equal(t, 2, len(sampler.counterLastSampledByContext))
+	assert.Equal(t, 2, len(sampler.shards[0].counterLastSampledByContext))
 
 	series, _ := flushSerie(sampler, 1010.0)
 
@@ -215,10 +216,10 @@ func testCounterExpirySeconds(t *testing.T, store *tags.Store) {
 	}
 	expectedSeries := metrics.Series{expectedSerie1, expectedSerie2, expectedSerie3}
 
-	require.Equal(t, 2, len(sampler.counterLastSampledByContext))
+	require.Equal(t, 2, len(sampler.shards[0].counterLastSampledByContext))
 	metrics.AssertSeriesEqual(t, expectedSeries, series)
-	assert.Equal(t, 1004.0, sampler.counterLastSampledByContext[contextCounter1])
-	assert.Equal(t, 1002.0, sampler.counterLastSampledByContext[contextCounter2])
+	assert.Equal(t, 1004.0, sampler.shards[0].counterLastSampledByContext[contextCounter1])
+	assert.Equal(t, 1002.0, sampler.shards[0].counterLastSampledByContext[contextCounter2])
 
 	sampleCounter1 = &metrics.MetricSample{
 		Name:       "my.counter1",
@@ -269,13 +270,13 @@ func testCounterExpirySeconds(t *testing.T, store *tags.Store) {
 	// Counter1 should have stopped reporting but the context is not expired yet
 	// Counter2 should still report
 	assert.Equal(t, 1, len(series))
-	assert.Equal(t, 1, len(sampler.counterLastSampledByContext))
+	assert.Equal(t, 1, len(sampler.shards[0].counterLastSampledByContext))
 	assert.Equal(t, 2, len(sampler.contextResolver.resolver.contextsByKey))
 
 	series, _ = flushSerie(sampler, 1800.0)
 	// Everything stopped reporting and is expired
 	assert.Equal(t, 0, len(series))
-	assert.Equal(t, 0, len(sampler.counterLastSampledByContext))
+	assert.Equal(t, 0, len(sampler.shards[0].counterLastSampledByContext))
 	assert.Equal(t, 0, len(sampler.contextResolver.resolver.contextsByKey))
 }
 func TestCounterExpirySeconds(t *testing.T) {
@@ -395,7 +396,7 @@ func testSketchBucketSampling(t *testing.T, store *tags.Store) {
 	}, flushed[0])
 
 	// The samples added after the flush time remains in the dist sampler
-	assert.Equal(t, 1, sampler.sketchMap.Len())
+	assert.Equal(t, 1, sampler.shards[0].sketchMap.Len())
 }
 func TestSketchBucketSampling(t *testing.T) {
 	testWithTagsStore(t, testSketchBucketSampling)
@@ -490,7 +491,7 @@ func testBucketSamplingWithSketchAndSeries(t *testing.T, store *tags.Store) {
 		NameSuffix: "",
 	}
 
-	assert.Equal(t, 1, len(sampler.metricsByTimestamp))
+	assert.Equal(t, 1, len(sampler.shards[0].metricsByTimestamp))
 	if assert.Equal(t, 1, len(series)) {
 		metrics.AssertSerieEqual(t, expectedSerie, series[0])
 	}
@@ -513,6 +514,65 @@ func TestBucketSamplingWithSketchAndSeries(t *testing.T) {
 	testWithTagsStore(t, testBucketSamplingWithSketchAndSeries)
 }
 
+func testFlushShards(t *testing.T, store *tags.Store) {
+	const flushShards = 4
+	sampler := NewTimeSamplerWithFlushShards(TimeSamplerID(0), 10, flushShards, store)
+	unsharded := NewTimeSampler(TimeSamplerID(1), 10, store)
+
+	for i := 0; i < 100; i++ {
+		for _, mType := range []metrics.MetricType{metrics.GaugeType, metrics.CounterType, metrics.DistributionType} {
+			sample := metrics.MetricSample{
+				Name:       fmt.Sprintf("my.metric.%d.%s", i, mType),
+				Value:      1,
+				Mtype:      mType,
+				Tags:       []string{"foo", "bar"},
+				SampleRate: 1,
+			}
+			for _, timestamp := range []float64{12345.0, 12355.0} {
+				sampler.sample(&sample, timestamp)
+				unsharded.sample(&sample, timestamp)
+			}
+		}
+	}
+
+	var series metrics.Series
+	var sketches metrics.SketchSeriesList
+	for shard := 0; shard < flushShards; shard++ {
+		var shardSeries metrics.Series
+		shardSketches := sampler.flushShard(shard, 12360.0, &shardSeries)
+		assert.NotEmpty(t, shardSeries, "every shard should get contexts")
+		series = append(series, shardSeries...)
+		sketches = append(sketches, shardSketches...)
+
+		// the other shards are not flushed yet
+		if shard < flushShards-1 {
+			assert.NotEmpty(t, sampler.shards[flushShards-1].metricsByTimestamp)
+		}
+	}
+
+	expectedSeries, expectedSketches := flushSerie(unsharded, 12360.0)
+	metrics.AssertSeriesEqual(t, expectedSeries, series)
+	require.Len(t, sketches, len(expectedSketches))
+	for _, sketch := range sketches {
+		require.Len(t, sketch.Points, 2)
+		assert.ElementsMatch(t, []int64{12340, 12350}, []int64{sketch.Points[0].Ts, sketch.Points[1].Ts})
+	}
+
+	// flushing a shard again only sends the zero values of its counters once
+	// per bucket
+	var shardSeries metrics.Series
+	sampler.flushShard(0, 12360.0, &shardSeries)
+	assert.Empty(t, shardSeries)
+	sampler.flushShard(0, 12370.0, &shardSeries)
+	for _, serie := range shardSeries {
+		require.Len(t, serie.Points, 1)
+		assert.Equal(t, metrics.Point{Ts: 12360.0, Value: 0}, serie.Points[0])
+	}
+}
+func TestFlushShards(t *testing.T) {
+	testWithTagsStore(t, testFlushShards)
+}
+
 func benchmarkTimeSampler(b *testing.B, store *tags.Store) {
 	sampler := testTimeSampler()
 
@@ -16,6 +16,8 @@ import (
 //  - receiving samples for the TimeSampler to process
 //  - receiving flush triggers to flush the series from the TimeSampler
 //    into a serializer
+//  - flushing one shard of the TimeSampler at every tick of its own ticker
+//    when the flush is staggered
 type timeSamplerWorker struct {
 	// parent sampler the timeSamplerWorker is responsible of
 	sampler *TimeSampler
@@ -39,11 +41,56 @@ type timeSamplerWorker struct {
 
 	// tagsStore shard used to store tag slices for this worker
 	tagsStore *tags.Store
+
+	// flushedShards receives the series and sketches of the shards flushed
+	// by the worker itself when the flush is staggered, nil otherwise.
+	flushedShards chan<- flushedShard
+}
+
+// flushedShard contains the series and sketches of a shard of a TimeSampler
+// flushed by its worker, they are serialized by the demultiplexer while the
+// worker goes back to processing samples.
+type flushedShard struct {
+	start    time.Time
+	series   metrics.Series
+	sketches metrics.SketchSeriesList
+}
+
+// shardBatch accumulates the flushed shards until they fill a series payload,
+// so that staggering the flush doesn't multiply the number of payloads.
+type shardBatch struct {
+	// start is the flush time of the oldest shard of the batch
+	start    time.Time
+	series   metrics.Series
+	sketches metrics.SketchSeriesList
+	points   int
+}
+
+func (b *shardBatch) add(shard flushedShard) {
+	if b.points == 0 {
+		b.start = shard.start
+	}
+	for _, serie := range shard.series {
+		b.points += len(serie.Points)
+	}
+	for _, sketch := range shard.sketches {
+		b.points += len(sketch.Points)
+	}
+	b.series = append(b.series, shard.series...)
+	b.sketches = append(b.sketches, shard.sketches...)
+}
+
+// take returns the content of the batch and empties it.
+func (b *shardBatch) take() (time.Time, metrics.Series, metrics.SketchSeriesList) {
+	start, series, sketches := b.start, b.series, b.sketches
+	*b = shardBatch{}
+	return start, series, sketches
 }
 
 func newTimeSamplerWorker(sampler *TimeSampler, flushInterval time.Duration, bufferSize int,
 	metricSamplePool *metrics.MetricSamplePool,
-	parallelSerialization FlushAndSerializeInParallel, tagsStore *tags.Store) *timeSamplerWorker {
+	parallelSerialization FlushAndSerializeInParallel, tagsStore *tags.Store,
+	flushedShards chan<- flushedShard) *timeSamplerWorker {
 	return &timeSamplerWorker{
 		sampler: sampler,
 
@@ -57,9 +104,20 @@ func newTimeSamplerWorker(sampler *TimeSampler, flushInterval time.Duration, buf
 		flushChan:   make(chan flushTrigger),
 
 		tagsStore: tagsStore,
+
+		flushedShards: flushedShards,
 	}
 }
 
+// staggeredFlushTicker returns a ticker firing once per shard of the sampler
+// during every flush interval, or nil if the flush is not staggered.
+func (w *timeSamplerWorker) staggeredFlushTicker() *time.Ticker {
+	if w.flushedShards == nil || w.flushInterval <= 0 || len(w.sampler.shards) < 2 {
+		return nil
+	}
+	return time.NewTicker(w.flushInterval / time.Duration(len(w.sampler.shards)))
+}
+
 // We process all receivend samples in the `select`, but we also process a flush action,
 // meaning that the time sampler does not process any sample while flushing.
 // Note that it was the same design in the BufferedAggregator (but at the aggregator level,
@@ -67,6 +125,13 @@ func newTimeSamplerWorker(sampler *TimeSampler, flushInterval time.Duration, buf
 // If we want to move to a design where we can flush while we are processing samples,
 // we could consider implementing double-buffering or locking for every sample reception.
 func (w *timeSamplerWorker) run() {
+	var shardTicker <-chan time.Time
+	if ticker := w.staggeredFlushTicker(); ticker != nil {
+		defer ticker.Stop()
+		shardTicker = ticker.C
+	}
+	nextShard := 0
+
 	for {
 		select {
 		case <-w.stopChan:
@@ -82,6 +147,14 @@ func (w *timeSamplerWorker) run() {
 		case trigger := <-w.flushChan:
 			w.triggerFlush(trigger)
 			w.tagsStore.Shrink()
+		case t := <-shardTicker:
+			if stopped := w.flushShard(nextShard, t); stopped {
+				return
+			}
+			nextShard = (nextShard + 1) % len(w.sampler.shards)
+			if nextShard == 0 {
+				w.tagsStore.Shrink()
+			}
 		}
 	}
 }
@@ -108,3 +181,22 @@ func (w *timeSamplerWorker) triggerFlush(trigger flushTrigger) {
 	}
 	trigger.blockChan <- struct{}{}
 }
+
+// flushShard flushes a single shard of the sampler and hands off its series
+// and sketches to the demultiplexer. It only blocks when the demultiplexer is
+// late serializing the previous shards, it returns true if the worker has
+// been stopped meanwhile.
+func (w *timeSamplerWorker) flushShard(shardID int, t time.Time) bool {
+	var series metrics.Series
+	sketches := w.sampler.flushShard(shardID, float64(t.Unix()), &series)
+	if len(series) == 0 && len(sketches) == 0 {
+		return false
+	}
+
+	select {
+	case w.flushedShards <- flushedShard{start: t, series: series, sketches: sketches}:
+		return false
+	case <-w.stopChan:
+		return true
+	}
+}
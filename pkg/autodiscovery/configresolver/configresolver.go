@@ -157,6 +157,14 @@ func resolveDataWithTemplateVars(ctx context.Context, data integration.Data, svc
 
 	templateVars := tmplvar.Parse(data)
 	for _, tVar := range templateVars {
+		if isExpression(tVar.Raw) {
+			resolvedVar, err := resolveExpression(ctx, tVar.Raw, svc)
+			if err != nil {
+				return res, err
+			}
+			res = bytes.Replace(res, tVar.Raw, resolvedVar, -1)
+			continue
+		}
 		if f, found := templateVariables[string(tVar.Name)]; found {
 			resolvedVar, err := f(ctx, tVar.Key, svc)
 			if err != nil {
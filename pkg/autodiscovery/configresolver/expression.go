@@ -0,0 +1,466 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// expressionRegex matches the template variables which are expressions, e.g.
+// `%%pod.labels["app.kubernetes.io/name"] | lower%%`: a variable followed by a field, a key or a function. The other
+// template variables, e.g. `%%port_metrics%%`, keep their legacy meaning.
+var expressionRegex = regexp.MustCompile(`^%%\s*(container|pod|host|port|pid|hostname|extra|kube)\s*[.\[|]`)
+
+// getWorkloadmetaStore returns the store the entity fields of the expressions are read from, tests override it
+var getWorkloadmetaStore = workloadmeta.GetGlobalStore
+
+// expression is a parsed template variable expression: a path, e.g. `container.env["JAVA_OPTS"]`, whose value is
+// piped into functions, e.g. `| default "none" | lower`
+type expression struct {
+	path      []string
+	functions []expressionFunction
+}
+
+type expressionFunction struct {
+	name string
+	args []string
+}
+
+// expressionFunctions gives the number of arguments of the functions of the expressions
+var expressionFunctions = map[string]int{
+	"default":    1,
+	"lower":      0,
+	"upper":      0,
+	"trim":       0,
+	"trimprefix": 1,
+	"trimsuffix": 1,
+	"replace":    2,
+}
+
+// missingValueError is the error of a value the service doesn't have, e.g. a label which isn't set. It is replaced
+// by the argument of a `default` function.
+type missingValueError struct {
+	err error
+}
+
+func (e missingValueError) Error() string {
+	return e.err.Error()
+}
+
+func missingValuef(format string, args ...interface{}) error {
+	return missingValueError{err: fmt.Errorf(format, args...)}
+}
+
+// isExpression returns whether a raw template variable, including its `%%` delimiters, is an expression
+func isExpression(raw []byte) bool {
+	return expressionRegex.Match(raw)
+}
+
+// resolveExpression resolves a raw template variable expression, including its `%%` delimiters, for a service
+func resolveExpression(ctx context.Context, raw []byte, svc listeners.Service) ([]byte, error) {
+	expr, err := parseExpression(string(raw[2 : len(raw)-2]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template variable %s: %s", raw, err)
+	}
+	value, err := expr.evaluate(ctx, svc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve template variable %s for service %s: %s", raw, svc.GetServiceID(), err)
+	}
+	return []byte(value), nil
+}
+
+func (e *expression) evaluate(ctx context.Context, svc listeners.Service) (string, error) {
+	value, err := resolvePath(ctx, e.path, svc)
+	if err != nil && !errors.As(err, &missingValueError{}) {
+		return "", err
+	}
+	for _, f := range e.functions {
+		if f.name == "default" {
+			if err != nil || value == "" {
+				value, err = f.args[0], nil
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		switch f.name {
+		case "lower":
+			value = strings.ToLower(value)
+		case "upper":
+			value = strings.ToUpper(value)
+		case "trim":
+			value = strings.TrimSpace(value)
+		case "trimprefix":
+			value = strings.TrimPrefix(value, f.args[0])
+		case "trimsuffix":
+			value = strings.TrimSuffix(value, f.args[0])
+		case "replace":
+			value = strings.ReplaceAll(value, f.args[0], f.args[1])
+		}
+	}
+	return value, err
+}
+
+// resolvePath returns the value of a path. The legacy template variables are available with their key as the only
+// path element, e.g. `port["metrics"]` resolves like `%%port_metrics%%`.
+func resolvePath(ctx context.Context, path []string, svc listeners.Service) (string, error) {
+	root, elements := path[0], path[1:]
+	switch root {
+	case "container":
+		return resolveContainerPath(elements, svc)
+	case "pod":
+		return resolvePodPath(elements, svc)
+	}
+
+	getter, found := templateVariables[root]
+	if !found {
+		return "", fmt.Errorf("unknown variable %q", root)
+	}
+	if len(elements) > 1 {
+		return "", fmt.Errorf("%q accepts at most one key", root)
+	}
+	key := ""
+	if len(elements) == 1 {
+		key = elements[0]
+	}
+	value, err := getter(ctx, []byte(key), svc)
+	if err != nil {
+		return "", missingValueError{err: err}
+	}
+	return string(value), nil
+}
+
+func resolveContainerPath(path []string, svc listeners.Service) (string, error) {
+	if len(path) == 0 {
+		return "", errors.New("a container field is required")
+	}
+	field, elements := path[0], path[1:]
+	if field != "image" && field != "labels" && field != "annotations" && field != "env" {
+		if len(elements) != 0 {
+			return "", fmt.Errorf("container field %q has no key", field)
+		}
+	}
+
+	container, err := getContainer(svc)
+	if err != nil {
+		return "", err
+	}
+	switch field {
+	case "id":
+		return container.ID, nil
+	case "name":
+		return container.Name, nil
+	case "hostname":
+		return container.Hostname, nil
+	case "runtime":
+		return string(container.Runtime), nil
+	case "image":
+		return resolveImagePath(elements, container.Image)
+	case "labels":
+		return lookupKey("container label", container.Labels, elements)
+	case "annotations":
+		return lookupKey("container annotation", container.Annotations, elements)
+	case "env":
+		return lookupKey("container environment variable", container.EnvVars, elements)
+	default:
+		return "", fmt.Errorf("unknown container field %q", field)
+	}
+}
+
+func resolveImagePath(path []string, image workloadmeta.ContainerImage) (string, error) {
+	if len(path) == 0 {
+		return image.RawName, nil
+	}
+	if len(path) > 1 {
+		return "", errors.New("container image fields have no key")
+	}
+	switch path[0] {
+	case "id":
+		return image.ID, nil
+	case "name":
+		return image.Name, nil
+	case "short_name":
+		return image.ShortName, nil
+	case "tag":
+		return image.Tag, nil
+	default:
+		return "", fmt.Errorf("unknown container image field %q", path[0])
+	}
+}
+
+func resolvePodPath(path []string, svc listeners.Service) (string, error) {
+	if len(path) == 0 {
+		return "", errors.New("a pod field is required")
+	}
+	field, elements := path[0], path[1:]
+	if field != "labels" && field != "annotations" && field != "namespace_labels" {
+		if len(elements) != 0 {
+			return "", fmt.Errorf("pod field %q has no key", field)
+		}
+	}
+
+	pod, err := getPod(svc)
+	if err != nil {
+		return "", err
+	}
+	switch field {
+	case "uid":
+		return pod.ID, nil
+	case "name":
+		return pod.Name, nil
+	case "namespace":
+		return pod.Namespace, nil
+	case "ip":
+		return pod.IP, nil
+	case "phase":
+		return pod.Phase, nil
+	case "priority_class":
+		return pod.PriorityClass, nil
+	case "labels":
+		return lookupKey("pod label", pod.Labels, elements)
+	case "annotations":
+		return lookupKey("pod annotation", pod.Annotations, elements)
+	case "namespace_labels":
+		return lookupKey("namespace label", pod.NamespaceLabels, elements)
+	default:
+		return "", fmt.Errorf("unknown pod field %q", field)
+	}
+}
+
+func lookupKey(description string, values map[string]string, path []string) (string, error) {
+	if len(path) != 1 {
+		return "", fmt.Errorf("a %s requires exactly one key", description)
+	}
+	value, found := values[path[0]]
+	if !found {
+		return "", missingValuef("%s %q not found", description, path[0])
+	}
+	return value, nil
+}
+
+// getContainer returns the workloadmeta container of a container service
+func getContainer(svc listeners.Service) (*workloadmeta.Container, error) {
+	entity := svc.GetTaggerEntity()
+	if !strings.HasPrefix(entity, containers.ContainerEntityPrefix) {
+		return nil, missingValuef("service %s is not a container", svc.GetServiceID())
+	}
+	container, err := getWorkloadmetaStore().GetContainer(strings.TrimPrefix(entity, containers.ContainerEntityPrefix))
+	if err != nil {
+		return nil, missingValuef("container not found: %s", err)
+	}
+	return container, nil
+}
+
+// getPod returns the workloadmeta pod of a pod service, or of the pod of a container service
+func getPod(svc listeners.Service) (*workloadmeta.KubernetesPod, error) {
+	entity := svc.GetTaggerEntity()
+	var pod *workloadmeta.KubernetesPod
+	var err error
+	switch {
+	case strings.HasPrefix(entity, kubelet.KubePodTaggerEntityPrefix):
+		pod, err = getWorkloadmetaStore().GetKubernetesPod(strings.TrimPrefix(entity, kubelet.KubePodTaggerEntityPrefix))
+	case strings.HasPrefix(entity, containers.ContainerEntityPrefix):
+		pod, err = getWorkloadmetaStore().GetKubernetesPodForContainer(strings.TrimPrefix(entity, containers.ContainerEntityPrefix))
+	default:
+		return nil, missingValuef("service %s is neither a pod nor a container", svc.GetServiceID())
+	}
+	if err != nil {
+		return nil, missingValuef("pod not found: %s", err)
+	}
+	return pod, nil
+}
+
+// parseExpression parses a template variable expression:
+//
+//	expression := path ( "|" function )*
+//	path       := identifier ( "." identifier | "[" ( string | number ) "]" )*
+//	function   := identifier ( string | number )*
+//
+// Strings are enclosed in single or double quotes, a backslash escapes the next character.
+func parseExpression(s string) (*expression, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens}
+
+	expr := &expression{}
+	root, err := p.expect(tokenIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	expr.path = append(expr.path, root)
+	for p.peek().kind == tokenDot || p.peek().kind == tokenLeftBracket {
+		if p.next().kind == tokenDot {
+			field, err := p.expect(tokenIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			expr.path = append(expr.path, field)
+			continue
+		}
+		key := p.next()
+		if key.kind != tokenString && key.kind != tokenNumber {
+			return nil, fmt.Errorf("expected a string or a number, got %s", key)
+		}
+		if _, err := p.expect(tokenRightBracket); err != nil {
+			return nil, err
+		}
+		expr.path = append(expr.path, key.value)
+	}
+
+	for p.peek().kind == tokenPipe {
+		p.next()
+		name, err := p.expect(tokenIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		f := expressionFunction{name: name}
+		for p.peek().kind == tokenString || p.peek().kind == tokenNumber {
+			f.args = append(f.args, p.next().value)
+		}
+		argCount, found := expressionFunctions[name]
+		if !found {
+			return nil, fmt.Errorf("unknown function %q", name)
+		}
+		if len(f.args) != argCount {
+			return nil, fmt.Errorf("function %q expects %d argument(s), got %d", name, argCount, len(f.args))
+		}
+		expr.functions = append(expr.functions, f)
+	}
+
+	if p.peek().kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected %s", p.peek())
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenDot
+	tokenLeftBracket
+	tokenRightBracket
+	tokenPipe
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+var punctuation = map[byte]tokenKind{
+	'.': tokenDot,
+	'[': tokenLeftBracket,
+	']': tokenRightBracket,
+	'|': tokenPipe,
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case punctuation[c] != tokenEnd:
+			tokens = append(tokens, token{kind: punctuation[c], value: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				sb.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: sb.String()})
+			i = j + 1
+		case isDigit(c) || c == '-':
+			j := i + 1
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: s[i:j]})
+			i = j
+		case isIdentifierChar(c):
+			j := i + 1
+			for j < len(s) && (isIdentifierChar(s[j]) || isDigit(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+type expressionParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *expressionParser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEnd}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() token {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+func (p *expressionParser) expect(kind tokenKind) (string, error) {
+	t := p.next()
+	if t.kind != kind {
+		return "", fmt.Errorf("unexpected %s", t)
+	}
+	return t.value, nil
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	workloadmetatesting "github.com/DataDog/datadog-agent/pkg/workloadmeta/testing"
+)
+
+func setupExpressionStore(t *testing.T) {
+	store := workloadmetatesting.NewStore()
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "abc"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "redis",
+			Labels: map[string]string{"com.example.tier": "cache"},
+		},
+		EnvVars:  map[string]string{"REDIS_PORT": "6380"},
+		Hostname: "redis-host",
+		Image: workloadmeta.ContainerImage{
+			RawName:   "docker.io/library/redis:7.0",
+			Name:      "docker.io/library/redis",
+			ShortName: "redis",
+			Tag:       "7.0",
+		},
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+	})
+	store.Set(&workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        "redis-0",
+			Namespace:   "default",
+			Labels:      map[string]string{"app.kubernetes.io/name": "Redis"},
+			Annotations: map[string]string{"example.com/scheme": " HTTPS "},
+		},
+		Containers: []workloadmeta.OrchestratorContainer{{ID: "abc", Name: "redis"}},
+		IP:         "10.0.0.5",
+	})
+
+	getWorkloadmetaStore = func() workloadmeta.Store { return store }
+	t.Cleanup(func() { getWorkloadmetaStore = workloadmeta.GetGlobalStore })
+}
+
+func TestIsExpression(t *testing.T) {
+	for raw, expected := range map[string]bool{
+		`%%host%%`:                        false,
+		`%%port_metrics%%`:                false,
+		`%%host_my.network%%`:             false,
+		`%%env_REDIS_PORT%%`:              false,
+		`%%{TIMESTAMP}%%`:                 false,
+		`%%host | lower%%`:                true,
+		`%% port["metrics"] %%`:           true,
+		`%%pod.labels['app']%%`:           true,
+		`%%container.image.short_name%%`:  true,
+		`%%kube.namespace | upper%%`:      true,
+		`%%unknown.field | default "x"%%`: false,
+	} {
+		assert.Equal(t, expected, isExpression([]byte(raw)), raw)
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	expr, err := parseExpression(` pod.labels["app.kubernetes.io/name"] | default 'my \'app\'' | replace "-" "_" `)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pod", "labels", "app.kubernetes.io/name"}, expr.path)
+	assert.Equal(t, []expressionFunction{
+		{name: "default", args: []string{"my 'app'"}},
+		{name: "replace", args: []string{"-", "_"}},
+	}, expr.functions)
+
+	expr, err = parseExpression(`port[0] | default 9090`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"port", "0"}, expr.path)
+	assert.Equal(t, []expressionFunction{{name: "default", args: []string{"9090"}}}, expr.functions)
+
+	for s, expectedErr := range map[string]string{
+		`pod.labels["app`:           "unterminated string",
+		`pod.labels[app]`:           `expected a string or a number, got "app"`,
+		`pod.labels["app"`:          "unexpected end of expression",
+		`pod. | lower`:              `unexpected "|"`,
+		`pod.name | capitalize`:     `unknown function "capitalize"`,
+		`pod.name | default`:        `function "default" expects 1 argument(s), got 0`,
+		`pod.name | lower "x"`:      `function "lower" expects 0 argument(s), got 1`,
+		`pod.name pod.namespace`:    `unexpected "pod"`,
+		`pod.name | trimprefix $`:   `unexpected character '$'`,
+		`container.env["A"] "B"`:    `unexpected string "B"`,
+		`pod.name | replace "a" 1 `: "",
+	} {
+		_, err := parseExpression(s)
+		if expectedErr == "" {
+			assert.NoError(t, err, s)
+			continue
+		}
+		assert.EqualError(t, err, expectedErr, s)
+	}
+}
+
+func TestResolveExpression(t *testing.T) {
+	setupExpressionStore(t)
+	ctx := context.Background()
+
+	containerSvc := &dummyService{
+		ID:          "container_id://abc",
+		Hosts:       map[string]string{"pod": "10.0.0.5"},
+		Ports:       []listeners.ContainerPort{{Port: 8080, Name: "http"}, {Port: 9121, Name: "metrics"}},
+		ExtraConfig: map[string]string{"namespace": "default"},
+	}
+	podSvc := &dummyService{ID: "kubernetes_pod_uid://pod-uid"}
+	processSvc := &dummyService{ID: "process://42", Pid: 42}
+
+	testCases := []struct {
+		raw         string
+		svc         listeners.Service
+		expected    string
+		errorString string
+	}{
+		{raw: `%%container.name%%`, svc: containerSvc, expected: "redis"},
+		{raw: `%%container.id%%`, svc: containerSvc, expected: "abc"},
+		{raw: `%%container.hostname%%`, svc: containerSvc, expected: "redis-host"},
+		{raw: `%%container.runtime%%`, svc: containerSvc, expected: "containerd"},
+		{raw: `%%container.image%%`, svc: containerSvc, expected: "docker.io/library/redis:7.0"},
+		{raw: `%%container.image.short_name%%`, svc: containerSvc, expected: "redis"},
+		{raw: `%%container.image.tag%%`, svc: containerSvc, expected: "7.0"},
+		{raw: `%%container.labels["com.example.tier"]%%`, svc: containerSvc, expected: "cache"},
+		{raw: `%%container.env["REDIS_PORT"]%%`, svc: containerSvc, expected: "6380"},
+		{raw: `%%pod.name%%`, svc: containerSvc, expected: "redis-0"},
+		{raw: `%%pod.ip%%`, svc: podSvc, expected: "10.0.0.5"},
+		{raw: `%%pod.labels["app.kubernetes.io/name"] | lower%%`, svc: containerSvc, expected: "redis"},
+		{raw: `%%pod.annotations["example.com/scheme"] | trim | lower%%`, svc: podSvc, expected: "https"},
+		{raw: `%%pod.labels["team"] | default "none"%%`, svc: podSvc, expected: "none"},
+		{raw: `%%pod.namespace | default "none" | upper%%`, svc: containerSvc, expected: "DEFAULT"},
+		{raw: `%%port["metrics"] | default 9090%%`, svc: containerSvc, expected: "9121"},
+		{raw: `%%port["prometheus"] | default 9090%%`, svc: containerSvc, expected: "9090"},
+		{raw: `%%port[0]%%`, svc: containerSvc, expected: "8080"},
+		{raw: `%%port | default 9090%%`, svc: podSvc, expected: "9090"},
+		{raw: `%%host | trimsuffix ".5"%%`, svc: containerSvc, expected: "10.0.0"},
+		{raw: `%%kube["namespace"] | replace "def" "DEF"%%`, svc: containerSvc, expected: "DEFault"},
+		{raw: `%%pid | trimprefix "4"%%`, svc: processSvc, expected: "2"},
+		{raw: `%%pod.name | default "none"%%`, svc: processSvc, expected: "none"},
+		{
+			raw:         `%%pod.labels["team"] | lower%%`,
+			svc:         podSvc,
+			errorString: `unable to resolve template variable %%pod.labels["team"] | lower%% for service kubernetes_pod_uid://pod-uid: pod label "team" not found`,
+		},
+		{
+			raw:         `%%container.name%%`,
+			svc:         processSvc,
+			errorString: `unable to resolve template variable %%container.name%% for service process://42: service process://42 is not a container`,
+		},
+		{
+			raw:         `%%port["prometheus"]%%`,
+			svc:         containerSvc,
+			errorString: `unable to resolve template variable %%port["prometheus"]%% for service container_id://abc: port prometheus not found, skipping container container_id://abc`,
+		},
+		{
+			raw:         `%%container.labels | default "x"%%`,
+			svc:         containerSvc,
+			errorString: `unable to resolve template variable %%container.labels | default "x"%% for service container_id://abc: a container label requires exactly one key`,
+		},
+		{
+			raw:         `%%pod.owner | default "x"%%`,
+			svc:         podSvc,
+			errorString: `unable to resolve template variable %%pod.owner | default "x"%% for service kubernetes_pod_uid://pod-uid: unknown pod field "owner"`,
+		},
+		{
+			raw:         `%%pod.name.first%%`,
+			svc:         podSvc,
+			errorString: `unable to resolve template variable %%pod.name.first%% for service kubernetes_pod_uid://pod-uid: pod field "name" has no key`,
+		},
+		{
+			raw:         `%%host.a.b%%`,
+			svc:         containerSvc,
+			errorString: `unable to resolve template variable %%host.a.b%% for service container_id://abc: "host" accepts at most one key`,
+		},
+		{
+			raw:         `%%pod.labels[%%`,
+			svc:         podSvc,
+			errorString: `invalid template variable %%pod.labels[%%: expected a string or a number, got end of expression`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			value, err := resolveExpression(ctx, []byte(tc.raw), tc.svc)
+			if tc.errorString != "" {
+				assert.EqualError(t, err, tc.errorString)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(value))
+		})
+	}
+}
+
+func TestResolveWithExpressions(t *testing.T) {
+	setupExpressionStore(t)
+
+	tpl := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances: []integration.Data{integration.Data(`host: %%host%%
+port: %%port["metrics"] | default 9090%%
+service: %%pod.labels['app.kubernetes.io/name'] | lower%%`)},
+	}
+	svc := &dummyService{
+		ID:    "container_id://abc",
+		Hosts: map[string]string{"pod": "10.0.0.5"},
+		Ports: []listeners.ContainerPort{{Port: 6379, Name: "redis"}},
+	}
+
+	config, _, err := Resolve(tpl, svc)
+	require.NoError(t, err)
+	assert.Equal(t, integration.Data("host: 10.0.0.5\nport: 9090\nservice: redis\ntags:\n- foo:bar\n"), config.Instances[0])
+
+	tpl.Instances = []integration.Data{integration.Data(`service: %%pod.labels["team"]%%`)}
+	_, _, err = Resolve(tpl, svc)
+	assert.EqualError(t, err, `unable to resolve template variable %%pod.labels["team"]%% for service container_id://abc: pod label "team" not found`)
+}
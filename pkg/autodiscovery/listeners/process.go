@@ -0,0 +1,389 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics/provider"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	defaultProcessDiscoveryInterval = 60
+	processServicePrefix            = "process://"
+	processHostKey                  = "host"
+
+	// containerIDCacheValidity is the cache validity of the container ID of a process
+	containerIDCacheValidity = time.Minute
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// processPattern gives an AD identifier to the processes whose name or command line match a regular expression
+type processPattern struct {
+	ADIdentifier string `mapstructure:"ad_identifier"`
+	Name         string `mapstructure:"name"`
+	Cmdline      string `mapstructure:"cmdline"`
+
+	nameRegex    *regexp.Regexp
+	cmdlineRegex *regexp.Regexp
+}
+
+// processListenerConfig is the `process_listener` configuration
+type processListenerConfig struct {
+	DiscoveryInterval int              `mapstructure:"discovery_interval"`
+	Patterns          []processPattern `mapstructure:"patterns"`
+}
+
+// getListeningSockets returns the TCP sockets the processes listen on, tests override it
+var getListeningSockets = procutil.ListeningSockets
+
+// containerIDForPID returns the container ID of a process, tests override it
+var containerIDForPID = func(pid int) (string, error) {
+	return provider.GetProvider().GetMetaCollector().GetContainerIDForPID(pid, containerIDCacheValidity)
+}
+
+// ProcessListener discovers the processes running on the host. The processes listening on TCP ports, or matching one
+// of the configured patterns, are services identified by their process name, their executable name, and the AD
+// identifiers of the patterns they match. The containerized processes are left to the container listeners.
+type ProcessListener struct {
+	sync.RWMutex
+	newService chan<- Service
+	delService chan<- Service
+	stop       chan bool
+	config     processListenerConfig
+	probe      procutil.Probe
+	services   map[string]*ProcessService
+}
+
+// ProcessService is a process discovered by the ProcessListener
+type ProcessService struct {
+	serviceID     string
+	adIdentifiers []string
+	pid           int
+	createTime    int64
+	host          string
+	ports         []ContainerPort
+}
+
+// Make sure ProcessService implements the Service interface
+var _ Service = &ProcessService{}
+
+// NewProcessListener creates a ProcessListener
+func NewProcessListener(Config) (ServiceListener, error) {
+	var listenerConfig processListenerConfig
+	if err := config.Datadog.UnmarshalKey("process_listener", &listenerConfig); err != nil {
+		return nil, fmt.Errorf("invalid process_listener configuration: %s", err)
+	}
+	if listenerConfig.DiscoveryInterval <= 0 {
+		listenerConfig.DiscoveryInterval = defaultProcessDiscoveryInterval
+	}
+	for i := range listenerConfig.Patterns {
+		if err := listenerConfig.Patterns[i].compile(); err != nil {
+			return nil, fmt.Errorf("invalid process_listener pattern %d: %s", i, err)
+		}
+	}
+
+	return &ProcessListener{
+		stop:     make(chan bool),
+		config:   listenerConfig,
+		probe:    procutil.NewProcessProbe(),
+		services: make(map[string]*ProcessService),
+	}, nil
+}
+
+func (p *processPattern) compile() error {
+	if p.ADIdentifier == "" {
+		return fmt.Errorf("`ad_identifier` is required")
+	}
+	if p.Name == "" && p.Cmdline == "" {
+		return fmt.Errorf("one of `name` or `cmdline` is required")
+	}
+	var err error
+	if p.Name != "" {
+		if p.nameRegex, err = regexp.Compile(p.Name); err != nil {
+			return err
+		}
+	}
+	if p.Cmdline != "" {
+		if p.cmdlineRegex, err = regexp.Compile(p.Cmdline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches returns whether a process matches all the regular expressions of a pattern
+func (p *processPattern) matches(proc *procutil.Process) bool {
+	if p.nameRegex != nil && !p.nameRegex.MatchString(proc.Name) {
+		return false
+	}
+	if p.cmdlineRegex != nil && !p.cmdlineRegex.MatchString(strings.Join(proc.Cmdline, " ")) {
+		return false
+	}
+	return true
+}
+
+// Listen starts the periodic discovery of the processes
+func (l *ProcessListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go l.run()
+}
+
+// Stop queues a shutdown of ProcessListener
+func (l *ProcessListener) Stop() {
+	l.stop <- true
+}
+
+func (l *ProcessListener) run() {
+	ticker := time.NewTicker(time.Duration(l.config.DiscoveryInterval) * time.Second)
+	defer ticker.Stop()
+	defer l.probe.Close()
+
+	for {
+		l.discover()
+
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover lists the processes, and creates and removes the services of the processes that appeared or exited
+// since the previous discovery
+func (l *ProcessListener) discover() {
+	procs, err := l.probe.ProcessesByPID(time.Now(), false)
+	if err != nil {
+		log.Warnf("Unable to list the processes: %s", err)
+		return
+	}
+	pids := make([]int32, 0, len(procs))
+	for pid := range procs {
+		pids = append(pids, pid)
+	}
+	sockets := getListeningSockets(pids)
+
+	discovered := make(map[int32]*ProcessService)
+	for pid, proc := range procs {
+		svc := l.buildService(proc, sockets[pid])
+		if svc == nil {
+			continue
+		}
+		if containerID, err := containerIDForPID(int(pid)); err != nil {
+			log.Debugf("Unable to get the container ID of process %d: %s", pid, err)
+		} else if containerID != "" {
+			continue
+		}
+		discovered[pid] = svc
+	}
+	// the children of a service process with the same name are its workers, e.g. the nginx worker processes
+	// share the listening sockets of the master process; only the parent process is a service
+	var workers []int32
+	for pid := range discovered {
+		ppid := procs[pid].Ppid
+		if _, ok := discovered[ppid]; ok && procs[ppid].Name == procs[pid].Name {
+			workers = append(workers, pid)
+		}
+	}
+	for _, pid := range workers {
+		delete(discovered, pid)
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	for serviceID, svc := range l.services {
+		current, ok := discovered[int32(svc.pid)]
+		if !ok || !svc.equal(current) {
+			delete(l.services, serviceID)
+			l.delService <- svc
+		}
+	}
+	for _, svc := range discovered {
+		if _, ok := l.services[svc.serviceID]; ok {
+			continue
+		}
+		log.Debugf("Process listener discovered process %d with AD identifiers %v", svc.pid, svc.adIdentifiers)
+		l.services[svc.serviceID] = svc
+		l.newService <- svc
+	}
+}
+
+// buildService returns the service of a process, or nil if the process is not a service
+func (l *ProcessListener) buildService(proc *procutil.Process, sockets []procutil.ListeningSocket) *ProcessService {
+	var patternIdentifiers []string
+	for i := range l.config.Patterns {
+		if l.config.Patterns[i].matches(proc) {
+			patternIdentifiers = append(patternIdentifiers, l.config.Patterns[i].ADIdentifier)
+		}
+	}
+	if len(patternIdentifiers) == 0 && len(sockets) == 0 {
+		return nil
+	}
+	adIdentifiers := appendIdentifier(nil, proc.Name)
+	if proc.Exe != "" {
+		adIdentifiers = appendIdentifier(adIdentifiers, filepath.Base(proc.Exe))
+	}
+	for _, identifier := range patternIdentifiers {
+		adIdentifiers = appendIdentifier(adIdentifiers, identifier)
+	}
+
+	svc := &ProcessService{
+		serviceID:     fmt.Sprintf("%s%d", processServicePrefix, proc.Pid),
+		adIdentifiers: adIdentifiers,
+		pid:           int(proc.Pid),
+		host:          listeningHost(sockets),
+	}
+	if proc.Stats != nil {
+		svc.createTime = proc.Stats.CreateTime
+	}
+	ports := make(map[int]struct{})
+	for _, socket := range sockets {
+		if _, ok := ports[socket.Port]; ok {
+			continue
+		}
+		ports[socket.Port] = struct{}{}
+		svc.ports = append(svc.ports, ContainerPort{Port: socket.Port, Name: fmt.Sprintf("p%d", socket.Port)})
+	}
+	sort.Slice(svc.ports, func(i, j int) bool { return svc.ports[i].Port < svc.ports[j].Port })
+	return svc
+}
+
+func appendIdentifier(identifiers []string, identifier string) []string {
+	if identifier == "" {
+		return identifiers
+	}
+	for _, existing := range identifiers {
+		if existing == identifier {
+			return identifiers
+		}
+	}
+	return append(identifiers, identifier)
+}
+
+// listeningHost returns the address to connect to a process: the loopback address when it listens on all
+// the interfaces or on the loopback interface, its listening address otherwise. IPv4 addresses are preferred.
+func listeningHost(sockets []procutil.ListeningSocket) string {
+	host := ""
+	for _, socket := range sockets {
+		ip := socket.IP
+		isV4 := ip.To4() != nil
+		if ip.IsUnspecified() || ip.IsLoopback() {
+			if isV4 {
+				return "127.0.0.1"
+			}
+			ip = net.IPv6loopback
+		}
+		if host == "" || (isV4 && net.ParseIP(host).To4() == nil) {
+			host = ip.String()
+		}
+	}
+	if host == "" {
+		return "127.0.0.1"
+	}
+	return host
+}
+
+// equal returns whether two services are the same process, with the same identifiers, host and ports
+func (s *ProcessService) equal(other *ProcessService) bool {
+	if s.serviceID != other.serviceID || s.createTime != other.createTime || s.host != other.host ||
+		len(s.adIdentifiers) != len(other.adIdentifiers) || len(s.ports) != len(other.ports) {
+		return false
+	}
+	for i := range s.adIdentifiers {
+		if s.adIdentifiers[i] != other.adIdentifiers[i] {
+			return false
+		}
+	}
+	for i := range s.ports {
+		if s.ports[i] != other.ports[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetServiceID returns the unique entity ID linked to that service
+func (s *ProcessService) GetServiceID() string {
+	return s.serviceID
+}
+
+// GetTaggerEntity returns the unique entity ID linked to that service
+func (s *ProcessService) GetTaggerEntity() string {
+	return s.serviceID
+}
+
+// GetADIdentifiers returns the process name, the executable name and the identifiers of the matched patterns
+func (s *ProcessService) GetADIdentifiers(context.Context) ([]string, error) {
+	return s.adIdentifiers, nil
+}
+
+// GetHosts returns the address the process listens on
+func (s *ProcessService) GetHosts(context.Context) (map[string]string, error) {
+	return map[string]string{processHostKey: s.host}, nil
+}
+
+// GetPorts returns the TCP ports the process listens on
+func (s *ProcessService) GetPorts(context.Context) ([]ContainerPort, error) {
+	return s.ports, nil
+}
+
+// GetTags returns the tags of the process, collected by the workloadmeta process collector
+func (s *ProcessService) GetTags() ([]string, string, error) {
+	return tagger.TagWithHash(s.GetTaggerEntity(), tagger.ChecksCardinality)
+}
+
+// GetPid returns the process identifier
+func (s *ProcessService) GetPid(context.Context) (int, error) {
+	return s.pid, nil
+}
+
+// GetHostname returns nothing - not supported
+func (s *ProcessService) GetHostname(context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+// IsReady returns true
+func (s *ProcessService) IsReady(context.Context) bool {
+	return true
+}
+
+// GetCheckNames returns nil
+func (s *ProcessService) GetCheckNames(context.Context) []string {
+	return nil
+}
+
+// HasFilter returns false on processes
+func (s *ProcessService) HasFilter(containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig isn't supported
+func (s *ProcessService) GetExtraConfig([]byte) ([]byte, error) {
+	return []byte{}, ErrNotSupported
+}
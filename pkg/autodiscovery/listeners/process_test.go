@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+type fakeProcessProbe struct {
+	procutil.Probe
+	procs map[int32]*procutil.Process
+}
+
+func (p *fakeProcessProbe) ProcessesByPID(time.Time, bool) (map[int32]*procutil.Process, error) {
+	return p.procs, nil
+}
+
+func (p *fakeProcessProbe) Close() {}
+
+func newTestProcess(pid, ppid int32, name, exe string, createTime int64, cmdline ...string) *procutil.Process {
+	return &procutil.Process{
+		Pid:     pid,
+		Ppid:    ppid,
+		Name:    name,
+		Exe:     exe,
+		Cmdline: cmdline,
+		Stats:   &procutil.Stats{CreateTime: createTime},
+	}
+}
+
+func TestProcessListener(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("process_listener", map[string]interface{}{
+		"patterns": []map[string]interface{}{
+			{"ad_identifier": "kafka", "cmdline": `kafka\.Kafka`},
+		},
+	})
+	defer mockConfig.Set("process_listener", nil)
+
+	sockets := map[int32][]procutil.ListeningSocket{
+		// redis listening on the loopback interfaces
+		10: {{IP: net.ParseIP("127.0.0.1"), Port: 6379}, {IP: net.ParseIP("::1"), Port: 6379}},
+		// nginx master and worker sharing the listening sockets
+		20: {{IP: net.ParseIP("0.0.0.0"), Port: 443}, {IP: net.ParseIP("0.0.0.0"), Port: 80}},
+		21: {{IP: net.ParseIP("0.0.0.0"), Port: 443}, {IP: net.ParseIP("0.0.0.0"), Port: 80}},
+		// postgres listening on a private address
+		30: {{IP: net.ParseIP("10.0.0.5"), Port: 5432}},
+	}
+	defer func(f func([]int32) map[int32][]procutil.ListeningSocket) { getListeningSockets = f }(getListeningSockets)
+	getListeningSockets = func([]int32) map[int32][]procutil.ListeningSocket { return sockets }
+	defer func(f func(int) (string, error)) { containerIDForPID = f }(containerIDForPID)
+	containerIDForPID = func(pid int) (string, error) {
+		if pid == 50 {
+			return "3c9a6bd2d4f7", nil
+		}
+		return "", nil
+	}
+
+	probe := &fakeProcessProbe{procs: map[int32]*procutil.Process{
+		1:  newTestProcess(1, 0, "systemd", "/usr/lib/systemd/systemd", 100, "/sbin/init"),
+		10: newTestProcess(10, 1, "redis-server", "/usr/bin/redis-server", 200, "/usr/bin/redis-server 127.0.0.1:6379"),
+		20: newTestProcess(20, 1, "nginx", "/usr/sbin/nginx", 300, "nginx: master process /usr/sbin/nginx"),
+		21: newTestProcess(21, 20, "nginx", "/usr/sbin/nginx", 301, "nginx: worker process"),
+		30: newTestProcess(30, 1, "postgres", "/usr/lib/postgresql/14/bin/postgres", 400, "/usr/lib/postgresql/14/bin/postgres"),
+		40: newTestProcess(40, 1, "java", "/usr/bin/java", 500, "java", "-Xmx1G", "kafka.Kafka", "server.properties"),
+		// a containerized kafka, left to the container listeners
+		50: newTestProcess(50, 1, "java", "/usr/bin/java", 600, "java", "kafka.Kafka", "server.properties"),
+	}}
+
+	listener, err := NewProcessListener(&config.Listeners{})
+	require.NoError(t, err)
+	l := listener.(*ProcessListener)
+	l.probe = probe
+
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l.newService = newSvc
+	l.delService = delSvc
+
+	l.discover()
+	require.Len(t, newSvc, 4)
+	services := make(map[string]Service)
+	for i := 0; i < 4; i++ {
+		svc := <-newSvc
+		services[svc.GetServiceID()] = svc
+	}
+	assert.NotContains(t, services, "process://50")
+	ctx := context.Background()
+
+	redis := services["process://10"]
+	require.NotNil(t, redis)
+	ids, _ := redis.GetADIdentifiers(ctx)
+	assert.Equal(t, []string{"redis-server"}, ids)
+	hosts, _ := redis.GetHosts(ctx)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+	ports, _ := redis.GetPorts(ctx)
+	assert.Equal(t, []ContainerPort{{Port: 6379, Name: "p6379"}}, ports)
+	pid, _ := redis.GetPid(ctx)
+	assert.Equal(t, 10, pid)
+
+	nginx := services["process://20"]
+	require.NotNil(t, nginx)
+	ports, _ = nginx.GetPorts(ctx)
+	assert.Equal(t, []ContainerPort{{Port: 80, Name: "p80"}, {Port: 443, Name: "p443"}}, ports)
+	hosts, _ = nginx.GetHosts(ctx)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+
+	postgres := services["process://30"]
+	require.NotNil(t, postgres)
+	hosts, _ = postgres.GetHosts(ctx)
+	assert.Equal(t, map[string]string{"host": "10.0.0.5"}, hosts)
+
+	kafka := services["process://40"]
+	require.NotNil(t, kafka)
+	ids, _ = kafka.GetADIdentifiers(ctx)
+	assert.Equal(t, []string{"java", "kafka"}, ids)
+	ports, _ = kafka.GetPorts(ctx)
+	assert.Empty(t, ports)
+
+	// a new discovery of the same processes doesn't change the services
+	l.discover()
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+
+	// exited processes are removed, processes with new ports are replaced
+	delete(probe.procs, 30)
+	sockets[40] = []procutil.ListeningSocket{{IP: net.ParseIP("::"), Port: 9092}}
+	l.discover()
+	require.Len(t, delSvc, 2)
+	require.Len(t, newSvc, 1)
+	kafka = <-newSvc
+	assert.Equal(t, "process://40", kafka.GetServiceID())
+	hosts, _ = kafka.GetHosts(ctx)
+	assert.Equal(t, map[string]string{"host": "::1"}, hosts)
+	ports, _ = kafka.GetPorts(ctx)
+	assert.Equal(t, []ContainerPort{{Port: 9092, Name: "p9092"}}, ports)
+}
+
+func TestProcessListenerInvalidPattern(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("process_listener", nil)
+
+	for _, pattern := range []map[string]interface{}{
+		{"cmdline": "kafka"},
+		{"ad_identifier": "kafka"},
+		{"ad_identifier": "kafka", "name": "("},
+	} {
+		mockConfig.Set("process_listener", map[string]interface{}{"patterns": []map[string]interface{}{pattern}})
+		_, err := NewProcessListener(&config.Listeners{})
+		assert.Error(t, err)
+	}
+}
@@ -345,6 +345,13 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
 	}
 
+	return buildIntegrationConfig(name, &cf, "file:"+fpath)
+}
+
+// buildIntegrationConfig returns the integration.Config of a parsed configuration, `source` is its origin
+func buildIntegrationConfig(name string, cf *configFormat, source string) (integration.Config, error) {
+	conf := integration.Config{Name: name}
+
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
 	// this is not a valid configuration file
 	if cf.MetricConfig == nil && cf.LogsConfig == nil && len(cf.Instances) < 1 {
@@ -368,7 +375,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 			tags := config.GetConfiguredTags(false)
 			err := dataConf.MergeAdditionalTags(tags)
 			if err != nil {
-				log.Debugf("Could not add agent-level tags to instance of %v: %v", fpath, err)
+				log.Debugf("Could not add agent-level tags to instance of %v: %v", source, err)
 			}
 		}
 		conf.Instances = append(conf.Instances, dataConf)
@@ -405,9 +412,9 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Interpolate env vars. Returns an error a variable wasn't subsituted, ignore it.
 	_ = configresolver.SubstituteTemplateEnvVars(&conf)
 
-	conf.Source = "file:" + fpath
+	conf.Source = source
 
-	return conf, err
+	return conf, nil
 }
 
 func containsString(slice []string, str string) bool {
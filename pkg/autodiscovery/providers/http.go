@@ -0,0 +1,280 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpProviderTimeout = 10 * time.Second
+	// maxLongPollTimeout keeps the long polling requests shorter than the liveness deadline of the config poller
+	maxLongPollTimeout = 20 * time.Second
+	// maxHTTPConfigsSize is the maximum size of a response of the http config provider
+	maxHTTPConfigsSize = 10 * 1024 * 1024
+)
+
+// httpConfigFormat is an entry of the list of configurations served to the http config provider: a named
+// configuration with the format of the configuration files
+type httpConfigFormat struct {
+	Name         string `yaml:"name"`
+	configFormat `yaml:",inline"`
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface. It pulls the list of configurations, in JSON or YAML,
+// served at an URL.
+//
+// The URL is requested with the ETag of the last response, the configurations are up to date when the server answers
+// with a 304 Not Modified. With long polling, the requests ask the server to wait for a change of the configurations
+// with the `Prefer: wait=<seconds>` header (RFC 7240).
+type HTTPConfigProvider struct {
+	sync.RWMutex
+	client          *http.Client
+	url             string
+	headers         map[string]string
+	longPollTimeout time.Duration
+	etag            string
+	body            []byte
+	configErrors    map[string]ErrorMsgSet
+}
+
+// NewHTTPConfigProvider creates a new HTTPConfigProvider
+func NewHTTPConfigProvider(providerConfig *config.ConfigurationProviders) (ConfigProvider, error) {
+	if providerConfig == nil {
+		providerConfig = &config.ConfigurationProviders{}
+	}
+	if providerConfig.TemplateURL == "" {
+		return nil, fmt.Errorf("template_url is required")
+	}
+
+	tlsConfig, err := buildHTTPProviderTLSConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var longPollTimeout time.Duration
+	if providerConfig.LongPollTimeout != "" {
+		longPollTimeout, err = time.ParseDuration(providerConfig.LongPollTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid long_poll_timeout: %s", err)
+		}
+		if longPollTimeout > maxLongPollTimeout {
+			log.Warnf("long_poll_timeout of the http config provider is too long, using %s", maxLongPollTimeout)
+			longPollTimeout = maxLongPollTimeout
+		}
+	}
+
+	headers := make(map[string]string, len(providerConfig.Headers)+1)
+	if providerConfig.Token != "" {
+		headers["Authorization"] = "Bearer " + providerConfig.Token
+	}
+	for name, value := range providerConfig.Headers {
+		headers[name] = value
+	}
+
+	p := &HTTPConfigProvider{
+		client: &http.Client{
+			Timeout:   httpProviderTimeout + longPollTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+		url:             providerConfig.TemplateURL,
+		headers:         headers,
+		longPollTimeout: longPollTimeout,
+		configErrors:    make(map[string]ErrorMsgSet),
+	}
+	if providerConfig.Username != "" {
+		username, password := providerConfig.Username, providerConfig.Password
+		p.client.Transport = basicAuthTransport{username: username, password: password, next: p.client.Transport}
+	}
+	return p, nil
+}
+
+func buildHTTPProviderTLSConfig(providerConfig *config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: providerConfig.InsecureSkipVerify,
+	}
+	if providerConfig.CAFile != "" {
+		caCert, err := ioutil.ReadFile(providerConfig.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_file: %s", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in ca_file %s", providerConfig.CAFile)
+		}
+	}
+	if providerConfig.CertFile != "" || providerConfig.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(providerConfig.CertFile, providerConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// basicAuthTransport sets the basic authentication credentials of the requests
+type basicAuthTransport struct {
+	username string
+	password string
+	next     http.RoundTripper
+}
+
+func (t basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return t.next.RoundTrip(req)
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect returns the configurations of the last response, the URL is requested if it hasn't been yet
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.RLock()
+	fetched := p.body != nil
+	p.RUnlock()
+
+	if !fetched {
+		if _, err := p.fetch(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	var entries []httpConfigFormat
+	if err := yaml.Unmarshal(p.body, &entries); err != nil {
+		return nil, fmt.Errorf("unable to parse the configurations served at %s: %s", p.url, err)
+	}
+
+	configs := make([]integration.Config, 0, len(entries))
+	p.configErrors = make(map[string]ErrorMsgSet)
+	for i := range entries {
+		entry := &entries[i]
+		resource := fmt.Sprintf("%s#%d", p.url, i)
+		if entry.Name == "" {
+			p.addConfigError(resource, "the configuration has no name")
+			continue
+		}
+		resource = fmt.Sprintf("%s#%d (%s)", p.url, i, entry.Name)
+		conf, err := buildIntegrationConfig(entry.Name, &entry.configFormat, "http:"+p.url)
+		if err != nil {
+			p.addConfigError(resource, err.Error())
+			continue
+		}
+		configs = append(configs, conf)
+	}
+	return configs, nil
+}
+
+// IsUpToDate requests the configurations. They are up to date when the server answers with a 304 Not Modified or
+// serves the same configurations. They are also considered up to date when the request fails, to keep the
+// configurations scheduled until the server is reachable again.
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return p.fetch(ctx, true)
+}
+
+// fetch requests the configurations and stores the response. It returns whether the configurations are unchanged.
+// The lock is only held to read and swap the last response, not during the request, which can last as long as the
+// long polling timeout.
+func (p *HTTPConfigProvider) fetch(ctx context.Context, conditional bool) (bool, error) {
+	p.RLock()
+	lastETag := p.etag
+	p.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml")
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+	if conditional && lastETag != "" {
+		req.Header.Set("If-None-Match", lastETag)
+		if p.longPollTimeout > 0 {
+			req.Header.Set("Prefer", fmt.Sprintf("wait=%d", int(math.Ceil(p.longPollTimeout.Seconds()))))
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("unable to request %s: %s", p.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return true, fmt.Errorf("unexpected status code %d requesting %s", resp.StatusCode, p.url)
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxHTTPConfigsSize))
+	if err != nil {
+		return true, fmt.Errorf("unable to read the response of %s: %s", p.url, err)
+	}
+
+	etag := resp.Header.Get("ETag")
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.body != nil && etag == p.etag && bytes.Equal(body, p.body) {
+		return true, nil
+	}
+	if p.body == nil {
+		log.Infof("Initializing cache for %v", p.String())
+	} else {
+		log.Debugf("Configurations served at %s were modified", p.url)
+	}
+	p.etag = etag
+	p.body = body
+	return false, nil
+}
+
+func (p *HTTPConfigProvider) addConfigError(resource string, msg string) {
+	log.Warnf("Invalid configuration %s: %s", resource, msg)
+	if _, ok := p.configErrors[resource]; !ok {
+		p.configErrors[resource] = make(ErrorMsgSet)
+	}
+	p.configErrors[resource][msg] = struct{}{}
+}
+
+// GetConfigErrors returns the errors of the configurations of the last response
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.configErrors))
+	for resource, msgs := range p.configErrors {
+		errors[resource] = msgs
+	}
+	return errors
+}
+
+func init() {
+	RegisterProvider(names.HTTPRegisterName, NewHTTPConfigProvider)
+}
@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const httpTestConfigs = `[
+  {
+    "name": "redisdb",
+    "ad_identifiers": ["redis"],
+    "init_config": {},
+    "instances": [{"host": "%%host%%", "port": 6379}]
+  },
+  {
+    "name": "http_check",
+    "instances": [{"name": "cmdb", "url": "https://cmdb.example.com"}],
+    "logs": [{"type": "file", "path": "/var/log/cmdb.log", "service": "cmdb", "source": "cmdb"}]
+  },
+  {"ad_identifiers": ["nginx"], "instances": [{}]},
+  {"name": "empty"}
+]`
+
+// httpTestServer serves configurations with an ETag, and records the headers of the requests
+type httpTestServer struct {
+	sync.Mutex
+	body     string
+	etag     string
+	requests []http.Header
+}
+
+func (s *httpTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.requests = append(s.requests, r.Header.Clone())
+	if r.Header.Get("Authorization") != "Bearer secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.body)) //nolint:errcheck
+}
+
+func (s *httpTestServer) set(body, etag string) {
+	s.Lock()
+	defer s.Unlock()
+	s.body = body
+	s.etag = etag
+}
+
+func (s *httpTestServer) lastRequest() http.Header {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func TestHTTPConfigProvider(t *testing.T) {
+	server := &httpTestServer{body: httpTestConfigs, etag: `"v1"`}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL:     ts.URL,
+		Headers:         map[string]string{"Authorization": "Bearer secret-token", "X-Team": "network"},
+		LongPollTimeout: "5s",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "redisdb", configs[0].Name)
+	assert.Equal(t, []string{"redis"}, configs[0].ADIdentifiers)
+	assert.Equal(t, integration.Data("host: '%%host%%'\nport: 6379\n"), configs[0].Instances[0])
+	assert.Equal(t, "http:"+ts.URL, configs[0].Source)
+	assert.Equal(t, "http_check", configs[1].Name)
+	assert.True(t, configs[1].IsLogConfig())
+	assert.Equal(t, "network", server.lastRequest().Get("X-Team"))
+	assert.Empty(t, server.lastRequest().Get("If-None-Match"))
+
+	assert.Len(t, provider.GetConfigErrors(), 2)
+	assert.Contains(t, provider.GetConfigErrors(), ts.URL+"#2")
+	assert.Contains(t, provider.GetConfigErrors(), ts.URL+"#3 (empty)")
+
+	upToDate, err := provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+	assert.Equal(t, `"v1"`, server.lastRequest().Get("If-None-Match"))
+	assert.Equal(t, "wait=5", server.lastRequest().Get("Prefer"))
+
+	server.set(`[{"name": "redisdb", "ad_identifiers": ["redis"], "instances": [{"host": "%%host%%", "port": 6380}]}]`, `"v2"`)
+	upToDate, err = provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	configs, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, integration.Data("host: '%%host%%'\nport: 6380\n"), configs[0].Instances[0])
+	assert.Empty(t, provider.GetConfigErrors())
+}
+
+func TestHTTPConfigProviderYAMLWithoutETag(t *testing.T) {
+	body := `
+- name: redisdb
+  ad_identifiers: [redis]
+  instances:
+    - host: "%%host%%"
+`
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(body)) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "redisdb", configs[0].Name)
+
+	// the configurations are compared when the server doesn't send ETags
+	upToDate, err := provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	mu.Lock()
+	body += "    - host: localhost\n"
+	mu.Unlock()
+	upToDate, err = provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+	configs, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Len(t, configs[0].Instances, 2)
+}
+
+func TestHTTPConfigProviderErrors(t *testing.T) {
+	server := &httpTestServer{body: httpTestConfigs, etag: `"v1"`}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	ctx := context.Background()
+
+	_, err := NewHTTPConfigProvider(&config.ConfigurationProviders{})
+	assert.EqualError(t, err, "template_url is required")
+	_, err = NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL, LongPollTimeout: "1 minute"})
+	assert.Error(t, err)
+
+	// the request is rejected without the token
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL})
+	require.NoError(t, err)
+	_, err = provider.Collect(ctx)
+	assert.EqualError(t, err, "unexpected status code 401 requesting "+ts.URL)
+
+	// the configurations stay scheduled when the server is unreachable
+	provider, err = NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL, Token: "secret-token"})
+	require.NoError(t, err)
+	_, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	ts.Close()
+	upToDate, err := provider.IsUpToDate(ctx)
+	assert.Error(t, err)
+	assert.True(t, upToDate)
+}
+
+func TestHTTPConfigProviderBasicAuth(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "datadog" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"name": "redisdb", "instances": [{}]}]`)) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL:        ts.URL,
+		Username:           "datadog",
+		Password:           "pass",
+		InsecureSkipVerify: true,
+	})
+	require.NoError(t, err)
+
+	configs, err := provider.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, configs, 1)
+}
+
+func TestHTTPConfigProviderLongPollDoesNotBlockErrors(t *testing.T) {
+	polling := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			// long poll until the test is done
+			close(polling)
+			<-release
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"instances": [{}]}]`)) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL, LongPollTimeout: "20s"})
+	require.NoError(t, err)
+	_, err = provider.Collect(context.Background())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		upToDate, err := provider.IsUpToDate(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, upToDate)
+	}()
+	<-polling
+
+	// the errors are available while the long polling request is pending
+	errors := make(chan map[string]ErrorMsgSet)
+	go func() { errors <- provider.GetConfigErrors() }()
+	select {
+	case errs := <-errors:
+		assert.Len(t, errs, 1)
+	case <-time.After(5 * time.Second):
+		t.Error("GetConfigErrors is blocked by the long polling request")
+	}
+
+	close(release)
+	<-done
+}
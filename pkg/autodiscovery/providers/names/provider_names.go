@@ -15,11 +15,13 @@ const (
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	HTTP               = "http"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
 	KubeServicesFile   = "kubernetes-services-file"
 	KubeEndpoints      = "kubernetes-endpoints"
 	KubeEndpointsFile  = "kubernetes-endpoints-file"
+	Nomad              = "nomad"
 	PrometheusPods     = "prometheus-pods"
 	PrometheusServices = "prometheus-services"
 	SNMP               = "snmp"
@@ -35,11 +37,13 @@ const (
 	ClusterChecksRegisterName      = "clusterchecks"
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
+	HTTPRegisterName               = "http"
 	KubeletRegisterName            = "kubelet"
 	KubeServicesRegisterName       = "kube_services"
 	KubeServicesFileRegisterName   = "kube_services_file"
 	KubeEndpointsRegisterName      = "kube_endpoints"
 	KubeEndpointsFileRegisterName  = "kube_endpoints_file"
+	NomadRegisterName              = "nomad"
 	PrometheusPodsRegisterName     = "prometheus_pods"
 	PrometheusServicesRegisterName = "prometheus_services"
 	ZookeeperRegisterName          = "zookeeper"
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/utils"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// nomadADMetaPrefix is the prefix of the AD templates in the meta of the Nomad
+// services. The keys of the meta of the services registered in Consul can
+// only contain letters, digits, dashes and underscores.
+const nomadADMetaPrefix = "datadog_ad_"
+
+// NomadConfigProvider implements the ConfigProvider interface for the Nomad
+// allocations. It reads the templates of the containers of the tasks in the
+// meta of the services of the tasks.
+type NomadConfigProvider struct {
+	workloadmetaStore workloadmeta.Store
+	allocationCache   map[string]*workloadmeta.NomadAllocation
+	configErrors      map[string]ErrorMsgSet
+	upToDate          bool
+	streaming         bool
+	once              sync.Once
+	sync.RWMutex
+}
+
+// NewNomadConfigProvider returns a new ConfigProvider reading the Nomad
+// allocations from workloadmeta.
+func NewNomadConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	return &NomadConfigProvider{
+		workloadmetaStore: workloadmeta.GetGlobalStore(),
+		configErrors:      make(map[string]ErrorMsgSet),
+		allocationCache:   make(map[string]*workloadmeta.NomadAllocation),
+	}, nil
+}
+
+// String returns a string representation of the NomadConfigProvider
+func (n *NomadConfigProvider) String() string {
+	return names.Nomad
+}
+
+// Collect retrieves all running allocations and extract AD templates from the
+// meta of their services.
+func (n *NomadConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	n.once.Do(func() {
+		go n.listen()
+	})
+
+	n.Lock()
+	n.upToDate = true
+	n.Unlock()
+
+	return n.generateConfigs()
+}
+
+func (n *NomadConfigProvider) listen() {
+	const name = "ad-nomadprovider"
+
+	n.Lock()
+	n.streaming = true
+	health := health.RegisterLiveness(name)
+	defer func() {
+		err := health.Deregister()
+		if err != nil {
+			log.Warnf("error de-registering health check: %s", err)
+		}
+	}()
+	n.Unlock()
+
+	ch := n.workloadmetaStore.Subscribe(name, workloadmeta.NormalPriority, workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindNomadAllocation},
+		workloadmeta.SourceNodeOrchestrator,
+	))
+
+	for {
+		select {
+		case evBundle, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			n.processEvents(evBundle)
+
+		case <-health.C:
+
+		}
+	}
+}
+
+func (n *NomadConfigProvider) processEvents(evBundle workloadmeta.EventBundle) {
+	close(evBundle.Ch)
+
+	for _, event := range evBundle.Events {
+		switch event.Type {
+		case workloadmeta.EventTypeSet:
+			n.addAllocation(event.Entity)
+		case workloadmeta.EventTypeUnset:
+			n.deleteAllocation(event.Entity)
+
+		default:
+			log.Errorf("cannot handle event of type %d", event.Type)
+		}
+	}
+}
+
+func (n *NomadConfigProvider) addAllocation(entity workloadmeta.Entity) {
+	n.Lock()
+	defer n.Unlock()
+	alloc := entity.(*workloadmeta.NomadAllocation)
+	n.allocationCache[alloc.GetID().ID] = alloc
+	n.upToDate = false
+}
+
+func (n *NomadConfigProvider) deleteAllocation(entity workloadmeta.Entity) {
+	n.Lock()
+	defer n.Unlock()
+	delete(n.allocationCache, entity.GetID().ID)
+	n.upToDate = false
+}
+
+func (n *NomadConfigProvider) generateConfigs() ([]integration.Config, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	adErrors := make(map[string]ErrorMsgSet)
+
+	var configs []integration.Config
+	for _, alloc := range n.allocationCache {
+		for _, allocContainer := range alloc.Containers {
+			meta := taskServicesMeta(alloc, allocContainer.Name)
+			if len(meta) == 0 {
+				continue
+			}
+
+			container, err := n.workloadmetaStore.GetContainer(allocContainer.ID)
+			if err != nil {
+				log.Debugf("Nomad allocation %q has reference to non-existing container %q", alloc.Name, allocContainer.ID)
+				continue
+			}
+
+			containerEntity := containers.BuildEntityName(string(container.Runtime), container.ID)
+			c, errors := utils.ExtractTemplatesFromMap(containerEntity, meta, nomadADMetaPrefix)
+
+			// the errors are reported by allocation and task, the names
+			// of the allocations are unique in a namespace
+			resource := alloc.Namespace + "/" + alloc.Name + "/" + allocContainer.Name
+			for _, err := range errors {
+				log.Errorf("Can't parse template for task %s of Nomad allocation %s: %s", allocContainer.Name, alloc.Name, err)
+				if _, found := adErrors[resource]; !found {
+					adErrors[resource] = map[string]struct{}{err.Error(): {}}
+				} else {
+					adErrors[resource][err.Error()] = struct{}{}
+				}
+			}
+
+			for idx := range c {
+				c[idx].Source = names.Nomad + ":" + containerEntity
+			}
+
+			configs = append(configs, c...)
+		}
+	}
+
+	n.configErrors = adErrors
+	telemetry.Errors.Set(float64(len(adErrors)), names.Nomad)
+
+	return configs, nil
+}
+
+// taskServicesMeta merges the meta of the services of a task. The group
+// services that are not bound to a task apply to every task of the group, the
+// meta of the services of the task taking precedence over them.
+func taskServicesMeta(alloc *workloadmeta.NomadAllocation, task string) map[string]string {
+	var meta map[string]string
+	merge := func(taskName string) {
+		for _, service := range alloc.Services {
+			if service.Task != taskName {
+				continue
+			}
+			for key, value := range service.Meta {
+				if meta == nil {
+					meta = make(map[string]string)
+				}
+				meta[key] = value
+			}
+		}
+	}
+
+	merge("")
+	merge(task)
+
+	return meta
+}
+
+// IsUpToDate checks whether we have new allocations to parse, based on events
+// received by the listen goroutine. If listening fails, we fallback to
+// collecting everytime.
+func (n *NomadConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	n.RLock()
+	defer n.RUnlock()
+	return n.streaming && n.upToDate, nil
+}
+
+func init() {
+	RegisterProvider(names.NomadRegisterName, NewNomadConfigProvider)
+}
+
+// GetConfigErrors returns a map of configuration errors for each task of the
+// allocations
+func (n *NomadConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	n.RLock()
+	defer n.RUnlock()
+	return n.configErrors
+}
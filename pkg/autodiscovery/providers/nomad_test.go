@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	workloadmetatesting "github.com/DataDog/datadog-agent/pkg/workloadmeta/testing"
+)
+
+func TestNomadGenerateConfigs(t *testing.T) {
+	store := workloadmetatesting.NewStore()
+	for _, id := range []string{"redis", "exporter", "web"} {
+		store.Set(&workloadmeta.Container{
+			EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: id},
+			Runtime:  workloadmeta.ContainerRuntimeDocker,
+		})
+	}
+
+	alloc := &workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindNomadAllocation, ID: "alloc-1"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "example.cache[0]",
+			Namespace: "default",
+		},
+		Containers: []workloadmeta.OrchestratorContainer{
+			{ID: "redis", Name: "redis"},
+			{ID: "exporter", Name: "exporter"},
+			{ID: "web", Name: "web"},
+			{ID: "deleted", Name: "sidecar"},
+		},
+		Services: []workloadmeta.NomadService{
+			{
+				Name: "redis-cache",
+				Task: "redis",
+				Meta: map[string]string{
+					"datadog_ad_check_names":  `["redisdb"]`,
+					"datadog_ad_init_configs": `[{}]`,
+				},
+			},
+			{
+				Name: "redis-admin",
+				Task: "redis",
+				Meta: map[string]string{
+					"datadog_ad_instances": `[{"host": "%%host%%", "port": "6379"}]`,
+					"datadog_ad_logs":      `[{"source": "redis", "service": "cache"}]`,
+				},
+			},
+			{
+				Name: "redis-exporter",
+				Task: "exporter",
+				// the instances of the task override the ones of the group, and are invalid
+				Meta: map[string]string{
+					"datadog_ad_check_names": `["openmetrics"]`,
+					"datadog_ad_instances":   `[{"openmetrics_endpoint": "http://%%host%%:9121/metrics"}`,
+				},
+			},
+			{
+				// group services without a task apply to every task of the group
+				Name: "cache-group",
+				Meta: map[string]string{
+					"datadog_ad_check_names":  `["http_check"]`,
+					"datadog_ad_init_configs": `[{}]`,
+					"datadog_ad_instances":    `[{"url": "http://%%host%%:8080/health"}]`,
+				},
+			},
+			{
+				Name: "sidecar",
+				Task: "sidecar",
+				Meta: map[string]string{"datadog_ad_check_names": `["envoy"]`},
+			},
+		},
+	}
+
+	provider := &NomadConfigProvider{
+		workloadmetaStore: store,
+		configErrors:      make(map[string]ErrorMsgSet),
+		allocationCache:   map[string]*workloadmeta.NomadAllocation{alloc.ID: alloc},
+	}
+
+	configs, err := provider.generateConfigs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []integration.Config{
+		{
+			Name:          "redisdb",
+			ADIdentifiers: []string{"docker://redis"},
+			InitConfig:    integration.Data("{}"),
+			Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":"6379"}`)},
+			Source:        "nomad:docker://redis",
+		},
+		{
+			Name:          "http_check",
+			ADIdentifiers: []string{"docker://web"},
+			InitConfig:    integration.Data("{}"),
+			Instances:     []integration.Data{integration.Data(`{"url":"http://%%host%%:8080/health"}`)},
+			Source:        "nomad:docker://web",
+		},
+		{
+			ADIdentifiers: []string{"docker://redis"},
+			LogsConfig:    integration.Data(`[{"service":"cache","source":"redis"}]`),
+			Source:        "nomad:docker://redis",
+		},
+	}, configs)
+
+	assert.Equal(t, map[string]ErrorMsgSet{
+		"default/example.cache[0]/exporter": {"could not extract checks config: in instances: failed to unmarshal JSON: unexpected end of JSON input": {}},
+	}, provider.GetConfigErrors())
+}
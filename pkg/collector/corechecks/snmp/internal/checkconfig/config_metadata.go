@@ -85,6 +85,125 @@ var LegacyMetadataConfig = MetadataConfig{
 	},
 }
 
+// TopologyMetadataConfig contains the metadata definitions of the LLDP and CDP neighbors tables.
+// The tables are walked when topology collection is enabled, they are used to build the links between
+// the local interfaces of a device and the interfaces of its neighbors.
+var TopologyMetadataConfig = MetadataConfig{
+	"lldp_remote": {
+		Fields: map[string]MetadataField{
+			"chassis_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.4",
+					Name: "lldpRemChassisIdSubtype",
+				},
+			},
+			"chassis_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.5",
+					Name: "lldpRemChassisId",
+				},
+			},
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.6",
+					Name: "lldpRemPortIdSubtype",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.7",
+					Name: "lldpRemPortId",
+				},
+			},
+			"interface_desc": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.8",
+					Name: "lldpRemPortDesc",
+				},
+			},
+			"device_name": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.9",
+					Name: "lldpRemSysName",
+				},
+			},
+			"device_desc": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.10",
+					Name: "lldpRemSysDesc",
+				},
+			},
+		},
+	},
+	"lldp_remote_management": {
+		// the management address is part of the index of the table
+		Fields: map[string]MetadataField{
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.2.1.3",
+					Name: "lldpRemManAddrIfSubtype",
+				},
+			},
+		},
+	},
+	"lldp_local": {
+		Fields: map[string]MetadataField{
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.3.7.1.2",
+					Name: "lldpLocPortIdSubtype",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.3.7.1.3",
+					Name: "lldpLocPortId",
+				},
+			},
+		},
+	},
+	"cdp_remote": {
+		Fields: map[string]MetadataField{
+			"address_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.3",
+					Name: "cdpCacheAddressType",
+				},
+			},
+			"address": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.4",
+					Name: "cdpCacheAddress",
+				},
+			},
+			"device_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.6",
+					Name: "cdpCacheDeviceId",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.7",
+					Name: "cdpCacheDevicePort",
+				},
+			},
+			"device_desc": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.8",
+					Name: "cdpCachePlatform",
+				},
+			},
+			"device_name": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.17",
+					Name: "cdpCacheSysName",
+				},
+			},
+		},
+	},
+}
+
 // MetadataConfig holds configs per resource type
 type MetadataConfig map[string]MetadataResourceConfig
 
@@ -112,16 +231,24 @@ func IsMetadataResourceWithScalarOids(resource string) bool {
 	return resource == common.MetadataDeviceResource
 }
 
-// updateMetadataDefinitionWithLegacyFallback will add metadata config for resources
-// that does not have metadata definitions
-func updateMetadataDefinitionWithLegacyFallback(config MetadataConfig) MetadataConfig {
-	if config == nil {
-		config = MetadataConfig{}
+// updateMetadataDefinitionWithDefaults returns a copy of the metadata config with the legacy metadata definitions
+// of the resources that don't have a definition, and the topology metadata definitions if collectTopology is set.
+// The config is copied since it might be shared by the instances using the same profile.
+func updateMetadataDefinitionWithDefaults(config MetadataConfig, collectTopology bool) MetadataConfig {
+	newConfig := make(MetadataConfig, len(config))
+	mergeMetadata(newConfig, config)
+	mergeMetadata(newConfig, LegacyMetadataConfig)
+	if collectTopology {
+		mergeMetadata(newConfig, TopologyMetadataConfig)
 	}
-	for resourceName, resourceConfig := range LegacyMetadataConfig {
+	return newConfig
+}
+
+// mergeMetadata adds the resources of extraConfig that are not defined in config
+func mergeMetadata(config MetadataConfig, extraConfig MetadataConfig) {
+	for resourceName, resourceConfig := range extraConfig {
 		if _, ok := config[resourceName]; !ok {
 			config[resourceName] = resourceConfig
 		}
 	}
-	return config
 }
@@ -1060,6 +1060,85 @@ collect_device_metadata: true
 	assert.Equal(t, false, config.CollectDeviceMetadata)
 }
 
+func Test_buildConfig_collectTopology(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+oid_batch_size: 10
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectTopology)
+	assert.NotContains(t, config.Metadata, "lldp_remote")
+	assert.NotContains(t, config.OidConfig.ColumnOids, "1.0.8802.1.1.2.1.4.1.1.5")
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+`)
+	// language=yaml
+	rawInitConfig = []byte(`
+oid_batch_size: 10
+collect_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectTopology)
+	assert.Contains(t, config.Metadata, "lldp_remote")
+	assert.Contains(t, config.Metadata, "cdp_remote")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.0.8802.1.1.2.1.4.1.1.5")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.4.1.9.9.23.1.2.1.1.6")
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_topology: false
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectTopology)
+
+	// topology tables are only walked with device metadata
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_device_metadata: false
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectTopology)
+	assert.NotContains(t, config.OidConfig.ColumnOids, "1.0.8802.1.1.2.1.4.1.1.5")
+}
+
+func Test_updateMetadataDefinitionWithDefaults(t *testing.T) {
+	profileMetadata := MetadataConfig{
+		"device": {
+			Fields: map[string]MetadataField{
+				"vendor": {Value: "f5"},
+			},
+		},
+	}
+
+	metadata := updateMetadataDefinitionWithDefaults(profileMetadata, true)
+	assert.Equal(t, profileMetadata["device"], metadata["device"])
+	assert.Equal(t, LegacyMetadataConfig["interface"], metadata["interface"])
+	assert.Equal(t, TopologyMetadataConfig["lldp_remote"], metadata["lldp_remote"])
+
+	// the profile definition shared by the instances is not modified
+	assert.Len(t, profileMetadata, 1)
+
+	metadata = updateMetadataDefinitionWithDefaults(profileMetadata, false)
+	assert.NotContains(t, metadata, "lldp_remote")
+	assert.NotContains(t, metadata, "cdp_remote")
+}
+
 func Test_buildConfig_namespace(t *testing.T) {
 	defer coreconfig.Datadog.Set("network_devices.namespace", "default")
 
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package gosnmplib
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// snmprec tags of the supported value types, see https://github.com/etingof/snmpsim
+// A `x` suffix means that the value is hex encoded.
+var snmprecTags = map[gosnmp.Asn1BER]string{
+	gosnmp.Integer:          "2",
+	gosnmp.OctetString:      "4",
+	gosnmp.Null:             "5",
+	gosnmp.ObjectIdentifier: "6",
+	gosnmp.IPAddress:        "64",
+	gosnmp.Counter32:        "65",
+	gosnmp.Gauge32:          "66",
+	gosnmp.TimeTicks:        "67",
+	gosnmp.Opaque:           "68",
+	gosnmp.Counter64:        "70",
+}
+
+var snmprecTypes = func() map[string]gosnmp.Asn1BER {
+	types := make(map[string]gosnmp.Asn1BER, len(snmprecTags))
+	for berType, tag := range snmprecTags {
+		types[tag] = berType
+	}
+	return types
+}()
+
+// FormatSnmprecRecord formats a PDU as a snmprec record: `<OID>|<TAG>|<VALUE>`
+func FormatSnmprecRecord(pdu gosnmp.SnmpPDU) (string, error) {
+	name := strings.TrimLeft(pdu.Name, ".")
+	tag, ok := snmprecTags[pdu.Type]
+	if !ok {
+		return "", fmt.Errorf("oid %s: unsupported type: %s", name, pdu.Type.String())
+	}
+
+	var value string
+	switch pdu.Type {
+	case gosnmp.OctetString, gosnmp.Opaque:
+		bytesValue, ok := pdu.Value.([]byte)
+		if !ok {
+			return "", fmt.Errorf("oid %s: %s should be []byte type but got type `%T`", name, pdu.Type.String(), pdu.Value)
+		}
+		if isPrintable(bytesValue) {
+			value = string(bytesValue)
+		} else {
+			tag += "x"
+			value = hex.EncodeToString(bytesValue)
+		}
+	case gosnmp.Null:
+	case gosnmp.ObjectIdentifier, gosnmp.IPAddress:
+		strValue, ok := pdu.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("oid %s: %s should be string type but got type `%T`", name, pdu.Type.String(), pdu.Value)
+		}
+		value = strings.TrimLeft(strValue, ".")
+	default:
+		value = gosnmp.ToBigInt(pdu.Value).String()
+	}
+	return name + "|" + tag + "|" + value, nil
+}
+
+// ParseSnmprecRecord parses a snmprec record: `<OID>|<TAG>|<VALUE>`
+func ParseSnmprecRecord(record string) (gosnmp.SnmpPDU, error) {
+	parts := strings.SplitN(record, "|", 3)
+	if len(parts) != 3 {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("invalid snmprec record `%s`", record)
+	}
+	name, tag, value := "."+strings.TrimLeft(parts[0], "."), parts[1], parts[2]
+
+	hexEncoded := strings.HasSuffix(tag, "x")
+	berType, ok := snmprecTypes[strings.TrimSuffix(tag, "x")]
+	if !ok {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: unsupported snmprec tag `%s`", name, tag)
+	}
+	if hexEncoded && berType != gosnmp.OctetString && berType != gosnmp.Opaque {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: unsupported hex encoded snmprec tag `%s`", name, tag)
+	}
+
+	pdu := gosnmp.SnmpPDU{Name: name, Type: berType}
+	switch berType {
+	case gosnmp.OctetString, gosnmp.Opaque:
+		if hexEncoded {
+			bytesValue, err := hex.DecodeString(value)
+			if err != nil {
+				return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid hex value `%s`: %s", name, value, err)
+			}
+			pdu.Value = bytesValue
+		} else {
+			pdu.Value = []byte(value)
+		}
+	case gosnmp.Null:
+	case gosnmp.ObjectIdentifier:
+		pdu.Value = "." + strings.TrimLeft(value, ".")
+	case gosnmp.IPAddress:
+		pdu.Value = value
+	case gosnmp.Integer:
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid integer value `%s`: %s", name, value, err)
+		}
+		pdu.Value = intValue
+	case gosnmp.Counter64:
+		bigValue, ok := new(big.Int).SetString(value, 10)
+		if !ok || !bigValue.IsUint64() {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid counter64 value `%s`", name, value)
+		}
+		pdu.Value = bigValue.Uint64()
+	default:
+		uintValue, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid %s value `%s`: %s", name, berType.String(), value, err)
+		}
+		pdu.Value = uint(uintValue)
+	}
+	return pdu, nil
+}
+
+// ReadSnmprec reads the records of a snmprec file, empty lines and lines starting with `#` are ignored
+func ReadSnmprec(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pdu, err := ParseSnmprecRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+// CompareOIDs compares two OIDs numerically, it returns -1 if a < b, 0 if a == b and 1 if a > b
+func CompareOIDs(a string, b string) int {
+	aElems := strings.Split(strings.TrimLeft(a, "."), ".")
+	bElems := strings.Split(strings.TrimLeft(b, "."), ".")
+	for i := 0; i < len(aElems) && i < len(bElems); i++ {
+		aNum, aErr := strconv.ParseUint(aElems[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bElems[i], 10, 64)
+		if aErr != nil || bErr != nil {
+			// not expected, fallback on string comparison
+			if c := strings.Compare(aElems[i], bElems[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+	}
+	switch {
+	case len(aElems) < len(bElems):
+		return -1
+	case len(aElems) > len(bElems):
+		return 1
+	}
+	return 0
+}
+
+// isPrintable returns true if the bytes can be written as is in a snmprec record
+func isPrintable(bytesValue []byte) bool {
+	for _, bit := range bytesValue {
+		if bit < 32 || bit > 126 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package gosnmplib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_snmprecRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		pdu    gosnmp.SnmpPDU
+		record string
+	}{
+		{"Integer", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Integer, Value: -141}, "1.2.3|2|-141"},
+		{"OctetString", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.OctetString, Value: []byte("my string")}, "1.2.3|4|my string"},
+		{"OctetString hex", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1c, 0x73, 0xff}}, "1.2.3|4x|001c73ff"},
+		{"Null", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Null}, "1.2.3|5|"},
+		{"ObjectIdentifier", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.3375.2.1.3.4.1"}, "1.2.3|6|1.3.6.1.4.1.3375.2.1.3.4.1"},
+		{"IPAddress", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.IPAddress, Value: "10.0.0.1"}, "1.2.3|64|10.0.0.1"},
+		{"Counter32", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Counter32, Value: uint(10)}, "1.2.3|65|10"},
+		{"Gauge32", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Gauge32, Value: uint(4294967295)}, "1.2.3|66|4294967295"},
+		{"TimeTicks", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.TimeTicks, Value: uint(20)}, "1.2.3|67|20"},
+		{"Opaque", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Opaque, Value: []byte{0x9f, 0x78, 0x04}}, "1.2.3|68x|9f7804"},
+		{"Counter64", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)}, "1.2.3|70|18446744073709551615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := FormatSnmprecRecord(tt.pdu)
+			require.NoError(t, err)
+			assert.Equal(t, tt.record, record)
+
+			pdu, err := ParseSnmprecRecord(record)
+			require.NoError(t, err)
+			assert.Equal(t, tt.pdu, pdu)
+		})
+	}
+}
+
+func Test_FormatSnmprecRecord_errors(t *testing.T) {
+	_, err := FormatSnmprecRecord(gosnmp.SnmpPDU{Name: "1.2.3", Type: gosnmp.NoSuchObject})
+	assert.EqualError(t, err, "oid 1.2.3: unsupported type: NoSuchObject")
+
+	_, err = FormatSnmprecRecord(gosnmp.SnmpPDU{Name: "1.2.3", Type: gosnmp.OctetString, Value: 1})
+	assert.EqualError(t, err, "oid 1.2.3: OctetString should be []byte type but got type `int`")
+}
+
+func Test_ParseSnmprecRecord_errors(t *testing.T) {
+	tests := []struct {
+		record      string
+		expectedErr string
+	}{
+		{"1.2.3|2", "invalid snmprec record `1.2.3|2`"},
+		{"1.2.3|99|1", "oid .1.2.3: unsupported snmprec tag `99`"},
+		{"1.2.3|2x|01", "oid .1.2.3: unsupported hex encoded snmprec tag `2x`"},
+		{"1.2.3|4x|zz", "oid .1.2.3: invalid hex value `zz`: encoding/hex: invalid byte: U+007A 'z'"},
+		{"1.2.3|2|abc", "oid .1.2.3: invalid integer value `abc`: strconv.Atoi: parsing \"abc\": invalid syntax"},
+		{"1.2.3|70|-1", "oid .1.2.3: invalid counter64 value `-1`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.record, func(t *testing.T) {
+			_, err := ParseSnmprecRecord(tt.record)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func Test_ReadSnmprec(t *testing.T) {
+	recording := `# a comment
+1.3.6.1.2.1.1.1.0|4|my device
+
+1.3.6.1.2.1.1.3.0|67|20
+`
+	pdus, err := ReadSnmprec(strings.NewReader(recording))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device")},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint(20)},
+	}, pdus)
+
+	_, err = ReadSnmprec(strings.NewReader("1.2.3|4|a\n1.2.3\n"))
+	assert.EqualError(t, err, "line 2: invalid snmprec record `1.2.3`")
+}
+
+func Test_CompareOIDs(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{".1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.3", "1.2.1", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareOIDs(tt.a, tt.b))
+		})
+	}
+}
@@ -21,11 +21,12 @@ const (
 
 // NetworkDevicesMetadata contains network devices metadata
 type NetworkDevicesMetadata struct {
-	Subnet           string              `json:"subnet"`
-	Namespace        string              `json:"namespace"`
-	Devices          []DeviceMetadata    `json:"devices,omitempty"`
-	Interfaces       []InterfaceMetadata `json:"interfaces,omitempty"`
-	CollectTimestamp int64               `json:"collect_timestamp"`
+	Subnet           string                 `json:"subnet"`
+	Namespace        string                 `json:"namespace"`
+	Devices          []DeviceMetadata       `json:"devices,omitempty"`
+	Interfaces       []InterfaceMetadata    `json:"interfaces,omitempty"`
+	Links            []TopologyLinkMetadata `json:"links,omitempty"`
+	CollectTimestamp int64                  `json:"collect_timestamp"`
 }
 
 // DeviceMetadata contains device metadata
@@ -63,3 +64,36 @@ type InterfaceMetadata struct {
 	AdminStatus int32    `json:"admin_status,omitempty"` // IF-MIB ifAdminStatus type is INTEGER
 	OperStatus  int32    `json:"oper_status,omitempty"`  // IF-MIB ifOperStatus type is INTEGER
 }
+
+// TopologyLinkDevice contains the device of a side of a topology link
+type TopologyLinkDevice struct {
+	DDID        string `json:"dd_id,omitempty"`   // device id of the monitored device
+	ID          string `json:"id,omitempty"`      // LLDP chassis id or CDP device id
+	IDType      string `json:"id_type,omitempty"` // LLDP chassis id subtype, e.g. mac_address
+	IPAddress   string `json:"ip_address,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TopologyLinkInterface contains the interface of a side of a topology link
+type TopologyLinkInterface struct {
+	DDID        string `json:"dd_id,omitempty"`   // <device_id>:<if_index> of an interface of the monitored device
+	ID          string `json:"id"`                // LLDP port id or CDP device port
+	IDType      string `json:"id_type,omitempty"` // LLDP port id subtype, e.g. interface_name
+	Description string `json:"description,omitempty"`
+}
+
+// TopologyLinkSide contains the device and the interface of a side of a topology link
+type TopologyLinkSide struct {
+	Device    *TopologyLinkDevice    `json:"device,omitempty"`
+	Interface *TopologyLinkInterface `json:"interface,omitempty"`
+}
+
+// TopologyLinkMetadata contains a link between a local interface and the interface of a neighbor,
+// discovered using LLDP or CDP
+type TopologyLinkMetadata struct {
+	ID         string            `json:"id"`
+	SourceType string            `json:"source_type"` // lldp or cdp
+	Local      *TopologyLinkSide `json:"local"`
+	Remote     *TopologyLinkSide `json:"remote"`
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -32,7 +33,16 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 
 	interfaces := buildNetworkInterfacesMetadata(config.DeviceID, metadataStore)
 
-	metadataPayloads := batchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, metadata.PayloadMetadataBatchSize, device, interfaces)
+	var links []metadata.TopologyLinkMetadata
+	if config.CollectTopology {
+		links = buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces)
+	}
+
+	if deviceStatus == metadata.DeviceStatusReachable {
+		devicestore.Update(buildStoreDevice(config.Namespace, config.ResolvedSubnetName, device, interfaces))
+	}
+
+	metadataPayloads := batchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, metadata.PayloadMetadataBatchSize, device, interfaces, links)
 
 	for _, payload := range metadataPayloads {
 		payloadBytes, err := json.Marshal(payload)
@@ -188,7 +198,37 @@ func buildNetworkInterfacesMetadata(deviceID string, store *metadata.Store) []me
 	return interfaces
 }
 
-func batchPayloads(namespace string, subnet string, collectTime time.Time, batchSize int, device metadata.DeviceMetadata, interfaces []metadata.InterfaceMetadata) []metadata.NetworkDevicesMetadata {
+// buildStoreDevice converts the device metadata for the in-memory device store, used to enrich traps and flows
+func buildStoreDevice(namespace string, subnet string, device metadata.DeviceMetadata, interfaces []metadata.InterfaceMetadata) devicestore.Device {
+	storeDevice := devicestore.Device{
+		ID:          device.ID,
+		IPAddress:   device.IPAddress,
+		Namespace:   namespace,
+		Subnet:      subnet,
+		Name:        device.Name,
+		Description: device.Description,
+		SysObjectID: device.SysObjectID,
+		Vendor:      device.Vendor,
+		Model:       device.Model,
+		Tags:        device.Tags,
+		Interfaces:  make(map[int32]devicestore.Interface, len(interfaces)),
+	}
+	for _, networkInterface := range interfaces {
+		storeDevice.Interfaces[networkInterface.Index] = devicestore.Interface{
+			Index:       networkInterface.Index,
+			IDTags:      networkInterface.IDTags,
+			Name:        networkInterface.Name,
+			Alias:       networkInterface.Alias,
+			Description: networkInterface.Description,
+			MacAddress:  networkInterface.MacAddress,
+			AdminStatus: networkInterface.AdminStatus,
+			OperStatus:  networkInterface.OperStatus,
+		}
+	}
+	return storeDevice
+}
+
+func batchPayloads(namespace string, subnet string, collectTime time.Time, batchSize int, device metadata.DeviceMetadata, interfaces []metadata.InterfaceMetadata, links []metadata.TopologyLinkMetadata) []metadata.NetworkDevicesMetadata {
 	var payloads []metadata.NetworkDevicesMetadata
 	var resourceCount int
 	payload := metadata.NetworkDevicesMetadata{
@@ -215,6 +255,20 @@ func batchPayloads(namespace string, subnet string, collectTime time.Time, batch
 		payload.Interfaces = append(payload.Interfaces, interfaceMetadata)
 	}
 
+	for _, linkMetadata := range links {
+		if resourceCount == batchSize {
+			payloads = append(payloads, payload)
+			payload = metadata.NetworkDevicesMetadata{
+				Subnet:           subnet,
+				Namespace:        namespace,
+				CollectTimestamp: collectTime.Unix(),
+			}
+			resourceCount = 0
+		}
+		resourceCount++
+		payload.Links = append(payload.Links, linkMetadata)
+	}
+
 	payloads = append(payloads, payload)
 	return payloads
 }
@@ -9,6 +9,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
 )
 
 func Test_metricSender_reportNetworkDeviceMetadata_withoutInterfaces(t *testing.T) {
@@ -326,6 +328,13 @@ func Test_metricSender_reportNetworkDeviceMetadata_withInterfaces(t *testing.T)
 	assert.NoError(t, err)
 
 	sender.AssertEventPlatformEvent(t, compactEvent.String(), "network-devices-metadata")
+
+	device, ok := devicestore.Get("my-ns", "1.2.3.4")
+	assert.True(t, ok)
+	assert.Equal(t, "1234", device.ID)
+	assert.Equal(t, []string{"tag1", "tag2"}, device.Tags)
+	assert.Equal(t, "127.0.0.0/29", device.Subnet)
+	assert.Equal(t, devicestore.Interface{Index: 2, IDTags: []string{"interface:22"}, Name: "22"}, device.Interfaces[2])
 }
 
 func Test_metricSender_reportNetworkDeviceMetadata_fallbackOnFieldValue(t *testing.T) {
@@ -406,7 +415,7 @@ func Test_batchPayloads(t *testing.T) {
 	for i := 0; i < 350; i++ {
 		interfaces = append(interfaces, metadata.InterfaceMetadata{DeviceID: deviceID, Index: int32(i)})
 	}
-	payloads := batchPayloads("my-ns", "127.0.0.0/30", collectTime, 100, device, interfaces)
+	payloads := batchPayloads("my-ns", "127.0.0.0/30", collectTime, 100, device, interfaces, nil)
 
 	assert.Equal(t, 4, len(payloads))
 
@@ -431,3 +440,32 @@ func Test_batchPayloads(t *testing.T) {
 	assert.Equal(t, 51, len(payloads[3].Interfaces))
 	assert.Equal(t, interfaces[299:350], payloads[3].Interfaces)
 }
+
+func Test_batchPayloads_withLinks(t *testing.T) {
+	collectTime := common.MockTimeNow()
+	deviceID := "123"
+	device := metadata.DeviceMetadata{ID: deviceID}
+
+	var interfaces []metadata.InterfaceMetadata
+	for i := 0; i < 150; i++ {
+		interfaces = append(interfaces, metadata.InterfaceMetadata{DeviceID: deviceID, Index: int32(i)})
+	}
+	var links []metadata.TopologyLinkMetadata
+	for i := 0; i < 80; i++ {
+		links = append(links, metadata.TopologyLinkMetadata{ID: deviceID + ":lldp:" + strconv.Itoa(i)})
+	}
+	payloads := batchPayloads("my-ns", "127.0.0.0/30", collectTime, 100, device, interfaces, links)
+
+	assert.Equal(t, 3, len(payloads))
+
+	assert.Equal(t, []metadata.DeviceMetadata{device}, payloads[0].Devices)
+	assert.Equal(t, interfaces[0:99], payloads[0].Interfaces)
+	assert.Equal(t, 0, len(payloads[0].Links))
+
+	assert.Equal(t, interfaces[99:150], payloads[1].Interfaces)
+	assert.Equal(t, links[0:49], payloads[1].Links)
+
+	assert.Equal(t, "my-ns", payloads[2].Namespace)
+	assert.Equal(t, 0, len(payloads[2].Interfaces))
+	assert.Equal(t, links[49:80], payloads[2].Links)
+}
@@ -0,0 +1,306 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/hex"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+)
+
+const (
+	topologySourceLLDP = "lldp"
+	topologySourceCDP  = "cdp"
+)
+
+// LLDP-MIB LldpChassisIdSubtype values
+var lldpChassisIDTypes = map[int]string{
+	1: "chassis_component",
+	2: "interface_alias",
+	3: "port_component",
+	4: "mac_address",
+	5: "network_address",
+	6: "interface_name",
+	7: "local",
+}
+
+// LLDP-MIB LldpPortIdSubtype values
+var lldpPortIDTypes = map[int]string{
+	1: "interface_alias",
+	2: "port_component",
+	3: "mac_address",
+	4: "network_address",
+	5: "interface_name",
+	6: "agent_circuit_id",
+	7: "local",
+}
+
+const (
+	// IANA address family numbers, used by LLDP-MIB network addresses
+	addressFamilyIPv4 = 1
+	addressFamilyIPv6 = 2
+
+	// CISCO-CDP-MIB CiscoNetworkProtocol ip value
+	cdpAddressTypeIP = 1
+)
+
+// buildNetworkTopologyMetadata builds the links between the interfaces of the device and the interfaces of its
+// neighbors, from the LLDP and CDP remote tables
+func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	if store == nil {
+		// it's expected that the value store is nil if we can't reach the device
+		// in that case, we just return a nil slice.
+		return nil
+	}
+	links := buildLLDPLinks(deviceID, store, interfaces)
+	links = append(links, buildCDPLinks(deviceID, store, interfaces)...)
+	return links
+}
+
+func buildLLDPLinks(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	indexes := store.GetColumnIndexes("lldp_remote.interface_id")
+	if len(indexes) == 0 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	managementAddresses := getLLDPManagementAddresses(store)
+
+	var links []metadata.TopologyLinkMetadata
+	for _, strIndex := range indexes {
+		// lldpRemTable index is lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) != 3 {
+			log.Debugf("topology metadata: invalid lldp remote index: %s", strIndex)
+			continue
+		}
+		localPortNum, remIndex := indexElems[1], indexElems[2]
+
+		chassisIDType := lldpChassisIDTypes[int(store.GetColumnAsFloat("lldp_remote.chassis_id_type", strIndex))]
+		portIDType := lldpPortIDTypes[int(store.GetColumnAsFloat("lldp_remote.interface_id_type", strIndex))]
+
+		remote := &metadata.TopologyLinkSide{
+			Device: &metadata.TopologyLinkDevice{
+				ID:          formatLLDPID(store.GetColumnAsString("lldp_remote.chassis_id", strIndex), chassisIDType),
+				IDType:      chassisIDType,
+				IPAddress:   managementAddresses[strIndex],
+				Name:        store.GetColumnAsString("lldp_remote.device_name", strIndex),
+				Description: store.GetColumnAsString("lldp_remote.device_desc", strIndex),
+			},
+			Interface: &metadata.TopologyLinkInterface{
+				ID:          formatLLDPID(store.GetColumnAsString("lldp_remote.interface_id", strIndex), portIDType),
+				IDType:      portIDType,
+				Description: store.GetColumnAsString("lldp_remote.interface_desc", strIndex),
+			},
+		}
+
+		localPortIDType := lldpPortIDTypes[int(store.GetColumnAsFloat("lldp_local.interface_id_type", localPortNum))]
+		localPortID := store.GetColumnAsString("lldp_local.interface_id", localPortNum)
+		localInterface := &metadata.TopologyLinkInterface{
+			ID:     formatLLDPID(localPortID, localPortIDType),
+			IDType: localPortIDType,
+		}
+		if networkInterface, ok := resolveLLDPLocalInterface(localPortNum, localPortID, localPortIDType, interfaces); ok {
+			localInterface.DDID = buildInterfaceDDID(deviceID, networkInterface.Index)
+			if localInterface.ID == "" {
+				localInterface.ID = networkInterface.Name
+				localInterface.IDType = "interface_name"
+			}
+		}
+
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":" + topologySourceLLDP + ":" + localPortNum + "." + remIndex,
+			SourceType: topologySourceLLDP,
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: deviceID},
+				Interface: localInterface,
+			},
+			Remote: remote,
+		})
+	}
+	return links
+}
+
+// getLLDPManagementAddresses returns the management address of the neighbors, indexed by lldpRemTable index.
+// The address is not readable, it is part of the lldpRemManAddrTable index:
+// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex.lldpRemManAddrSubtype.<address length>.<address bytes>
+func getLLDPManagementAddresses(store *metadata.Store) map[string]string {
+	addresses := make(map[string]string)
+	indexes := store.GetColumnIndexes("lldp_remote_management.interface_id_type")
+	sort.Strings(indexes)
+	for _, strIndex := range indexes {
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) < 5 {
+			log.Debugf("topology metadata: invalid lldp remote management index: %s", strIndex)
+			continue
+		}
+		remoteIndex := strings.Join(indexElems[0:3], ".")
+		addrType, err := strconv.Atoi(indexElems[3])
+		if err != nil {
+			continue
+		}
+		var addrBytes []byte
+		for _, elem := range indexElems[5:] {
+			b, err := strconv.ParseUint(elem, 10, 8)
+			if err != nil {
+				addrBytes = nil
+				break
+			}
+			addrBytes = append(addrBytes, byte(b))
+		}
+		ip := formatIPAddress(addrType, addrBytes)
+		if ip == "" {
+			continue
+		}
+		// IPv4 addresses are preferred
+		if _, ok := addresses[remoteIndex]; !ok || addrType == addressFamilyIPv4 {
+			addresses[remoteIndex] = ip
+		}
+	}
+	return addresses
+}
+
+// resolveLLDPLocalInterface finds the interface of a lldpLocPortTable entry using its port id, and falls back on
+// the port number, which is the ifIndex of the interface on most devices
+func resolveLLDPLocalInterface(localPortNum string, localPortID string, localPortIDType string, interfaces []metadata.InterfaceMetadata) (metadata.InterfaceMetadata, bool) {
+	if localPortID != "" {
+		for _, networkInterface := range interfaces {
+			var match bool
+			switch localPortIDType {
+			case "interface_name":
+				match = networkInterface.Name == localPortID
+			case "interface_alias":
+				match = networkInterface.Alias == localPortID
+			case "mac_address":
+				match = networkInterface.MacAddress == localPortID
+			case "local":
+				match = networkInterface.Name == localPortID || strconv.Itoa(int(networkInterface.Index)) == localPortID
+			}
+			if match {
+				return networkInterface, true
+			}
+		}
+	}
+	return findInterfaceByIndex(localPortNum, interfaces)
+}
+
+func buildCDPLinks(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	indexes := store.GetColumnIndexes("cdp_remote.device_id")
+	if len(indexes) == 0 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	var links []metadata.TopologyLinkMetadata
+	for _, strIndex := range indexes {
+		// cdpCacheTable index is cdpCacheIfIndex.cdpCacheDeviceIndex
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) != 2 {
+			log.Debugf("topology metadata: invalid cdp cache index: %s", strIndex)
+			continue
+		}
+		ifIndex, deviceIndex := indexElems[0], indexElems[1]
+
+		var ipAddress string
+		if int(store.GetColumnAsFloat("cdp_remote.address_type", strIndex)) == cdpAddressTypeIP {
+			ipAddress = formatIPAddress(addressFamilyIPv4, valueToBytes(store.GetColumnAsString("cdp_remote.address", strIndex)))
+		}
+
+		localInterface := &metadata.TopologyLinkInterface{
+			ID:     ifIndex,
+			IDType: "if_index",
+		}
+		if networkInterface, ok := findInterfaceByIndex(ifIndex, interfaces); ok {
+			localInterface.DDID = buildInterfaceDDID(deviceID, networkInterface.Index)
+			if networkInterface.Name != "" {
+				localInterface.ID = networkInterface.Name
+				localInterface.IDType = "interface_name"
+			}
+		}
+
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":" + topologySourceCDP + ":" + ifIndex + "." + deviceIndex,
+			SourceType: topologySourceCDP,
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: deviceID},
+				Interface: localInterface,
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          store.GetColumnAsString("cdp_remote.device_id", strIndex),
+					IPAddress:   ipAddress,
+					Name:        store.GetColumnAsString("cdp_remote.device_name", strIndex),
+					Description: store.GetColumnAsString("cdp_remote.device_desc", strIndex),
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     store.GetColumnAsString("cdp_remote.interface_id", strIndex),
+					IDType: "interface_name",
+				},
+			},
+		})
+	}
+	return links
+}
+
+func findInterfaceByIndex(strIndex string, interfaces []metadata.InterfaceMetadata) (metadata.InterfaceMetadata, bool) {
+	index, err := strconv.ParseInt(strIndex, 10, 32)
+	if err != nil {
+		return metadata.InterfaceMetadata{}, false
+	}
+	for _, networkInterface := range interfaces {
+		if networkInterface.Index == int32(index) {
+			return networkInterface, true
+		}
+	}
+	return metadata.InterfaceMetadata{}, false
+}
+
+func buildInterfaceDDID(deviceID string, index int32) string {
+	return deviceID + ":" + strconv.Itoa(int(index))
+}
+
+// formatLLDPID formats the LLDP chassis and port ids holding a mac address or a network address,
+// other ids are reported as is
+func formatLLDPID(value string, idType string) string {
+	switch idType {
+	case "mac_address":
+		if b := valueToBytes(value); len(b) == 6 {
+			return net.HardwareAddr(b).String()
+		}
+	case "network_address":
+		// the address is prefixed by its IANA address family
+		if b := valueToBytes(value); len(b) > 1 {
+			if ip := formatIPAddress(int(b[0]), b[1:]); ip != "" {
+				return ip
+			}
+		}
+	}
+	return value
+}
+
+func formatIPAddress(addressFamily int, b []byte) string {
+	if (addressFamily == addressFamilyIPv4 && len(b) == net.IPv4len) || (addressFamily == addressFamilyIPv6 && len(b) == net.IPv6len) {
+		return net.IP(b).String()
+	}
+	return ""
+}
+
+// valueToBytes returns the bytes of an OctetString value, which is hexified when it's not printable,
+// see gosnmplib.GetValueFromPDU
+func valueToBytes(value string) []byte {
+	if strings.HasPrefix(value, "0x") {
+		if b, err := hex.DecodeString(value[2:]); err == nil {
+			return b
+		}
+	}
+	return []byte(value)
+}
@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+func topologyMetadataConfig() checkconfig.MetadataConfig {
+	metadataConfig := checkconfig.MetadataConfig{}
+	for resourceName, resourceConfig := range checkconfig.LegacyMetadataConfig {
+		metadataConfig[resourceName] = resourceConfig
+	}
+	for resourceName, resourceConfig := range checkconfig.TopologyMetadataConfig {
+		metadataConfig[resourceName] = resourceConfig
+	}
+	return metadataConfig
+}
+
+// loadWalkFixture loads the column values of the metadata config from a `snmpwalk -On` output
+func loadWalkFixture(t *testing.T, name string, metadataConfig checkconfig.MetadataConfig) *valuestore.ResultValueStore {
+	var columnOids []string
+	for resourceName, resourceConfig := range metadataConfig {
+		if checkconfig.IsMetadataResourceWithScalarOids(resourceName) {
+			continue
+		}
+		for _, field := range resourceConfig.Fields {
+			columnOids = append(columnOids, field.Symbol.OID)
+		}
+	}
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+
+	store := &valuestore.ResultValueStore{ColumnValues: valuestore.ColumnResultValuesType{}}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), " = ", 2)
+		require.Len(t, parts, 2, "invalid walk line: %s", scanner.Text())
+		typeAndValue := strings.SplitN(parts[1], ": ", 2)
+		require.Len(t, typeAndValue, 2, "invalid walk line: %s", scanner.Text())
+
+		pdu := gosnmp.SnmpPDU{Name: parts[0]}
+		switch typeAndValue[0] {
+		case "STRING":
+			pdu.Type = gosnmp.OctetString
+			pdu.Value = []byte(strings.Trim(typeAndValue[1], `"`))
+		case "Hex-STRING":
+			pdu.Type = gosnmp.OctetString
+			pdu.Value, err = hex.DecodeString(strings.ReplaceAll(typeAndValue[1], " ", ""))
+			require.NoError(t, err)
+		case "INTEGER":
+			pdu.Type = gosnmp.Integer
+			pdu.Value, err = strconv.Atoi(typeAndValue[1])
+			require.NoError(t, err)
+		default:
+			require.Failf(t, "unsupported walk value type", "line: %s", scanner.Text())
+		}
+		oid, value, err := gosnmplib.GetValueFromPDU(pdu)
+		require.NoError(t, err)
+
+		for _, columnOid := range columnOids {
+			if strings.HasPrefix(oid, columnOid+".") {
+				if _, ok := store.ColumnValues[columnOid]; !ok {
+					store.ColumnValues[columnOid] = make(map[string]valuestore.ResultValue)
+				}
+				store.ColumnValues[columnOid][oid[len(columnOid)+1:]] = value
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return store
+}
+
+func Test_buildNetworkTopologyMetadata_lldp(t *testing.T) {
+	metadataConfig := topologyMetadataConfig()
+	store := buildMetadataStore(metadataConfig, loadWalkFixture(t, "lldp.snmpwalk", metadataConfig))
+	interfaces := buildNetworkInterfacesMetadata("default:10.0.0.10", store)
+	require.Len(t, interfaces, 3)
+
+	links := buildNetworkTopologyMetadata("default:10.0.0.10", store, interfaces)
+
+	localDevice := &metadata.TopologyLinkDevice{DDID: "default:10.0.0.10"}
+	assert.Equal(t, []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:10.0.0.10:lldp:1.1",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					DDID:   "default:10.0.0.10:1",
+					ID:     "Ethernet1",
+					IDType: "interface_name",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "00:1c:73:aa:bb:01",
+					IDType:      "mac_address",
+					IPAddress:   "10.0.0.1",
+					Name:        "spine1.example.com",
+					Description: "Arista Networks EOS version 4.27.0F running on an Arista Networks DCS-7280SR-48C6",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:          "Ethernet49/1",
+					IDType:      "interface_name",
+					Description: "to leaf1 Ethernet1",
+				},
+			},
+		},
+		{
+			ID:         "default:10.0.0.10:lldp:2.3",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					DDID:   "default:10.0.0.10:2",
+					ID:     "Ethernet2",
+					IDType: "interface_name",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "00:1c:73:aa:bb:02",
+					IDType:      "mac_address",
+					IPAddress:   "10.0.0.2", // IPv4 management address is preferred
+					Name:        "spine2.example.com",
+					Description: "Arista Networks EOS version 4.27.0F running on an Arista Networks DCS-7280SR-48C6",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:          "00:1c:73:aa:bb:31",
+					IDType:      "mac_address",
+					Description: "to leaf1 Ethernet2",
+				},
+			},
+		},
+		{
+			// the local port number is not the ifIndex, the interface is resolved using its name
+			ID:         "default:10.0.0.10:lldp:3.1",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					DDID:   "default:10.0.0.10:999001",
+					ID:     "Management1",
+					IDType: "interface_name",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "192.168.1.10",
+					IDType:      "network_address",
+					Name:        "oob-switch",
+					Description: "Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:          "24",
+					IDType:      "local",
+					Description: "GigabitEthernet0/24",
+				},
+			},
+		},
+	}, links)
+}
+
+func Test_buildNetworkTopologyMetadata_cdp(t *testing.T) {
+	metadataConfig := topologyMetadataConfig()
+	store := buildMetadataStore(metadataConfig, loadWalkFixture(t, "cdp.snmpwalk", metadataConfig))
+	interfaces := buildNetworkInterfacesMetadata("default:10.0.0.20", store)
+	require.Len(t, interfaces, 2)
+
+	links := buildNetworkTopologyMetadata("default:10.0.0.20", store, interfaces)
+
+	localDevice := &metadata.TopologyLinkDevice{DDID: "default:10.0.0.20"}
+	assert.Equal(t, []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:10.0.0.20:cdp:10101.1",
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					DDID:   "default:10.0.0.20:10101",
+					ID:     "Gi1/0/1",
+					IDType: "interface_name",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "access1.example.com",
+					IPAddress:   "10.0.0.11",
+					Name:        "access1",
+					Description: "cisco WS-C2960X-48FPD-L",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "GigabitEthernet0/1",
+					IDType: "interface_name",
+				},
+			},
+		},
+		{
+			// the printable address bytes are not hexified
+			ID:         "default:10.0.0.20:cdp:10102.3",
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					DDID:   "default:10.0.0.20:10102",
+					ID:     "Gi1/0/2",
+					IDType: "interface_name",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "SEP001122334455",
+					IPAddress:   "65.66.67.68",
+					Description: "Cisco IP Phone 8845",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "Port 1",
+					IDType: "interface_name",
+				},
+			},
+		},
+		{
+			// unknown local interface
+			ID:         "default:10.0.0.20:cdp:10199.2",
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device: localDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "10199",
+					IDType: "if_index",
+				},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "ap1",
+					IPAddress:   "10.0.0.13",
+					Description: "cisco AIR-AP2802I-E-K9",
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     "GigabitEthernet0",
+					IDType: "interface_name",
+				},
+			},
+		},
+	}, links)
+}
+
+func Test_buildNetworkTopologyMetadata_noNeighbors(t *testing.T) {
+	assert.Nil(t, buildNetworkTopologyMetadata("default:10.0.0.20", nil, nil))
+
+	metadataConfig := topologyMetadataConfig()
+	store := buildMetadataStore(metadataConfig, &valuestore.ResultValueStore{})
+	assert.Nil(t, buildNetworkTopologyMetadata("default:10.0.0.20", store, nil))
+}
+
+func Test_metricSender_reportNetworkDeviceMetadata_withLinks(t *testing.T) {
+	metadataConfig := topologyMetadataConfig()
+	values := loadWalkFixture(t, "cdp.snmpwalk", metadataConfig)
+
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	ms := &MetricSender{
+		sender: sender,
+	}
+
+	config := &checkconfig.CheckConfig{
+		IPAddress:       "10.0.0.20",
+		DeviceID:        "default:10.0.0.20",
+		Namespace:       "default",
+		Metadata:        metadataConfig,
+		CollectTopology: true,
+	}
+	ms.ReportNetworkDeviceMetadata(config, values, nil, time.Now(), metadata.DeviceStatusReachable)
+
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+	var payload metadata.NetworkDevicesMetadata
+	require.NoError(t, json.Unmarshal([]byte(sender.Calls[0].Arguments.String(0)), &payload))
+	assert.Len(t, payload.Interfaces, 2)
+	require.Len(t, payload.Links, 3)
+	assert.Equal(t, "default:10.0.0.20:cdp:10101.1", payload.Links[0].ID)
+	assert.Equal(t, "access1.example.com", payload.Links[0].Remote.Device.ID)
+
+	// links are not reported when topology collection is disabled
+	config.CollectTopology = false
+	ms.ReportNetworkDeviceMetadata(config, values, nil, time.Now(), metadata.DeviceStatusReachable)
+
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+	payload = metadata.NetworkDevicesMetadata{}
+	require.NoError(t, json.Unmarshal([]byte(sender.Calls[1].Arguments.String(0)), &payload))
+	assert.Len(t, payload.Interfaces, 2)
+	assert.Empty(t, payload.Links)
+}
+
+func Test_formatLLDPID(t *testing.T) {
+	tests := []struct {
+		value    string
+		idType   string
+		expected string
+	}{
+		{"0x001c73aabb01", "mac_address", "00:1c:73:aa:bb:01"},
+		{"0x001c73", "mac_address", "0x001c73"},
+		{"0x01c0a8010a", "network_address", "192.168.1.10"},
+		{"0x0220010db8000000000000000000000001", "network_address", "2001:db8::1"},
+		{"0x03c0a8010a", "network_address", "0x03c0a8010a"},
+		{"Ethernet1", "interface_name", "Ethernet1"},
+		{"0x001c73aabb01", "local", "0x001c73aabb01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idType+"/"+tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatLLDPID(tt.value, tt.idType))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/gosnmplib"
+)
+
+// FileSession is a Session replaying the values of a snmprec recording, e.g. recorded with `agent snmp walk`.
+// It is used to simulate a device.
+type FileSession struct {
+	// pdus are sorted by OID
+	pdus []gosnmp.SnmpPDU
+}
+
+// NewFileSession creates a session replaying the snmprec recording at path
+func NewFileSession(path string) (*FileSession, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %s", err)
+	}
+	defer f.Close()
+
+	pdus, err := gosnmplib.ReadSnmprec(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording `%s`: %s", path, err)
+	}
+	return newFileSession(pdus), nil
+}
+
+func newFileSession(pdus []gosnmp.SnmpPDU) *FileSession {
+	sort.SliceStable(pdus, func(i, j int) bool {
+		return gosnmplib.CompareOIDs(pdus[i].Name, pdus[j].Name) < 0
+	})
+	return &FileSession{pdus: pdus}
+}
+
+// NewFileSessionFactory returns a Factory creating sessions replaying the snmprec recording at path.
+// The recording is read once, the sessions are shared by all the devices.
+func NewFileSessionFactory(path string) (Factory, error) {
+	sess, err := NewFileSession(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(config *checkconfig.CheckConfig) (Session, error) {
+		return sess, nil
+	}, nil
+}
+
+// Connect is used to create a new connection
+func (s *FileSession) Connect() error {
+	return nil
+}
+
+// Close is used to close the connection
+func (s *FileSession) Close() error {
+	return nil
+}
+
+// Get will send a SNMPGET command
+func (s *FileSession) Get(oids []string) (result *gosnmp.SnmpPacket, err error) {
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		i := s.search(oid)
+		if i < len(s.pdus) && gosnmplib.CompareOIDs(s.pdus[i].Name, oid) == 0 {
+			packet.Variables = append(packet.Variables, s.pdus[i])
+		} else {
+			packet.Variables = append(packet.Variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject})
+		}
+	}
+	return packet, nil
+}
+
+// GetBulk will send a SNMP BULKGET command
+func (s *FileSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (result *gosnmp.SnmpPacket, err error) {
+	packet := &gosnmp.SnmpPacket{}
+	// the variables of each repetition are the next values of the variables of the previous repetition
+	nextOids := append([]string(nil), oids...)
+	for r := uint32(0); r < bulkMaxRepetitions; r++ {
+		for i, oid := range nextOids {
+			pdu := s.getNext(oid)
+			packet.Variables = append(packet.Variables, pdu)
+			nextOids[i] = pdu.Name
+		}
+	}
+	return packet, nil
+}
+
+// GetNext will send a SNMP GETNEXT command
+func (s *FileSession) GetNext(oids []string) (result *gosnmp.SnmpPacket, err error) {
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		packet.Variables = append(packet.Variables, s.getNext(oid))
+	}
+	return packet, nil
+}
+
+// GetVersion returns the snmp version used
+func (s *FileSession) GetVersion() gosnmp.SnmpVersion {
+	return gosnmp.Version2c
+}
+
+func (s *FileSession) getNext(oid string) gosnmp.SnmpPDU {
+	i := s.search(oid)
+	if i < len(s.pdus) && gosnmplib.CompareOIDs(s.pdus[i].Name, oid) == 0 {
+		i++
+	}
+	if i >= len(s.pdus) {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+	return s.pdus[i]
+}
+
+// search returns the index of the first PDU with an OID greater than or equal to oid
+func (s *FileSession) search(oid string) int {
+	return sort.Search(len(s.pdus), func(i int) bool {
+		return gosnmplib.CompareOIDs(s.pdus[i].Name, oid) >= 0
+	})
+}
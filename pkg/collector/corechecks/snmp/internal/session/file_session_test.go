@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestFileSession() *FileSession {
+	return newFileSession([]gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(101)},
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device")},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: []byte("eth1")},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(102)},
+	})
+}
+
+func TestFileSession_Get(t *testing.T) {
+	sess := createTestFileSession()
+
+	result, err := sess.Get([]string{"1.3.6.1.2.1.1.1.0", "1.3.6.1.2.1.1.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device")},
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.NoSuchObject},
+	}, result.Variables)
+}
+
+func TestFileSession_GetNext(t *testing.T) {
+	sess := createTestFileSession()
+
+	result, err := sess.GetNext([]string{"1.3", "1.3.6.1.2.1.2.2.1.2.2", "1.3.6.1.2.1.2.2.1.10.2"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device")},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(101)},
+		{Name: "1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.EndOfMibView},
+	}, result.Variables)
+}
+
+func TestFileSession_GetBulk(t *testing.T) {
+	sess := createTestFileSession()
+
+	result, err := sess.GetBulk([]string{"1.3.6.1.2.1.2.2.1.2", "1.3.6.1.2.1.2.2.1.10"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(101)},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: []byte("eth1")},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(102)},
+	}, result.Variables)
+}
+
+func TestNewFileSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device.snmprec")
+	require.NoError(t, os.WriteFile(path, []byte("1.3.6.1.2.1.1.3.0|67|20\n1.3.6.1.2.1.1.1.0|4|my device\n"), 0644))
+
+	sess, err := NewFileSession(path)
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("my device")},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint(20)},
+	}, sess.pdus)
+
+	_, err = NewFileSession(filepath.Join(t.TempDir(), "missing.snmprec"))
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name         string
+		rootOid      string
+		useGetNext   bool
+		expectedOids []string
+	}{
+		{
+			name:    "whole tree with getbulk",
+			rootOid: "",
+			expectedOids: []string{
+				".1.3.6.1.2.1.1.1.0",
+				".1.3.6.1.2.1.2.2.1.2.1",
+				".1.3.6.1.2.1.2.2.1.2.2",
+				".1.3.6.1.2.1.2.2.1.10.1",
+				".1.3.6.1.2.1.2.2.1.10.2",
+			},
+		},
+		{
+			name:       "subtree with getnext",
+			rootOid:    "1.3.6.1.2.1.2.2.1.2",
+			useGetNext: true,
+			expectedOids: []string{
+				".1.3.6.1.2.1.2.2.1.2.1",
+				".1.3.6.1.2.1.2.2.1.2.2",
+			},
+		},
+		{
+			name:         "scalar",
+			rootOid:      ".1.3.6.1.2.1.1.1.0",
+			expectedOids: []string{".1.3.6.1.2.1.1.1.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oids []string
+			err := Walk(createTestFileSession(), tt.rootOid, 2, tt.useGetNext, func(pdu gosnmp.SnmpPDU) error {
+				oids = append(oids, pdu.Name)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOids, oids)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/gosnmplib"
+)
+
+// walkStartOid is the OID preceding all the OIDs of a device, used when the whole tree is walked
+const walkStartOid = "0.0"
+
+// Walk retrieves the values of the OID tree under rootOid, or of the whole device tree if rootOid is empty,
+// and calls walkFn for each of them in OID order.
+// GetBulk is used unless the device only supports SNMP v1 or useGetNext is set.
+func Walk(sess Session, rootOid string, bulkMaxRepetitions uint32, useGetNext bool, walkFn func(pdu gosnmp.SnmpPDU) error) error {
+	rootOid = strings.Trim(rootOid, ".")
+	curOid := rootOid
+	if curOid == "" {
+		curOid = walkStartOid
+	}
+
+	if rootOid != "" {
+		// the root OID might be a scalar value
+		result, err := sess.Get([]string{rootOid})
+		if err != nil {
+			log.Debugf("walk: failed to get root oid `%s`: %s", rootOid, err)
+			result = &gosnmp.SnmpPacket{}
+		}
+		for _, pdu := range result.Variables {
+			if isValuePDU(pdu) {
+				if err := walkFn(pdu); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for {
+		var result *gosnmp.SnmpPacket
+		var err error
+		if useGetNext || sess.GetVersion() == gosnmp.Version1 {
+			result, err = sess.GetNext([]string{curOid})
+		} else {
+			result, err = sess.GetBulk([]string{curOid}, bulkMaxRepetitions)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to walk oid `%s`: %s", curOid, err)
+		}
+		if len(result.Variables) == 0 {
+			return nil
+		}
+
+		for _, pdu := range result.Variables {
+			if !isValuePDU(pdu) {
+				return nil
+			}
+			oid := strings.TrimLeft(pdu.Name, ".")
+			if rootOid != "" && !strings.HasPrefix(oid, rootOid+".") {
+				return nil
+			}
+			if gosnmplib.CompareOIDs(oid, curOid) <= 0 {
+				return fmt.Errorf("oid not increasing: `%s` returned after `%s`", oid, curOid)
+			}
+			if err := walkFn(pdu); err != nil {
+				return err
+			}
+			curOid = oid
+		}
+	}
+}
+
+func isValuePDU(pdu gosnmp.SnmpPDU) bool {
+	switch pdu.Type {
+	case gosnmp.EndOfContents, gosnmp.EndOfMibView, gosnmp.NoSuchInstance, gosnmp.NoSuchObject:
+		return false
+	}
+	return true
+}
@@ -363,3 +363,113 @@ profiles:
 
 	sender.AssertEventPlatformEvent(t, compactEvent.String(), "network-devices-metadata")
 }
+
+func TestProfileSimulation_f5(t *testing.T) {
+	timeNow = common.MockTimeNow
+	aggregator.InitAggregatorWithFlushInterval(nil, nil, "", 1*time.Hour)
+	confdPath, _ := filepath.Abs(filepath.Join("internal", "test", "metadata.d"))
+	config.Datadog.Set("confd_path", confdPath)
+
+	chk, err := NewSimulatedCheck(filepath.Join("internal", "test", "recordings", "f5-big-ip.snmprec"))
+	assert.NoError(t, err)
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+namespace: profile-simulation
+collect_device_metadata: true
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+profiles:
+  f5-big-ip:
+    definition_file: f5-big-ip.yaml
+`)
+
+	err = chk.Configure(rawInstanceConfig, rawInitConfig, "test")
+	assert.NoError(t, err)
+
+	sender := mocksender.NewMockSender(chk.ID()) // required to initiate aggregator
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Commit").Return()
+
+	err = chk.Run()
+	assert.Nil(t, err)
+
+	tags := []string{"device_namespace:profile-simulation", "device_vendor:f5", "snmp_device:1.2.3.4", "snmp_host:foo_sys_name", "snmp_profile:f5-big-ip"}
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", float64(20), "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInErrors", float64(141), "", append(common.CopyStrings(tags), "interface:nameRow1"))
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInDiscards", float64(132), "", append(common.CopyStrings(tags), "interface:nameRow2"))
+
+	// language=json
+	event := []byte(fmt.Sprintf(`
+{
+  "subnet": "",
+  "namespace":"profile-simulation",
+  "devices": [
+    {
+      "id": "profile-simulation:1.2.3.4",
+      "id_tags": [
+        "device_namespace:profile-simulation",
+        "snmp_device:1.2.3.4"
+      ],
+      "tags": [
+        "agent_version:%s",
+        "device_namespace:profile-simulation",
+        "device_vendor:f5",
+        "snmp_device:1.2.3.4",
+        "snmp_host:foo_sys_name",
+        "snmp_profile:f5-big-ip"
+      ],
+      "ip_address": "1.2.3.4",
+      "status": 1,
+      "name": "foo_sys_name",
+      "description": "BIG-IP Virtual Edition : Linux 3.10.0-862.14.4.el7.ve.x86_64 : BIG-IP software release 15.0.1, build 0.0.11",
+      "sys_object_id": "1.3.6.1.4.1.3375.2.1.3.4.1",
+      "location": "paris",
+      "profile": "f5-big-ip",
+      "vendor": "f5",
+      "serial_number": "a-serial-num",
+      "version":"15.0.1",
+      "product_name":"BIG-IP",
+      "model":"Final",
+      "os_name":"LINUX (3.10.0-862.14.4.el7.ve.x86_64)",
+      "os_version":"3.10.0-862.14.4.el7.ve.x86_64",
+      "os_hostname":"my-linux-f5-server"
+    }
+  ],
+  "interfaces": [
+    {
+      "device_id": "profile-simulation:1.2.3.4",
+      "id_tags": ["interface:nameRow1"],
+      "index": 1,
+      "name": "nameRow1",
+      "alias": "descRow1",
+      "description": "ifDesc1",
+      "mac_address": "0x000000000001",
+      "admin_status": 1,
+      "oper_status": 1
+    },
+    {
+      "device_id": "profile-simulation:1.2.3.4",
+      "id_tags": ["interface:nameRow2"],
+      "index": 2,
+      "name": "nameRow2",
+      "alias": "descRow2",
+      "description": "ifDesc2",
+      "mac_address": "0x000000000002",
+      "admin_status": 1,
+      "oper_status": 2
+    }
+  ],
+  "collect_timestamp":946684800
+}
+`, version.AgentVersion))
+	compactEvent := new(bytes.Buffer)
+	err = json.Compact(compactEvent, event)
+	assert.NoError(t, err)
+
+	sender.AssertEventPlatformEvent(t, compactEvent.String(), "network-devices-metadata")
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// NewSimulatedCheck returns a snmp check replaying the snmprec recording at recordingPath, e.g. recorded with
+// `agent snmp walk`, instead of querying a device. It's used to show what a profile produces without the device.
+func NewSimulatedCheck(recordingPath string) (check.Check, error) {
+	sessionFactory, err := session.NewFileSessionFactory(recordingPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Check{
+		CheckBase:      core.NewCheckBase(common.SnmpIntegrationName),
+		sessionFactory: sessionFactory,
+	}, nil
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// WalkConfig holds the connection parameters of the device walked by `agent snmp walk`
+type WalkConfig struct {
+	IPAddress       string
+	Port            uint16
+	SnmpVersion     string
+	CommunityString string
+	User            string
+	AuthProtocol    string
+	AuthKey         string
+	PrivProtocol    string
+	PrivKey         string
+	ContextName     string
+	Timeout         int
+	Retries         int
+
+	BulkMaxRepetitions uint32
+	UseGetNext         bool
+}
+
+// Walk walks the OID tree of a device under rootOid, or the whole tree if rootOid is empty, and writes the values
+// to w as snmprec records. The recording can be replayed with `agent snmp check --simulate`.
+// It returns the number of written records.
+func Walk(walkConfig WalkConfig, rootOid string, w io.Writer) (int, error) {
+	return walk(walkConfig, rootOid, w, session.NewGosnmpSession)
+}
+
+func walk(walkConfig WalkConfig, rootOid string, w io.Writer, sessionFactory session.Factory) (int, error) {
+	config := &checkconfig.CheckConfig{
+		IPAddress:       walkConfig.IPAddress,
+		Port:            walkConfig.Port,
+		SnmpVersion:     walkConfig.SnmpVersion,
+		CommunityString: walkConfig.CommunityString,
+		User:            walkConfig.User,
+		AuthProtocol:    walkConfig.AuthProtocol,
+		AuthKey:         walkConfig.AuthKey,
+		PrivProtocol:    walkConfig.PrivProtocol,
+		PrivKey:         walkConfig.PrivKey,
+		ContextName:     walkConfig.ContextName,
+		Timeout:         walkConfig.Timeout,
+		Retries:         walkConfig.Retries,
+	}
+	bulkMaxRepetitions := walkConfig.BulkMaxRepetitions
+	if bulkMaxRepetitions == 0 {
+		bulkMaxRepetitions = checkconfig.DefaultBulkMaxRepetitions
+	}
+
+	sess, err := sessionFactory(config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to configure session: %s", err)
+	}
+	if err := sess.Connect(); err != nil {
+		return 0, fmt.Errorf("snmp connection error: %s", err)
+	}
+	defer func() {
+		if err := sess.Close(); err != nil {
+			log.Warnf("failed to close session: %v", err)
+		}
+	}()
+
+	var count int
+	err = session.Walk(sess, rootOid, bulkMaxRepetitions, walkConfig.UseGetNext, func(pdu gosnmp.SnmpPDU) error {
+		record, err := gosnmplib.FormatSnmprecRecord(pdu)
+		if err != nil {
+			log.Warnf("skipping value: %s", err)
+			return nil
+		}
+		if _, err := io.WriteString(w, record+"\n"); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+func Test_walk(t *testing.T) {
+	sessionFactory, err := session.NewFileSessionFactory(filepath.Join("internal", "test", "recordings", "f5-big-ip.snmprec"))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	count, err := walk(WalkConfig{IPAddress: "1.2.3.4", CommunityString: "public"}, "1.3.6.1.2.1.2.2.1.6", buf, sessionFactory)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "1.3.6.1.2.1.2.2.1.6.1|4x|000000000001\n1.3.6.1.2.1.2.2.1.6.2|4x|000000000002\n", buf.String())
+}
+
+func Test_walk_roundTrip(t *testing.T) {
+	recordingPath := filepath.Join("internal", "test", "recordings", "f5-big-ip.snmprec")
+	sessionFactory, err := session.NewFileSessionFactory(recordingPath)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	count, err := walk(WalkConfig{IPAddress: "1.2.3.4", CommunityString: "public"}, "", buf, sessionFactory)
+	require.NoError(t, err)
+	assert.Equal(t, 28, count)
+
+	// the recording of a simulated device is the same as the original recording
+	path := filepath.Join(t.TempDir(), "walk.snmprec")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	original, err := session.NewFileSession(recordingPath)
+	require.NoError(t, err)
+	recorded, err := session.NewFileSession(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, recorded)
+}
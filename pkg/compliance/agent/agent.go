@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event/sinks"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -33,6 +34,7 @@ type Scheduler interface {
 
 // Agent defines Compliance Agent
 type Agent struct {
+	reporter  event.Reporter
 	builder   checks.Builder
 	scheduler Scheduler
 	telemetry *telemetry
@@ -57,6 +59,7 @@ func New(reporter event.Reporter, scheduler Scheduler, configDir string, endpoin
 	}
 
 	return &Agent{
+		reporter:  reporter,
 		builder:   builder,
 		scheduler: scheduler,
 		configDir: configDir,
@@ -196,7 +199,13 @@ func (a *Agent) buildChecks(onCheck compliance.CheckVisitor) error {
 
 // GetStatus returns the agent status
 func (a *Agent) GetStatus() map[string]interface{} {
-	return map[string]interface{}{
+	status := map[string]interface{}{
 		"endpoints": a.endpoints.GetStatus(),
 	}
+
+	if sinksReporter, ok := a.reporter.(*sinks.Reporter); ok {
+		status["sinks"] = sinksReporter.GetStatus()
+	}
+
+	return status
 }
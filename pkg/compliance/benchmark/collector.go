@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// Collector is a reporter gathering the events of the checks run from suite files, to build
+// the report of the run. The events are forwarded to an optional reporter.
+type Collector struct {
+	sync.Mutex
+	forward   event.Reporter
+	startTime time.Time
+	suites    []*SuiteReport
+	rules     map[string]*RuleReport
+}
+
+// NewCollector returns a new collector
+func NewCollector(forward event.Reporter) *Collector {
+	return &Collector{
+		forward:   forward,
+		startTime: time.Now(),
+		suites:    []*SuiteReport{},
+		rules:     make(map[string]*RuleReport),
+	}
+}
+
+// RunFile runs the checks of a suite file, the collector being the reporter of the builder
+func (c *Collector) RunFile(builder checks.Builder, file string) error {
+	suite, err := compliance.ParseSuite(file)
+	if err != nil {
+		return err
+	}
+
+	report := &SuiteReport{
+		Name:      suite.Meta.Name,
+		Framework: suite.Meta.Framework,
+		Version:   suite.Meta.Version,
+		Source:    file,
+	}
+
+	if err := builder.ChecksFromFile(file, c.visitor(report)); err != nil {
+		return err
+	}
+
+	// the suites filtered out by the builder have no rule
+	if len(report.Rules) > 0 {
+		c.Lock()
+		c.suites = append(c.suites, report)
+		c.Unlock()
+	}
+	return nil
+}
+
+func (c *Collector) visitor(suite *SuiteReport) compliance.CheckVisitor {
+	return func(rule *compliance.RuleCommon, check compliance.Check, err error) bool {
+		ruleReport := &RuleReport{
+			ID:          rule.ID,
+			Description: rule.Description,
+		}
+		suite.Rules = append(suite.Rules, ruleReport)
+
+		if err != nil {
+			log.Infof("%s: not running check: %v", rule.ID, err)
+			ruleReport.Status = StatusSkipped
+			ruleReport.Reason = err.Error()
+			return true
+		}
+
+		key := ruleKey(suite.Framework, rule.ID)
+		c.Lock()
+		c.rules[key] = ruleReport
+		c.Unlock()
+
+		log.Infof("%s: running check: %s [version=%s]", rule.ID, check.String(), check.Version())
+		start := time.Now()
+		if err := check.Run(); err != nil {
+			log.Errorf("%s: check failed: %v", rule.ID, err)
+		}
+		ruleReport.Duration = time.Since(start)
+
+		c.Lock()
+		delete(c.rules, key)
+		c.Unlock()
+
+		ruleReport.Status = ruleStatus(ruleReport.Findings)
+		if ruleReport.Status == StatusSkipped {
+			ruleReport.Reason = "no resource reported"
+		}
+		return true
+	}
+}
+
+// Report adds the finding of an event to the rule being run
+func (c *Collector) Report(e *event.Event) {
+	c.Lock()
+	if rule, found := c.rules[ruleKey(e.AgentFrameworkID, e.AgentRuleID)]; found {
+		rule.Findings = append(rule.Findings, &Finding{
+			Status:       Status(e.Result),
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceID,
+			Data:         e.Data,
+		})
+	}
+	c.Unlock()
+
+	if c.forward != nil {
+		c.forward.Report(e)
+	}
+}
+
+// ReportRaw forwards a raw event
+func (c *Collector) ReportRaw(content []byte, service string, tags ...string) {
+	if c.forward != nil {
+		c.forward.ReportRaw(content, service, tags...)
+	}
+}
+
+// Build returns the report of the suites run so far
+func (c *Collector) Build(hostname string) *Report {
+	c.Lock()
+	defer c.Unlock()
+
+	report := &Report{
+		Hostname:     hostname,
+		AgentVersion: version.AgentVersion,
+		StartTime:    c.startTime,
+		EndTime:      time.Now(),
+		Suites:       c.suites,
+	}
+
+	for _, suite := range c.suites {
+		suite.Summary = Summary{}
+		for _, rule := range suite.Rules {
+			suite.Summary.add(rule.Status)
+		}
+		suite.Summary.computeScore()
+		report.Summary.merge(suite.Summary)
+	}
+	report.Summary.computeScore()
+
+	return report
+}
+
+func ruleKey(framework, ruleID string) string {
+	return framework + "/" + ruleID
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+const testSuite = `
+schema:
+  version: 1.0
+name: CIS Docker Generic
+framework: cis-docker
+version: 1.2.0
+rules:
+- id: cis-docker-1
+  description: daemon.json file permissions are set to 644 or more restrictive
+  scope:
+    - docker
+  resources:
+    - file:
+        path: /etc/docker/daemon.json
+      condition: file.permissions == 0644
+- id: cis-docker-2
+  description: daemon.json file permissions are set to 600 or more restrictive
+  scope:
+    - docker
+  resources:
+    - file:
+        path: /etc/docker/daemon.json
+      condition: file.permissions == 0600
+- id: cis-docker-3
+  scope:
+    - docker
+  resources:
+    - file:
+        path: /etc/docker/daemon.json
+      condition: file.unknownFunction("foo")
+- id: cis-docker-4
+  scope:
+    - kubernetesNode
+  resources:
+    - file:
+        path: /etc/kubernetes/kubelet.conf
+      condition: file.permissions == 0644
+`
+
+const testOtherSuite = `
+schema:
+  version: 1.0
+name: CIS Kubernetes Generic
+framework: cis-kubernetes
+version: 1.6.0
+rules:
+- id: cis-kubernetes-1
+  scope:
+    - kubernetesNode
+  resources:
+    - file:
+        path: /etc/kubernetes/kubelet.conf
+      condition: file.permissions == 0644
+`
+
+func writeTestFile(t *testing.T, path, content string, perm os.FileMode) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), perm))
+	require.NoError(t, os.Chmod(path, perm))
+}
+
+// runTestSuites runs the test suites on a temporary host root and returns the report
+func runTestSuites(t *testing.T, options ...checks.BuilderOption) *Report {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "etc/docker/daemon.json"), "{}", 0644)
+	writeTestFile(t, filepath.Join(dir, "cis-docker.yaml"), testSuite, 0644)
+	writeTestFile(t, filepath.Join(dir, "cis-kubernetes.yaml"), testOtherSuite, 0644)
+
+	dockerClient := &mocks.DockerClient{}
+	dockerClient.On("Close").Return(nil).Once()
+	defer dockerClient.AssertExpectations(t)
+
+	reporter := &mocks.Reporter{}
+	collector := NewCollector(reporter)
+
+	options = append(options,
+		checks.WithHostname("the-host"),
+		checks.WithHostRootMount(dir),
+		checks.WithDockerClient(dockerClient),
+	)
+	builder, err := checks.NewBuilder(collector, options...)
+	require.NoError(t, err)
+	defer builder.Close()
+
+	reporter.On("Report", mock.Anything).Times(3)
+	defer reporter.AssertExpectations(t)
+
+	require.NoError(t, collector.RunFile(builder, filepath.Join(dir, "cis-docker.yaml")))
+	require.NoError(t, collector.RunFile(builder, filepath.Join(dir, "cis-kubernetes.yaml")))
+
+	return collector.Build("the-host")
+}
+
+func TestCollector(t *testing.T) {
+	report := runTestSuites(t, checks.WithMatchSuite(checks.IsFramework("cis-docker")))
+
+	assert := assert.New(t)
+	assert.Equal("the-host", report.Hostname)
+	require.Len(t, report.Suites, 1)
+
+	suite := report.Suites[0]
+	assert.Equal("cis-docker", suite.Framework)
+	assert.Equal("1.2.0", suite.Version)
+	require.Len(t, suite.Rules, 4)
+
+	assert.Equal(StatusPassed, suite.Rules[0].Status)
+	assert.Equal("daemon.json file permissions are set to 644 or more restrictive", suite.Rules[0].Description)
+	require.Len(t, suite.Rules[0].Findings, 1)
+	assert.Equal("the-host_daemon", suite.Rules[0].Findings[0].ResourceID)
+	assert.Equal("docker_daemon", suite.Rules[0].Findings[0].ResourceType)
+	assert.Empty(suite.Rules[0].FailedFindings())
+
+	assert.Equal(StatusFailed, suite.Rules[1].Status)
+	assert.Len(suite.Rules[1].FailedFindings(), 1)
+
+	assert.Equal(StatusError, suite.Rules[2].Status)
+	assert.NotEmpty(findingError(suite.Rules[2].Findings[0]))
+
+	assert.Equal(StatusSkipped, suite.Rules[3].Status)
+	assert.NotEmpty(suite.Rules[3].Reason)
+	assert.Empty(suite.Rules[3].Findings)
+
+	expected := Summary{Total: 4, Passed: 1, Failed: 1, Errors: 1, Skipped: 1, Score: 33.33}
+	assert.Equal(expected, suite.Summary)
+	assert.Equal(expected, report.Summary)
+}
+
+func TestSummary(t *testing.T) {
+	var summary Summary
+	summary.computeScore()
+	assert.Equal(t, 100.0, summary.Score)
+
+	for _, status := range []Status{StatusPassed, StatusPassed, StatusFailed, StatusSkipped} {
+		summary.add(status)
+	}
+	summary.computeScore()
+	assert.Equal(t, Summary{Total: 4, Passed: 2, Failed: 1, Skipped: 1, Score: 66.67}, summary)
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// Format is the format of a report
+type Format string
+
+const (
+	// JSONFormat writes the report as a JSON document
+	JSONFormat Format = "json"
+	// SARIFFormat writes the report as a SARIF 2.1.0 log
+	SARIFFormat Format = "sarif"
+	// JUnitFormat writes the report as a JUnit XML document, each rule being a test case
+	JUnitFormat Format = "junit"
+)
+
+// Formats lists the supported report formats
+var Formats = []Format{JSONFormat, SARIFFormat, JUnitFormat}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown report format `%s`, expecting one of %v", name, Formats)
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case SARIFFormat:
+		return ".sarif"
+	case JUnitFormat:
+		return ".xml"
+	default:
+		return ".json"
+	}
+}
+
+// Write writes the report in the given format
+func Write(w io.Writer, format Format, report *Report) error {
+	switch format {
+	case JSONFormat:
+		return writeJSON(w, report)
+	case SARIFFormat:
+		return writeSARIF(w, report)
+	case JUnitFormat:
+		return writeJUnit(w, report)
+	default:
+		return fmt.Errorf("unknown report format `%s`", format)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// findingError returns the error message of a finding in error
+func findingError(finding *Finding) string {
+	var msg interface{}
+	switch data := finding.Data.(type) {
+	case event.Data:
+		msg = data["error"]
+	case map[string]interface{}:
+		msg = data["error"]
+	}
+
+	if msg, ok := msg.(string); ok {
+		return msg
+	}
+	return ""
+}
+
+// findingMessage returns a one line description of a finding which did not pass
+func findingMessage(ruleID string, finding *Finding) string {
+	if finding.Status == StatusError {
+		return fmt.Sprintf("%s: unable to evaluate %s %s: %s", ruleID, finding.ResourceType, finding.ResourceID, findingError(finding))
+	}
+	return fmt.Sprintf("%s: %s %s failed", ruleID, finding.ResourceType, finding.ResourceID)
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+func testReport() *Report {
+	start := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite := &SuiteReport{
+		Name:      "CIS Docker Generic",
+		Framework: "cis-docker",
+		Version:   "1.2.0",
+		Source:    "/etc/datadog-agent/compliance.d/cis-docker.yaml",
+		Rules: []*RuleReport{
+			{
+				ID:          "cis-docker-1",
+				Description: "daemon.json file permissions",
+				Status:      StatusPassed,
+				Duration:    10 * time.Millisecond,
+				Findings: []*Finding{
+					{Status: StatusPassed, ResourceType: "docker_daemon", ResourceID: "the-host_daemon", Data: event.Data{"file.permissions": 0644}},
+				},
+			},
+			{
+				ID:       "cis-docker-2",
+				Status:   StatusFailed,
+				Duration: 20 * time.Millisecond,
+				Findings: []*Finding{
+					{Status: StatusPassed, ResourceType: "docker_container", ResourceID: "the-host_abc"},
+					{Status: StatusFailed, ResourceType: "docker_container", ResourceID: "the-host_def"},
+				},
+			},
+			{
+				ID:     "cis-docker-3",
+				Status: StatusError,
+				Findings: []*Finding{
+					{Status: StatusError, ResourceType: "docker_daemon", ResourceID: "the-host_daemon", Data: event.Data{"error": "command not found"}},
+				},
+			},
+			{
+				ID:     "cis-docker-4",
+				Status: StatusSkipped,
+				Reason: "rule does not apply",
+			},
+		},
+		Summary: Summary{Total: 4, Passed: 1, Failed: 1, Errors: 1, Skipped: 1, Score: 33.33},
+	}
+
+	return &Report{
+		Hostname:     "the-host",
+		AgentVersion: "7.40.0",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Suites:       []*SuiteReport{suite},
+		Summary:      suite.Summary,
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("SARIF")
+	assert.NoError(t, err)
+	assert.Equal(t, SARIFFormat, format)
+	assert.Equal(t, ".sarif", format.Extension())
+
+	_, err = ParseFormat("html")
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, JSONFormat, testReport()))
+
+	var report struct {
+		Hostname string `json:"hostname"`
+		Suites   []struct {
+			Rules []struct {
+				ID       string `json:"id"`
+				Status   string `json:"status"`
+				Reason   string `json:"reason"`
+				Findings []struct {
+					ResourceID string                 `json:"resource_id"`
+					Data       map[string]interface{} `json:"data"`
+				} `json:"findings"`
+			} `json:"rules"`
+		} `json:"suites"`
+		Summary Summary `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert := assert.New(t)
+	assert.Equal("the-host", report.Hostname)
+	assert.Equal(33.33, report.Summary.Score)
+	require.Len(t, report.Suites[0].Rules, 4)
+	assert.Equal("failed", report.Suites[0].Rules[1].Status)
+	assert.Equal("the-host_def", report.Suites[0].Rules[1].Findings[1].ResourceID)
+	assert.Equal("command not found", report.Suites[0].Rules[2].Findings[0].Data["error"])
+	assert.Equal("rule does not apply", report.Suites[0].Rules[3].Reason)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, SARIFFormat, testReport()))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert := assert.New(t)
+	assert.Equal("2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal("7.40.0", run.Tool.Driver.Version)
+	require.Len(t, run.Tool.Driver.Rules, 4)
+	assert.Equal("daemon.json file permissions", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Nil(run.Tool.Driver.Rules[1].ShortDescription)
+	assert.False(run.Invocations[0].ExecutionSuccessful)
+	assert.Equal("2022-06-01T12:00:00Z", run.Invocations[0].StartTimeUTC)
+
+	type result struct {
+		ruleID, kind, level, location string
+	}
+	var results []result
+	for _, r := range run.Results {
+		var location string
+		if len(r.Locations) > 0 {
+			location = r.Locations[0].LogicalLocations[0].Name
+		}
+		results = append(results, result{r.RuleID, r.Kind, r.Level, location})
+	}
+	assert.Equal([]result{
+		{"cis-docker-1", "pass", "none", "the-host_daemon"},
+		{"cis-docker-2", "pass", "none", "the-host_abc"},
+		{"cis-docker-2", "fail", "error", "the-host_def"},
+		{"cis-docker-3", "fail", "warning", "the-host_daemon"},
+		{"cis-docker-4", "notApplicable", "none", ""},
+	}, results)
+	assert.Equal(2, run.Results[3].RuleIndex)
+	assert.Contains(run.Results[3].Message.Text, "command not found")
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, JUnitFormat, testReport()))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+
+	assert := assert.New(t)
+	assert.Equal(4, suites.Tests)
+	assert.Equal(1, suites.Failures)
+	assert.Equal(1, suites.Errors)
+	assert.Equal(1, suites.Skipped)
+	assert.Equal("1.000", suites.Time)
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	assert.Equal("CIS Docker Generic 1.2.0", suite.Name)
+	assert.Equal("0.030", suite.Time)
+	assert.Contains(suite.Properties, junitProperty{Name: "score", Value: "33.33"})
+	require.Len(t, suite.Cases, 4)
+
+	assert.Equal("cis-docker-1: daemon.json file permissions", suite.Cases[0].Name)
+	assert.Equal("cis-docker", suite.Cases[0].ClassName)
+	assert.Nil(suite.Cases[0].Failure)
+
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal("1 resource(s) failed", suite.Cases[1].Failure.Message)
+	assert.Equal("cis-docker-2: docker_container the-host_def failed", suite.Cases[1].Failure.Details)
+
+	require.NotNil(t, suite.Cases[2].Error)
+	assert.Contains(suite.Cases[2].Error.Details, "command not found")
+
+	require.NotNil(t, suite.Cases[3].Skipped)
+	assert.Equal("rule does not apply", suite.Cases[3].Skipped.Message)
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Hostname   string          `xml:"hostname,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Details string `xml:",chardata"`
+}
+
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitFindingsDetails lists the resources of a rule which did not pass, one per line
+func junitFindingsDetails(rule *RuleReport) string {
+	var lines []string
+	for _, finding := range rule.FailedFindings() {
+		lines = append(lines, findingMessage(rule.ID, finding))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func newJUnitTestCase(suite *SuiteReport, rule *RuleReport) junitTestCase {
+	testCase := junitTestCase{
+		Name:      rule.ID,
+		ClassName: suite.Framework,
+		Time:      junitDuration(rule.Duration),
+	}
+	if rule.Description != "" {
+		testCase.Name = rule.ID + ": " + rule.Description
+	}
+
+	switch rule.Status {
+	case StatusFailed:
+		testCase.Failure = &junitMessage{
+			Message: fmt.Sprintf("%d resource(s) failed", len(rule.FailedFindings())),
+			Type:    string(StatusFailed),
+			Details: junitFindingsDetails(rule),
+		}
+	case StatusError:
+		testCase.Error = &junitMessage{
+			Message: "unable to evaluate the rule",
+			Type:    string(StatusError),
+			Details: junitFindingsDetails(rule),
+		}
+	case StatusSkipped:
+		testCase.Skipped = &junitMessage{
+			Message: rule.Reason,
+		}
+	}
+	return testCase
+}
+
+func newJUnitTestSuites(report *Report) *junitTestSuites {
+	suites := &junitTestSuites{
+		Name:     toolName,
+		Tests:    report.Summary.Total,
+		Failures: report.Summary.Failed,
+		Errors:   report.Summary.Errors,
+		Skipped:  report.Summary.Skipped,
+		Time:     junitDuration(report.EndTime.Sub(report.StartTime)),
+	}
+
+	for _, suite := range report.Suites {
+		var duration time.Duration
+		testSuite := junitTestSuite{
+			Name:      fmt.Sprintf("%s %s", suite.Name, suite.Version),
+			Tests:     suite.Summary.Total,
+			Failures:  suite.Summary.Failed,
+			Errors:    suite.Summary.Errors,
+			Skipped:   suite.Summary.Skipped,
+			Timestamp: report.StartTime.UTC().Format("2006-01-02T15:04:05"),
+			Hostname:  report.Hostname,
+			Properties: []junitProperty{
+				{Name: "framework", Value: suite.Framework},
+				{Name: "source", Value: suite.Source},
+				{Name: "score", Value: fmt.Sprintf("%.2f", suite.Summary.Score)},
+			},
+		}
+
+		for _, rule := range suite.Rules {
+			testSuite.Cases = append(testSuite.Cases, newJUnitTestCase(suite, rule))
+			duration += rule.Duration
+		}
+		testSuite.Time = junitDuration(duration)
+
+		suites.Suites = append(suites.Suites, testSuite)
+	}
+
+	return suites
+}
+
+func writeJUnit(w io.Writer, report *Report) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(newJUnitTestSuites(report)); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package benchmark runs compliance suites outside of the scheduler and builds machine readable
+// reports of their results
+package benchmark
+
+import (
+	"math"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// Status is the status of a rule or of a finding in a report
+type Status string
+
+const (
+	// StatusPassed is set when all the resources of a rule pass its condition
+	StatusPassed Status = event.Passed
+	// StatusFailed is set when a resource fails the condition of a rule
+	StatusFailed Status = event.Failed
+	// StatusError is set when a rule cannot be evaluated
+	StatusError Status = event.Error
+	// StatusSkipped is set when a rule does not apply to the host or reports no resource
+	StatusSkipped Status = "skipped"
+)
+
+// Report is the report of a benchmark run
+type Report struct {
+	Hostname     string         `json:"hostname"`
+	AgentVersion string         `json:"agent_version"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	Suites       []*SuiteReport `json:"suites"`
+	Summary      Summary        `json:"summary"`
+}
+
+// SuiteReport holds the results of the rules of a suite
+type SuiteReport struct {
+	Name      string        `json:"name"`
+	Framework string        `json:"framework"`
+	Version   string        `json:"version"`
+	Source    string        `json:"source"`
+	Rules     []*RuleReport `json:"rules"`
+	Summary   Summary       `json:"summary"`
+}
+
+// RuleReport holds the result of a rule and the resources it was evaluated on
+type RuleReport struct {
+	ID          string        `json:"id"`
+	Description string        `json:"description,omitempty"`
+	Status      Status        `json:"status"`
+	Reason      string        `json:"reason,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Findings    []*Finding    `json:"findings,omitempty"`
+}
+
+// Finding is the result of a rule on a resource
+type Finding struct {
+	Status       Status      `json:"status"`
+	ResourceType string      `json:"resource_type"`
+	ResourceID   string      `json:"resource_id"`
+	Data         interface{} `json:"data,omitempty"`
+}
+
+// Summary counts the rules by status. The score is the percentage of evaluated rules that
+// passed, the skipped rules being left out.
+type Summary struct {
+	Total   int     `json:"total"`
+	Passed  int     `json:"passed"`
+	Failed  int     `json:"failed"`
+	Errors  int     `json:"errors"`
+	Skipped int     `json:"skipped"`
+	Score   float64 `json:"score"`
+}
+
+func (s *Summary) add(status Status) {
+	s.Total++
+	switch status {
+	case StatusPassed:
+		s.Passed++
+	case StatusFailed:
+		s.Failed++
+	case StatusError:
+		s.Errors++
+	case StatusSkipped:
+		s.Skipped++
+	}
+}
+
+func (s *Summary) merge(other Summary) {
+	s.Total += other.Total
+	s.Passed += other.Passed
+	s.Failed += other.Failed
+	s.Errors += other.Errors
+	s.Skipped += other.Skipped
+}
+
+func (s *Summary) computeScore() {
+	evaluated := s.Passed + s.Failed + s.Errors
+	if evaluated == 0 {
+		s.Score = 100
+		return
+	}
+	s.Score = math.Round(float64(s.Passed)*10000/float64(evaluated)) / 100
+}
+
+// FailedFindings returns the findings of the rule that did not pass
+func (r *RuleReport) FailedFindings() []*Finding {
+	var findings []*Finding
+	for _, finding := range r.Findings {
+		if finding.Status != StatusPassed {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// ruleStatus returns the status of a rule from the statuses of its findings, an error taking
+// precedence over a failure
+func ruleStatus(findings []*Finding) Status {
+	if len(findings) == 0 {
+		return StatusSkipped
+	}
+
+	status := StatusPassed
+	for _, finding := range findings {
+		switch finding.Status {
+		case StatusError:
+			return StatusError
+		case StatusFailed:
+			status = StatusFailed
+		}
+	}
+	return status
+}
@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "datadog-security-agent"
+	toolURI      = "https://docs.datadoghq.com/security_platform/cspm/"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool              `json:"tool"`
+	Invocations []sarifInvocation      `json:"invocations"`
+	Results     []sarifResult          `json:"results"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	ShortDescription *sarifMessage          `json:"shortDescription,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc"`
+	EndTimeUTC          string `json:"endTimeUtc"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// sarifKindAndLevel returns the kind and the level of the result of a finding
+func sarifKindAndLevel(status Status) (string, string) {
+	switch status {
+	case StatusFailed:
+		return "fail", "error"
+	case StatusError:
+		return "fail", "warning"
+	case StatusSkipped:
+		return "notApplicable", "none"
+	default:
+		return "pass", "none"
+	}
+}
+
+func newSARIFLog(report *Report) *sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				Version:        report.AgentVersion,
+				InformationURI: toolURI,
+				Rules:          []sarifRule{},
+			},
+		},
+		Invocations: []sarifInvocation{
+			{
+				ExecutionSuccessful: report.Summary.Errors == 0,
+				StartTimeUTC:        report.StartTime.UTC().Format(time.RFC3339),
+				EndTimeUTC:          report.EndTime.UTC().Format(time.RFC3339),
+			},
+		},
+		Results: []sarifResult{},
+		Properties: map[string]interface{}{
+			"hostname": report.Hostname,
+			"score":    report.Summary.Score,
+		},
+	}
+
+	for _, suite := range report.Suites {
+		for _, rule := range suite.Rules {
+			ruleIndex := len(run.Tool.Driver.Rules)
+			sr := sarifRule{
+				ID: rule.ID,
+				Properties: map[string]interface{}{
+					"framework": suite.Framework,
+					"suite":     suite.Name,
+					"version":   suite.Version,
+				},
+			}
+			if rule.Description != "" {
+				sr.ShortDescription = &sarifMessage{Text: rule.Description}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+
+			if rule.Status == StatusSkipped {
+				kind, level := sarifKindAndLevel(StatusSkipped)
+				run.Results = append(run.Results, sarifResult{
+					RuleID:    rule.ID,
+					RuleIndex: ruleIndex,
+					Kind:      kind,
+					Level:     level,
+					Message:   sarifMessage{Text: fmt.Sprintf("%s: skipped: %s", rule.ID, rule.Reason)},
+				})
+				continue
+			}
+
+			for _, finding := range rule.Findings {
+				kind, level := sarifKindAndLevel(finding.Status)
+
+				text := fmt.Sprintf("%s: %s %s passed", rule.ID, finding.ResourceType, finding.ResourceID)
+				if finding.Status != StatusPassed {
+					text = findingMessage(rule.ID, finding)
+				}
+
+				result := sarifResult{
+					RuleID:    rule.ID,
+					RuleIndex: ruleIndex,
+					Kind:      kind,
+					Level:     level,
+					Message:   sarifMessage{Text: text},
+					Locations: []sarifLocation{
+						{
+							LogicalLocations: []sarifLogicalLocation{
+								{Name: finding.ResourceID, Kind: finding.ResourceType},
+							},
+						},
+					},
+				}
+				if finding.Data != nil {
+					result.Properties = map[string]interface{}{"data": finding.Data}
+				}
+				run.Results = append(run.Results, result)
+			}
+		}
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+}
+
+func writeSARIF(w io.Writer, report *Report) error {
+	return writeJSON(w, newSARIFLog(report))
+}
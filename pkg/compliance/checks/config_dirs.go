@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+)
+
+// listConfigFiles returns the `.conf` files of configuration directories, like `sysctl.d` or
+// `modprobe.d`, in the order they are applied: sorted by file name, a file shadowing the files
+// with the same name in the directories listed after its own. The returned paths are relative to
+// the host root.
+func listConfigFiles(e env.Env, dirs []string) []string {
+	byName := make(map[string]string)
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(dir), "*.conf"))
+		if err != nil {
+			continue
+		}
+
+		for _, match := range matches {
+			name := filepath.Base(match)
+			if _, shadowed := byName[name]; !shadowed {
+				byName[name] = filepath.Join(dir, name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, byName[name])
+	}
+	return files
+}
+
+// readConfigLines calls fn for each line of a configuration file, skipping the empty lines and
+// the comments starting with one of the given characters. The lines ending with a backslash are
+// continued on the next line.
+func readConfigLines(path string, comments string, fn func(line string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var continued string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if continued != "" {
+			line, continued = continued+" "+line, ""
+		} else if len(line) == 0 || strings.ContainsRune(comments, rune(line[0])) {
+			continue
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			continued = strings.TrimSpace(strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		fn(line)
+	}
+	if continued != "" {
+		fn(continued)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procModulesPath = "/proc/modules"
+
+// modprobeConfigDirs lists the modprobe.d directories by decreasing priority
+var modprobeConfigDirs = []string{
+	"/etc/modprobe.d",
+	"/run/modprobe.d",
+	"/usr/local/lib/modprobe.d",
+	"/usr/lib/modprobe.d",
+	"/lib/modprobe.d",
+}
+
+// disablingInstallCommands are the install commands used to prevent a module from being loaded
+var disablingInstallCommands = map[string]bool{
+	"/bin/true":      true,
+	"/bin/false":     true,
+	"/usr/bin/true":  true,
+	"/usr/bin/false": true,
+	"true":           true,
+	"false":          true,
+}
+
+var kernelModuleReportedFields = []string{
+	compliance.KernelModuleFieldName,
+	compliance.KernelModuleFieldLoaded,
+	compliance.KernelModuleFieldBlacklisted,
+	compliance.KernelModuleFieldDisabled,
+}
+
+// modprobeConfig holds the modprobe.d directives applying to a module
+type modprobeConfig struct {
+	blacklisted bool
+	install     string
+	options     []string
+}
+
+func resolveKernelModule(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.KernelModule == nil {
+		return nil, fmt.Errorf("%s: expecting kernel_module resource in kernel_module check", id)
+	}
+
+	name := normalizeModuleName(res.KernelModule.Name)
+
+	log.Debugf("%s: running kernel_module check: %s", id, name)
+
+	loaded, err := isModuleLoaded(e, name)
+	if err != nil {
+		return nil, log.Errorf("%s: unable to read loaded modules: %v", id, err)
+	}
+
+	config := readModprobeConfig(e, name)
+	disabled := disablingInstallCommands[config.install]
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.KernelModuleFieldName:        name,
+			compliance.KernelModuleFieldLoaded:      loaded,
+			compliance.KernelModuleFieldBlacklisted: config.blacklisted,
+			compliance.KernelModuleFieldInstall:     config.install,
+			compliance.KernelModuleFieldDisabled:    disabled,
+			compliance.KernelModuleFieldOptions:     strings.Join(config.options, " "),
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":        name,
+			"loaded":      loaded,
+			"blacklisted": config.blacklisted,
+			"install":     config.install,
+			"disabled":    disabled,
+			"options":     config.options,
+		},
+	)
+
+	return newResolvedInstance(instance, name, "kernel_module"), nil
+}
+
+// normalizeModuleName returns the name of a module as listed in /proc/modules, dashes and
+// underscores being interchangeable
+func normalizeModuleName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func isModuleLoaded(e env.Env, name string) (bool, error) {
+	data, err := os.ReadFile(e.NormalizeToHostRoot(procModulesPath))
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// readModprobeConfig returns the blacklist, install and options directives of a module. As for
+// modprobe, the first install directive wins and the options are accumulated.
+func readModprobeConfig(e env.Env, name string) modprobeConfig {
+	var config modprobeConfig
+
+	for _, file := range listConfigFiles(e, modprobeConfigDirs) {
+		err := readConfigLines(e.NormalizeToHostRoot(file), "#", func(line string) {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || normalizeModuleName(fields[1]) != name {
+				return
+			}
+
+			switch fields[0] {
+			case "blacklist":
+				config.blacklisted = true
+			case "install":
+				if config.install == "" {
+					config.install = strings.Join(fields[2:], " ")
+				}
+			case "options":
+				config.options = append(config.options, fields[2:]...)
+			}
+		})
+		if err != nil {
+			log.Debugf("unable to read modprobe configuration %s: %v", file, err)
+		}
+	}
+
+	return config
+}
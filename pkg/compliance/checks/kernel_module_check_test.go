@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+func TestKernelModuleCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "disabled module",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: "cramfs",
+					},
+				},
+				Condition: `!kernel_module.loaded && kernel_module.disabled && kernel_module.install == "/bin/true"`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kernel_module.name":        "cramfs",
+					"kernel_module.loaded":      false,
+					"kernel_module.blacklisted": true,
+					"kernel_module.disabled":    true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "cramfs",
+					Type: "kernel_module",
+				},
+			},
+		},
+		{
+			name: "disabled module still loaded",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: "usb-storage",
+					},
+				},
+				Condition: `!kernel_module.loaded && kernel_module.disabled`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"kernel_module.name":        "usb_storage",
+					"kernel_module.loaded":      true,
+					"kernel_module.blacklisted": false,
+					"kernel_module.disabled":    true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "usb_storage",
+					Type: "kernel_module",
+				},
+			},
+		},
+		{
+			name: "module options",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: "usb_storage",
+					},
+				},
+				Condition: `kernel_module.options == "quirks=0bc2:2320:u delay_use=1"`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kernel_module.name":        "usb_storage",
+					"kernel_module.loaded":      true,
+					"kernel_module.blacklisted": false,
+					"kernel_module.disabled":    true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "usb_storage",
+					Type: "kernel_module",
+				},
+			},
+		},
+		{
+			name: "unconfigured module",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: "squashfs",
+					},
+				},
+				Condition: `kernel_module.disabled || kernel_module.blacklisted`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"kernel_module.name":        "squashfs",
+					"kernel_module.loaded":      false,
+					"kernel_module.blacklisted": false,
+					"kernel_module.disabled":    false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "squashfs",
+					Type: "kernel_module",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newFakeHostRootEnv()
+
+			kernelModuleCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := kernelModuleCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+			assert.NoError(reports[0].Error)
+		})
+	}
+}
+
+func TestReadModprobeConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	env := newFakeHostRootEnv()
+
+	// cramfs has two install directives, in cis.conf and local.conf
+	assert.Equal(modprobeConfig{
+		blacklisted: true,
+		install:     "/bin/true",
+	}, readModprobeConfig(env, "cramfs"))
+
+	assert.Equal(modprobeConfig{
+		install: "/bin/true",
+		options: []string{"quirks=0bc2:2320:u", "delay_use=1"},
+	}, readModprobeConfig(env, "usb_storage"))
+}
+
+func TestKernelModuleRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	fixture := regoFixture{
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: "cramfs",
+					},
+				},
+				TagName: "cramfs",
+			},
+		},
+		module: `
+			package test
+
+			import data.datadog as dd
+
+			findings[f] {
+				not input.cramfs.loaded
+				input.cramfs.disabled
+				f := dd.passed_finding("kernel_module", input.cramfs.name, {"kernel_module.install": input.cramfs.install})
+			}
+		`,
+		findings: "data.test.findings",
+	}
+
+	env := newFakeHostRootEnv()
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+
+	regoCheck, err := fixture.newRegoCheck()
+	assert.NoError(err)
+
+	reports := regoCheck.check(env)
+	assert.Equal([]*compliance.Report{
+		{
+			Passed: true,
+			Data: event.Data{
+				"kernel_module.install": "/bin/true",
+			},
+			Resource: compliance.ReportResource{
+				ID:   "cramfs",
+				Type: "kernel_module",
+			},
+			Evaluator: "rego",
+		},
+	}, reports)
+}
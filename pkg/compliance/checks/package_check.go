@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/packages"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	packagesCacheKey string = "compliance-packages"
+)
+
+var (
+	packagesLister = packages.List
+)
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldVersion,
+	compliance.PackageFieldArch,
+	compliance.PackageFieldInstalled,
+}
+
+func getPackages(e env.Env, maxAge time.Duration) ([]*packages.Package, error) {
+	if value, found := cache.Cache.Get(packagesCacheKey); found {
+		return value.([]*packages.Package), nil
+	}
+
+	log.Debug("Updating package cache")
+	installed, err := packagesLister(e.NormalizeToHostRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Cache.Set(packagesCacheKey, installed, maxAge)
+	return installed, nil
+}
+
+func resolvePackage(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Package == nil {
+		return nil, fmt.Errorf("%s: expecting package resource in package check", id)
+	}
+
+	pkg := res.Package
+
+	log.Debugf("%s: running package check: %s", id, pkg.Name)
+
+	installed, err := getPackages(e, cacheValidity)
+	if err != nil {
+		return nil, log.Errorf("%s: unable to list packages: %v", id, err)
+	}
+
+	var instances []resolvedInstance
+	for _, p := range installed {
+		if p.Name != pkg.Name {
+			continue
+		}
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.PackageFieldName:      p.Name,
+				compliance.PackageFieldVersion:   p.Version,
+				compliance.PackageFieldArch:      p.Arch,
+				compliance.PackageFieldManager:   string(p.Manager),
+				compliance.PackageFieldInstalled: true,
+			},
+			eval.FunctionMap{
+				compliance.PackageFuncVersionCompare: packageVersionCompare(p),
+			},
+			eval.RegoInputMap{
+				"name":    p.Name,
+				"version": p.Version,
+				"arch":    p.Arch,
+				"manager": string(p.Manager),
+			},
+		)
+		instances = append(instances, newResolvedInstance(instance, p.Name, "package"))
+	}
+
+	if len(instances) == 0 {
+		if rego {
+			return nil, nil
+		}
+
+		// a missing package is reported as an instance, for the conditions to check that
+		// the package is not installed
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.PackageFieldName:      pkg.Name,
+				compliance.PackageFieldVersion:   "",
+				compliance.PackageFieldArch:      "",
+				compliance.PackageFieldManager:   "",
+				compliance.PackageFieldInstalled: false,
+			},
+			eval.FunctionMap{
+				compliance.PackageFuncVersionCompare: packageVersionCompare(nil),
+			},
+			nil,
+		)
+		return newResolvedInstance(instance, pkg.Name, "package"), nil
+	}
+
+	if len(instances) == 1 {
+		return instances[0].(*_resolvedInstance), nil
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+// packageVersionCompare returns -1, 0 or 1 if the installed version is older, the same or newer than
+// the version passed as argument. A missing package is older than any version.
+func packageVersionCompare(p *packages.Package) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+		}
+		version, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New(`expecting string value for version argument`)
+		}
+
+		if p == nil {
+			return -1, nil
+		}
+		return packages.CompareVersions(p.Manager, p.Version, version)
+	}
+}
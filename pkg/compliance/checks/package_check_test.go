@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/packages"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+var testInstalledPackages = []*packages.Package{
+	{Name: "openssh-server", Version: "1:8.4p1-5+deb11u1", Arch: "amd64", Manager: packages.Dpkg},
+	{Name: "telnetd", Version: "0.17-42", Arch: "amd64", Manager: packages.Dpkg},
+}
+
+func mockPackagesLister(installed []*packages.Package) {
+	cache.Cache.Delete(packagesCacheKey)
+	packagesLister = func(resolve func(string) string) ([]*packages.Package, error) {
+		return installed, nil
+	}
+}
+
+func TestPackageCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "installed at minimum version",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssh-server",
+					},
+				},
+				Condition: `package.installed && package.versionCompare("1:8.4p1-5") >= 0`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.version":   "1:8.4p1-5+deb11u1",
+					"package.arch":      "amd64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "installed below minimum version",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssh-server",
+					},
+				},
+				Condition: `package.versionCompare("1:8.4p1-5+deb11u2") >= 0`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.version":   "1:8.4p1-5+deb11u1",
+					"package.arch":      "amd64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "forbidden package installed",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "telnetd",
+					},
+				},
+				Condition: `!package.installed`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "telnetd",
+					"package.version":   "0.17-42",
+					"package.arch":      "amd64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnetd",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "forbidden package not installed",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "rsh-server",
+					},
+				},
+				Condition: `!package.installed`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "rsh-server",
+					"package.version":   "",
+					"package.arch":      "",
+					"package.installed": false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "rsh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "missing package older than any version",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "rsh-server",
+					},
+				},
+				Condition: `package.versionCompare("0") < 0`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "rsh-server",
+					"package.version":   "",
+					"package.arch":      "",
+					"package.installed": false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "rsh-server",
+					Type: "package",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			mockPackagesLister(testInstalledPackages)
+
+			env := &mocks.Env{}
+			env.On("MaxEventsPerRun").Return(30).Maybe()
+
+			packageCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := packageCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+			assert.NoError(reports[0].Error)
+		})
+	}
+}
+
+func TestPackageRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	mockPackagesLister(testInstalledPackages)
+
+	fixture := regoFixture{
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssh-server",
+					},
+				},
+				TagName: "sshd",
+			},
+		},
+		module: `
+			package test
+
+			import data.datadog as dd
+
+			findings[f] {
+				package_version_compare(input.sshd.manager, input.sshd.version, "1:8.4p1-5") >= 0
+				f := dd.passed_finding("package", input.sshd.name, {"package.version": input.sshd.version})
+			}
+		`,
+		findings: "data.test.findings",
+	}
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+
+	regoCheck, err := fixture.newRegoCheck()
+	assert.NoError(err)
+
+	reports := regoCheck.check(env)
+	assert.Equal([]*compliance.Report{
+		{
+			Passed: true,
+			Data: event.Data{
+				"package.version": "1:8.4p1-5+deb11u1",
+			},
+			Resource: compliance.ReportResource{
+				ID:   "openssh-server",
+				Type: "package",
+			},
+			Evaluator: "rego",
+		},
+	}, reports)
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"io"
+	"os"
+)
+
+// readApkInstalled reads the installed packages from the apk database
+func readApkInstalled(path string) ([]*Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseApkInstalled(f)
+}
+
+func parseApkInstalled(r io.Reader) ([]*Package, error) {
+	var packages []*Package
+	err := readStanzas(r, func(fields map[string]string) {
+		if fields["P"] == "" {
+			return
+		}
+
+		packages = append(packages, &Package{
+			Name:    fields["P"],
+			Version: fields["V"],
+			Arch:    fields["A"],
+			Manager: APK,
+		})
+	})
+	return packages, err
+}
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readStanzas calls fn for each `key: value` field of the paragraphs of a file, the paragraphs
+// being separated by blank lines. Continuation lines, starting with a space, are ignored.
+func readStanzas(r io.Reader, fn func(fields map[string]string)) error {
+	fields := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			if len(fields) > 0 {
+				fn(fields)
+				fields = make(map[string]string)
+			}
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		if i := strings.IndexByte(line, ':'); i > 0 {
+			fields[line[:i]] = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	if len(fields) > 0 {
+		fn(fields)
+	}
+
+	return scanner.Err()
+}
+
+// readDpkgStatus reads the installed packages from the dpkg status file
+func readDpkgStatus(path string) ([]*Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseDpkgStatus(f)
+}
+
+func parseDpkgStatus(r io.Reader) ([]*Package, error) {
+	var packages []*Package
+	err := readStanzas(r, func(fields map[string]string) {
+		// the status is made of the wanted state, the error flag and the package state
+		status := strings.Fields(fields["Status"])
+		if len(status) != 3 || status[2] != "installed" {
+			return
+		}
+
+		packages = append(packages, &Package{
+			Name:    fields["Package"],
+			Version: fields["Version"],
+			Arch:    fields["Architecture"],
+			Manager: Dpkg,
+		})
+	})
+	return packages, err
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package packages reads the databases of the system package managers
+package packages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Manager identifies a package manager
+type Manager string
+
+const (
+	// Dpkg is the Debian package manager
+	Dpkg = Manager("dpkg")
+	// RPM is the Red Hat package manager
+	RPM = Manager("rpm")
+	// APK is the Alpine package manager
+	APK = Manager("apk")
+)
+
+// ErrNoDatabase is returned when no package database can be found
+var ErrNoDatabase = errors.New("no package database found")
+
+// Package describes an installed package
+type Package struct {
+	Name    string
+	Version string
+	Arch    string
+	Manager Manager
+}
+
+type database struct {
+	manager Manager
+	path    string
+	read    func(path string) ([]*Package, error)
+}
+
+// databases lists the known package databases. Only the first RPM database found is read, the
+// most recent locations being listed first.
+var databases = []database{
+	{manager: Dpkg, path: "/var/lib/dpkg/status", read: readDpkgStatus},
+	{manager: APK, path: "/lib/apk/db/installed", read: readApkInstalled},
+	{manager: RPM, path: "/usr/lib/sysimage/rpm/rpmdb.sqlite", read: readRPMSQLite},
+	{manager: RPM, path: "/var/lib/rpm/rpmdb.sqlite", read: readRPMSQLite},
+	{manager: RPM, path: "/usr/lib/sysimage/rpm/Packages.db", read: readRPMNDB},
+	{manager: RPM, path: "/var/lib/rpm/Packages.db", read: readRPMNDB},
+	{manager: RPM, path: "/var/lib/rpm/Packages", read: readRPMBerkeleyDB},
+}
+
+// List returns the installed packages, read from the package databases. The resolve function
+// maps the path of a database to the path to read, to support a host root mount.
+func List(resolve func(path string) string) ([]*Package, error) {
+	var packages []*Package
+	found := make(map[Manager]bool)
+
+	for _, db := range databases {
+		if found[db.manager] {
+			continue
+		}
+
+		path := resolve(db.path)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		pkgs, err := db.read(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s database %s: %w", db.manager, path, err)
+		}
+
+		found[db.manager] = true
+		packages = append(packages, pkgs...)
+	}
+
+	if len(found) == 0 {
+		return nil, ErrNoDatabase
+	}
+
+	return packages, nil
+}
@@ -0,0 +1,316 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var expectedRPMPackages = []*Package{
+	{Name: "openssh-server", Version: "8.0p1-13.el8", Arch: "x86_64", Manager: RPM},
+	{Name: "openssl-libs", Version: "1:1.1.1k-7.el8_6", Arch: "x86_64", Manager: RPM},
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// rpmHeader builds an header blob with the given string tags
+func rpmHeader(tags map[uint32]string, epoch int) []byte {
+	var index, data []byte
+	addEntry := func(tag, typ uint32, value []byte) {
+		var entry [16]byte
+		binary.BigEndian.PutUint32(entry[0:], tag)
+		binary.BigEndian.PutUint32(entry[4:], typ)
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(entry[12:], 1)
+		index = append(index, entry[:]...)
+		data = append(data, value...)
+	}
+
+	var sortedTags []uint32
+	for tag := range tags {
+		sortedTags = append(sortedTags, tag)
+	}
+	sort.Slice(sortedTags, func(i, j int) bool { return sortedTags[i] < sortedTags[j] })
+
+	for _, tag := range sortedTags {
+		addEntry(tag, rpmTypeString, append([]byte(tags[tag]), 0))
+	}
+	if epoch >= 0 {
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+		addEntry(rpmTagEpoch, rpmTypeInt32, appendUint32(nil, uint32(epoch)))
+	}
+
+	blob := appendUint32(nil, uint32(len(index)/16))
+	blob = appendUint32(blob, uint32(len(data)))
+	return append(append(blob, index...), data...)
+}
+
+func testRPMHeaders() [][]byte {
+	return [][]byte{
+		rpmHeader(map[uint32]string{rpmTagName: "gpg-pubkey", rpmTagVersion: "fd431d51", rpmTagRelease: "4ae0493b"}, -1),
+		rpmHeader(map[uint32]string{rpmTagName: "openssh-server", rpmTagVersion: "8.0p1", rpmTagRelease: "13.el8", rpmTagArch: "x86_64"}, -1),
+		rpmHeader(map[uint32]string{rpmTagName: "openssl-libs", rpmTagVersion: "1.1.1k", rpmTagRelease: "7.el8_6", rpmTagArch: "x86_64"}, 1),
+	}
+}
+
+func TestDpkgStatus(t *testing.T) {
+	pkgs, err := readDpkgStatus("testdata/dpkg-status")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Package{
+		{Name: "openssh-server", Version: "1:8.4p1-5+deb11u1", Arch: "amd64", Manager: Dpkg},
+		{Name: "libssl1.1", Version: "1.1.1n-0+deb11u3", Arch: "amd64", Manager: Dpkg},
+		{Name: "libssl1.1", Version: "1.1.1n-0+deb11u3", Arch: "i386", Manager: Dpkg},
+	}, pkgs)
+}
+
+func TestApkInstalled(t *testing.T) {
+	pkgs, err := readApkInstalled("testdata/apk-installed")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Package{
+		{Name: "musl", Version: "1.2.3-r0", Arch: "x86_64", Manager: APK},
+		{Name: "openssl", Version: "1.1.1q-r0", Arch: "x86_64", Manager: APK},
+	}, pkgs)
+}
+
+func TestRPMSQLite(t *testing.T) {
+	assert := assert.New(t)
+
+	// the database is made of several leaf pages and of headers stored in overflow pages
+	pkgs, err := readRPMSQLite("testdata/rpmdb.sqlite")
+	assert.NoError(err)
+	if assert.Len(pkgs, 33) {
+		assert.Equal(expectedRPMPackages, pkgs[:2])
+		assert.Equal(&Package{Name: "kernel-core", Version: "4.18.0-372.9.1.el8", Arch: "x86_64", Manager: RPM}, pkgs[2])
+		assert.Equal(&Package{Name: "filler-29", Version: "1.29-1.el8", Arch: "noarch", Manager: RPM}, pkgs[32])
+	}
+
+	_, err = readRPMSQLite("testdata/dpkg-status")
+	assert.Error(err)
+}
+
+func TestParseSQLiteRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  []byte
+		expected []interface{}
+		err      error
+	}{
+		{
+			name:     "valid record",
+			payload:  []byte{0x04, 0x01, 0x09, 0x17, 0x2a, 'r', 'p', 'm', 'd', 'b'},
+			expected: []interface{}{int64(42), int64(1), "rpmdb"},
+		},
+		{
+			name:    "empty payload",
+			payload: []byte{},
+			err:     errInvalidSQLite,
+		},
+		{
+			name:    "header size larger than the payload",
+			payload: []byte{0x10, 0x01},
+			err:     errInvalidSQLite,
+		},
+		{
+			name:    "header size smaller than its varint",
+			payload: []byte{0x00, 0x01, 0x2a},
+			err:     errInvalidSQLite,
+		},
+		{
+			name:    "header size in the middle of its varint",
+			payload: []byte{0x80, 0x01, 0x01, 0x2a},
+			err:     errInvalidSQLite,
+		},
+		{
+			name:    "value larger than the body",
+			payload: []byte{0x02, 0x1b, 'r', 'p', 'm'},
+			err:     errInvalidSQLite,
+		},
+		{
+			name:    "huge serial type",
+			payload: []byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			err:     errInvalidSQLite,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record, err := parseSQLiteRecord(test.payload)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.expected, record)
+		})
+	}
+}
+
+// berkeleyDBFile builds a little endian hash database, each value being stored in a chain
+// of overflow pages
+func berkeleyDBFile(values [][]byte) []byte {
+	const pageSize = 512
+	order := binary.LittleEndian
+
+	var pages [][]byte
+	newPage := func(pageType uint8) []byte {
+		page := make([]byte, pageSize)
+		order.PutUint32(page[8:], uint32(len(pages)))
+		page[25] = pageType
+		pages = append(pages, page)
+		return page
+	}
+
+	meta := newPage(bdbHashMetaPage)
+	order.PutUint32(meta[12:], bdbHashMagic)
+	order.PutUint32(meta[20:], pageSize)
+
+	hashPage := newPage(bdbHashPage)
+	order.PutUint16(hashPage[20:], uint16(2*len(values)))
+
+	entryOffset := pageSize
+	for i, value := range values {
+		// key entry, holding the record number
+		entryOffset -= 5
+		hashPage[entryOffset] = 1
+		order.PutUint32(hashPage[entryOffset+1:], uint32(i+1))
+		order.PutUint16(hashPage[bdbPageHeaderSize+4*i:], uint16(entryOffset))
+
+		// value entry, stored off page
+		entryOffset -= 12
+		hashPage[entryOffset] = bdbOffPageEntry
+		order.PutUint32(hashPage[entryOffset+4:], uint32(len(pages)))
+		order.PutUint32(hashPage[entryOffset+8:], uint32(len(value)))
+		order.PutUint16(hashPage[bdbPageHeaderSize+4*i+2:], uint16(entryOffset))
+
+		for len(value) > 0 {
+			page := newPage(bdbOverflowPage)
+			n := copy(page[bdbPageHeaderSize:], value)
+			order.PutUint16(page[22:], uint16(n))
+			value = value[n:]
+			if len(value) > 0 {
+				order.PutUint32(page[16:], uint32(len(pages)))
+			}
+		}
+	}
+
+	order.PutUint32(meta[32:], uint32(len(pages)-1))
+
+	var data []byte
+	for _, page := range pages {
+		data = append(data, page...)
+	}
+	return data
+}
+
+func TestRPMBerkeleyDB(t *testing.T) {
+	assert := assert.New(t)
+
+	headers := testRPMHeaders()
+	// the third header spans several overflow pages
+	headers[2] = rpmHeader(map[uint32]string{rpmTagName: "openssl-libs", rpmTagVersion: "1.1.1k", rpmTagRelease: "7.el8_6", rpmTagArch: "x86_64", 1004: strings.Repeat("x", 1500)}, 1)
+
+	path := filepath.Join(t.TempDir(), "Packages")
+	assert.NoError(os.WriteFile(path, berkeleyDBFile(headers), 0644))
+
+	pkgs, err := readRPMBerkeleyDB(path)
+	assert.NoError(err)
+	assert.Equal(expectedRPMPackages, pkgs)
+
+	// a database written by rpm on Rocky Linux 8, from the go-rpmdb test suite, whose hash pages also hold small values
+	pkgs, err = readRPMBerkeleyDB("testdata/Packages")
+	assert.NoError(err)
+	assert.Equal([]*Package{{Name: "libuuid", Version: "2.32.1-42.el8_8", Arch: "x86_64", Manager: RPM}}, pkgs)
+}
+
+// ndbFile builds a database with a single page of slots
+func ndbFile(blobs [][]byte) []byte {
+	order := binary.LittleEndian
+
+	data := make([]byte, ndbPageSize)
+	order.PutUint32(data[0:], ndbHeaderMagic)
+	order.PutUint32(data[12:], 1)
+
+	for i := ndbHeaderSlots; i < ndbPageSize/ndbSlotSize; i++ {
+		order.PutUint32(data[i*ndbSlotSize:], ndbSlotMagic)
+	}
+
+	for i, blob := range blobs {
+		slot := data[(ndbHeaderSlots+i)*ndbSlotSize:]
+		order.PutUint32(slot[4:], uint32(i+1))
+		order.PutUint32(slot[8:], uint32(len(data)/ndbBlockSize))
+
+		var header [ndbBlobHeaderSize]byte
+		order.PutUint32(header[0:], ndbBlobMagic)
+		order.PutUint32(header[4:], uint32(i+1))
+		order.PutUint32(header[12:], uint32(len(blob)))
+		data = append(data, header[:]...)
+		data = append(data, blob...)
+		for len(data)%ndbBlockSize != 0 {
+			data = append(data, 0)
+		}
+	}
+
+	return data
+}
+
+func TestRPMNDB(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "Packages.db")
+	assert.NoError(os.WriteFile(path, ndbFile(testRPMHeaders()), 0644))
+
+	pkgs, err := readRPMNDB(path)
+	assert.NoError(err)
+	assert.Equal(expectedRPMPackages, pkgs)
+
+	// a database written by rpm on SUSE BCI 15, from the go-rpmdb test suite, trimmed to three packages by freeing the
+	// slots of the other ones
+	pkgs, err = readRPMNDB("testdata/Packages.db")
+	assert.NoError(err)
+	assert.Equal([]*Package{
+		{Name: "system-user-root", Version: "20190513-3.3.1", Arch: "noarch", Manager: RPM},
+		{Name: "libpcre1", Version: "8.45-20.10.1", Arch: "x86_64", Manager: RPM},
+		{Name: "libgmp10", Version: "6.1.2-4.6.1", Arch: "x86_64", Manager: RPM},
+	}, pkgs)
+}
+
+func TestList(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	install := func(path, fixture string) {
+		data, err := os.ReadFile(fixture)
+		assert.NoError(err)
+		assert.NoError(os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755))
+		assert.NoError(os.WriteFile(filepath.Join(root, path), data, 0644))
+	}
+	resolve := func(path string) string {
+		return filepath.Join(root, path)
+	}
+
+	_, err := List(resolve)
+	assert.ErrorIs(err, ErrNoDatabase)
+
+	install("/var/lib/dpkg/status", "testdata/dpkg-status")
+	install("/var/lib/rpm/rpmdb.sqlite", "testdata/rpmdb.sqlite")
+	// only the most recent rpm database is read
+	install("/var/lib/rpm/Packages", "testdata/dpkg-status")
+
+	pkgs, err := List(resolve)
+	assert.NoError(err)
+	assert.Len(pkgs, 3+33)
+	assert.Equal(Dpkg, pkgs[0].Manager)
+	assert.Equal(RPM, pkgs[3].Manager)
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// RPM header tags and types, see rpmtag.h
+const (
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+	rpmTagArch    = 1022
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+
+	rpmIndexEntrySize = 16
+	// rpmMaxHeaderSize is the maximum size of an header, as enforced by rpm
+	rpmMaxHeaderSize = 256 * 1024 * 1024
+)
+
+var errInvalidRPMHeader = errors.New("invalid rpm header")
+
+// parseRPMHeader returns the package described by an header blob, as stored in the rpm database.
+// A blob starts with the count of index entries and the size of the data store, followed by the
+// index entries and the data store.
+func parseRPMHeader(blob []byte) (*Package, error) {
+	if len(blob) < 8 {
+		return nil, errInvalidRPMHeader
+	}
+
+	indexCount := binary.BigEndian.Uint32(blob[0:4])
+	dataSize := binary.BigEndian.Uint32(blob[4:8])
+	if uint64(indexCount)*rpmIndexEntrySize+uint64(dataSize) > rpmMaxHeaderSize {
+		return nil, errInvalidRPMHeader
+	}
+
+	dataStart := 8 + int(indexCount)*rpmIndexEntrySize
+	dataEnd := dataStart + int(dataSize)
+	if len(blob) < dataEnd {
+		return nil, errInvalidRPMHeader
+	}
+	data := blob[dataStart:dataEnd]
+
+	var name, version, release, arch string
+	epoch := -1
+
+	for i := 0; i < int(indexCount); i++ {
+		entry := blob[8+i*rpmIndexEntrySize:]
+		tag := binary.BigEndian.Uint32(entry[0:4])
+		typ := binary.BigEndian.Uint32(entry[4:8])
+		offset := binary.BigEndian.Uint32(entry[8:12])
+		if offset >= dataSize {
+			continue
+		}
+
+		switch tag {
+		case rpmTagName, rpmTagVersion, rpmTagRelease, rpmTagArch:
+			if typ != rpmTypeString && typ != rpmTypeStringArray && typ != rpmTypeI18NString {
+				continue
+			}
+			value := data[offset:]
+			if end := bytes.IndexByte(value, 0); end >= 0 {
+				value = value[:end]
+			}
+
+			switch tag {
+			case rpmTagName:
+				name = string(value)
+			case rpmTagVersion:
+				version = string(value)
+			case rpmTagRelease:
+				release = string(value)
+			case rpmTagArch:
+				arch = string(value)
+			}
+		case rpmTagEpoch:
+			if typ != rpmTypeInt32 || offset+4 > dataSize {
+				continue
+			}
+			epoch = int(binary.BigEndian.Uint32(data[offset:]))
+		}
+	}
+
+	if name == "" {
+		return nil, errInvalidRPMHeader
+	}
+
+	evr := version
+	if release != "" {
+		evr += "-" + release
+	}
+	if epoch >= 0 {
+		evr = strconv.Itoa(epoch) + ":" + evr
+	}
+
+	return &Package{
+		Name:    name,
+		Version: evr,
+		Arch:    arch,
+		Manager: RPM,
+	}, nil
+}
+
+// parseRPMHeaders returns the packages described by the header blobs, skipping the entries
+// which are not headers, like the public keys imported in the database
+func parseRPMHeaders(blobs [][]byte) ([]*Package, error) {
+	var packages []*Package
+	for i, blob := range blobs {
+		pkg, err := parseRPMHeader(blob)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		// imported public keys are stored as packages
+		if pkg.Name == "gpg-pubkey" {
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The rpm Berkeley DB backend stores the headers in the `Packages` hash database, each header
+// being too large to fit in a hash page and stored in a chain of overflow pages. All the pages
+// are scanned, without going through the hash buckets.
+
+const (
+	bdbHashMagic = 0x061561
+
+	bdbPageHeaderSize = 26
+
+	bdbHashUnsortedPage = 2
+	bdbOverflowPage     = 7
+	bdbHashMetaPage     = 8
+	bdbHashPage         = 13
+
+	// bdbOffPageEntry is the type of the hash entries stored in overflow pages
+	bdbOffPageEntry = 3
+)
+
+var errInvalidBerkeleyDB = errors.New("invalid berkeley db database")
+
+type bdbPageHeader struct {
+	nextPage    uint32
+	numEntries  uint16
+	freeOffset  uint16
+	pageType    uint8
+	entriesData []byte
+}
+
+type berkeleyDB struct {
+	data     []byte
+	order    binary.ByteOrder
+	pageSize uint32
+	lastPage uint32
+}
+
+func readRPMBerkeleyDB(path string) ([]*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newBerkeleyDB(data)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs, err := db.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRPMHeaders(blobs)
+}
+
+func newBerkeleyDB(data []byte) (*berkeleyDB, error) {
+	if len(data) < 512 {
+		return nil, errInvalidBerkeleyDB
+	}
+
+	// the database is stored in the byte order of the host which created it
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(data[12:16]) == bdbHashMagic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(data[12:16]) == bdbHashMagic:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: not a hash database", errInvalidBerkeleyDB)
+	}
+
+	if data[24] != 0 {
+		return nil, fmt.Errorf("%w: encrypted database", errInvalidBerkeleyDB)
+	}
+	if data[25] != bdbHashMetaPage {
+		return nil, errInvalidBerkeleyDB
+	}
+
+	pageSize := order.Uint32(data[20:24])
+	if pageSize < 512 || pageSize > 65536 || pageSize&(pageSize-1) != 0 {
+		return nil, errInvalidBerkeleyDB
+	}
+
+	return &berkeleyDB{
+		data:     data,
+		order:    order,
+		pageSize: pageSize,
+		lastPage: order.Uint32(data[32:36]),
+	}, nil
+}
+
+func (db *berkeleyDB) page(pgno uint32) ([]byte, *bdbPageHeader, error) {
+	start := uint64(pgno) * uint64(db.pageSize)
+	if start+uint64(db.pageSize) > uint64(len(db.data)) {
+		return nil, nil, fmt.Errorf("%w: page %d out of bounds", errInvalidBerkeleyDB, pgno)
+	}
+
+	page := db.data[start : start+uint64(db.pageSize)]
+	return page, &bdbPageHeader{
+		nextPage:    db.order.Uint32(page[16:20]),
+		numEntries:  db.order.Uint16(page[20:22]),
+		freeOffset:  db.order.Uint16(page[22:24]),
+		pageType:    page[25],
+		entriesData: page[bdbPageHeaderSize:],
+	}, nil
+}
+
+// values returns the values of the hash entries stored in overflow pages
+func (db *berkeleyDB) values() ([][]byte, error) {
+	var values [][]byte
+
+	for pgno := uint32(0); pgno <= db.lastPage; pgno++ {
+		page, header, err := db.page(pgno)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.pageType != bdbHashPage && header.pageType != bdbHashUnsortedPage {
+			continue
+		}
+
+		// the entries are key/value pairs, the values being at odd indexes
+		for i := 1; i < int(header.numEntries); i += 2 {
+			if 2*i+2 > len(header.entriesData) {
+				return nil, errInvalidBerkeleyDB
+			}
+
+			offset := db.order.Uint16(header.entriesData[2*i:])
+			if int(offset) >= len(page) {
+				return nil, errInvalidBerkeleyDB
+			}
+
+			// small values, which can't be headers, are stored in the hash page itself
+			entry := page[offset:]
+			if entry[0] != bdbOffPageEntry {
+				continue
+			}
+			if len(entry) < 12 {
+				return nil, errInvalidBerkeleyDB
+			}
+
+			value, err := db.overflowValue(db.order.Uint32(entry[4:8]), db.order.Uint32(entry[8:12]))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
+
+// overflowValue reads a value stored in a chain of overflow pages
+func (db *berkeleyDB) overflowValue(pgno uint32, length uint32) ([]byte, error) {
+	if length > uint32(len(db.data)) {
+		return nil, errInvalidBerkeleyDB
+	}
+
+	value := make([]byte, 0, length)
+	for pgno != 0 && uint32(len(value)) < length {
+		_, header, err := db.page(pgno)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.pageType != bdbOverflowPage {
+			return nil, fmt.Errorf("%w: unexpected page type %d in overflow chain", errInvalidBerkeleyDB, header.pageType)
+		}
+
+		// the free area offset holds the length of the data stored in an overflow page
+		size := int(header.freeOffset)
+		if size == 0 || size > len(header.entriesData) {
+			return nil, errInvalidBerkeleyDB
+		}
+		value = append(value, header.entriesData[:size]...)
+		pgno = header.nextPage
+	}
+
+	if uint32(len(value)) != length {
+		return nil, fmt.Errorf("%w: truncated overflow value", errInvalidBerkeleyDB)
+	}
+
+	return value, nil
+}
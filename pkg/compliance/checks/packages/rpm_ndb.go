@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The rpm ndb backend, used by SUSE, stores the headers as blobs indexed by a table of slots. The
+// first two slots of the table hold the database header.
+
+const (
+	ndbHeaderMagic = 'R' | 'p'<<8 | 'm'<<16 | 'P'<<24
+	ndbSlotMagic   = 'S' | 'l'<<8 | 'o'<<16 | 't'<<24
+	ndbBlobMagic   = 'B' | 'l'<<8 | 'b'<<16 | 'S'<<24
+	ndbVersion     = 0
+
+	ndbPageSize       = 4096
+	ndbSlotSize       = 16
+	ndbHeaderSlots    = 2
+	ndbBlockSize      = 16
+	ndbBlobHeaderSize = 16
+)
+
+var errInvalidNDB = errors.New("invalid ndb database")
+
+func readRPMNDB(path string) ([]*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs, err := ndbBlobs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRPMHeaders(blobs)
+}
+
+func ndbBlobs(data []byte) ([][]byte, error) {
+	if len(data) < ndbHeaderSlots*ndbSlotSize {
+		return nil, errInvalidNDB
+	}
+
+	order := binary.LittleEndian
+	if order.Uint32(data[0:4]) != ndbHeaderMagic || order.Uint32(data[4:8]) != ndbVersion {
+		return nil, errInvalidNDB
+	}
+
+	slotPages := uint64(order.Uint32(data[12:16]))
+	slotsEnd := slotPages * ndbPageSize
+	if slotPages == 0 || slotsEnd > uint64(len(data)) {
+		return nil, errInvalidNDB
+	}
+
+	var blobs [][]byte
+	for offset := uint64(ndbHeaderSlots * ndbSlotSize); offset+ndbSlotSize <= slotsEnd; offset += ndbSlotSize {
+		slot := data[offset : offset+ndbSlotSize]
+		if order.Uint32(slot[0:4]) != ndbSlotMagic {
+			return nil, fmt.Errorf("%w: bad slot magic", errInvalidNDB)
+		}
+
+		pkgIndex := order.Uint32(slot[4:8])
+		if pkgIndex == 0 {
+			// free slot
+			continue
+		}
+
+		blobOffset := uint64(order.Uint32(slot[8:12])) * ndbBlockSize
+		if blobOffset+ndbBlobHeaderSize > uint64(len(data)) {
+			return nil, errInvalidNDB
+		}
+
+		blobHeader := data[blobOffset : blobOffset+ndbBlobHeaderSize]
+		if order.Uint32(blobHeader[0:4]) != ndbBlobMagic || order.Uint32(blobHeader[4:8]) != pkgIndex {
+			return nil, fmt.Errorf("%w: bad blob for package %d", errInvalidNDB, pkgIndex)
+		}
+
+		blobStart := blobOffset + ndbBlobHeaderSize
+		blobEnd := blobStart + uint64(order.Uint32(blobHeader[12:16]))
+		if blobEnd > uint64(len(data)) {
+			return nil, errInvalidNDB
+		}
+		blobs = append(blobs, data[blobStart:blobEnd])
+	}
+
+	return blobs, nil
+}
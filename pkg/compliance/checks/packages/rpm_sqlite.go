@@ -0,0 +1,347 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The rpm sqlite backend stores the headers in the `blob` column of the `Packages` table. The
+// file is read as a SQLite database file, without going through a SQLite library, following
+// https://www.sqlite.org/fileformat2.html. Only the table b-trees needed to list the rows of
+// a table are supported.
+
+const (
+	sqliteHeaderMagic = "SQLite format 3\x00"
+	sqliteHeaderSize  = 100
+
+	sqliteInteriorTablePage = 0x05
+	sqliteLeafTablePage     = 0x0d
+
+	// sqliteMaxDepth bounds the depth of the b-trees, to protect against loops in corrupted files
+	sqliteMaxDepth = 32
+)
+
+var errInvalidSQLite = errors.New("invalid sqlite database")
+
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+func readRPMSQLite(path string) ([]*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newSQLiteDB(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var blobs [][]byte
+	err = db.readTable("Packages", func(record []interface{}) error {
+		if len(record) < 2 {
+			return errInvalidSQLite
+		}
+		if blob, ok := record[1].([]byte); ok {
+			blobs = append(blobs, blob)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRPMHeaders(blobs)
+}
+
+func newSQLiteDB(data []byte) (*sqliteDB, error) {
+	if len(data) < sqliteHeaderSize || string(data[:16]) != sqliteHeaderMagic {
+		return nil, errInvalidSQLite
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errInvalidSQLite
+	}
+
+	return &sqliteDB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+	}, nil
+}
+
+func (db *sqliteDB) page(pgno uint32) ([]byte, error) {
+	start := int64(pgno-1) * int64(db.pageSize)
+	if pgno == 0 || start+int64(db.pageSize) > int64(len(db.data)) {
+		return nil, fmt.Errorf("%w: page %d out of bounds", errInvalidSQLite, pgno)
+	}
+	return db.data[start : start+int64(db.pageSize)], nil
+}
+
+// readTable calls fn for the record of each row of a table
+func (db *sqliteDB) readTable(name string, fn func(record []interface{}) error) error {
+	var rootPage uint32
+
+	// the schema table is rooted at the first page
+	err := db.walkTable(1, 0, func(record []interface{}) error {
+		if len(record) < 4 {
+			return errInvalidSQLite
+		}
+		if typ, _ := record[0].(string); typ != "table" {
+			return nil
+		}
+		if tblName, _ := record[1].(string); tblName != name {
+			return nil
+		}
+		if root, ok := record[3].(int64); ok {
+			rootPage = uint32(root)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rootPage == 0 {
+		return fmt.Errorf("%w: table %s not found", errInvalidSQLite, name)
+	}
+
+	return db.walkTable(rootPage, 0, fn)
+}
+
+func (db *sqliteDB) walkTable(pgno uint32, depth int, fn func(record []interface{}) error) error {
+	if depth > sqliteMaxDepth {
+		return fmt.Errorf("%w: b-tree too deep", errInvalidSQLite)
+	}
+
+	page, err := db.page(pgno)
+	if err != nil {
+		return err
+	}
+
+	// the first page starts with the database header
+	headerOffset := 0
+	if pgno == 1 {
+		headerOffset = sqliteHeaderSize
+	}
+
+	header := page[headerOffset:]
+	pageType := header[0]
+	cellCount := int(binary.BigEndian.Uint16(header[3:5]))
+
+	var cellPointers []byte
+	switch pageType {
+	case sqliteInteriorTablePage:
+		cellPointers = header[12:]
+	case sqliteLeafTablePage:
+		cellPointers = header[8:]
+	default:
+		return fmt.Errorf("%w: unexpected page type %d", errInvalidSQLite, pageType)
+	}
+
+	if len(cellPointers) < 2*cellCount {
+		return errInvalidSQLite
+	}
+
+	for i := 0; i < cellCount; i++ {
+		offset := int(binary.BigEndian.Uint16(cellPointers[2*i:]))
+		if offset >= len(page) {
+			return errInvalidSQLite
+		}
+		cell := page[offset:]
+
+		if pageType == sqliteInteriorTablePage {
+			if len(cell) < 4 {
+				return errInvalidSQLite
+			}
+			if err := db.walkTable(binary.BigEndian.Uint32(cell), depth+1, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		payload, err := db.leafPayload(cell)
+		if err != nil {
+			return err
+		}
+
+		record, err := parseSQLiteRecord(payload)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if pageType == sqliteInteriorTablePage {
+		return db.walkTable(binary.BigEndian.Uint32(header[8:12]), depth+1, fn)
+	}
+
+	return nil
+}
+
+// leafPayload returns the payload of a table leaf cell, following the overflow pages
+func (db *sqliteDB) leafPayload(cell []byte) ([]byte, error) {
+	payloadSize, n := sqliteVarint(cell)
+	if n == 0 {
+		return nil, errInvalidSQLite
+	}
+	cell = cell[n:]
+
+	// skip the row id
+	if _, n = sqliteVarint(cell); n == 0 {
+		return nil, errInvalidSQLite
+	}
+	cell = cell[n:]
+
+	size := int(payloadSize)
+	if payloadSize > uint64(len(db.data)) {
+		return nil, errInvalidSQLite
+	}
+
+	// compute the part of the payload stored in the cell
+	maxLocal := db.usableSize - 35
+	local := size
+	if size > maxLocal {
+		minLocal := (db.usableSize-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if len(cell) < local {
+		return nil, errInvalidSQLite
+	}
+
+	if local == size {
+		return cell[:size], nil
+	}
+
+	if len(cell) < local+4 {
+		return nil, errInvalidSQLite
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+
+	overflow := binary.BigEndian.Uint32(cell[local:])
+	for len(payload) < size {
+		page, err := db.page(overflow)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk := page[4:db.usableSize]
+		if remaining := size - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		overflow = binary.BigEndian.Uint32(page)
+	}
+
+	return payload, nil
+}
+
+// parseSQLiteRecord returns the column values of a record
+func parseSQLiteRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || headerSize < uint64(n) || headerSize > uint64(len(payload)) {
+		return nil, errInvalidSQLite
+	}
+
+	header := payload[n:headerSize]
+	body := payload[headerSize:]
+
+	var record []interface{}
+	for len(header) > 0 {
+		serialType, n := sqliteVarint(header)
+		if n == 0 {
+			return nil, errInvalidSQLite
+		}
+		header = header[n:]
+
+		var size uint64
+		switch {
+		case serialType == 0, serialType == 8, serialType == 9:
+			size = 0
+		case serialType <= 4:
+			size = serialType
+		case serialType == 5:
+			size = 6
+		case serialType == 6, serialType == 7:
+			size = 8
+		case serialType >= 12:
+			size = (serialType - 12) / 2
+		default:
+			return nil, errInvalidSQLite
+		}
+
+		if uint64(len(body)) < size {
+			return nil, errInvalidSQLite
+		}
+		value := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0, serialType == 7:
+			// floating point values are not needed
+			record = append(record, nil)
+		case serialType == 8:
+			record = append(record, int64(0))
+		case serialType == 9:
+			record = append(record, int64(1))
+		case serialType <= 6:
+			// big-endian two's complement integer
+			var v int64
+			if value[0]&0x80 != 0 {
+				v = -1
+			}
+			for _, b := range value {
+				v = v<<8 | int64(b)
+			}
+			record = append(record, v)
+		case serialType%2 == 0:
+			record = append(record, value)
+		default:
+			record = append(record, string(value))
+		}
+	}
+
+	return record, nil
+}
+
+// sqliteVarint decodes a big-endian variable-length integer of at most 9 bytes, returning the
+// value and the number of bytes read, 0 if the buffer is too short
+func sqliteVarint(buf []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(buf) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(buf[i]), 9
+		}
+		v = v<<7 | uint64(buf[i]&0x7f)
+		if buf[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
@@ -0,0 +1,334 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two package versions, following the ordering of the given package
+// manager. It returns -1, 0 or 1 if the first version is respectively older, the same or newer
+// than the second one.
+func CompareVersions(manager Manager, a, b string) (int, error) {
+	switch manager {
+	case Dpkg:
+		return compareDpkgVersions(a, b), nil
+	case RPM:
+		return compareRPMVersions(a, b), nil
+	case APK:
+		return compareApkVersions(a, b), nil
+	default:
+		return 0, fmt.Errorf("unknown package manager '%s'", manager)
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// compareNumbers compares two strings of digits
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return sign(len(a) - len(b))
+	}
+	return strings.Compare(a, b)
+}
+
+// splitEpoch splits the `epoch:` prefix of a version, the epoch defaulting to 0
+func splitEpoch(version string) (int, string) {
+	if i := strings.IndexByte(version, ':'); i > 0 {
+		if epoch, err := strconv.Atoi(version[:i]); err == nil {
+			return epoch, version[i+1:]
+		}
+	}
+	return 0, version
+}
+
+// splitRelease splits the `-release` suffix of a version
+func splitRelease(version string) (string, string) {
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+// compareDpkgVersions compares `[epoch:]upstream[-revision]` versions, see deb-version(7)
+func compareDpkgVersions(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	upstreamA, revisionA := splitRelease(a)
+	upstreamB, revisionB := splitRelease(b)
+	if c := dpkgVerRevCmp(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return dpkgVerRevCmp(revisionA, revisionB)
+}
+
+// dpkgOrder returns the weight of a character, the tilde sorting before anything, even the end
+// of the version, and the letters sorting before the other characters
+func dpkgOrder(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+	switch c := s[0]; {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func dpkgVerRevCmp(a, b string) int {
+	for len(a) > 0 || len(b) > 0 {
+		for (len(a) > 0 && !isDigit(a[0])) || (len(b) > 0 && !isDigit(b[0])) {
+			orderA, orderB := dpkgOrder(a), dpkgOrder(b)
+			if orderA != orderB {
+				return sign(orderA - orderB)
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		var digitsA, digitsB int
+		for digitsA < len(a) && isDigit(a[digitsA]) {
+			digitsA++
+		}
+		for digitsB < len(b) && isDigit(b[digitsB]) {
+			digitsB++
+		}
+
+		if c := compareNumbers(a[:digitsA], b[:digitsB]); c != 0 {
+			return c
+		}
+		a, b = a[digitsA:], b[digitsB:]
+	}
+	return 0
+}
+
+// compareRPMVersions compares `[epoch:]version[-release]` versions. The releases are compared
+// only when both versions have one.
+func compareRPMVersions(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	versionA, releaseA := splitRelease(a)
+	versionB, releaseB := splitRelease(b)
+	if c := rpmVerCmp(versionA, versionB); c != 0 || releaseA == "" || releaseB == "" {
+		return c
+	}
+	return rpmVerCmp(releaseA, releaseB)
+}
+
+// rpmVerCmp compares two version segments, following rpmvercmp of rpmio/rpmvercmp.c
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isSeparator := func(r rune) bool {
+		return !(r < 128 && (isDigit(byte(r)) || isAlpha(byte(r)))) && r != '~' && r != '^'
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+
+		// the tilde sorts before anything, even the end of the version
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// the caret sorts after the end of the version, but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if len(a) == 0 {
+				return -1
+			}
+			if len(b) == 0 {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if len(a) == 0 || len(b) == 0 {
+			break
+		}
+
+		numeric := isDigit(a[0])
+		inSegment := isAlpha
+		if numeric {
+			inSegment = isDigit
+		}
+
+		var lenA, lenB int
+		for lenA < len(a) && inSegment(a[lenA]) {
+			lenA++
+		}
+		for lenB < len(b) && inSegment(b[lenB]) {
+			lenB++
+		}
+
+		// a numeric segment is newer than an alphabetic one
+		if lenB == 0 {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		var c int
+		if numeric {
+			c = compareNumbers(a[:lenA], b[:lenB])
+		} else {
+			c = strings.Compare(a[:lenA], b[:lenB])
+		}
+		if c != 0 {
+			return c
+		}
+
+		a, b = a[lenA:], b[lenB:]
+	}
+
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// apkSuffixes ranks the suffixes of the apk versions, the pre-release suffixes sorting before
+// the version without suffix
+var apkSuffixes = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+type apkVersion struct {
+	numbers  []string
+	letter   byte
+	suffixes []apkSuffix
+	release  string
+}
+
+type apkSuffix struct {
+	rank   int
+	number string
+}
+
+// parseApkVersion parses a `number{.number}[letter]{_suffix[number]}[-rrelease]` version
+func parseApkVersion(version string) apkVersion {
+	var v apkVersion
+
+	if i := strings.LastIndex(version, "-r"); i >= 0 {
+		version, v.release = version[:i], version[i+2:]
+	}
+
+	parts := strings.Split(version, "_")
+	for _, part := range parts[1:] {
+		name := strings.TrimRightFunc(part, func(r rune) bool { return r >= '0' && r <= '9' })
+		v.suffixes = append(v.suffixes, apkSuffix{rank: apkSuffixes[name], number: part[len(name):]})
+	}
+
+	main := parts[0]
+	if len(main) > 0 && isAlpha(main[len(main)-1]) {
+		v.letter = main[len(main)-1]
+		main = main[:len(main)-1]
+	}
+	v.numbers = strings.Split(main, ".")
+
+	return v
+}
+
+// compareApkVersions compares apk versions, see apk_version_compare of apk-tools
+func compareApkVersions(a, b string) int {
+	versionA, versionB := parseApkVersion(a), parseApkVersion(b)
+
+	for i := 0; i < len(versionA.numbers) || i < len(versionB.numbers); i++ {
+		if i >= len(versionA.numbers) {
+			return -1
+		}
+		if i >= len(versionB.numbers) {
+			return 1
+		}
+		if c := compareNumbers(versionA.numbers[i], versionB.numbers[i]); c != 0 {
+			return c
+		}
+	}
+
+	if versionA.letter != versionB.letter {
+		return sign(int(versionA.letter) - int(versionB.letter))
+	}
+
+	for i := 0; i < len(versionA.suffixes) || i < len(versionB.suffixes); i++ {
+		var suffixA, suffixB apkSuffix
+		if i < len(versionA.suffixes) {
+			suffixA = versionA.suffixes[i]
+		}
+		if i < len(versionB.suffixes) {
+			suffixB = versionB.suffixes[i]
+		}
+		if suffixA.rank != suffixB.rank {
+			return sign(suffixA.rank - suffixB.rank)
+		}
+		if c := compareNumbers(suffixA.number, suffixB.number); c != 0 {
+			return c
+		}
+	}
+
+	return compareNumbers(versionA.release, versionB.release)
+}
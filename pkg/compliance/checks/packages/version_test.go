@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		manager  Manager
+		a, b     string
+		expected int
+	}{
+		{Dpkg, "1.0", "1.0", 0},
+		{Dpkg, "1.0", "1.1", -1},
+		{Dpkg, "1.10", "1.9", 1},
+		{Dpkg, "1:1.0", "2.0", 1},
+		{Dpkg, "1.0~rc1", "1.0", -1},
+		{Dpkg, "1.0~rc1", "1.0~rc2", -1},
+		{Dpkg, "1.0a", "1.0", 1},
+		{Dpkg, "1.0a", "1.0+", -1},
+		{Dpkg, "1.0-1", "1.0-2", -1},
+		{Dpkg, "1.0-1", "1.0", 1},
+		{Dpkg, "1:8.4p1-5+deb11u1", "1:8.4p1-5", 1},
+		{Dpkg, "1:8.4p1-5+deb11u1", "1:8.4p1-5+deb11u2", -1},
+		{Dpkg, "2.31-13+deb11u3", "2.31-13+deb11u10", -1},
+		{Dpkg, "007", "7", 0},
+
+		{RPM, "1.0", "1.0", 0},
+		{RPM, "1.0", "1.0.1", -1},
+		{RPM, "1.10", "1.9", 1},
+		{RPM, "1.0a", "1.0", 1},
+		{RPM, "1.a", "1.1", -1},
+		{RPM, "1.0~rc1", "1.0", -1},
+		{RPM, "1.0^git1", "1.0", 1},
+		{RPM, "1.0^git1", "1.0.1", -1},
+		{RPM, "1:1.0", "2.0", 1},
+		{RPM, "8.0p1-13.el8", "8.0p1", 0},
+		{RPM, "8.0p1-13.el8", "8.0p1-12.el8", 1},
+		{RPM, "1.1.1k-7.el8_6", "1.1.1k-7.el8_10", -1},
+		{RPM, "2.0_1", "2.0.1", 0},
+
+		{APK, "1.2.3-r0", "1.2.3-r0", 0},
+		{APK, "1.2.3-r1", "1.2.3-r0", 1},
+		{APK, "1.2.3", "1.2.10", -1},
+		{APK, "1.1.1q-r0", "1.1.1k-r0", 1},
+		{APK, "1.2.3_rc1", "1.2.3", -1},
+		{APK, "1.2.3_alpha", "1.2.3_beta", -1},
+		{APK, "1.2.3_p1", "1.2.3", 1},
+		{APK, "1.2", "1.2.1", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s %s", test.manager, test.a, test.b), func(t *testing.T) {
+			result, err := CompareVersions(test.manager, test.a, test.b)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+
+			result, err = CompareVersions(test.manager, test.b, test.a)
+			assert.NoError(t, err)
+			assert.Equal(t, -test.expected, result)
+		})
+	}
+
+	_, err := CompareVersions("pacman", "1", "2")
+	assert.Error(t, err)
+}
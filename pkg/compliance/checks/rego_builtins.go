@@ -14,6 +14,8 @@ import (
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/types"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/packages"
 )
 
 //go:embed rego_helpers/datadog.rego
@@ -21,6 +23,7 @@ var helpers string
 
 var regoBuiltins = []func(*rego.Rego){
 	octalLiteralFunc,
+	packageVersionCompareFunc,
 }
 
 var octalLiteralFunc = rego.Function1(
@@ -42,3 +45,30 @@ var octalLiteralFunc = rego.Function1(
 		return ast.IntNumberTerm(int(value)), err
 	},
 )
+
+// packageVersionCompareFunc compares two versions of a package, following the ordering of the package
+// manager, like `package_version_compare(input.package.manager, input.package.version, "1.2.3")`.
+// It returns -1, 0 or 1 if the first version is respectively older, the same or newer than the second one.
+var packageVersionCompareFunc = rego.Function3(
+	&rego.Function{
+		Name: "package_version_compare",
+		Decl: types.NewFunction(types.Args(types.S, types.S, types.S), types.N),
+	},
+	func(_ rego.BuiltinContext, manager, a, b *ast.Term) (*ast.Term, error) {
+		var args [3]string
+		for i, term := range []*ast.Term{manager, a, b} {
+			str, ok := term.Value.(ast.String)
+			if !ok {
+				return nil, errors.New("failed to compare package versions, expecting string arguments")
+			}
+			args[i] = string(str)
+		}
+
+		result, err := packages.CompareVersions(packages.Manager(args[0]), args[1], args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.IntNumberTerm(result), nil
+	},
+)
@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	procSysPath      = "/proc/sys"
+	sysctlConfigFile = "/etc/sysctl.conf"
+)
+
+// sysctlConfigDirs lists the sysctl.d directories by decreasing priority
+var sysctlConfigDirs = []string{
+	"/etc/sysctl.d",
+	"/run/sysctl.d",
+	"/usr/local/lib/sysctl.d",
+	"/usr/lib/sysctl.d",
+	"/lib/sysctl.d",
+}
+
+var sysctlReportedFields = []string{
+	compliance.SysctlFieldKey,
+	compliance.SysctlFieldValue,
+	compliance.SysctlFieldPersistedValue,
+	compliance.SysctlFieldPersistedFile,
+}
+
+// ErrSysctlNotFound is returned when no kernel parameter matches a sysctl key
+var ErrSysctlNotFound = errors.New("sysctl key not found")
+
+// sysctlSetting is a kernel parameter value set by a configuration file
+type sysctlSetting struct {
+	value string
+	file  string
+}
+
+func resolveSysctl(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", id)
+	}
+
+	sysctl := res.Sysctl
+
+	log.Debugf("%s: running sysctl check: %s", id, sysctl.Key)
+
+	root := e.NormalizeToHostRoot(procSysPath)
+	paths, err := filepath.Glob(filepath.Join(root, swapSysctlSeparators(sysctl.Key)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid sysctl key %s: %w", id, sysctl.Key, err)
+	}
+
+	persisted := readSysctlConfig(e)
+
+	var instances []resolvedInstance
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		key := swapSysctlSeparators(filepath.ToSlash(rel))
+
+		// some parameters, like net.ipv4.route.flush, are write only
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Debugf("%s: unable to read sysctl %s: %v", id, key, err)
+			continue
+		}
+		value := normalizeSysctlValue(string(data))
+
+		setting, configured := persisted[key]
+		input := eval.RegoInputMap{
+			"key":    key,
+			"value":  value,
+			"fields": strings.Fields(value),
+		}
+		if configured {
+			input["persisted"] = map[string]interface{}{
+				"value":  setting.value,
+				"fields": strings.Fields(setting.value),
+				"file":   setting.file,
+			}
+		}
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.SysctlFieldKey:            key,
+				compliance.SysctlFieldValue:          value,
+				compliance.SysctlFieldPersisted:      configured,
+				compliance.SysctlFieldPersistedValue: setting.value,
+				compliance.SysctlFieldPersistedFile:  setting.file,
+			},
+			nil,
+			input,
+		)
+		instances = append(instances, newResolvedInstance(instance, key, "sysctl"))
+	}
+
+	if len(instances) == 0 {
+		if rego {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w: %s", id, ErrSysctlNotFound, sysctl.Key)
+	}
+
+	if len(instances) == 1 {
+		return instances[0].(*_resolvedInstance), nil
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+// readSysctlConfig returns the kernel parameters persisted in the sysctl.d directories and in
+// /etc/sysctl.conf, the latter being applied last
+func readSysctlConfig(e env.Env) map[string]sysctlSetting {
+	settings := make(map[string]sysctlSetting)
+
+	files := append(listConfigFiles(e, sysctlConfigDirs), sysctlConfigFile)
+	for _, file := range files {
+		err := readConfigLines(e.NormalizeToHostRoot(file), "#;", func(line string) {
+			assignment := strings.SplitN(line, "=", 2)
+			if len(assignment) != 2 {
+				return
+			}
+
+			// a leading dash tells to ignore the errors when setting the parameter
+			key := strings.TrimPrefix(strings.TrimSpace(assignment[0]), "-")
+			settings[normalizeSysctlKey(key)] = sysctlSetting{
+				value: normalizeSysctlValue(assignment[1]),
+				file:  file,
+			}
+		})
+		if err != nil && !os.IsNotExist(err) {
+			log.Debugf("unable to read sysctl configuration %s: %v", file, err)
+		}
+	}
+
+	return settings
+}
+
+// swapSysctlSeparators converts a dotted sysctl key to its path relative to /proc/sys, and back
+func swapSysctlSeparators(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return '/'
+		case '/':
+			return '.'
+		}
+		return r
+	}, key)
+}
+
+// normalizeSysctlKey returns the dotted notation of a key, which may be written with slashes in
+// the configuration files
+func normalizeSysctlKey(key string) string {
+	if i := strings.IndexAny(key, "./"); i >= 0 && key[i] == '/' {
+		return swapSysctlSeparators(key)
+	}
+	return key
+}
+
+// normalizeSysctlValue collapses the whitespaces of a value, multiple values being separated by
+// tabs in /proc/sys and by spaces in the configuration files
+func normalizeSysctlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
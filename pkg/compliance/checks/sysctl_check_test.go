@@ -0,0 +1,249 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+// newFakeHostRootEnv returns an environment whose host root is the sysctl testdata directory
+func newFakeHostRootEnv() *mocks.Env {
+	root := "./testdata/sysctl"
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+		return filepath.Join(root, path)
+	}).Maybe()
+	return env
+}
+
+func TestSysctlCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReports []*compliance.Report
+		expectError   error
+	}{
+		{
+			name: "runtime value",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "kernel.randomize_va_space",
+					},
+				},
+				Condition: `sysctl.value == "2" && sysctl.persistedValue == "2"`,
+			},
+			expectReports: []*compliance.Report{
+				{
+					Passed: true,
+					Data: event.Data{
+						"sysctl.key":            "kernel.randomize_va_space",
+						"sysctl.value":          "2",
+						"sysctl.persistedValue": "2",
+						"sysctl.persistedFile":  "/etc/sysctl.d/60-hardening.conf",
+					},
+					Resource: compliance.ReportResource{
+						ID:   "kernel.randomize_va_space",
+						Type: "sysctl",
+					},
+				},
+			},
+		},
+		{
+			name: "runtime value differs from persisted value",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "net.ipv4.ip_forward",
+					},
+				},
+				Condition: `sysctl.value == sysctl.persistedValue`,
+			},
+			expectReports: []*compliance.Report{
+				{
+					Passed: false,
+					Data: event.Data{
+						"sysctl.key":            "net.ipv4.ip_forward",
+						"sysctl.value":          "0",
+						"sysctl.persistedValue": "1",
+						"sysctl.persistedFile":  "/etc/sysctl.conf",
+					},
+					Resource: compliance.ReportResource{
+						ID:   "net.ipv4.ip_forward",
+						Type: "sysctl",
+					},
+				},
+			},
+		},
+		{
+			name: "multiple values",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "net.ipv4.ip_local_port_range",
+					},
+				},
+				Condition: `sysctl.persisted && sysctl.value == sysctl.persistedValue`,
+			},
+			expectReports: []*compliance.Report{
+				{
+					Passed: true,
+					Data: event.Data{
+						"sysctl.key":            "net.ipv4.ip_local_port_range",
+						"sysctl.value":          "32768 60999",
+						"sysctl.persistedValue": "32768 60999",
+						"sysctl.persistedFile":  "/etc/sysctl.d/60-hardening.conf",
+					},
+					Resource: compliance.ReportResource{
+						ID:   "net.ipv4.ip_local_port_range",
+						Type: "sysctl",
+					},
+				},
+			},
+		},
+		{
+			name: "glob",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "net.ipv4.conf.*.rp_filter",
+					},
+				},
+				Condition: `sysctl.value == "1"`,
+			},
+			expectReports: []*compliance.Report{
+				{
+					Passed: true,
+					Data: event.Data{
+						"sysctl.key":            "net.ipv4.conf.all.rp_filter",
+						"sysctl.value":          "1",
+						"sysctl.persistedValue": "1",
+						"sysctl.persistedFile":  "/etc/sysctl.d/60-hardening.conf",
+					},
+					Resource: compliance.ReportResource{
+						ID:   "net.ipv4.conf.all.rp_filter",
+						Type: "sysctl",
+					},
+				},
+				{
+					Passed: false,
+					Data: event.Data{
+						"sysctl.key":            "net.ipv4.conf.default.rp_filter",
+						"sysctl.value":          "2",
+						"sysctl.persistedValue": "2",
+						"sysctl.persistedFile":  "/usr/lib/sysctl.d/50-default.conf",
+					},
+					Resource: compliance.ReportResource{
+						ID:   "net.ipv4.conf.default.rp_filter",
+						Type: "sysctl",
+					},
+				},
+			},
+		},
+		{
+			name: "unknown key",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "net.ipv6.conf.all.forwarding",
+					},
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectError: ErrSysctlNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newFakeHostRootEnv()
+
+			sysctlCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := sysctlCheck.check(env)
+			if test.expectError != nil {
+				assert.ErrorIs(reports[0].Error, test.expectError)
+				return
+			}
+			assert.Equal(test.expectReports, reports)
+		})
+	}
+}
+
+func TestSysctlRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	fixture := regoFixture{
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Key: "net.ipv4.ip_local_port_range",
+					},
+				},
+				TagName: "range",
+			},
+		},
+		module: `
+			package test
+
+			import data.datadog as dd
+
+			findings[f] {
+				to_number(input.range.fields[0]) >= 32768
+				input.range.persisted.value == input.range.value
+				f := dd.passed_finding("sysctl", input.range.key, {"sysctl.persistedFile": input.range.persisted.file})
+			}
+		`,
+		findings: "data.test.findings",
+	}
+
+	env := newFakeHostRootEnv()
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+
+	regoCheck, err := fixture.newRegoCheck()
+	assert.NoError(err)
+
+	reports := regoCheck.check(env)
+	assert.Equal([]*compliance.Report{
+		{
+			Passed: true,
+			Data: event.Data{
+				"sysctl.persistedFile": "/etc/sysctl.d/60-hardening.conf",
+			},
+			Resource: compliance.ReportResource{
+				ID:   "net.ipv4.ip_local_port_range",
+				Type: "sysctl",
+			},
+			Evaluator: "rego",
+		},
+	}, reports)
+}
+
+func TestSysctlKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("net/ipv4/conf/eth0.100/rp_filter", swapSysctlSeparators("net.ipv4.conf.eth0/100.rp_filter"))
+	assert.Equal("net.ipv4.conf.eth0/100.rp_filter", swapSysctlSeparators("net/ipv4/conf/eth0.100/rp_filter"))
+	assert.Equal("net.ipv4.conf.eth0/100.rp_filter", normalizeSysctlKey("net/ipv4/conf/eth0.100/rp_filter"))
+	assert.Equal("net.ipv4.conf.eth0/100.rp_filter", normalizeSysctlKey("net.ipv4.conf.eth0/100.rp_filter"))
+}
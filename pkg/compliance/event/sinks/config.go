@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"fmt"
+	"time"
+
+	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// Sink types
+const (
+	FileSinkType    = "file"
+	SyslogSinkType  = "syslog"
+	WebhookSinkType = "webhook"
+)
+
+const (
+	defaultQueueSize         = 1000
+	defaultFlushInterval     = time.Second
+	defaultFileMaxSize       = 100 * 1024 * 1024
+	defaultFileMaxBackups    = 5
+	defaultSyslogFacility    = 16 // local0
+	defaultWebhookBatchSize  = 100
+	defaultWebhookFlush      = 5 * time.Second
+	defaultWebhookMaxRetries = 3
+	defaultWebhookTimeout    = 10 * time.Second
+)
+
+// Config describes a local sink
+type Config struct {
+	Name      string `mapstructure:"name"`
+	Type      string `mapstructure:"type"`
+	QueueSize int    `mapstructure:"queue_size"`
+
+	// filtering
+	RuleIDs []string `mapstructure:"rule_ids"`
+	Tags    []string `mapstructure:"tags"`
+
+	// file
+	Path       string `mapstructure:"path"`
+	MaxSize    int64  `mapstructure:"max_size"`
+	MaxBackups int    `mapstructure:"max_backups"`
+
+	// syslog
+	Network  string `mapstructure:"network"`
+	Address  string `mapstructure:"address"`
+	Facility int    `mapstructure:"facility"`
+
+	// webhook
+	URL           string            `mapstructure:"url"`
+	Headers       map[string]string `mapstructure:"headers"`
+	BatchSize     int               `mapstructure:"batch_size"`
+	FlushInterval time.Duration     `mapstructure:"flush_interval"`
+	MaxRetries    int               `mapstructure:"max_retries"`
+	Timeout       time.Duration     `mapstructure:"timeout"`
+}
+
+// LoadConfigs reads the list of sinks configured under the given key
+func LoadConfigs(key string) ([]Config, error) {
+	var configs []Config
+	if !coreconfig.Datadog.IsSet(key) {
+		return nil, nil
+	}
+
+	if err := coreconfig.Datadog.UnmarshalKey(key, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+
+	for i := range configs {
+		if err := configs[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid sink #%d in %s: %w", i, key, err)
+		}
+	}
+
+	return configs, nil
+}
+
+// validate checks the sink configuration and sets the default values
+func (c *Config) validate() error {
+	if c.Name == "" {
+		c.Name = c.Type
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+
+	switch c.Type {
+	case FileSinkType:
+		if c.Path == "" {
+			return fmt.Errorf("sink '%s': missing path", c.Name)
+		}
+		if c.MaxSize <= 0 {
+			c.MaxSize = defaultFileMaxSize
+		}
+		if c.MaxBackups <= 0 {
+			c.MaxBackups = defaultFileMaxBackups
+		}
+	case SyslogSinkType:
+		switch c.Network {
+		case "", "udp", "tcp", "unix", "unixgram":
+		default:
+			return fmt.Errorf("sink '%s': unsupported network '%s'", c.Name, c.Network)
+		}
+		if c.Network != "" && c.Address == "" {
+			return fmt.Errorf("sink '%s': missing address", c.Name)
+		}
+		if c.Facility == 0 {
+			c.Facility = defaultSyslogFacility
+		} else if c.Facility < 0 || c.Facility > 23 {
+			return fmt.Errorf("sink '%s': invalid facility %d", c.Name, c.Facility)
+		}
+	case WebhookSinkType:
+		if c.URL == "" {
+			return fmt.Errorf("sink '%s': missing url", c.Name)
+		}
+		if c.BatchSize <= 0 {
+			c.BatchSize = defaultWebhookBatchSize
+		}
+		if c.FlushInterval <= 0 {
+			c.FlushInterval = defaultWebhookFlush
+		}
+		if c.MaxRetries <= 0 {
+			c.MaxRetries = defaultWebhookMaxRetries
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = defaultWebhookTimeout
+		}
+	default:
+		return fmt.Errorf("sink '%s': unknown type '%s'", c.Name, c.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileSink writes the messages as JSON lines, rotating the file once it reaches its maximum size.
+// The rotated files are suffixed with their index, `.1` being the most recent one.
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	writer     *bufio.Writer
+	size       int64
+	stats      *stats
+}
+
+func newFileSink(cfg Config, stats *stats) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, err
+	}
+
+	s := &fileSink{
+		path:       cfg.Path,
+		maxSize:    cfg.MaxSize,
+		maxBackups: cfg.MaxBackups,
+		stats:      stats,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+
+	for i := s.maxBackups - 1; i > 0; i-- {
+		from := fmt.Sprintf("%s.%d", s.path, i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", s.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// Send implements the Sink interface
+func (s *fileSink) Send(msg *Message) error {
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			s.stats.addDropped(1)
+			return err
+		}
+	}
+
+	size := int64(len(msg.Content) + 1)
+	if s.size > 0 && s.size+size > s.maxSize {
+		if err := s.rotate(); err != nil {
+			s.stats.addDropped(1)
+			return fmt.Errorf("failed to rotate %s: %w", s.path, err)
+		}
+	}
+
+	if _, err := s.writer.Write(msg.Content); err != nil {
+		s.stats.addDropped(1)
+		return err
+	}
+	if err := s.writer.WriteByte('\n'); err != nil {
+		s.stats.addDropped(1)
+		return err
+	}
+
+	s.size += size
+	s.stats.addSent(1)
+	return nil
+}
+
+// Flush implements the Sink interface
+func (s *fileSink) Flush() error {
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Flush()
+}
+
+func (s *fileSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.writer.Flush()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file, s.writer = nil, nil
+	return err
+}
+
+// Close implements the Sink interface
+func (s *fileSink) Close() error {
+	return s.closeFile()
+}
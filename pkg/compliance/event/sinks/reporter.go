@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const ruleIDTagPrefix = "rule_id:"
+
+// Reporter forwards the events to an underlying reporter, and to the local sinks
+type Reporter struct {
+	reporter event.Reporter
+	runners  []*runner
+	lock     sync.RWMutex
+	stopped  bool
+}
+
+// NewReporter returns a reporter delivering the events to the given reporter and to the configured
+// local sinks. The application name is used to identify the sender of the syslog messages.
+func NewReporter(reporter event.Reporter, hostname, appName string, configs []Config) (*Reporter, error) {
+	r := &Reporter{
+		reporter: reporter,
+	}
+
+	for _, cfg := range configs {
+		runner, err := newRunner(cfg, hostname, appName)
+		if err != nil {
+			r.Stop()
+			return nil, err
+		}
+		r.runners = append(r.runners, runner)
+		log.Infof("Security events are forwarded to the %s sink '%s'", cfg.Type, cfg.Name)
+	}
+
+	for _, runner := range r.runners {
+		runner.start()
+	}
+
+	return r, nil
+}
+
+// Report implements the event.Reporter interface
+func (r *Reporter) Report(e *event.Event) {
+	r.reporter.Report(e)
+
+	if len(r.runners) == 0 {
+		return
+	}
+
+	buf, err := json.Marshal(e)
+	if err != nil {
+		log.Errorf("Failed to serialize rule event for rule %s", e.AgentRuleID)
+		return
+	}
+
+	r.dispatch(&Message{
+		Timestamp: time.Now(),
+		RuleID:    e.AgentRuleID,
+		Tags:      e.Tags,
+		Content:   buf,
+	})
+}
+
+// ReportRaw implements the event.Reporter interface
+func (r *Reporter) ReportRaw(content []byte, service string, tags ...string) {
+	r.reporter.ReportRaw(content, service, tags...)
+
+	if len(r.runners) == 0 {
+		return
+	}
+
+	var ruleID string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, ruleIDTagPrefix) {
+			ruleID = strings.TrimPrefix(tag, ruleIDTagPrefix)
+			break
+		}
+	}
+
+	r.dispatch(&Message{
+		Timestamp: time.Now(),
+		RuleID:    ruleID,
+		Service:   service,
+		Tags:      tags,
+		Content:   content,
+	})
+}
+
+func (r *Reporter) dispatch(msg *Message) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.stopped {
+		return
+	}
+
+	for _, runner := range r.runners {
+		runner.push(msg)
+	}
+}
+
+// GetStatus returns the delivery statistics of the local sinks
+func (r *Reporter) GetStatus() []map[string]interface{} {
+	var status []map[string]interface{}
+	for _, runner := range r.runners {
+		status = append(status, runner.status())
+	}
+	return status
+}
+
+// Stop flushes the pending events and closes the local sinks
+func (r *Reporter) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.stopped {
+		return
+	}
+	r.stopped = true
+
+	for _, runner := range r.runners {
+		runner.stop()
+	}
+}
@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Message is an event delivered to the local sinks
+type Message struct {
+	Timestamp time.Time
+	RuleID    string
+	Service   string
+	Tags      []string
+	Content   []byte
+}
+
+// Sink defines a local output of security events
+type Sink interface {
+	// Send delivers a message, blocking until it is written or failed
+	Send(msg *Message) error
+	// Flush delivers the buffered messages, if any
+	Flush() error
+	// Close releases the resources of the sink
+	Close() error
+}
+
+// Filter selects the messages delivered to a sink. An empty filter selects all the messages.
+type Filter struct {
+	ruleIDs map[string]bool
+	tags    map[string]bool
+}
+
+// NewFilter returns a filter selecting the messages matching one of the rule IDs, and one of the tags
+func NewFilter(ruleIDs []string, tags []string) *Filter {
+	f := &Filter{}
+	if len(ruleIDs) > 0 {
+		f.ruleIDs = make(map[string]bool)
+		for _, id := range ruleIDs {
+			f.ruleIDs[id] = true
+		}
+	}
+	if len(tags) > 0 {
+		f.tags = make(map[string]bool)
+		for _, tag := range tags {
+			f.tags[tag] = true
+		}
+	}
+	return f
+}
+
+// Match returns whether the message is selected by the filter. A tag of the filter without
+// value, like `tactic`, matches all the values of the tag.
+func (f *Filter) Match(msg *Message) bool {
+	if f.ruleIDs != nil && !f.ruleIDs[msg.RuleID] {
+		return false
+	}
+	if f.tags == nil {
+		return true
+	}
+	for _, tag := range msg.Tags {
+		if f.tags[tag] {
+			return true
+		}
+		if i := strings.IndexByte(tag, ':'); i > 0 && f.tags[tag[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// stats holds the delivery statistics of a sink
+type stats struct {
+	sent      uint64
+	dropped   uint64
+	errors    uint64
+	lock      sync.RWMutex
+	lastError string
+}
+
+func (s *stats) addSent(count int) {
+	atomic.AddUint64(&s.sent, uint64(count))
+}
+
+func (s *stats) addDropped(count int) {
+	atomic.AddUint64(&s.dropped, uint64(count))
+}
+
+func (s *stats) addError(err error) {
+	atomic.AddUint64(&s.errors, 1)
+
+	s.lock.Lock()
+	s.lastError = err.Error()
+	s.lock.Unlock()
+}
+
+func (s *stats) getLastError() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.lastError
+}
+
+// runner delivers the messages queued for a sink
+type runner struct {
+	name     string
+	sinkType string
+	sink     Sink
+	filter   *Filter
+	queue    chan *Message
+	flush    time.Duration
+	stats    *stats
+	wg       sync.WaitGroup
+}
+
+func newRunner(cfg Config, hostname, appName string) (*runner, error) {
+	st := &stats{}
+
+	var sink Sink
+	var err error
+	switch cfg.Type {
+	case FileSinkType:
+		sink, err = newFileSink(cfg, st)
+	case SyslogSinkType:
+		sink, err = newSyslogSink(cfg, hostname, appName, st)
+	case WebhookSinkType:
+		sink, err = newWebhookSink(cfg, st)
+	default:
+		err = fmt.Errorf("unknown sink type '%s'", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	flush := cfg.FlushInterval
+	if flush <= 0 {
+		flush = defaultFlushInterval
+	}
+
+	return &runner{
+		name:     cfg.Name,
+		sinkType: cfg.Type,
+		sink:     sink,
+		filter:   NewFilter(cfg.RuleIDs, cfg.Tags),
+		queue:    make(chan *Message, cfg.QueueSize),
+		flush:    flush,
+		stats:    st,
+	}, nil
+}
+
+// push queues a message without blocking, dropping it when the queue is full
+func (r *runner) push(msg *Message) {
+	if !r.filter.Match(msg) {
+		return
+	}
+
+	select {
+	case r.queue <- msg:
+	default:
+		r.stats.addDropped(1)
+	}
+}
+
+func (r *runner) start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+func (r *runner) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.flush)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-r.queue:
+			if !ok {
+				r.handleError(r.sink.Flush())
+				return
+			}
+			r.handleError(r.sink.Send(msg))
+		case <-ticker.C:
+			r.handleError(r.sink.Flush())
+		}
+	}
+}
+
+func (r *runner) handleError(err error) {
+	if err == nil {
+		return
+	}
+
+	r.stats.addError(err)
+	log.Debugf("sink '%s' failed to deliver events: %v", r.name, err)
+}
+
+func (r *runner) stop() {
+	close(r.queue)
+	r.wg.Wait()
+
+	if err := r.sink.Close(); err != nil {
+		log.Errorf("failed to close sink '%s': %v", r.name, err)
+	}
+}
+
+func (r *runner) status() map[string]interface{} {
+	return map[string]interface{}{
+		"name":      r.name,
+		"type":      r.sinkType,
+		"sent":      atomic.LoadUint64(&r.stats.sent),
+		"dropped":   atomic.LoadUint64(&r.stats.dropped),
+		"errors":    atomic.LoadUint64(&r.stats.errors),
+		"lastError": r.stats.getLastError(),
+	}
+}
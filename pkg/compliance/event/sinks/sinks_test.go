@@ -0,0 +1,289 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruleIDs []string
+		tags    []string
+		msg     *Message
+		match   bool
+	}{
+		{
+			name:  "empty",
+			msg:   &Message{RuleID: "rule_a"},
+			match: true,
+		},
+		{
+			name:    "rule id",
+			ruleIDs: []string{"rule_a", "rule_b"},
+			msg:     &Message{RuleID: "rule_b"},
+			match:   true,
+		},
+		{
+			name:    "other rule id",
+			ruleIDs: []string{"rule_a"},
+			msg:     &Message{RuleID: "rule_b"},
+			match:   false,
+		},
+		{
+			name:  "tag",
+			tags:  []string{"tactic:TA0002-execution"},
+			msg:   &Message{Tags: []string{"rule_id:rule_a", "tactic:TA0002-execution"}},
+			match: true,
+		},
+		{
+			name:  "tag key",
+			tags:  []string{"tactic"},
+			msg:   &Message{Tags: []string{"tactic:TA0002-execution"}},
+			match: true,
+		},
+		{
+			name:  "other tag",
+			tags:  []string{"tactic:TA0003-persistence"},
+			msg:   &Message{Tags: []string{"tactic:TA0002-execution"}},
+			match: false,
+		},
+		{
+			name:    "rule id and tag",
+			ruleIDs: []string{"rule_a"},
+			tags:    []string{"tactic"},
+			msg:     &Message{RuleID: "rule_a"},
+			match:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.match, NewFilter(test.ruleIDs, test.tags).Match(test.msg))
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := Config{Type: WebhookSinkType, URL: "http://localhost"}
+	assert.NoError(cfg.validate())
+	assert.Equal(WebhookSinkType, cfg.Name)
+	assert.Equal(defaultWebhookBatchSize, cfg.BatchSize)
+	assert.Equal(defaultWebhookFlush, cfg.FlushInterval)
+
+	cfg = Config{Type: FileSinkType}
+	assert.Error(cfg.validate())
+
+	cfg = Config{Type: SyslogSinkType, Network: "tcp"}
+	assert.Error(cfg.validate())
+
+	cfg = Config{Type: "kafka"}
+	assert.Error(cfg.validate())
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "events.json")
+	cfg := Config{Type: FileSinkType, Path: path, MaxSize: 20, MaxBackups: 2}
+	assert.NoError(cfg.validate())
+
+	st := &stats{}
+	sink, err := newFileSink(cfg, st)
+	assert.NoError(err)
+
+	for _, content := range []string{`{"id":1}`, `{"id":2}`, `{"id":3}`, `{"id":4}`, `{"id":5}`, `{"id":6}`, `{"id":7}`} {
+		assert.NoError(sink.Send(&Message{Content: []byte(content)}))
+	}
+	assert.NoError(sink.Close())
+
+	assert.Equal([]string{`{"id":7}`}, readLines(t, path))
+	assert.Equal([]string{`{"id":5}`, `{"id":6}`}, readLines(t, path+".1"))
+	assert.Equal([]string{`{"id":3}`, `{"id":4}`}, readLines(t, path+".2"))
+	assert.NoFileExists(path + ".3")
+	assert.EqualValues(7, st.sent)
+}
+
+func TestSyslogSink(t *testing.T) {
+	assert := assert.New(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	cfg := Config{Type: SyslogSinkType, Network: "udp", Address: conn.LocalAddr().String()}
+	assert.NoError(cfg.validate())
+
+	sink, err := newSyslogSink(cfg, "my host", "runtime-security-agent", &stats{})
+	assert.NoError(err)
+	defer sink.Close()
+
+	timestamp := time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC)
+	assert.NoError(sink.Send(&Message{Timestamp: timestamp, RuleID: "rule_a", Content: []byte(`{"id":1}`)}))
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(err)
+
+	expected := "<134>1 2022-03-04T05:06:07.000008Z myhost runtime-security-agent " + sink.procID + ` rule_a - {"id":1}`
+	assert.Equal(expected, string(buf[:n]))
+}
+
+func TestWebhookSink(t *testing.T) {
+	assert := assert.New(t)
+
+	var lock sync.Mutex
+	var batches [][]map[string]interface{}
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal("secret", r.Header.Get("X-Api-Key"))
+		body, _ := io.ReadAll(r.Body)
+		var batch []map[string]interface{}
+		assert.NoError(json.Unmarshal(body, &batch))
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	cfg := Config{Type: WebhookSinkType, URL: server.URL, BatchSize: 2, Headers: map[string]string{"X-Api-Key": "secret"}}
+	assert.NoError(cfg.validate())
+
+	st := &stats{}
+	sink, err := newWebhookSink(cfg, st)
+	assert.NoError(err)
+	sink.retryInterval = time.Millisecond
+
+	for _, content := range []string{`{"id":1}`, `{"id":2}`, `{"id":3}`} {
+		assert.NoError(sink.Send(&Message{Content: []byte(content)}))
+	}
+	assert.NoError(sink.Flush())
+
+	assert.Equal(3, calls)
+	assert.Len(batches, 2)
+	assert.Len(batches[0], 2)
+	assert.Len(batches[1], 1)
+	assert.EqualValues(3, st.sent)
+}
+
+func TestWebhookSinkClientError(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg := Config{Type: WebhookSinkType, URL: server.URL}
+	assert.NoError(cfg.validate())
+
+	st := &stats{}
+	sink, err := newWebhookSink(cfg, st)
+	assert.NoError(err)
+	sink.retryInterval = time.Millisecond
+
+	assert.NoError(sink.Send(&Message{Content: []byte(`{"id":1}`)}))
+	assert.Error(sink.Flush())
+	assert.Equal(1, calls)
+	assert.EqualValues(1, st.dropped)
+}
+
+func TestReporter(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	configs := []Config{
+		{Name: "all", Type: FileSinkType, Path: filepath.Join(dir, "all.json")},
+		{Name: "rule_a", Type: FileSinkType, Path: filepath.Join(dir, "rule_a.json"), RuleIDs: []string{"rule_a"}},
+	}
+	for i := range configs {
+		assert.NoError(configs[i].validate())
+	}
+
+	delegate := &mocks.Reporter{}
+	delegate.On("ReportRaw", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	delegate.On("ReportRaw", mock.Anything, mock.Anything, mock.Anything).Return()
+	delegate.On("Report", mock.Anything).Return()
+
+	reporter, err := NewReporter(delegate, "myhost", "runtime-security-agent", configs)
+	assert.NoError(err)
+
+	reporter.ReportRaw([]byte(`{"id":1}`), "runtime-security-agent", "rule_id:rule_a", "tactic:TA0002-execution")
+	reporter.ReportRaw([]byte(`{"id":2}`), "runtime-security-agent", "rule_id:rule_b")
+	reporter.Report(&event.Event{AgentRuleID: "rule_a", Result: event.Passed})
+	reporter.Stop()
+
+	// events reported after the reporter was stopped are only forwarded
+	reporter.ReportRaw([]byte(`{"id":3}`), "runtime-security-agent", "rule_id:rule_a")
+
+	delegate.AssertNumberOfCalls(t, "ReportRaw", 3)
+	delegate.AssertNumberOfCalls(t, "Report", 1)
+
+	assert.Len(readLines(t, filepath.Join(dir, "all.json")), 3)
+
+	lines := readLines(t, filepath.Join(dir, "rule_a.json"))
+	if assert.Len(lines, 2) {
+		assert.Equal(`{"id":1}`, lines[0])
+		assert.True(strings.Contains(lines[1], `"agent_rule_id":"rule_a"`), lines[1])
+	}
+
+	status := reporter.GetStatus()
+	if assert.Len(status, 2) {
+		assert.Equal("all", status[0]["name"])
+		assert.EqualValues(3, status[0]["sent"])
+		assert.EqualValues(2, status[1]["sent"])
+	}
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	syslogSeverityInfo = 6
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+)
+
+// localSyslogSockets lists the sockets tried when no syslog address is configured
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogSink sends the messages to a syslog server, in the RFC 5424 format. The messages
+// sent over TCP are framed with the octet counting method of RFC 6587.
+type syslogSink struct {
+	network  string
+	address  string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	conn     net.Conn
+	stats    *stats
+}
+
+func newSyslogSink(cfg Config, hostname, appName string, stats *stats) (*syslogSink, error) {
+	if hostname == "" {
+		hostname = "-"
+	}
+	if appName == "" {
+		appName = "-"
+	}
+
+	return &syslogSink{
+		network:  cfg.Network,
+		address:  cfg.Address,
+		facility: cfg.Facility,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(appName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+		stats:    stats,
+	}, nil
+}
+
+func (s *syslogSink) connect() error {
+	if s.network != "" {
+		conn, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		return nil
+	}
+
+	var err error
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, path, syslogDialTimeout); err == nil {
+				s.conn = conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("failed to connect to the local syslog daemon: %w", err)
+}
+
+// syslogHeaderField returns the value of a header field, made of printable US-ASCII
+// characters only
+func syslogHeaderField(value string, maxLen int) string {
+	field := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(field) < maxLen; i++ {
+		if c := value[i]; c >= 33 && c <= 126 {
+			field = append(field, c)
+		}
+	}
+	if len(field) == 0 {
+		return "-"
+	}
+	return string(field)
+}
+
+// format returns the RFC 5424 representation of the message, the rule ID being used as MSGID
+func (s *syslogSink) format(msg *Message) []byte {
+	msgID := "-"
+	if msg.RuleID != "" {
+		msgID = syslogHeaderField(msg.RuleID, 32)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s - ",
+		s.facility*8+syslogSeverityInfo,
+		msg.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, s.appName, s.procID, msgID)
+	buf.Write(msg.Content)
+
+	return buf.Bytes()
+}
+
+func (s *syslogSink) write(data []byte) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	if s.network == "tcp" {
+		data = append([]byte(strconv.Itoa(len(data))+" "), data...)
+	}
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := s.conn.Write(data); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Send implements the Sink interface
+func (s *syslogSink) Send(msg *Message) error {
+	data := s.format(msg)
+
+	// retry once to recover from a connection closed by the server
+	err := s.write(data)
+	if err != nil {
+		err = s.write(data)
+	}
+	if err != nil {
+		s.stats.addDropped(1)
+		return err
+	}
+
+	s.stats.addSent(1)
+	return nil
+}
+
+// Flush implements the Sink interface
+func (s *syslogSink) Flush() error {
+	return nil
+}
+
+// Close implements the Sink interface
+func (s *syslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const webhookRetryInterval = time.Second
+
+// webhookSink posts the messages by batches to an HTTP endpoint, as a JSON array. The failed
+// requests are retried with an exponential backoff, except for the client errors.
+type webhookSink struct {
+	url        string
+	headers    map[string]string
+	batchSize  int
+	maxRetries int
+	client     *http.Client
+	batch      [][]byte
+	stats      *stats
+
+	// retryInterval is the delay before the first retry, doubled at each attempt
+	retryInterval time.Duration
+}
+
+func newWebhookSink(cfg Config, stats *stats) (*webhookSink, error) {
+	return &webhookSink{
+		url:           cfg.URL,
+		headers:       cfg.Headers,
+		batchSize:     cfg.BatchSize,
+		maxRetries:    cfg.MaxRetries,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		stats:         stats,
+		retryInterval: webhookRetryInterval,
+	}, nil
+}
+
+// Send implements the Sink interface
+func (s *webhookSink) Send(msg *Message) error {
+	s.batch = append(s.batch, msg.Content)
+	if len(s.batch) >= s.batchSize {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush implements the Sink interface
+func (s *webhookSink) Flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	body.WriteByte('[')
+	for i, content := range s.batch {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(content)
+	}
+	body.WriteByte(']')
+
+	count := len(s.batch)
+	s.batch = s.batch[:0]
+
+	var err error
+	var retry bool
+	interval := s.retryInterval
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(interval)
+			interval *= 2
+		}
+
+		if retry, err = s.post(body.Bytes()); err == nil || !retry {
+			break
+		}
+	}
+
+	if err != nil {
+		s.stats.addDropped(count)
+		return err
+	}
+
+	s.stats.addSent(count)
+	return nil
+}
+
+// post sends a batch, returning whether the request can be retried on error
+func (s *webhookSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("webhook %s returned status %d", s.url, resp.StatusCode)
+}
+
+// Close implements the Sink interface
+func (s *webhookSink) Close() error {
+	return nil
+}
@@ -34,6 +34,12 @@ const (
 	KindConstants = ResourceKind("constants")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
+	// KindPackage is used for a Package resource
+	KindPackage = ResourceKind("package")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
+	// KindKernelModule is used for a KernelModule resource
+	KindKernelModule = ResourceKind("kernel_module")
 )
 
 // ResourceCommon describes the base fields of resource types
@@ -47,6 +53,9 @@ type ResourceCommon struct {
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
 	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
+	Package       *Package            `yaml:"package,omitempty"`
+	Sysctl        *Sysctl             `yaml:"sysctl,omitempty"`
+	KernelModule  *KernelModule       `yaml:"kernel_module,omitempty"`
 }
 
 // Resource describes supported resource types observed by a Rule
@@ -96,6 +105,12 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindConstants
 	case r.Custom != nil:
 		return KindCustom
+	case r.Package != nil:
+		return KindPackage
+	case r.Sysctl != nil:
+		return KindSysctl
+	case r.KernelModule != nil:
+		return KindKernelModule
 	default:
 		return KindInvalid
 	}
@@ -195,6 +210,53 @@ type Group struct {
 	Name string `yaml:"name"`
 }
 
+// Fields & functions available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldVersion   = "package.version"
+	PackageFieldArch      = "package.arch"
+	PackageFieldManager   = "package.manager"
+	PackageFieldInstalled = "package.installed"
+
+	PackageFuncVersionCompare = "package.versionCompare"
+)
+
+// Package describes a package resource, read from the databases of the package managers
+type Package struct {
+	Name string `yaml:"name"`
+}
+
+// Fields & functions available for Sysctl
+const (
+	SysctlFieldKey            = "sysctl.key"
+	SysctlFieldValue          = "sysctl.value"
+	SysctlFieldPersisted      = "sysctl.persisted"
+	SysctlFieldPersistedValue = "sysctl.persistedValue"
+	SysctlFieldPersistedFile  = "sysctl.persistedFile"
+)
+
+// Sysctl describes a kernel parameter resource, read from /proc/sys and from the sysctl.d
+// configuration. The key uses the dotted notation and may contain glob patterns.
+type Sysctl struct {
+	Key string `yaml:"key"`
+}
+
+// Fields & functions available for KernelModule
+const (
+	KernelModuleFieldName        = "kernel_module.name"
+	KernelModuleFieldLoaded      = "kernel_module.loaded"
+	KernelModuleFieldBlacklisted = "kernel_module.blacklisted"
+	KernelModuleFieldInstall     = "kernel_module.install"
+	KernelModuleFieldDisabled    = "kernel_module.disabled"
+	KernelModuleFieldOptions     = "kernel_module.options"
+)
+
+// KernelModule describes a kernel module resource, read from /proc/modules and from the
+// modprobe.d configuration
+type KernelModule struct {
+	Name string `yaml:"name"`
+}
+
 // BinaryCmd describes a command in form of a name + args
 type BinaryCmd struct {
 	Name string   `yaml:"name"`
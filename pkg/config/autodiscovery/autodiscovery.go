@@ -96,6 +96,11 @@ func DiscoverComponentsFromEnv() ([]config.ConfigurationProviders, []config.List
 		log.Info("Adding Container provider and ECS listener from environment")
 	}
 
+	if config.IsFeaturePresent(config.Nomad) {
+		detectedProviders = append(detectedProviders, config.ConfigurationProviders{Name: names.NomadRegisterName, Polling: true, PollInterval: "1s"})
+		log.Info("Adding Nomad provider from environment")
+	}
+
 	if config.IsFeaturePresent(config.Kubernetes) {
 		detectedProviders = append(detectedProviders, config.ConfigurationProviders{Name: "kubelet", Polling: true})
 		detectedListeners = append(detectedListeners, config.Listeners{Name: "kubelet"})
@@ -138,6 +138,11 @@ type ConfigurationProviders struct {
 	KeyFile          string `mapstructure:"key_file"`
 	Token            string `mapstructure:"token"`
 	GraceTimeSeconds int    `mapstructure:"grace_time_seconds"`
+
+	// Used by the http config provider
+	Headers            map[string]string `mapstructure:"headers"`
+	InsecureSkipVerify bool              `mapstructure:"insecure_skip_verify"`
+	LongPollTimeout    string            `mapstructure:"long_poll_timeout"`
 }
 
 // Listeners helps unmarshalling `listeners` config param
@@ -425,6 +430,21 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_chan_size", 200)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size", 4000)
 
+	// OpenMetrics exposition of the aggregated metrics
+	config.BindEnvAndSetDefault("openmetrics_exposition.enabled", false)
+	config.BindEnvAndSetDefault("openmetrics_exposition.bind_host", "localhost")
+	config.BindEnvAndSetDefault("openmetrics_exposition.port", 5009)
+	config.BindEnvAndSetDefault("openmetrics_exposition.namespace", "")
+	config.BindEnvAndSetDefault("openmetrics_exposition.sanitize_labels", true)
+	config.BindEnvAndSetDefault("openmetrics_exposition.staleness_timeout", 5*time.Minute)
+	config.BindEnvAndSetDefault("openmetrics_exposition.quantiles", []string{"0.5", "0.9", "0.95", "0.99"})
+
+	// Prometheus remote-write output of the aggregated metrics
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnv("prometheus_remote_write.endpoints")
+	config.BindEnvAndSetDefault("prometheus_remote_write.max_series_per_payload", 2000)
+	config.BindEnvAndSetDefault("prometheus_remote_write.quantiles", []string{"0.5", "0.9", "0.95", "0.99"})
+
 	// Serializer
 	config.BindEnvAndSetDefault("enable_stream_payload_serialization", true)
 	config.BindEnvAndSetDefault("enable_service_checks_stream_payload_serialization", true)
@@ -468,6 +488,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0)                // 0 means disabled. This is a BETA feature.
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80)                // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 	config.BindEnvAndSetDefault("forwarder_retry_queue_capacity_time_interval_sec", 900) // 15 mins
+	// The files of the retry queue are encrypted with AES-GCM when a key is set.
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key", "")
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key_file", "")
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_previous_keys", []string{})
 
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
@@ -496,6 +520,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
 	config.BindEnvAndSetDefault("dogstatsd_pipeline_autoadjust", false)
 	config.BindEnvAndSetDefault("dogstatsd_pipeline_count", 1)
+	// Split the contexts of every DogStatsD time sampler in this number of shards, each shard
+	// being flushed at a different time of the flush interval instead of every context at once.
+	config.BindEnvAndSetDefault("dogstatsd_flush_shards", 1)
 	config.BindEnvAndSetDefault("dogstatsd_stats_port", 5000)
 	config.BindEnvAndSetDefault("dogstatsd_stats_enable", false)
 	config.BindEnvAndSetDefault("dogstatsd_stats_buffer", 10)
@@ -556,12 +583,18 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("container_exclude_stopped_age", DefaultAuditorTTL-1) // in hours
 	config.BindEnvAndSetDefault("ad_config_poll_interval", int64(10))                 // in seconds
 	config.BindEnvAndSetDefault("extra_listeners", []string{})
+	config.SetKnown("process_listener.discovery_interval")
+	config.SetKnown("process_listener.patterns")
 	config.BindEnvAndSetDefault("extra_config_providers", []string{})
 	config.BindEnvAndSetDefault("ignore_autoconf", []string{})
 	config.BindEnvAndSetDefault("autoconfig_from_environment", true)
 	config.BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
 	config.BindEnvAndSetDefault("autoconfig_include_features", []string{})
 
+	// Workloadmeta
+	config.BindEnvAndSetDefault("workloadmeta.process_collector.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.process_collector.interval", 10*time.Second)
+
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
 	config.BindEnvAndSetDefault("docker_labels_as_tags", map[string]string{})
@@ -634,6 +667,15 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("snmp_traps_config.bind_host", "localhost")
 	config.BindEnvAndSetDefault("snmp_traps_config.stop_timeout", 5) // in seconds
 	config.SetKnown("snmp_traps_config.users")
+	config.SetKnown("snmp_traps_config.rules")
+
+	// NetFlow
+	config.BindEnvAndSetDefault("network_devices.netflow.enabled", false)
+	config.SetKnown("network_devices.netflow.listeners")
+	config.BindEnvAndSetDefault("network_devices.netflow.stop_timeout", 5)                // in seconds
+	config.BindEnvAndSetDefault("network_devices.netflow.aggregator_buffer_size", 10000)  // number of flows
+	config.BindEnvAndSetDefault("network_devices.netflow.aggregator_flush_interval", 300) // in seconds
+	config.BindEnvAndSetDefault("network_devices.netflow.aggregator_max_flows", 100000)   // per flush interval
 
 	// Kube ApiServer
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
@@ -717,6 +759,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cloud_foundry_garden.listen_network", "unix")
 	config.BindEnvAndSetDefault("cloud_foundry_garden.listen_address", "/var/vcap/data/garden/garden.sock")
 
+	// Nomad
+	config.BindEnvAndSetDefault("nomad_agent_url", "")
+	config.BindEnvAndSetDefault("nomad_token", "")
+
 	// Azure
 	config.BindEnvAndSetDefault("azure_hostname_style", "os")
 
@@ -808,6 +854,7 @@ func InitConfig(config Config) {
 	bindEnvAndSetLogsConfigKeys(config, "database_monitoring.activity.")
 	bindEnvAndSetLogsConfigKeys(config, "database_monitoring.metrics.")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.metadata.")
+	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 	config.BindEnvAndSetDefault("network_devices.namespace", "default")
 
 	config.BindEnvAndSetDefault("logs_config.dd_port", 10516)
@@ -844,6 +891,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("checks_tag_cardinality", "low")
 	config.BindEnvAndSetDefault("dogstatsd_tag_cardinality", "low")
 
+	// Rules deriving, mapping and dropping tags when the tagger computes the
+	// tags of an entity.
+	config.SetKnown("tagger_rules")
+
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
 
@@ -977,6 +1028,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("compliance_config.run_path", defaultRunPath)
 	config.BindEnv("compliance_config.run_commands_as")
 	bindEnvAndSetLogsConfigKeys(config, "compliance_config.endpoints.")
+	config.SetKnown("compliance_config.sinks")
 
 	// Datadog security agent (runtime)
 	config.BindEnvAndSetDefault("runtime_security_config.enabled", false)
@@ -985,6 +1037,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.map_dentry_resolution_enabled", true)
 	config.BindEnvAndSetDefault("runtime_security_config.dentry_cache_size", 1024)
 	config.BindEnvAndSetDefault("runtime_security_config.policies.dir", DefaultRuntimePoliciesDir)
+	config.BindEnvAndSetDefault("runtime_security_config.policies.max_window_states", 10000)
 	config.BindEnvAndSetDefault("runtime_security_config.socket", "/opt/datadog-agent/run/runtime-security.sock")
 	config.BindEnvAndSetDefault("runtime_security_config.enable_approvers", true)
 	config.BindEnvAndSetDefault("runtime_security_config.enable_kernel_filters", true)
@@ -1005,6 +1058,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.agent_monitoring_events", true)
 	config.BindEnvAndSetDefault("runtime_security_config.custom_sensitive_words", []string{})
 	config.BindEnvAndSetDefault("runtime_security_config.remote_tagger", true)
+	config.SetKnown("runtime_security_config.sinks")
 	config.BindEnvAndSetDefault("runtime_security_config.log_patterns", []string{})
 	bindEnvAndSetLogsConfigKeys(config, "runtime_security_config.endpoints.")
 	config.BindEnvAndSetDefault("runtime_security_config.self_test.enabled", true)
@@ -1018,6 +1072,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.cgroup_dump_timeout", 30)
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.cgroup_wait_list_size", 10)
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.cgroup_output_directory", "")
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.dir", filepath.Join(defaultRunPath, "runtime-security", "profiles"))
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.learning_period", 30)
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.event_types", []string{"exec", "open", "dns"})
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.cache_size", 100)
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.max_process_nodes", 1000)
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.max_files", 10000)
+	config.BindEnvAndSetDefault("runtime_security_config.security_profile.max_dns_names", 1000)
 	config.BindEnvAndSetDefault("runtime_security_config.network.enabled", false)
 	config.BindEnvAndSetDefault("runtime_security_config.network.lazy_interface_prefixes", []string{})
 
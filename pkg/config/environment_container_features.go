@@ -25,4 +25,6 @@ const (
 	CloudFoundry Feature = "cloudfoundry"
 	// Podman containers storage path accessible
 	Podman Feature = "podman"
+	// Nomad client agent configured, or agent running in a Nomad allocation
+	Nomad Feature = "nomad"
 )
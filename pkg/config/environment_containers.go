@@ -43,6 +43,7 @@ func init() {
 	registerFeature(KubeOrchestratorExplorer)
 	registerFeature(CloudFoundry)
 	registerFeature(Podman)
+	registerFeature(Nomad)
 }
 
 // IsAnyContainerFeaturePresent checks if any of known container features is present
@@ -64,6 +65,7 @@ func detectContainerFeatures(features FeatureMap) {
 	detectFargate(features)
 	detectCloudFoundry(features)
 	detectPodman(features)
+	detectNomad(features)
 }
 
 func detectKubernetes(features FeatureMap) {
@@ -169,6 +171,13 @@ func detectPodman(features FeatureMap) {
 	}
 }
 
+func detectNomad(features FeatureMap) {
+	// NOMAD_ALLOC_ID is set in the environment of the tasks of all the Nomad drivers
+	if Datadog.GetString("nomad_agent_url") != "" || os.Getenv("NOMAD_ALLOC_ID") != "" {
+		features[Nomad] = struct{}{}
+	}
+}
+
 func getHostMountPrefixes() []string {
 	if IsContainerized() {
 		return []string{"", defaultHostMountPrefix}
@@ -135,6 +135,10 @@ func InitSystemProbeConfig(cfg Config) {
 	// network_config namespace only
 	cfg.BindEnv(join(netNS, "enable_http_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP_MONITORING")
 	cfg.BindEnv(join(netNS, "enable_https_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTPS_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_l7_monitoring"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_L7_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "kafka_ports"), []string{"9092"}, "DD_SYSTEM_PROBE_NETWORK_KAFKA_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "postgres_ports"), []string{"5432"}, "DD_SYSTEM_PROBE_NETWORK_POSTGRES_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "redis_ports"), []string{"6379"}, "DD_SYSTEM_PROBE_NETWORK_REDIS_PORTS")
 	cfg.BindEnvAndSetDefault(join(netNS, "enable_gateway_lookup"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_GATEWAY_LOOKUP")
 	httpRules := join(netNS, "http_replace_rules")
 	cfg.BindEnv(httpRules, "DD_SYSTEM_PROBE_NETWORK_HTTP_REPLACE_RULES")
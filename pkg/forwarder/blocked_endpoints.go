@@ -92,6 +92,18 @@ func (e *blockedEndpoints) recover(endpoint string) {
 	e.errorPerEndpoint[endpoint] = b
 }
 
+// unblockAll lets the transactions of every endpoint be sent again right away
+// without resetting their error count: an endpoint still failing is blocked
+// again with the same backoff duration.
+func (e *blockedEndpoints) unblockAll() {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	for _, b := range e.errorPerEndpoint {
+		b.until = time.Time{}
+	}
+}
+
 func (e *blockedEndpoints) isBlock(endpoint string) bool {
 	e.m.RLock()
 	defer e.m.RUnlock()
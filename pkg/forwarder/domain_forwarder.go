@@ -112,6 +112,58 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	}
 }
 
+// replayTransactions unblocks the endpoints and sends the transactions of the
+// retry queue, from the memory first and then from the disk, to the workers
+// until their queue is full. The remaining transactions stay in the retry queue.
+func (f *domainForwarder) replayTransactions() (int, error) {
+	// Lock so we can't stop the domainForwarder while sending transactions to the workers
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Stopped {
+		return 0, fmt.Errorf("the forwarder for %s is not started", f.domain)
+	}
+	if !atomic.CompareAndSwapInt32(&f.isRetrying, 0, 1) {
+		return 0, fmt.Errorf("the forwarder for %s is already retrying transactions", f.domain)
+	}
+	defer atomic.StoreInt32(&f.isRetrying, 0)
+
+	f.blockedList.unblockAll()
+
+	replayed := 0
+	for {
+		transactions, err := f.retryQueue.ExtractTransactions()
+		if err != nil {
+			return replayed, err
+		}
+		if len(transactions) == 0 {
+			// a file may contain no valid transaction, stop only once the disk is empty
+			if files, err := f.retryQueue.GetOnDiskFiles(); err == nil && len(files) > 0 {
+				continue
+			}
+			return replayed, nil
+		}
+
+		f.transactionPrioritySorter.Sort(transactions)
+		for i, t := range transactions {
+			select {
+			case f.lowPrio <- t:
+				replayed++
+				transactionEndpointName := t.GetEndpointName()
+				transactionsRetriedByEndpoint.Add(transactionEndpointName, 1)
+				transactionsRetried.Add(1)
+				tlmTxRetried.Inc(f.domain, transactionEndpointName)
+			default:
+				for _, t := range transactions[i:] {
+					f.addToTransactionRetryQueue(t)
+				}
+				log.Infof("Replayed %d transactions for %s, the remaining ones are kept in the retry queue as the workers are busy", replayed, f.domain)
+				return replayed, nil
+			}
+		}
+	}
+}
+
 func (f *domainForwarder) addToTransactionRetryQueue(t transaction.Transaction) int {
 	dropCount, err := f.retryQueue.Add(t)
 	if err != nil {
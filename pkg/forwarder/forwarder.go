@@ -7,6 +7,7 @@ package forwarder
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"path"
 	"strconv"
@@ -227,15 +228,16 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
 	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
 	var diskUsageLimit *retry.DiskUsageLimit
+	optionalEncryption, encryptionErr := retryQueueEncryption()
 
 	// Disk Persistence is a core-only feature for now.
 	if storageMaxSize == 0 {
 		log.Infof("Retry queue storage on disk is disabled")
+	} else if encryptionErr != nil {
+		// Never store the transactions in plaintext when the encryption is configured
+		log.Errorf("Retry queue storage on disk is disabled because the encryption cannot be set up: %v", encryptionErr)
 	} else if agentName != "" {
-		storagePath := config.Datadog.GetString("forwarder_storage_path")
-		if storagePath == "" {
-			storagePath = path.Join(config.Datadog.GetString("run_path"), "transactions_to_retry")
-		}
+		storagePath := retryQueueStoragePath()
 		outdatedFileInDays := config.Datadog.GetInt("forwarder_outdated_file_in_days")
 		var err error
 
@@ -283,6 +285,7 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 				flushToDiskMemRatio,
 				domainFolderPath,
 				diskUsageLimit,
+				optionalEncryption,
 				transactionContainerSort,
 				resolver)
 			f.domainResolvers[domain] = resolver
@@ -320,6 +323,47 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	return f
 }
 
+// retryQueueStoragePath returns the root folder of the on-disk retry queues.
+func retryQueueStoragePath() string {
+	storagePath := config.Datadog.GetString("forwarder_storage_path")
+	if storagePath == "" {
+		storagePath = path.Join(config.Datadog.GetString("run_path"), "transactions_to_retry")
+	}
+	return storagePath
+}
+
+// retryQueueEncryption returns the encryption of the files of the retry
+// queue, nil when no encryption key is configured. The first key encrypts the
+// new files, the other ones only decrypt the files written before a key rotation.
+func retryQueueEncryption() (*retry.FileEncryption, error) {
+	var keys [][]byte
+	if key := config.Datadog.GetString("forwarder_storage_encryption_key"); key != "" {
+		keys = append(keys, []byte(key))
+	}
+
+	if keyFile := config.Datadog.GetString("forwarder_storage_encryption_key_file"); keyFile != "" {
+		content, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the encryption key file: %v", err)
+		}
+		// one key per line, the comments and the empty lines are ignored
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, []byte(line))
+			}
+		}
+	}
+
+	for _, key := range config.Datadog.GetStringSlice("forwarder_storage_encryption_previous_keys") {
+		keys = append(keys, []byte(key))
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return retry.NewFileEncryption(keys)
+}
+
 func getAgentName(options *Options) string {
 	if HasFeature(options.EnabledFeatures, CoreFeatures) {
 		return "core"
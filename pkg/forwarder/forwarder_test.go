@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -707,3 +709,39 @@ func TestCustomCompletionHandler(t *testing.T) {
 
 	assert.True(t, handlerCalled)
 }
+
+func TestRetryQueueEncryption(t *testing.T) {
+	defer func() {
+		config.Datadog.Set("forwarder_storage_encryption_key", "")
+		config.Datadog.Set("forwarder_storage_encryption_key_file", "")
+	}()
+
+	encryption, err := retryQueueEncryption()
+	require.NoError(t, err)
+	assert.Nil(t, encryption)
+
+	keyFile := path.Join(t.TempDir(), "forwarder_storage.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("# current key\n0123456789abcdef-new\n\n0123456789abcdef-old\n"), 0600))
+	config.Datadog.Set("forwarder_storage_encryption_key_file", keyFile)
+
+	encryption, err = retryQueueEncryption()
+	require.NoError(t, err)
+	require.NotNil(t, encryption)
+
+	// the files written with the previous key can still be read
+	previous, err := retry.NewFileEncryption([][]byte{[]byte("0123456789abcdef-old")})
+	require.NoError(t, err)
+	encrypted, err := previous.Encrypt([]byte("transactions"))
+	require.NoError(t, err)
+	decrypted, err := encryption.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "transactions", string(decrypted))
+
+	config.Datadog.Set("forwarder_storage_encryption_key", "short")
+	_, err = retryQueueEncryption()
+	assert.Error(t, err)
+
+	config.Datadog.Set("forwarder_storage_encryption_key_file", path.Join(t.TempDir(), "missing"))
+	_, err = retryQueueEncryption()
+	assert.Error(t, err)
+}
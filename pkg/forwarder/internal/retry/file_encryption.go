@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// encryptedFileMagic starts every encrypted retry file.
+	encryptedFileMagic          = "DDRQ"
+	encryptedFileFormatVersion  = 1
+	encryptionKeyIDSize         = 8
+	minEncryptionKeyMaterialLen = 16
+	encryptionKeyInfo           = "datadog-agent forwarder retry queue v1"
+)
+
+// encryptedFileHeaderSize is the size of the magic, the version and the key ID.
+var encryptedFileHeaderSize = len(encryptedFileMagic) + 1 + encryptionKeyIDSize
+
+type encryptionKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// FileEncryption encrypts the files of the on-disk retry queue with AES-256-GCM.
+//
+// An encrypted file is made of the magic `DDRQ`, the format version, the ID
+// of the key, the nonce and the sealed transactions. The header is
+// authenticated with the transactions. The ID of the key lets the previous
+// keys decrypt the files written before a key rotation.
+type FileEncryption struct {
+	current *encryptionKey
+	keys    map[string]*encryptionKey
+}
+
+// NewFileEncryption creates a new instance of FileEncryption. The first key
+// encrypts the new files, every key can decrypt the existing files. The AES
+// keys are derived from the key materials with HKDF-SHA256.
+func NewFileEncryption(keyMaterials [][]byte) (*FileEncryption, error) {
+	if len(keyMaterials) == 0 {
+		return nil, errors.New("no encryption key")
+	}
+
+	e := &FileEncryption{keys: make(map[string]*encryptionKey, len(keyMaterials))}
+	for i, material := range keyMaterials {
+		if len(material) < minEncryptionKeyMaterialLen {
+			return nil, fmt.Errorf("the encryption key #%d is too short: %d bytes, at least %d bytes are required", i, len(material), minEncryptionKeyMaterialLen)
+		}
+
+		key, err := newEncryptionKey(material)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			e.current = key
+		}
+		e.keys[string(key.id)] = key
+	}
+	return e, nil
+}
+
+func newEncryptionKey(material []byte) (*encryptionKey, error) {
+	aesKey := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, material, nil, []byte(encryptionKeyInfo)), aesKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is a hash of the AES key, it does not reveal the key.
+	id := sha256.Sum256(append([]byte(encryptionKeyInfo+" key id"), aesKey...))
+	return &encryptionKey{id: id[:encryptionKeyIDSize], aead: aead}, nil
+}
+
+// Encrypt encrypts the content of a retry file with the current key.
+func (e *FileEncryption) Encrypt(plaintext []byte) ([]byte, error) {
+	nonceSize := e.current.aead.NonceSize()
+	out := make([]byte, 0, encryptedFileHeaderSize+nonceSize+len(plaintext)+e.current.aead.Overhead())
+	out = append(out, encryptedFileMagic...)
+	out = append(out, encryptedFileFormatVersion)
+	out = append(out, e.current.id...)
+	header := out
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return e.current.aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt decrypts the content of a retry file with the key used to encrypt it.
+func (e *FileEncryption) Decrypt(data []byte) ([]byte, error) {
+	if !isEncryptedFile(data) {
+		return nil, errors.New("the retry file is not encrypted")
+	}
+	if len(data) < encryptedFileHeaderSize {
+		return nil, errors.New("the encrypted retry file is truncated")
+	}
+	if version := data[len(encryptedFileMagic)]; version != encryptedFileFormatVersion {
+		return nil, fmt.Errorf("unsupported encrypted retry file version %d", version)
+	}
+
+	header := data[:encryptedFileHeaderSize]
+	key, found := e.keys[string(header[len(encryptedFileMagic)+1:])]
+	if !found {
+		return nil, errors.New("the retry file was encrypted with an unknown key")
+	}
+
+	nonceSize := key.aead.NonceSize()
+	if len(data) < encryptedFileHeaderSize+nonceSize {
+		return nil, errors.New("the encrypted retry file is truncated")
+	}
+	nonce := data[encryptedFileHeaderSize : encryptedFileHeaderSize+nonceSize]
+	return key.aead.Open(nil, nonce, data[encryptedFileHeaderSize+nonceSize:], header)
+}
+
+func isEncryptedFile(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedFileMagic))
+}
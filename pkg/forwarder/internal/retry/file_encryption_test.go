@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	encryptionKey1 = "0123456789abcdef-key1"
+	encryptionKey2 = "0123456789abcdef-key2"
+)
+
+func TestFileEncryption(t *testing.T) {
+	e, err := NewFileEncryption([][]byte{[]byte(encryptionKey1)})
+	require.NoError(t, err)
+
+	plaintext := []byte("transactions")
+	encrypted, err := e.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.True(t, isEncryptedFile(encrypted))
+	assert.NotContains(t, string(encrypted), "transactions")
+
+	// a new nonce is used for every file
+	encryptedTwice, err := e.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encryptedTwice)
+
+	decrypted, err := e.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// the header and the content are authenticated
+	for _, i := range []int{len(encryptedFileMagic) + 1, len(encrypted) - 1} {
+		tampered := append([]byte{}, encrypted...)
+		tampered[i] ^= 0xff
+		_, err = e.Decrypt(tampered)
+		assert.Error(t, err)
+	}
+
+	_, err = e.Decrypt(plaintext)
+	assert.Error(t, err)
+	_, err = e.Decrypt(encrypted[:encryptedFileHeaderSize+2])
+	assert.Error(t, err)
+}
+
+func TestFileEncryptionKeyRotation(t *testing.T) {
+	before, err := NewFileEncryption([][]byte{[]byte(encryptionKey1)})
+	require.NoError(t, err)
+	encryptedBefore, err := before.Encrypt([]byte("before"))
+	require.NoError(t, err)
+
+	after, err := NewFileEncryption([][]byte{[]byte(encryptionKey2), []byte(encryptionKey1)})
+	require.NoError(t, err)
+	encryptedAfter, err := after.Encrypt([]byte("after"))
+	require.NoError(t, err)
+
+	decrypted, err := after.Decrypt(encryptedBefore)
+	require.NoError(t, err)
+	assert.Equal(t, "before", string(decrypted))
+
+	decrypted, err = after.Decrypt(encryptedAfter)
+	require.NoError(t, err)
+	assert.Equal(t, "after", string(decrypted))
+
+	// the files encrypted with the new key cannot be read with the previous key only
+	_, err = before.Decrypt(encryptedAfter)
+	assert.Error(t, err)
+}
+
+func TestNewFileEncryptionErrors(t *testing.T) {
+	_, err := NewFileEncryption(nil)
+	assert.Error(t, err)
+
+	_, err = NewFileEncryption([][]byte{[]byte(encryptionKey1), []byte("short")})
+	assert.Error(t, err)
+}
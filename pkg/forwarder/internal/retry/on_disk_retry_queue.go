@@ -22,8 +22,16 @@ import (
 const retryTransactionsExtension = ".retry"
 const retryFileFormat = "2006_01_02__15_04_05_"
 
+// RetryFile describes a file of the on-disk retry queue.
+type RetryFile struct {
+	Path        string
+	SizeInBytes int64
+	ModTime     time.Time
+}
+
 type onDiskRetryQueue struct {
 	serializer         *HTTPTransactionsSerializer
+	encryption         *FileEncryption
 	storagePath        string
 	diskUsageLimit     *DiskUsageLimit
 	filenames          []string
@@ -33,6 +41,7 @@ type onDiskRetryQueue struct {
 
 func newOnDiskRetryQueue(
 	serializer *HTTPTransactionsSerializer,
+	optionalEncryption *FileEncryption,
 	storagePath string,
 	diskUsageLimit *DiskUsageLimit,
 	telemetry onDiskRetryQueueTelemetry) (*onDiskRetryQueue, error) {
@@ -43,6 +52,7 @@ func newOnDiskRetryQueue(
 
 	storage := &onDiskRetryQueue{
 		serializer:     serializer,
+		encryption:     optionalEncryption,
 		storagePath:    storagePath,
 		diskUsageLimit: diskUsageLimit,
 		telemetry:      telemetry,
@@ -77,6 +87,11 @@ func (s *onDiskRetryQueue) Serialize(transactions []transaction.Transaction) err
 	if err != nil {
 		return err
 	}
+	if s.encryption != nil {
+		if bytes, err = s.encryption.Encrypt(bytes); err != nil {
+			return err
+		}
+	}
 	bufferSize := int64(len(bytes))
 
 	if err := s.makeRoomFor(bufferSize); err != nil {
@@ -122,7 +137,7 @@ func (s *onDiskRetryQueue) Deserialize() ([]transaction.Transaction, error) {
 		return nil, err
 	}
 
-	transactions, errorsCount, err := s.serializer.Deserialize(bytes)
+	transactions, errorsCount, err := s.deserialize(bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +148,82 @@ func (s *onDiskRetryQueue) Deserialize() ([]transaction.Transaction, error) {
 	return transactions, err
 }
 
+// ListFiles returns the files of the queue, from the oldest to the newest.
+func (s *onDiskRetryQueue) ListFiles() ([]RetryFile, error) {
+	files := make([]RetryFile, 0, len(s.filenames))
+	for _, filename := range s.filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, RetryFile{Path: filename, SizeInBytes: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// ReadFile deserializes the transactions of a file without removing it from the queue.
+func (s *onDiskRetryQueue) ReadFile(path string) ([]transaction.Transaction, error) {
+	if s.indexOf(path) < 0 {
+		return nil, fmt.Errorf("unknown retry file %s", path)
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	transactions, _, err := s.deserialize(bytes)
+	return transactions, err
+}
+
+// deserialize decrypts the content of a file when the encryption is enabled
+// and deserializes its transactions.
+// The plaintext files are rejected when the encryption is enabled: they can be
+// written by anyone having access to the disk.
+func (s *onDiskRetryQueue) deserialize(bytes []byte) ([]transaction.Transaction, int, error) {
+	if s.encryption != nil {
+		var err error
+		if bytes, err = s.encryption.Decrypt(bytes); err != nil {
+			return nil, 0, fmt.Errorf("cannot decrypt the retry file: %v", err)
+		}
+	} else if isEncryptedFile(bytes) {
+		return nil, 0, fmt.Errorf("the retry file is encrypted but no encryption key is configured")
+	}
+	return s.serializer.Deserialize(bytes)
+}
+
+// RemoveFilesOlderThan removes the files last modified before `olderThan` and
+// returns the number of files removed. Every file is removed when `olderThan` is zero.
+// A file is written once all its transactions are created so its transactions
+// are all older than its modification time.
+func (s *onDiskRetryQueue) RemoveFilesOlderThan(olderThan time.Time) (int, error) {
+	files, err := s.ListFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, f := range files {
+		if !olderThan.IsZero() && !f.ModTime.Before(olderThan) {
+			continue
+		}
+		if err := s.removeFileAt(s.indexOf(f.Path)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	s.telemetry.setCurrentSizeInBytes(s.GetDiskSpaceUsed())
+	s.telemetry.setFilesCount(s.getFilesCount())
+	return removed, nil
+}
+
+func (s *onDiskRetryQueue) indexOf(path string) int {
+	for i, filename := range s.filenames {
+		if filename == path {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetFileCount returns the current files count.
 func (s *onDiskRetryQueue) getFilesCount() int {
 	return len(s.filenames)
@@ -10,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
@@ -91,6 +92,77 @@ func TestOnDiskRetryQueueReloadExistingRetryFiles(t *testing.T) {
 	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
 }
 
+func TestOnDiskRetryQueueListReadAndRemoveFiles(t *testing.T) {
+	a := assert.New(t)
+	path, clean := createTmpFolder(a)
+	defer clean()
+
+	q := newTestOnDiskRetryQueue(a, path, 1000)
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("endpoint3")))
+
+	files, err := q.ListFiles()
+	a.NoError(err)
+	a.Len(files, 2)
+	a.Equal(q.GetDiskSpaceUsed(), files[0].SizeInBytes+files[1].SizeInBytes)
+
+	// reading a file does not remove it
+	transactions, err := q.ReadFile(files[0].Path)
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
+	a.Equal(2, q.getFilesCount())
+
+	_, err = q.ReadFile(path + "/unknown.retry")
+	a.Error(err)
+
+	removed, err := q.RemoveFilesOlderThan(time.Now().Add(-time.Hour))
+	a.NoError(err)
+	a.Equal(0, removed)
+	a.Equal(2, q.getFilesCount())
+
+	removed, err = q.RemoveFilesOlderThan(time.Time{})
+	a.NoError(err)
+	a.Equal(2, removed)
+	a.Equal(0, q.getFilesCount())
+	a.Equal(int64(0), q.GetDiskSpaceUsed())
+}
+
+func TestOnDiskRetryQueueEncryption(t *testing.T) {
+	a := assert.New(t)
+	path, clean := createTmpFolder(a)
+	defer clean()
+
+	encryption, err := NewFileEncryption([][]byte{[]byte(encryptionKey1)})
+	a.NoError(err)
+
+	plaintextQueue := newTestOnDiskRetryQueue(a, path, 1000)
+	a.NoError(plaintextQueue.Serialize(createHTTPTransactionCollectionTests("plaintext_endpoint")))
+
+	q := newTestOnDiskRetryQueue(a, path, 1000)
+	q.encryption = encryption
+	a.NoError(q.Serialize(createHTTPTransactionCollectionTests("encrypted_endpoint")))
+
+	files, err := q.ListFiles()
+	a.NoError(err)
+	a.Len(files, 2)
+	content, err := ioutil.ReadFile(files[1].Path)
+	a.NoError(err)
+	a.NotContains(string(content), "encrypted_endpoint")
+
+	transactions, err := q.ReadFile(files[1].Path)
+	a.NoError(err)
+	a.Equal([]string{"encrypted_endpoint"}, getEndpointsFromTransactions(transactions))
+
+	// plaintext files are rejected when the encryption is enabled
+	_, err = q.ReadFile(files[0].Path)
+	a.Error(err)
+
+	// encrypted files are rejected when the encryption is disabled
+	q.encryption = nil
+	_, err = q.ReadFile(files[1].Path)
+	a.Error(err)
+}
+
 func createHTTPTransactionCollectionTests(endpoints ...string) []transaction.Transaction {
 	var transactions []transaction.Transaction
 
@@ -126,7 +198,7 @@ func newTestOnDiskRetryQueue(a *assert.Assertions, path string, maxSizeInBytes i
 			Total:     10000,
 		}}
 	diskUsageLimit := NewDiskUsageLimit("", disk, maxSizeInBytes, 1)
-	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry)
+	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domainName, nil)), nil, path, diskUsageLimit, telemetry)
 	a.NoError(err)
 	return storage
 }
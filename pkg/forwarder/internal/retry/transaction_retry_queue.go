@@ -8,6 +8,7 @@ package retry
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
@@ -20,6 +21,9 @@ type DiskTransactionSerializer interface {
 	Serialize([]transaction.Transaction) error
 	Deserialize() ([]transaction.Transaction, error)
 	GetDiskSpaceUsed() int64
+	ListFiles() ([]RetryFile, error)
+	ReadFile(path string) ([]transaction.Transaction, error)
+	RemoveFilesOlderThan(olderThan time.Time) (int, error)
 }
 
 // TransactionPrioritySorter is an interface to sort transactions.
@@ -46,6 +50,7 @@ func BuildTransactionRetryQueue(
 	flushToStorageRatio float64,
 	optionalDomainFolderPath string,
 	optionalDiskUsageLimit *DiskUsageLimit,
+	optionalEncryption *FileEncryption,
 	dropPrioritySorter TransactionPrioritySorter,
 	resolver resolver.DomainResolver) *TransactionRetryQueue {
 	var storage DiskTransactionSerializer
@@ -53,7 +58,7 @@ func BuildTransactionRetryQueue(
 
 	if optionalDomainFolderPath != "" && optionalDiskUsageLimit != nil {
 		serializer := NewHTTPTransactionsSerializer(resolver)
-		storage, err = newOnDiskRetryQueue(serializer, optionalDomainFolderPath, optionalDiskUsageLimit, newOnDiskRetryQueueTelemetry(resolver.GetBaseDomain()))
+		storage, err = newOnDiskRetryQueue(serializer, optionalEncryption, optionalDomainFolderPath, optionalDiskUsageLimit, newOnDiskRetryQueueTelemetry(resolver.GetBaseDomain()))
 
 		// If the storage on disk cannot be used, log the error and continue.
 		// Returning `nil, err` would mean not using `TransactionRetryQueue` and so not using `forwarder_retry_queue_payloads_max_size` config.
@@ -192,6 +197,70 @@ func (tc *TransactionRetryQueue) GetDiskSpaceUsed() int64 {
 	return 0
 }
 
+// GetInMemoryTransactions returns a copy of the transactions stored in memory.
+func (tc *TransactionRetryQueue) GetInMemoryTransactions() []transaction.Transaction {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	transactions := make([]transaction.Transaction, len(tc.transactions))
+	copy(transactions, tc.transactions)
+	return transactions
+}
+
+// GetOnDiskFiles returns the files of the on-disk storage, from the oldest to the newest.
+// No file is returned when the storage on disk is disabled.
+func (tc *TransactionRetryQueue) GetOnDiskFiles() ([]RetryFile, error) {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	if tc.optionalSerializer == nil {
+		return nil, nil
+	}
+	return tc.optionalSerializer.ListFiles()
+}
+
+// ReadOnDiskFile returns the transactions stored in a file of the on-disk
+// storage without removing them.
+func (tc *TransactionRetryQueue) ReadOnDiskFile(path string) ([]transaction.Transaction, error) {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+
+	if tc.optionalSerializer == nil {
+		return nil, fmt.Errorf("the storage on disk is disabled")
+	}
+	return tc.optionalSerializer.ReadFile(path)
+}
+
+// Purge removes the transactions created before `olderThan` from the memory and
+// the files last modified before `olderThan` from the disk. Everything is
+// removed when `olderThan` is zero.
+// It returns the number of transactions and the number of files removed.
+func (tc *TransactionRetryQueue) Purge(olderThan time.Time) (int, int, error) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	var kept []transaction.Transaction
+	keptSizeInBytes := 0
+	for _, t := range tc.transactions {
+		if olderThan.IsZero() || t.GetCreatedAt().Before(olderThan) {
+			continue
+		}
+		kept = append(kept, t)
+		keptSizeInBytes += t.GetPayloadSize()
+	}
+	transactionsRemoved := len(tc.transactions) - len(kept)
+	tc.transactions = kept
+	tc.currentMemSizeInBytes = keptSizeInBytes
+	tc.telemetry.setCurrentMemSizeInBytes(tc.currentMemSizeInBytes)
+	tc.telemetry.setTransactionsCount(len(tc.transactions))
+
+	if tc.optionalSerializer == nil {
+		return transactionsRemoved, 0, nil
+	}
+	filesRemoved, err := tc.optionalSerializer.RemoveFilesOlderThan(olderThan)
+	return transactionsRemoved, filesRemoved, err
+}
+
 func (tc *TransactionRetryQueue) extractTransactionsForDisk(payloadSize int) [][]transaction.Transaction {
 	sizeInBytesToFlush := int(float64(tc.maxMemSizeInBytes) * tc.flushToStorageRatio)
 	var payloadsGroupToFlush [][]transaction.Transaction
@@ -7,6 +7,7 @@ package retry
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
@@ -102,6 +103,41 @@ func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 	a.Equal(1, inMemTrDropped)
 }
 
+func TestTransactionRetryQueuePurge(t *testing.T) {
+	a := assert.New(t)
+	q, clean := newOnDiskRetryQueueTest(a)
+	defer clean()
+
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 50, 0.1, NewTransactionRetryQueueTelemetry("domain"))
+
+	// The oldest transaction `10` is flushed to disk when adding `40`
+	for _, payloadSize := range []int{9, 10, 40} {
+		tr := createTransactionWithPayloadSize(payloadSize)
+		tr.CreatedAt = time.Now().Add(-time.Duration(payloadSize) * time.Minute)
+		container.Add(tr)
+	}
+	a.Len(container.GetInMemoryTransactions(), 2)
+	files, err := container.GetOnDiskFiles()
+	a.NoError(err)
+	a.Len(files, 1)
+	transactions, err := container.ReadOnDiskFile(files[0].Path)
+	a.NoError(err)
+	a.Len(transactions, 1)
+
+	transactionsRemoved, filesRemoved, err := container.Purge(time.Now().Add(-30 * time.Minute))
+	a.NoError(err)
+	a.Equal(1, transactionsRemoved)
+	a.Equal(0, filesRemoved)
+	a.Equal(9, container.getCurrentMemSizeInBytes())
+
+	transactionsRemoved, filesRemoved, err = container.Purge(time.Time{})
+	a.NoError(err)
+	a.Equal(1, transactionsRemoved)
+	a.Equal(1, filesRemoved)
+	a.Equal(0, container.getCurrentMemSizeInBytes())
+	assertPayloadSizeFromExtractTransactions(a, container, nil)
+}
+
 func createTransactionWithPayloadSize(payloadSize int) *transaction.HTTPTransaction {
 	tr := transaction.NewHTTPTransaction()
 	payload := make([]byte, payloadSize)
@@ -137,7 +173,7 @@ func newOnDiskRetryQueueTest(a *assert.Assertions) (*onDiskRetryQueue, func()) {
 			Total:     10000,
 		}}
 	diskUsageLimit := NewDiskUsageLimit("", disk, 1000, 1)
-	q, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver("", nil)), path, diskUsageLimit, newOnDiskRetryQueueTelemetry("domain"))
+	q, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver("", nil)), nil, path, diskUsageLimit, newOnDiskRetryQueueTelemetry("domain"))
 	a.NoError(err)
 	return q, clean
 }
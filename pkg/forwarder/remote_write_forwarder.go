@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	remoteWriteEndpointName    = "prometheus_remote_write"
+	remoteWriteStorageFolder   = "remote_write"
+	remoteWriteVersionHeader   = "X-Prometheus-Remote-Write-Version"
+	remoteWriteProtocolVersion = "0.1.0"
+)
+
+// RemoteWriteEndpoint is a Prometheus remote-write destination.
+type RemoteWriteEndpoint struct {
+	// Name identifies the endpoint, it must be unique.
+	Name string
+	// URL is the full remote-write URL, for instance `http://cortex:9009/api/v1/push`.
+	URL string
+	// Headers are added to every request (authentication, tenant ID, ...).
+	Headers http.Header
+}
+
+type remoteWriteDestination struct {
+	domain    string
+	endpoint  transaction.Endpoint
+	headers   http.Header
+	forwarder *domainForwarder
+}
+
+// RemoteWriteForwarder sends snappy compressed remote-write payloads to
+// Prometheus compatible backends. Each endpoint gets its own domainForwarder
+// so that payloads are retried, and persisted on disk when the retry queue
+// is full, exactly like the payloads sent to Datadog.
+type RemoteWriteForwarder struct {
+	destinations  map[string]*remoteWriteDestination
+	internalState uint32
+	m             sync.Mutex
+}
+
+// NewRemoteWriteForwarder returns a new RemoteWriteForwarder.
+func NewRemoteWriteForwarder(endpoints []RemoteWriteEndpoint, options *Options) (*RemoteWriteForwarder, error) {
+	var removalPolicy *retry.FileRemovalPolicy
+	var diskUsageLimit *retry.DiskUsageLimit
+	optionalEncryption, encryptionErr := retryQueueEncryption()
+
+	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
+	if storageMaxSize != 0 && encryptionErr != nil {
+		log.Errorf("Remote-write retry queue storage on disk is disabled because the encryption cannot be set up: %v", encryptionErr)
+	} else if storageMaxSize != 0 && getAgentName(options) != "" {
+		storagePath := path.Join(retryQueueStoragePath(), remoteWriteStorageFolder)
+		outdatedFileInDays := config.Datadog.GetInt("forwarder_outdated_file_in_days")
+
+		var err error
+		removalPolicy, err = retry.NewFileRemovalPolicy(storagePath, outdatedFileInDays, retry.FileRemovalPolicyTelemetry{})
+		if err != nil {
+			log.Errorf("Error when initializing the removal policy of the remote-write retry queues: %v", err)
+		} else {
+			if filesRemoved, err := removalPolicy.RemoveOutdatedFiles(); err != nil {
+				log.Errorf("Error when removing outdated files: %v", err)
+			} else {
+				log.Debugf("Outdated files removed: %v", strings.Join(filesRemoved, ", "))
+			}
+		}
+
+		diskRatio := config.Datadog.GetFloat64("forwarder_storage_max_disk_ratio")
+		diskUsageLimit = retry.NewDiskUsageLimit(storagePath, filesystem.NewDisk(), storageMaxSize, diskRatio)
+	}
+
+	flushToDiskMemRatio := config.Datadog.GetFloat64("forwarder_flush_to_disk_mem_ratio")
+	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
+	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
+
+	f := &RemoteWriteForwarder{
+		destinations:  make(map[string]*remoteWriteDestination, len(endpoints)),
+		internalState: Stopped,
+	}
+
+	for _, e := range endpoints {
+		if _, found := f.destinations[e.Name]; found {
+			return nil, fmt.Errorf("duplicate remote-write endpoint name %q", e.Name)
+		}
+
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL for the remote-write endpoint %q: %v", e.Name, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid URL for the remote-write endpoint %q: scheme and host are required", e.Name)
+		}
+
+		domain := u.Scheme + "://" + u.Host
+		route := u.EscapedPath()
+		if u.RawQuery != "" {
+			route += "?" + u.RawQuery
+		}
+
+		var domainFolderPath string
+		if removalPolicy != nil {
+			domainFolderPath, err = removalPolicy.RegisterDomain(e.Name)
+			if err != nil {
+				log.Errorf("Retry queue storage on disk disabled. Cannot register the remote-write endpoint '%v': %v", e.Name, err)
+			}
+		}
+
+		retryQueue := retry.BuildTransactionRetryQueue(
+			options.RetryQueuePayloadsTotalMaxSize,
+			flushToDiskMemRatio,
+			domainFolderPath,
+			diskUsageLimit,
+			optionalEncryption,
+			transactionContainerSort,
+			resolver.NewSingleDomainResolver(domain, headerValues(e.Headers)))
+
+		f.destinations[e.Name] = &remoteWriteDestination{
+			domain:   domain,
+			endpoint: transaction.Endpoint{Route: route, Name: remoteWriteEndpointName},
+			headers:  e.Headers,
+			forwarder: newDomainForwarder(
+				domain,
+				retryQueue,
+				options.NumberOfWorkers,
+				options.ConnectionResetInterval,
+				domainForwarderSort),
+		}
+	}
+
+	if removalPolicy != nil {
+		filesRemoved, err := removalPolicy.RemoveUnknownDomains()
+		if err != nil {
+			log.Errorf("Error when removing outdated files: %v", err)
+		}
+		log.Debugf("Outdated files removed: %v", strings.Join(filesRemoved, ", "))
+	}
+
+	return f, nil
+}
+
+// headerValues returns the values of the headers of an endpoint. They are used
+// as the API keys of the resolver of the retry queue so that the credentials
+// set in the headers are replaced by placeholders in the files of the retry
+// queue and restored when the files are read.
+func headerValues(headers http.Header) []string {
+	var values []string
+	for _, v := range headers {
+		for _, value := range v {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// Start starts the forwarders of every endpoint.
+func (f *RemoteWriteForwarder) Start() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if atomic.LoadUint32(&f.internalState) == Started {
+		return fmt.Errorf("the remote-write forwarder is already started")
+	}
+
+	for _, d := range f.destinations {
+		_ = d.forwarder.Start()
+	}
+
+	log.Infof("Remote-write forwarder started, sending to %v endpoint(s)", len(f.destinations))
+	atomic.StoreUint32(&f.internalState, Started)
+	return nil
+}
+
+// Stop stops the forwarders of every endpoint, flushing the pending
+// transactions to the disk when possible.
+func (f *RemoteWriteForwarder) Stop() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if atomic.LoadUint32(&f.internalState) == Stopped {
+		log.Warnf("the remote-write forwarder is already stopped")
+		return
+	}
+	atomic.StoreUint32(&f.internalState, Stopped)
+
+	purgeTimeout := config.Datadog.GetDuration("forwarder_stop_timeout") * time.Second
+	var wg sync.WaitGroup
+	for _, d := range f.destinations {
+		wg.Add(1)
+		go func(df *domainForwarder) {
+			df.Stop(purgeTimeout > 0)
+			wg.Done()
+		}(d.forwarder)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	if purgeTimeout > 0 {
+		select {
+		case <-done:
+		case <-time.After(purgeTimeout):
+			log.Warnf("Timeout emptying new remote-write transactions before stopping the forwarder %v", purgeTimeout)
+		}
+	} else {
+		<-done
+	}
+}
+
+// SubmitRemoteWrite sends snappy compressed remote-write payloads to the
+// endpoint with the given name.
+func (f *RemoteWriteForwarder) SubmitRemoteWrite(name string, payloads Payloads) error {
+	if atomic.LoadUint32(&f.internalState) == Stopped {
+		return fmt.Errorf("the remote-write forwarder is not started")
+	}
+
+	d, found := f.destinations[name]
+	if !found {
+		return fmt.Errorf("unknown remote-write endpoint %q", name)
+	}
+
+	for _, payload := range payloads {
+		t := transaction.NewHTTPTransaction()
+		t.Domain = d.domain
+		t.Endpoint = d.endpoint
+		t.Payload = payload
+		for key := range d.headers {
+			t.Headers.Set(key, d.headers.Get(key))
+		}
+		t.Headers.Set("Content-Encoding", "snappy")
+		t.Headers.Set("Content-Type", "application/x-protobuf")
+		t.Headers.Set(remoteWriteVersionHeader, remoteWriteProtocolVersion)
+		t.Headers.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+
+		tlmTxInputCount.Inc(d.domain, d.endpoint.Name)
+		tlmTxInputBytes.Add(float64(t.GetPayloadSize()), d.domain, d.endpoint.Name)
+		transactionsInputCountByEndpoint.Add(d.endpoint.Name, 1)
+		transactionsInputBytesByEndpoint.Add(d.endpoint.Name, int64(t.GetPayloadSize()))
+
+		d.forwarder.sendHTTPTransactions(t)
+	}
+	return nil
+}
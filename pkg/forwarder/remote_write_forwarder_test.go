@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRemoteWriteForwarderErrors(t *testing.T) {
+	options := NewOptionsWithResolvers(nil)
+
+	_, err := NewRemoteWriteForwarder([]RemoteWriteEndpoint{{Name: "a", URL: "/api/v1/push"}}, options)
+	assert.Error(t, err)
+
+	_, err = NewRemoteWriteForwarder([]RemoteWriteEndpoint{
+		{Name: "a", URL: "http://localhost/api/v1/push"},
+		{Name: "a", URL: "http://localhost:9090/api/v1/write"},
+	}, options)
+	assert.Error(t, err)
+}
+
+func TestRemoteWriteForwarderSubmit(t *testing.T) {
+	var m sync.Mutex
+	var requests []*http.Request
+	var bodies []string
+	calls := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		calls++
+		// the first attempt fails, the transaction must be retried
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+	}))
+	defer ts.Close()
+
+	flushInterval = 50 * time.Millisecond
+	defer func() { flushInterval = 5 * time.Second }()
+
+	fwd, err := NewRemoteWriteForwarder([]RemoteWriteEndpoint{
+		{
+			Name:    "cortex",
+			URL:     ts.URL + "/api/v1/push?tenant=a",
+			Headers: http.Header{"X-Scope-Orgid": []string{"tenant"}},
+		},
+	}, NewOptionsWithResolvers(nil))
+	require.NoError(t, err)
+
+	assert.Error(t, fwd.SubmitRemoteWrite("cortex", Payloads{}), "the forwarder is not started")
+
+	require.NoError(t, fwd.Start())
+	defer fwd.Stop()
+
+	assert.Error(t, fwd.SubmitRemoteWrite("unknown", Payloads{}))
+
+	payload := []byte("payload")
+	require.NoError(t, fwd.SubmitRemoteWrite("cortex", Payloads{&payload}))
+
+	require.Eventually(t, func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(requests) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	m.Lock()
+	defer m.Unlock()
+	r := requests[0]
+	assert.Equal(t, "/api/v1/push", r.URL.Path)
+	assert.Equal(t, "tenant=a", r.URL.RawQuery)
+	assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+	assert.Equal(t, remoteWriteProtocolVersion, r.Header.Get(remoteWriteVersionHeader))
+	assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+	assert.Empty(t, r.Header.Get(apiHTTPHeaderKey))
+	assert.Equal(t, "payload", bodies[0])
+}
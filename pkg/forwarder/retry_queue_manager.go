@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+// RetryQueueManager lists, inspects, purges and replays the transactions
+// waiting in the retry queues of a forwarder. An empty domain targets the
+// retry queues of every domain.
+type RetryQueueManager interface {
+	ListRetryQueues() []RetryQueueSummary
+	InspectRetryQueue(domain string) ([]RetryQueueTransaction, error)
+	PurgeRetryQueue(domain string, olderThan time.Duration) ([]RetryQueuePurgeResult, error)
+	ReplayRetryQueue(domain string) ([]RetryQueueReplayResult, error)
+}
+
+// RetryQueueSummary describes the retry queue of a domain.
+type RetryQueueSummary struct {
+	Domain               string     `json:"domain"`
+	InMemoryTransactions int        `json:"in_memory_transactions"`
+	InMemorySizeInBytes  int        `json:"in_memory_size_in_bytes"`
+	OldestInMemory       *time.Time `json:"oldest_in_memory,omitempty"`
+	OnDiskFiles          int        `json:"on_disk_files"`
+	OnDiskSizeInBytes    int64      `json:"on_disk_size_in_bytes"`
+	OldestOnDisk         *time.Time `json:"oldest_on_disk,omitempty"`
+	Error                string     `json:"error,omitempty"`
+}
+
+// RetryQueueTransaction describes a transaction waiting in a retry queue.
+// The route and the headers are not exposed as they can contain API keys.
+type RetryQueueTransaction struct {
+	Domain   string `json:"domain"`
+	Endpoint string `json:"endpoint"`
+	// Storage is `memory` or the name of the file storing the transaction.
+	Storage     string    `json:"storage"`
+	PayloadSize int       `json:"payload_size"`
+	Priority    string    `json:"priority"`
+	ErrorCount  int       `json:"error_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RetryQueuePurgeResult is the number of transactions and files removed
+// from the retry queue of a domain.
+type RetryQueuePurgeResult struct {
+	Domain       string `json:"domain"`
+	Transactions int    `json:"transactions"`
+	Files        int    `json:"files"`
+	Error        string `json:"error,omitempty"`
+}
+
+// RetryQueueReplayResult is the number of transactions of the retry queue of
+// a domain sent to the workers.
+type RetryQueueReplayResult struct {
+	Domain       string `json:"domain"`
+	Transactions int    `json:"transactions"`
+	Error        string `json:"error,omitempty"`
+}
+
+const inMemoryStorage = "memory"
+
+// ListRetryQueues returns a summary of the retry queue of every domain.
+func (f *DefaultForwarder) ListRetryQueues() []RetryQueueSummary {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	summaries := []RetryQueueSummary{}
+	for _, domain := range f.sortedDomains() {
+		retryQueue := f.domainForwarders[domain].retryQueue
+		summary := RetryQueueSummary{Domain: domain}
+
+		for _, t := range retryQueue.GetInMemoryTransactions() {
+			summary.InMemoryTransactions++
+			summary.InMemorySizeInBytes += t.GetPayloadSize()
+			if createdAt := t.GetCreatedAt(); summary.OldestInMemory == nil || createdAt.Before(*summary.OldestInMemory) {
+				summary.OldestInMemory = &createdAt
+			}
+		}
+
+		files, err := retryQueue.GetOnDiskFiles()
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		for _, file := range files {
+			summary.OnDiskFiles++
+			summary.OnDiskSizeInBytes += file.SizeInBytes
+			if modTime := file.ModTime; summary.OldestOnDisk == nil || modTime.Before(*summary.OldestOnDisk) {
+				summary.OldestOnDisk = &modTime
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// InspectRetryQueue returns the transactions stored in memory and on disk by
+// the retry queue of a domain.
+func (f *DefaultForwarder) InspectRetryQueue(domain string) ([]RetryQueueTransaction, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	domains, err := f.selectDomains(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []RetryQueueTransaction{}
+	for _, domain := range domains {
+		retryQueue := f.domainForwarders[domain].retryQueue
+		for _, t := range retryQueue.GetInMemoryTransactions() {
+			transactions = append(transactions, toRetryQueueTransaction(domain, inMemoryStorage, t))
+		}
+
+		files, err := retryQueue.GetOnDiskFiles()
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the files of the retry queue of %s: %v", domain, err)
+		}
+		for _, file := range files {
+			stored, err := retryQueue.ReadOnDiskFile(file.Path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read the retry file %s: %v", file.Path, err)
+			}
+			for _, t := range stored {
+				transactions = append(transactions, toRetryQueueTransaction(domain, filepath.Base(file.Path), t))
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// PurgeRetryQueue removes the transactions older than `olderThan` from the
+// retry queue of a domain. Every transaction is removed when `olderThan` is 0.
+func (f *DefaultForwarder) PurgeRetryQueue(domain string, olderThan time.Duration) ([]RetryQueuePurgeResult, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	domains, err := f.selectDomains(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var before time.Time
+	if olderThan > 0 {
+		before = time.Now().Add(-olderThan)
+	}
+
+	results := make([]RetryQueuePurgeResult, 0, len(domains))
+	for _, domain := range domains {
+		result := RetryQueuePurgeResult{Domain: domain}
+		result.Transactions, result.Files, err = f.domainForwarders[domain].retryQueue.Purge(before)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReplayRetryQueue sends the transactions of the retry queue of a domain to
+// the workers right away instead of waiting for the next retry.
+func (f *DefaultForwarder) ReplayRetryQueue(domain string) ([]RetryQueueReplayResult, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	domains, err := f.selectDomains(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RetryQueueReplayResult, 0, len(domains))
+	for _, domain := range domains {
+		result := RetryQueueReplayResult{Domain: domain}
+		result.Transactions, err = f.domainForwarders[domain].replayTransactions()
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// selectDomains returns the given domain, or every domain when it is empty.
+func (f *DefaultForwarder) selectDomains(domain string) ([]string, error) {
+	if domain == "" {
+		return f.sortedDomains(), nil
+	}
+	if _, found := f.domainForwarders[domain]; !found {
+		return nil, fmt.Errorf("unknown domain %q, the known domains are %v", domain, f.sortedDomains())
+	}
+	return []string{domain}, nil
+}
+
+// sortedDomains returns the domains of the domainForwarders, the alternate
+// domains sharing the domainForwarder of another domain are skipped.
+func (f *DefaultForwarder) sortedDomains() []string {
+	domains := make([]string, 0, len(f.domainForwarders))
+	for domain, df := range f.domainForwarders {
+		if domain == df.domain {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+func toRetryQueueTransaction(domain string, storage string, t transaction.Transaction) RetryQueueTransaction {
+	tr := RetryQueueTransaction{
+		Domain:      domain,
+		Endpoint:    t.GetEndpointName(),
+		Storage:     storage,
+		PayloadSize: t.GetPayloadSize(),
+		Priority:    "normal",
+		CreatedAt:   t.GetCreatedAt(),
+	}
+	if t.GetPriority() == transaction.TransactionPriorityHigh {
+		tr.Priority = "high"
+	}
+	if httpTransaction, ok := t.(*transaction.HTTPTransaction); ok {
+		tr.ErrorCount = httpTransaction.ErrorCount
+	}
+	return tr
+}
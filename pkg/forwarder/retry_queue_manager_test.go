@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+func addRetryQueueTransaction(t *testing.T, f *DefaultForwarder, domain string, endpoint string, payloadSize int, age time.Duration) {
+	tr := transaction.NewHTTPTransaction()
+	tr.Domain = domain
+	tr.Endpoint = transaction.Endpoint{Route: "/" + endpoint, Name: endpoint}
+	payload := make([]byte, payloadSize)
+	tr.Payload = &payload
+	tr.CreatedAt = time.Now().Add(-age)
+	tr.ErrorCount = 2
+	_, err := f.domainForwarders[domain].retryQueue.Add(tr)
+	require.NoError(t, err)
+}
+
+func TestRetryQueueManagerListInspectPurge(t *testing.T) {
+	otherDomain, _ := config.AddAgentVersionToDomain("https://app.datadoghq.eu", "app")
+	f := NewDefaultForwarder(NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(map[string][]string{
+		testVersionDomain: {"api-key-1"},
+		otherDomain:       {"api-key-2"},
+	})))
+	addRetryQueueTransaction(t, f, testVersionDomain, "series_v2", 10, time.Hour)
+	addRetryQueueTransaction(t, f, testVersionDomain, "intake", 20, time.Minute)
+
+	summaries := f.ListRetryQueues()
+	require.Len(t, summaries, 2)
+	assert.Equal(t, testVersionDomain, summaries[0].Domain)
+	assert.Equal(t, 2, summaries[0].InMemoryTransactions)
+	assert.Equal(t, 30, summaries[0].InMemorySizeInBytes)
+	require.NotNil(t, summaries[0].OldestInMemory)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), *summaries[0].OldestInMemory, time.Second)
+	assert.Equal(t, otherDomain, summaries[1].Domain)
+	assert.Equal(t, 0, summaries[1].InMemoryTransactions)
+	assert.Nil(t, summaries[1].OldestInMemory)
+
+	transactions, err := f.InspectRetryQueue(testVersionDomain)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "series_v2", transactions[0].Endpoint)
+	assert.Equal(t, inMemoryStorage, transactions[0].Storage)
+	assert.Equal(t, 10, transactions[0].PayloadSize)
+	assert.Equal(t, "normal", transactions[0].Priority)
+	assert.Equal(t, 2, transactions[0].ErrorCount)
+
+	_, err = f.InspectRetryQueue("unknown")
+	assert.Error(t, err)
+
+	results, err := f.PurgeRetryQueue(testVersionDomain, 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []RetryQueuePurgeResult{{Domain: testVersionDomain, Transactions: 1}}, results)
+
+	transactions, err = f.InspectRetryQueue("")
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "intake", transactions[0].Endpoint)
+
+	results, err = f.PurgeRetryQueue("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []RetryQueuePurgeResult{{Domain: testVersionDomain, Transactions: 1}, {Domain: otherDomain}}, results)
+	assert.Equal(t, 0, f.domainForwarders[testVersionDomain].retryQueue.GetTransactionCount())
+}
+
+func TestRetryQueueManagerReplay(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ignore the API key validation requests
+		if r.Method == http.MethodPost {
+			atomic.AddInt64(&requests, 1)
+		}
+	}))
+	defer ts.Close()
+
+	// the retry ticker must not replay the transactions before the test does
+	oldFlushInterval := flushInterval
+	defer func() { flushInterval = oldFlushInterval }()
+	flushInterval = time.Hour
+
+	f := NewDefaultForwarder(NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(map[string][]string{ts.URL: {"api-key"}})))
+
+	_, err := f.ReplayRetryQueue(ts.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Start())
+	defer f.Stop()
+
+	addRetryQueueTransaction(t, f, ts.URL, "series_v2", 10, time.Minute)
+	addRetryQueueTransaction(t, f, ts.URL, "intake", 10, time.Minute)
+	// the endpoint was failing, the replay sends the transactions right away
+	f.domainForwarders[ts.URL].blockedList.close(ts.URL + "/series_v2")
+
+	results, err := f.ReplayRetryQueue("")
+	require.NoError(t, err)
+	assert.Equal(t, []RetryQueueReplayResult{{Domain: ts.URL, Transactions: 2}}, results)
+	assert.Equal(t, 0, f.domainForwarders[ts.URL].retryQueue.GetTransactionCount())
+
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&requests) == 2 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package labels builds OpenMetrics and Prometheus labels out of Datadog
+// tags, and sanitizes metric and label names.
+package labels
+
+import (
+	"sort"
+	"strings"
+)
+
+// SanitizeName turns a Datadog metric name or tag key into a valid
+// OpenMetrics metric or label name: every character outside of
+// [a-zA-Z0-9_] (and `:` for metric names) is replaced with an underscore
+// and a leading digit is prefixed with an underscore.
+func SanitizeName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+			b.WriteByte(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteByte(c)
+		case c == ':' && allowColon:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// escapeLabelValue escapes a label value as required by the text format.
+func escapeLabelValue(value string) string {
+	if !strings.ContainsAny(value, "\\\"\n") {
+		return value
+	}
+
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+// Label is a label name and value pair.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// LabelSet builds OpenMetrics labels out of Datadog tags.
+type LabelSet struct {
+	sanitize bool
+	labels   map[string][]string
+}
+
+// NewLabelSet returns an empty LabelSet, label names are sanitized when
+// `sanitize` is true.
+func NewLabelSet(sanitize bool) *LabelSet {
+	return &LabelSet{
+		sanitize: sanitize,
+		labels:   make(map[string][]string),
+	}
+}
+
+// AddTag adds a `key:value` tag to the set. Tags without a value are
+// exposed with the `true` value, tags sharing a key have their values
+// joined with a comma.
+func (l *LabelSet) AddTag(tag string) {
+	key, value := tag, "true"
+	if i := strings.IndexByte(tag, ':'); i > 0 {
+		key, value = tag[:i], tag[i+1:]
+	}
+	l.Add(key, value)
+}
+
+// Add adds a label to the set.
+func (l *LabelSet) Add(key, value string) {
+	if l.sanitize {
+		key = SanitizeName(key, false)
+	}
+	for _, v := range l.labels[key] {
+		if v == value {
+			return
+		}
+	}
+	l.labels[key] = append(l.labels[key], value)
+}
+
+// Labels returns the labels of the set sorted by name.
+func (l *LabelSet) Labels() []Label {
+	labels := make([]Label, 0, len(l.labels))
+	for k, values := range l.labels {
+		sort.Strings(values)
+		labels = append(labels, Label{Name: k, Value: strings.Join(values, ",")})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+// String renders the label set in its canonical form, labels are sorted by
+// name so that two identical sets render identically.
+func (l *LabelSet) String() string {
+	var b strings.Builder
+	for i, label := range l.Labels() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(label.Name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(label.Value))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
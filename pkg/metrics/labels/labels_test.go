@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package labels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "my_metric_name", SanitizeName("my.metric-name", false))
+	assert.Equal(t, "ns:metric", SanitizeName("ns:metric", true))
+	assert.Equal(t, "ns_metric", SanitizeName("ns:metric", false))
+	assert.Equal(t, "_1st", SanitizeName("1st", false))
+	assert.Equal(t, "_", SanitizeName("", false))
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, "plain", escapeLabelValue("plain"))
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package common
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// FlowType is the protocol a flow was exported with
+type FlowType string
+
+const (
+	// TypeNetFlow5 is the NetFlow v5 flow type
+	TypeNetFlow5 FlowType = "netflow5"
+	// TypeNetFlow9 is the NetFlow v9 flow type
+	TypeNetFlow9 FlowType = "netflow9"
+	// TypeIPFIX is the IPFIX flow type
+	TypeIPFIX FlowType = "ipfix"
+	// TypeSFlow5 is the sFlow v5 flow type
+	TypeSFlow5 FlowType = "sflow5"
+)
+
+// FlowTypes lists the supported flow types
+var FlowTypes = []FlowType{TypeNetFlow5, TypeNetFlow9, TypeIPFIX, TypeSFlow5}
+
+// ParseFlowType returns the flow type with the provided name
+func ParseFlowType(name string) (FlowType, error) {
+	for _, flowType := range FlowTypes {
+		if string(flowType) == strings.ToLower(name) {
+			return flowType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown flow type: %s", name)
+}
+
+// DefaultPort returns the port flows of this type are usually exported to
+func (t FlowType) DefaultPort() uint16 {
+	switch t {
+	case TypeNetFlow5, TypeNetFlow9:
+		return 2055
+	case TypeIPFIX:
+		return 4739
+	case TypeSFlow5:
+		return 6343
+	}
+	return 0
+}
+
+// Direction values, from the flowDirection information element
+const (
+	DirectionIngress = 0
+	DirectionEgress  = 1
+)
+
+// Flow is the decoded representation of a flow record, common to all the flow types
+type Flow struct {
+	FlowType     FlowType
+	Namespace    string // namespace of the exporter device, set by the listener which received the flow
+	ExporterAddr net.IP
+	SamplingRate uint64
+	Direction    uint32
+
+	// timestamps in seconds
+	StartTimestamp uint64
+	EndTimestamp   uint64
+
+	Bytes   uint64
+	Packets uint64
+
+	EtherType  uint32
+	IPProtocol uint32
+	SrcAddr    net.IP
+	DstAddr    net.IP
+	SrcPort    uint32
+	DstPort    uint32
+	NextHop    net.IP
+	SrcMask    uint32
+	DstMask    uint32
+	Tos        uint32
+	TCPFlags   uint32
+
+	InputInterface  uint32
+	OutputInterface uint32
+}
+
+// EtherType values
+const (
+	EtherTypeIPv4 = 0x0800
+	EtherTypeIPv6 = 0x86DD
+)
+
+// EtherTypeName returns the name of an EtherType
+func EtherTypeName(etherType uint32) string {
+	switch etherType {
+	case EtherTypeIPv4:
+		return "IPv4"
+	case EtherTypeIPv6:
+		return "IPv6"
+	}
+	return ""
+}
+
+var ipProtocolNames = map[uint32]string{
+	1:   "ICMP",
+	2:   "IGMP",
+	6:   "TCP",
+	17:  "UDP",
+	47:  "GRE",
+	50:  "ESP",
+	51:  "AH",
+	58:  "IPv6-ICMP",
+	89:  "OSPF",
+	132: "SCTP",
+}
+
+// IPProtocolName returns the name of an IP protocol number, or the number itself for the less common protocols
+func IPProtocolName(protocol uint32) string {
+	if name, ok := ipProtocolNames[protocol]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", protocol)
+}
+
+var tcpFlagNames = []string{"FIN", "SYN", "RST", "PSH", "ACK", "URG", "ECE", "CWR"}
+
+// TCPFlagNames returns the names of the TCP flags set in the provided bitmask
+func TCPFlagNames(flags uint32) []string {
+	var names []string
+	for i, name := range tcpFlagNames {
+		if flags&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
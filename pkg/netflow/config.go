@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package netflow
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	snmpcommon "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+const (
+	defaultStopTimeout             = 5
+	defaultAggregatorBufferSize    = 10000
+	defaultAggregatorFlushInterval = 300
+	defaultListenerWorkers         = 1
+)
+
+// IsEnabled returns whether NetFlow collection is enabled in the Agent configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("network_devices.netflow.enabled")
+}
+
+// ListenerConfig contains the configuration of a flow listener.
+// YAML field tags provided for test marshalling purposes.
+type ListenerConfig struct {
+	FlowType  common.FlowType `mapstructure:"flow_type" yaml:"flow_type"`
+	BindHost  string          `mapstructure:"bind_host" yaml:"bind_host"`
+	Port      uint16          `mapstructure:"port" yaml:"port"`
+	Workers   int             `mapstructure:"workers" yaml:"workers"`
+	Namespace string          `mapstructure:"namespace" yaml:"namespace"`
+}
+
+// Addr returns the host:port address to listen on.
+func (c *ListenerConfig) Addr() string {
+	return net.JoinHostPort(c.BindHost, strconv.Itoa(int(c.Port)))
+}
+
+// Config contains the configuration of the NetFlow collector.
+// YAML field tags provided for test marshalling purposes.
+type Config struct {
+	Listeners               []ListenerConfig `mapstructure:"listeners" yaml:"listeners"`
+	StopTimeout             int              `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	AggregatorBufferSize    int              `mapstructure:"aggregator_buffer_size" yaml:"aggregator_buffer_size"`
+	AggregatorFlushInterval int              `mapstructure:"aggregator_flush_interval" yaml:"aggregator_flush_interval"`
+	AggregatorMaxFlows      int              `mapstructure:"aggregator_max_flows" yaml:"aggregator_max_flows"`
+}
+
+// ReadConfig builds and returns configuration from Agent configuration.
+func ReadConfig() (*Config, error) {
+	var c Config
+	err := config.Datadog.UnmarshalKey("network_devices.netflow", &c)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range c.Listeners {
+		listener := &c.Listeners[i]
+
+		flowType, err := common.ParseFlowType(string(listener.FlowType))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network_devices.netflow listener: %w", err)
+		}
+		listener.FlowType = flowType
+
+		// Set defaults.
+		if listener.Port == 0 {
+			listener.Port = flowType.DefaultPort()
+		}
+		if listener.BindHost == "" {
+			// Default to global bind_host option.
+			listener.BindHost = config.GetBindHost()
+		}
+		if listener.Workers <= 0 {
+			listener.Workers = defaultListenerWorkers
+		}
+		if listener.Namespace == "" {
+			listener.Namespace = config.Datadog.GetString("network_devices.namespace")
+		}
+		listener.Namespace, err = snmpcommon.NormalizeNamespace(listener.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network_devices.netflow listener: %w", err)
+		}
+	}
+
+	if c.StopTimeout <= 0 {
+		c.StopTimeout = defaultStopTimeout
+	}
+	if c.AggregatorBufferSize <= 0 {
+		c.AggregatorBufferSize = defaultAggregatorBufferSize
+	}
+	if c.AggregatorFlushInterval <= 0 {
+		c.AggregatorFlushInterval = defaultAggregatorFlushInterval
+	}
+
+	return &c, nil
+}
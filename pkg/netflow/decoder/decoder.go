@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package decoder decodes NetFlow v5, NetFlow v9, IPFIX and sFlow v5 packets into flows.
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// ErrUnknownTemplate is returned when a data set refers to a template the exporter didn't send yet. The flows of the
+// other sets of the packet are returned along with it.
+var ErrUnknownTemplate = errors.New("unknown template")
+
+func isUnknownTemplate(err error) bool {
+	return errors.Is(err, ErrUnknownTemplate)
+}
+
+// Decoder decodes flow packets. It caches the templates of the NetFlow v9 and IPFIX exporters, and is safe for
+// concurrent use.
+type Decoder struct {
+	templates *TemplateCache
+}
+
+// NewDecoder returns a new Decoder
+func NewDecoder() *Decoder {
+	return &Decoder{
+		templates: NewTemplateCache(),
+	}
+}
+
+// Templates returns the template cache of the decoder
+func (d *Decoder) Templates() *TemplateCache {
+	return d.templates
+}
+
+// Decode decodes a packet received from the exporter. NetFlow listeners accept the v5, v9 and IPFIX versions of the
+// protocol, which all start with a 2 bytes version number, while sFlow listeners only accept sFlow v5.
+func (d *Decoder) Decode(flowType common.FlowType, exporter net.IP, payload []byte) ([]*common.Flow, error) {
+	if flowType == common.TypeSFlow5 {
+		if len(payload) < 4 {
+			return nil, ErrTruncated
+		}
+		if version := binary.BigEndian.Uint32(payload[0:4]); version != 5 {
+			return nil, fmt.Errorf("unsupported sFlow version %d", version)
+		}
+		return decodeSFlow5(exporter, payload, time.Now())
+	}
+
+	if len(payload) < 2 {
+		return nil, ErrTruncated
+	}
+	switch version := binary.BigEndian.Uint16(payload[0:2]); version {
+	case 5:
+		return decodeNetFlow5(exporter, payload)
+	case 9:
+		return d.decodeNetFlow9OrIPFIX(exporter, payload, false)
+	case 10:
+		return d.decodeNetFlow9OrIPFIX(exporter, payload, true)
+	default:
+		return nil, fmt.Errorf("unsupported NetFlow version %d", version)
+	}
+}
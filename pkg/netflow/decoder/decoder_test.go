@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+var exporter = net.ParseIP("192.0.2.1")
+
+// packet builds big endian packets
+type packet struct {
+	bytes.Buffer
+}
+
+func (p *packet) put(values ...interface{}) *packet {
+	for _, value := range values {
+		switch v := value.(type) {
+		case net.IP:
+			if v4 := v.To4(); v4 != nil {
+				v = v4
+			}
+			p.Write(v)
+		case []byte:
+			p.Write(v)
+		default:
+			_ = binary.Write(&p.Buffer, binary.BigEndian, v)
+		}
+	}
+	return p
+}
+
+// set returns a NetFlow v9 or IPFIX set with the provided ID and content
+func set(id uint16, content []byte) []byte {
+	var p packet
+	p.put(id, uint16(len(content)+4), content)
+	return p.Bytes()
+}
+
+func TestDecodeNetFlow5(t *testing.T) {
+	var p packet
+	// header: version, count, uptime, unix secs, unix nsecs, sequence, engine type, engine ID, sampling
+	p.put(uint16(5), uint16(1), uint32(100000), uint32(1650000000), uint32(0), uint32(1), uint8(0), uint8(0), uint16(0x4000|10))
+	// record
+	p.put(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.254"))
+	p.put(uint16(1), uint16(2), uint32(10), uint32(1500), uint32(90000), uint32(99000))
+	p.put(uint16(51000), uint16(443), uint8(0), uint8(0x12), uint8(6), uint8(0), uint16(0), uint16(0), uint8(24), uint8(16), uint16(0))
+
+	flows, err := NewDecoder().Decode(common.TypeNetFlow5, exporter, p.Bytes())
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+
+	assert.Equal(t, &common.Flow{
+		FlowType:        common.TypeNetFlow5,
+		ExporterAddr:    exporter,
+		SamplingRate:    10,
+		StartTimestamp:  1649999990,
+		EndTimestamp:    1649999999,
+		Bytes:           1500,
+		Packets:         10,
+		EtherType:       common.EtherTypeIPv4,
+		IPProtocol:      6,
+		SrcAddr:         net.ParseIP("10.0.0.1").To4(),
+		DstAddr:         net.ParseIP("10.0.0.2").To4(),
+		SrcPort:         51000,
+		DstPort:         443,
+		NextHop:         net.ParseIP("10.0.0.254").To4(),
+		SrcMask:         24,
+		DstMask:         16,
+		TCPFlags:        0x12,
+		InputInterface:  1,
+		OutputInterface: 2,
+	}, flows[0])
+
+	_, err = NewDecoder().Decode(common.TypeNetFlow5, exporter, p.Bytes()[:60])
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestDecodeNetFlow9(t *testing.T) {
+	var tmpl packet
+	tmpl.put(uint16(256), uint16(8))
+	for _, field := range [][2]uint16{{8, 4}, {12, 4}, {7, 2}, {11, 2}, {4, 1}, {1, 4}, {2, 4}, {22, 4}, {21, 4}, {10, 2}, {14, 2}, {61, 1}} {
+		tmpl.put(field[0], field[1])
+	}
+	// the field count announces 8 fields, fix it to the actual count
+	raw := tmpl.Bytes()
+	binary.BigEndian.PutUint16(raw[2:4], 12)
+
+	var options packet
+	// options template 257: scope length 4 (system), option length 4 (sampling interval)
+	options.put(uint16(257), uint16(4), uint16(4), uint16(1), uint16(4), uint16(34), uint16(4))
+
+	var optionsData packet
+	optionsData.put(uint32(0), uint32(100), uint16(0)) // padding
+
+	var data packet
+	data.put(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), uint16(51000), uint16(53), uint8(17), uint32(200), uint32(2), uint32(50000), uint32(59000), uint16(3), uint16(4), uint8(1))
+	data.put(uint8(0), uint8(0), uint8(0)) // padding
+
+	header := func(count uint16) *packet {
+		var p packet
+		// version, count, uptime, unix secs, sequence, source ID
+		p.put(uint16(9), count, uint32(60000), uint32(1650000000), uint32(1), uint32(7))
+		return &p
+	}
+
+	decoder := NewDecoder()
+
+	// data before the template
+	p := header(1).put(set(256, data.Bytes()))
+	flows, err := decoder.Decode(common.TypeNetFlow9, exporter, p.Bytes())
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+	assert.Empty(t, flows)
+
+	p = header(4).put(set(0, raw), set(1, options.Bytes()), set(257, optionsData.Bytes()), set(256, data.Bytes()))
+	flows, err = decoder.Decode(common.TypeNetFlow9, exporter, p.Bytes())
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, 2, decoder.Templates().Len())
+
+	assert.Equal(t, &common.Flow{
+		FlowType:        common.TypeNetFlow9,
+		ExporterAddr:    exporter,
+		SamplingRate:    100,
+		Direction:       common.DirectionEgress,
+		StartTimestamp:  1649999990,
+		EndTimestamp:    1649999999,
+		Bytes:           200,
+		Packets:         2,
+		EtherType:       common.EtherTypeIPv4,
+		IPProtocol:      17,
+		SrcAddr:         net.ParseIP("10.0.0.1").To4(),
+		DstAddr:         net.ParseIP("10.0.0.2").To4(),
+		SrcPort:         51000,
+		DstPort:         53,
+		InputInterface:  3,
+		OutputInterface: 4,
+	}, flows[0])
+
+	// the templates are cached per source ID
+	p = header(1).put(set(256, data.Bytes()))
+	binary.BigEndian.PutUint32(p.Bytes()[16:20], 8)
+	_, err = decoder.Decode(common.TypeNetFlow9, exporter, p.Bytes())
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	var tmpl packet
+	tmpl.put(uint16(300), uint16(7))
+	tmpl.put(uint16(27), uint16(16), uint16(28), uint16(16), uint16(7), uint16(2), uint16(11), uint16(2), uint16(4), uint16(1))
+	tmpl.put(uint16(152), uint16(8), uint16(153), uint16(8))
+	// an enterprise specific variable length field
+	tmpl.put(uint16(0x8000|1), uint16(0xFFFF), uint32(29305))
+	binary.BigEndian.PutUint16(tmpl.Bytes()[2:4], 8)
+
+	var data packet
+	data.put(net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), uint16(40000), uint16(443), uint8(6))
+	data.put(uint64(1650000000000), uint64(1650000005000))
+	data.put(uint8(3), []byte("abc"))
+
+	body := append(set(2, tmpl.Bytes()), set(300, data.Bytes())...)
+	var p packet
+	// version, length, export time, sequence, observation domain
+	p.put(uint16(10), uint16(16+len(body)), uint32(1650000010), uint32(1), uint32(0), body)
+
+	flows, err := NewDecoder().Decode(common.TypeIPFIX, exporter, p.Bytes())
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+
+	flow := flows[0]
+	assert.Equal(t, common.TypeIPFIX, flow.FlowType)
+	assert.Equal(t, net.ParseIP("2001:db8::1"), flow.SrcAddr)
+	assert.Equal(t, net.ParseIP("2001:db8::2"), flow.DstAddr)
+	assert.Equal(t, uint32(443), flow.DstPort)
+	assert.Equal(t, uint32(common.EtherTypeIPv6), flow.EtherType)
+	assert.Equal(t, uint64(1650000000), flow.StartTimestamp)
+	assert.Equal(t, uint64(1650000005), flow.EndTimestamp)
+	assert.Equal(t, uint64(1), flow.SamplingRate)
+}
+
+func TestDecodeSFlow5(t *testing.T) {
+	// ethernet + IPv4 + TCP headers of a sampled packet
+	var frame packet
+	frame.put(make([]byte, 12), uint16(0x8100), uint16(10), uint16(0x0800))
+	frame.put(uint8(0x45), uint8(0), uint16(60), uint32(0), uint8(64), uint8(6), uint16(0), net.ParseIP("10.1.0.1"), net.ParseIP("10.1.0.2"))
+	frame.put(uint16(33000), uint16(22), uint32(0), uint32(0), uint8(0x50), uint8(0x02), uint16(0))
+
+	var record packet
+	record.put(uint32(sflowHeaderProtocolEthernet), uint32(78), uint32(4), uint32(frame.Len()), frame.Bytes())
+	for record.Len()%4 != 0 {
+		record.put(uint8(0))
+	}
+
+	var sample packet
+	// sequence, source ID, sampling rate, pool, drops, input, output, record count
+	sample.put(uint32(1), uint32(5), uint32(512), uint32(0), uint32(0), uint32(5), uint32(6), uint32(1))
+	sample.put(uint32(sflowRawPacketHeader), uint32(record.Len()), record.Bytes())
+
+	var p packet
+	// version, agent address, sub agent, sequence, uptime, sample count
+	p.put(uint32(5), uint32(sflowAddressIPv4), net.ParseIP("192.0.2.1"), uint32(0), uint32(1), uint32(1000), uint32(2))
+	// a counter sample, skipped
+	p.put(uint32(2), uint32(4), uint32(0))
+	p.put(uint32(sflowFlowSample), uint32(sample.Len()), sample.Bytes())
+
+	flows, err := NewDecoder().Decode(common.TypeSFlow5, exporter, p.Bytes())
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+
+	flow := flows[0]
+	assert.Equal(t, common.TypeSFlow5, flow.FlowType)
+	assert.Equal(t, uint64(512), flow.SamplingRate)
+	assert.Equal(t, uint64(78), flow.Bytes)
+	assert.Equal(t, uint64(1), flow.Packets)
+	assert.Equal(t, net.ParseIP("10.1.0.1").To4(), flow.SrcAddr)
+	assert.Equal(t, net.ParseIP("10.1.0.2").To4(), flow.DstAddr)
+	assert.Equal(t, uint32(33000), flow.SrcPort)
+	assert.Equal(t, uint32(22), flow.DstPort)
+	assert.Equal(t, uint32(6), flow.IPProtocol)
+	assert.Equal(t, uint32(0x02), flow.TCPFlags)
+	assert.Equal(t, uint32(5), flow.InputInterface)
+	assert.Equal(t, uint32(6), flow.OutputInterface)
+	assert.NotZero(t, flow.StartTimestamp)
+}
+
+func TestDecodeUnsupportedVersion(t *testing.T) {
+	_, err := NewDecoder().Decode(common.TypeNetFlow9, exporter, []byte{0, 1, 0, 0})
+	assert.Error(t, err)
+
+	_, err = NewDecoder().Decode(common.TypeSFlow5, exporter, []byte{0, 0, 0, 4})
+	assert.Error(t, err)
+}
@@ -0,0 +1,434 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+const (
+	netflow5RecordLength = 48
+
+	netflow9TemplateSetID        = 0
+	netflow9OptionsTemplateSetID = 1
+	ipfixTemplateSetID           = 2
+	ipfixOptionsTemplateSetID    = 3
+	minDataSetID                 = 256
+)
+
+// Information elements shared by NetFlow v9 and IPFIX, see https://www.iana.org/assignments/ipfix/ipfix.xhtml
+const (
+	fieldOctetDeltaCount             = 1
+	fieldPacketDeltaCount            = 2
+	fieldProtocolIdentifier          = 4
+	fieldIPClassOfService            = 5
+	fieldTCPControlBits              = 6
+	fieldSourceTransportPort         = 7
+	fieldSourceIPv4Address           = 8
+	fieldSourceIPv4PrefixLength      = 9
+	fieldIngressInterface            = 10
+	fieldDestinationTransportPort    = 11
+	fieldDestinationIPv4Address      = 12
+	fieldDestinationIPv4PrefixLength = 13
+	fieldEgressInterface             = 14
+	fieldIPNextHopIPv4Address        = 15
+	fieldFlowEndSysUpTime            = 21
+	fieldFlowStartSysUpTime          = 22
+	fieldSourceIPv6Address           = 27
+	fieldDestinationIPv6Address      = 28
+	fieldSourceIPv6PrefixLength      = 29
+	fieldDestinationIPv6PrefixLength = 30
+	fieldSamplingInterval            = 34
+	fieldSamplerRandomInterval       = 50
+	fieldFlowDirection               = 61
+	fieldIPNextHopIPv6Address        = 62
+	fieldOctetTotalCount             = 85
+	fieldPacketTotalCount            = 86
+	fieldFlowStartSeconds            = 150
+	fieldFlowEndSeconds              = 151
+	fieldFlowStartMilliseconds       = 152
+	fieldFlowEndMilliseconds         = 153
+	fieldSystemInitTimeMilliseconds  = 160
+	fieldEthernetType                = 256
+	fieldSamplingPacketInterval      = 305
+)
+
+// decodeNetFlow5 decodes a NetFlow v5 packet, made of fixed size records
+func decodeNetFlow5(exporter net.IP, payload []byte) ([]*common.Flow, error) {
+	r := newReader(payload)
+	r.skip(2) // version
+	count := int(r.uint16())
+	sysUptime := r.uint32()
+	unixSecs := r.uint32()
+	unixNsecs := r.uint32()
+	r.skip(6) // flow sequence, engine type and ID
+	samplingInterval := uint64(r.uint16() & 0x3FFF)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if samplingInterval == 0 {
+		samplingInterval = 1
+	}
+	if r.remaining() < count*netflow5RecordLength {
+		return nil, ErrTruncated
+	}
+
+	exportTimeMs := uint64(unixSecs)*1000 + uint64(unixNsecs)/1000000
+	uptimeToUnix := func(uptime uint32) uint64 {
+		return uptimeToUnixMs(exportTimeMs, sysUptime, uptime) / 1000
+	}
+
+	flows := make([]*common.Flow, 0, count)
+	for i := 0; i < count; i++ {
+		flow := &common.Flow{
+			FlowType:     common.TypeNetFlow5,
+			ExporterAddr: exporter,
+			SamplingRate: samplingInterval,
+			EtherType:    common.EtherTypeIPv4,
+		}
+		flow.SrcAddr = copyIP(r.bytes(4))
+		flow.DstAddr = copyIP(r.bytes(4))
+		flow.NextHop = copyIP(r.bytes(4))
+		flow.InputInterface = uint32(r.uint16())
+		flow.OutputInterface = uint32(r.uint16())
+		flow.Packets = uint64(r.uint32())
+		flow.Bytes = uint64(r.uint32())
+		flow.StartTimestamp = uptimeToUnix(r.uint32())
+		flow.EndTimestamp = uptimeToUnix(r.uint32())
+		flow.SrcPort = uint32(r.uint16())
+		flow.DstPort = uint32(r.uint16())
+		r.skip(1) // padding
+		flow.TCPFlags = uint32(r.uint8())
+		flow.IPProtocol = uint32(r.uint8())
+		flow.Tos = uint32(r.uint8())
+		r.skip(4) // source and destination AS
+		flow.SrcMask = uint32(r.uint8())
+		flow.DstMask = uint32(r.uint8())
+		r.skip(2) // padding
+		flows = append(flows, flow)
+	}
+	return flows, r.err
+}
+
+// uptimeToUnixMs converts a timestamp relative to the exporter boot to a unix timestamp in milliseconds, given the
+// uptime of the exporter at export time
+func uptimeToUnixMs(exportTimeMs uint64, sysUptime uint32, uptime uint32) uint64 {
+	// the subtraction wraps around with the 32 bits uptime counters
+	age := uint64(sysUptime - uptime)
+	if age > exportTimeMs {
+		return 0
+	}
+	return exportTimeMs - age
+}
+
+// recordContext holds the packet header values needed to decode the fields of a data record
+type recordContext struct {
+	flowType       common.FlowType
+	exporter       net.IP
+	exportTime     uint32
+	sysUptime      uint32
+	hasSysUptime   bool
+	defaultRate    uint64
+	systemInitTime uint64
+}
+
+// flowDecoder accumulates the fields of a NetFlow v9 or IPFIX data record
+type flowDecoder struct {
+	ctx                    *recordContext
+	flow                   *common.Flow
+	startUptime, endUptime uint64
+	hasStartUp, hasEndUp   bool
+}
+
+func (d *flowDecoder) setField(field templateField, value []byte) {
+	if field.EnterpriseNumber != 0 {
+		return
+	}
+
+	flow := d.flow
+	switch field.Type {
+	case fieldOctetDeltaCount, fieldOctetTotalCount:
+		flow.Bytes = decodeUint(value)
+	case fieldPacketDeltaCount, fieldPacketTotalCount:
+		flow.Packets = decodeUint(value)
+	case fieldProtocolIdentifier:
+		flow.IPProtocol = uint32(decodeUint(value))
+	case fieldIPClassOfService:
+		flow.Tos = uint32(decodeUint(value))
+	case fieldTCPControlBits:
+		flow.TCPFlags = uint32(decodeUint(value))
+	case fieldSourceTransportPort:
+		flow.SrcPort = uint32(decodeUint(value))
+	case fieldDestinationTransportPort:
+		flow.DstPort = uint32(decodeUint(value))
+	case fieldSourceIPv4Address, fieldSourceIPv6Address:
+		flow.SrcAddr = copyIP(value)
+	case fieldDestinationIPv4Address, fieldDestinationIPv6Address:
+		flow.DstAddr = copyIP(value)
+	case fieldIPNextHopIPv4Address, fieldIPNextHopIPv6Address:
+		flow.NextHop = copyIP(value)
+	case fieldSourceIPv4PrefixLength, fieldSourceIPv6PrefixLength:
+		flow.SrcMask = uint32(decodeUint(value))
+	case fieldDestinationIPv4PrefixLength, fieldDestinationIPv6PrefixLength:
+		flow.DstMask = uint32(decodeUint(value))
+	case fieldIngressInterface:
+		flow.InputInterface = uint32(decodeUint(value))
+	case fieldEgressInterface:
+		flow.OutputInterface = uint32(decodeUint(value))
+	case fieldFlowDirection:
+		flow.Direction = uint32(decodeUint(value))
+	case fieldEthernetType:
+		flow.EtherType = uint32(decodeUint(value))
+	case fieldSamplingInterval, fieldSamplerRandomInterval, fieldSamplingPacketInterval:
+		flow.SamplingRate = decodeUint(value)
+	case fieldFlowStartSysUpTime:
+		d.startUptime, d.hasStartUp = decodeUint(value), true
+	case fieldFlowEndSysUpTime:
+		d.endUptime, d.hasEndUp = decodeUint(value), true
+	case fieldFlowStartSeconds:
+		flow.StartTimestamp = decodeUint(value)
+	case fieldFlowEndSeconds:
+		flow.EndTimestamp = decodeUint(value)
+	case fieldFlowStartMilliseconds:
+		flow.StartTimestamp = decodeUint(value) / 1000
+	case fieldFlowEndMilliseconds:
+		flow.EndTimestamp = decodeUint(value) / 1000
+	case fieldSystemInitTimeMilliseconds:
+		d.ctx.systemInitTime = decodeUint(value)
+	}
+}
+
+// uptimeToUnix converts a timestamp relative to the exporter boot to a unix timestamp in seconds
+func (d *flowDecoder) uptimeToUnix(uptime uint64) uint64 {
+	if d.ctx.hasSysUptime {
+		return uptimeToUnixMs(uint64(d.ctx.exportTime)*1000, d.ctx.sysUptime, uint32(uptime)) / 1000
+	}
+	if d.ctx.systemInitTime != 0 {
+		return (d.ctx.systemInitTime + uptime) / 1000
+	}
+	return 0
+}
+
+func (d *flowDecoder) finish() *common.Flow {
+	flow := d.flow
+	if d.hasStartUp && flow.StartTimestamp == 0 {
+		flow.StartTimestamp = d.uptimeToUnix(d.startUptime)
+	}
+	if d.hasEndUp && flow.EndTimestamp == 0 {
+		flow.EndTimestamp = d.uptimeToUnix(d.endUptime)
+	}
+	if flow.EndTimestamp == 0 {
+		flow.EndTimestamp = uint64(d.ctx.exportTime)
+	}
+	if flow.StartTimestamp == 0 {
+		flow.StartTimestamp = flow.EndTimestamp
+	}
+	if flow.SamplingRate == 0 {
+		flow.SamplingRate = d.ctx.defaultRate
+	}
+	if flow.SamplingRate == 0 {
+		flow.SamplingRate = 1
+	}
+	if flow.EtherType == 0 {
+		if flow.SrcAddr.To4() != nil {
+			flow.EtherType = common.EtherTypeIPv4
+		} else if flow.SrcAddr != nil {
+			flow.EtherType = common.EtherTypeIPv6
+		}
+	}
+	return flow
+}
+
+func copyIP(value []byte) net.IP {
+	if len(value) != net.IPv4len && len(value) != net.IPv6len {
+		return nil
+	}
+	ip := make(net.IP, len(value))
+	copy(ip, value)
+	return ip
+}
+
+// readTemplateFields reads the field specifiers of a template. IPFIX field specifiers carry an enterprise number
+// when the high bit of their type is set.
+func readTemplateFields(r *reader, count int, ipfix bool) []templateField {
+	fields := make([]templateField, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		field := templateField{
+			Type:   r.uint16(),
+			Length: r.uint16(),
+		}
+		if ipfix && field.Type&0x8000 != 0 {
+			field.Type &= 0x7FFF
+			field.EnterpriseNumber = r.uint32()
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// decodeTemplateSet decodes and caches the templates of a template set
+func (d *Decoder) decodeTemplateSet(r *reader, domain domainKey, ipfix bool) error {
+	// the records are at least 4 bytes long, anything shorter is padding
+	for r.remaining() >= 4 {
+		templateID := r.uint16()
+		fieldCount := int(r.uint16())
+		fields := readTemplateFields(r, fieldCount, ipfix)
+		if r.err != nil {
+			return r.err
+		}
+		if fieldCount == 0 {
+			// template withdrawal, the cached template is kept until it is replaced
+			continue
+		}
+		d.templates.add(templateKey{domain, templateID}, &template{fields: fields})
+	}
+	return nil
+}
+
+// decodeOptionsTemplateSet decodes and caches the templates of an options template set
+func (d *Decoder) decodeOptionsTemplateSet(r *reader, domain domainKey, ipfix bool) error {
+	for r.remaining() >= 6 {
+		templateID := r.uint16()
+		var fields []templateField
+		if ipfix {
+			fieldCount := int(r.uint16())
+			r.skip(2) // scope field count, scope fields are decoded like the others
+			fields = readTemplateFields(r, fieldCount, true)
+		} else {
+			scopeLength := int(r.uint16())
+			optionLength := int(r.uint16())
+			fields = readTemplateFields(r, (scopeLength+optionLength)/4, false)
+		}
+		if r.err != nil {
+			return r.err
+		}
+		d.templates.add(templateKey{domain, templateID}, &template{fields: fields, options: true})
+	}
+	return nil
+}
+
+// decodeDataSet decodes the records of a data set with the cached template
+func (d *Decoder) decodeDataSet(r *reader, domain domainKey, setID uint16, ctx *recordContext) ([]*common.Flow, error) {
+	tmpl := d.templates.get(templateKey{domain, setID})
+	if tmpl == nil {
+		return nil, fmt.Errorf("%w: template %d of %s domain %d", ErrUnknownTemplate, setID, domain.exporter, domain.domainID)
+	}
+
+	minLength := tmpl.minRecordLength()
+	if minLength == 0 {
+		return nil, nil
+	}
+
+	ctx.defaultRate = d.templates.samplingRate(domain)
+
+	var flows []*common.Flow
+	for r.remaining() >= minLength {
+		fd := &flowDecoder{
+			ctx: ctx,
+			flow: &common.Flow{
+				FlowType:     ctx.flowType,
+				ExporterAddr: ctx.exporter,
+			},
+		}
+		for _, field := range tmpl.fields {
+			length := int(field.Length)
+			if field.Length == variableLength {
+				length = int(r.uint8())
+				if length == 0xFF {
+					length = int(r.uint16())
+				}
+			}
+			fd.setField(field, r.bytes(length))
+		}
+		if r.err != nil {
+			return flows, r.err
+		}
+
+		if tmpl.options {
+			// options records describe the exporter, keep the sampling rate they announce
+			if fd.flow.SamplingRate != 0 {
+				d.templates.setSamplingRate(domain, fd.flow.SamplingRate)
+				ctx.defaultRate = fd.flow.SamplingRate
+			}
+			continue
+		}
+		flows = append(flows, fd.finish())
+	}
+	return flows, nil
+}
+
+// decodeNetFlow9OrIPFIX decodes the sets of a NetFlow v9 or IPFIX packet. Both share the same template mechanism and
+// information elements, they differ by their headers.
+func (d *Decoder) decodeNetFlow9OrIPFIX(exporter net.IP, payload []byte, ipfix bool) ([]*common.Flow, error) {
+	r := newReader(payload)
+	ctx := &recordContext{exporter: exporter}
+	domain := domainKey{exporter: exporter.String()}
+
+	domain.version = r.uint16()
+	if ipfix {
+		ctx.flowType = common.TypeIPFIX
+		length := int(r.uint16())
+		if length > len(payload) {
+			return nil, ErrTruncated
+		}
+		r = newReader(payload[:length])
+		r.skip(4)
+		ctx.exportTime = r.uint32()
+		r.skip(4) // sequence number
+		domain.domainID = r.uint32()
+	} else {
+		ctx.flowType = common.TypeNetFlow9
+		r.skip(2) // record count
+		ctx.sysUptime = r.uint32()
+		ctx.hasSysUptime = true
+		ctx.exportTime = r.uint32()
+		r.skip(4) // sequence number
+		domain.domainID = r.uint32()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var flows []*common.Flow
+	var unknownTemplateErr error
+	for r.remaining() >= 4 {
+		setID := r.uint16()
+		setLength := int(r.uint16())
+		if setLength < 4 {
+			return flows, fmt.Errorf("invalid set length %d", setLength)
+		}
+		set := r.sub(setLength - 4)
+		if set.err != nil {
+			return flows, set.err
+		}
+
+		var err error
+		switch {
+		case !ipfix && setID == netflow9TemplateSetID, ipfix && setID == ipfixTemplateSetID:
+			err = d.decodeTemplateSet(set, domain, ipfix)
+		case !ipfix && setID == netflow9OptionsTemplateSetID, ipfix && setID == ipfixOptionsTemplateSetID:
+			err = d.decodeOptionsTemplateSet(set, domain, ipfix)
+		case setID >= minDataSetID:
+			var setFlows []*common.Flow
+			setFlows, err = d.decodeDataSet(set, domain, setID, ctx)
+			flows = append(flows, setFlows...)
+		}
+
+		if isUnknownTemplate(err) {
+			// the other sets of the packet may still be decoded
+			unknownTemplateErr = err
+			continue
+		}
+		if err != nil {
+			return flows, err
+		}
+	}
+
+	return flows, unknownTemplateErr
+}
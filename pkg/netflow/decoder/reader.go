@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrTruncated is returned when a packet is shorter than what its headers announce
+var ErrTruncated = errors.New("truncated packet")
+
+// reader reads big endian values from a packet. Reading past the end of the packet sets the error of the reader
+// and returns zero values, so that the error only needs to be checked once a structure was read.
+type reader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func newReader(buf []byte) *reader {
+	return &reader{buf: buf}
+}
+
+func (r *reader) remaining() int {
+	return len(r.buf) - r.off
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.remaining() < n {
+		r.err = ErrTruncated
+		return nil
+	}
+	b := r.buf[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) uint8() uint8 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *reader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *reader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// sub returns a reader on the next n bytes, and moves the current reader past them
+func (r *reader) sub(n int) *reader {
+	b := r.bytes(n)
+	if b == nil {
+		return &reader{err: r.err}
+	}
+	return newReader(b)
+}
+
+// decodeUint decodes an unsigned integer of any length up to 8 bytes, as used by the reduced size encoding of
+// NetFlow v9 and IPFIX
+func decodeUint(b []byte) uint64 {
+	var value uint64
+	for _, c := range b {
+		value = value<<8 | uint64(c)
+	}
+	return value
+}
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// sFlow v5 structures, see https://sflow.org/sflow_version_5.txt
+const (
+	sflowAddressIPv4 = 1
+	sflowAddressIPv6 = 2
+
+	sflowFlowSample         = 1
+	sflowExpandedFlowSample = 3
+
+	sflowRawPacketHeader = 1
+	sflowSampledIPv4     = 3
+	sflowSampledIPv6     = 4
+
+	sflowHeaderProtocolEthernet = 1
+	sflowHeaderProtocolIPv4     = 11
+	sflowHeaderProtocolIPv6     = 12
+
+	etherTypeVLAN = 0x8100
+)
+
+// decodeSFlow5 decodes the flow samples of an sFlow v5 datagram. Counter samples are skipped. Each flow sample
+// describes a single sampled packet.
+func decodeSFlow5(exporter net.IP, payload []byte, now time.Time) ([]*common.Flow, error) {
+	r := newReader(payload)
+	r.skip(4) // version
+	switch r.uint32() {
+	case sflowAddressIPv4:
+		r.skip(4)
+	case sflowAddressIPv6:
+		r.skip(16)
+	default:
+		if r.err == nil {
+			return nil, fmt.Errorf("unknown sFlow agent address type")
+		}
+	}
+	r.skip(12) // sub agent ID, sequence number and uptime
+	sampleCount := int(r.uint32())
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var flows []*common.Flow
+	for i := 0; i < sampleCount; i++ {
+		format := r.uint32()
+		sample := r.sub(int(r.uint32()))
+		if r.err != nil {
+			return flows, r.err
+		}
+
+		// only the standard formats are decoded, their enterprise is 0
+		var flow *common.Flow
+		switch format {
+		case sflowFlowSample:
+			flow = decodeSFlowSample(sample, false)
+		case sflowExpandedFlowSample:
+			flow = decodeSFlowSample(sample, true)
+		default:
+			continue
+		}
+		if sample.err != nil {
+			return flows, sample.err
+		}
+		if flow == nil {
+			continue
+		}
+
+		flow.FlowType = common.TypeSFlow5
+		flow.ExporterAddr = exporter
+		flow.StartTimestamp = uint64(now.Unix())
+		flow.EndTimestamp = flow.StartTimestamp
+		flow.Packets = 1
+		flows = append(flows, flow)
+	}
+	return flows, nil
+}
+
+// decodeSFlowSample decodes a flow sample, nil is returned when none of its records describes the sampled packet
+func decodeSFlowSample(r *reader, expanded bool) *common.Flow {
+	flow := &common.Flow{}
+
+	r.skip(4) // sequence number
+	if expanded {
+		r.skip(8) // source ID type and index
+	} else {
+		r.skip(4) // source ID
+	}
+	flow.SamplingRate = uint64(r.uint32())
+	r.skip(8) // sample pool and drops
+
+	var input, output uint32
+	if expanded {
+		r.skip(4)
+		input = r.uint32()
+		r.skip(4)
+		output = r.uint32()
+	} else {
+		// the 2 high bits are the format of the interface value
+		input = r.uint32() & 0x3FFFFFFF
+		output = r.uint32() & 0x3FFFFFFF
+	}
+	flow.InputInterface = input
+	flow.OutputInterface = output
+	if flow.SamplingRate == 0 {
+		flow.SamplingRate = 1
+	}
+
+	var decoded bool
+	recordCount := int(r.uint32())
+	for i := 0; i < recordCount && r.err == nil; i++ {
+		format := r.uint32()
+		record := r.sub(int(r.uint32()))
+
+		switch format {
+		case sflowRawPacketHeader:
+			decoded = decodeSFlowRawPacketHeader(record, flow) || decoded
+		case sflowSampledIPv4:
+			if !decoded {
+				decoded = decodeSFlowSampledIP(record, flow, net.IPv4len)
+			}
+		case sflowSampledIPv6:
+			if !decoded {
+				decoded = decodeSFlowSampledIP(record, flow, net.IPv6len)
+			}
+		}
+	}
+
+	if !decoded {
+		return nil
+	}
+	return flow
+}
+
+// decodeSFlowSampledIP decodes the sampled IPv4 and IPv6 records, which hold the decoded headers of the packet
+func decodeSFlowSampledIP(r *reader, flow *common.Flow, addrLen int) bool {
+	flow.Bytes = uint64(r.uint32())
+	flow.IPProtocol = r.uint32()
+	flow.SrcAddr = copyIP(r.bytes(addrLen))
+	flow.DstAddr = copyIP(r.bytes(addrLen))
+	flow.SrcPort = r.uint32()
+	flow.DstPort = r.uint32()
+	flow.TCPFlags = r.uint32()
+	flow.Tos = r.uint32()
+	if addrLen == net.IPv4len {
+		flow.EtherType = common.EtherTypeIPv4
+	} else {
+		flow.EtherType = common.EtherTypeIPv6
+	}
+	return r.err == nil
+}
+
+// decodeSFlowRawPacketHeader decodes the headers of the sampled packet, as captured by the exporter
+func decodeSFlowRawPacketHeader(r *reader, flow *common.Flow) bool {
+	protocol := r.uint32()
+	flow.Bytes = uint64(r.uint32()) // frame length
+	r.skip(4)                       // stripped bytes
+	header := r.bytes(int(r.uint32()))
+	if r.err != nil {
+		return false
+	}
+
+	switch protocol {
+	case sflowHeaderProtocolEthernet:
+		return decodeEthernetHeader(header, flow)
+	case sflowHeaderProtocolIPv4:
+		return decodeIPHeader(header, flow, common.EtherTypeIPv4)
+	case sflowHeaderProtocolIPv6:
+		return decodeIPHeader(header, flow, common.EtherTypeIPv6)
+	}
+	return false
+}
+
+func decodeEthernetHeader(header []byte, flow *common.Flow) bool {
+	if len(header) < 14 {
+		return false
+	}
+	etherType := binary.BigEndian.Uint16(header[12:14])
+	offset := 14
+	// skip the 802.1Q tags
+	for etherType == etherTypeVLAN && len(header) >= offset+4 {
+		etherType = binary.BigEndian.Uint16(header[offset+2 : offset+4])
+		offset += 4
+	}
+	return decodeIPHeader(header[offset:], flow, uint32(etherType))
+}
+
+// decodeIPHeader decodes the addresses, the protocol and the ports of an IPv4 or IPv6 packet
+func decodeIPHeader(header []byte, flow *common.Flow, etherType uint32) bool {
+	var transport []byte
+
+	switch etherType {
+	case common.EtherTypeIPv4:
+		if len(header) < 20 {
+			return false
+		}
+		ihl := int(header[0]&0x0F) * 4
+		if ihl < 20 || len(header) < ihl {
+			return false
+		}
+		flow.Tos = uint32(header[1])
+		flow.IPProtocol = uint32(header[9])
+		flow.SrcAddr = copyIP(header[12:16])
+		flow.DstAddr = copyIP(header[16:20])
+		// only the first fragment holds the transport header
+		if binary.BigEndian.Uint16(header[6:8])&0x1FFF == 0 {
+			transport = header[ihl:]
+		}
+	case common.EtherTypeIPv6:
+		if len(header) < 40 {
+			return false
+		}
+		flow.Tos = uint32(binary.BigEndian.Uint16(header[0:2])>>4) & 0xFF
+		flow.IPProtocol = uint32(header[6])
+		flow.SrcAddr = copyIP(header[8:24])
+		flow.DstAddr = copyIP(header[24:40])
+		transport = header[40:]
+	default:
+		return false
+	}
+	flow.EtherType = etherType
+
+	switch flow.IPProtocol {
+	case 6, 17, 132: // TCP, UDP, SCTP
+		if len(transport) >= 4 {
+			flow.SrcPort = uint32(binary.BigEndian.Uint16(transport[0:2]))
+			flow.DstPort = uint32(binary.BigEndian.Uint16(transport[2:4]))
+		}
+		if flow.IPProtocol == 6 && len(transport) >= 14 {
+			flow.TCPFlags = uint32(transport[13])
+		}
+	}
+	return true
+}
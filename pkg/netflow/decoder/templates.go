@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// variableLength is the field length announcing a variable length field in IPFIX templates
+const variableLength = 0xFFFF
+
+// templateField is a field of a NetFlow v9 or IPFIX template
+type templateField struct {
+	Type             uint16
+	Length           uint16
+	EnterpriseNumber uint32
+}
+
+// template describes the layout of the data records of a NetFlow v9 or IPFIX data set
+type template struct {
+	fields []templateField
+	// options is true for the options templates, which describe records about the exporter itself
+	options bool
+}
+
+// minRecordLength returns the minimal length of a record of this template, used to tell records from padding
+func (t *template) minRecordLength() int {
+	var length int
+	for _, field := range t.fields {
+		if field.Length == variableLength {
+			length++
+		} else {
+			length += int(field.Length)
+		}
+	}
+	return length
+}
+
+// domainKey identifies the templates namespace of an exporter: NetFlow v9 source ID and IPFIX observation domain
+// template IDs are only unique per exporter and domain.
+type domainKey struct {
+	exporter string
+	version  uint16
+	domainID uint32
+}
+
+type templateKey struct {
+	domainKey
+	templateID uint16
+}
+
+const (
+	// defaultMaxExporterEntries caps the number of templates and sampling rates cached per exporter
+	defaultMaxExporterEntries = 1024
+	// defaultMaxEntries caps the number of templates and sampling rates cached for all the exporters
+	defaultMaxEntries = 65536
+	// defaultTemplateTimeout is the duration after which the templates and sampling rates which weren't announced or
+	// used are forgotten
+	defaultTemplateTimeout = 30 * time.Minute
+)
+
+// cacheEntry is a template or a sampling rate of the cache
+type cacheEntry struct {
+	key      interface{}
+	exporter string
+	value    interface{}
+	lastUsed time.Time
+	// element and exporterElement are the positions of the entry in the lists of the cache and of its exporter
+	element         *list.Element
+	exporterElement *list.Element
+}
+
+// TemplateCache holds the templates announced by the exporters, along with the sampling rates announced in their
+// options records. The least recently used entries are evicted when an exporter, or all the exporters together, reach
+// the maximum number of entries, and the entries unused for longer than the template timeout expire.
+type TemplateCache struct {
+	mu                 sync.Mutex
+	maxExporterEntries int
+	maxEntries         int
+	timeout            time.Duration
+	// now returns the current time, tests override it
+	now func() time.Time
+
+	entries map[interface{}]*cacheEntry
+	// lru and exporterLRUs order the entries from the most to the least recently used, for all the exporters and per
+	// exporter
+	lru           *list.List
+	exporterLRUs  map[string]*list.List
+	templateCount int
+}
+
+// NewTemplateCache returns a new empty TemplateCache
+func NewTemplateCache() *TemplateCache {
+	return newTemplateCache(defaultMaxExporterEntries, defaultMaxEntries, defaultTemplateTimeout)
+}
+
+func newTemplateCache(maxExporterEntries int, maxEntries int, timeout time.Duration) *TemplateCache {
+	return &TemplateCache{
+		maxExporterEntries: maxExporterEntries,
+		maxEntries:         maxEntries,
+		timeout:            timeout,
+		now:                time.Now,
+		entries:            make(map[interface{}]*cacheEntry),
+		lru:                list.New(),
+		exporterLRUs:       make(map[string]*list.List),
+	}
+}
+
+func (c *TemplateCache) add(key templateKey, tmpl *template) {
+	c.set(key, key.exporter, tmpl)
+}
+
+func (c *TemplateCache) get(key templateKey) *template {
+	if value := c.lookup(key); value != nil {
+		return value.(*template)
+	}
+	return nil
+}
+
+func (c *TemplateCache) setSamplingRate(key domainKey, rate uint64) {
+	c.set(key, key.exporter, rate)
+}
+
+func (c *TemplateCache) samplingRate(key domainKey) uint64 {
+	if value := c.lookup(key); value != nil {
+		return value.(uint64)
+	}
+	return 0
+}
+
+// set adds or replaces an entry, then evicts the least recently used entries above the limits
+func (c *TemplateCache) set(key interface{}, exporter string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.expire(now)
+
+	if e, ok := c.entries[key]; ok {
+		e.value = value
+		c.touch(e, now)
+		return
+	}
+
+	exporterLRU := c.exporterLRUs[exporter]
+	if exporterLRU == nil {
+		exporterLRU = list.New()
+		c.exporterLRUs[exporter] = exporterLRU
+	}
+	e := &cacheEntry{key: key, exporter: exporter, value: value, lastUsed: now}
+	e.element = c.lru.PushFront(e)
+	e.exporterElement = exporterLRU.PushFront(e)
+	c.entries[key] = e
+	if _, ok := key.(templateKey); ok {
+		c.templateCount++
+	}
+
+	if exporterLRU.Len() > c.maxExporterEntries {
+		c.remove(exporterLRU.Back().Value.(*cacheEntry))
+	}
+	if c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back().Value.(*cacheEntry))
+	}
+}
+
+// lookup returns the value of an entry, or nil if it isn't cached
+func (c *TemplateCache) lookup(key interface{}) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.expire(now)
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.touch(e, now)
+	return e.value
+}
+
+// touch marks an entry as the most recently used one
+func (c *TemplateCache) touch(e *cacheEntry, now time.Time) {
+	e.lastUsed = now
+	c.lru.MoveToFront(e.element)
+	c.exporterLRUs[e.exporter].MoveToFront(e.exporterElement)
+}
+
+// expire removes the entries unused for longer than the template timeout, starting with the least recently used one
+func (c *TemplateCache) expire(now time.Time) {
+	for back := c.lru.Back(); back != nil; back = c.lru.Back() {
+		e := back.Value.(*cacheEntry)
+		if now.Sub(e.lastUsed) <= c.timeout {
+			return
+		}
+		c.remove(e)
+	}
+}
+
+func (c *TemplateCache) remove(e *cacheEntry) {
+	c.lru.Remove(e.element)
+	exporterLRU := c.exporterLRUs[e.exporter]
+	exporterLRU.Remove(e.exporterElement)
+	if exporterLRU.Len() == 0 {
+		delete(c.exporterLRUs, e.exporter)
+	}
+	delete(c.entries, e.key)
+	if _, ok := e.key.(templateKey); ok {
+		c.templateCount--
+	}
+}
+
+// Len returns the number of cached templates
+func (c *TemplateCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(c.now())
+	return c.templateCount
+}
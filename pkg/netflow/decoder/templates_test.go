@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package decoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateCacheLimits(t *testing.T) {
+	cache := newTemplateCache(2, 3, time.Hour)
+	key := func(exporter string, templateID uint16) templateKey {
+		return templateKey{domainKey{exporter: exporter, version: 10}, templateID}
+	}
+	tmpl := &template{}
+
+	cache.add(key("10.0.0.1", 256), tmpl)
+	cache.add(key("10.0.0.1", 257), tmpl)
+	assert.Equal(t, 2, cache.Len())
+
+	// the least recently used template of the exporter is evicted
+	assert.Same(t, tmpl, cache.get(key("10.0.0.1", 256)))
+	cache.add(key("10.0.0.1", 258), tmpl)
+	assert.Equal(t, 2, cache.Len())
+	assert.Nil(t, cache.get(key("10.0.0.1", 257)))
+	assert.Same(t, tmpl, cache.get(key("10.0.0.1", 256)))
+
+	// the least recently used template of all the exporters is evicted
+	cache.add(key("10.0.0.2", 256), tmpl)
+	cache.add(key("10.0.0.3", 256), tmpl)
+	assert.Equal(t, 3, cache.Len())
+	assert.Nil(t, cache.get(key("10.0.0.1", 258)))
+
+	// sampling rates count in the limits
+	cache.setSamplingRate(domainKey{exporter: "10.0.0.4", version: 10}, 100)
+	assert.Equal(t, uint64(100), cache.samplingRate(domainKey{exporter: "10.0.0.4", version: 10}))
+	assert.Equal(t, 2, cache.Len())
+	assert.Len(t, cache.exporterLRUs, 3)
+}
+
+func TestTemplateCacheTimeout(t *testing.T) {
+	cache := newTemplateCache(defaultMaxExporterEntries, defaultMaxEntries, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	key := templateKey{domainKey{exporter: "10.0.0.1", version: 9}, 256}
+	domain := domainKey{exporter: "10.0.0.2", version: 9}
+	tmpl := &template{}
+	cache.add(key, tmpl)
+	cache.setSamplingRate(domain, 100)
+
+	// used entries don't expire
+	now = now.Add(45 * time.Second)
+	assert.Same(t, tmpl, cache.get(key))
+	now = now.Add(45 * time.Second)
+	assert.Same(t, tmpl, cache.get(key))
+	assert.Equal(t, uint64(0), cache.samplingRate(domain))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 0, cache.Len())
+	assert.Nil(t, cache.get(key))
+	assert.Empty(t, cache.entries)
+	assert.Empty(t, cache.exporterLRUs)
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package flowaggregator aggregates the flows received by the flow listeners and sends them to the event platform.
+package flowaggregator
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// flowKey identifies the flows aggregated together: flows of the same 5-tuple, seen by the same exporter through
+// the same interfaces
+type flowKey struct {
+	namespace       string
+	flowType        common.FlowType
+	exporter        string
+	srcAddr         string
+	dstAddr         string
+	srcPort         uint32
+	dstPort         uint32
+	ipProtocol      uint32
+	inputInterface  uint32
+	outputInterface uint32
+	direction       uint32
+}
+
+func newFlowKey(flow *common.Flow) flowKey {
+	return flowKey{
+		namespace:       flow.Namespace,
+		flowType:        flow.FlowType,
+		exporter:        string(flow.ExporterAddr),
+		srcAddr:         string(flow.SrcAddr),
+		dstAddr:         string(flow.DstAddr),
+		srcPort:         flow.SrcPort,
+		dstPort:         flow.DstPort,
+		ipProtocol:      flow.IPProtocol,
+		inputInterface:  flow.InputInterface,
+		outputInterface: flow.OutputInterface,
+		direction:       flow.Direction,
+	}
+}
+
+// aggregatedFlow is the sum of the flows of a key over a flush interval
+type aggregatedFlow struct {
+	common.Flow
+	count uint64
+}
+
+func (f *aggregatedFlow) add(flow *common.Flow) {
+	f.Bytes += flow.Bytes
+	f.Packets += flow.Packets
+	f.TCPFlags |= flow.TCPFlags
+	if flow.StartTimestamp < f.StartTimestamp {
+		f.StartTimestamp = flow.StartTimestamp
+	}
+	if flow.EndTimestamp > f.EndTimestamp {
+		f.EndTimestamp = flow.EndTimestamp
+	}
+	f.SamplingRate = flow.SamplingRate
+	f.count++
+}
+
+// Stats contains the counters of a FlowAggregator
+type Stats struct {
+	FlowsReceived uint64
+	FlowsDropped  uint64
+	FlowsFlushed  uint64
+}
+
+// FlowAggregator aggregates the flows sent on its input channel, and flushes them to the event platform at each
+// flush interval
+type FlowAggregator struct {
+	flowIn        chan *common.Flow
+	flushInterval time.Duration
+	maxFlows      int
+	sender        aggregator.Sender
+	hostname      string
+
+	flows map[flowKey]*aggregatedFlow
+
+	statsMu sync.Mutex
+	stats   Stats
+
+	stopChan chan struct{}
+	stopped  chan struct{}
+}
+
+// NewFlowAggregator returns a new FlowAggregator
+func NewFlowAggregator(sender aggregator.Sender, hostname string, bufferSize int, flushInterval time.Duration, maxFlows int) *FlowAggregator {
+	return &FlowAggregator{
+		flowIn:        make(chan *common.Flow, bufferSize),
+		flushInterval: flushInterval,
+		maxFlows:      maxFlows,
+		sender:        sender,
+		hostname:      hostname,
+		flows:         make(map[flowKey]*aggregatedFlow),
+		stopChan:      make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+}
+
+// GetFlowInChan returns the channel the listeners send the decoded flows to
+func (agg *FlowAggregator) GetFlowInChan() chan *common.Flow {
+	return agg.flowIn
+}
+
+// Start runs the aggregation loop in the background
+func (agg *FlowAggregator) Start() {
+	go agg.run()
+}
+
+// Stop flushes the aggregated flows and stops the aggregation loop
+func (agg *FlowAggregator) Stop() {
+	close(agg.stopChan)
+	<-agg.stopped
+}
+
+// GetStats returns the counters of the aggregator
+func (agg *FlowAggregator) GetStats() Stats {
+	agg.statsMu.Lock()
+	defer agg.statsMu.Unlock()
+	return agg.stats
+}
+
+func (agg *FlowAggregator) run() {
+	defer close(agg.stopped)
+
+	ticker := time.NewTicker(agg.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-agg.stopChan:
+			agg.flush(time.Now())
+			return
+		case <-ticker.C:
+			agg.flush(time.Now())
+		case flow := <-agg.flowIn:
+			agg.add(flow)
+		}
+	}
+}
+
+// add aggregates a flow, new keys are dropped once the maximum number of flows per flush interval is reached
+func (agg *FlowAggregator) add(flow *common.Flow) {
+	key := newFlowKey(flow)
+
+	agg.statsMu.Lock()
+	defer agg.statsMu.Unlock()
+	agg.stats.FlowsReceived++
+
+	if aggFlow, ok := agg.flows[key]; ok {
+		aggFlow.add(flow)
+		return
+	}
+
+	if agg.maxFlows > 0 && len(agg.flows) >= agg.maxFlows {
+		agg.stats.FlowsDropped++
+		return
+	}
+	agg.flows[key] = &aggregatedFlow{Flow: *flow, count: 1}
+}
+
+// flush sends the aggregated flows to the event platform and resets the aggregation
+func (agg *FlowAggregator) flush(now time.Time) {
+	flows := agg.flows
+	agg.flows = make(map[flowKey]*aggregatedFlow)
+	if len(flows) == 0 {
+		return
+	}
+
+	flushTimestamp := now.UnixNano() / int64(time.Millisecond)
+	for _, flow := range flows {
+		payloadBytes, err := json.Marshal(buildPayload(flow, agg.hostname, flushTimestamp))
+		if err != nil {
+			log.Errorf("Error marshalling flow: %s", err)
+			continue
+		}
+		agg.sender.EventPlatformEvent(string(payloadBytes), epforwarder.EventTypeNetworkDevicesNetFlow)
+	}
+
+	agg.statsMu.Lock()
+	agg.stats.FlowsFlushed += uint64(len(flows))
+	agg.statsMu.Unlock()
+
+	agg.sender.Gauge("datadog.netflow.aggregator.flows_flushed", float64(len(flows)), "", nil)
+	agg.sender.Commit()
+	log.Debugf("Flushed %d aggregated flows", len(flows))
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
+)
+
+func newTestFlow(srcPort uint32, flowBytes uint64, start, end uint64) *common.Flow {
+	return &common.Flow{
+		FlowType:        common.TypeNetFlow9,
+		Namespace:       "default",
+		ExporterAddr:    net.ParseIP("192.0.2.10"),
+		SamplingRate:    1,
+		StartTimestamp:  start,
+		EndTimestamp:    end,
+		Bytes:           flowBytes,
+		Packets:         1,
+		EtherType:       common.EtherTypeIPv4,
+		IPProtocol:      6,
+		SrcAddr:         net.ParseIP("10.0.0.1"),
+		DstAddr:         net.ParseIP("10.0.0.2"),
+		SrcPort:         srcPort,
+		DstPort:         443,
+		SrcMask:         24,
+		TCPFlags:        0x02,
+		InputInterface:  1,
+		OutputInterface: 2,
+	}
+}
+
+func TestAggregateFlows(t *testing.T) {
+	sender := mocksender.NewMockSender("netflow-aggregate")
+	sender.SetupAcceptAll()
+
+	devicestore.Update(devicestore.Device{
+		ID:        "default:192.0.2.10",
+		IPAddress: "192.0.2.10",
+		Namespace: "default",
+		Name:      "edge-router",
+		Vendor:    "cisco",
+		Tags:      []string{"site:paris"},
+		Interfaces: map[int32]devicestore.Interface{
+			1: {Index: 1, Name: "Gi0/1", Alias: "uplink"},
+		},
+	})
+
+	agg := NewFlowAggregator(sender, "my-host", 10, time.Minute, 0)
+
+	first := newTestFlow(51000, 100, 1650000010, 1650000020)
+	second := newTestFlow(51000, 200, 1650000000, 1650000015)
+	second.TCPFlags = 0x10
+	agg.add(first)
+	agg.add(second)
+	assert.Len(t, agg.flows, 1)
+
+	agg.flush(time.Unix(1650000300, 0))
+	assert.Empty(t, agg.flows)
+	assert.Equal(t, Stats{FlowsReceived: 2, FlowsFlushed: 1}, agg.GetStats())
+
+	event := []byte(`
+{
+  "flush_timestamp": 1650000300000,
+  "type": "netflow9",
+  "sampling_rate": 1,
+  "direction": "ingress",
+  "start": 1650000000,
+  "end": 1650000020,
+  "bytes": 300,
+  "packets": 2,
+  "flow_count": 2,
+  "ether_type": "IPv4",
+  "ip_protocol": "TCP",
+  "device": {
+    "namespace": "default",
+    "id": "default:192.0.2.10",
+    "name": "edge-router",
+    "vendor": "cisco",
+    "tags": ["site:paris"]
+  },
+  "exporter": {"ip": "192.0.2.10"},
+  "source": {"ip": "10.0.0.1", "port": "51000", "mask": "10.0.0.0/24"},
+  "destination": {"ip": "10.0.0.2", "port": "443"},
+  "ingress": {"interface": {"index": 1, "name": "Gi0/1", "alias": "uplink"}},
+  "egress": {"interface": {"index": 2}},
+  "host": "my-host",
+  "tcp_flags": ["SYN", "ACK"]
+}
+`)
+	compactEvent := new(bytes.Buffer)
+	require.NoError(t, json.Compact(compactEvent, event))
+
+	sender.AssertEventPlatformEvent(t, compactEvent.String(), "network-devices-netflow")
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.aggregator.flows_flushed", 1, "", nil)
+}
+
+func TestAggregateFlowsMaxFlows(t *testing.T) {
+	sender := mocksender.NewMockSender("netflow-max-flows")
+	sender.SetupAcceptAll()
+
+	agg := NewFlowAggregator(sender, "my-host", 10, time.Minute, 2)
+	agg.add(newTestFlow(1, 10, 0, 0))
+	agg.add(newTestFlow(2, 10, 0, 0))
+	agg.add(newTestFlow(3, 10, 0, 0))
+	// existing contexts are still aggregated
+	agg.add(newTestFlow(1, 10, 0, 0))
+
+	assert.Len(t, agg.flows, 2)
+	assert.Equal(t, Stats{FlowsReceived: 4, FlowsDropped: 1}, agg.GetStats())
+}
+
+func TestFlowAggregatorStopFlushes(t *testing.T) {
+	sender := mocksender.NewMockSender("netflow-stop")
+	sender.SetupAcceptAll()
+
+	agg := NewFlowAggregator(sender, "my-host", 10, time.Hour, 0)
+	agg.Start()
+	agg.GetFlowInChan() <- newTestFlow(51000, 100, 0, 0)
+
+	require.Eventually(t, func() bool {
+		return agg.GetStats().FlowsReceived == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	agg.Stop()
+
+	assert.Equal(t, uint64(1), agg.GetStats().FlowsFlushed)
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+}
@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
+)
+
+// Device contains the metadata of the network device which exported a flow, as collected by the SNMP check
+type Device struct {
+	Namespace string   `json:"namespace"`
+	ID        string   `json:"id,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Vendor    string   `json:"vendor,omitempty"`
+	Model     string   `json:"model,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// Exporter contains the address a flow was exported from
+type Exporter struct {
+	IP string `json:"ip"`
+}
+
+// Endpoint contains the source or the destination of a flow
+type Endpoint struct {
+	IP   string `json:"ip"`
+	Port string `json:"port"`
+	Mask string `json:"mask,omitempty"`
+}
+
+// Interface contains a network device interface
+type Interface struct {
+	Index uint32 `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// ObservationPoint contains the interface a flow entered or left the network device through
+type ObservationPoint struct {
+	Interface Interface `json:"interface"`
+}
+
+// NextHop contains the next hop of a flow
+type NextHop struct {
+	IP string `json:"ip"`
+}
+
+// FlowPayload contains an aggregated flow, as sent to the event platform
+type FlowPayload struct {
+	FlushTimestamp int64            `json:"flush_timestamp"`
+	FlowType       common.FlowType  `json:"type"`
+	SamplingRate   uint64           `json:"sampling_rate"`
+	Direction      string           `json:"direction"`
+	Start          uint64           `json:"start"` // in seconds
+	End            uint64           `json:"end"`   // in seconds
+	Bytes          uint64           `json:"bytes"`
+	Packets        uint64           `json:"packets"`
+	FlowCount      uint64           `json:"flow_count"`
+	EtherType      string           `json:"ether_type,omitempty"`
+	IPProtocol     string           `json:"ip_protocol"`
+	Device         Device           `json:"device"`
+	Exporter       Exporter         `json:"exporter"`
+	Source         Endpoint         `json:"source"`
+	Destination    Endpoint         `json:"destination"`
+	Ingress        ObservationPoint `json:"ingress"`
+	Egress         ObservationPoint `json:"egress"`
+	Host           string           `json:"host"`
+	TCPFlags       []string         `json:"tcp_flags,omitempty"`
+	NextHop        *NextHop         `json:"next_hop,omitempty"`
+}
+
+func formatIP(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// formatMask returns the network of an address in CIDR notation, empty if the prefix length is unknown
+func formatMask(ip net.IP, prefixLength uint32) string {
+	if ip == nil || prefixLength == 0 {
+		return ""
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len
+	}
+	if int(prefixLength) > bits {
+		return ""
+	}
+	mask := net.CIDRMask(int(prefixLength), bits)
+	return fmt.Sprintf("%s/%d", ip.Mask(mask).String(), prefixLength)
+}
+
+func formatDirection(direction uint32) string {
+	if direction == common.DirectionEgress {
+		return "egress"
+	}
+	return "ingress"
+}
+
+// buildPayload builds the payload of an aggregated flow, enriched with the metadata of its exporter collected by
+// the SNMP check
+func buildPayload(flow *aggregatedFlow, hostname string, flushTimestamp int64) FlowPayload {
+	payload := FlowPayload{
+		FlushTimestamp: flushTimestamp,
+		FlowType:       flow.FlowType,
+		SamplingRate:   flow.SamplingRate,
+		Direction:      formatDirection(flow.Direction),
+		Start:          flow.StartTimestamp,
+		End:            flow.EndTimestamp,
+		Bytes:          flow.Bytes,
+		Packets:        flow.Packets,
+		FlowCount:      flow.count,
+		EtherType:      common.EtherTypeName(flow.EtherType),
+		IPProtocol:     common.IPProtocolName(flow.IPProtocol),
+		Device: Device{
+			Namespace: flow.Namespace,
+		},
+		Exporter: Exporter{
+			IP: formatIP(flow.ExporterAddr),
+		},
+		Source: Endpoint{
+			IP:   formatIP(flow.SrcAddr),
+			Port: fmt.Sprintf("%d", flow.SrcPort),
+			Mask: formatMask(flow.SrcAddr, flow.SrcMask),
+		},
+		Destination: Endpoint{
+			IP:   formatIP(flow.DstAddr),
+			Port: fmt.Sprintf("%d", flow.DstPort),
+			Mask: formatMask(flow.DstAddr, flow.DstMask),
+		},
+		Ingress: ObservationPoint{
+			Interface: Interface{Index: flow.InputInterface},
+		},
+		Egress: ObservationPoint{
+			Interface: Interface{Index: flow.OutputInterface},
+		},
+		Host:     hostname,
+		TCPFlags: common.TCPFlagNames(flow.TCPFlags),
+	}
+
+	if nextHop := formatIP(flow.NextHop); nextHop != "" && !flow.NextHop.IsUnspecified() {
+		payload.NextHop = &NextHop{IP: nextHop}
+	}
+
+	if device, ok := devicestore.Get(flow.Namespace, payload.Exporter.IP); ok {
+		payload.Device.ID = device.ID
+		payload.Device.Name = device.Name
+		payload.Device.Vendor = device.Vendor
+		payload.Device.Model = device.Model
+		payload.Device.Tags = device.Tags
+		if networkInterface, ok := device.Interfaces[int32(flow.InputInterface)]; ok {
+			payload.Ingress.Interface.Name = networkInterface.Name
+			payload.Ingress.Interface.Alias = networkInterface.Alias
+		}
+		if networkInterface, ok := device.Interfaces[int32(flow.OutputInterface)]; ok {
+			payload.Egress.Interface.Name = networkInterface.Name
+			payload.Egress.Interface.Alias = networkInterface.Alias
+		}
+	}
+
+	return payload
+}
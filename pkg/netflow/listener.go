@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package netflow
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/decoder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxPacketSize is the maximum size of a UDP datagram
+const maxPacketSize = 65535
+
+// netflowListener receives the flow packets of a listener configuration, decodes them and sends the flows to the
+// aggregator
+type netflowListener struct {
+	config  ListenerConfig
+	conn    *net.UDPConn
+	decoder *decoder.Decoder
+	flowOut chan *common.Flow
+	wg      sync.WaitGroup
+}
+
+func startFlowListener(listenerConfig ListenerConfig, flowOut chan *common.Flow) (*netflowListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", listenerConfig.Addr())
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := &netflowListener{
+		config:  listenerConfig,
+		conn:    conn,
+		decoder: decoder.NewDecoder(),
+		flowOut: flowOut,
+	}
+
+	log.Infof("Start listening for %s flows on %s", listenerConfig.FlowType, listenerConfig.Addr())
+	for i := 0; i < listenerConfig.Workers; i++ {
+		listener.wg.Add(1)
+		go listener.run()
+	}
+	return listener, nil
+}
+
+func (l *netflowListener) run() {
+	defer l.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warnf("Error reading flow packet on %s: %s", l.config.Addr(), err)
+			continue
+		}
+		netflowPackets.Add(1)
+		l.handlePacket(addr.IP, buf[:n])
+	}
+}
+
+func (l *netflowListener) handlePacket(exporter net.IP, payload []byte) {
+	flows, err := l.decoder.Decode(l.config.FlowType, exporter, payload)
+	if err != nil {
+		if errors.Is(err, decoder.ErrUnknownTemplate) {
+			// expected until the exporter sends its templates, usually every few minutes
+			netflowUnknownTemplates.Add(1)
+			log.Debugf("Packet received from %s on %s refers to an unknown template: %s", exporter, l.config.Addr(), err)
+		} else {
+			netflowDecodingErrors.Add(1)
+			log.Debugf("Error decoding packet received from %s on %s: %s", exporter, l.config.Addr(), err)
+		}
+	}
+
+	for _, flow := range flows {
+		flow.Namespace = l.config.Namespace
+		select {
+		case l.flowOut <- flow:
+			netflowFlows.Add(1)
+		default:
+			// don't block the listener when the aggregator can't keep up
+			netflowFlowsDropped.Add(1)
+		}
+	}
+}
+
+// stop closes the listener socket and waits for the workers to exit
+func (l *netflowListener) stop() {
+	log.Infof("Stop listening on %s", l.config.Addr())
+	l.conn.Close()
+	l.wg.Wait()
+}
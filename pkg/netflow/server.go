@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package netflow collects the NetFlow, IPFIX and sFlow records exported by network devices, aggregates them and
+// sends them to the event platform.
+package netflow
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/netflow/flowaggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Server manages the flow listeners and the flow aggregator.
+type Server struct {
+	config     *Config
+	listeners  []*netflowListener
+	aggregator *flowaggregator.FlowAggregator
+}
+
+var (
+	serverInstance *Server
+	startError     error
+)
+
+// StartServer starts the global NetFlow server.
+func StartServer(agentHostname string, sender aggregator.Sender) error {
+	server, err := NewNetflowServer(agentHostname, sender)
+	serverInstance = server
+	startError = err
+	return err
+}
+
+// StopServer stops the global NetFlow server, if it is running.
+func StopServer() {
+	if serverInstance != nil {
+		serverInstance.Stop()
+		serverInstance = nil
+		startError = nil
+	}
+}
+
+// IsRunning returns whether the NetFlow server is currently running.
+func IsRunning() bool {
+	return serverInstance != nil
+}
+
+// NewNetflowServer configures and returns a running NetFlow server.
+func NewNetflowServer(agentHostname string, sender aggregator.Sender) (*Server, error) {
+	config, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	flowAgg := flowaggregator.NewFlowAggregator(sender, agentHostname, config.AggregatorBufferSize,
+		time.Duration(config.AggregatorFlushInterval)*time.Second, config.AggregatorMaxFlows)
+	flowAgg.Start()
+
+	server := &Server{
+		config:     config,
+		aggregator: flowAgg,
+	}
+
+	for _, listenerConfig := range config.Listeners {
+		listener, err := startFlowListener(listenerConfig, flowAgg.GetFlowInChan())
+		if err != nil {
+			server.Stop()
+			return nil, err
+		}
+		server.listeners = append(server.listeners, listener)
+	}
+
+	return server, nil
+}
+
+// Stop stops the listeners, then flushes and stops the aggregator.
+func (s *Server) Stop() {
+	stopped := make(chan interface{})
+
+	go func() {
+		for _, listener := range s.listeners {
+			listener.stop()
+		}
+		s.aggregator.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Duration(s.config.StopTimeout) * time.Second):
+		log.Errorf("Stopping NetFlow server. Timeout after %d seconds", s.config.StopTimeout)
+	}
+}
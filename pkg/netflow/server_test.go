@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package netflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// configure sets Datadog Agent configuration from a config object.
+func configure(t *testing.T, netflowConfig Config) {
+	datadogYaml := map[string]interface{}{
+		"network_devices": map[string]interface{}{
+			"namespace": "my-ns",
+			"netflow":   netflowConfig,
+		},
+	}
+
+	config.Datadog.SetConfigType("yaml")
+	out, err := yaml.Marshal(datadogYaml)
+	require.NoError(t, err)
+
+	err = config.Datadog.ReadConfig(strings.NewReader(string(out)))
+	require.NoError(t, err)
+}
+
+func freeUDPPort(t *testing.T) uint16 {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	return uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+func TestReadConfig(t *testing.T) {
+	configure(t, Config{
+		Listeners: []ListenerConfig{
+			{FlowType: "NetFlow5"},
+			{FlowType: "ipfix", BindHost: "127.0.0.1", Port: 1234, Workers: 4, Namespace: "other"},
+		},
+		AggregatorFlushInterval: 60,
+	})
+
+	c, err := ReadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []ListenerConfig{
+		{FlowType: common.TypeNetFlow5, BindHost: "localhost", Port: 2055, Workers: 1, Namespace: "my-ns"},
+		{FlowType: common.TypeIPFIX, BindHost: "127.0.0.1", Port: 1234, Workers: 4, Namespace: "other"},
+	}, c.Listeners)
+	assert.Equal(t, "127.0.0.1:1234", c.Listeners[1].Addr())
+	assert.Equal(t, defaultStopTimeout, c.StopTimeout)
+	assert.Equal(t, defaultAggregatorBufferSize, c.AggregatorBufferSize)
+	assert.Equal(t, 60, c.AggregatorFlushInterval)
+
+	configure(t, Config{Listeners: []ListenerConfig{{FlowType: "netflow6"}}})
+	_, err = ReadConfig()
+	assert.EqualError(t, err, "invalid network_devices.netflow listener: unknown flow type: netflow6")
+}
+
+func TestStartServer(t *testing.T) {
+	port := freeUDPPort(t)
+	configure(t, Config{
+		Listeners:               []ListenerConfig{{FlowType: common.TypeNetFlow5, BindHost: "127.0.0.1", Port: port}},
+		AggregatorFlushInterval: 3600,
+	})
+
+	sender := mocksender.NewMockSender("netflow-server")
+	sender.SetupAcceptAll()
+
+	err := StartServer("my-host", sender)
+	require.NoError(t, err)
+	assert.True(t, IsRunning())
+
+	// a NetFlow v5 packet with a single record
+	var p bytes.Buffer
+	for _, v := range []interface{}{
+		uint16(5), uint16(1), uint32(100000), uint32(1650000000), uint32(0), uint32(1), uint8(0), uint8(0), uint16(0),
+		[]byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}, []byte{0, 0, 0, 0},
+		uint16(1), uint16(2), uint32(10), uint32(1500), uint32(90000), uint32(99000),
+		uint16(51000), uint16(443), uint8(0), uint8(0x12), uint8(6), uint8(0), uint16(0), uint16(0), uint8(24), uint8(16), uint16(0),
+	} {
+		require.NoError(t, binary.Write(&p, binary.BigEndian, v))
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort("127.0.0.1", fmt.Sprint(port)))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(p.Bytes())
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return netflowFlows.Value() >= 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	status := GetStatus()
+	assert.NotContains(t, status, "error")
+	assert.Len(t, status["listeners"], 1)
+
+	// stopping the server flushes the aggregated flows
+	StopServer()
+	assert.False(t, IsRunning())
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package netflow
+
+import (
+	"encoding/json"
+	"expvar"
+)
+
+var (
+	netflowExpvars          = expvar.NewMap("netflow")
+	netflowPackets          = expvar.Int{}
+	netflowDecodingErrors   = expvar.Int{}
+	netflowUnknownTemplates = expvar.Int{}
+	netflowFlows            = expvar.Int{}
+	netflowFlowsDropped     = expvar.Int{}
+)
+
+func init() {
+	netflowExpvars.Set("Packets", &netflowPackets)
+	netflowExpvars.Set("DecodingErrors", &netflowDecodingErrors)
+	netflowExpvars.Set("UnknownTemplates", &netflowUnknownTemplates)
+	netflowExpvars.Set("Flows", &netflowFlows)
+	netflowExpvars.Set("FlowsDropped", &netflowFlowsDropped)
+	netflowExpvars.Set("AggregatedFlowsFlushed", expvar.Func(func() interface{} {
+		if serverInstance == nil {
+			return 0
+		}
+		return serverInstance.aggregator.GetStats().FlowsFlushed
+	}))
+	netflowExpvars.Set("AggregatedFlowsDropped", expvar.Func(func() interface{} {
+		if serverInstance == nil {
+			return 0
+		}
+		return serverInstance.aggregator.GetStats().FlowsDropped
+	}))
+}
+
+// GetStatus returns key-value data for use in status reporting of the NetFlow server.
+func GetStatus() map[string]interface{} {
+	status := make(map[string]interface{})
+
+	metricsJSON := []byte(expvar.Get("netflow").String())
+	metrics := make(map[string]interface{})
+	json.Unmarshal(metricsJSON, &metrics) //nolint:errcheck
+	status["metrics"] = metrics
+
+	if serverInstance != nil {
+		listeners := make([]map[string]interface{}, 0, len(serverInstance.listeners))
+		for _, listener := range serverInstance.listeners {
+			listeners = append(listeners, map[string]interface{}{
+				"flowType":  string(listener.config.FlowType),
+				"addr":      listener.config.Addr(),
+				"namespace": listener.config.Namespace,
+				"templates": listener.decoder.Templates().Len(),
+			})
+		}
+		status["listeners"] = listeners
+	}
+
+	if startError != nil {
+		status["error"] = startError.Error()
+	}
+
+	return status
+}
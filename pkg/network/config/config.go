@@ -6,6 +6,7 @@
 package config
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -72,6 +73,15 @@ type Config struct {
 	// Supported libraries: OpenSSL
 	EnableHTTPSMonitoring bool
 
+	// EnableL7Monitoring specifies whether the tracer should classify and monitor Kafka, PostgreSQL and Redis traffic
+	EnableL7Monitoring bool
+
+	// KafkaPorts, PostgresPorts and RedisPorts are the server ports of the connections checked for each protocol
+	// when EnableL7Monitoring is set
+	KafkaPorts    []uint16
+	PostgresPorts []uint16
+	RedisPorts    []uint16
+
 	// UDPConnTimeout determines the length of traffic inactivity between two
 	// (IP, port)-pairs before declaring a UDP connection as inactive. This is
 	// set to /proc/sys/net/netfilter/nf_conntrack_udp_timeout on Linux by
@@ -107,6 +117,10 @@ type Config struct {
 	// get flushed on every client request (default 30s check interval)
 	MaxHTTPStatsBuffered int
 
+	// MaxL7StatsBuffered represents the maximum number of Kafka, PostgreSQL and Redis stats we'll buffer in memory.
+	// These stats get flushed on every client request (default 30s check interval)
+	MaxL7StatsBuffered int
+
 	// MaxConnectionsStateBuffered represents the maximum number of state objects that we'll store in memory. These state objects store
 	// the stats for a connection so we can accurately determine traffic change between client requests.
 	MaxConnectionsStateBuffered int
@@ -208,6 +222,12 @@ func New() *Config {
 		EnableHTTPSMonitoring: cfg.GetBool(join(netNS, "enable_https_monitoring")),
 		MaxHTTPStatsBuffered:  100000,
 
+		EnableL7Monitoring: cfg.GetBool(join(netNS, "enable_l7_monitoring")),
+		KafkaPorts:         parsePorts(cfg, join(netNS, "kafka_ports")),
+		PostgresPorts:      parsePorts(cfg, join(netNS, "postgres_ports")),
+		RedisPorts:         parsePorts(cfg, join(netNS, "redis_ports")),
+		MaxL7StatsBuffered: 100000,
+
 		EnableConntrack:              cfg.GetBool(join(spNS, "enable_conntrack")),
 		ConntrackMaxStateSize:        cfg.GetInt(join(spNS, "conntrack_max_state_size")),
 		ConntrackRateLimit:           cfg.GetInt(join(spNS, "conntrack_rate_limit")),
@@ -264,3 +284,17 @@ func New() *Config {
 
 	return c
 }
+
+// parsePorts reads a list of ports, skipping the invalid ones
+func parsePorts(cfg ddconfig.Config, key string) []uint16 {
+	var ports []uint16
+	for _, p := range cfg.GetStringSlice(key) {
+		port, err := strconv.ParseUint(strings.TrimSpace(p), 10, 16)
+		if err != nil || port == 0 {
+			log.Warnf("ignoring invalid port %q in %q", p, key)
+			continue
+		}
+		ports = append(ports, uint16(port))
+	}
+	return ports
+}
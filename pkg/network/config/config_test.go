@@ -81,6 +81,52 @@ func TestEnableHTTPMonitoring(t *testing.T) {
 	})
 }
 
+func TestEnableL7Monitoring(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableL7Monitoring)
+		assert.Equal(t, []uint16{9092}, cfg.KafkaPorts)
+		assert.Equal(t, []uint16{5432}, cfg.PostgresPorts)
+		assert.Equal(t, []uint16{6379}, cfg.RedisPorts)
+	})
+
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableL7.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableL7Monitoring)
+		assert.Equal(t, []uint16{9092, 9093}, cfg.KafkaPorts)
+		assert.Equal(t, []uint16{5432}, cfg.PostgresPorts)
+		assert.Equal(t, []uint16{6379}, cfg.RedisPorts)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_L7_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_L7_MONITORING")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_POSTGRES_PORTS", "5432 5433")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_POSTGRES_PORTS")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableL7Monitoring)
+		assert.Equal(t, []uint16{5432, 5433}, cfg.PostgresPorts)
+	})
+}
+
 func TestEnableGatewayLookup(t *testing.T) {
 	t.Run("via YAML", func(t *testing.T) {
 		newConfig()
@@ -13,6 +13,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/gogo/protobuf/jsonpb"
 )
@@ -70,6 +71,8 @@ func modelConnections(conns *network.Connections) *model.Connections {
 	routeIndex := make(map[string]RouteIdx)
 	httpIndex := FormatHTTPStats(conns.HTTP)
 	httpMatches := make(map[http.Key]struct{}, len(httpIndex))
+	l7Index := FormatDataStreamsStats(conns.L7)
+	l7Matches := make(map[protocols.Key]struct{}, len(l7Index))
 	ipc := make(ipCache, len(conns.Conns)/2)
 	dnsFormatter := newDNSFormatter(conns, ipc)
 
@@ -80,7 +83,15 @@ func modelConnections(conns *network.Connections) *model.Connections {
 			httpMatches[httpKey] = struct{}{}
 		}
 
-		agentConns[i] = FormatConnection(conn, routeIndex, httpAggregations, dnsFormatter, ipc)
+		var dataStreamsAggregations *model.DataStreamsAggregations
+		if len(l7Index) > 0 {
+			l7Key := l7KeyFromConn(conn)
+			if dataStreamsAggregations = l7Index[l7Key]; dataStreamsAggregations != nil {
+				l7Matches[l7Key] = struct{}{}
+			}
+		}
+
+		agentConns[i] = FormatConnection(conn, routeIndex, httpAggregations, dataStreamsAggregations, dnsFormatter, ipc)
 	}
 
 	if orphans := len(httpIndex) - len(httpMatches); orphans > 0 {
@@ -90,6 +101,13 @@ func modelConnections(conns *network.Connections) *model.Connections {
 		)
 	}
 
+	if orphans := len(l7Index) - len(l7Matches); orphans > 0 {
+		log.Debugf(
+			"detected orphan l7 aggregations. this can be either caused by conntrack sampling or missed tcp close events. count=%d",
+			orphans,
+		)
+	}
+
 	routes := make([]*model.Route, len(routeIndex))
 	for _, v := range routeIndex {
 		routes[v.Idx] = &v.Route
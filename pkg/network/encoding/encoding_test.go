@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/sketches-go/ddsketch"
 	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
@@ -257,6 +258,10 @@ func TestSerialization(t *testing.T) {
 
 		// fixup: json marshaler encode nil slices and maps as empty
 		result.ConnTelemetryMap = nil
+		result.Tags = nil
+		for _, c := range result.Conns {
+			c.Tags = nil
+		}
 		assert.Equal(out, result)
 	})
 	t.Run("requesting application/json serialization (with query types)", func(t *testing.T) {
@@ -278,6 +283,10 @@ func TestSerialization(t *testing.T) {
 
 		// fixup: json marshaler encode nil slices and maps as empty
 		result.ConnTelemetryMap = nil
+		result.Tags = nil
+		for _, c := range result.Conns {
+			c.Tags = nil
+		}
 		assert.Equal(out, result)
 	})
 
@@ -300,6 +309,10 @@ func TestSerialization(t *testing.T) {
 
 		// fixup: json marshaler encode nil slices and maps as empty
 		result.ConnTelemetryMap = nil
+		result.Tags = nil
+		for _, c := range result.Conns {
+			c.Tags = nil
+		}
 		assert.Equal(out, result)
 	})
 
@@ -324,6 +337,10 @@ func TestSerialization(t *testing.T) {
 
 		// fixup: json marshaler encode nil slices and maps as empty
 		result.ConnTelemetryMap = nil
+		result.Tags = nil
+		for _, c := range result.Conns {
+			c.Tags = nil
+		}
 		assert.Equal(out, result)
 	})
 
@@ -540,6 +557,61 @@ func TestHTTPSerializationWithLocalhostTraffic(t *testing.T) {
 	assert.Equal(t, out, result)
 }
 
+func TestL7Serialization(t *testing.T) {
+	client := util.AddressFromString("10.0.15.1")
+	server := util.AddressFromString("10.0.15.2")
+
+	var produce, query, get protocols.RequestStats
+	produce.AddRequest(1000, false)
+	produce.AddRequest(3000, true)
+	query.AddRequest(2000, false)
+	get.AddRequest(500, false)
+
+	var httpReqStats http.RequestStats
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{Source: client, Dest: server, SPort: 60000, DPort: 9092},
+				{Source: client, Dest: server, SPort: 60001, DPort: 5432},
+				{Source: client, Dest: server, SPort: 60002, DPort: 6379},
+			},
+		},
+		HTTP: map[http.Key]http.RequestStats{
+			http.NewKey(client, server, 60002, 6379, "/testpath", http.MethodGet): httpReqStats,
+		},
+		L7: map[protocols.Key]protocols.RequestStats{
+			protocols.NewKey(client, server, 60000, 9092, protocols.ProtocolKafka, "Produce", "orders"): produce,
+			protocols.NewKey(client, server, 60001, 5432, protocols.ProtocolPostgres, "SELECT", ""):     query,
+			protocols.NewKey(client, server, 60002, 6379, protocols.ProtocolRedis, "GET", ""):           get,
+		},
+	}
+
+	marshaler := GetMarshaler("application/protobuf")
+	blob, err := marshaler.Marshal(in)
+	require.NoError(t, err)
+
+	unmarshaler := GetUnmarshaler("application/protobuf")
+	result, err := unmarshaler.Unmarshal(blob)
+	require.NoError(t, err)
+	require.Len(t, result.Conns, 3)
+
+	kafka := new(model.DataStreamsAggregations)
+	require.NoError(t, proto.Unmarshal(result.Conns[0].DataStreamsAggregations, kafka))
+	assert.Equal(t, []*model.DataStreamsAggregations_TopicStats{{Topic: "orders", Count: 2}}, kafka.GetKafkaProduceAggregations().GetStats())
+	assert.Nil(t, kafka.KafkaFetchAggregations)
+	assert.Nil(t, result.Conns[0].HttpAggregations)
+
+	// PostgreSQL and Redis stats have no message in the payload
+	assert.Nil(t, result.Conns[1].DataStreamsAggregations)
+	assert.Nil(t, result.Conns[1].HttpAggregations)
+	assert.Nil(t, result.Conns[2].DataStreamsAggregations)
+
+	httpOut := new(model.HTTPAggregations)
+	require.NoError(t, proto.Unmarshal(result.Conns[2].HttpAggregations, httpOut))
+	require.Len(t, httpOut.EndpointAggregations, 1)
+	assert.Equal(t, "/testpath", httpOut.EndpointAggregations[0].Path)
+}
+
 func TestPooledObjectGarbageRegression(t *testing.T) {
 	// This test ensures that no garbage data is accidentally
 	// left on pooled Connection objects used during serialization
@@ -47,6 +47,7 @@ func FormatConnection(
 	conn network.ConnectionStats,
 	routes map[string]RouteIdx,
 	httpStats *model.HTTPAggregations,
+	dataStreamsStats *model.DataStreamsAggregations,
 	dnsFormatter *dnsFormatter,
 	ipc ipCache,
 ) *model.Connection {
@@ -80,6 +81,10 @@ func FormatConnection(
 		c.HttpAggregations, _ = proto.Marshal(httpStats)
 	}
 
+	if dataStreamsStats != nil {
+		c.DataStreamsAggregations, _ = proto.Marshal(dataStreamsStats)
+	}
+
 	return c
 }
 
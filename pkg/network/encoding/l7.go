@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+)
+
+const (
+	kafkaProduce = "Produce"
+	kafkaFetch   = "Fetch"
+)
+
+// FormatDataStreamsStats converts the Kafka Produce and Fetch stats into a suitable format for serialization, grouped
+// by connection. The payload only has a message for the Kafka topics, so the other Kafka operations and the PostgreSQL
+// and Redis stats are left out.
+func FormatDataStreamsStats(l7Data map[protocols.Key]protocols.RequestStats) map[protocols.Key]*model.DataStreamsAggregations {
+	var aggregationsByKey = make(map[protocols.Key]*model.DataStreamsAggregations)
+
+	for key, stats := range l7Data {
+		if key.Protocol != protocols.ProtocolKafka || (key.Operation != kafkaProduce && key.Operation != kafkaFetch) {
+			continue
+		}
+
+		topicStats := &model.DataStreamsAggregations_TopicStats{
+			Topic: key.Resource,
+			Count: uint32(stats.Count),
+		}
+		operation := key.Operation
+
+		key.Protocol = protocols.ProtocolUnknown
+		key.Operation = ""
+		key.Resource = ""
+		aggregations := aggregationsByKey[key]
+		if aggregations == nil {
+			aggregations = new(model.DataStreamsAggregations)
+			aggregationsByKey[key] = aggregations
+		}
+
+		if operation == kafkaProduce {
+			if aggregations.KafkaProduceAggregations == nil {
+				aggregations.KafkaProduceAggregations = new(model.DataStreamsAggregations_KafkaProduceAggregations)
+			}
+			aggregations.KafkaProduceAggregations.Stats = append(aggregations.KafkaProduceAggregations.Stats, topicStats)
+		} else {
+			if aggregations.KafkaFetchAggregations == nil {
+				aggregations.KafkaFetchAggregations = new(model.DataStreamsAggregations_KafkaFetchAggregations)
+			}
+			aggregations.KafkaFetchAggregations.Stats = append(aggregations.KafkaFetchAggregations.Stats, topicStats)
+		}
+	}
+
+	return aggregationsByKey
+}
+
+// Build the key for the L7 index based on whether the local or remote side is the server.
+func l7KeyFromConn(c network.ConnectionStats) protocols.Key {
+	// Retrieve translated addresses
+	laddr, lport := network.GetNATLocalAddress(c)
+	raddr, rport := network.GetNATRemoteAddress(c)
+
+	// L7 data is always indexed as (client, server), so we flip
+	// the lookup key if necessary using the port range heuristic
+	if network.IsEphemeralPort(int(lport)) {
+		return protocols.NewKey(laddr, raddr, lport, rport, protocols.ProtocolUnknown, "", "")
+	}
+
+	return protocols.NewKey(raddr, laddr, rport, lport, protocols.ProtocolUnknown, "", "")
+}
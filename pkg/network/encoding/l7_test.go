@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDataStreamsStats(t *testing.T) {
+	client := util.AddressFromString("10.0.0.1")
+	server := util.AddressFromString("10.0.0.2")
+
+	var stats protocols.RequestStats
+	stats.AddRequest(1000, false)
+	index := FormatDataStreamsStats(map[protocols.Key]protocols.RequestStats{
+		protocols.NewKey(client, server, 60000, 9092, protocols.ProtocolKafka, "Produce", "orders"): stats,
+		protocols.NewKey(client, server, 60000, 9092, protocols.ProtocolKafka, "Fetch", "orders"):   stats,
+		protocols.NewKey(client, server, 60000, 9092, protocols.ProtocolKafka, "Metadata", ""):      stats,
+		protocols.NewKey(client, server, 60001, 5432, protocols.ProtocolPostgres, "SELECT", ""):     stats,
+		protocols.NewKey(client, server, 60002, 6379, protocols.ProtocolRedis, "GET", ""):           stats,
+	})
+	assert.Len(t, index, 1)
+
+	// both sides of the connection match the stats, which are indexed as (client, server)
+	clientConn := network.ConnectionStats{Source: client, Dest: server, SPort: 60000, DPort: 9092}
+	serverConn := network.ConnectionStats{Source: server, Dest: client, SPort: 9092, DPort: 60000}
+	assert.Equal(t, &model.DataStreamsAggregations{
+		KafkaProduceAggregations: &model.DataStreamsAggregations_KafkaProduceAggregations{
+			Stats: []*model.DataStreamsAggregations_TopicStats{{Topic: "orders", Count: 1}},
+		},
+		KafkaFetchAggregations: &model.DataStreamsAggregations_KafkaFetchAggregations{
+			Stats: []*model.DataStreamsAggregations_TopicStats{{Topic: "orders", Count: 1}},
+		},
+	}, index[l7KeyFromConn(clientConn)])
+	assert.Equal(t, index[l7KeyFromConn(clientConn)], index[l7KeyFromConn(serverConn)])
+
+	otherConn := network.ConnectionStats{Source: client, Dest: server, SPort: 60001, DPort: 5432}
+	assert.NotContains(t, index, l7KeyFromConn(otherConn))
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/dustin/go-humanize"
 )
@@ -121,6 +122,7 @@ type Connections struct {
 	ConnTelemetry               map[ConnTelemetryType]int64
 	CompilationTelemetryByAsset map[string]RuntimeCompilationTelemetry
 	HTTP                        map[http.Key]http.RequestStats
+	L7                          map[protocols.Key]protocols.RequestStats
 	DNSStats                    dns.StatsByKeyByNameByType
 }
 
@@ -145,6 +147,7 @@ const (
 	MonotonicDNSPacketsDropped                           = "dns_packets_dropped"
 	HTTPRequestsDropped                                  = "http_requests_dropped"
 	HTTPRequestsMissed                                   = "http_requests_missed"
+	L7RequestsDropped                                    = "l7_requests_dropped"
 )
 
 //revive:enable
@@ -159,6 +162,7 @@ var (
 		NPMDriverFlowsMissedMaxExceeded,
 		HTTPRequestsDropped,
 		HTTPRequestsMissed,
+		L7RequestsDropped,
 	}
 
 	// MonotonicConnTelemetryTypes lists all the possible monotonic telemetry which can be bundled
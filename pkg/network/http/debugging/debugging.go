@@ -39,8 +39,8 @@ type Stats struct {
 func HTTP(stats map[http.Key]http.RequestStats, dns map[util.Address][]string) []RequestSummary {
 	all := make([]RequestSummary, 0, len(stats))
 	for k, v := range stats {
-		clientAddr := formatIP(k.SrcIPLow, k.SrcIPHigh)
-		serverAddr := formatIP(k.DstIPLow, k.DstIPHigh)
+		clientAddr := FormatIP(k.SrcIPLow, k.SrcIPHigh)
+		serverAddr := FormatIP(k.DstIPLow, k.DstIPHigh)
 
 		debug := RequestSummary{
 			Client: Address{
@@ -51,7 +51,7 @@ func HTTP(stats map[http.Key]http.RequestStats, dns map[util.Address][]string) [
 				IP:   serverAddr.String(),
 				Port: k.DstPort,
 			},
-			DNS:      getDNS(dns, serverAddr),
+			DNS:      GetDNS(dns, serverAddr),
 			Path:     k.Path,
 			Method:   k.Method.String(),
 			ByStatus: make(map[int]Stats),
@@ -66,7 +66,7 @@ func HTTP(stats map[http.Key]http.RequestStats, dns map[util.Address][]string) [
 			debug.ByStatus[status] = Stats{
 				Count:              stat.Count,
 				FirstLatencySample: stat.FirstLatencySample,
-				LatencyP50:         getSketchQuantile(stat.Latencies, 0.5),
+				LatencyP50:         GetSketchQuantile(stat.Latencies, 0.5),
 			}
 		}
 
@@ -76,7 +76,8 @@ func HTTP(stats map[http.Key]http.RequestStats, dns map[util.Address][]string) [
 	return all
 }
 
-func formatIP(low, high uint64) util.Address {
+// FormatIP returns the address built from the low and high order bits of an IP
+func FormatIP(low, high uint64) util.Address {
 	// TODO: this is  not correct, but we don't have socket family information
 	// for HTTP at the moment, so given this is purely debugging code I think it's fine
 	// to assume for now that it's only IPv6 if higher order bits are set.
@@ -87,7 +88,8 @@ func formatIP(low, high uint64) util.Address {
 	return util.V4Address(uint32(low))
 }
 
-func getDNS(dns map[util.Address][]string, addr util.Address) string {
+// GetDNS returns the first DNS name resolved for addr, if any
+func GetDNS(dns map[util.Address][]string, addr util.Address) string {
 	if names := dns[addr]; len(names) > 0 {
 		return names[0]
 	}
@@ -95,11 +97,12 @@ func getDNS(dns map[util.Address][]string, addr util.Address) string {
 	return ""
 }
 
-func getSketchQuantile(sketch *ddsketch.DDSketch, percentile float64) float64 {
+// GetSketchQuantile returns the value of the sketch at the given quantile, or 0 if the sketch is nil
+func GetSketchQuantile(sketch *ddsketch.DDSketch, percentile float64) float64 {
 	if sketch == nil {
 		return 0.0
 	}
 
-	val, _ := sketch.GetValueAtQuantile(0.5)
+	val, _ := sketch.GetValueAtQuantile(percentile)
 	return val
 }
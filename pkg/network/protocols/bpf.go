@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"fmt"
+
+	"golang.org/x/net/bpf"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	ipProtoTCP    = 6
+
+	// snapLen is the number of bytes of the accepted packets copied to userspace
+	snapLen = 262144
+)
+
+// portFilter returns a classic BPF program accepting the TCP segments of Ethernet frames with one of the given
+// source or destination ports. IPv4 fragments and IPv6 packets with extension headers are rejected.
+func portFilter(ports []uint16) ([]bpf.Instruction, error) {
+	var (
+		prog []bpf.Instruction
+		// indexes of the instructions jumping to the drop and accept instructions
+		dropIfFalse  []int
+		dropIfTrue   []int
+		drop         []int
+		acceptIfTrue []int
+	)
+	matchPorts := func(load bpf.Instruction) {
+		prog = append(prog, load)
+		for _, port := range ports {
+			acceptIfTrue = append(acceptIfTrue, len(prog))
+			prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port)})
+		}
+	}
+
+	// EtherType
+	prog = append(prog, bpf.LoadAbsolute{Off: 12, Size: 2})
+	isIPv4 := len(prog)
+	prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: etherTypeIPv4})
+
+	// IPv4: protocol, fragment offset, then the ports after the variable length header
+	prog = append(prog, bpf.LoadAbsolute{Off: 23, Size: 1})
+	dropIfFalse = append(dropIfFalse, len(prog))
+	prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: ipProtoTCP})
+	prog = append(prog, bpf.LoadAbsolute{Off: 20, Size: 2})
+	dropIfTrue = append(dropIfTrue, len(prog))
+	prog = append(prog, bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff})
+	prog = append(prog, bpf.LoadMemShift{Off: 14})
+	matchPorts(bpf.LoadIndirect{Off: 14, Size: 2})
+	matchPorts(bpf.LoadIndirect{Off: 16, Size: 2})
+	drop = append(drop, len(prog))
+	prog = append(prog, bpf.Jump{})
+
+	// IPv6: next header, then the ports after the fixed size header
+	ipv6 := len(prog)
+	dropIfFalse = append(dropIfFalse, len(prog))
+	prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: etherTypeIPv6})
+	prog = append(prog, bpf.LoadAbsolute{Off: 20, Size: 1})
+	dropIfFalse = append(dropIfFalse, len(prog))
+	prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: ipProtoTCP})
+	matchPorts(bpf.LoadAbsolute{Off: 54, Size: 2})
+	matchPorts(bpf.LoadAbsolute{Off: 56, Size: 2})
+
+	dropIndex := len(prog)
+	prog = append(prog, bpf.RetConstant{Val: 0})
+	acceptIndex := len(prog)
+	prog = append(prog, bpf.RetConstant{Val: snapLen})
+
+	var err error
+	skip := func(from, to int) uint8 {
+		if to-from-1 > 255 {
+			err = fmt.Errorf("too many ports to filter: %d", len(ports))
+		}
+		return uint8(to - from - 1)
+	}
+
+	jump := prog[isIPv4].(bpf.JumpIf)
+	jump.SkipFalse = skip(isIPv4, ipv6)
+	prog[isIPv4] = jump
+	for _, i := range dropIfFalse {
+		jump := prog[i].(bpf.JumpIf)
+		jump.SkipFalse = skip(i, dropIndex)
+		prog[i] = jump
+	}
+	for _, i := range dropIfTrue {
+		jump := prog[i].(bpf.JumpIf)
+		jump.SkipTrue = skip(i, dropIndex)
+		prog[i] = jump
+	}
+	for _, i := range acceptIfTrue {
+		jump := prog[i].(bpf.JumpIf)
+		jump.SkipTrue = skip(i, acceptIndex)
+		prog[i] = jump
+	}
+	for _, i := range drop {
+		prog[i] = bpf.Jump{Skip: uint32(dropIndex - i - 1)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
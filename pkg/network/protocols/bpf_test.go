@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func serializePacket(t *testing.T, ls ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, ls...))
+	return buf.Bytes()
+}
+
+func TestPortFilter(t *testing.T) {
+	filter, err := portFilter([]uint16{5432, 6379, 9092})
+	require.NoError(t, err)
+	vm, err := bpf.NewVM(filter)
+	require.NoError(t, err)
+
+	// all the packets of the fixtures are accepted, in both directions
+	for _, name := range []string{"kafka.pcap", "postgres.pcap", "redis.pcap"} {
+		for _, p := range readPCAP(t, name) {
+			n, err := vm.Run(p.data)
+			require.NoError(t, err)
+			assert.Positive(t, n, name)
+		}
+	}
+
+	eth := func(etherType layers.EthernetType) *layers.Ethernet {
+		return &layers.Ethernet{SrcMAC: net.HardwareAddr{0, 1, 2, 3, 4, 5}, DstMAC: net.HardwareAddr{0, 1, 2, 3, 4, 6}, EthernetType: etherType}
+	}
+	ipv4 := func(protocol layers.IPProtocol) *layers.IPv4 {
+		return &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: protocol, SrcIP: net.IPv4(10, 0, 0, 1), DstIP: net.IPv4(10, 0, 0, 2)}
+	}
+	ipv6 := &layers.IPv6{Version: 6, NextHeader: layers.IPProtocolTCP, SrcIP: net.ParseIP("fd00::1"), DstIP: net.ParseIP("fd00::2")}
+
+	tests := []struct {
+		name     string
+		packet   []byte
+		accepted bool
+	}{
+		{
+			name:     "ipv4 with options",
+			packet:   serializePacket(t, eth(layers.EthernetTypeIPv4), &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: net.IPv4(10, 0, 0, 1), DstIP: net.IPv4(10, 0, 0, 2), Options: []layers.IPv4Option{{OptionType: 1}, {OptionType: 1}, {OptionType: 1}, {OptionType: 0}}}, &layers.TCP{SrcPort: 50000, DstPort: 6379}),
+			accepted: true,
+		},
+		{
+			name:     "ipv4 other port",
+			packet:   serializePacket(t, eth(layers.EthernetTypeIPv4), ipv4(layers.IPProtocolTCP), &layers.TCP{SrcPort: 50000, DstPort: 443}),
+			accepted: false,
+		},
+		{
+			name:     "ipv4 udp",
+			packet:   serializePacket(t, eth(layers.EthernetTypeIPv4), ipv4(layers.IPProtocolUDP), &layers.UDP{SrcPort: 50000, DstPort: 6379}),
+			accepted: false,
+		},
+		{
+			name:     "ipv6 server to client",
+			packet:   serializePacket(t, eth(layers.EthernetTypeIPv6), ipv6, &layers.TCP{SrcPort: 9092, DstPort: 50000}),
+			accepted: true,
+		},
+		{
+			name:     "ipv6 other port",
+			packet:   serializePacket(t, eth(layers.EthernetTypeIPv6), ipv6, &layers.TCP{SrcPort: 50000, DstPort: 80}),
+			accepted: false,
+		},
+		{
+			name:     "arp",
+			packet:   serializePacket(t, eth(layers.EthernetTypeARP), &layers.ARP{AddrType: layers.LinkTypeEthernet, Protocol: layers.EthernetTypeIPv4, HwAddressSize: 6, ProtAddressSize: 4, SourceHwAddress: make([]byte, 6), SourceProtAddress: make([]byte, 4), DstHwAddress: make([]byte, 6), DstProtAddress: make([]byte, 4)}),
+			accepted: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := vm.Run(test.packet)
+			require.NoError(t, err)
+			assert.Equal(t, test.accepted, n > 0)
+		})
+	}
+}
+
+func TestPortFilterTooManyPorts(t *testing.T) {
+	ports := make([]uint16, 200)
+	for i := range ports {
+		ports[i] = uint16(1000 + i)
+	}
+	_, err := portFilter(ports)
+	assert.Error(t, err)
+}
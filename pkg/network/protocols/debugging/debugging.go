@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package debugging
+
+import (
+	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/http/debugging"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// RequestSummary represents a (debug-friendly) aggregated view of requests
+// matching a (client, server, protocol, operation, resource) tuple
+type RequestSummary struct {
+	Client             Address
+	Server             Address
+	DNS                string
+	Protocol           string
+	Operation          string
+	Resource           string
+	Count              int
+	ErrorCount         int
+	FirstLatencySample float64
+	LatencyP50         float64
+}
+
+// Address represents represents a IP:Port
+type Address struct {
+	IP   string
+	Port uint16
+}
+
+// L7 returns a debug-friendly representation of map[protocols.Key]protocols.RequestStats
+func L7(stats map[protocols.Key]protocols.RequestStats, dns map[util.Address][]string) []RequestSummary {
+	all := make([]RequestSummary, 0, len(stats))
+	for k, v := range stats {
+		clientAddr := httpdebugging.FormatIP(k.SrcIPLow, k.SrcIPHigh)
+		serverAddr := httpdebugging.FormatIP(k.DstIPLow, k.DstIPHigh)
+
+		all = append(all, RequestSummary{
+			Client: Address{
+				IP:   clientAddr.String(),
+				Port: k.SrcPort,
+			},
+			Server: Address{
+				IP:   serverAddr.String(),
+				Port: k.DstPort,
+			},
+			DNS:                httpdebugging.GetDNS(dns, serverAddr),
+			Protocol:           k.Protocol.String(),
+			Operation:          k.Operation,
+			Resource:           k.Resource,
+			Count:              v.Count,
+			ErrorCount:         v.ErrorCount,
+			FirstLatencySample: v.FirstLatencySample,
+			LatencyP50:         httpdebugging.GetSketchQuantile(v.Latencies, 0.5),
+		})
+	}
+
+	return all
+}
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+)
+
+const (
+	kafkaProduceAPIKey = 0
+	kafkaFetchAPIKey   = 1
+
+	// the last versions of the Produce and Fetch requests which don't use the flexible (compact) encoding, and for
+	// which the topic names are parsed
+	kafkaMaxProduceVersion = 8
+	kafkaMaxFetchVersion   = 11
+
+	kafkaMaxAPIKey     = 67
+	kafkaMaxAPIVersion = 20
+	// kafkaMaxMessageSize is the default maximum size of a Kafka request
+	kafkaMaxMessageSize = 100 * 1024 * 1024
+	// kafkaRequestPrefixSize is the number of bytes of a request parsed to find its topics
+	kafkaRequestPrefixSize = 512
+	// kafkaHeaderSize is the size of a request header without the client ID: size, API key, API version, correlation ID
+	kafkaHeaderSize = 12
+)
+
+var kafkaAPIKeyNames = []string{
+	"Produce", "Fetch", "ListOffsets", "Metadata", "LeaderAndIsr", "StopReplica", "UpdateMetadata",
+	"ControlledShutdown", "OffsetCommit", "OffsetFetch", "FindCoordinator", "JoinGroup", "Heartbeat", "LeaveGroup",
+	"SyncGroup", "DescribeGroups", "ListGroups", "SaslHandshake", "ApiVersions", "CreateTopics", "DeleteTopics",
+	"DeleteRecords", "InitProducerId", "OffsetForLeaderEpoch", "AddPartitionsToTxn", "AddOffsetsToTxn", "EndTxn",
+	"WriteTxnMarkers", "TxnOffsetCommit", "DescribeAcls", "CreateAcls", "DeleteAcls", "DescribeConfigs",
+	"AlterConfigs", "AlterReplicaLogDirs", "DescribeLogDirs", "SaslAuthenticate", "CreatePartitions",
+	"CreateDelegationToken", "RenewDelegationToken", "ExpireDelegationToken", "DescribeDelegationToken",
+	"DeleteGroups", "ElectLeaders", "IncrementalAlterConfigs", "AlterPartitionReassignments",
+	"ListPartitionReassignments", "OffsetDelete", "DescribeClientQuotas", "AlterClientQuotas",
+	"DescribeUserScramCredentials", "AlterUserScramCredentials",
+}
+
+func kafkaAPIKeyName(apiKey int16) string {
+	if int(apiKey) < len(kafkaAPIKeyNames) {
+		return kafkaAPIKeyNames[apiKey]
+	}
+	return strconv.Itoa(int(apiKey))
+}
+
+// isKafkaRequest returns whether the payload starts with a Kafka request header
+func isKafkaRequest(payload []byte) bool {
+	if len(payload) < kafkaHeaderSize+2 {
+		return false
+	}
+	size := int32(binary.BigEndian.Uint32(payload[0:4]))
+	apiKey := int16(binary.BigEndian.Uint16(payload[4:6]))
+	apiVersion := int16(binary.BigEndian.Uint16(payload[6:8]))
+	correlationID := int32(binary.BigEndian.Uint32(payload[8:12]))
+	clientIDSize := int16(binary.BigEndian.Uint16(payload[12:14]))
+
+	return size >= kafkaHeaderSize-2 && size <= kafkaMaxMessageSize &&
+		apiKey >= 0 && apiKey <= kafkaMaxAPIKey &&
+		apiVersion >= 0 && apiVersion <= kafkaMaxAPIVersion &&
+		correlationID >= 0 &&
+		clientIDSize >= -1 && int32(clientIDSize) <= size-kafkaHeaderSize+2
+}
+
+type kafkaRequest struct {
+	correlationID int32
+	apiKey        int16
+	topics        []string
+	start         time.Time
+}
+
+// kafkaParser parses the Kafka requests and responses of a connection. Kafka brokers answer the requests of a
+// connection in order, the responses are matched with their request by correlation ID.
+type kafkaParser struct {
+	report  reportFunc
+	pending []kafkaRequest
+}
+
+func newKafkaParser(report reportFunc) protocolParser {
+	return &kafkaParser{report: report}
+}
+
+func (p *kafkaParser) parseRequest(buf []byte, ts time.Time) (int, error) {
+	if len(buf) < 4 {
+		return 0, nil
+	}
+	size := int(int32(binary.BigEndian.Uint32(buf[0:4])))
+	if size < kafkaHeaderSize-2 || size > kafkaMaxMessageSize {
+		return 0, errMalformed
+	}
+	total := size + 4
+	prefix := total
+	if prefix > kafkaRequestPrefixSize {
+		prefix = kafkaRequestPrefixSize
+	}
+	if len(buf) < prefix {
+		return 0, nil
+	}
+
+	r := reader{buf: buf[:prefix], off: 4}
+	apiKey := r.int16()
+	apiVersion := r.int16()
+	correlationID := r.int32()
+	if clientIDSize := r.int16(); clientIDSize > 0 {
+		r.skip(int(clientIDSize))
+	}
+	if r.err || apiKey < 0 || apiKey > kafkaMaxAPIKey {
+		return 0, errMalformed
+	}
+
+	request := kafkaRequest{correlationID: correlationID, apiKey: apiKey, start: ts}
+	switch {
+	case apiKey == kafkaProduceAPIKey && apiVersion <= kafkaMaxProduceVersion:
+		var acks int16
+		request.topics, acks = parseKafkaProduceRequest(&r, apiVersion)
+		if acks == 0 {
+			// the broker doesn't answer the requests which don't require any acknowledgement
+			p.reportRequest(request, 0)
+			return total, nil
+		}
+	case apiKey == kafkaFetchAPIKey && apiVersion <= kafkaMaxFetchVersion:
+		request.topics = parseKafkaFetchRequest(&r, apiVersion)
+	}
+
+	if len(p.pending) >= maxPendingRequests {
+		p.reportRequest(p.pending[0], 0)
+		p.pending = p.pending[1:]
+	}
+	p.pending = append(p.pending, request)
+	return total, nil
+}
+
+func (p *kafkaParser) parseResponse(buf []byte, ts time.Time) (int, error) {
+	if len(buf) < 8 {
+		return 0, nil
+	}
+	size := int(int32(binary.BigEndian.Uint32(buf[0:4])))
+	if size < 4 || size > kafkaMaxMessageSize {
+		return 0, errMalformed
+	}
+	correlationID := int32(binary.BigEndian.Uint32(buf[4:8]))
+
+	for i, request := range p.pending {
+		if request.correlationID != correlationID {
+			continue
+		}
+		// the requests before this one won't get a response anymore
+		for _, lost := range p.pending[:i] {
+			p.reportRequest(lost, 0)
+		}
+		p.reportRequest(request, latencySince(request.start, ts))
+		p.pending = p.pending[i+1:]
+		break
+	}
+	return size + 4, nil
+}
+
+func (p *kafkaParser) reportRequest(request kafkaRequest, latency float64) {
+	operation := kafkaAPIKeyName(request.apiKey)
+	if len(request.topics) == 0 {
+		p.report(operation, "", latency, false)
+		return
+	}
+	for _, topic := range request.topics {
+		p.report(operation, topic, latency, false)
+	}
+}
+
+// kafkaString reads a non compact, nullable string
+func kafkaString(r *reader) string {
+	size := r.int16()
+	if size <= 0 {
+		return ""
+	}
+	return string(r.bytes(int(size)))
+}
+
+// parseKafkaProduceRequest returns the topics and the acks of a Produce request. The partitions records usually
+// don't fit in the request prefix, the topics after the first one are then not returned.
+func parseKafkaProduceRequest(r *reader, apiVersion int16) ([]string, int16) {
+	if apiVersion >= 3 {
+		kafkaString(r) // transactional ID
+	}
+	acks := r.int16()
+	r.skip(4) // timeout
+	topicCount := r.int32()
+	if r.err {
+		// not enough data to know, assume a response is expected
+		return nil, -1
+	}
+
+	var topics []string
+	for i := int32(0); i < topicCount; i++ {
+		topic := kafkaString(r)
+		if r.err {
+			break
+		}
+		topics = append(topics, topic)
+
+		partitionCount := r.int32()
+		for j := int32(0); j < partitionCount && !r.err; j++ {
+			r.skip(4) // partition index
+			if recordsSize := r.int32(); recordsSize > 0 {
+				r.skip(int(recordsSize))
+			}
+		}
+	}
+	return topics, acks
+}
+
+// parseKafkaFetchRequest returns the topics of a Fetch request
+func parseKafkaFetchRequest(r *reader, apiVersion int16) []string {
+	r.skip(12) // replica ID, max wait, min bytes
+	if apiVersion >= 3 {
+		r.skip(4) // max bytes
+	}
+	if apiVersion >= 4 {
+		r.skip(1) // isolation level
+	}
+	if apiVersion >= 7 {
+		r.skip(8) // session ID and epoch
+	}
+
+	// partition index, fetch offset, partition max bytes
+	partitionSize := 16
+	if apiVersion >= 9 {
+		partitionSize += 4 // current leader epoch
+	}
+	if apiVersion >= 5 {
+		partitionSize += 8 // log start offset
+	}
+
+	var topics []string
+	topicCount := r.int32()
+	for i := int32(0); i < topicCount && !r.err; i++ {
+		topic := kafkaString(r)
+		if r.err {
+			break
+		}
+		topics = append(topics, topic)
+		partitionCount := r.int32()
+		r.skip(int(partitionCount) * partitionSize)
+	}
+	return topics
+}
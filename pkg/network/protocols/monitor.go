@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/google/gopacket"
+)
+
+// maxTrackedConns is the maximum number of connections tracked by the monitor
+const maxTrackedConns = 65536
+
+// packetSource reads raw packet data
+type packetSource interface {
+	// VisitPackets reads all new raw packets that are available, invoking the given callback for each packet.
+	// If no packet is available, VisitPacket returns immediately.
+	// The data buffer is reused between invocations of VisitPacket and thus should not be pointed to.
+	// If the cancel channel is closed, VisitPackets will stop reading.
+	VisitPackets(cancel <-chan struct{}, visitor func(data []byte, timestamp time.Time) error) error
+
+	// PacketType returns the type of packet this source reads
+	PacketType() gopacket.LayerType
+
+	// Close closes the packet source
+	Close()
+}
+
+// Monitor is responsible for:
+// * Reading the TCP segments of the configured ports from a packet source;
+// * Classifying their connections as Kafka, PostgreSQL or Redis connections;
+// * Parsing the requests and responses of the classified connections;
+// * Aggregating the requests per connection, operation and resource.
+type Monitor struct {
+	source  packetSource
+	decoder *packetDecoder
+	segment segment
+
+	// mux protects the tracker and the statkeeper, which are accessed by the packet loop and by GetL7Stats
+	mux               sync.Mutex
+	tracker           *connTracker
+	statkeeper        *statKeeper
+	telemetry         *telemetry
+	telemetrySnapshot *telemetry
+
+	exit    chan struct{}
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// ports returns the server ports of each protocol
+func ports(c *config.Config) map[uint16]ProtocolType {
+	ports := make(map[uint16]ProtocolType)
+	for _, p := range c.KafkaPorts {
+		ports[p] = ProtocolKafka
+	}
+	for _, p := range c.PostgresPorts {
+		ports[p] = ProtocolPostgres
+	}
+	for _, p := range c.RedisPorts {
+		ports[p] = ProtocolRedis
+	}
+	return ports
+}
+
+func newMonitor(c *config.Config, source packetSource) *Monitor {
+	telemetry := newTelemetry()
+	statkeeper := newStatKeeper(c.MaxL7StatsBuffered, telemetry)
+	m := &Monitor{
+		source:     source,
+		decoder:    newPacketDecoder(source.PacketType()),
+		tracker:    newConnTracker(ports(c), maxTrackedConns, statkeeper, telemetry),
+		statkeeper: statkeeper,
+		telemetry:  telemetry,
+		exit:       make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+	return m
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		if err := m.source.VisitPackets(m.exit, m.processPacket); err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (m *Monitor) processPacket(data []byte, ts time.Time) error {
+	if err := m.decoder.decode(data, &m.segment); err != nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.tracker.process(&m.segment, ts)
+	return nil
+}
+
+// GetL7Stats returns a map of Kafka, PostgreSQL and Redis stats stored in the following format:
+// [source, dest tuple, protocol, operation, resource] -> RequestStats object
+func (m *Monitor) GetL7Stats() map[Key]RequestStats {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped {
+		return nil
+	}
+
+	delta := m.telemetry.reset()
+	delta.report()
+	m.telemetrySnapshot = &delta
+	return m.statkeeper.getAndResetAllStats()
+}
+
+// GetStats returns the telemetry of the last GetL7Stats call
+func (m *Monitor) GetStats() map[string]int64 {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped || m.telemetrySnapshot == nil {
+		return nil
+	}
+
+	return map[string]int64{
+		"l7_requests_dropped":   m.telemetrySnapshot.dropped,
+		"l7_conns_dropped":      m.telemetrySnapshot.connsDropped,
+		"l7_conns_unclassified": m.telemetrySnapshot.unclassified,
+		"l7_conns_encrypted":    m.telemetrySnapshot.encrypted,
+		"l7_parse_errors":       m.telemetrySnapshot.parseErrors,
+		"l7_kafka_requests":     m.telemetrySnapshot.requests[ProtocolKafka],
+		"l7_postgres_requests":  m.telemetrySnapshot.requests[ProtocolPostgres],
+		"l7_redis_requests":     m.telemetrySnapshot.requests[ProtocolRedis],
+		"l7_tracked_conns":      int64(len(m.tracker.conns)),
+	}
+}
+
+// Stop L7 monitoring
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.mux.Lock()
+	if m.stopped {
+		m.mux.Unlock()
+		return
+	}
+	m.stopped = true
+	m.mux.Unlock()
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package protocols
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+)
+
+// NewMonitor returns a new Monitor instance, reading the traffic of the configured ports from a raw socket
+func NewMonitor(c *config.Config) (*Monitor, error) {
+	serverPorts := ports(c)
+	if len(serverPorts) == 0 {
+		return nil, errors.New("no kafka, postgres or redis port configured")
+	}
+	portList := make([]uint16, 0, len(serverPorts))
+	for p := range serverPorts {
+		portList = append(portList, p)
+	}
+	sort.Slice(portList, func(i, j int) bool { return portList[i] < portList[j] })
+
+	filter, err := portFilter(portList)
+	if err != nil {
+		return nil, err
+	}
+	rawFilter, err := bpf.Assemble(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error assembling socket filter: %s", err)
+	}
+
+	source, err := newAFPacketSource(rawFilter)
+	if err != nil {
+		return nil, err
+	}
+	return newMonitor(c, source), nil
+}
+
+// afPacketSource reads the packets of a raw socket filtered by a classic BPF program
+type afPacketSource struct {
+	*afpacket.TPacket
+}
+
+func newAFPacketSource(filter []bpf.RawInstruction) (*afPacketSource, error) {
+	rawSocket, err := afpacket.NewTPacket(
+		afpacket.OptPollTimeout(1*time.Second),
+		// This setup will require ~4Mb that is mmap'd into the process virtual space
+		// More information here: https://www.kernel.org/doc/Documentation/networking/packet_mmap.txt
+		afpacket.OptFrameSize(4096),
+		afpacket.OptBlockSize(4096*128),
+		afpacket.OptNumBlocks(8),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating raw socket: %s", err)
+	}
+
+	if err := rawSocket.SetBPF(filter); err != nil {
+		rawSocket.Close()
+		return nil, fmt.Errorf("error attaching socket filter: %s", err)
+	}
+	return &afPacketSource{TPacket: rawSocket}, nil
+}
+
+func (p *afPacketSource) VisitPackets(exit <-chan struct{}, visit func([]byte, time.Time) error) error {
+	for {
+		// allow the read loop to be prematurely interrupted
+		select {
+		case <-exit:
+			return nil
+		default:
+		}
+
+		data, stats, err := p.ZeroCopyReadPacketData()
+
+		// Immediately retry for EAGAIN
+		if err == syscall.EAGAIN {
+			continue
+		}
+
+		if err == afpacket.ErrTimeout {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := visit(data, stats.Timestamp); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *afPacketSource) PacketType() gopacket.LayerType {
+	return layers.LayerTypeEthernet
+}
+
+func (p *afPacketSource) Close() {
+	p.TPacket.Close()
+}
@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type packet struct {
+	data []byte
+	ts   time.Time
+}
+
+// readPCAP returns the packets of a pcap file of the testdata directory
+func readPCAP(t *testing.T, name string) []packet {
+	f, err := os.Open("testdata/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := pcapgo.NewReader(f)
+	require.NoError(t, err)
+	require.Equal(t, layers.LinkTypeEthernet, r.LinkType())
+
+	var packets []packet
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF {
+			return packets
+		}
+		require.NoError(t, err)
+		packets = append(packets, packet{data: data, ts: ci.Timestamp})
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		EnableL7Monitoring: true,
+		KafkaPorts:         []uint16{9092},
+		PostgresPorts:      []uint16{5432},
+		RedisPorts:         []uint16{6379},
+		MaxL7StatsBuffered: 1000,
+	}
+}
+
+// replayPCAP feeds the packets of a pcap file to a connection tracker, and returns the resulting stats
+func replayPCAP(t *testing.T, name string) (map[Key]RequestStats, *telemetry) {
+	telemetry := newTelemetry()
+	statkeeper := newStatKeeper(1000, telemetry)
+	tracker := newConnTracker(ports(testConfig()), maxTrackedConns, statkeeper, telemetry)
+	decoder := newPacketDecoder(layers.LayerTypeEthernet)
+
+	var s segment
+	for _, p := range readPCAP(t, name) {
+		require.NoError(t, decoder.decode(p.data, &s))
+		tracker.process(&s, p.ts)
+	}
+	require.Empty(t, tracker.conns, "the connections should be removed once closed")
+	return statkeeper.getAndResetAllStats(), telemetry
+}
+
+// pcapSource is a packetSource replaying the packets of a pcap file once
+type pcapSource struct {
+	packets []packet
+	done    chan struct{}
+}
+
+func (p *pcapSource) VisitPackets(exit <-chan struct{}, visit func([]byte, time.Time) error) error {
+	for len(p.packets) > 0 {
+		packet := p.packets[0]
+		p.packets = p.packets[1:]
+		if err := visit(packet.data, packet.ts); err != nil {
+			return err
+		}
+		if len(p.packets) == 0 {
+			close(p.done)
+		}
+	}
+	return nil
+}
+
+func (p *pcapSource) PacketType() gopacket.LayerType {
+	return layers.LayerTypeEthernet
+}
+
+func (p *pcapSource) Close() {}
+
+const ms = float64(time.Millisecond)
+
+func addr(ip string) util.Address {
+	return util.AddressFromString(ip)
+}
+
+func requireStats(t *testing.T, stats map[Key]RequestStats, key Key, count, errors int, latency float64) {
+	s, ok := stats[key]
+	require.Truef(t, ok, "missing stats for %s %s %q", key.Protocol, key.Operation, key.Resource)
+	assert.Equalf(t, count, s.Count, "%s %s %q", key.Protocol, key.Operation, key.Resource)
+	assert.Equalf(t, errors, s.ErrorCount, "%s %s %q", key.Protocol, key.Operation, key.Resource)
+	if s.Latencies == nil {
+		assert.Equalf(t, latency, s.FirstLatencySample, "%s %s %q", key.Protocol, key.Operation, key.Resource)
+	} else {
+		p50, err := s.Latencies.GetValueAtQuantile(0.5)
+		require.NoError(t, err)
+		assert.InEpsilonf(t, latency, p50, RelativeAccuracy*2, "%s %s %q", key.Protocol, key.Operation, key.Resource)
+	}
+}
+
+func TestKafkaPCAP(t *testing.T) {
+	stats, telemetry := replayPCAP(t, "kafka.pcap")
+
+	key := func(operation, resource string) Key {
+		return NewKey(addr("10.0.0.1"), addr("10.0.0.2"), 40000, 9092, ProtocolKafka, operation, resource)
+	}
+	assert.Len(t, stats, 5)
+	requireStats(t, stats, key("Metadata", ""), 1, 0, 1*ms)
+	requireStats(t, stats, key("Produce", "orders"), 2, 0, 2*ms)
+	requireStats(t, stats, key("Produce", "payments"), 1, 0, 3*ms)
+	// acks=0, the broker doesn't answer
+	requireStats(t, stats, key("Produce", "logs"), 1, 0, 0)
+	requireStats(t, stats, key("Fetch", "orders"), 1, 0, 5*ms)
+
+	assert.Equal(t, int64(1), telemetry.classified[ProtocolKafka])
+	assert.Zero(t, telemetry.parseErrors)
+}
+
+func TestPostgresPCAP(t *testing.T) {
+	stats, telemetry := replayPCAP(t, "postgres.pcap")
+
+	key := func(operation string) Key {
+		return NewKey(addr("fd00::1"), addr("fd00::2"), 41000, 5432, ProtocolPostgres, operation, "")
+	}
+	assert.Len(t, stats, 3)
+	requireStats(t, stats, key("SELECT"), 1, 0, 2*ms)
+	requireStats(t, stats, key("INSERT"), 1, 1, 1*ms)
+	// prepared statement executed twice
+	requireStats(t, stats, key("UPDATE"), 2, 0, 3*ms)
+
+	assert.Equal(t, int64(1), telemetry.classified[ProtocolPostgres])
+	assert.Zero(t, telemetry.parseErrors)
+}
+
+func TestRedisPCAP(t *testing.T) {
+	stats, telemetry := replayPCAP(t, "redis.pcap")
+
+	key := func(operation string) Key {
+		return NewKey(addr("10.0.0.1"), addr("10.0.0.3"), 42000, 6379, ProtocolRedis, operation, "")
+	}
+	assert.Len(t, stats, 6)
+	requireStats(t, stats, key("SET"), 1, 0, 1*ms)
+	requireStats(t, stats, key("GET"), 1, 0, 1*ms)
+	requireStats(t, stats, key("INCR"), 2, 0, 2*ms)
+	requireStats(t, stats, key("BOGUS"), 1, 1, 1*ms)
+	requireStats(t, stats, key("LRANGE"), 1, 0, 4*ms)
+	// the retransmitted command is counted once
+	requireStats(t, stats, key("PING"), 1, 0, 1*ms)
+
+	assert.Equal(t, int64(1), telemetry.classified[ProtocolRedis])
+	assert.Zero(t, telemetry.parseErrors)
+}
+
+func TestMonitor(t *testing.T) {
+	var packets []packet
+	for _, name := range []string{"kafka.pcap", "postgres.pcap", "redis.pcap"} {
+		packets = append(packets, readPCAP(t, name)...)
+	}
+	source := &pcapSource{packets: packets, done: make(chan struct{})}
+
+	monitor := newMonitor(testConfig(), source)
+	defer monitor.Stop()
+	assert.Nil(t, monitor.GetStats())
+
+	select {
+	case <-source.done:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out replaying the packets")
+	}
+
+	stats := monitor.GetL7Stats()
+	assert.Len(t, stats, 14)
+	telemetry := monitor.GetStats()
+	assert.Equal(t, int64(6), telemetry["l7_kafka_requests"])
+	assert.Equal(t, int64(4), telemetry["l7_postgres_requests"])
+	assert.Equal(t, int64(7), telemetry["l7_redis_requests"])
+	assert.Zero(t, telemetry["l7_tracked_conns"])
+
+	// the stats are reset on each call
+	assert.Empty(t, monitor.GetL7Stats())
+
+	monitor.Stop()
+	assert.Nil(t, monitor.GetL7Stats())
+}
+
+func TestNilMonitor(t *testing.T) {
+	var monitor *Monitor
+	assert.Nil(t, monitor.GetL7Stats())
+	assert.Nil(t, monitor.GetStats())
+	monitor.Stop()
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var errNotTCP = errors.New("not a TCP packet")
+
+// segment is a TCP segment read from a packet
+type segment struct {
+	saddr, daddr util.Address
+	sport, dport uint16
+	seq          uint32
+	syn, fin     bool
+	rst          bool
+	payload      []byte
+}
+
+// packetDecoder decodes the TCP segments of raw packets
+type packetDecoder struct {
+	decoder *gopacket.DecodingLayerParser
+	decoded []gopacket.LayerType
+	ipv4    *layers.IPv4
+	ipv6    *layers.IPv6
+	tcp     *layers.TCP
+}
+
+func newPacketDecoder(layerType gopacket.LayerType) *packetDecoder {
+	d := &packetDecoder{
+		ipv4: &layers.IPv4{},
+		ipv6: &layers.IPv6{},
+		tcp:  &layers.TCP{},
+	}
+	var payload gopacket.Payload
+	d.decoder = gopacket.NewDecodingLayerParser(layerType, &layers.Ethernet{}, d.ipv4, d.ipv6, d.tcp, &payload)
+	d.decoder.IgnoreUnsupported = true
+	return d
+}
+
+// decode decodes the TCP segment of a packet. The payload of the segment points to data.
+func (d *packetDecoder) decode(data []byte, s *segment) error {
+	if err := d.decoder.DecodeLayers(data, &d.decoded); err != nil {
+		return err
+	}
+
+	var hasIP, hasTCP bool
+	for _, layer := range d.decoded {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			s.saddr = util.V4AddressFromBytes(d.ipv4.SrcIP)
+			s.daddr = util.V4AddressFromBytes(d.ipv4.DstIP)
+			hasIP = true
+		case layers.LayerTypeIPv6:
+			s.saddr = util.V6AddressFromBytes(d.ipv6.SrcIP)
+			s.daddr = util.V6AddressFromBytes(d.ipv6.DstIP)
+			hasIP = true
+		case layers.LayerTypeTCP:
+			hasTCP = true
+		}
+	}
+	if !hasIP || !hasTCP {
+		return errNotTCP
+	}
+
+	s.sport = uint16(d.tcp.SrcPort)
+	s.dport = uint16(d.tcp.DstPort)
+	s.seq = d.tcp.Seq
+	s.syn = d.tcp.SYN
+	s.fin = d.tcp.FIN
+	s.rst = d.tcp.RST
+	s.payload = d.tcp.Payload
+	return nil
+}
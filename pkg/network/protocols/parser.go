@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// maxPendingRequests is the maximum number of requests of a connection waiting for their response
+const maxPendingRequests = 128
+
+// errEncrypted is returned when the connection switches to TLS, its traffic can't be parsed anymore
+var errEncrypted = errors.New("encrypted connection")
+
+// reportFunc records a request of a connection. latency is in nanoseconds, zero when the response wasn't seen.
+type reportFunc func(operation, resource string, latency float64, isError bool)
+
+// protocolParser parses the messages of a connection. Both methods have the consumeFunc semantics.
+type protocolParser interface {
+	parseRequest(buf []byte, ts time.Time) (int, error)
+	parseResponse(buf []byte, ts time.Time) (int, error)
+}
+
+// protocolDetector returns whether the first payload sent by a client belongs to a protocol
+type protocolDetector struct {
+	protocol  ProtocolType
+	detect    func(payload []byte) bool
+	newParser func(report reportFunc) protocolParser
+}
+
+var detectors = []protocolDetector{
+	{protocol: ProtocolKafka, detect: isKafkaRequest, newParser: newKafkaParser},
+	{protocol: ProtocolPostgres, detect: isPostgresRequest, newParser: newPostgresParser},
+	{protocol: ProtocolRedis, detect: isRedisRequest, newParser: newRedisParser},
+}
+
+// latencySince returns the latency of a response received at ts, in nanoseconds
+func latencySince(start, ts time.Time) float64 {
+	if latency := ts.Sub(start); latency > 0 {
+		return float64(latency.Nanoseconds())
+	}
+	return 0
+}
+
+// reader reads big endian values from a message, it reports truncated reads instead of panicking
+type reader struct {
+	buf []byte
+	off int
+	err bool
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err || n < 0 || r.off+n > len(r.buf) {
+		r.err = true
+		return nil
+	}
+	b := r.buf[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) int8() int8 {
+	if b := r.bytes(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *reader) int16() int16 {
+	if b := r.bytes(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *reader) int32() int32 {
+	if b := r.bytes(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// cstring reads a null terminated string
+func (r *reader) cstring() string {
+	if r.err {
+		return ""
+	}
+	for i := r.off; i < len(r.buf); i++ {
+		if r.buf[i] == 0 {
+			s := string(r.buf[r.off:i])
+			r.off = i + 1
+			return s
+		}
+	}
+	r.err = true
+	return ""
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type report struct {
+	operation, resource string
+	isError             bool
+}
+
+func pgMessage(t byte, body ...[]byte) []byte {
+	payload := bytes.Join(body, nil)
+	msg := []byte{t, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], uint32(len(payload)+4))
+	return append(msg, payload...)
+}
+
+func TestParsersStreaming(t *testing.T) {
+	ready := pgMessage('Z', []byte("I"))
+
+	tests := []struct {
+		name      string
+		newParser func(reportFunc) protocolParser
+		request   []byte
+		response  []byte
+		expected  []report
+	}{
+		{
+			name:      "redis",
+			newParser: newRedisParser,
+			request:   []byte("*2\r\n$3\r\nget\r\n$3\r\nkey\r\nPING\r\n*3\r\n$4\r\nHSET\r\n$1\r\nh\r\n$40\r\n0123456789012345678901234567890123456789\r\n"),
+			response:  []byte("$3\r\nval\r\n-ERR wrong\r\n%1\r\n+f\r\n*2\r\n$1\r\na\r\n_\r\n"),
+			expected: []report{
+				{operation: "GET"},
+				{operation: "PING", isError: true},
+				{operation: "HSET"},
+			},
+		},
+		{
+			name:      "postgres",
+			newParser: newPostgresParser,
+			request: bytes.Join([][]byte{
+				pgMessage('Q', []byte("select * from t\x00")),
+				pgMessage('P', []byte("\x00delete from t\x00\x00\x00")),
+				pgMessage('B', []byte("\x00\x00\x00\x00\x00\x00\x00\x00")),
+				pgMessage('E', []byte("\x00\x00\x00\x00\x00")),
+				pgMessage('S'),
+			}, nil),
+			response: bytes.Join([][]byte{
+				pgMessage('T', make([]byte, 20)), pgMessage('D', make([]byte, 300)), pgMessage('C', []byte("SELECT 1\x00")), ready,
+				pgMessage('1'), pgMessage('2'), pgMessage('E', []byte("SERROR\x00\x00")), ready,
+			}, nil),
+			expected: []report{
+				{operation: "SELECT"},
+				{operation: "DELETE", isError: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reports []report
+			parser := test.newParser(func(operation, resource string, latency float64, isError bool) {
+				reports = append(reports, report{operation: operation, resource: resource, isError: isError})
+			})
+
+			// the messages are received one byte at a time
+			var requests, responses stream
+			now := time.Now()
+			for i := range test.request {
+				require.NoError(t, requests.feed(test.request[i:i+1], now, parser.parseRequest))
+			}
+			for i := range test.response {
+				require.NoError(t, responses.feed(test.response[i:i+1], now, parser.parseResponse))
+			}
+			assert.Equal(t, test.expected, reports)
+		})
+	}
+}
+
+func TestRedisSubscribe(t *testing.T) {
+	var operations []string
+	parser := newRedisParser(func(operation, resource string, latency float64, isError bool) {
+		operations = append(operations, operation)
+	})
+
+	var requests, responses stream
+	now := time.Now()
+	require.NoError(t, requests.feed([]byte("*2\r\n$9\r\nSUBSCRIBE\r\n$4\r\nnews\r\n"), now, parser.parseRequest))
+	require.NoError(t, responses.feed([]byte("*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$2\r\nhi\r\n"), now, parser.parseResponse))
+	require.NoError(t, requests.feed([]byte("*1\r\n$4\r\nPING\r\n"), now, parser.parseRequest))
+
+	assert.Equal(t, []string{"SUBSCRIBE", "PING"}, operations)
+}
+
+func TestMalformedStream(t *testing.T) {
+	parser := newRedisParser(func(operation, resource string, latency float64, isError bool) {})
+	var requests stream
+	assert.Equal(t, errMalformed, requests.feed([]byte("*x\r\n"), time.Now(), parser.parseRequest))
+	assert.Equal(t, errBufferFull, requests.feed(bytes.Repeat([]byte("a"), maxBufferedBytes+1), time.Now(), func([]byte, time.Time) (int, error) {
+		return 0, nil
+	}))
+}
+
+func TestDetectors(t *testing.T) {
+	kafkaHeader := []byte{0, 0, 0, 20, 0, 3, 0, 1, 0, 0, 0, 7, 0, 4, 't', 'e', 's', 't'}
+	assert.True(t, isKafkaRequest(kafkaHeader))
+	assert.False(t, isRedisRequest(kafkaHeader))
+	assert.False(t, isPostgresRequest(kafkaHeader))
+
+	query := pgMessage('Q', []byte("SELECT 1\x00"))
+	assert.True(t, isPostgresRequest(query))
+	assert.False(t, isKafkaRequest(query))
+	assert.False(t, isRedisRequest(query))
+
+	command := []byte("*1\r\n$4\r\nPING\r\n")
+	assert.True(t, isRedisRequest(command))
+	assert.False(t, isKafkaRequest(command))
+	assert.False(t, isPostgresRequest(command))
+
+	tls := []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01, 0x00, 0x01, 0xfc, 0x03, 0x03, 0x00, 0x00, 0x00}
+	assert.False(t, isKafkaRequest(tls))
+	assert.False(t, isRedisRequest(tls))
+	assert.False(t, isPostgresRequest(tls))
+}
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+const (
+	postgresProtocolVersion3 = 196608
+	postgresSSLRequestCode   = 80877103
+	postgresGSSENCRequest    = 80877104
+	postgresCancelRequest    = 80877102
+
+	// postgresMaxStartupSize is the maximum size of a startup message accepted by PostgreSQL servers
+	postgresMaxStartupSize = 10000
+	postgresMaxMessageSize = 1024 * 1024 * 1024
+	// postgresPrefixSize is the number of bytes of a query parsed to find its command
+	postgresPrefixSize = 256
+	// postgresMaxStatements is the maximum number of prepared statements and portals tracked per connection
+	postgresMaxStatements = 1024
+	// postgresMaxCommandLength is the length of the longest command keyword
+	postgresMaxCommandLength = 16
+)
+
+// isPostgresRequest returns whether the payload starts with a startup message, or with a query when the monitoring
+// started after the startup of the connection
+func isPostgresRequest(payload []byte) bool {
+	if len(payload) < 8 {
+		return false
+	}
+
+	size := int32(binary.BigEndian.Uint32(payload[0:4]))
+	switch code := binary.BigEndian.Uint32(payload[4:8]); code {
+	case postgresSSLRequestCode, postgresGSSENCRequest:
+		return size == 8
+	case postgresCancelRequest:
+		return size == 16
+	case postgresProtocolVersion3:
+		return size > 8 && size <= postgresMaxStartupSize
+	}
+
+	size = int32(binary.BigEndian.Uint32(payload[1:5]))
+	if payload[0] != 'Q' && payload[0] != 'P' || size <= 4 || size > postgresMaxMessageSize {
+		return false
+	}
+	// queries usually start with a keyword
+	c := payload[5]
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == ' ' || c == '(' || c == '\n' || c == '\t'
+}
+
+type postgresRequestKind uint8
+
+const (
+	postgresSimpleQuery postgresRequestKind = iota
+	postgresExtendedQuery
+	postgresSync
+)
+
+type postgresRequest struct {
+	kind    postgresRequestKind
+	command string
+	start   time.Time
+	isError bool
+}
+
+// postgresParser parses the frontend and backend messages of a PostgreSQL connection. Simple queries complete with
+// the ReadyForQuery message following them, while the Execute messages of the extended query protocol complete
+// with their CommandComplete message.
+type postgresParser struct {
+	report reportFunc
+
+	startupDone bool
+	// sslRequested is set when the client asked to switch to TLS, the server answers with a single byte
+	sslRequested bool
+
+	// statements maps the prepared statements to their command, portals maps the portals to their command
+	statements map[string]string
+	portals    map[string]string
+
+	pending []postgresRequest
+}
+
+func newPostgresParser(report reportFunc) protocolParser {
+	return &postgresParser{
+		report:     report,
+		statements: make(map[string]string),
+		portals:    make(map[string]string),
+	}
+}
+
+func (p *postgresParser) parseRequest(buf []byte, ts time.Time) (int, error) {
+	if !p.startupDone {
+		if len(buf) < 8 {
+			return 0, nil
+		}
+		size := int(int32(binary.BigEndian.Uint32(buf[0:4])))
+		switch code := binary.BigEndian.Uint32(buf[4:8]); code {
+		case postgresSSLRequestCode, postgresGSSENCRequest:
+			p.sslRequested = true
+			return 8, nil
+		case postgresCancelRequest:
+			return 16, nil
+		case postgresProtocolVersion3:
+			if size <= 8 || size > postgresMaxStartupSize {
+				return 0, errMalformed
+			}
+			p.startupDone = true
+			return size, nil
+		}
+		// the monitoring started after the startup of the connection
+		p.startupDone = true
+	}
+
+	if len(buf) < 5 {
+		return 0, nil
+	}
+	messageType := buf[0]
+	size := int(int32(binary.BigEndian.Uint32(buf[1:5])))
+	if size < 4 || size > postgresMaxMessageSize {
+		return 0, errMalformed
+	}
+	total := size + 1
+
+	switch messageType {
+	case 'Q', 'P', 'B', 'E':
+	case 'S':
+		p.enqueue(postgresRequest{kind: postgresSync})
+		return total, nil
+	default:
+		return total, nil
+	}
+
+	prefix := total
+	if prefix > 5+postgresPrefixSize {
+		prefix = 5 + postgresPrefixSize
+	}
+	if len(buf) < prefix {
+		return 0, nil
+	}
+	r := reader{buf: buf[5:prefix]}
+
+	switch messageType {
+	case 'Q':
+		p.enqueue(postgresRequest{kind: postgresSimpleQuery, command: postgresCommand(r.buf), start: ts})
+	case 'P':
+		name := r.cstring()
+		if r.err {
+			break
+		}
+		if len(p.statements) >= postgresMaxStatements {
+			p.statements = make(map[string]string)
+		}
+		p.statements[name] = postgresCommand(r.buf[r.off:])
+	case 'B':
+		portal := r.cstring()
+		statement := r.cstring()
+		if r.err {
+			break
+		}
+		if len(p.portals) >= postgresMaxStatements {
+			p.portals = make(map[string]string)
+		}
+		p.portals[portal] = p.statements[statement]
+	case 'E':
+		portal := r.cstring()
+		p.enqueue(postgresRequest{kind: postgresExtendedQuery, command: p.portals[portal], start: ts})
+	}
+	return total, nil
+}
+
+func (p *postgresParser) enqueue(request postgresRequest) {
+	if len(p.pending) >= maxPendingRequests {
+		p.complete(p.pending[0], time.Time{})
+		p.pending = p.pending[1:]
+	}
+	p.pending = append(p.pending, request)
+}
+
+func (p *postgresParser) complete(request postgresRequest, ts time.Time) {
+	if request.kind == postgresSync {
+		return
+	}
+	var latency float64
+	if !ts.IsZero() {
+		latency = latencySince(request.start, ts)
+	}
+	p.report(request.command, "", latency, request.isError)
+}
+
+func (p *postgresParser) parseResponse(buf []byte, ts time.Time) (int, error) {
+	if p.sslRequested {
+		if len(buf) < 1 {
+			return 0, nil
+		}
+		p.sslRequested = false
+		if buf[0] == 'S' || buf[0] == 'G' {
+			return 0, errEncrypted
+		}
+		return 1, nil
+	}
+
+	if len(buf) < 5 {
+		return 0, nil
+	}
+	messageType := buf[0]
+	size := int(int32(binary.BigEndian.Uint32(buf[1:5])))
+	if size < 4 || size > postgresMaxMessageSize {
+		return 0, errMalformed
+	}
+
+	switch messageType {
+	case 'C', 'I', 's': // CommandComplete, EmptyQueryResponse, PortalSuspended
+		if len(p.pending) > 0 && p.pending[0].kind == postgresExtendedQuery {
+			p.complete(p.pending[0], ts)
+			p.pending = p.pending[1:]
+		}
+	case 'E': // ErrorResponse
+		if len(p.pending) > 0 {
+			switch p.pending[0].kind {
+			case postgresSimpleQuery:
+				p.pending[0].isError = true
+			case postgresExtendedQuery:
+				p.pending[0].isError = true
+				p.complete(p.pending[0], ts)
+				p.pending = p.pending[1:]
+			}
+		}
+	case 'Z': // ReadyForQuery
+		for len(p.pending) > 0 {
+			request := p.pending[0]
+			p.pending = p.pending[1:]
+			if request.kind == postgresSync {
+				break
+			}
+			if request.kind == postgresSimpleQuery {
+				p.complete(request, ts)
+				break
+			}
+			// the server skips the Execute messages following an error until the next Sync
+		}
+	}
+	return size + 1, nil
+}
+
+// postgresCommand returns the first keyword of a query
+func postgresCommand(query []byte) string {
+	start := 0
+	for start < len(query) && (query[start] == ' ' || query[start] == '\t' || query[start] == '\n' || query[start] == '\r' || query[start] == '(') {
+		start++
+	}
+	end := start
+	for end < len(query) && end-start < postgresMaxCommandLength {
+		c := query[end]
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			break
+		}
+		end++
+	}
+	return strings.ToUpper(string(query[start:end]))
+}
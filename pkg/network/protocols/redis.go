@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisMaxLineLength is the maximum length of a RESP type line, bulk strings are read separately
+	redisMaxLineLength = 4096
+	// redisMaxCommandLength is the number of bytes of the first bulk string of a request read as the command
+	redisMaxCommandLength = 32
+	redisMaxAggregateSize = 1024 * 1024
+)
+
+// the commands after which the server pushes messages which don't answer any request
+var redisPushCommands = map[string]struct{}{
+	"SUBSCRIBE":  {},
+	"PSUBSCRIBE": {},
+	"SSUBSCRIBE": {},
+	"MONITOR":    {},
+}
+
+// isRedisRequest returns whether the payload starts with a RESP array of bulk strings, which is how clients send
+// their commands
+func isRedisRequest(payload []byte) bool {
+	if len(payload) < 4 || payload[0] != '*' {
+		return false
+	}
+	end := bytes.Index(payload, []byte("\r\n"))
+	if end < 2 {
+		return false
+	}
+	count, err := strconv.Atoi(string(payload[1:end]))
+	if err != nil || count <= 0 || count > redisMaxAggregateSize {
+		return false
+	}
+	return len(payload) == end+2 || payload[end+2] == '$'
+}
+
+// respReader reads RESP values one token at a time: type lines are buffered, while bulk strings are skipped
+// without buffering them
+type respReader struct {
+	// remaining holds the number of elements left in each of the aggregates being read
+	remaining []int
+	// bulkSize is the size of the bulk string expected next, -1 when a type line is expected
+	bulkSize int
+	// isError is set when the value being read is an error
+	isError bool
+	// first holds the first bulk string or simple value of the value being read, truncated
+	first string
+	// inline is set when the value being read is an inline command
+	inline bool
+}
+
+func newRESPReader() respReader {
+	return respReader{bulkSize: -1}
+}
+
+// next reads the token at the start of buf. It returns the size of the token, and whether it completed a top level
+// value.
+func (r *respReader) next(buf []byte, isRequest bool) (int, bool, error) {
+	if r.bulkSize >= 0 {
+		total := r.bulkSize + 2
+		if r.first == "" && len(r.remaining) > 0 {
+			need := total
+			if r.bulkSize > redisMaxCommandLength {
+				need = redisMaxCommandLength
+			}
+			if len(buf) < need {
+				return 0, false, nil
+			}
+			end := r.bulkSize
+			if end > redisMaxCommandLength {
+				end = redisMaxCommandLength
+			}
+			r.first = string(buf[:end])
+		}
+		r.bulkSize = -1
+		return total, r.elementDone(), nil
+	}
+
+	end := bytes.Index(buf, []byte("\r\n"))
+	if end < 0 {
+		if len(buf) > redisMaxLineLength {
+			return 0, false, errMalformed
+		}
+		return 0, false, nil
+	}
+	if end == 0 {
+		return 0, false, errMalformed
+	}
+	line := buf[:end]
+	total := end + 2
+
+	if len(r.remaining) == 0 {
+		r.isError = false
+		r.first = ""
+		r.inline = false
+	}
+
+	switch line[0] {
+	case '+', ':', '_', ',', '#', '(':
+		return total, r.elementDone(), nil
+	case '-':
+		if len(r.remaining) == 0 {
+			r.isError = true
+		}
+		return total, r.elementDone(), nil
+	case '$', '!', '=':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return 0, false, errMalformed
+		}
+		if line[0] == '!' && len(r.remaining) == 0 {
+			r.isError = true
+		}
+		if size < 0 {
+			return total, r.elementDone(), nil
+		}
+		r.bulkSize = size
+		return total, false, nil
+	case '*', '~', '>', '%', '|':
+		count, err := strconv.Atoi(string(line[1:]))
+		if err != nil || count > redisMaxAggregateSize {
+			return 0, false, errMalformed
+		}
+		if line[0] == '%' || line[0] == '|' {
+			count *= 2
+		}
+		if count <= 0 {
+			return total, r.elementDone(), nil
+		}
+		r.remaining = append(r.remaining, count)
+		return total, false, nil
+	}
+
+	if isRequest && len(r.remaining) == 0 {
+		// inline commands are sent as a line of space separated arguments
+		r.inline = true
+		r.first = string(bytes.SplitN(line, []byte(" "), 2)[0])
+		return total, true, nil
+	}
+	return 0, false, errMalformed
+}
+
+// elementDone records the end of a value, it returns whether it was a top level value
+func (r *respReader) elementDone() bool {
+	for len(r.remaining) > 0 {
+		top := len(r.remaining) - 1
+		r.remaining[top]--
+		if r.remaining[top] > 0 {
+			return false
+		}
+		r.remaining = r.remaining[:top]
+	}
+	return true
+}
+
+type redisRequest struct {
+	command string
+	start   time.Time
+}
+
+// redisParser parses the commands and replies of a Redis connection. Redis answers the commands of a connection in
+// order, including pipelined commands.
+type redisParser struct {
+	report reportFunc
+
+	requests  respReader
+	responses respReader
+	pending   []redisRequest
+	// pushMode is set once the connection subscribed to channels, the server then pushes messages which don't
+	// answer any command
+	pushMode bool
+}
+
+func newRedisParser(report reportFunc) protocolParser {
+	return &redisParser{
+		report:    report,
+		requests:  newRESPReader(),
+		responses: newRESPReader(),
+	}
+}
+
+func (p *redisParser) parseRequest(buf []byte, ts time.Time) (int, error) {
+	n, done, err := p.requests.next(buf, true)
+	if err != nil || !done {
+		return n, err
+	}
+
+	command := strings.ToUpper(p.requests.first)
+	if _, ok := redisPushCommands[command]; ok {
+		p.pushMode = true
+	}
+	if p.pushMode {
+		p.report(command, "", 0, false)
+		return n, nil
+	}
+
+	if len(p.pending) >= maxPendingRequests {
+		p.report(p.pending[0].command, "", 0, false)
+		p.pending = p.pending[1:]
+	}
+	p.pending = append(p.pending, redisRequest{command: command, start: ts})
+	return n, nil
+}
+
+func (p *redisParser) parseResponse(buf []byte, ts time.Time) (int, error) {
+	if p.pushMode {
+		// skip the rest of the connection, its messages can't be matched with the commands anymore
+		return len(buf), nil
+	}
+
+	n, done, err := p.responses.next(buf, false)
+	if err != nil || !done {
+		return n, err
+	}
+	if len(p.pending) > 0 {
+		request := p.pending[0]
+		p.pending = p.pending[1:]
+		p.report(request.command, "", latencySince(request.start, ts), p.responses.isError)
+	}
+	return n, nil
+}
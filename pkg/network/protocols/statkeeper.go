@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"sync/atomic"
+)
+
+type statKeeper struct {
+	stats      map[Key]RequestStats
+	maxEntries int
+	telemetry  *telemetry
+
+	// map containing interned operation and resource strings
+	// this is rotated with the stats map
+	interned map[string]string
+}
+
+func newStatKeeper(maxEntries int, telemetry *telemetry) *statKeeper {
+	return &statKeeper{
+		stats:      make(map[Key]RequestStats),
+		maxEntries: maxEntries,
+		telemetry:  telemetry,
+		interned:   make(map[string]string),
+	}
+}
+
+func (s *statKeeper) add(key Key, latency float64, isError bool) {
+	atomic.AddInt64(&s.telemetry.requests[key.Protocol], 1)
+
+	key.Operation = s.intern(key.Operation)
+	key.Resource = s.intern(key.Resource)
+	stats, ok := s.stats[key]
+	if !ok && len(s.stats) >= s.maxEntries {
+		atomic.AddInt64(&s.telemetry.dropped, 1)
+		return
+	}
+
+	stats.AddRequest(latency, isError)
+	s.stats[key] = stats
+	atomic.StoreInt64(&s.telemetry.aggregations, int64(len(s.stats)))
+}
+
+func (s *statKeeper) getAndResetAllStats() map[Key]RequestStats {
+	ret := s.stats // No deep copy needed since `s.stats` gets reset
+	s.stats = make(map[Key]RequestStats)
+	s.interned = make(map[string]string)
+	return ret
+}
+
+func (s *statKeeper) intern(v string) string {
+	if v == "" {
+		return v
+	}
+	interned, ok := s.interned[v]
+	if !ok {
+		s.interned[v] = v
+		return v
+	}
+	return interned
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/sketches-go/ddsketch"
+)
+
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+const RelativeAccuracy = 0.01
+
+// RequestStats stores the stats of the requests of a Key
+type RequestStats struct {
+	// Count is the number of requests, errors included. As for HTTP, we keep our own count as the sketch may discard
+	// some latency values.
+	Count int
+	// ErrorCount is the number of requests which got an error response
+	ErrorCount int
+	// Latencies holds the latencies of the requests, in nanoseconds. It is nil until a second request is recorded.
+	Latencies *ddsketch.DDSketch
+	// FirstLatencySample holds the latency of the first request, to avoid creating sketches with a single value
+	FirstLatencySample float64
+}
+
+// AddRequest records a request, a zero latency means the response wasn't seen
+func (r *RequestStats) AddRequest(latency float64, isError bool) {
+	r.Count++
+	if isError {
+		r.ErrorCount++
+	}
+
+	if r.Count == 1 {
+		r.FirstLatencySample = latency
+		return
+	}
+
+	if r.Latencies == nil {
+		if err := r.initSketch(); err != nil {
+			return
+		}
+		r.addLatency(r.FirstLatencySample)
+	}
+	r.addLatency(latency)
+}
+
+// CombineWith merges the data of 2 RequestStats objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStats) CombineWith(newStats RequestStats) {
+	if newStats.Count == 0 {
+		return
+	}
+
+	if newStats.Count == 1 {
+		r.AddRequest(newStats.FirstLatencySample, newStats.ErrorCount > 0)
+		return
+	}
+
+	if r.Latencies == nil {
+		if err := r.initSketch(); err != nil {
+			return
+		}
+		if r.Count == 1 {
+			r.addLatency(r.FirstLatencySample)
+		}
+	}
+
+	r.Count += newStats.Count
+	r.ErrorCount += newStats.ErrorCount
+	if newStats.Latencies != nil {
+		if err := r.Latencies.MergeWith(newStats.Latencies); err != nil {
+			log.Debugf("error merging request latencies: %v", err)
+		}
+	}
+}
+
+func (r *RequestStats) addLatency(latency float64) {
+	if latency <= 0 {
+		return
+	}
+	if err := r.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add request latency to ddsketch: %v", err)
+	}
+}
+
+func (r *RequestStats) initSketch() (err error) {
+	r.Latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy)
+	if err != nil {
+		log.Debugf("error recording request latency: could not create new ddsketch: %v", err)
+	}
+	return
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"errors"
+	"time"
+)
+
+// maxBufferedBytes is the maximum number of bytes buffered per connection direction while waiting for the rest of
+// a message prefix. The parsers only need the first bytes of each message, the rest is skipped without buffering.
+const maxBufferedBytes = 16 * 1024
+
+var (
+	// errBufferFull is returned when a message prefix doesn't fit in the stream buffer
+	errBufferFull = errors.New("stream buffer full")
+	// errMalformed is returned by the parsers when the stream doesn't follow the protocol anymore, for instance
+	// after a lost segment
+	errMalformed = errors.New("malformed message")
+)
+
+// consumeFunc parses the message at the start of buf. It returns the total size of the message, which may be
+// larger than buf when the parser doesn't need the end of the message, or 0 when more bytes are needed.
+type consumeFunc func(buf []byte, ts time.Time) (int, error)
+
+// stream reassembles the payloads of one direction of a TCP connection into messages
+type stream struct {
+	buf []byte
+	// skip is the number of bytes of the current message which weren't received yet, and are discarded on arrival
+	skip int
+}
+
+// feed processes the payload of a segment, calling consume for each message it completes
+func (s *stream) feed(payload []byte, ts time.Time, consume consumeFunc) error {
+	if s.skip > 0 {
+		n := s.skip
+		if n > len(payload) {
+			n = len(payload)
+		}
+		s.skip -= n
+		payload = payload[n:]
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+
+	s.buf = append(s.buf, payload...)
+	offset := 0
+	for offset < len(s.buf) {
+		n, err := consume(s.buf[offset:], ts)
+		if err != nil {
+			s.reset()
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		if remaining := len(s.buf) - offset; n > remaining {
+			s.skip = n - remaining
+			offset = len(s.buf)
+			break
+		}
+		offset += n
+	}
+
+	// keep the incomplete message at the start of the buffer
+	s.buf = s.buf[:copy(s.buf, s.buf[offset:])]
+	if len(s.buf) > maxBufferedBytes {
+		s.reset()
+		return errBufferFull
+	}
+	return nil
+}
+
+func (s *stream) reset() {
+	s.buf = nil
+	s.skip = 0
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type telemetry struct {
+	then    int64
+	elapsed int64
+
+	requests     [ProtocolRedis + 1]int64
+	classified   [ProtocolRedis + 1]int64
+	unclassified int64 // this happens when a connection doesn't match any protocol
+	parseErrors  int64 // this happens when a lost segment or an unsupported message breaks the parsing of a stream
+	encrypted    int64 // this happens when a connection switches to TLS
+	dropped      int64 // this happens when the statkeeper reaches capacity
+	connsDropped int64 // this happens when the connection tracker reaches capacity
+	aggregations int64
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		then: time.Now().Unix(),
+	}
+}
+
+func (t *telemetry) reset() telemetry {
+	now := time.Now()
+	then := atomic.SwapInt64(&t.then, now.Unix())
+
+	delta := telemetry{
+		unclassified: atomic.SwapInt64(&t.unclassified, 0),
+		parseErrors:  atomic.SwapInt64(&t.parseErrors, 0),
+		encrypted:    atomic.SwapInt64(&t.encrypted, 0),
+		dropped:      atomic.SwapInt64(&t.dropped, 0),
+		connsDropped: atomic.SwapInt64(&t.connsDropped, 0),
+		aggregations: atomic.SwapInt64(&t.aggregations, 0),
+		elapsed:      now.Unix() - then,
+	}
+
+	for i := range t.requests {
+		delta.requests[i] = atomic.SwapInt64(&t.requests[i], 0)
+		delta.classified[i] = atomic.SwapInt64(&t.classified[i], 0)
+	}
+
+	return delta
+}
+
+func (t *telemetry) report() {
+	var totalRequests int64
+	for _, n := range t.requests {
+		totalRequests += n
+	}
+
+	log.Debugf(
+		"l7 stats summary: requests_processed=%d(%.2f/s) kafka_conns=%d postgres_conns=%d redis_conns=%d unclassified_conns=%d encrypted_conns=%d parse_errors=%d requests_dropped=%d conns_dropped=%d aggregations=%d",
+		totalRequests,
+		float64(totalRequests)/float64(t.elapsed),
+		t.classified[ProtocolKafka],
+		t.classified[ProtocolPostgres],
+		t.classified[ProtocolRedis],
+		t.unclassified,
+		t.encrypted,
+		t.parseErrors,
+		t.dropped,
+		t.connsDropped,
+		t.aggregations,
+	)
+}
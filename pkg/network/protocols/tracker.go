@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+const (
+	// maxClassificationAttempts is the number of client payloads checked before giving up on a connection
+	maxClassificationAttempts = 3
+	// connExpiry is the duration after which an idle connection is forgotten, when its FIN or RST wasn't seen
+	connExpiry = 2 * time.Minute
+	// expiryInterval is the interval between two scans of the idle connections
+	expiryInterval = 30 * time.Second
+)
+
+// connKey identifies a connection, indexed as (client, server)
+type connKey struct {
+	client, server         util.Address
+	clientPort, serverPort uint16
+}
+
+// direction of the segments of a connection
+const (
+	fromClient = iota
+	fromServer
+)
+
+type connState struct {
+	protocol ProtocolType
+	// failed is set when the connection couldn't be classified, or when its stream can't be parsed anymore
+	failed   bool
+	attempts int
+
+	parser  protocolParser
+	streams [2]stream
+	// nextSeq is the sequence number expected next in each direction, valid once seqKnown is set
+	nextSeq  [2]uint32
+	seqKnown [2]bool
+
+	lastSeen time.Time
+}
+
+// connTracker classifies the TCP connections from their first client payloads, and feeds the payloads of the
+// classified connections to their protocol parser
+type connTracker struct {
+	// ports maps the server ports to the protocol expected on them, which is checked first
+	ports      map[uint16]ProtocolType
+	conns      map[connKey]*connState
+	maxConns   int
+	statkeeper *statKeeper
+	telemetry  *telemetry
+	lastExpiry time.Time
+}
+
+func newConnTracker(ports map[uint16]ProtocolType, maxConns int, statkeeper *statKeeper, telemetry *telemetry) *connTracker {
+	return &connTracker{
+		ports:      ports,
+		conns:      make(map[connKey]*connState),
+		maxConns:   maxConns,
+		statkeeper: statkeeper,
+		telemetry:  telemetry,
+	}
+}
+
+// process handles a TCP segment captured at ts
+func (t *connTracker) process(s *segment, ts time.Time) {
+	t.expire(ts)
+
+	var key connKey
+	var dir int
+	if _, ok := t.ports[s.dport]; ok {
+		key = connKey{client: s.saddr, server: s.daddr, clientPort: s.sport, serverPort: s.dport}
+		dir = fromClient
+	} else if _, ok := t.ports[s.sport]; ok {
+		key = connKey{client: s.daddr, server: s.saddr, clientPort: s.dport, serverPort: s.sport}
+		dir = fromServer
+	} else {
+		return
+	}
+
+	conn, ok := t.conns[key]
+	if !ok {
+		if s.fin || s.rst {
+			return
+		}
+		if len(t.conns) >= t.maxConns {
+			atomic.AddInt64(&t.telemetry.connsDropped, 1)
+			return
+		}
+		conn = &connState{}
+		t.conns[key] = conn
+	}
+	conn.lastSeen = ts
+
+	if s.fin || s.rst {
+		delete(t.conns, key)
+		if len(s.payload) == 0 {
+			return
+		}
+	}
+	if conn.failed {
+		return
+	}
+
+	payload, ok := conn.ordered(dir, s)
+	if !ok {
+		t.fail(conn, &t.telemetry.parseErrors)
+		return
+	}
+	if len(payload) == 0 {
+		return
+	}
+
+	if conn.parser == nil {
+		if dir == fromServer {
+			// the connection is classified from the client payloads only
+			return
+		}
+		if !t.classify(key, conn, payload) {
+			return
+		}
+	}
+
+	consume := conn.parser.parseRequest
+	if dir == fromServer {
+		consume = conn.parser.parseResponse
+	}
+	if err := conn.streams[dir].feed(payload, ts, consume); err != nil {
+		if err == errEncrypted {
+			t.fail(conn, &t.telemetry.encrypted)
+		} else {
+			t.fail(conn, &t.telemetry.parseErrors)
+		}
+	}
+}
+
+// classify looks for the protocol of a connection, starting with the one expected on its server port
+func (t *connTracker) classify(key connKey, conn *connState, payload []byte) bool {
+	expected := t.ports[key.serverPort]
+	protocol := ProtocolUnknown
+	var newParser func(report reportFunc) protocolParser
+	for _, d := range detectors {
+		if d.protocol == expected && d.detect(payload) {
+			protocol, newParser = d.protocol, d.newParser
+			break
+		}
+	}
+	for i := 0; newParser == nil && i < len(detectors); i++ {
+		if d := detectors[i]; d.protocol != expected && d.detect(payload) {
+			protocol, newParser = d.protocol, d.newParser
+		}
+	}
+
+	if newParser == nil {
+		conn.attempts++
+		if conn.attempts >= maxClassificationAttempts {
+			t.fail(conn, &t.telemetry.unclassified)
+		}
+		return false
+	}
+
+	atomic.AddInt64(&t.telemetry.classified[protocol], 1)
+	conn.protocol = protocol
+	conn.parser = newParser(func(operation, resource string, latency float64, isError bool) {
+		k := NewKey(key.client, key.server, key.clientPort, key.serverPort, protocol, operation, resource)
+		t.statkeeper.add(k, latency, isError)
+	})
+	return true
+}
+
+func (t *connTracker) fail(conn *connState, counter *int64) {
+	atomic.AddInt64(counter, 1)
+	conn.failed = true
+	conn.parser = nil
+	conn.streams[fromClient].reset()
+	conn.streams[fromServer].reset()
+}
+
+// expire removes the idle connections
+func (t *connTracker) expire(now time.Time) {
+	if now.Sub(t.lastExpiry) < expiryInterval {
+		return
+	}
+	t.lastExpiry = now
+	for key, conn := range t.conns {
+		if now.Sub(conn.lastSeen) > connExpiry {
+			delete(t.conns, key)
+		}
+	}
+}
+
+// ordered returns the part of the payload of a segment which wasn't seen yet. It returns false when segments were
+// lost before this one.
+func (c *connState) ordered(dir int, s *segment) ([]byte, bool) {
+	seq := s.seq
+	if s.syn {
+		// the SYN flag uses a sequence number
+		seq++
+	}
+	end := seq + uint32(len(s.payload))
+
+	if !c.seqKnown[dir] {
+		c.seqKnown[dir] = true
+		c.nextSeq[dir] = end
+		return s.payload, true
+	}
+
+	expected := c.nextSeq[dir]
+	if diff := int32(seq - expected); diff > 0 {
+		return nil, false
+	} else if diff < 0 {
+		// retransmission, possibly with new data at the end
+		if int32(end-expected) <= 0 {
+			return nil, true
+		}
+		c.nextSeq[dir] = end
+		return s.payload[expected-seq:], true
+	}
+	c.nextSeq[dir] = end
+	return s.payload, true
+}
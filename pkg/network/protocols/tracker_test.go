@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConn generates the segments of a connection between 10.0.0.1:50000 and 10.0.0.2:port
+type testConn struct {
+	tracker    *connTracker
+	port       uint16
+	cseq, sseq uint32
+	ts         time.Time
+}
+
+func newTestTracker(maxConns, maxStats int) (*connTracker, *statKeeper, *telemetry) {
+	telemetry := newTelemetry()
+	statkeeper := newStatKeeper(maxStats, telemetry)
+	return newConnTracker(ports(testConfig()), maxConns, statkeeper, telemetry), statkeeper, telemetry
+}
+
+func (c *testConn) send(fromClient bool, payload []byte) {
+	s := segment{
+		saddr:   addr("10.0.0.1"),
+		daddr:   addr("10.0.0.2"),
+		sport:   50000,
+		dport:   c.port,
+		seq:     c.cseq,
+		payload: payload,
+	}
+	if !fromClient {
+		s.saddr, s.daddr = s.daddr, s.saddr
+		s.sport, s.dport = s.dport, s.sport
+		s.seq = c.sseq
+		c.sseq += uint32(len(payload))
+	} else {
+		c.cseq += uint32(len(payload))
+	}
+	c.ts = c.ts.Add(time.Millisecond)
+	c.tracker.process(&s, c.ts)
+}
+
+func TestUnclassifiedConnection(t *testing.T) {
+	tracker, statkeeper, telemetry := newTestTracker(10, 10)
+	c := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+
+	for i := 0; i < maxClassificationAttempts; i++ {
+		c.send(true, []byte("GET / HTTP/1.1\r\n\r\n"))
+		c.send(false, []byte("HTTP/1.1 200 OK\r\n\r\n"))
+	}
+	assert.Equal(t, int64(1), telemetry.unclassified)
+
+	// the connection isn't checked anymore
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+	assert.Empty(t, statkeeper.getAndResetAllStats())
+	assert.Equal(t, int64(0), telemetry.classified[ProtocolRedis])
+}
+
+func TestClassificationOnOtherProtocolPort(t *testing.T) {
+	tracker, statkeeper, telemetry := newTestTracker(10, 10)
+	// redis traffic on the kafka port
+	c := &testConn{tracker: tracker, port: 9092, ts: time.Now()}
+
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+
+	stats := statkeeper.getAndResetAllStats()
+	require.Len(t, stats, 1)
+	assert.Contains(t, stats, NewKey(addr("10.0.0.1"), addr("10.0.0.2"), 50000, 9092, ProtocolRedis, "PING", ""))
+	assert.Equal(t, int64(1), telemetry.classified[ProtocolRedis])
+}
+
+func TestLostSegment(t *testing.T) {
+	tracker, statkeeper, telemetry := newTestTracker(10, 10)
+	c := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+	// the client segment carrying the second command is lost
+	c.cseq += 100
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+
+	assert.Equal(t, int64(1), telemetry.parseErrors)
+	stats := statkeeper.getAndResetAllStats()
+	require.Len(t, stats, 1)
+	for _, s := range stats {
+		assert.Equal(t, 1, s.Count)
+	}
+}
+
+func TestPartialRetransmission(t *testing.T) {
+	tracker, statkeeper, _ := newTestTracker(10, 10)
+	c := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+
+	request := []byte("*1\r\n$4\r\nPING\r\n")
+	c.send(true, request[:5])
+	// the first bytes are sent again with the rest of the command
+	c.cseq -= 5
+	c.send(true, request)
+	c.send(false, []byte("+PONG\r\n"))
+
+	stats := statkeeper.getAndResetAllStats()
+	require.Len(t, stats, 1)
+	s := stats[NewKey(addr("10.0.0.1"), addr("10.0.0.2"), 50000, 6379, ProtocolRedis, "PING", "")]
+	assert.Equal(t, 1, s.Count)
+	assert.Equal(t, ms, s.FirstLatencySample)
+}
+
+func TestEncryptedPostgresConnection(t *testing.T) {
+	tracker, statkeeper, telemetry := newTestTracker(10, 10)
+	c := &testConn{tracker: tracker, port: 5432, ts: time.Now()}
+
+	sslRequest := make([]byte, 8)
+	binary.BigEndian.PutUint32(sslRequest[0:4], 8)
+	binary.BigEndian.PutUint32(sslRequest[4:8], postgresSSLRequestCode)
+	c.send(true, sslRequest)
+	c.send(false, []byte("S"))
+	c.send(true, []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01, 0x00, 0x01, 0xfc})
+
+	assert.Equal(t, int64(1), telemetry.classified[ProtocolPostgres])
+	assert.Equal(t, int64(1), telemetry.encrypted)
+	assert.Empty(t, statkeeper.getAndResetAllStats())
+}
+
+func TestMaxTrackedConnections(t *testing.T) {
+	tracker, _, telemetry := newTestTracker(1, 10)
+	c1 := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+	c1.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c2 := &testConn{tracker: tracker, port: 5432, ts: time.Now()}
+	c2.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+
+	assert.Len(t, tracker.conns, 1)
+	assert.Equal(t, int64(1), telemetry.connsDropped)
+}
+
+func TestIdleConnectionExpiry(t *testing.T) {
+	tracker, _, _ := newTestTracker(10, 10)
+	c := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	require.Len(t, tracker.conns, 1)
+
+	c.ts = c.ts.Add(connExpiry + expiryInterval)
+	other := &testConn{tracker: tracker, port: 5432, ts: c.ts}
+	other.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	assert.Len(t, tracker.conns, 1)
+}
+
+func TestMaxStats(t *testing.T) {
+	tracker, statkeeper, telemetry := newTestTracker(10, 1)
+	c := &testConn{tracker: tracker, port: 6379, ts: time.Now()}
+
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+	c.send(true, []byte("*1\r\n$4\r\nPING\r\n"))
+	c.send(false, []byte("+PONG\r\n"))
+	c.send(true, []byte("*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n"))
+	c.send(false, []byte("$-1\r\n"))
+
+	stats := statkeeper.getAndResetAllStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, int64(1), telemetry.dropped)
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package protocols classifies TCP connections carrying Kafka, PostgreSQL and Redis traffic, and parses their wire
+// protocols in userspace to produce per-connection request stats.
+package protocols
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// ProtocolType is the application protocol of a connection
+type ProtocolType uint8
+
+const (
+	// ProtocolUnknown is used for the connections which are not classified yet, or couldn't be classified
+	ProtocolUnknown ProtocolType = iota
+	// ProtocolKafka represents the Kafka protocol
+	ProtocolKafka
+	// ProtocolPostgres represents the PostgreSQL frontend/backend protocol
+	ProtocolPostgres
+	// ProtocolRedis represents the Redis serialization protocol (RESP)
+	ProtocolRedis
+)
+
+// String returns a string representing the protocol
+func (p ProtocolType) String() string {
+	switch p {
+	case ProtocolKafka:
+		return "kafka"
+	case ProtocolPostgres:
+		return "postgres"
+	case ProtocolRedis:
+		return "redis"
+	default:
+		return "unknown"
+	}
+}
+
+// Key is an identifier for a group of requests of a connection. Like http.Key, it is always indexed as
+// (client, server).
+type Key struct {
+	SrcIPHigh uint64
+	SrcIPLow  uint64
+	SrcPort   uint16
+
+	DstIPHigh uint64
+	DstIPLow  uint64
+	DstPort   uint16
+
+	Protocol ProtocolType
+	// Operation is the Kafka API key name, the PostgreSQL command (SELECT, INSERT, ...) or the Redis command
+	Operation string
+	// Resource is the Kafka topic, it is empty for the other protocols
+	Resource string
+}
+
+// NewKey generates a new Key
+func NewKey(saddr, daddr util.Address, sport, dport uint16, protocol ProtocolType, operation, resource string) Key {
+	saddrl, saddrh := util.ToLowHigh(saddr)
+	daddrl, daddrh := util.ToLowHigh(daddr)
+	return Key{
+		SrcIPHigh: saddrh,
+		SrcIPLow:  saddrl,
+		SrcPort:   sport,
+		DstIPHigh: daddrh,
+		DstIPLow:  daddrl,
+		DstPort:   dport,
+		Protocol:  protocol,
+		Operation: operation,
+		Resource:  resource,
+	}
+}
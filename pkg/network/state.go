@@ -11,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"go4.org/intern"
@@ -44,6 +45,7 @@ type State interface {
 		active []ConnectionStats,
 		dns dns.StatsByKeyByNameByType,
 		http map[http.Key]http.RequestStats,
+		l7 map[protocols.Key]protocols.RequestStats,
 	) Delta
 
 	// RemoveClient stops tracking stateful data for a given client
@@ -69,6 +71,7 @@ type State interface {
 type Delta struct {
 	BufferedData
 	HTTP     map[http.Key]http.RequestStats
+	L7       map[protocols.Key]protocols.RequestStats
 	DNSStats dns.StatsByKeyByNameByType
 }
 
@@ -79,6 +82,7 @@ type telemetry struct {
 	timeSyncCollisions int64
 	dnsStatsDropped    int64
 	httpStatsDropped   int64
+	l7StatsDropped     int64
 	dnsPidCollisions   int64
 }
 
@@ -103,6 +107,7 @@ type client struct {
 	// maps by dns key the domain (string) to stats structure
 	dnsStats       dns.StatsByKeyByNameByType
 	httpStatsDelta map[http.Key]http.RequestStats
+	l7StatsDelta   map[protocols.Key]protocols.RequestStats
 }
 
 func (c *client) Reset(active map[string]*ConnectionStats) {
@@ -115,6 +120,7 @@ func (c *client) Reset(active map[string]*ConnectionStats) {
 	c.closedConnectionsKeys = make(map[string]int)
 	c.dnsStats = make(dns.StatsByKeyByNameByType)
 	c.httpStatsDelta = make(map[http.Key]http.RequestStats)
+	c.l7StatsDelta = make(map[protocols.Key]protocols.RequestStats)
 
 	// XXX: we should change the way we clean this map once
 	// https://github.com/golang/go/issues/20135 is solved
@@ -144,10 +150,11 @@ type networkState struct {
 	maxClientStats int
 	maxDNSStats    int
 	maxHTTPStats   int
+	maxL7Stats     int
 }
 
 // NewState creates a new network state
-func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int, maxHTTPStats int) State {
+func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int, maxHTTPStats int, maxL7Stats int) State {
 	return &networkState{
 		clients:        map[string]*client{},
 		telemetry:      telemetry{},
@@ -156,6 +163,7 @@ func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, ma
 		maxClientStats: maxClientStats,
 		maxDNSStats:    maxDNSStats,
 		maxHTTPStats:   maxHTTPStats,
+		maxL7Stats:     maxL7Stats,
 		buf:            make([]byte, ConnectionByteKeyMaxLen),
 	}
 }
@@ -181,6 +189,7 @@ func (ns *networkState) GetDelta(
 	active []ConnectionStats,
 	dnsStats dns.StatsByKeyByNameByType,
 	httpStats map[http.Key]http.RequestStats,
+	l7Stats map[protocols.Key]protocols.RequestStats,
 ) Delta {
 	ns.Lock()
 	defer ns.Unlock()
@@ -221,6 +230,9 @@ func (ns *networkState) GetDelta(
 	if len(httpStats) > 0 {
 		ns.storeHTTPStats(httpStats)
 	}
+	if len(l7Stats) > 0 {
+		ns.storeL7Stats(l7Stats)
+	}
 
 	return Delta{
 		BufferedData: BufferedData{
@@ -228,6 +240,7 @@ func (ns *networkState) GetDelta(
 			buffer: clientBuffer,
 		},
 		HTTP:     client.httpStatsDelta,
+		L7:       client.l7StatsDelta,
 		DNSStats: client.dnsStats,
 	}
 }
@@ -361,6 +374,22 @@ func (ns *networkState) storeHTTPStats(allStats map[http.Key]http.RequestStats)
 	}
 }
 
+// storeL7Stats stores latest Kafka, PostgreSQL and Redis stats for all clients
+func (ns *networkState) storeL7Stats(allStats map[protocols.Key]protocols.RequestStats) {
+	for key, stats := range allStats {
+		for _, client := range ns.clients {
+			prevStats, ok := client.l7StatsDelta[key]
+			if !ok && len(client.l7StatsDelta) >= ns.maxL7Stats {
+				ns.telemetry.l7StatsDropped++
+				continue
+			}
+
+			prevStats.CombineWith(stats)
+			client.l7StatsDelta[key] = prevStats
+		}
+	}
+}
+
 func (ns *networkState) getClient(clientID string) (*client, bool) {
 	if c, ok := ns.clients[clientID]; ok {
 		return c, true
@@ -372,6 +401,7 @@ func (ns *networkState) getClient(clientID string) (*client, bool) {
 		closedConnections: make([]ConnectionStats, 0, minClosedCapacity),
 		dnsStats:          dns.StatsByKeyByNameByType{},
 		httpStatsDelta:    map[http.Key]http.RequestStats{},
+		l7StatsDelta:      map[protocols.Key]protocols.RequestStats{},
 	}
 	ns.clients[clientID] = c
 	return c, false
@@ -569,6 +599,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 		s += " [%d closed connections dropped]"
 		s += " [%d dns stats dropped]"
 		s += " [%d HTTP stats dropped]"
+		s += " [%d L7 stats dropped]"
 		s += " [%d DNS pid collisions]"
 		s += " [%d time sync collisions]"
 		log.Warnf(s,
@@ -577,6 +608,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 			ns.telemetry.closedConnDropped,
 			ns.telemetry.dnsStatsDropped,
 			ns.telemetry.httpStatsDropped,
+			ns.telemetry.l7StatsDropped,
 			ns.telemetry.dnsPidCollisions,
 			ns.telemetry.timeSyncCollisions)
 	}
@@ -607,6 +639,7 @@ func (ns *networkState) GetStats() map[string]interface{} {
 			"time_sync_collisions": ns.telemetry.timeSyncCollisions,
 			"dns_stats_dropped":    ns.telemetry.dnsStatsDropped,
 			"http_stats_dropped":   ns.telemetry.httpStatsDropped,
+			"l7_stats_dropped":     ns.telemetry.l7StatsDropped,
 			"dns_pid_collisions":   ns.telemetry.dnsPidCollisions,
 		},
 		"current_time":       time.Now().Unix(),
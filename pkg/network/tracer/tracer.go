@@ -28,6 +28,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
 	"github.com/DataDog/datadog-agent/pkg/network/netlink"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection/kprobe"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
@@ -47,6 +48,7 @@ type Tracer struct {
 	conntracker netlink.Conntracker
 	reverseDNS  dns.ReverseDNS
 	httpMonitor *http.Monitor
+	l7Monitor   *protocols.Monitor
 	ebpfTracer  connection.Tracer
 
 	// Telemetry
@@ -140,6 +142,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxConnectionsStateBuffered,
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
+		config.MaxL7StatsBuffered,
 	)
 
 	tr := &Tracer{
@@ -147,6 +150,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		state:                      state,
 		reverseDNS:                 newReverseDNS(!pre410Kernel, config),
 		httpMonitor:                newHTTPMonitor(!pre410Kernel, config, ebpfTracer, constantEditors),
+		l7Monitor:                  newL7Monitor(config),
 		activeBuffer:               network.NewConnectionBuffer(512, 256),
 		conntracker:                conntracker,
 		sourceExcludes:             network.ParseConnectionFilters(config.ExcludedSourceConnections),
@@ -298,6 +302,7 @@ func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
 	t.ebpfTracer.Stop()
 	t.httpMonitor.Stop()
+	t.l7Monitor.Stop()
 	t.conntracker.Close()
 }
 
@@ -313,7 +318,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}
 	active := t.activeBuffer.Connections()
 
-	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.httpMonitor.GetHTTPStats())
+	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.httpMonitor.GetHTTPStats(), t.l7Monitor.GetL7Stats())
 	t.activeBuffer.Reset()
 
 	t.retryConntrack(delta.Conns)
@@ -331,6 +336,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		DNS:                         names,
 		DNSStats:                    delta.DNSStats,
 		HTTP:                        delta.HTTP,
+		L7:                          delta.L7,
 		ConnTelemetry:               ctm,
 		CompilationTelemetryByAsset: rctm,
 	}, nil
@@ -374,6 +380,11 @@ func (t *Tracer) getConnTelemetry(mapSize int) map[network.ConnTelemetryType]int
 		tm[network.HTTPRequestsMissed] = ms
 	}
 
+	l7Stats := t.l7Monitor.GetStats()
+	if ds, ok := l7Stats["l7_requests_dropped"]; ok {
+		tm[network.L7RequestsDropped] = ds
+	}
+
 	ebpfStats := t.ebpfTracer.GetTelemetry()
 	if usp, ok := ebpfStats["udp_sends_processed"]; ok {
 		tm[network.MonotonicUDPSendsProcessed] = usp
@@ -564,6 +575,7 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 		"kprobes":   ddebpf.GetProbeStats(),
 		"dns":       t.reverseDNS.GetStats(),
 		"http":      t.httpMonitor.GetStats(),
+		"l7":        t.l7Monitor.GetStats(),
 	}
 
 	return ret, nil
@@ -700,3 +712,18 @@ func newHTTPMonitor(supported bool, c *config.Config, tracer connection.Tracer,
 	log.Info("http monitoring enabled")
 	return monitor
 }
+
+func newL7Monitor(c *config.Config) *protocols.Monitor {
+	if !c.EnableL7Monitoring {
+		return nil
+	}
+
+	monitor, err := protocols.NewMonitor(c)
+	if err != nil {
+		log.Errorf("could not enable l7 monitoring: %s", err)
+		return nil
+	}
+
+	log.Info("l7 monitoring enabled")
+	return monitor
+}
@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package procutil
+
+import "net"
+
+// ListeningSocket is a TCP socket a process listens on
+type ListeningSocket struct {
+	IP   net.IP
+	Port int
+}
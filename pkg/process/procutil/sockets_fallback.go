@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package procutil
+
+// ListeningSockets isn't supported outside of Linux
+func ListeningSockets(pids []int32) map[int32][]ListeningSocket {
+	return nil
+}
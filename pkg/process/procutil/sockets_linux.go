@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// tcpListenState is the state of the listening sockets in /proc/net/tcp
+const tcpListenState = "0A"
+
+// ListeningSockets returns the TCP sockets the processes listen on
+func ListeningSockets(pids []int32) map[int32][]ListeningSocket {
+	return readListeningSockets(util.HostProc(), pids)
+}
+
+// readListeningSockets matches the file descriptors of the processes with the listening sockets of the
+// /proc/<pid>/net/tcp and tcp6 files, read once per network namespace. Only the processes of the host network
+// namespace are considered, the sockets of the other namespaces, e.g. of the containers, aren't reachable from the
+// host.
+func readListeningSockets(procRoot string, pids []int32) map[int32][]ListeningSocket {
+	hostNamespace, err := os.Readlink(filepath.Join(procRoot, "1", "ns", "net"))
+	if err != nil {
+		log.Debugf("Unable to read the host network namespace: %s", err)
+	}
+
+	socketsByNamespace := make(map[string]map[uint64]ListeningSocket)
+	result := make(map[int32][]ListeningSocket)
+	for _, pid := range pids {
+		pidPath := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+		namespace, err := os.Readlink(filepath.Join(pidPath, "ns", "net"))
+		if err != nil {
+			// the namespaces of the processes of other users can't be read without privileges
+			continue
+		}
+		if hostNamespace != "" && namespace != hostNamespace {
+			continue
+		}
+		sockets, ok := socketsByNamespace[namespace]
+		if !ok {
+			sockets = make(map[uint64]ListeningSocket)
+			for _, file := range []string{"tcp", "tcp6"} {
+				if err := readProcNetTCP(filepath.Join(pidPath, "net", file), sockets); err != nil {
+					log.Debugf("Unable to read the %s sockets of process %d: %s", file, pid, err)
+				}
+			}
+			socketsByNamespace[namespace] = sockets
+		}
+		if len(sockets) == 0 {
+			continue
+		}
+
+		fds, err := os.ReadDir(filepath.Join(pidPath, "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(pidPath, "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if socket, ok := sockets[inode]; ok {
+				result[pid] = append(result[pid], socket)
+			}
+		}
+	}
+	return result
+}
+
+// readProcNetTCP reads the listening sockets of a /proc/net/tcp or tcp6 file, by inode
+func readProcNetTCP(path string, sockets map[uint64]ListeningSocket) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		ip, port, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		sockets[inode] = ListeningSocket{IP: ip, Port: port}
+	}
+	return scanner.Err()
+}
+
+// parseProcNetAddress parses an `<ip>:<port>` address of /proc/net/tcp, the IP being hex encoded as 32-bit words in
+// host byte order, e.g. `0100007F:1F90` for 127.0.0.1:8080
+func parseProcNetAddress(address string) (net.IP, int, error) {
+	parts := strings.Split(address, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address %s", address)
+	}
+	ipBytes, err := hex.DecodeString(parts[0])
+	if err != nil || (len(ipBytes) != net.IPv4len && len(ipBytes) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %s", address)
+	}
+	for i := 0; i < len(ipBytes); i += 4 {
+		ipBytes[i], ipBytes[i+1], ipBytes[i+2], ipBytes[i+3] = ipBytes[i+3], ipBytes[i+2], ipBytes[i+1], ipBytes[i]
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid address %s", address)
+	}
+	return net.IP(ipBytes), int(port), nil
+}
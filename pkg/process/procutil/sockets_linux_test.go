@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   112        0 21001 1 0000000000000000 100 0 0 10 0
+   1: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:18EB 0100007F:C350 01 00000000:00000000 00:00000000 00000000   112        0 21003 1 0000000000000000 20 4 30 10 -1
+`
+
+const testProcNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:18EB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   112        0 21004 1 0000000000000000 100 0 0 10 0
+`
+
+func TestReadListeningSockets(t *testing.T) {
+	procRoot := t.TempDir()
+	for pid, fds := range map[string]map[string]string{
+		"1":  {"0": "/dev/null"},
+		"10": {"0": "/dev/null", "6": "socket:[21001]", "7": "socket:[21004]", "8": "socket:[21003]"},
+		"20": {"3": "socket:[21002]", "4": "pipe:[9000]"},
+		"30": {"0": "/dev/null"},
+		"50": {"3": "socket:[21002]"},
+		"60": {"3": "socket:[21002]"},
+	} {
+		pidPath := filepath.Join(procRoot, pid)
+		require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "fd"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "ns"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "net"), 0755))
+		switch pid {
+		case "50":
+			// a container
+			require.NoError(t, os.Symlink("net:[4026532000]", filepath.Join(pidPath, "ns", "net")))
+		case "60":
+			// the namespace can't be read
+		default:
+			require.NoError(t, os.Symlink("net:[4026531840]", filepath.Join(pidPath, "ns", "net")))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp"), []byte(testProcNetTCP), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp6"), []byte(testProcNetTCP6), 0644))
+		for fd, target := range fds {
+			require.NoError(t, os.Symlink(target, filepath.Join(pidPath, "fd", fd)))
+		}
+	}
+
+	sockets := readListeningSockets(procRoot, []int32{10, 20, 30, 40, 50, 60})
+	assert.ElementsMatch(t, []ListeningSocket{
+		{IP: net.ParseIP("127.0.0.1").To4(), Port: 6379},
+		{IP: net.IPv6loopback, Port: 6379},
+	}, sockets[10])
+	assert.Equal(t, []ListeningSocket{{IP: net.IPv4zero.To4(), Port: 80}}, sockets[20])
+	assert.NotContains(t, sockets, int32(30))
+	assert.NotContains(t, sockets, int32(40))
+	assert.NotContains(t, sockets, int32(50))
+	assert.NotContains(t, sockets, int32(60))
+}
+
+func TestParseProcNetAddress(t *testing.T) {
+	ip, port, err := parseProcNetAddress("0100007F:1F90")
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, 8080, port)
+
+	ip, port, err = parseProcNetAddress("0000000000000000FFFF00000500000A:1538")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", ip.String())
+	assert.Equal(t, 5432, port)
+
+	_, _, err = parseProcNetAddress("0100007F")
+	assert.Error(t, err)
+	_, _, err = parseProcNetAddress("01007F:1F90")
+	assert.Error(t, err)
+}
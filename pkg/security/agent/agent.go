@@ -19,6 +19,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event/sinks"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/security/api"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -149,6 +150,10 @@ func (rsa *RuntimeSecurityAgent) GetStatus() map[string]interface{} {
 		"endpoints":     rsa.endpoints.GetStatus(),
 	}
 
+	if sinksReporter, ok := rsa.reporter.(*sinks.Reporter); ok {
+		base["sinks"] = sinksReporter.GetStatus()
+	}
+
 	if rsa.client != nil {
 		cfStatus, err := rsa.client.GetStatus()
 		if err == nil {
@@ -34,6 +34,8 @@ type Config struct {
 	RuntimeEnabled bool
 	// PoliciesDir defines the folder in which the policy files are located
 	PoliciesDir string
+	// PoliciesMaxWindowStates defines the maximum number of states kept for the rules matching over a time window
+	PoliciesMaxWindowStates int
 	// EnableKernelFilters defines if in-kernel filtering should be activated or not
 	EnableKernelFilters bool
 	// EnableApprovers defines if in-kernel approvers should be activated or not
@@ -120,6 +122,29 @@ type Config struct {
 	// ActivityDumpCgroupOutputDirectory defines the output directory for the cgroup activity dumps and graphs. Leave
 	// this field empty to prevent writing any output to disk.
 	ActivityDumpCgroupOutputDirectory string
+	// SecurityProfileEnabled defines if the security profile manager should be enabled. The security profile manager
+	// learns the activity of the containers of each image, and reports the activity that doesn't match it once the
+	// learning period is over.
+	SecurityProfileEnabled bool
+	// SecurityProfileDir defines the directory in which the security profiles are persisted
+	SecurityProfileDir string
+	// SecurityProfileLearningPeriod defines how long a new security profile learns the activity of its workload
+	// before it starts reporting anomalies.
+	SecurityProfileLearningPeriod time.Duration
+	// SecurityProfileEventTypes defines the list of event types that should be learnt and evaluated by the security
+	// profiles. Supported event types are `exec`, `open` and `dns`.
+	SecurityProfileEventTypes []model.EventType
+	// SecurityProfileCacheSize defines the maximum number of security profiles kept in memory. The least recently used
+	// profiles are written to disk and evicted, they are loaded again when their workload shows up.
+	SecurityProfileCacheSize int
+	// SecurityProfileMaxProcessNodes defines the maximum number of process nodes of a security profile. Set to 0 to
+	// disable the limit.
+	SecurityProfileMaxProcessNodes int
+	// SecurityProfileMaxFiles defines the maximum number of files of a security profile. Set to 0 to disable the limit.
+	SecurityProfileMaxFiles int
+	// SecurityProfileMaxDNSNames defines the maximum number of DNS names of a security profile. Set to 0 to disable
+	// the limit.
+	SecurityProfileMaxDNSNames int
 	// RuntimeMonitor defines if the runtime monitor should be enabled
 	RuntimeMonitor bool
 	// NetworkEnabled defines if the network probes should be activated
@@ -159,6 +184,7 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		SocketPath:                         aconfig.Datadog.GetString("runtime_security_config.socket"),
 		SyscallMonitor:                     aconfig.Datadog.GetBool("runtime_security_config.syscall_monitor.enabled"),
 		PoliciesDir:                        aconfig.Datadog.GetString("runtime_security_config.policies.dir"),
+		PoliciesMaxWindowStates:            aconfig.Datadog.GetInt("runtime_security_config.policies.max_window_states"),
 		EventServerBurst:                   aconfig.Datadog.GetInt("runtime_security_config.event_server.burst"),
 		EventServerRate:                    aconfig.Datadog.GetInt("runtime_security_config.event_server.rate"),
 		EventServerRetention:               aconfig.Datadog.GetInt("runtime_security_config.event_server.retention"),
@@ -189,6 +215,14 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		ActivityDumpCgroupDumpTimeout:      time.Duration(aconfig.Datadog.GetInt("runtime_security_config.activity_dump.cgroup_dump_timeout")) * time.Minute,
 		ActivityDumpCgroupWaitListSize:     aconfig.Datadog.GetInt("runtime_security_config.activity_dump.cgroup_wait_list_size"),
 		ActivityDumpCgroupOutputDirectory:  aconfig.Datadog.GetString("runtime_security_config.activity.cgroup_output_directory"),
+		SecurityProfileEnabled:             aconfig.Datadog.GetBool("runtime_security_config.security_profile.enabled"),
+		SecurityProfileDir:                 aconfig.Datadog.GetString("runtime_security_config.security_profile.dir"),
+		SecurityProfileLearningPeriod:      time.Duration(aconfig.Datadog.GetInt("runtime_security_config.security_profile.learning_period")) * time.Minute,
+		SecurityProfileEventTypes:          model.ParseEventTypeStringSlice(aconfig.Datadog.GetStringSlice("runtime_security_config.security_profile.event_types")),
+		SecurityProfileCacheSize:           aconfig.Datadog.GetInt("runtime_security_config.security_profile.cache_size"),
+		SecurityProfileMaxProcessNodes:     aconfig.Datadog.GetInt("runtime_security_config.security_profile.max_process_nodes"),
+		SecurityProfileMaxFiles:            aconfig.Datadog.GetInt("runtime_security_config.security_profile.max_files"),
+		SecurityProfileMaxDNSNames:         aconfig.Datadog.GetInt("runtime_security_config.security_profile.max_dns_names"),
 		RuntimeMonitor:                     aconfig.Datadog.GetBool("runtime_security_config.runtime_monitor.enabled"),
 		NetworkEnabled:                     aconfig.Datadog.GetBool("runtime_security_config.network.enabled"),
 		NetworkLazyInterfacePrefixes:       aconfig.Datadog.GetStringSlice("runtime_security_config.network.lazy_interface_prefixes"),
@@ -0,0 +1,68 @@
+#ifndef _ACCEPT_H_
+#define _ACCEPT_H_
+
+#include "socket.h"
+
+DECLARE_SOCKET_FAMILY_APPROVERS(accept)
+
+SYSCALL_KPROBE0(accept) {
+    return cache_socket_syscall(EVENT_ACCEPT);
+}
+
+SYSCALL_KPROBE0(accept4) {
+    return cache_socket_syscall(EVENT_ACCEPT);
+}
+
+// inet_csk_accept returns the socket of the accepted connection, the remote peer being its destination
+SEC("kretprobe/inet_csk_accept")
+int kretprobe_inet_csk_accept(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(EVENT_ACCEPT);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct sock *sk = (struct sock *)PT_REGS_RC(ctx);
+    if (sk == NULL) {
+        return 0;
+    }
+
+    bpf_probe_read(&syscall->socket.family, sizeof(syscall->socket.family), &sk->__sk_common.skc_family);
+    bpf_probe_read(&syscall->socket.port, sizeof(syscall->socket.port), &sk->__sk_common.skc_dport);
+
+    switch (syscall->socket.family) {
+    case AF_INET:
+        bpf_probe_read(&syscall->socket.addr[0], sizeof(sk->__sk_common.skc_daddr), &sk->__sk_common.skc_daddr);
+        break;
+    case AF_INET6:
+        bpf_probe_read(&syscall->socket.addr, sizeof(syscall->socket.addr), &sk->__sk_common.skc_v6_daddr);
+        break;
+    }
+
+    // inet_csk_accept is only used by connection oriented protocols
+    syscall->socket.protocol = IPPROTO_TCP;
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_accept_ret(void *ctx, int retval) {
+    return sys_socket_ret(ctx, EVENT_ACCEPT, retval, accept_approvers);
+}
+
+SYSCALL_KRETPROBE(accept) {
+    return sys_accept_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept")
+int tracepoint_syscalls_sys_exit_accept(struct tracepoint_syscalls_sys_exit_t *args) {
+    return sys_accept_ret(args, (int)args->ret);
+}
+
+SYSCALL_KRETPROBE(accept4) {
+    return sys_accept_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+SEC("tracepoint/syscalls/sys_exit_accept4")
+int tracepoint_syscalls_sys_exit_accept4(struct tracepoint_syscalls_sys_exit_t *args) {
+    return sys_accept_ret(args, (int)args->ret);
+}
+
+#endif
@@ -0,0 +1,39 @@
+#ifndef _BIND_H_
+#define _BIND_H_
+
+#include "socket.h"
+
+DECLARE_SOCKET_FAMILY_APPROVERS(bind)
+
+SYSCALL_KPROBE0(bind) {
+    return cache_socket_syscall(EVENT_BIND);
+}
+
+SEC("kprobe/security_socket_bind")
+int kprobe_security_socket_bind(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(EVENT_BIND);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct socket *sock = (struct socket *)PT_REGS_PARM1(ctx);
+    struct sockaddr *address = (struct sockaddr *)PT_REGS_PARM2(ctx);
+    parse_sockaddr(syscall, address);
+    syscall->socket.protocol = socket_protocol(sock);
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_bind_ret(void *ctx, int retval) {
+    return sys_socket_ret(ctx, EVENT_BIND, retval, bind_approvers);
+}
+
+SYSCALL_KRETPROBE(bind) {
+    return sys_bind_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+SEC("tracepoint/syscalls/sys_exit_bind")
+int tracepoint_syscalls_sys_exit_bind(struct tracepoint_syscalls_sys_exit_t *args) {
+    return sys_bind_ret(args, (int)args->ret);
+}
+
+#endif
@@ -0,0 +1,43 @@
+#ifndef _CONNECT_H_
+#define _CONNECT_H_
+
+#include "socket.h"
+
+DECLARE_SOCKET_FAMILY_APPROVERS(connect)
+
+SYSCALL_KPROBE0(connect) {
+    return cache_socket_syscall(EVENT_CONNECT);
+}
+
+SEC("kprobe/security_socket_connect")
+int kprobe_security_socket_connect(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(EVENT_CONNECT);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct socket *sock = (struct socket *)PT_REGS_PARM1(ctx);
+    struct sockaddr *address = (struct sockaddr *)PT_REGS_PARM2(ctx);
+    parse_sockaddr(syscall, address);
+    syscall->socket.protocol = socket_protocol(sock);
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_connect_ret(void *ctx, int retval) {
+    // a non blocking connect still in progress is reported
+    if (retval == -EINPROGRESS) {
+        retval = 0;
+    }
+    return sys_socket_ret(ctx, EVENT_CONNECT, retval, connect_approvers);
+}
+
+SYSCALL_KRETPROBE(connect) {
+    return sys_connect_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+SEC("tracepoint/syscalls/sys_exit_connect")
+int tracepoint_syscalls_sys_exit_connect(struct tracepoint_syscalls_sys_exit_t *args) {
+    return sys_connect_ret(args, (int)args->ret);
+}
+
+#endif
@@ -227,6 +227,9 @@ enum event_type
     EVENT_NET_DEVICE,
     EVENT_VETH_PAIR,
     EVENT_NAMESPACE_SWITCH,
+    EVENT_CONNECT,
+    EVENT_BIND,
+    EVENT_ACCEPT,
     EVENT_MAX, // has to be the last one
 
     EVENT_ALL = 0xffffffffffffffff // used as a mask for all the events
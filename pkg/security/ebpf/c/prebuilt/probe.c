@@ -15,6 +15,8 @@
 #include <net/netfilter/nf_nat.h>
 #include <uapi/linux/ip.h>
 #include <uapi/linux/ipv6.h>
+#include <uapi/linux/in.h>
+#include <uapi/linux/in6.h>
 #include <uapi/linux/udp.h>
 #include <uapi/linux/tcp.h>
 
@@ -60,6 +62,9 @@
 #include "flow.h"
 #include "network_parser.h"
 #include "dns.h"
+#include "connect.h"
+#include "bind.h"
+#include "accept.h"
 #include "tc.h"
 #include "module.h"
 #include "signal.h"
@@ -0,0 +1,126 @@
+#ifndef _SOCKET_H_
+#define _SOCKET_H_
+
+struct socket_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct span_context_t span;
+    struct container_context_t container;
+    struct syscall_t syscall;
+
+    u64 addr[2];
+    u16 family;
+    u16 port;
+    u16 protocol;
+    u16 padding;
+};
+
+#define DECLARE_SOCKET_FAMILY_APPROVERS(name)                                                                           \
+    struct bpf_map_def SEC("maps/" #name "_family_approvers") name##_family_approvers = {                              \
+        .type = BPF_MAP_TYPE_ARRAY,                                                                                    \
+        .key_size = sizeof(u32),                                                                                       \
+        .value_size = sizeof(u32),                                                                                     \
+        .max_entries = 1,                                                                                              \
+        .pinning = 0,                                                                                                  \
+        .namespace = "",                                                                                               \
+    };                                                                                                                 \
+                                                                                                                       \
+    int __attribute__((always_inline)) name##_approvers(struct syscall_cache_t *syscall) {                             \
+        if ((syscall->policy.flags & FLAGS) == 0) {                                                                    \
+            return 0;                                                                                                  \
+        }                                                                                                              \
+                                                                                                                       \
+        u32 key = 0;                                                                                                   \
+        u32 *flags = bpf_map_lookup_elem(&name##_family_approvers, &key);                                              \
+        if (flags != NULL && syscall->socket.family < 32 && ((1 << syscall->socket.family) & *flags) > 0) {            \
+            return 1;                                                                                                  \
+        }                                                                                                              \
+        return 0;                                                                                                      \
+    }
+
+// socket_protocol returns the protocol of a socket from its type, only TCP and UDP sockets are reported
+u16 __attribute__((always_inline)) socket_protocol(struct socket *sock) {
+    short type = 0;
+    bpf_probe_read(&type, sizeof(type), &sock->type);
+
+    switch (type) {
+    case SOCK_STREAM:
+        return IPPROTO_TCP;
+    case SOCK_DGRAM:
+        return IPPROTO_UDP;
+    }
+    return 0;
+}
+
+// parse_sockaddr copies the family, address and port of a sockaddr to the syscall cache
+void __attribute__((always_inline)) parse_sockaddr(struct syscall_cache_t *syscall, struct sockaddr *address) {
+    bpf_probe_read(&syscall->socket.family, sizeof(syscall->socket.family), &address->sa_family);
+
+    switch (syscall->socket.family) {
+    case AF_INET: {
+        struct sockaddr_in *addr_in = (struct sockaddr_in *)address;
+        bpf_probe_read(&syscall->socket.port, sizeof(syscall->socket.port), &addr_in->sin_port);
+        bpf_probe_read(&syscall->socket.addr[0], sizeof(addr_in->sin_addr.s_addr), &addr_in->sin_addr.s_addr);
+        break;
+    }
+    case AF_INET6: {
+        struct sockaddr_in6 *addr_in6 = (struct sockaddr_in6 *)address;
+        bpf_probe_read(&syscall->socket.port, sizeof(syscall->socket.port), &addr_in6->sin6_port);
+        bpf_probe_read(&syscall->socket.addr, sizeof(syscall->socket.addr), &addr_in6->sin6_addr);
+        break;
+    }
+    }
+}
+
+int __attribute__((always_inline)) cache_socket_syscall(u64 event_type) {
+    struct policy_t policy = fetch_policy(event_type);
+    if (is_discarded_by_process(policy.mode, event_type)) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = event_type,
+        .policy = policy,
+    };
+
+    cache_syscall(&syscall);
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_socket_ret(void *ctx, u64 event_type, int retval, int (*approvers)(struct syscall_cache_t *syscall)) {
+    struct syscall_cache_t *syscall = pop_syscall(event_type);
+    if (!syscall) {
+        return 0;
+    }
+
+    if (IS_UNHANDLED_ERROR(retval)) {
+        return 0;
+    }
+
+    // only IPv4 and IPv6 addresses are reported
+    if (syscall->socket.family != AF_INET && syscall->socket.family != AF_INET6) {
+        return 0;
+    }
+
+    if (filter_syscall(syscall, approvers)) {
+        return discard_syscall(syscall);
+    }
+
+    struct socket_event_t event = {
+        .syscall.retval = retval,
+        .family = syscall->socket.family,
+        .port = syscall->socket.port,
+        .protocol = syscall->socket.protocol,
+    };
+    event.addr[0] = syscall->socket.addr[0];
+    event.addr[1] = syscall->socket.addr[1];
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+    fill_span_context(&event.span);
+
+    send_event(ctx, event_type, event);
+    return 0;
+}
+
+#endif
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probes
+
+import manager "github.com/DataDog/ebpf-manager"
+
+// socketProbes holds the list of probes used to track connect, bind and accept events
+var socketProbes = []*manager.Probe{
+	{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID:          SecurityAgentUID,
+			EBPFSection:  "kprobe/security_socket_connect",
+			EBPFFuncName: "kprobe_security_socket_connect",
+		},
+	},
+	{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID:          SecurityAgentUID,
+			EBPFSection:  "kprobe/security_socket_bind",
+			EBPFFuncName: "kprobe_security_socket_bind",
+		},
+	},
+	{
+		ProbeIdentificationPair: manager.ProbeIdentificationPair{
+			UID:          SecurityAgentUID,
+			EBPFSection:  "kretprobe/inet_csk_accept",
+			EBPFFuncName: "kretprobe_inet_csk_accept",
+		},
+	},
+}
+
+func getSocketProbes() []*manager.Probe {
+	for _, name := range []string{"connect", "bind", "accept", "accept4"} {
+		socketProbes = append(socketProbes, ExpandSyscallProbes(&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				UID: SecurityAgentUID,
+			},
+			SyscallFuncName: name,
+		}, EntryAndExit)...)
+	}
+	return socketProbes
+}
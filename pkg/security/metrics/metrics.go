@@ -148,6 +148,20 @@ var (
 	// Tags: -
 	MetricActivityDumpActiveDumps = newRuntimeMetric(".activity_dump.active_dumps")
 
+	// Security profile metrics
+
+	// MetricSecurityProfileProfiles is the name of the metric used to report the number of security profiles
+	// Tags: status
+	MetricSecurityProfileProfiles = newRuntimeMetric(".security_profile.profiles")
+	// MetricSecurityProfileAnomaly is the name of the metric used to count the anomalies detected with the security
+	// profiles
+	// Tags: anomaly_type
+	MetricSecurityProfileAnomaly = newRuntimeMetric(".security_profile.anomaly")
+	// MetricSecurityProfileDroppedEntries is the name of the metric used to count the process nodes, files and DNS
+	// names that weren't learnt because a security profile reached one of its limits
+	// Tags: -
+	MetricSecurityProfileDroppedEntries = newRuntimeMetric(".security_profile.dropped_entries")
+
 	// Namespace resolver metrics
 
 	// MetricNamespaceResolverNetNSHandle is the name of the metric used to report the count of netns handles
@@ -168,6 +182,14 @@ var (
 	// MetricRuleSetLoaded is the name of the metric used to report that a new ruleset was loaded
 	// Tags: -
 	MetricRuleSetLoaded = newRuntimeMetric(".ruleset_loaded")
+	// MetricRuleSetWindowStates is the name of the metric used to report the count of states kept for the rules
+	// matching over a time window
+	// Tags: -
+	MetricRuleSetWindowStates = newRuntimeMetric(".ruleset.window_states")
+	// MetricRuleSetWindowEvictions is the name of the metric used to report the count of states of the rules
+	// matching over a time window evicted to keep the state table within its bounds
+	// Tags: -
+	MetricRuleSetWindowEvictions = newRuntimeMetric(".ruleset.window_evictions")
 	// MetricTCProgram is the name of the metric used to report the count of active TC programs
 	// Tags: -
 	MetricTCProgram = newRuntimeMetric(".tc_program")
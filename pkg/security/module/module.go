@@ -258,6 +258,7 @@ func (m *Module) Reload() error {
 		WithEventTypeEnabled(m.getEventTypeEnabled()).
 		WithReservedRuleIDs(sprobe.AllCustomRuleIDs()).
 		WithLegacyFields(model.SECLLegacyFields).
+		WithMaxWindowStates(m.config.PoliciesMaxWindowStates).
 		WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
 			"process": func() rules.VariableProvider {
 				return eval.NewScopedVariables(func(ctx *eval.Context) unsafe.Pointer {
@@ -446,6 +447,13 @@ func (m *Module) metricsSender() {
 			if err := m.apiServer.SendStats(); err != nil {
 				log.Debug(err)
 			}
+			if ruleSet := m.GetRuleSet(); ruleSet != nil {
+				stats := ruleSet.GetWindowStats()
+				_ = m.statsdClient.Gauge(metrics.MetricRuleSetWindowStates, float64(stats.States), nil, 1.0)
+				if stats.Evictions > 0 {
+					_ = m.statsdClient.Count(metrics.MetricRuleSetWindowEvictions, stats.Evictions, nil, 1.0)
+				}
+			}
 		case <-heartbeatTicker.C:
 			tags := []string{fmt.Sprintf("version:%s", version.AgentVersion)}
 
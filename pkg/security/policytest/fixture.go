@@ -0,0 +1,245 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package policytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+// Fixture describes an event and the rules expected to match it, or not.
+//
+// The fields of the event are SECL fields, for instance `exec.file.path` or
+// `process.uid`. The integer fields accept SECL constants like
+// `O_CREAT | O_RDWR`, the array fields accept lists. For an exec event, the
+// `exec.*` fields are also the `process.*` fields unless they are set
+// explicitly, and the `file.name` of a file is the base of its `file.path`
+// unless it is set explicitly.
+//
+// The timestamp of a fixture is the time of its event for the rules matching
+// over a time window, the time of the evaluation is used if it is not set.
+type Fixture struct {
+	Name      string                 `json:"name" yaml:"name"`
+	Type      string                 `json:"type" yaml:"type"`
+	Timestamp time.Time              `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+	Fields    map[string]interface{} `json:"fields" yaml:"fields"`
+	// Ancestors are the fields of the ancestors of the process, starting
+	// with its parent, without the `process.` prefix.
+	Ancestors []map[string]interface{} `json:"ancestors,omitempty" yaml:"ancestors,omitempty"`
+	Expect    Expectations             `json:"expect" yaml:"expect"`
+}
+
+// Expectations lists the rules expected to match an event and the rules
+// expected not to match it.
+type Expectations struct {
+	Match   []string `json:"match,omitempty" yaml:"match,omitempty"`
+	NoMatch []string `json:"no_match,omitempty" yaml:"no_match,omitempty"`
+}
+
+// LoadFixtures reads a list of fixtures from a JSON or a YAML file.
+func LoadFixtures(filename string) ([]Fixture, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	switch ext := filepath.Ext(filename); ext {
+	case ".json":
+		err = json.Unmarshal(content, &fixtures)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &fixtures)
+	default:
+		return nil, fmt.Errorf("unsupported fixture file extension `%s`, expected .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the fixtures of %s: %w", filename, err)
+	}
+
+	for i := range fixtures {
+		if fixtures[i].Name == "" {
+			fixtures[i].Name = fmt.Sprintf("%s#%d", filepath.Base(filename), i)
+		}
+	}
+	return fixtures, nil
+}
+
+// NewEvent builds the event described by the fixture.
+func (f *Fixture) NewEvent() (*model.Event, error) {
+	eventType := model.ParseEvalEventType(f.Type)
+	if eventType == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", f.Type)
+	}
+
+	event := &model.Event{Type: uint64(eventType), Timestamp: f.Timestamp}
+	fields := f.Fields
+	if eventType == model.ExecEventType {
+		fields = withExecProcessFields(event, fields)
+	}
+	if err := setFields(event, fields); err != nil {
+		return nil, err
+	}
+
+	var last *model.ProcessCacheEntry
+	for i, ancestorFields := range f.Ancestors {
+		ancestor := &model.Event{}
+		fields := make(map[string]interface{}, len(ancestorFields))
+		for field, value := range ancestorFields {
+			fields["process."+strings.TrimPrefix(field, "process.")] = value
+		}
+		if err := setFields(ancestor, fields); err != nil {
+			return nil, fmt.Errorf("ancestor #%d: %w", i, err)
+		}
+
+		entry := &model.ProcessCacheEntry{
+			ProcessContext: model.ProcessContext{Process: ancestor.ProcessContext.Process},
+		}
+		if last == nil {
+			event.ProcessContext.Ancestor = entry
+		} else {
+			last.Ancestor = entry
+		}
+		last = entry
+	}
+
+	return event, nil
+}
+
+// withExecProcessFields returns the fields of an exec event with the
+// `process.*` fields copied from the `exec.*` fields, the process of the
+// context of an exec event being the executed process.
+func withExecProcessFields(event *model.Event, fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		result[field] = value
+	}
+	for field, value := range fields {
+		if !strings.HasPrefix(field, "exec.") {
+			continue
+		}
+		processField := "process." + strings.TrimPrefix(field, "exec.")
+		if _, found := fields[processField]; found {
+			continue
+		}
+		if _, err := event.GetFieldType(processField); err == nil {
+			result[processField] = value
+		}
+	}
+	return result
+}
+
+func setFields(event *model.Event, fields map[string]interface{}) error {
+	// sorted to report the errors in a stable order
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	for _, field := range names {
+		if err := setField(event, field, fields[field]); err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(field, ".file.path") {
+			nameField := strings.TrimSuffix(field, ".path") + ".name"
+			if _, found := fields[nameField]; found {
+				continue
+			}
+			if filePath, ok := fields[field].(string); ok {
+				if err := setField(event, nameField, path.Base(filePath)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func setField(event *model.Event, field string, value interface{}) error {
+	kind, err := event.GetFieldType(field)
+	if err != nil {
+		return fmt.Errorf("unknown field `%s`", field)
+	}
+
+	values := []interface{}{value}
+	if list, ok := value.([]interface{}); ok {
+		values = list
+	}
+
+	for _, v := range values {
+		converted, err := convertValue(kind, v)
+		if err != nil {
+			return fmt.Errorf("invalid value for `%s`: %w", field, err)
+		}
+		if err := event.SetFieldValue(field, converted); err != nil {
+			return fmt.Errorf("invalid value for `%s`: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// convertValue converts a value decoded from JSON or YAML to the type
+// expected by Event.SetFieldValue.
+func convertValue(kind reflect.Kind, value interface{}) (interface{}, error) {
+	switch kind {
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case reflect.Int:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case uint64:
+			return int(v), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case string:
+			return constantValue(v)
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	default:
+		return nil, fmt.Errorf("fields of type %s are not supported", kind)
+	}
+	return nil, fmt.Errorf("expected a value of type %s, got %v", kind, value)
+}
+
+// constantValue returns the value of SECL constants or'ed together, for
+// instance `O_CREAT | O_RDWR`.
+func constantValue(expr string) (int, error) {
+	var result int
+	for _, name := range strings.Split(expr, "|") {
+		name = strings.TrimSpace(name)
+		constant, ok := model.SECLConstants[name].(*eval.IntEvaluator)
+		if !ok {
+			return 0, fmt.Errorf("unknown integer constant `%s`", name)
+		}
+		result |= constant.Value
+	}
+	return result, nil
+}
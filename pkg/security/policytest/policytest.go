@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+// Package policytest evaluates the rules of runtime security policies against
+// event fixtures, without a kernel probe.
+package policytest
+
+import (
+	"fmt"
+	"sort"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+// Harness evaluates the rules of the policies of a directory against event
+// fixtures. The fixtures are evaluated in order against the same rule set,
+// the variables set by the actions of the rules are kept from one fixture to
+// the next one.
+type Harness struct {
+	ruleSet *rules.RuleSet
+	matched []*rules.Rule
+}
+
+// Result is the evaluation of a fixture.
+type Result struct {
+	Fixture   string `json:"fixture"`
+	EventType string `json:"event_type"`
+	// Rules are the rules which matched the event and the rules of the
+	// expectations of the fixture.
+	Rules    []RuleResult `json:"rules"`
+	Failures []string     `json:"failures,omitempty"`
+}
+
+// RuleResult is the evaluation of a rule against the event of a fixture.
+type RuleResult struct {
+	ID      string `json:"id"`
+	Matched bool   `json:"matched"`
+	// Fields are the values of the fields used by the rule for the event.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Report is the evaluation of a list of fixtures.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// NewHarness loads the policies of a directory.
+func NewHarness(policiesDir string) (*Harness, error) {
+	variables := make(map[string]eval.VariableValue, len(model.SECLVariables))
+	for name, value := range model.SECLVariables {
+		variables[name] = value
+	}
+
+	var opts rules.Opts
+	opts.
+		WithConstants(model.SECLConstants).
+		WithVariables(variables).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true}).
+		WithLegacyFields(model.SECLLegacyFields).
+		WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
+			"process": func() rules.VariableProvider {
+				return eval.NewScopedVariables(func(ctx *eval.Context) unsafe.Pointer {
+					return unsafe.Pointer(&(*model.Event)(ctx.Object).ProcessContext)
+				}, nil)
+			},
+		})
+
+	m := &model.Model{}
+	h := &Harness{ruleSet: rules.NewRuleSet(m, m.NewEvent, &opts)}
+	if err := rules.LoadPolicies(policiesDir, h.ruleSet); err.ErrorOrNil() != nil {
+		return nil, err
+	}
+	h.ruleSet.AddListener(h)
+
+	return h, nil
+}
+
+// RuleMatch implements the rules.RuleSetListener interface
+func (h *Harness) RuleMatch(rule *rules.Rule, event eval.Event) {
+	h.matched = append(h.matched, rule)
+}
+
+// EventDiscarderFound implements the rules.RuleSetListener interface
+func (h *Harness) EventDiscarderFound(rs *rules.RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+// Evaluate evaluates the rules against the event of a fixture and checks
+// the expectations of the fixture.
+func (h *Harness) Evaluate(fixture Fixture) (Result, error) {
+	result := Result{Fixture: fixture.Name, EventType: fixture.Type}
+
+	event, err := fixture.NewEvent()
+	if err != nil {
+		return result, fmt.Errorf("fixture %s: %w", fixture.Name, err)
+	}
+
+	h.matched = nil
+	h.ruleSet.Evaluate(event)
+
+	matched := make(map[string]bool, len(h.matched))
+	for _, rule := range h.matched {
+		matched[rule.ID] = true
+	}
+
+	ruleIDs := make(map[string]bool, len(matched))
+	for id := range matched {
+		ruleIDs[id] = true
+	}
+	for _, id := range fixture.Expect.Match {
+		ruleIDs[id] = true
+		if !matched[id] {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule `%s` was expected to match", id))
+		}
+	}
+	for _, id := range fixture.Expect.NoMatch {
+		ruleIDs[id] = true
+		if matched[id] {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule `%s` was expected not to match", id))
+		}
+	}
+
+	allRules := h.ruleSet.GetRules()
+	for id := range ruleIDs {
+		ruleResult := RuleResult{ID: id, Matched: matched[id]}
+		if rule, found := allRules[id]; found {
+			ruleResult.Fields = fieldValues(event, rule)
+		} else {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule `%s` is not defined by the policies", id))
+		}
+		result.Rules = append(result.Rules, ruleResult)
+	}
+	sort.Slice(result.Rules, func(i, j int) bool { return result.Rules[i].ID < result.Rules[j].ID })
+	sort.Strings(result.Failures)
+
+	return result, nil
+}
+
+// Run evaluates a list of fixtures in order.
+func (h *Harness) Run(fixtures []Fixture) (*Report, error) {
+	report := &Report{}
+	for _, fixture := range fixtures {
+		result, err := h.Evaluate(fixture)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// Failures returns the number of failed expectations.
+func (r *Report) Failures() int {
+	count := 0
+	for _, result := range r.Results {
+		count += len(result.Failures)
+	}
+	return count
+}
+
+// fieldValues returns the values of the fields of the event used by a rule.
+func fieldValues(event *model.Event, rule *rules.Rule) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, field := range rule.GetEvaluator().GetFields() {
+		if value, err := event.GetFieldValue(field); err == nil {
+			values[field] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package policytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func TestHarness(t *testing.T) {
+	h, err := NewHarness("testdata/policies")
+	require.NoError(t, err)
+
+	fixtures, err := LoadFixtures("testdata/fixtures.yaml")
+	require.NoError(t, err)
+	jsonFixtures, err := LoadFixtures("testdata/fixtures.json")
+	require.NoError(t, err)
+	fixtures = append(fixtures, jsonFixtures...)
+
+	report, err := h.Run(fixtures)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 6)
+
+	for _, result := range report.Results[:5] {
+		assert.Empty(t, result.Failures, result.Fixture)
+	}
+
+	shellFromSSH := report.Results[0]
+	require.Len(t, shellFromSSH.Rules, 2)
+	assert.Equal(t, RuleResult{
+		ID:      "curl_pipe",
+		Matched: false,
+		Fields: map[string]interface{}{
+			"exec.file.path":              "/bin/bash",
+			"process.ancestors.file.name": []string{"sshd"},
+		},
+	}, shellFromSSH.Rules[0])
+	assert.Equal(t, "shell_from_ssh", shellFromSSH.Rules[1].ID)
+	assert.True(t, shellFromSSH.Rules[1].Matched)
+
+	// the variable set by the curl_pipe rule is kept for the next fixtures
+	assert.Equal(t, "dns after a download", report.Results[4].Fixture)
+
+	failed := report.Results[5]
+	assert.Equal(t, "fixtures.json#1", failed.Fixture)
+	assert.Equal(t, []string{
+		"rule `shadow_write` was expected to match",
+		"rule `unknown_rule` is not defined by the policies",
+		"rule `unknown_rule` was expected to match",
+	}, failed.Failures)
+	assert.Equal(t, 3, report.Failures())
+}
+
+func TestFixtureNewEvent(t *testing.T) {
+	fixture := Fixture{
+		Type: "exec",
+		Fields: map[string]interface{}{
+			"exec.file.path": "/usr/bin/curl",
+			"exec.argv":      []interface{}{"-s", "https://example.org"},
+			"process.uid":    float64(1000),
+		},
+		Ancestors: []map[string]interface{}{
+			{"file.path": "/bin/sh"},
+			{"process.file.path": "/usr/sbin/sshd", "uid": 0},
+		},
+	}
+
+	event, err := fixture.NewEvent()
+	require.NoError(t, err)
+	assert.Equal(t, "exec", event.GetType())
+	assert.Equal(t, "curl", event.Exec.Process.BasenameStr)
+	assert.Equal(t, "/usr/bin/curl", event.ProcessContext.Process.PathnameStr)
+	assert.Equal(t, []string{"-s", "https://example.org"}, event.ProcessContext.Process.Argv)
+	assert.Equal(t, uint32(1000), event.ProcessContext.Process.UID)
+
+	value, err := event.GetFieldValue("process.ancestors.file.name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "sshd"}, value)
+
+	for _, fixture := range []Fixture{
+		{Type: "unknown"},
+		{Type: "open", Fields: map[string]interface{}{"open.unknown": "value"}},
+		{Type: "open", Fields: map[string]interface{}{"open.flags": "O_UNKNOWN"}},
+		{Type: "open", Fields: map[string]interface{}{"open.flags": 1.5}},
+		{Type: "open", Fields: map[string]interface{}{"open.file.path": 1}},
+	} {
+		_, err := fixture.NewEvent()
+		assert.Error(t, err, fixture)
+	}
+}
+
+func TestFixtureTimestamp(t *testing.T) {
+	fixtures, err := LoadFixtures("testdata/fixtures.yaml")
+	require.NoError(t, err)
+
+	fixture := fixtures[0]
+	fixture.Timestamp = time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	event, err := fixture.NewEvent()
+	require.NoError(t, err)
+	assert.Equal(t, fixture.Timestamp, event.GetTimestamp())
+}
+
+func TestConstantValue(t *testing.T) {
+	value, err := constantValue("O_CREAT | O_WRONLY")
+	require.NoError(t, err)
+	creat := model.SECLConstants["O_CREAT"].(*eval.IntEvaluator).Value
+	wronly := model.SECLConstants["O_WRONLY"].(*eval.IntEvaluator).Value
+	assert.Equal(t, creat|wronly, value)
+
+	_, err = constantValue("O_CREAT | O_UNKNOWN")
+	assert.Error(t, err)
+}
@@ -251,6 +251,11 @@ func (ad *ActivityDump) Done() {
 
 	ad.close()
 
+	// merge the dump in the security profile of its image
+	if profileManager := ad.adm.probe.monitor.profileManager; profileManager != nil {
+		profileManager.InsertActivityDump(ad)
+	}
+
 	// release all shared resources
 	for _, p := range ad.ProcessActivityTree {
 		p.recursiveRelease()
@@ -143,4 +143,7 @@ func init() {
 	allApproversHandlers["mmap"] = mmapOnNewApprovers
 	allApproversHandlers["mprotect"] = mprotectOnNewApprovers
 	allApproversHandlers["splice"] = spliceOnNewApprovers
+	allApproversHandlers["connect"] = socketOnNewApproversWrapper(model.ConnectEventType)
+	allApproversHandlers["bind"] = socketOnNewApproversWrapper(model.BindEventType)
+	allApproversHandlers["accept"] = socketOnNewApproversWrapper(model.AcceptEventType)
 }
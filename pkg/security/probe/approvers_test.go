@@ -71,3 +71,29 @@ func TestApproverAncestors2(t *testing.T) {
 		t.Fatalf("expected approver not found: %v", values)
 	}
 }
+
+func TestApproverSocketFamily(t *testing.T) {
+	enabled := map[eval.EventType]bool{"*": true}
+
+	var opts rules.Opts
+	opts.
+		WithConstants(model.SECLConstants).
+		WithEventTypeEnabled(enabled).
+		WithLegacyFields(model.SECLLegacyFields).
+		WithLogger(&seclog.PatternLogger{})
+
+	m := &model.Model{}
+	rs := rules.NewRuleSet(m, m.NewEvent, &opts)
+	addRuleExpr(t, rs, `connect.addr.family == AF_INET && connect.addr.ip in [10.0.0.0/8, 192.168.0.0/16]`, `connect.addr.family == AF_INET6 && connect.addr.ip == ::1`)
+	capabilities, exists := allCapabilities["connect"]
+	if !exists {
+		t.Fatal("no capabilities for connect")
+	}
+	approvers, err := rs.GetEventApprovers("connect", capabilities.GetFieldCapabilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values, exists := approvers["connect.addr.family"]; !exists || len(values) != 2 {
+		t.Fatalf("expected approver not found: %v", values)
+	}
+}
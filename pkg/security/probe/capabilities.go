@@ -138,5 +138,8 @@ func init() {
 	allCapabilities["utimes"] = oneBasenameCapabilities("utimes")
 	allCapabilities["mmap"] = mmapCapabilities
 	allCapabilities["mprotect"] = mprotectCapabilities
+	allCapabilities["connect"] = socketCapabilities("connect")
+	allCapabilities["bind"] = socketCapabilities("bind")
+	allCapabilities["accept"] = socketCapabilities("accept")
 	allCapabilities["splice"] = spliceCapabilities
 }
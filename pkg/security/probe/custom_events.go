@@ -30,6 +30,8 @@ const (
 	NoisyProcessRuleID = "noisy_process"
 	// AbnormalPathRuleID is the rule ID for the abnormal_path events
 	AbnormalPathRuleID = "abnormal_path"
+	// AnomalyDetectionRuleID is the rule ID for the anomaly_detection events
+	AnomalyDetectionRuleID = "anomaly_detection"
 )
 
 // AllCustomRuleIDs returns the list of custom rule IDs
@@ -39,6 +41,7 @@ func AllCustomRuleIDs() []string {
 		RulesetLoadedRuleID,
 		NoisyProcessRuleID,
 		AbnormalPathRuleID,
+		AnomalyDetectionRuleID,
 	}
 }
 
@@ -324,3 +327,28 @@ func NewAbnormalPathEvent(event *Event, pathResolutionError error) (*rules.Rule,
 			PathResolutionError: pathResolutionError.Error(),
 		})
 }
+
+// AnomalyDetectionEvent is used to report activity which doesn't match the security profile of a workload
+// easyjson:json
+type AnomalyDetectionEvent struct {
+	Timestamp   time.Time        `json:"date"`
+	Selector    string           `json:"profile_selector"`
+	AnomalyType AnomalyType      `json:"anomaly_type"`
+	Value       string           `json:"value"`
+	Lineage     []string         `json:"lineage,omitempty"`
+	Event       *EventSerializer `json:"triggering_event"`
+}
+
+// NewAnomalyDetectionEvent returns the rule and a populated custom event for an anomaly_detection event
+func NewAnomalyDetectionEvent(event *Event, profile *SecurityProfile, anomalyType AnomalyType, value string, lineage []string) (*rules.Rule, *CustomEvent) {
+	return newRule(&rules.RuleDefinition{
+			ID: AnomalyDetectionRuleID,
+		}), newCustomEvent(model.CustomAnomalyDetectionEventType, AnomalyDetectionEvent{
+			Timestamp:   event.ResolveEventTimestamp(),
+			Selector:    profile.GetSelectorStr(),
+			AnomalyType: anomalyType,
+			Value:       value,
+			Lineage:     lineage,
+			Event:       NewEventSerializer(event),
+		})
+}
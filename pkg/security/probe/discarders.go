@@ -625,4 +625,7 @@ func init() {
 	allDiscarderHandlers["load_module"] = processDiscarderWrapper(model.LoadModuleEventType, nil)
 	allDiscarderHandlers["unload_module"] = processDiscarderWrapper(model.UnloadModuleEventType, nil)
 	allDiscarderHandlers["signal"] = processDiscarderWrapper(model.SignalEventType, nil)
+	allDiscarderHandlers["connect"] = processDiscarderWrapper(model.ConnectEventType, nil)
+	allDiscarderHandlers["bind"] = processDiscarderWrapper(model.BindEventType, nil)
+	allDiscarderHandlers["accept"] = processDiscarderWrapper(model.AcceptEventType, nil)
 }
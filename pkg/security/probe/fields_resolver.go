@@ -31,6 +31,10 @@ func (ev *Event) ResolveFields() {
 	// resolve event specific fields
 	switch ev.GetEventType().String() {
 
+	case "accept":
+
+	case "bind":
+
 	case "bpf":
 		_ = ev.ResolveHelpers(&ev.BPF.Program)
 
@@ -54,6 +58,8 @@ func (ev *Event) ResolveFields() {
 		_ = ev.ResolveChownUID(&ev.Chown)
 		_ = ev.ResolveChownGID(&ev.Chown)
 
+	case "connect":
+
 	case "dns":
 
 	case "exec":
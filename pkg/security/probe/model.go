@@ -483,6 +483,11 @@ func (ev *Event) ResolveEventTimestamp() time.Time {
 	return ev.Timestamp
 }
 
+// GetTimestamp returns the time at which the event occurred
+func (ev *Event) GetTimestamp() time.Time {
+	return ev.ResolveEventTimestamp()
+}
+
 // ResolveProcessCacheEntry queries the ProcessResolver to retrieve the ProcessCacheEntry of the event
 func (ev *Event) ResolveProcessCacheEntry() *model.ProcessCacheEntry {
 	if ev.processCacheEntry == nil {
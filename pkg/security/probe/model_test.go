@@ -9,6 +9,7 @@
 package probe
 
 import (
+	"net"
 	"reflect"
 	"sort"
 	"testing"
@@ -38,6 +39,11 @@ func TestSetFieldValue(t *testing.T) {
 			if err = event.SetFieldValue(field, true); err != nil {
 				t.Error(err)
 			}
+		case reflect.Struct:
+			_, ipnet, _ := net.ParseCIDR("192.168.0.0/24")
+			if err = event.SetFieldValue(field, *ipnet); err != nil {
+				t.Error(err)
+			}
 		default:
 			t.Errorf("type unknown: %v", kind)
 		}
@@ -35,6 +35,7 @@ type Monitor struct {
 	syscallMonitor      *SyscallMonitor
 	reordererMonitor    *ReordererMonitor
 	activityDumpManager *ActivityDumpManager
+	profileManager      *SecurityProfileManager
 	runtimeMonitor      *RuntimeMonitor
 	discarderMonitor    *DiscarderMonitor
 }
@@ -71,6 +72,13 @@ func NewMonitor(p *Probe, client *statsd.Client) (*Monitor, error) {
 		}
 	}
 
+	if p.config.SecurityProfileEnabled {
+		m.profileManager, err = NewSecurityProfileManager(p, client)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't create the security profile manager")
+		}
+	}
+
 	// create a new syscall monitor if requested
 	if p.config.SyscallMonitor {
 		m.syscallMonitor, err = NewSyscallMonitor(p.manager)
@@ -102,6 +110,9 @@ func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	if m.activityDumpManager != nil {
 		delta++
 	}
+	if m.profileManager != nil {
+		delta++
+	}
 	wg.Add(delta)
 
 	go m.loadController.Start(ctx, wg)
@@ -110,6 +121,10 @@ func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	if m.activityDumpManager != nil {
 		go m.activityDumpManager.Start(ctx, wg)
 	}
+
+	if m.profileManager != nil {
+		go m.profileManager.Start(ctx, wg)
+	}
 	return nil
 }
 
@@ -154,6 +169,12 @@ func (m *Monitor) SendStats() error {
 		}
 	}
 
+	if m.profileManager != nil {
+		if err := m.profileManager.SendStats(); err != nil {
+			return errors.Wrap(err, "failed to send security profile manager stats")
+		}
+	}
+
 	if m.probe.config.RuntimeMonitor {
 		if err := m.runtimeMonitor.SendStats(); err != nil {
 			return errors.Wrap(err, "failed to send runtime monitor stats")
@@ -198,6 +219,9 @@ func (m *Monitor) ProcessEvent(event *Event, size uint64, CPU int, perfMap *mana
 		if m.activityDumpManager != nil {
 			m.activityDumpManager.ProcessEvent(event)
 		}
+		if m.profileManager != nil {
+			m.profileManager.ProcessEvent(event)
+		}
 	}
 }
 
@@ -0,0 +1,471 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecurityProfileStatus defines the state of a security profile
+type SecurityProfileStatus string
+
+const (
+	// SecurityProfileLearning is the state of a profile which is still learning the activity of a workload
+	SecurityProfileLearning SecurityProfileStatus = "learning"
+	// SecurityProfileEnforcing is the state of a profile against which the activity of a workload is evaluated
+	SecurityProfileEnforcing SecurityProfileStatus = "enforcing"
+)
+
+// AnomalyType defines the kind of activity that didn't match a security profile
+type AnomalyType string
+
+const (
+	// ProcessAnomaly is reported when a process lineage isn't part of a profile
+	ProcessAnomaly AnomalyType = "process"
+	// FileAnomaly is reported when a process opens a file which isn't part of a profile
+	FileAnomaly AnomalyType = "file"
+	// DNSAnomaly is reported when a process resolves a domain name which isn't part of a profile
+	DNSAnomaly AnomalyType = "dns"
+)
+
+// ProfileProcessNode holds the activity of a process in a security profile. Files and DNS names are kept sorted and
+// may contain glob patterns.
+type ProfileProcessNode struct {
+	Path     string                `json:"path"`
+	Files    []string              `json:"files,omitempty"`
+	DNSNames []string              `json:"dns,omitempty"`
+	Children []*ProfileProcessNode `json:"children,omitempty"`
+}
+
+const (
+	// fileCollapseThreshold is the number of files of a directory above which they are replaced by a glob pattern
+	// matching every file of the directory
+	fileCollapseThreshold = 16
+)
+
+// securityProfileLimits caps the size of a security profile, zero means no limit
+type securityProfileLimits struct {
+	maxProcessNodes int
+	maxFiles        int
+	maxDNSNames     int
+}
+
+// isNumeric returns true if the provided string is only made of digits
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// normalizeFilePath cleans a file path and replaces its numeric components, such as pids or file descriptors, with a
+// glob pattern so that they don't grow the profile with one entry per value
+func normalizeFilePath(filePath string) string {
+	filePath = path.Clean(filePath)
+	if !strings.ContainsAny(filePath, "0123456789") {
+		return filePath
+	}
+
+	parts := strings.Split(filePath, "/")
+	for i, part := range parts {
+		if isNumeric(part) {
+			parts[i] = "*"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// normalizeDNSName returns the canonical form of a domain name
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+// matchEntry returns true if the value is part of the provided sorted list of entries, either as is or through one of
+// the glob patterns of the list
+func matchEntry(entries []string, value string) bool {
+	if i := sort.SearchStrings(entries, value); i < len(entries) && entries[i] == value {
+		return true
+	}
+	for _, entry := range entries {
+		if !strings.ContainsAny(entry, "*?[") {
+			continue
+		}
+		if matched, _ := path.Match(entry, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// insertEntry inserts the value in the provided sorted list of entries. The second return value is false if the
+// value was already matched by the list.
+func insertEntry(entries []string, value string) ([]string, bool) {
+	if matchEntry(entries, value) {
+		return entries, false
+	}
+	i := sort.SearchStrings(entries, value)
+	entries = append(entries, "")
+	copy(entries[i+1:], entries[i:])
+	entries[i] = value
+	return entries, true
+}
+
+// collapseEntries replaces the entries of the provided directory with a glob pattern matching every file of the
+// directory once there are more than fileCollapseThreshold of them. It returns the number of removed entries.
+func collapseEntries(entries []string, dir string) ([]string, int) {
+	if dir == "/" || dir == "." {
+		return entries, 0
+	}
+
+	var count int
+	for _, entry := range entries {
+		if path.Dir(entry) == dir {
+			count++
+		}
+	}
+	if count <= fileCollapseThreshold {
+		return entries, 0
+	}
+
+	kept := entries[:0]
+	for _, entry := range entries {
+		if path.Dir(entry) != dir {
+			kept = append(kept, entry)
+		}
+	}
+	kept, _ = insertEntry(kept, path.Join(dir, "*"))
+	return kept, count - 1
+}
+
+// findChild returns the node of the provided list matching the process path
+func findChild(nodes []*ProfileProcessNode, processPath string) *ProfileProcessNode {
+	for _, node := range nodes {
+		if node.Path == processPath {
+			return node
+		}
+	}
+	for _, node := range nodes {
+		if matched, _ := path.Match(node.Path, processPath); matched {
+			return node
+		}
+	}
+	return nil
+}
+
+// SecurityProfile holds the activity learnt for the containers of an image: the tree of the processes they executed,
+// and the files and the domain names each of these processes accessed
+type SecurityProfile struct {
+	sync.Mutex `json:"-"`
+	// saveLock makes sure the snapshots of the profile are written to disk in order
+	saveLock sync.Mutex
+
+	ImageName     string                `json:"image_name"`
+	ImageTag      string                `json:"image_tag"`
+	Status        SecurityProfileStatus `json:"status"`
+	LearningStart time.Time             `json:"learning_start"`
+	LastUpdate    time.Time             `json:"last_update"`
+	Processes     []*ProfileProcessNode `json:"processes,omitempty"`
+
+	dirty  bool
+	limits securityProfileLimits
+	// processCount, fileCount and dnsCount are the number of process nodes, files and DNS names of the profile
+	processCount int
+	fileCount    int
+	dnsCount     int
+	// droppedEntries is the number of entries which weren't learnt because the profile reached one of its limits
+	droppedEntries int
+}
+
+// NewSecurityProfile returns a new security profile in the learning state
+func NewSecurityProfile(imageName, imageTag string, now time.Time) *SecurityProfile {
+	return &SecurityProfile{
+		ImageName:     imageName,
+		ImageTag:      imageTag,
+		Status:        SecurityProfileLearning,
+		LearningStart: now,
+		LastUpdate:    now,
+		dirty:         true,
+	}
+}
+
+func securityProfileSelector(imageName, imageTag string) string {
+	return fmt.Sprintf("image_name:%s,image_tag:%s", imageName, imageTag)
+}
+
+// GetSelectorStr returns a string representation of the profile selector
+func (sp *SecurityProfile) GetSelectorStr() string {
+	return securityProfileSelector(sp.ImageName, sp.ImageTag)
+}
+
+// securityProfileFilename returns the name of the file the profile of an image is persisted to. The name is a hash of
+// the selector so that two images never share the same file.
+func securityProfileFilename(imageName, imageTag string) string {
+	return fmt.Sprintf("%x.json", sha256.Sum256([]byte(securityProfileSelector(imageName, imageTag))))
+}
+
+// filename returns the name of the file the profile is persisted to
+func (sp *SecurityProfile) filename() string {
+	return securityProfileFilename(sp.ImageName, sp.ImageTag)
+}
+
+// findProcessNode returns the node matching the provided lineage, the list of the paths of a process and its
+// ancestors starting with the oldest one. When create is true, the missing nodes are added to the profile as long as
+// it doesn't reach its maximum number of process nodes.
+func (sp *SecurityProfile) findProcessNode(lineage []string, create bool) (*ProfileProcessNode, bool) {
+	var node *ProfileProcessNode
+	var added bool
+
+	children := &sp.Processes
+	for _, processPath := range lineage {
+		processPath = path.Clean(processPath)
+		node = findChild(*children, processPath)
+		if node == nil {
+			if !create || (sp.limits.maxProcessNodes > 0 && sp.processCount >= sp.limits.maxProcessNodes) {
+				return nil, added
+			}
+			node = &ProfileProcessNode{Path: processPath}
+			*children = append(*children, node)
+			sp.processCount++
+			added = true
+		}
+		children = &node.Children
+	}
+	return node, added
+}
+
+// insert adds the activity to the profile, returns true if the profile was updated. The activity exceeding the limits
+// of the profile is dropped.
+func (sp *SecurityProfile) insert(lineage []string, anomalyType AnomalyType, value string) bool {
+	if len(lineage) == 0 {
+		return false
+	}
+
+	node, added := sp.findProcessNode(lineage, true)
+	if node == nil {
+		sp.droppedEntries++
+		return added
+	}
+
+	switch anomalyType {
+	case FileAnomaly:
+		value = normalizeFilePath(value)
+		if matchEntry(node.Files, value) {
+			break
+		}
+		if sp.limits.maxFiles > 0 && sp.fileCount >= sp.limits.maxFiles {
+			sp.droppedEntries++
+			break
+		}
+		var removed int
+		node.Files, _ = insertEntry(node.Files, value)
+		node.Files, removed = collapseEntries(node.Files, path.Dir(value))
+		sp.fileCount += 1 - removed
+		added = true
+	case DNSAnomaly:
+		value = normalizeDNSName(value)
+		if matchEntry(node.DNSNames, value) {
+			break
+		}
+		if sp.limits.maxDNSNames > 0 && sp.dnsCount >= sp.limits.maxDNSNames {
+			sp.droppedEntries++
+			break
+		}
+		node.DNSNames, _ = insertEntry(node.DNSNames, value)
+		sp.dnsCount++
+		added = true
+	}
+	return added
+}
+
+// match returns the anomaly of the activity, an empty anomaly type means that the activity is part of the profile
+func (sp *SecurityProfile) match(lineage []string, anomalyType AnomalyType, value string) (AnomalyType, string) {
+	if len(lineage) == 0 {
+		return "", ""
+	}
+
+	node, _ := sp.findProcessNode(lineage, false)
+	if node == nil {
+		return ProcessAnomaly, lineage[len(lineage)-1]
+	}
+
+	switch anomalyType {
+	case FileAnomaly:
+		if !matchEntry(node.Files, normalizeFilePath(value)) {
+			return FileAnomaly, value
+		}
+	case DNSAnomaly:
+		if !matchEntry(node.DNSNames, normalizeDNSName(value)) {
+			return DNSAnomaly, value
+		}
+	}
+	return "", ""
+}
+
+// updateStatus switches the profile to the enforcing state once the learning period is over
+func (sp *SecurityProfile) updateStatus(now time.Time, learningPeriod time.Duration) {
+	if sp.Status == SecurityProfileLearning && !now.Before(sp.LearningStart.Add(learningPeriod)) {
+		sp.Status = SecurityProfileEnforcing
+		sp.dirty = true
+	}
+}
+
+// Evaluate learns the activity while the profile is learning and checks it against the profile once it is enforcing.
+// It returns the type and the value of the anomaly, the anomaly type is empty when the activity matches the profile.
+func (sp *SecurityProfile) Evaluate(lineage []string, anomalyType AnomalyType, value string, now time.Time, learningPeriod time.Duration) (AnomalyType, string) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.updateStatus(now, learningPeriod)
+
+	if sp.Status == SecurityProfileLearning {
+		if sp.insert(lineage, anomalyType, value) {
+			sp.LastUpdate = now
+			sp.dirty = true
+		}
+		return "", ""
+	}
+	return sp.match(lineage, anomalyType, value)
+}
+
+// insertActivityNode adds the processes and the opened files of an activity dump node to the profile
+func (sp *SecurityProfile) insertActivityNode(pan *ProcessActivityNode, lineage []string) {
+	lineage = append(lineage[:len(lineage):len(lineage)], pan.Process.PathnameStr)
+	sp.insert(lineage, ProcessAnomaly, pan.Process.PathnameStr)
+
+	var insertFiles func(fan *FileActivityNode)
+	insertFiles = func(fan *FileActivityNode) {
+		if fan.File != nil && fan.Open != nil {
+			sp.insert(lineage, FileAnomaly, fan.File.PathnameStr)
+		}
+		for _, child := range fan.Children {
+			insertFiles(child)
+		}
+	}
+	for _, file := range pan.Files {
+		insertFiles(file)
+	}
+
+	for _, child := range pan.Children {
+		sp.insertActivityNode(child, lineage)
+	}
+}
+
+// InsertActivityDump merges the process tree and the opened files of an activity dump in the profile
+func (sp *SecurityProfile) InsertActivityDump(ad *ActivityDump) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	for _, root := range ad.ProcessActivityTree {
+		sp.insertActivityNode(root, nil)
+	}
+	sp.LastUpdate = time.Now()
+	sp.dirty = true
+}
+
+// Save writes the profile to the provided directory if it changed since it was last saved. The profile is only locked
+// while its snapshot is taken, not while it is written.
+func (sp *SecurityProfile) Save(directory string) error {
+	sp.saveLock.Lock()
+	defer sp.saveLock.Unlock()
+
+	sp.Lock()
+	if !sp.dirty {
+		sp.Unlock()
+		return nil
+	}
+	raw, err := json.MarshalIndent(sp, "", "  ")
+	if err == nil {
+		sp.dirty = false
+	}
+	sp.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("couldn't marshal security profile %s: %w", sp.GetSelectorStr(), err)
+	}
+
+	if err := sp.write(directory, raw); err != nil {
+		sp.Lock()
+		sp.dirty = true
+		sp.Unlock()
+		return err
+	}
+	return nil
+}
+
+// write writes the snapshot of the profile to the provided directory
+func (sp *SecurityProfile) write(directory string, raw []byte) error {
+
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return fmt.Errorf("couldn't create security profile directory: %w", err)
+	}
+
+	// write to a temporary file first so that a profile is never partially written
+	filename := filepath.Join(directory, sp.filename())
+	if err := os.WriteFile(filename+".tmp", raw, 0640); err != nil {
+		return fmt.Errorf("couldn't write security profile %s: %w", sp.GetSelectorStr(), err)
+	}
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		return fmt.Errorf("couldn't write security profile %s: %w", sp.GetSelectorStr(), err)
+	}
+	return nil
+}
+
+// LoadSecurityProfile reads a security profile from the provided file
+func LoadSecurityProfile(filename string) (*SecurityProfile, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var sp SecurityProfile
+	if err := json.Unmarshal(raw, &sp); err != nil {
+		return nil, fmt.Errorf("couldn't parse security profile %s: %w", filename, err)
+	}
+	if len(sp.ImageName) == 0 {
+		return nil, fmt.Errorf("security profile %s has no image name", filename)
+	}
+	if len(sp.ImageTag) == 0 {
+		sp.ImageTag = "latest"
+	}
+
+	switch sp.Status {
+	case SecurityProfileLearning, SecurityProfileEnforcing:
+	case "":
+		sp.Status = SecurityProfileEnforcing
+	default:
+		return nil, fmt.Errorf("security profile %s has an unknown status: %s", filename, sp.Status)
+	}
+
+	// make sure the lookups can rely on sorted lists, profiles may have been edited by hand
+	var sortNodes func(nodes []*ProfileProcessNode)
+	sortNodes = func(nodes []*ProfileProcessNode) {
+		for _, node := range nodes {
+			sort.Strings(node.Files)
+			sort.Strings(node.DNSNames)
+			sp.processCount++
+			sp.fileCount += len(node.Files)
+			sp.dnsCount += len(node.DNSNames)
+			sortNodes(node.Children)
+		}
+	}
+	sortNodes(sp.Processes)
+
+	return &sp, nil
+}
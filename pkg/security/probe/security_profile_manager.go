@@ -0,0 +1,432 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+const (
+	// securityProfileSavePeriod is the period at which the modified profiles are written to disk
+	securityProfileSavePeriod = time.Minute
+	// reportedAnomaliesCacheSize is the number of anomalies remembered to avoid reporting the same anomaly twice
+	reportedAnomaliesCacheSize = 1024
+)
+
+// SecurityProfileManager is used to learn the security profiles of the container images and to evaluate the live
+// events against them
+type SecurityProfileManager struct {
+	sync.Mutex
+	probe          *Probe
+	statsdClient   *statsd.Client
+	directory      string
+	learningPeriod time.Duration
+	eventTypes     []model.EventType
+	limits         securityProfileLimits
+
+	// profiles is the cache of the profiles in memory, the evicted profiles are written to disk
+	profiles *simplelru.LRU
+	// evictedProfiles holds the profiles evicted from the cache until they are written to disk
+	evictedProfiles map[string]*SecurityProfile
+	// pendingProfiles holds the selectors of the profiles being loaded from disk
+	pendingProfiles   map[string]bool
+	reportedAnomalies *simplelru.LRU
+}
+
+// NewSecurityProfileManager returns a new SecurityProfileManager instance, loaded with the profiles found on disk
+func NewSecurityProfileManager(p *Probe, client *statsd.Client) (*SecurityProfileManager, error) {
+	reportedAnomalies, err := simplelru.NewLRU(reportedAnomaliesCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	spm := &SecurityProfileManager{
+		probe:          p,
+		statsdClient:   client,
+		directory:      p.config.SecurityProfileDir,
+		learningPeriod: p.config.SecurityProfileLearningPeriod,
+		eventTypes:     p.config.SecurityProfileEventTypes,
+		limits: securityProfileLimits{
+			maxProcessNodes: p.config.SecurityProfileMaxProcessNodes,
+			maxFiles:        p.config.SecurityProfileMaxFiles,
+			maxDNSNames:     p.config.SecurityProfileMaxDNSNames,
+		},
+		evictedProfiles:   make(map[string]*SecurityProfile),
+		pendingProfiles:   make(map[string]bool),
+		reportedAnomalies: reportedAnomalies,
+	}
+
+	spm.profiles, err = simplelru.NewLRU(p.config.SecurityProfileCacheSize, spm.onEvict)
+	if err != nil {
+		return nil, fmt.Errorf("invalid security profile cache size: %w", err)
+	}
+
+	if err := spm.loadProfiles(); err != nil {
+		return nil, err
+	}
+	return spm, nil
+}
+
+// onEvict keeps the profiles evicted from the cache until saveEvictedProfiles writes them to disk, spm must be locked
+func (spm *SecurityProfileManager) onEvict(key interface{}, value interface{}) {
+	spm.evictedProfiles[key.(string)] = value.(*SecurityProfile)
+}
+
+// saveEvictedProfiles writes the profiles evicted from the cache to disk, spm must not be locked
+func (spm *SecurityProfileManager) saveEvictedProfiles() {
+	spm.Lock()
+	evicted := make([]*SecurityProfile, 0, len(spm.evictedProfiles))
+	for _, profile := range spm.evictedProfiles {
+		evicted = append(evicted, profile)
+	}
+	spm.Unlock()
+
+	for _, profile := range evicted {
+		if err := profile.Save(spm.directory); err != nil {
+			seclog.Errorf("couldn't save evicted security profile: %v", err)
+		} else {
+			seclog.Debugf("security profile for [%s] evicted from the cache", profile.GetSelectorStr())
+		}
+
+		// the profile may have been brought back in the cache in the meantime
+		spm.Lock()
+		if spm.evictedProfiles[profile.GetSelectorStr()] == profile {
+			delete(spm.evictedProfiles, profile.GetSelectorStr())
+		}
+		spm.Unlock()
+	}
+}
+
+// loadProfiles loads the profiles of the profiles directory, up to the size of the cache
+func (spm *SecurityProfileManager) loadProfiles() error {
+	files, err := filepath.Glob(filepath.Join(spm.directory, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		profile, err := LoadSecurityProfile(file)
+		if err != nil {
+			seclog.Errorf("couldn't load security profile: %v", err)
+			continue
+		}
+		profile.limits = spm.limits
+		spm.profiles.Add(profile.GetSelectorStr(), profile)
+		seclog.Infof("security profile for [%s] loaded from %s (%s)", profile.GetSelectorStr(), file, profile.Status)
+	}
+
+	// the profiles which didn't fit in the cache are left on disk
+	spm.evictedProfiles = make(map[string]*SecurityProfile)
+	return nil
+}
+
+// Start runs the SecurityProfileManager
+func (spm *SecurityProfileManager) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(securityProfileSavePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			spm.saveProfiles()
+			return
+		case <-ticker.C:
+			spm.updateProfiles()
+			spm.saveProfiles()
+		}
+	}
+}
+
+// updateProfiles moves the profiles whose learning period is over to the enforcing state, even if their workload
+// was idle
+func (spm *SecurityProfileManager) updateProfiles() {
+	spm.Lock()
+	defer spm.Unlock()
+
+	now := time.Now()
+	for _, profile := range spm.cachedProfiles() {
+		profile.Lock()
+		profile.updateStatus(now, spm.learningPeriod)
+		profile.Unlock()
+	}
+}
+
+// saveProfiles writes the modified profiles to disk
+func (spm *SecurityProfileManager) saveProfiles() {
+	spm.Lock()
+	profiles := spm.cachedProfiles()
+	spm.Unlock()
+
+	for _, profile := range profiles {
+		if err := profile.Save(spm.directory); err != nil {
+			seclog.Errorf("couldn't save security profile: %v", err)
+		}
+	}
+	spm.saveEvictedProfiles()
+}
+
+// cachedProfiles returns the profiles of the cache without updating their recency, spm must be locked
+func (spm *SecurityProfileManager) cachedProfiles() []*SecurityProfile {
+	keys := spm.profiles.Keys()
+	profiles := make([]*SecurityProfile, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := spm.profiles.Peek(key); ok {
+			profiles = append(profiles, value.(*SecurityProfile))
+		}
+	}
+	return profiles
+}
+
+// getProfile returns the cached profile of the provided image. On a cache miss, the profile is loaded asynchronously
+// and nil is returned, so that the event path never waits for the disk.
+func (spm *SecurityProfileManager) getProfile(imageName, imageTag string) *SecurityProfile {
+	selector := securityProfileSelector(imageName, imageTag)
+
+	spm.Lock()
+	defer spm.Unlock()
+
+	if existing, ok := spm.profiles.Get(selector); ok {
+		return existing.(*SecurityProfile)
+	}
+
+	if !spm.pendingProfiles[selector] {
+		spm.pendingProfiles[selector] = true
+		go func() {
+			spm.fetchProfile(imageName, imageTag)
+
+			spm.Lock()
+			delete(spm.pendingProfiles, selector)
+			spm.Unlock()
+		}()
+	}
+	return nil
+}
+
+// fetchProfile returns the profile of the provided image, loading it from disk if it isn't cached. The disk is
+// accessed without holding the lock of the manager.
+func (spm *SecurityProfileManager) fetchProfile(imageName, imageTag string) *SecurityProfile {
+	selector := securityProfileSelector(imageName, imageTag)
+
+	spm.Lock()
+	profile := spm.lookupProfile(selector)
+	spm.Unlock()
+
+	if profile == nil {
+		loaded := spm.loadProfile(imageName, imageTag)
+
+		// the profile may have been added while it was being loaded
+		spm.Lock()
+		if profile = spm.lookupProfile(selector); profile == nil {
+			profile = loaded
+			spm.profiles.Add(selector, profile)
+		}
+		spm.Unlock()
+	}
+
+	spm.saveEvictedProfiles()
+	return profile
+}
+
+// lookupProfile returns the profile matching the selector from the cache, or from the evicted profiles which aren't
+// written to disk yet, spm must be locked
+func (spm *SecurityProfileManager) lookupProfile(selector string) *SecurityProfile {
+	if existing, ok := spm.profiles.Get(selector); ok {
+		return existing.(*SecurityProfile)
+	}
+
+	if evicted, ok := spm.evictedProfiles[selector]; ok {
+		delete(spm.evictedProfiles, selector)
+		spm.profiles.Add(selector, evicted)
+		return evicted
+	}
+	return nil
+}
+
+// loadProfile reads the profile of the provided image from disk, a new learning profile is created if none exists
+func (spm *SecurityProfileManager) loadProfile(imageName, imageTag string) *SecurityProfile {
+	selector := securityProfileSelector(imageName, imageTag)
+	filename := filepath.Join(spm.directory, securityProfileFilename(imageName, imageTag))
+
+	profile, err := LoadSecurityProfile(filename)
+	if err == nil && profile.GetSelectorStr() == selector {
+		seclog.Debugf("security profile for [%s] loaded from %s", selector, filename)
+	} else {
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			seclog.Errorf("couldn't load security profile: %v", err)
+		}
+		profile = NewSecurityProfile(imageName, imageTag, time.Now())
+		seclog.Infof("learning a new security profile for [%s]", selector)
+	}
+	profile.limits = spm.limits
+	return profile
+}
+
+// resolveImage returns the image name and tag of a container, the name is empty if the tags of the container
+// aren't resolved yet
+func (spm *SecurityProfileManager) resolveImage(containerID string) (string, string) {
+	tags := spm.probe.resolvers.TagsResolver.Resolve(containerID)
+	imageName := utils.GetTagValue("image_name", tags)
+	imageTag := utils.GetTagValue("image_tag", tags)
+	if len(imageTag) == 0 {
+		imageTag = "latest"
+	}
+	return imageName, imageTag
+}
+
+// processLineage returns the paths of a process and of its ancestors in the same container, starting with the oldest
+// one
+func processLineage(entry *model.ProcessCacheEntry) []string {
+	var lineage []string
+	for ancestor := entry; ancestor != nil && ancestor.ContainerID == entry.ContainerID; ancestor = ancestor.GetNextAncestorNoFork() {
+		lineage = append(lineage, ancestor.Process.PathnameStr)
+	}
+
+	for i, j := 0, len(lineage)-1; i < j; i, j = i+1, j-1 {
+		lineage[i], lineage[j] = lineage[j], lineage[i]
+	}
+	return lineage
+}
+
+// isEventTypeEvaluated returns true if the profiles should learn and evaluate the provided event type
+func (spm *SecurityProfileManager) isEventTypeEvaluated(eventType model.EventType) bool {
+	for _, evtType := range spm.eventTypes {
+		if evtType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// isNewAnomaly returns true if the anomaly wasn't recently reported
+func (spm *SecurityProfileManager) isNewAnomaly(profile *SecurityProfile, anomalyType AnomalyType, value string, lineage []string) bool {
+	key := strings.Join([]string{profile.GetSelectorStr(), string(anomalyType), value, strings.Join(lineage, ",")}, "|")
+
+	spm.Lock()
+	defer spm.Unlock()
+
+	if spm.reportedAnomalies.Contains(key) {
+		return false
+	}
+	spm.reportedAnomalies.Add(key, struct{}{})
+	return true
+}
+
+// ProcessEvent learns the activity of the event or evaluates it against the profile of its container image
+func (spm *SecurityProfileManager) ProcessEvent(event *Event) {
+	if !spm.isEventTypeEvaluated(event.GetEventType()) {
+		return
+	}
+
+	entry := event.ResolveProcessCacheEntry()
+	if entry == nil || len(entry.ContainerID) == 0 {
+		return
+	}
+
+	imageName, imageTag := spm.resolveImage(entry.ContainerID)
+	if len(imageName) == 0 {
+		return
+	}
+
+	var anomalyType AnomalyType
+	var value string
+	switch event.GetEventType() {
+	case model.ExecEventType:
+		anomalyType, value = ProcessAnomaly, entry.Process.PathnameStr
+	case model.FileOpenEventType:
+		anomalyType, value = FileAnomaly, event.ResolveFilePath(&event.Open.File)
+	case model.DNSEventType:
+		anomalyType, value = DNSAnomaly, event.DNS.Name
+	default:
+		return
+	}
+	if len(value) == 0 {
+		return
+	}
+
+	profile := spm.getProfile(imageName, imageTag)
+	if profile == nil {
+		return
+	}
+
+	lineage := processLineage(entry)
+	anomalyType, value = profile.Evaluate(lineage, anomalyType, value, time.Now(), spm.learningPeriod)
+	if len(anomalyType) == 0 || !spm.isNewAnomaly(profile, anomalyType, value, lineage) {
+		return
+	}
+
+	tags := []string{"anomaly_type:" + string(anomalyType)}
+	if err := spm.statsdClient.Count(metrics.MetricSecurityProfileAnomaly, 1, tags, 1.0); err != nil {
+		seclog.Warnf("couldn't send %s metric: %v", metrics.MetricSecurityProfileAnomaly, err)
+	}
+
+	spm.probe.DispatchCustomEvent(NewAnomalyDetectionEvent(event, profile, anomalyType, value, lineage))
+}
+
+// InsertActivityDump merges a finished activity dump in the profile of its container image
+func (spm *SecurityProfileManager) InsertActivityDump(ad *ActivityDump) {
+	imageName := utils.GetTagValue("image_name", ad.Tags)
+	if len(imageName) == 0 {
+		return
+	}
+	imageTag := utils.GetTagValue("image_tag", ad.Tags)
+	if len(imageTag) == 0 {
+		imageTag = "latest"
+	}
+
+	profile := spm.fetchProfile(imageName, imageTag)
+	profile.InsertActivityDump(ad)
+	seclog.Infof("activity dump for [%s] merged in the security profile for [%s]", ad.GetSelectorStr(), profile.GetSelectorStr())
+}
+
+// SendStats sends the security profile manager stats
+func (spm *SecurityProfileManager) SendStats() error {
+	spm.Lock()
+	defer spm.Unlock()
+
+	counts := make(map[SecurityProfileStatus]int)
+	var droppedEntries int
+	for _, profile := range spm.cachedProfiles() {
+		profile.Lock()
+		counts[profile.Status]++
+		droppedEntries += profile.droppedEntries
+		profile.droppedEntries = 0
+		profile.Unlock()
+	}
+
+	if droppedEntries > 0 {
+		if err := spm.statsdClient.Count(metrics.MetricSecurityProfileDroppedEntries, int64(droppedEntries), []string{}, 1.0); err != nil {
+			seclog.Errorf("couldn't send %s metric: %v", metrics.MetricSecurityProfileDroppedEntries, err)
+		}
+	}
+
+	for _, status := range []SecurityProfileStatus{SecurityProfileLearning, SecurityProfileEnforcing} {
+		tags := []string{"status:" + string(status)}
+		if err := spm.statsdClient.Gauge(metrics.MetricSecurityProfileProfiles, float64(counts[status]), tags, 1.0); err != nil {
+			seclog.Errorf("couldn't send %s metric: %v", metrics.MetricSecurityProfileProfiles, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func TestSecurityProfileLearning(t *testing.T) {
+	start := time.Now()
+	learningPeriod := 30 * time.Minute
+	sp := NewSecurityProfile("nginx", "1.21", start)
+
+	shell := []string{"/usr/sbin/nginx", "/bin/sh"}
+	nginx := []string{"/usr/sbin/nginx"}
+
+	// learning
+	learn := func(lineage []string, anomalyType AnomalyType, value string) {
+		anomaly, _ := sp.Evaluate(lineage, anomalyType, value, start.Add(time.Minute), learningPeriod)
+		assert.Empty(t, anomaly)
+	}
+	learn(nginx, ProcessAnomaly, "/usr/sbin/nginx")
+	learn(nginx, FileAnomaly, "/etc/nginx/nginx.conf")
+	learn(nginx, FileAnomaly, "/var/log/nginx/access.log")
+	learn(nginx, DNSAnomaly, "example.com")
+	learn(shell, ProcessAnomaly, "/bin/sh")
+	assert.Equal(t, SecurityProfileLearning, sp.Status)
+
+	require.Len(t, sp.Processes, 1)
+	assert.Equal(t, []string{"/etc/nginx/nginx.conf", "/var/log/nginx/access.log"}, sp.Processes[0].Files)
+	assert.Equal(t, []string{"example.com"}, sp.Processes[0].DNSNames)
+	require.Len(t, sp.Processes[0].Children, 1)
+	assert.Equal(t, "/bin/sh", sp.Processes[0].Children[0].Path)
+
+	// enforcing
+	evaluate := func(lineage []string, anomalyType AnomalyType, value string) (AnomalyType, string) {
+		return sp.Evaluate(lineage, anomalyType, value, start.Add(learningPeriod), learningPeriod)
+	}
+
+	anomaly, _ := evaluate(nginx, FileAnomaly, "/etc/nginx/nginx.conf")
+	assert.Empty(t, anomaly)
+	assert.Equal(t, SecurityProfileEnforcing, sp.Status)
+
+	anomaly, value := evaluate(nginx, FileAnomaly, "/etc/shadow")
+	assert.Equal(t, FileAnomaly, anomaly)
+	assert.Equal(t, "/etc/shadow", value)
+
+	anomaly, _ = evaluate(shell, FileAnomaly, "/etc/nginx/nginx.conf")
+	assert.Equal(t, FileAnomaly, anomaly)
+
+	anomaly, value = evaluate(nginx, DNSAnomaly, "evil.com")
+	assert.Equal(t, DNSAnomaly, anomaly)
+	assert.Equal(t, "evil.com", value)
+
+	anomaly, value = evaluate(append(shell, "/usr/bin/curl"), DNSAnomaly, "example.com")
+	assert.Equal(t, ProcessAnomaly, anomaly)
+	assert.Equal(t, "/usr/bin/curl", value)
+
+	// activity isn't learnt anymore
+	anomaly, _ = evaluate(nginx, FileAnomaly, "/etc/shadow")
+	assert.Equal(t, FileAnomaly, anomaly)
+}
+
+func TestSecurityProfilePatterns(t *testing.T) {
+	sp := NewSecurityProfile("nginx", "latest", time.Now())
+	sp.Status = SecurityProfileEnforcing
+	sp.Processes = []*ProfileProcessNode{
+		{
+			Path:     "/usr/sbin/nginx",
+			Files:    []string{"/etc/nginx/*.conf"},
+			DNSNames: []string{"*.example.com"},
+		},
+	}
+
+	tests := []struct {
+		anomalyType AnomalyType
+		value       string
+		anomaly     bool
+	}{
+		{FileAnomaly, "/etc/nginx/nginx.conf", false},
+		{FileAnomaly, "/etc/nginx/conf.d/default.conf", true},
+		{DNSAnomaly, "api.example.com", false},
+		{DNSAnomaly, "example.com", true},
+	}
+
+	for _, test := range tests {
+		anomaly, _ := sp.Evaluate([]string{"/usr/sbin/nginx"}, test.anomalyType, test.value, time.Now(), time.Hour)
+		assert.Equal(t, test.anomaly, len(anomaly) > 0, test.value)
+	}
+}
+
+func TestSecurityProfileInsertActivityDump(t *testing.T) {
+	ad := &ActivityDump{
+		ProcessActivityTree: []*ProcessActivityNode{
+			{
+				Process: model.Process{PathnameStr: "/usr/sbin/nginx"},
+				Files: map[string]*FileActivityNode{
+					"etc": {
+						Name: "etc",
+						Children: map[string]*FileActivityNode{
+							"passwd": {
+								Name: "passwd",
+								File: &model.FileEvent{PathnameStr: "/etc/passwd"},
+								Open: &OpenNode{},
+							},
+						},
+					},
+				},
+				Children: []*ProcessActivityNode{
+					{Process: model.Process{PathnameStr: "/bin/sh"}},
+				},
+			},
+		},
+	}
+
+	sp := NewSecurityProfile("nginx", "latest", time.Now())
+	sp.InsertActivityDump(ad)
+
+	require.Len(t, sp.Processes, 1)
+	assert.Equal(t, "/usr/sbin/nginx", sp.Processes[0].Path)
+	assert.Equal(t, []string{"/etc/passwd"}, sp.Processes[0].Files)
+	require.Len(t, sp.Processes[0].Children, 1)
+	assert.Equal(t, "/bin/sh", sp.Processes[0].Children[0].Path)
+}
+
+func TestSecurityProfilePersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	sp := NewSecurityProfile("datadog/agent", "7.40.0", time.Now())
+	sp.insert([]string{"/opt/datadog-agent/bin/agent/agent"}, FileAnomaly, "/etc/datadog-agent/datadog.yaml")
+	require.NoError(t, sp.Save(dir))
+
+	filename := filepath.Join(dir, sp.filename())
+	assert.FileExists(t, filename)
+
+	// unchanged profiles aren't written again
+	require.NoError(t, os.Remove(filename))
+	require.NoError(t, sp.Save(dir))
+	assert.NoFileExists(t, filename)
+
+	sp.dirty = true
+	require.NoError(t, sp.Save(dir))
+
+	loaded, err := LoadSecurityProfile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, sp.GetSelectorStr(), loaded.GetSelectorStr())
+	assert.Equal(t, SecurityProfileLearning, loaded.Status)
+	assert.True(t, sp.LearningStart.Equal(loaded.LearningStart))
+	assert.Equal(t, sp.Processes, loaded.Processes)
+
+	require.NoError(t, os.WriteFile(filename, []byte(`{"image_name": "nginx", "processes": [{"path": "/usr/sbin/nginx", "files": ["/z", "/a"]}]}`), 0640))
+	loaded, err = LoadSecurityProfile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "latest", loaded.ImageTag)
+	assert.Equal(t, SecurityProfileEnforcing, loaded.Status)
+	assert.Equal(t, []string{"/a", "/z"}, loaded.Processes[0].Files)
+
+	require.NoError(t, os.WriteFile(filename, []byte(`{"image_name": "nginx", "status": "unknown"}`), 0640))
+	_, err = LoadSecurityProfile(filename)
+	assert.Error(t, err)
+}
+
+func TestSecurityProfileNormalization(t *testing.T) {
+	sp := NewSecurityProfile("nginx", "latest", time.Now())
+	nginx := []string{"/usr/sbin/../sbin/nginx"}
+
+	sp.insert(nginx, FileAnomaly, "/proc/1234/status")
+	sp.insert(nginx, FileAnomaly, "/proc/5678/status")
+	sp.insert(nginx, FileAnomaly, "/etc/nginx//nginx.conf")
+	sp.insert(nginx, DNSAnomaly, "Example.COM.")
+	for i := 0; i <= fileCollapseThreshold; i++ {
+		sp.insert(nginx, FileAnomaly, fmt.Sprintf("/var/cache/nginx/entry-%c", 'a'+i))
+	}
+	sp.insert(nginx, FileAnomaly, "/var/cache/nginx/proxy/entry")
+
+	require.Len(t, sp.Processes, 1)
+	assert.Equal(t, "/usr/sbin/nginx", sp.Processes[0].Path)
+	assert.Equal(t, []string{"/etc/nginx/nginx.conf", "/proc/*/status", "/var/cache/nginx/*", "/var/cache/nginx/proxy/entry"}, sp.Processes[0].Files)
+	assert.Equal(t, []string{"example.com"}, sp.Processes[0].DNSNames)
+	assert.Equal(t, 4, sp.fileCount)
+
+	sp.Status = SecurityProfileEnforcing
+	for _, file := range []string{"/proc/42/status", "/var/cache/nginx/entry-z", "/etc/nginx/./nginx.conf"} {
+		anomaly, _ := sp.Evaluate([]string{"/usr/sbin/nginx"}, FileAnomaly, file, time.Now(), time.Hour)
+		assert.Empty(t, anomaly, file)
+	}
+	anomaly, value := sp.Evaluate([]string{"/usr/sbin/nginx"}, FileAnomaly, "/var/cache/nginx/other/entry", time.Now(), time.Hour)
+	assert.Equal(t, FileAnomaly, anomaly)
+	assert.Equal(t, "/var/cache/nginx/other/entry", value)
+	anomaly, _ = sp.Evaluate([]string{"/usr/sbin/nginx"}, DNSAnomaly, "example.com.", time.Now(), time.Hour)
+	assert.Empty(t, anomaly)
+}
+
+func TestSecurityProfileLimits(t *testing.T) {
+	sp := NewSecurityProfile("nginx", "latest", time.Now())
+	sp.limits = securityProfileLimits{maxProcessNodes: 2, maxFiles: 2, maxDNSNames: 1}
+	nginx := []string{"/usr/sbin/nginx"}
+
+	assert.True(t, sp.insert(nginx, FileAnomaly, "/etc/nginx/nginx.conf"))
+	assert.True(t, sp.insert(nginx, FileAnomaly, "/etc/nginx/mime.types"))
+	assert.False(t, sp.insert(nginx, FileAnomaly, "/etc/passwd"))
+	// known entries are still matched
+	assert.False(t, sp.insert(nginx, FileAnomaly, "/etc/nginx/nginx.conf"))
+
+	assert.True(t, sp.insert(nginx, DNSAnomaly, "example.com"))
+	assert.False(t, sp.insert(nginx, DNSAnomaly, "example.org"))
+
+	assert.True(t, sp.insert(append(nginx, "/bin/sh"), ProcessAnomaly, "/bin/sh"))
+	assert.False(t, sp.insert(append(nginx, "/bin/bash"), ProcessAnomaly, "/bin/bash"))
+
+	assert.Equal(t, 3, sp.droppedEntries)
+	assert.Equal(t, []string{"/etc/nginx/mime.types", "/etc/nginx/nginx.conf"}, sp.Processes[0].Files)
+	assert.Equal(t, []string{"example.com"}, sp.Processes[0].DNSNames)
+	require.Len(t, sp.Processes[0].Children, 1)
+
+	// the counts of the profiles loaded from disk are restored
+	dir := t.TempDir()
+	require.NoError(t, sp.Save(dir))
+	loaded, err := LoadSecurityProfile(filepath.Join(dir, sp.filename()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.processCount)
+	assert.Equal(t, 2, loaded.fileCount)
+	assert.Equal(t, 1, loaded.dnsCount)
+}
+
+func TestSecurityProfileManagerEviction(t *testing.T) {
+	dir := t.TempDir()
+	p := &Probe{
+		config: &config.Config{
+			SecurityProfileDir:       dir,
+			SecurityProfileCacheSize: 2,
+		},
+	}
+	spm, err := NewSecurityProfileManager(p, nil)
+	require.NoError(t, err)
+
+	nginx := spm.fetchProfile("nginx", "latest")
+	nginx.insert([]string{"/usr/sbin/nginx"}, FileAnomaly, "/etc/nginx/nginx.conf")
+	nginx.Status = SecurityProfileEnforcing
+	spm.fetchProfile("redis", "latest")
+	assert.Equal(t, 2, spm.profiles.Len())
+
+	// the least recently used profile is written to disk and evicted
+	spm.fetchProfile("postgres", "latest")
+	assert.Equal(t, 2, spm.profiles.Len())
+	assert.False(t, spm.profiles.Contains(nginx.GetSelectorStr()))
+	assert.Empty(t, spm.evictedProfiles)
+	assert.FileExists(t, filepath.Join(dir, nginx.filename()))
+
+	// and loaded again when its workload shows up
+	loaded := spm.fetchProfile("nginx", "latest")
+	assert.NotSame(t, nginx, loaded)
+	assert.Equal(t, SecurityProfileEnforcing, loaded.Status)
+	assert.Equal(t, nginx.Processes, loaded.Processes)
+	assert.False(t, spm.profiles.Contains(securityProfileSelector("redis", "latest")))
+
+	// evicted profiles which aren't written yet are brought back from memory
+	spm.Lock()
+	spm.profiles.Add(securityProfileSelector("mysql", "latest"), NewSecurityProfile("mysql", "latest", time.Now()))
+	evicted := spm.evictedProfiles[securityProfileSelector("postgres", "latest")]
+	spm.Unlock()
+	require.NotNil(t, evicted)
+	assert.Same(t, evicted, spm.fetchProfile("postgres", "latest"))
+
+	_, err = NewSecurityProfileManager(&Probe{config: &config.Config{SecurityProfileCacheSize: 0}}, nil)
+	assert.Error(t, err)
+}
+
+func TestSecurityProfileManagerAsyncLoad(t *testing.T) {
+	dir := t.TempDir()
+	p := &Probe{
+		config: &config.Config{
+			SecurityProfileDir:       dir,
+			SecurityProfileCacheSize: 2,
+		},
+	}
+
+	spm, err := NewSecurityProfileManager(p, nil)
+	require.NoError(t, err)
+
+	sp := NewSecurityProfile("nginx", "latest", time.Now())
+	sp.Status = SecurityProfileEnforcing
+	require.NoError(t, sp.Save(dir))
+
+	// a cache miss doesn't wait for the disk
+	assert.Nil(t, spm.getProfile("nginx", "latest"))
+	assert.Eventually(t, func() bool {
+		return spm.getProfile("nginx", "latest") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	loaded := spm.getProfile("nginx", "latest")
+	assert.Equal(t, SecurityProfileEnforcing, loaded.Status)
+	spm.Lock()
+	assert.Empty(t, spm.pendingProfiles)
+	spm.Unlock()
+}
+
+func TestSecurityProfileFilename(t *testing.T) {
+	filenames := make(map[string]bool)
+	for _, image := range [][2]string{
+		{"a/b", "latest"},
+		{"a-b", "latest"},
+		{"repo:5000/x", "latest"},
+		{"repo-5000-x", "latest"},
+		{"a_b", "c"},
+		{"a", "b_c"},
+	} {
+		filename := securityProfileFilename(image[0], image[1])
+		assert.False(t, filenames[filename], "%s:%s", image[0], image[1])
+		assert.NotContains(t, filename, "/")
+		filenames[filename] = true
+	}
+}
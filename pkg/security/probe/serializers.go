@@ -267,6 +267,35 @@ type IPPortSerializer struct {
 	Port uint16 `json:"port" jsonschema_description:"Port number"`
 }
 
+// SocketAddrSerializer is used to serialize the address of a socket to JSON
+// easyjson:json
+type SocketAddrSerializer struct {
+	Family string `json:"family" jsonschema_description:"Address family"`
+	IP     string `json:"ip" jsonschema_description:"IP address"`
+	Port   uint16 `json:"port" jsonschema_description:"Port number"`
+}
+
+// ConnectEventSerializer serializes a connect event to JSON
+// easyjson:json
+type ConnectEventSerializer struct {
+	Addr     *SocketAddrSerializer `json:"addr" jsonschema_description:"address the socket was connected to"`
+	Protocol string                `json:"protocol" jsonschema_description:"socket protocol"`
+}
+
+// BindEventSerializer serializes a bind event to JSON
+// easyjson:json
+type BindEventSerializer struct {
+	Addr     *SocketAddrSerializer `json:"addr" jsonschema_description:"address the socket was bound to"`
+	Protocol string                `json:"protocol" jsonschema_description:"socket protocol"`
+}
+
+// AcceptEventSerializer serializes an accept event to JSON
+// easyjson:json
+type AcceptEventSerializer struct {
+	Addr     *SocketAddrSerializer `json:"addr" jsonschema_description:"address of the remote peer of the accepted connection"`
+	Protocol string                `json:"protocol" jsonschema_description:"socket protocol"`
+}
+
 // NetworkContextSerializer serializes the network context to JSON
 // easyjson:json
 type NetworkContextSerializer struct {
@@ -331,6 +360,9 @@ type EventSerializer struct {
 	*SignalEventSerializer      `json:"signal,omitempty"`
 	*SpliceEventSerializer      `json:"splice,omitempty"`
 	*DNSEventSerializer         `json:"dns,omitempty"`
+	*ConnectEventSerializer     `json:"connect,omitempty"`
+	*BindEventSerializer        `json:"bind,omitempty"`
+	*AcceptEventSerializer      `json:"accept,omitempty"`
 	*NetworkContextSerializer   `json:"network,omitempty"`
 	*UserContextSerializer      `json:"usr,omitempty"`
 	*ProcessContextSerializer   `json:"process,omitempty"`
@@ -675,11 +707,40 @@ func newDNSEventSerializer(d *model.DNSEvent) *DNSEventSerializer {
 
 func newIPPortSerializer(c *model.IPPortContext) *IPPortSerializer {
 	return &IPPortSerializer{
-		IP:   c.IP.String(),
+		IP:   c.IPNet.IP.String(),
 		Port: c.Port,
 	}
 }
 
+func newSocketAddrSerializer(addr *model.IPPortContext, family uint16) *SocketAddrSerializer {
+	return &SocketAddrSerializer{
+		Family: model.AddressFamily(family).String(),
+		IP:     addr.IPNet.IP.String(),
+		Port:   addr.Port,
+	}
+}
+
+func newConnectEventSerializer(e *model.ConnectEvent) *ConnectEventSerializer {
+	return &ConnectEventSerializer{
+		Addr:     newSocketAddrSerializer(&e.Addr, e.AddrFamily),
+		Protocol: model.L4Protocol(e.Protocol).String(),
+	}
+}
+
+func newBindEventSerializer(e *model.BindEvent) *BindEventSerializer {
+	return &BindEventSerializer{
+		Addr:     newSocketAddrSerializer(&e.Addr, e.AddrFamily),
+		Protocol: model.L4Protocol(e.Protocol).String(),
+	}
+}
+
+func newAcceptEventSerializer(e *model.AcceptEvent) *AcceptEventSerializer {
+	return &AcceptEventSerializer{
+		Addr:     newSocketAddrSerializer(&e.Addr, e.AddrFamily),
+		Protocol: model.L4Protocol(e.Protocol).String(),
+	}
+}
+
 func newNetworkDeviceSerializer(e *Event) *NetworkDeviceSerializer {
 	return &NetworkDeviceSerializer{
 		NetNS:   e.NetworkContext.Device.NetNS,
@@ -941,6 +1002,15 @@ func NewEventSerializer(event *Event) *EventSerializer {
 	case model.DNSEventType:
 		s.EventContextSerializer.Outcome = serializeSyscallRetval(0)
 		s.DNSEventSerializer = newDNSEventSerializer(&event.DNS)
+	case model.ConnectEventType:
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Connect.Retval)
+		s.ConnectEventSerializer = newConnectEventSerializer(&event.Connect)
+	case model.BindEventType:
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Bind.Retval)
+		s.BindEventSerializer = newBindEventSerializer(&event.Bind)
+	case model.AcceptEventType:
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Accept.Retval)
+		s.AcceptEventSerializer = newAcceptEventSerializer(&event.Accept)
 	}
 
 	return s
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+// socketCapabilities returns the capabilities of the connect, bind and accept events, the kernel
+// filtering them by address family
+func socketCapabilities(event string) Capabilities {
+	return Capabilities{
+		event + ".addr.family": {
+			PolicyFlags:     PolicyFlagFlags,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+	}
+}
+
+func socketOnNewApproversWrapper(eventType model.EventType) onApproverHandler {
+	return func(probe *Probe, approvers rules.Approvers) (activeApprovers, error) {
+		var socketApprovers []activeApprover
+
+		for field, values := range approvers {
+			switch field {
+			case eventType.String() + ".addr.family":
+				// the kernel approves the families of a bitmask
+				var flags []int
+				for _, value := range values {
+					flags = append(flags, 1<<value.Value.(int))
+				}
+
+				approver, err := approveFlags(eventType.String()+"_family_approvers", flags...)
+				if err != nil {
+					return nil, err
+				}
+				socketApprovers = append(socketApprovers, approver)
+
+			default:
+				return nil, fmt.Errorf("unknown field '%s'", field)
+			}
+		}
+		return newActiveKFilters(socketApprovers...), nil
+	}
+}
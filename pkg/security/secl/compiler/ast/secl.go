@@ -18,6 +18,8 @@ import (
 var (
 	seclLexer = lexer.Must(ebnf.New(`
 Comment = ("#" | "//") { "\u0000"…"\uffff"-"\n" } .
+CIDR = IP "/" digit { digit } .
+IP = (ipv4 | ipv6) .
 Variable = "${" (alpha | "_") { "_" | alpha | digit | "." } "}" .
 Duration = digit { digit } ("ms" | "s" | "m" | "h" | "d") .
 Regexp = "r\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
@@ -27,6 +29,9 @@ Pattern = "~\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 Int = [ "-" | "+" ] digit { digit } .
 Punct = "!"…"/" | ":"…"@" | "["…` + "\"`\"" + ` | "{"…"~" .
 Whitespace = ( " " | "\t" | "\n" ) { " " | "\t" | "\n" } .
+ipv4 = (digit { digit } "." digit { digit } "." digit { digit } "." digit { digit }) .
+ipv6 = ( [hex { hex }] ":" [hex { hex }] ":" [hex { hex }] [":" | "."] [hex { hex }] [":" | "."] [hex { hex }] [":" | "."] [hex { hex }] [":" | "."] [hex { hex }] [":" | "."] [hex { hex }]) .
+hex = "a"…"f" | "A"…"F" | "0"…"9" .
 alpha = "a"…"z" | "A"…"Z" .
 digit = "0"…"9" .
 any = "\u0000"…"\uffff" .
@@ -187,6 +192,8 @@ type Primary struct {
 	Pattern       *string     `parser:"| @Pattern"`
 	Regexp        *string     `parser:"| @Regexp"`
 	Duration      *int        `parser:"| @Duration"`
+	IP            *string     `parser:"| @IP"`
+	CIDR          *string     `parser:"| @CIDR"`
 	SubExpression *Expression `parser:"| \"(\" @@ \")\""`
 }
 
@@ -199,11 +206,21 @@ type StringMember struct {
 	Regexp  *string `parser:"| @Regexp"`
 }
 
+// CIDRMember describes a CIDR based array member
+type CIDRMember struct {
+	Pos lexer.Position
+
+	IP   *string `parser:"@IP"`
+	CIDR *string `parser:"| @CIDR"`
+}
+
 // Array describes an array of values
 type Array struct {
 	Pos lexer.Position
 
-	StringMembers []StringMember `parser:"\"[\" @@ { \",\" @@ } \"]\""`
+	CIDR          *string        `parser:"@CIDR"`
+	StringMembers []StringMember `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
+	CIDRMembers   []CIDRMember   `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
 	Numbers       []int          `parser:"| \"[\" @Int { \",\" @Int } \"]\""`
 	Variable      *string        `parser:"| @Variable"`
 	Ident         *string        `parser:"| @Ident"`
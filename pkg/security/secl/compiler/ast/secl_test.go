@@ -222,3 +222,19 @@ func TestNumberVariable(t *testing.T) {
 
 	print(t, rule)
 }
+
+func TestCIDR(t *testing.T) {
+	rule, err := ParseRule(`network.destination.ip in [10.0.0.0/8, 172.16.0.0/12, fe80::/10, 127.0.0.1, ::1] && bind.addr.ip == 192.168.0.0/16`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	print(t, rule)
+
+	rule, err = ParseRule(`connect.addr.ip not in 10.0.0.0/8`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	print(t, rule)
+}
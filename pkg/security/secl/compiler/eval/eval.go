@@ -152,6 +152,18 @@ func arrayToEvaluator(array *ast.Array, opts *Opts, state *State) (interface{},
 		var evaluator IntArrayEvaluator
 		evaluator.AppendValues(array.Numbers...)
 		return &evaluator, array.Pos, nil
+	} else if array.CIDR != nil {
+		var evaluator CIDRValuesEvaluator
+		if err := evaluator.Values.AppendCIDR(*array.CIDR); err != nil {
+			return nil, array.Pos, NewError(array.Pos, err.Error())
+		}
+		return &evaluator, array.Pos, nil
+	} else if len(array.CIDRMembers) != 0 {
+		var evaluator CIDRValuesEvaluator
+		if err := evaluator.AppendMembers(array.CIDRMembers...); err != nil {
+			return nil, array.Pos, NewError(array.Pos, err.Error())
+		}
+		return &evaluator, array.Pos, nil
 	} else if len(array.StringMembers) != 0 {
 		var evaluator StringValuesEvaluator
 		if err := evaluator.AppendMembers(array.StringMembers...); err != nil {
@@ -542,6 +554,20 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 				default:
 					return nil, pos, NewArrayTypeError(pos, reflect.Array, reflect.Int)
 				}
+			case *CIDREvaluator:
+				switch nextCIDR := next.(type) {
+				case *CIDRValuesEvaluator:
+					boolEvaluator, err = CIDRValuesContains(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, pos, err
+					}
+					if *obj.ArrayComparison.Op == "notin" {
+						return Not(boolEvaluator, opts, state), obj.Pos, nil
+					}
+					return boolEvaluator, obj.Pos, nil
+				default:
+					return nil, pos, NewArrayTypeError(pos, reflect.Array, reflect.Struct)
+				}
 			default:
 				return nil, pos, NewTypeError(pos, reflect.Array)
 			}
@@ -874,6 +900,27 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 					return boolEvaluator, obj.Pos, nil
 				}
 				return nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
+			case *CIDREvaluator:
+				nextCIDR, ok := next.(*CIDREvaluator)
+				if !ok {
+					return nil, pos, NewTypeError(pos, reflect.Struct)
+				}
+
+				switch *obj.ScalarComparison.Op {
+				case "!=":
+					boolEvaluator, err = CIDREquals(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, obj.Pos, err
+					}
+					return Not(boolEvaluator, opts, state), obj.Pos, nil
+				case "==":
+					boolEvaluator, err = CIDREquals(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, obj.Pos, err
+					}
+					return boolEvaluator, obj.Pos, nil
+				}
+				return nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
 			}
 		} else {
 			return unary, pos, nil
@@ -969,6 +1016,18 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 				return nil, obj.Pos, NewError(obj.Pos, err.Error())
 			}
 			return evaluator, obj.Pos, nil
+		case obj.IP != nil, obj.CIDR != nil:
+			value := obj.CIDR
+			if obj.IP != nil {
+				value = obj.IP
+			}
+			ipnet, err := ParseCIDR(*value)
+			if err != nil {
+				return nil, obj.Pos, NewError(obj.Pos, err.Error())
+			}
+			return &CIDREvaluator{
+				Value: *ipnet,
+			}, obj.Pos, nil
 		case obj.SubExpression != nil:
 			return nodeToEvaluator(obj.SubExpression, opts, state)
 		default:
@@ -8,6 +8,7 @@ package eval
 import (
 	"container/list"
 	"fmt"
+	"net"
 	"os"
 	"runtime"
 	"strings"
@@ -1298,3 +1299,47 @@ func BenchmarkPool(b *testing.B) {
 		pool.pool.Put(ctx)
 	}
 }
+
+func TestCIDR(t *testing.T) {
+	event := &testEvent{
+		network: testNetwork{
+			ip: net.IPNet{IP: net.IPv4(10, 1, 2, 3).To4(), Mask: net.CIDRMask(32, 32)},
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `network.ip == 10.1.2.3`, Expected: true},
+		{Expr: `network.ip == 10.1.2.4`, Expected: false},
+		{Expr: `network.ip != 10.1.2.4`, Expected: true},
+		{Expr: `network.ip == 10.0.0.0/8`, Expected: true},
+		{Expr: `network.ip in 10.0.0.0/8`, Expected: true},
+		{Expr: `network.ip in [172.16.0.0/12, 192.168.0.0/16]`, Expected: false},
+		{Expr: `network.ip not in [10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16]`, Expected: false},
+		{Expr: `network.ip in [::1, 10.1.2.3]`, Expected: true},
+		{Expr: `network.ip in [fe80::/10, 2001:db8::/32]`, Expected: false},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+
+	for _, expr := range []string{
+		`network.ip == 10.0.0.0/33`,
+		`network.ip in [10.0.0.0/8, 300.0.0.0/8]`,
+		`network.ip == "10.0.0.1"`,
+	} {
+		if _, _, err := eval(t, event, expr); err == nil {
+			t.Errorf("expression `%s` should be rejected", expr)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package eval
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
 )
@@ -321,3 +322,80 @@ func (b *BoolArrayEvaluator) GetField() string {
 func (b *BoolArrayEvaluator) IsScalar() bool {
 	return b.EvalFnc == nil
 }
+
+// CIDREvaluator returns a network as result of the evaluation
+type CIDREvaluator struct {
+	EvalFnc     func(ctx *Context) net.IPNet
+	Field       Field
+	Value       net.IPNet
+	Weight      int
+	OpOverrides *OpOverrides
+
+	// used during compilation of partial
+	isDeterministic bool
+}
+
+// Eval returns the result of the evaluation
+func (c *CIDREvaluator) Eval(ctx *Context) interface{} {
+	return c.EvalFnc(ctx)
+}
+
+// IsDeterministicFor returns whether the evaluator is partial
+func (c *CIDREvaluator) IsDeterministicFor(field Field) bool {
+	return c.isDeterministic || (c.Field != "" && c.Field == field)
+}
+
+// GetField returns field name used by this evaluator
+func (c *CIDREvaluator) GetField() string {
+	return c.Field
+}
+
+// IsScalar returns whether the evaluator is a scalar
+func (c *CIDREvaluator) IsScalar() bool {
+	return c.EvalFnc == nil
+}
+
+// CIDRValuesEvaluator returns a set of networks
+type CIDRValuesEvaluator struct {
+	EvalFnc func(ctx *Context) *CIDRValues
+	Values  CIDRValues
+	Weight  int
+
+	// used during compilation of partial
+	isDeterministic bool
+}
+
+// Eval returns the result of the evaluation
+func (c *CIDRValuesEvaluator) Eval(ctx *Context) interface{} {
+	return c.EvalFnc(ctx)
+}
+
+// IsDeterministicFor returns whether the evaluator is partial
+func (c *CIDRValuesEvaluator) IsDeterministicFor(field Field) bool {
+	return c.isDeterministic
+}
+
+// GetField returns field name used by this evaluator
+func (c *CIDRValuesEvaluator) GetField() string {
+	return ""
+}
+
+// IsScalar returns whether the evaluator is a scalar
+func (c *CIDRValuesEvaluator) IsScalar() bool {
+	return c.EvalFnc == nil
+}
+
+// AppendMembers add members to the evaluator
+func (c *CIDRValuesEvaluator) AppendMembers(members ...ast.CIDRMember) error {
+	for _, member := range members {
+		value := member.CIDR
+		if member.IP != nil {
+			value = member.IP
+		}
+		if err := c.Values.AppendCIDR(*value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseCIDR parses an IP address or a CIDR, an IP address is returned as a network with a full mask
+func ParseCIDR(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address `%s`", value)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR `%s`", value)
+	}
+	return ipnet, nil
+}
+
+// IPNetsMatch returns whether one of the networks contains the other one
+func IPNetsMatch(a, b net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// CIDRValues describes a set of networks
+type CIDRValues struct {
+	ipnets []net.IPNet
+
+	// caches
+	fieldValues []FieldValue
+	exists      map[string]bool
+}
+
+// AppendCIDR appends an IP address or a CIDR
+func (c *CIDRValues) AppendCIDR(value string) error {
+	ipnet, err := ParseCIDR(value)
+	if err != nil {
+		return err
+	}
+	c.AppendIPNet(*ipnet)
+
+	return nil
+}
+
+// AppendIPNet appends a network
+func (c *CIDRValues) AppendIPNet(ipnet net.IPNet) {
+	key := ipnet.String()
+	if c.exists[key] {
+		return
+	}
+	if c.exists == nil {
+		c.exists = make(map[string]bool)
+	}
+	c.exists[key] = true
+
+	c.ipnets = append(c.ipnets, ipnet)
+	c.fieldValues = append(c.fieldValues, FieldValue{Value: ipnet, Type: IPNetValueType})
+}
+
+// Contains returns whether one of the networks matches the given network
+func (c *CIDRValues) Contains(ipnet net.IPNet) bool {
+	for _, n := range c.ipnets {
+		if IPNetsMatch(n, ipnet) {
+			return true
+		}
+	}
+	return false
+}
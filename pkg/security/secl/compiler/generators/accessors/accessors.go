@@ -95,7 +95,7 @@ func handleField(module *common.Module, astFile *ast.File, name, alias, prefix,
 	}
 
 	switch fieldType.Name {
-	case "string", "bool", "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+	case "string", "bool", "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "net.IPNet":
 		if prefix != "" {
 			name = prefix + "." + name
 			alias = aliasPrefix + "." + alias
@@ -137,6 +137,11 @@ func getFieldIdent(field *ast.Field) (ident *ast.Ident, isPointer, isArray bool)
 		if ident, ok := ft.Elt.(*ast.Ident); ok {
 			return ident, false, true
 		}
+	} else if selector, ok := field.Type.(*ast.SelectorExpr); ok {
+		// only IP networks are supported from other packages
+		if pkg, ok := selector.X.(*ast.Ident); ok && pkg.Name == "net" && selector.Sel.Name == "IPNet" {
+			return &ast.Ident{NamePos: selector.Pos(), Name: "net.IPNet"}, false, false
+		}
 	}
 	return nil, false, false
 }
@@ -58,6 +58,8 @@ func (sf *StructField) GetEvaluatorType() string {
 		if sf.Iterator != nil || sf.IsArray {
 			evaluatorType = "eval.IntArrayEvaluator"
 		}
+	} else if sf.ReturnType == "net.IPNet" {
+		evaluatorType = "eval.CIDREvaluator"
 	} else if sf.ReturnType == "bool" {
 		evaluatorType = "eval.BoolEvaluator"
 		if sf.Iterator != nil || sf.IsArray {
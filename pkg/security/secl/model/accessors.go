@@ -3,6 +3,7 @@
 package model
 
 import (
+	"net"
 	"reflect"
 	"unsafe"
 
@@ -12,6 +13,7 @@ import (
 // suppress unused package warning
 var (
 	_ *unsafe.Pointer
+	_ *net.IPNet
 )
 
 func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
@@ -34,6 +36,10 @@ func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
 func (m *Model) GetEventTypes() []eval.EventType {
 	return []eval.EventType{
 
+		eval.EventType("accept"),
+
+		eval.EventType("bind"),
+
 		eval.EventType("bpf"),
 
 		eval.EventType("capset"),
@@ -42,6 +48,8 @@ func (m *Model) GetEventTypes() []eval.EventType {
 
 		eval.EventType("chown"),
 
+		eval.EventType("connect"),
+
 		eval.EventType("dns"),
 
 		eval.EventType("exec"),
@@ -89,6 +97,106 @@ func (m *Model) GetEventTypes() []eval.EventType {
 func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Evaluator, error) {
 	switch field {
 
+	case "accept.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.AddrFamily)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.addr.ip":
+		return &eval.CIDREvaluator{
+			EvalFnc: func(ctx *eval.Context) net.IPNet {
+
+				return (*Event)(ctx.Object).Accept.Addr.IPNet
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.Addr.Port)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.protocol":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.Protocol)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "accept.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Accept.SyscallEvent.Retval)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.AddrFamily)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.ip":
+		return &eval.CIDREvaluator{
+			EvalFnc: func(ctx *eval.Context) net.IPNet {
+
+				return (*Event)(ctx.Object).Bind.Addr.IPNet
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Addr.Port)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.protocol":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Protocol)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.SyscallEvent.Retval)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "bpf.cmd":
 		return &eval.IntEvaluator{
 			EvalFnc: func(ctx *eval.Context) int {
@@ -563,6 +671,56 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "connect.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.AddrFamily)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.addr.ip":
+		return &eval.CIDREvaluator{
+			EvalFnc: func(ctx *eval.Context) net.IPNet {
+
+				return (*Event)(ctx.Object).Connect.Addr.IPNet
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Addr.Port)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.protocol":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Protocol)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.SyscallEvent.Retval)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "container.id":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1913,6 +2071,16 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "network.destination.ip":
+		return &eval.CIDREvaluator{
+			EvalFnc: func(ctx *eval.Context) net.IPNet {
+
+				return (*Event)(ctx.Object).NetworkContext.Destination.IPNet
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "network.destination.port":
 		return &eval.IntEvaluator{
 			EvalFnc: func(ctx *eval.Context) int {
@@ -1973,6 +2141,16 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "network.source.ip":
+		return &eval.CIDREvaluator{
+			EvalFnc: func(ctx *eval.Context) net.IPNet {
+
+				return (*Event)(ctx.Object).NetworkContext.Source.IPNet
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "network.source.port":
 		return &eval.IntEvaluator{
 			EvalFnc: func(ctx *eval.Context) int {
@@ -8366,6 +8544,26 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 func (e *Event) GetFields() []eval.Field {
 	return []eval.Field{
 
+		"accept.addr.family",
+
+		"accept.addr.ip",
+
+		"accept.addr.port",
+
+		"accept.protocol",
+
+		"accept.retval",
+
+		"bind.addr.family",
+
+		"bind.addr.ip",
+
+		"bind.addr.port",
+
+		"bind.protocol",
+
+		"bind.retval",
+
 		"bpf.cmd",
 
 		"bpf.map.name",
@@ -8460,6 +8658,16 @@ func (e *Event) GetFields() []eval.Field {
 
 		"chown.retval",
 
+		"connect.addr.family",
+
+		"connect.addr.ip",
+
+		"connect.addr.port",
+
+		"connect.protocol",
+
+		"connect.retval",
+
 		"container.id",
 
 		"container.tags",
@@ -8730,6 +8938,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"mprotect.vm_protection",
 
+		"network.destination.ip",
+
 		"network.destination.port",
 
 		"network.device.ifindex",
@@ -8742,6 +8952,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"network.size",
 
+		"network.source.ip",
+
 		"network.source.port",
 
 		"open.file.change_time",
@@ -9619,6 +9831,46 @@ func (e *Event) GetFields() []eval.Field {
 func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 	switch field {
 
+	case "accept.addr.family":
+
+		return int(e.Accept.AddrFamily), nil
+
+	case "accept.addr.ip":
+
+		return e.Accept.Addr.IPNet, nil
+
+	case "accept.addr.port":
+
+		return int(e.Accept.Addr.Port), nil
+
+	case "accept.protocol":
+
+		return int(e.Accept.Protocol), nil
+
+	case "accept.retval":
+
+		return int(e.Accept.SyscallEvent.Retval), nil
+
+	case "bind.addr.family":
+
+		return int(e.Bind.AddrFamily), nil
+
+	case "bind.addr.ip":
+
+		return e.Bind.Addr.IPNet, nil
+
+	case "bind.addr.port":
+
+		return int(e.Bind.Addr.Port), nil
+
+	case "bind.protocol":
+
+		return int(e.Bind.Protocol), nil
+
+	case "bind.retval":
+
+		return int(e.Bind.SyscallEvent.Retval), nil
+
 	case "bpf.cmd":
 
 		return int(e.BPF.Cmd), nil
@@ -9811,6 +10063,26 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Chown.SyscallEvent.Retval), nil
 
+	case "connect.addr.family":
+
+		return int(e.Connect.AddrFamily), nil
+
+	case "connect.addr.ip":
+
+		return e.Connect.Addr.IPNet, nil
+
+	case "connect.addr.port":
+
+		return int(e.Connect.Addr.Port), nil
+
+	case "connect.protocol":
+
+		return int(e.Connect.Protocol), nil
+
+	case "connect.retval":
+
+		return int(e.Connect.SyscallEvent.Retval), nil
+
 	case "container.id":
 
 		return e.ContainerContext.ID, nil
@@ -10351,6 +10623,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.MProtect.VMProtection, nil
 
+	case "network.destination.ip":
+
+		return e.NetworkContext.Destination.IPNet, nil
+
 	case "network.destination.port":
 
 		return int(e.NetworkContext.Destination.Port), nil
@@ -10375,6 +10651,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.NetworkContext.Size), nil
 
+	case "network.source.ip":
+
+		return e.NetworkContext.Source.IPNet, nil
+
 	case "network.source.port":
 
 		return int(e.NetworkContext.Source.Port), nil
@@ -14557,6 +14837,36 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	switch field {
 
+	case "accept.addr.family":
+		return "accept", nil
+
+	case "accept.addr.ip":
+		return "accept", nil
+
+	case "accept.addr.port":
+		return "accept", nil
+
+	case "accept.protocol":
+		return "accept", nil
+
+	case "accept.retval":
+		return "accept", nil
+
+	case "bind.addr.family":
+		return "bind", nil
+
+	case "bind.addr.ip":
+		return "bind", nil
+
+	case "bind.addr.port":
+		return "bind", nil
+
+	case "bind.protocol":
+		return "bind", nil
+
+	case "bind.retval":
+		return "bind", nil
+
 	case "bpf.cmd":
 		return "bpf", nil
 
@@ -14698,6 +15008,21 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "chown.retval":
 		return "chown", nil
 
+	case "connect.addr.family":
+		return "connect", nil
+
+	case "connect.addr.ip":
+		return "connect", nil
+
+	case "connect.addr.port":
+		return "connect", nil
+
+	case "connect.protocol":
+		return "connect", nil
+
+	case "connect.retval":
+		return "connect", nil
+
 	case "container.id":
 		return "*", nil
 
@@ -15103,6 +15428,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "mprotect.vm_protection":
 		return "mprotect", nil
 
+	case "network.destination.ip":
+		return "*", nil
+
 	case "network.destination.port":
 		return "*", nil
 
@@ -15121,6 +15449,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "network.size":
 		return "*", nil
 
+	case "network.source.ip":
+		return "*", nil
+
 	case "network.source.port":
 		return "*", nil
 
@@ -16437,6 +16768,46 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 	switch field {
 
+	case "accept.addr.family":
+
+		return reflect.Int, nil
+
+	case "accept.addr.ip":
+
+		return reflect.Struct, nil
+
+	case "accept.addr.port":
+
+		return reflect.Int, nil
+
+	case "accept.protocol":
+
+		return reflect.Int, nil
+
+	case "accept.retval":
+
+		return reflect.Int, nil
+
+	case "bind.addr.family":
+
+		return reflect.Int, nil
+
+	case "bind.addr.ip":
+
+		return reflect.Struct, nil
+
+	case "bind.addr.port":
+
+		return reflect.Int, nil
+
+	case "bind.protocol":
+
+		return reflect.Int, nil
+
+	case "bind.retval":
+
+		return reflect.Int, nil
+
 	case "bpf.cmd":
 
 		return reflect.Int, nil
@@ -16625,6 +16996,26 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "connect.addr.family":
+
+		return reflect.Int, nil
+
+	case "connect.addr.ip":
+
+		return reflect.Struct, nil
+
+	case "connect.addr.port":
+
+		return reflect.Int, nil
+
+	case "connect.protocol":
+
+		return reflect.Int, nil
+
+	case "connect.retval":
+
+		return reflect.Int, nil
+
 	case "container.id":
 
 		return reflect.String, nil
@@ -17165,6 +17556,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "network.destination.ip":
+
+		return reflect.Struct, nil
+
 	case "network.destination.port":
 
 		return reflect.Int, nil
@@ -17189,6 +17584,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "network.source.ip":
+
+		return reflect.Struct, nil
+
 	case "network.source.port":
 
 		return reflect.Int, nil
@@ -18941,6 +19340,110 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 	switch field {
 
+	case "accept.addr.family":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.AddrFamily"}
+		}
+		e.Accept.AddrFamily = uint16(v)
+
+		return nil
+
+	case "accept.addr.ip":
+
+		var ok bool
+		if e.Accept.Addr.IPNet, ok = value.(net.IPNet); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Addr.IPNet"}
+		}
+		return nil
+
+	case "accept.addr.port":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Addr.Port"}
+		}
+		e.Accept.Addr.Port = uint16(v)
+
+		return nil
+
+	case "accept.protocol":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.Protocol"}
+		}
+		e.Accept.Protocol = uint16(v)
+
+		return nil
+
+	case "accept.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Accept.SyscallEvent.Retval"}
+		}
+		e.Accept.SyscallEvent.Retval = int64(v)
+
+		return nil
+
+	case "bind.addr.family":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.AddrFamily"}
+		}
+		e.Bind.AddrFamily = uint16(v)
+
+		return nil
+
+	case "bind.addr.ip":
+
+		var ok bool
+		if e.Bind.Addr.IPNet, ok = value.(net.IPNet); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.IPNet"}
+		}
+		return nil
+
+	case "bind.addr.port":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.Port"}
+		}
+		e.Bind.Addr.Port = uint16(v)
+
+		return nil
+
+	case "bind.protocol":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Protocol"}
+		}
+		e.Bind.Protocol = uint16(v)
+
+		return nil
+
+	case "bind.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.SyscallEvent.Retval"}
+		}
+		e.Bind.SyscallEvent.Retval = int64(v)
+
+		return nil
+
 	case "bpf.cmd":
 
 		var ok bool
@@ -19452,6 +19955,58 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "connect.addr.family":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.AddrFamily"}
+		}
+		e.Connect.AddrFamily = uint16(v)
+
+		return nil
+
+	case "connect.addr.ip":
+
+		var ok bool
+		if e.Connect.Addr.IPNet, ok = value.(net.IPNet); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.IPNet"}
+		}
+		return nil
+
+	case "connect.addr.port":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.Port"}
+		}
+		e.Connect.Addr.Port = uint16(v)
+
+		return nil
+
+	case "connect.protocol":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Protocol"}
+		}
+		e.Connect.Protocol = uint16(v)
+
+		return nil
+
+	case "connect.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.SyscallEvent.Retval"}
+		}
+		e.Connect.SyscallEvent.Retval = int64(v)
+
+		return nil
+
 	case "container.id":
 
 		var ok bool
@@ -20910,6 +21465,14 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "network.destination.ip":
+
+		var ok bool
+		if e.NetworkContext.Destination.IPNet, ok = value.(net.IPNet); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "NetworkContext.Destination.IPNet"}
+		}
+		return nil
+
 	case "network.destination.port":
 
 		var ok bool
@@ -20976,6 +21539,14 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "network.source.ip":
+
+		var ok bool
+		if e.NetworkContext.Source.IPNet, ok = value.(net.IPNet); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "NetworkContext.Source.IPNet"}
+		}
+		return nil
+
 	case "network.source.port":
 
 		var ok bool
@@ -607,6 +607,17 @@ var (
 		"ETH_P_MAP":             EthPMAP,
 	}
 
+	// AddressFamilyConstants is the list of supported address families
+	AddressFamilyConstants = map[string]AddressFamily{
+		"AF_UNSPEC":  AFUnspec,
+		"AF_UNIX":    AFUnix,
+		"AF_INET":    AFInet,
+		"AF_INET6":   AFInet6,
+		"AF_NETLINK": AFNetlink,
+		"AF_PACKET":  AFPacket,
+		"AF_VSOCK":   AFVSock,
+	}
+
 	// L4ProtocolConstants is the list of supported L4 protocols
 	L4ProtocolConstants = map[string]L4Protocol{
 		"IP_PROTO_IP":      IPProtoIP,
@@ -659,6 +670,7 @@ var (
 	dnsQClassStrings          = map[uint32]string{}
 	l3ProtocolStrings         = map[L3Protocol]string{}
 	l4ProtocolStrings         = map[L4Protocol]string{}
+	addressFamilyStrings      = map[AddressFamily]string{}
 )
 
 // File flags
@@ -834,6 +846,13 @@ func initL4ProtocolConstants() {
 	}
 }
 
+func initAddressFamilyConstants() {
+	for k, v := range AddressFamilyConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: int(v)}
+		addressFamilyStrings[v] = k
+	}
+}
+
 func initConstants() {
 	initErrorConstants()
 	initOpenConstants()
@@ -855,6 +874,7 @@ func initConstants() {
 	initDNSQTypeConstants()
 	initL3ProtocolConstants()
 	initL4ProtocolConstants()
+	initAddressFamilyConstants()
 }
 
 func bitmaskToStringArray(bitmask int, intToStrMap map[int]string) []string {
@@ -1975,3 +1995,27 @@ const (
 	// IPProtoRAW Raw IP packets
 	IPProtoRAW L4Protocol = 255
 )
+
+// AddressFamily socket address families
+type AddressFamily uint16
+
+func (family AddressFamily) String() string {
+	return addressFamilyStrings[family]
+}
+
+const (
+	// AFUnspec Unspecified
+	AFUnspec AddressFamily = 0
+	// AFUnix Unix domain sockets
+	AFUnix AddressFamily = 1
+	// AFInet Internet IP Protocol
+	AFInet AddressFamily = 2
+	// AFInet6 IP version 6
+	AFInet6 AddressFamily = 10
+	// AFNetlink Netlink sockets
+	AFNetlink AddressFamily = 16
+	// AFPacket Packet family
+	AFPacket AddressFamily = 17
+	// AFVSock vSockets
+	AFVSock AddressFamily = 40
+)
@@ -85,6 +85,12 @@ const (
 	VethPairEventType
 	// NamespaceSwitchEventType is sent when a process changes one of its namespaces
 	NamespaceSwitchEventType
+	// ConnectEventType connect event
+	ConnectEventType
+	// BindEventType bind event
+	BindEventType
+	// AcceptEventType accept event
+	AcceptEventType
 	// MaxEventType is used internally to get the maximum number of kernel events.
 	MaxEventType
 
@@ -106,6 +112,8 @@ const (
 	CustomForkBombEventType
 	// CustomTruncatedParentsEventType is the custom event used to report that the parents of a path were truncated
 	CustomTruncatedParentsEventType
+	// CustomAnomalyDetectionEventType is the custom event used to report activity that doesn't match a security profile
+	CustomAnomalyDetectionEventType
 )
 
 func (t EventType) String() string {
@@ -182,6 +190,12 @@ func (t EventType) String() string {
 		return "veth_pair"
 	case NamespaceSwitchEventType:
 		return "namespace_switch"
+	case ConnectEventType:
+		return "connect"
+	case BindEventType:
+		return "bind"
+	case AcceptEventType:
+		return "accept"
 
 	case CustomLostReadEventType:
 		return "lost_events_read"
@@ -195,6 +209,8 @@ func (t EventType) String() string {
 		return "fork_bomb"
 	case CustomTruncatedParentsEventType:
 		return "truncated_parents"
+	case CustomAnomalyDetectionEventType:
+		return "anomaly_detection"
 	default:
 		return "unknown"
 	}
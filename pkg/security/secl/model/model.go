@@ -165,6 +165,9 @@ type Event struct {
 	LoadModule   LoadModuleEvent   `field:"load_module" event:"load_module"`     // [7.35] [Kernel] A new kernel module was loaded
 	UnloadModule UnloadModuleEvent `field:"unload_module" event:"unload_module"` // [7.35] [Kernel] A kernel module was deleted
 	DNS          DNSEvent          `field:"dns" event:"dns"`                     // [7.36] [Network] A DNS request was sent
+	Connect      ConnectEvent      `field:"connect" event:"connect"`             // [7.37] [Network] A socket was connected to an address
+	Bind         BindEvent         `field:"bind" event:"bind"`                   // [7.37] [Network] A socket was bound to an address
+	Accept       AcceptEvent       `field:"accept" event:"accept"`               // [7.37] [Network] A connection was accepted on a socket
 
 	Mount            MountEvent            `field:"-"`
 	Umount           UmountEvent           `field:"-"`
@@ -186,6 +189,11 @@ func (e *Event) GetEventType() EventType {
 	return EventType(e.Type)
 }
 
+// GetTimestamp returns the time at which the event occurred
+func (e *Event) GetTimestamp() time.Time {
+	return e.Timestamp
+}
+
 // GetTags returns the list of tags specific to this event
 func (e *Event) GetTags() []string {
 	tags := []string{"type:" + e.GetType()}
@@ -755,8 +763,8 @@ type NetworkDeviceContext struct {
 // IPPortContext is used to hold an IP and Port
 //msgp:ignore IPPortContext
 type IPPortContext struct {
-	IP   net.IP `field:"-"`
-	Port uint16 `field:"port"` // Port number
+	IPNet net.IPNet `field:"ip"`   // IP address
+	Port  uint16    `field:"port"` // Port number
 }
 
 // NetworkContext represents the network context of the event
@@ -782,6 +790,36 @@ type DNSEvent struct {
 	Count uint16 `field:"question.count"` // the total count of questions in the DNS request
 }
 
+// ConnectEvent represents a connect event
+//msgp:ignore ConnectEvent
+type ConnectEvent struct {
+	SyscallEvent
+
+	Addr       IPPortContext `field:"addr"`        // Address the socket was connected to
+	AddrFamily uint16        `field:"addr.family"` // Address family
+	Protocol   uint16        `field:"protocol"`    // Socket protocol
+}
+
+// BindEvent represents a bind event
+//msgp:ignore BindEvent
+type BindEvent struct {
+	SyscallEvent
+
+	Addr       IPPortContext `field:"addr"`        // Address the socket was bound to
+	AddrFamily uint16        `field:"addr.family"` // Address family
+	Protocol   uint16        `field:"protocol"`    // Socket protocol
+}
+
+// AcceptEvent represents an accept event
+//msgp:ignore AcceptEvent
+type AcceptEvent struct {
+	SyscallEvent
+
+	Addr       IPPortContext `field:"addr"`        // Address of the remote peer of the accepted connection
+	AddrFamily uint16        `field:"addr.family"` // Address family
+	Protocol   uint16        `field:"protocol"`    // Socket protocol
+}
+
 // NetDevice represents a network device
 //msgp:ignore NetDevice
 type NetDevice struct {
@@ -8,6 +8,7 @@ package model
 import (
 	"encoding/binary"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 	"unsafe"
@@ -780,8 +781,7 @@ func (e *NetworkContext) UnmarshalBinary(data []byte) (int, error) {
 		return 0, ErrNotEnoughData
 	}
 
-	e.Source.IP = data[read : read+16]
-	e.Destination.IP = data[read+16 : read+32]
+	var srcIP, dstIP net.IP = data[read : read+16], data[read+16 : read+32]
 	e.Source.Port = binary.BigEndian.Uint16(data[read+32 : read+34])
 	e.Destination.Port = binary.BigEndian.Uint16(data[read+34 : read+36])
 	// padding 4 bytes
@@ -793,12 +793,81 @@ func (e *NetworkContext) UnmarshalBinary(data []byte) (int, error) {
 	// readjust IP sizes depending on the protocol
 	switch e.L3Protocol {
 	case 0x800: // unix.ETH_P_IP
-		e.Source.IP = e.Source.IP[0:4]
-		e.Destination.IP = e.Destination.IP[0:4]
+		srcIP, dstIP = srcIP[0:4], dstIP[0:4]
 	}
+	e.Source.IPNet = newIPNet(srcIP)
+	e.Destination.IPNet = newIPNet(dstIP)
 	return read + 48, nil
 }
 
+// newIPNet returns a network holding only the given IP, the IP being copied out of the event buffer
+func newIPNet(ip net.IP) net.IPNet {
+	ip = append(net.IP(nil), ip...)
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+}
+
+// unmarshalSocketAddr unmarshalls the address, address family and protocol of a socket event
+func unmarshalSocketAddr(data []byte, addr *IPPortContext, family, protocol *uint16) (int, error) {
+	if len(data) < 24 {
+		return 0, ErrNotEnoughData
+	}
+
+	*family = ByteOrder.Uint16(data[16:18])
+	addr.Port = binary.BigEndian.Uint16(data[18:20])
+	*protocol = ByteOrder.Uint16(data[20:22])
+	// padding 2 bytes
+
+	switch AddressFamily(*family) {
+	case AFInet:
+		addr.IPNet = newIPNet(data[0:4])
+	case AFInet6:
+		addr.IPNet = newIPNet(data[0:16])
+	}
+	return 24, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *ConnectEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := unmarshalSocketAddr(data[read:], &e.Addr, &e.AddrFamily, &e.Protocol)
+	if err != nil {
+		return 0, err
+	}
+	return read + n, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *BindEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := unmarshalSocketAddr(data[read:], &e.Addr, &e.AddrFamily, &e.Protocol)
+	if err != nil {
+		return 0, err
+	}
+	return read + n, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *AcceptEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := unmarshalSocketAddr(data[read:], &e.Addr, &e.AddrFamily, &e.Protocol)
+	if err != nil {
+		return 0, err
+	}
+	return read + n, nil
+}
+
 // UnmarshalBinary unmarshalls a binary representation of itself
 func (e *DNSEvent) UnmarshalBinary(data []byte) (int, error) {
 	if len(data) < 10 {
@@ -24,10 +24,8 @@ type RuleBucket struct {
 
 // AddRule adds a rule to the bucket
 func (rb *RuleBucket) AddRule(rule *Rule) error {
-	for _, r := range rb.rules {
-		if r.ID == rule.ID {
-			return &ErrRuleLoad{Definition: rule.Definition, Err: errors.New("multiple definition with the same ID")}
-		}
+	if rb.hasRule(rule.ID) {
+		return &ErrRuleLoad{Definition: rule.Definition, Err: errors.New("multiple definition with the same ID")}
 	}
 
 	for _, field := range rule.GetEvaluator().GetFields() {
@@ -44,6 +42,15 @@ func (rb *RuleBucket) AddRule(rule *Rule) error {
 	return nil
 }
 
+func (rb *RuleBucket) hasRule(id string) bool {
+	for _, r := range rb.rules {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRules returns the bucket rules
 func (rb *RuleBucket) GetRules() []*Rule {
 	return rb.rules
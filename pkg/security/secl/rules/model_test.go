@@ -8,6 +8,7 @@ package rules
 import (
 	"reflect"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
@@ -34,8 +35,9 @@ type testMkdir struct {
 }
 
 type testEvent struct {
-	id   string
-	kind string
+	id        string
+	kind      string
+	timestamp time.Time
 
 	process testProcess
 	open    testOpen
@@ -57,6 +59,10 @@ func (e *testEvent) GetPointer() unsafe.Pointer {
 	return unsafe.Pointer(e)
 }
 
+func (e *testEvent) GetTimestamp() time.Time {
+	return e.timestamp
+}
+
 func (m *testModel) NewEvent() eval.Event {
 	return &testEvent{}
 }
@@ -25,6 +25,8 @@ type Opts struct {
 	EventTypeEnabled    map[eval.EventType]bool
 	StateScopes         map[Scope]VariableProviderFactory
 	Logger              Logger
+	// MaxWindowStates is the maximum number of states kept for the rules matching over a time window
+	MaxWindowStates int
 }
 
 // WithConstants set constants
@@ -86,3 +88,9 @@ func (o *Opts) WithStateScopes(stateScopes map[Scope]VariableProviderFactory) *O
 	o.StateScopes = stateScopes
 	return o
 }
+
+// WithMaxWindowStates set the maximum number of states of the rules matching over a time window
+func (o *Opts) WithMaxWindowStates(maxStates int) *Opts {
+	o.MaxWindowStates = maxStates
+	return o
+}
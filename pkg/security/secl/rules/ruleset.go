@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -64,14 +65,16 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID          RuleID             `yaml:"id"`
-	Version     string             `yaml:"version"`
-	Expression  string             `yaml:"expression"`
-	Description string             `yaml:"description"`
-	Tags        map[string]string  `yaml:"tags"`
-	Disabled    bool               `yaml:"disabled"`
-	Combine     CombinePolicy      `yaml:"combine"`
-	Actions     []ActionDefinition `yaml:"actions"`
+	ID          RuleID               `yaml:"id"`
+	Version     string               `yaml:"version"`
+	Expression  string               `yaml:"expression"`
+	Description string               `yaml:"description"`
+	Tags        map[string]string    `yaml:"tags"`
+	Disabled    bool                 `yaml:"disabled"`
+	Combine     CombinePolicy        `yaml:"combine"`
+	Actions     []ActionDefinition   `yaml:"actions"`
+	Every       *ThresholdDefinition `yaml:"every"`
+	Sequence    *SequenceDefinition  `yaml:"sequence"`
 	Policy      *Policy
 }
 
@@ -138,6 +141,8 @@ type SetDefinition struct {
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+	// window is set for the rules matching over a time window and for the steps of their sequence
+	window *ruleWindow
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -164,6 +169,8 @@ type RuleSet struct {
 	fields []string
 	logger Logger
 	pool   *eval.ContextPool
+	// windows holds the states of the rules matching over a time window
+	windows *windowTable
 }
 
 // ListRuleIDs returns the list of RuleIDs from the ruleset
@@ -297,41 +304,24 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 		Definition: ruleDef,
 	}
 
-	if err := rule.Parse(); err != nil {
-		return nil, &ErrRuleLoad{Definition: ruleDef, Err: errors.Wrap(err, "syntax error")}
-	}
-
-	if err := rule.GenEvaluator(rs.model, &rs.opts.Opts); err != nil {
+	if err := rs.compileRule(rule); err != nil {
 		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
 	}
 
-	eventType, err := GetRuleEventType(rule.Rule)
+	steps, err := rs.compileRuleWindows(rule)
 	if err != nil {
 		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
 	}
 
-	// ignore event types not supported
-	if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
-		if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
-			return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrEventTypeNotEnabled}
-		}
-	}
-
-	for _, event := range rule.GetEvaluator().EventTypes {
-		bucket, exists := rs.eventRuleBuckets[event]
-		if !exists {
-			bucket = &RuleBucket{}
-			rs.eventRuleBuckets[event] = bucket
-		}
-
-		if err := bucket.AddRule(rule); err != nil {
+	// the rule and the steps of its sequence are only added to the buckets once they are all valid, so
+	// that a rule failing to load never matches
+	if err := rs.addRulesToBuckets(append([]*Rule{rule}, steps...)); err != nil {
+		if _, ok := err.(*ErrRuleLoad); ok {
 			return nil, err
 		}
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
 	}
 
-	// Merge the fields of the new rule with the existing list of fields of the ruleset
-	rs.AddFields(rule.GetEvaluator().GetFields())
-
 	rs.rules[ruleDef.ID] = rule
 
 	// Generate evaluator for fields that are used in variables
@@ -350,6 +340,62 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 	return rule.Rule, nil
 }
 
+// compileRule parses a rule and creates its evaluator
+func (rs *RuleSet) compileRule(rule *Rule) error {
+	if err := rule.Parse(); err != nil {
+		return errors.Wrap(err, "syntax error")
+	}
+
+	if err := rule.GenEvaluator(rs.model, &rs.opts.Opts); err != nil {
+		return err
+	}
+
+	eventType, err := GetRuleEventType(rule.Rule)
+	if err != nil {
+		return err
+	}
+
+	// ignore event types not supported
+	if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
+		if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
+			return ErrEventTypeNotEnabled
+		}
+	}
+
+	return nil
+}
+
+// addRulesToBuckets adds compiled rules to the buckets of their events. None of the rules is added if
+// one of them conflicts with a rule of the buckets.
+func (rs *RuleSet) addRulesToBuckets(rules []*Rule) error {
+	for _, rule := range rules {
+		for _, event := range rule.GetEvaluator().EventTypes {
+			if bucket, exists := rs.eventRuleBuckets[event]; exists && bucket.hasRule(rule.ID) {
+				return &ErrRuleLoad{Definition: rule.Definition, Err: errors.New("multiple definition with the same ID")}
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		for _, event := range rule.GetEvaluator().EventTypes {
+			bucket, exists := rs.eventRuleBuckets[event]
+			if !exists {
+				bucket = &RuleBucket{}
+				rs.eventRuleBuckets[event] = bucket
+			}
+
+			if err := bucket.AddRule(rule); err != nil {
+				return err
+			}
+		}
+
+		// Merge the fields of the new rule with the existing list of fields of the ruleset
+		rs.AddFields(rule.GetEvaluator().GetFields())
+	}
+
+	return nil
+}
+
 // NotifyRuleMatch notifies all the ruleset listeners that an event matched a rule
 func (rs *RuleSet) NotifyRuleMatch(rule *Rule, event eval.Event) {
 	for _, listener := range rs.listeners {
@@ -506,8 +552,19 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 	}
 	rs.logger.Tracef("Evaluating event of type `%s` against set of %d rules", eventType, len(bucket.rules))
 
+	var now time.Time
 	for _, rule := range bucket.rules {
 		if rule.GetEvaluator().Eval(ctx) {
+			if rule.window != nil {
+				if now.IsZero() {
+					now = eventTime(event)
+				}
+				if !rs.windows.match(ctx, rule.window, now) {
+					continue
+				}
+				rule = rule.window.rule
+			}
+
 			rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 
 			rs.NotifyRuleMatch(rule, event)
@@ -609,5 +666,6 @@ func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts) *Rule
 		pool:             eval.NewContextPool(),
 		fieldEvaluators:  make(map[string]eval.Evaluator),
 		scopedVariables:  make(map[Scope]VariableProvider),
+		windows:          newWindowTable(opts.MaxWindowStates),
 	}
 }
@@ -170,6 +170,16 @@ func genFilterValues(rule *eval.Rule, event eval.Event) ([]FilterValues, error)
 				})
 			case eval.BitmaskValueType:
 				bitmasks = append(bitmasks, fValue.Value.(int))
+			case eval.IPNetValueType:
+				// networks can't be negated, only a value matching the expression is used
+				if len(values) == 0 {
+					values = append(values, FilterValue{
+						Field:    field,
+						Value:    fValue.Value,
+						Type:     fValue.Type,
+						isScalar: false,
+					})
+				}
 			}
 		}
 
@@ -0,0 +1,316 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// DefaultMaxWindowStates is the default maximum number of states kept by a rule set for the rules
+// matching over a time window
+const DefaultMaxWindowStates = 10000
+
+// ThresholdDefinition describes the `every` section of a rule. The rule matches once its expression
+// matched `count` events within `within`, the events being grouped by the values of the `group_by` fields.
+type ThresholdDefinition struct {
+	Count   int           `yaml:"count"`
+	Within  time.Duration `yaml:"within"`
+	GroupBy []eval.Field  `yaml:"group_by"`
+}
+
+// SequenceDefinition describes the `sequence` section of a rule. The rule matches when its expression
+// matches an event following events matching the expressions of the steps, in order, within `within`.
+// The events are correlated by the values of the `group_by` fields, for instance `process.pid` or
+// `container.id`.
+type SequenceDefinition struct {
+	Within  time.Duration             `yaml:"within"`
+	GroupBy []eval.Field              `yaml:"group_by"`
+	Steps   []*SequenceStepDefinition `yaml:"steps"`
+}
+
+// SequenceStepDefinition describes a step of a sequence. The `group_by` fields of a step replace the
+// ones of the sequence for the events of this step, there must be as many.
+type SequenceStepDefinition struct {
+	Expression string       `yaml:"expression"`
+	GroupBy    []eval.Field `yaml:"group_by"`
+}
+
+// TimestampedEvent is implemented by the events providing the time at which they occurred. The time of
+// the evaluation is used for the other events.
+type TimestampedEvent interface {
+	GetTimestamp() time.Time
+}
+
+// WindowStats holds the statistics of the states of the rules matching over a time window
+type WindowStats struct {
+	// States is the number of states currently kept
+	States int64
+	// Evictions is the number of states evicted to keep the table within its bounds since the
+	// previous call to GetWindowStats
+	Evictions int64
+}
+
+// ruleWindow aggregates the matches of a rule, or of a step of a sequence, over a time window
+type ruleWindow struct {
+	// rule is the rule matching at the end of the window
+	rule    *Rule
+	groupBy []eval.Evaluator
+	within  time.Duration
+	// count is the number of events of a threshold, 0 for a sequence
+	count int
+	// step is the index of the step of a sequence, the expression of the rule being the last step
+	step      int
+	lastStep  int
+	stepCount int
+}
+
+type windowState struct {
+	key     string
+	expires time.Time
+	// times are the times of the events of a threshold
+	times []time.Time
+	// step is the next expected step of a sequence
+	step int
+}
+
+// windowTable holds the states of the windows of a rule set. The least recently updated states are
+// evicted once the table is full.
+type windowTable struct {
+	maxStates int
+	states    map[string]*list.Element
+	lru       *list.List
+	size      int64
+	evictions int64
+}
+
+func newWindowTable(maxStates int) *windowTable {
+	if maxStates <= 0 {
+		maxStates = DefaultMaxWindowStates
+	}
+	return &windowTable{
+		maxStates: maxStates,
+		states:    make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+func (t *windowTable) get(key string, now time.Time) *windowState {
+	element, found := t.states[key]
+	if !found {
+		return nil
+	}
+
+	state := element.Value.(*windowState)
+	if now.After(state.expires) {
+		t.remove(element)
+		return nil
+	}
+	t.lru.MoveToBack(element)
+
+	return state
+}
+
+func (t *windowTable) add(state *windowState, now time.Time) {
+	// drop the expired states first, then the least recently updated ones
+	for element := t.lru.Front(); element != nil && now.After(element.Value.(*windowState).expires); element = t.lru.Front() {
+		t.remove(element)
+	}
+	for t.lru.Len() >= t.maxStates {
+		t.remove(t.lru.Front())
+		atomic.AddInt64(&t.evictions, 1)
+	}
+
+	t.states[state.key] = t.lru.PushBack(state)
+	atomic.StoreInt64(&t.size, int64(t.lru.Len()))
+}
+
+func (t *windowTable) delete(key string) {
+	if element, found := t.states[key]; found {
+		t.remove(element)
+	}
+}
+
+func (t *windowTable) remove(element *list.Element) {
+	delete(t.states, element.Value.(*windowState).key)
+	t.lru.Remove(element)
+	atomic.StoreInt64(&t.size, int64(t.lru.Len()))
+}
+
+func (t *windowTable) stats() WindowStats {
+	return WindowStats{
+		States:    atomic.LoadInt64(&t.size),
+		Evictions: atomic.SwapInt64(&t.evictions, 0),
+	}
+}
+
+// match updates the state of the window with an event matching the expression of the rule, or of the
+// step, and returns whether the rule matches.
+func (t *windowTable) match(ctx *eval.Context, window *ruleWindow, now time.Time) bool {
+	key := window.key(ctx)
+
+	if window.count > 0 {
+		state := t.get(key, now)
+		if state == nil {
+			state = &windowState{key: key, times: make([]time.Time, 0, window.count)}
+			t.add(state, now)
+		}
+
+		times := state.times[:0]
+		for _, ts := range state.times {
+			if now.Sub(ts) <= window.within {
+				times = append(times, ts)
+			}
+		}
+		state.times = append(times, now)
+		state.expires = now.Add(window.within)
+
+		if len(state.times) < window.count {
+			return false
+		}
+		t.delete(key)
+		return true
+	}
+
+	if window.step == 0 {
+		// the first step (re)starts the sequence
+		t.delete(key)
+		t.add(&windowState{key: key, step: 1, expires: now.Add(window.within)}, now)
+		return false
+	}
+
+	state := t.get(key, now)
+	if state == nil || state.step != window.step {
+		return false
+	}
+
+	if window.step < window.lastStep {
+		state.step++
+		return false
+	}
+	t.delete(key)
+	return true
+}
+
+// key returns the key of the state of the window of an event
+func (w *ruleWindow) key(ctx *eval.Context) string {
+	var builder strings.Builder
+	builder.WriteString(w.rule.ID)
+	for _, evaluator := range w.groupBy {
+		builder.WriteByte(0)
+		fmt.Fprint(&builder, evaluator.Eval(ctx))
+	}
+	return builder.String()
+}
+
+func eventTime(event eval.Event) time.Time {
+	if e, ok := event.(TimestampedEvent); ok {
+		if ts := e.GetTimestamp(); !ts.IsZero() {
+			return ts
+		}
+	}
+	return time.Now()
+}
+
+// newRuleWindow returns the window of a threshold or of a sequence step, the step of the expression of
+// the rule being the last one
+func (rs *RuleSet) newRuleWindow(rule *Rule, within time.Duration, count int, step int, groupBy []eval.Field) (*ruleWindow, error) {
+	window := &ruleWindow{
+		rule:   rule,
+		within: within,
+		count:  count,
+		step:   step,
+	}
+	if sequence := rule.Definition.Sequence; sequence != nil {
+		window.lastStep = len(sequence.Steps)
+	}
+
+	for _, field := range groupBy {
+		evaluator, err := rs.model.GetEvaluator(field, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid group_by field `%s`", field)
+		}
+		window.groupBy = append(window.groupBy, evaluator)
+	}
+
+	return window, nil
+}
+
+// compileRuleWindows sets the window of a rule with an `every` or a `sequence` section, and returns the
+// compiled rules of the steps of its sequence
+func (rs *RuleSet) compileRuleWindows(rule *Rule) ([]*Rule, error) {
+	ruleDef := rule.Definition
+
+	switch {
+	case ruleDef.Every != nil && ruleDef.Sequence != nil:
+		return nil, errors.New("only one of 'every' and 'sequence' can be defined")
+	case ruleDef.Every != nil:
+		every := ruleDef.Every
+		if every.Count < 1 || every.Within <= 0 {
+			return nil, errors.New("'every' requires a positive 'count' and 'within'")
+		}
+
+		window, err := rs.newRuleWindow(rule, every.Within, every.Count, 0, every.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		rule.window = window
+	case ruleDef.Sequence != nil:
+		sequence := ruleDef.Sequence
+		if len(sequence.Steps) == 0 || sequence.Within <= 0 {
+			return nil, errors.New("'sequence' requires at least one step and a positive 'within'")
+		}
+
+		window, err := rs.newRuleWindow(rule, sequence.Within, 0, len(sequence.Steps), sequence.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		rule.window = window
+
+		steps := make([]*Rule, 0, len(sequence.Steps))
+		for i, stepDef := range sequence.Steps {
+			groupBy := sequence.GroupBy
+			if len(stepDef.GroupBy) > 0 {
+				if len(stepDef.GroupBy) != len(sequence.GroupBy) {
+					return nil, fmt.Errorf("step %d: expected %d group_by fields, got %d", i, len(sequence.GroupBy), len(stepDef.GroupBy))
+				}
+				groupBy = stepDef.GroupBy
+			}
+
+			step := &Rule{
+				Rule: &eval.Rule{
+					ID:         fmt.Sprintf("%s/step_%d", ruleDef.ID, i),
+					Expression: stepDef.Expression,
+					Tags:       rule.Tags,
+				},
+				Definition: ruleDef,
+			}
+			if err := rs.compileRule(step); err != nil {
+				return nil, errors.Wrapf(err, "step %d", i)
+			}
+
+			if step.window, err = rs.newRuleWindow(rule, sequence.Within, 0, i, groupBy); err != nil {
+				return nil, errors.Wrapf(err, "step %d", i)
+			}
+			steps = append(steps, step)
+		}
+		return steps, nil
+	}
+
+	return nil, nil
+}
+
+// GetWindowStats returns the statistics of the states of the rules matching over a time window
+func (rs *RuleSet) GetWindowStats() WindowStats {
+	return rs.windows.stats()
+}
@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+type testMatchHandler struct {
+	matches []string
+}
+
+func (h *testMatchHandler) RuleMatch(rule *Rule, event eval.Event) {
+	h.matches = append(h.matches, rule.ID)
+}
+
+func (h *testMatchHandler) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+func newWindowRuleSet(t *testing.T, maxStates int, ruleDefs ...*RuleDefinition) (*RuleSet, *testMatchHandler) {
+	var opts Opts
+	opts.
+		WithConstants(testConstants).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true}).
+		WithMaxWindowStates(maxStates)
+
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, &opts)
+	if err := rs.AddRules(ruleDefs); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &testMatchHandler{}
+	rs.AddListener(handler)
+
+	return rs, handler
+}
+
+func newOpenEvent(name string, uid int, filename string, ts time.Time) *testEvent {
+	return &testEvent{
+		kind:      "open",
+		timestamp: ts,
+		process:   testProcess{name: name, uid: uid},
+		open:      testOpen{filename: filename},
+	}
+}
+
+func TestRuleSetThreshold(t *testing.T) {
+	rs, handler := newWindowRuleSet(t, 0, &RuleDefinition{
+		ID:         "mass_open",
+		Expression: `open.filename =~ "/data/*"`,
+		Every: &ThresholdDefinition{
+			Count:   3,
+			Within:  10 * time.Second,
+			GroupBy: []eval.Field{"process.uid"},
+		},
+	})
+
+	now := time.Now()
+	rs.Evaluate(newOpenEvent("a", 1, "/data/1", now))
+	rs.Evaluate(newOpenEvent("a", 1, "/data/2", now.Add(time.Second)))
+	// another group
+	rs.Evaluate(newOpenEvent("a", 2, "/data/3", now.Add(2*time.Second)))
+	if len(handler.matches) != 0 {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+
+	if !rs.Evaluate(newOpenEvent("a", 1, "/data/4", now.Add(3*time.Second))) {
+		t.Fatal("the third event of the group should match")
+	}
+	if len(handler.matches) != 1 || handler.matches[0] != "mass_open" {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+
+	// the counter is reset once the rule matched and the events out of the window are ignored
+	rs.Evaluate(newOpenEvent("a", 1, "/data/5", now.Add(4*time.Second)))
+	rs.Evaluate(newOpenEvent("a", 1, "/data/6", now.Add(5*time.Second)))
+	if rs.Evaluate(newOpenEvent("a", 1, "/data/7", now.Add(20*time.Second))) {
+		t.Fatal("the events out of the window shouldn't be counted")
+	}
+	if len(handler.matches) != 1 {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+}
+
+func TestRuleSetSequence(t *testing.T) {
+	rs, handler := newWindowRuleSet(t, 0, &RuleDefinition{
+		ID:         "download_chmod",
+		Expression: `mkdir.filename =~ "/tmp/*" && mkdir.mode & 64 > 0`,
+		Sequence: &SequenceDefinition{
+			Within:  time.Minute,
+			GroupBy: []eval.Field{"mkdir.filename"},
+			Steps: []*SequenceStepDefinition{
+				{
+					Expression: `open.filename =~ "/tmp/*" && process.name == "curl"`,
+					GroupBy:    []eval.Field{"open.filename"},
+				},
+			},
+		},
+	})
+
+	if _, found := rs.GetRules()["download_chmod/step_0"]; found {
+		t.Fatal("the steps of a sequence shouldn't be listed as rules")
+	}
+
+	mkdir := func(filename string, ts time.Time) *testEvent {
+		return &testEvent{
+			kind:      "mkdir",
+			timestamp: ts,
+			mkdir:     testMkdir{filename: filename, mode: 0755},
+		}
+	}
+
+	now := time.Now()
+	if rs.Evaluate(mkdir("/tmp/payload", now)) {
+		t.Fatal("the last step shouldn't match without the previous ones")
+	}
+	if rs.Evaluate(newOpenEvent("curl", 0, "/tmp/payload", now.Add(time.Second))) {
+		t.Fatal("the first step shouldn't match the rule")
+	}
+	if rs.Evaluate(mkdir("/tmp/other", now.Add(2*time.Second))) {
+		t.Fatal("the steps should be correlated by their group_by fields")
+	}
+	if !rs.Evaluate(mkdir("/tmp/payload", now.Add(3*time.Second))) {
+		t.Fatal("the sequence should match")
+	}
+	if len(handler.matches) != 1 || handler.matches[0] != "download_chmod" {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+
+	rs.Evaluate(newOpenEvent("curl", 0, "/tmp/late", now))
+	if rs.Evaluate(mkdir("/tmp/late", now.Add(2*time.Minute))) {
+		t.Fatal("the sequence shouldn't match out of its window")
+	}
+}
+
+func TestRuleSetWindowEvictions(t *testing.T) {
+	rs, handler := newWindowRuleSet(t, 2, &RuleDefinition{
+		ID:         "mass_open",
+		Expression: `open.filename =~ "/data/*"`,
+		Every: &ThresholdDefinition{
+			Count:   2,
+			Within:  time.Minute,
+			GroupBy: []eval.Field{"process.uid"},
+		},
+	})
+
+	now := time.Now()
+	for uid := 1; uid <= 3; uid++ {
+		rs.Evaluate(newOpenEvent("a", uid, "/data/file", now))
+	}
+
+	stats := rs.GetWindowStats()
+	if stats.States != 2 || stats.Evictions != 1 {
+		t.Fatalf("unexpected window stats: %+v", stats)
+	}
+	if stats = rs.GetWindowStats(); stats.Evictions != 0 {
+		t.Fatalf("the evictions should be reset: %+v", stats)
+	}
+
+	// the state of the first uid was evicted
+	rs.Evaluate(newOpenEvent("a", 1, "/data/file", now))
+	rs.Evaluate(newOpenEvent("a", 3, "/data/file", now))
+	if len(handler.matches) != 1 {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+}
+
+func TestRuleSetWindowInvalid(t *testing.T) {
+	var opts Opts
+	opts.
+		WithConstants(testConstants).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true})
+
+	for _, ruleDef := range []*RuleDefinition{
+		{
+			ID:         "no_count",
+			Expression: `open.filename == "/etc/passwd"`,
+			Every:      &ThresholdDefinition{Within: time.Second},
+		},
+		{
+			ID:         "no_steps",
+			Expression: `open.filename == "/etc/passwd"`,
+			Sequence:   &SequenceDefinition{Within: time.Second},
+		},
+		{
+			ID:         "unknown_field",
+			Expression: `open.filename == "/etc/passwd"`,
+			Every:      &ThresholdDefinition{Count: 2, Within: time.Second, GroupBy: []eval.Field{"process.unknown"}},
+		},
+		{
+			ID:         "group_by_mismatch",
+			Expression: `open.filename == "/etc/passwd"`,
+			Sequence: &SequenceDefinition{
+				Within:  time.Second,
+				GroupBy: []eval.Field{"open.filename"},
+				Steps: []*SequenceStepDefinition{
+					{Expression: `mkdir.filename == "/etc"`, GroupBy: []eval.Field{"mkdir.filename", "process.uid"}},
+				},
+			},
+		},
+	} {
+		rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, &opts)
+		if _, err := rs.AddRule(ruleDef); err == nil {
+			t.Errorf("rule `%s` should be rejected", ruleDef.ID)
+		}
+	}
+}
+
+func TestRuleSetWindowInvalidNeverMatches(t *testing.T) {
+	var opts Opts
+	opts.
+		WithConstants(testConstants).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true})
+
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, &opts)
+	handler := &testMatchHandler{}
+	rs.AddListener(handler)
+
+	for _, ruleDef := range []*RuleDefinition{
+		{
+			ID:         "invalid_group_by",
+			Expression: `open.filename == "/etc/passwd"`,
+			Every:      &ThresholdDefinition{Count: 2, Within: time.Second, GroupBy: []eval.Field{"process.unknown"}},
+		},
+		{
+			ID:         "invalid_step",
+			Expression: `open.filename == "/etc/passwd"`,
+			Sequence: &SequenceDefinition{
+				Within: time.Second,
+				Steps: []*SequenceStepDefinition{
+					{Expression: `open.filename == "/etc/shadow"`},
+					{Expression: `open.filename ==`},
+				},
+			},
+		},
+	} {
+		if _, err := rs.AddRule(ruleDef); err == nil {
+			t.Fatalf("rule `%s` should be rejected", ruleDef.ID)
+		}
+	}
+
+	if rs.HasRulesForEventType("open") {
+		t.Fatal("the rejected rules shouldn't be in the buckets")
+	}
+
+	now := time.Now()
+	for i, filename := range []string{"/etc/shadow", "/etc/passwd", "/etc/passwd", "/etc/passwd"} {
+		if rs.Evaluate(newOpenEvent("a", 1, filename, now.Add(time.Duration(i)*time.Millisecond))) {
+			t.Fatalf("event %d shouldn't match", i)
+		}
+	}
+	if len(handler.matches) != 0 {
+		t.Fatalf("unexpected matches: %v", handler.matches)
+	}
+}
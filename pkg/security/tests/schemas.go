@@ -140,3 +140,15 @@ func validateSpliceSchema(t *testing.T, event *sprobe.Event) bool {
 func validateDNSSchema(t *testing.T, event *sprobe.Event) bool {
 	return validateSchema(t, event, "file:///schemas/dns.schema.json")
 }
+
+func validateConnectSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/connect.schema.json")
+}
+
+func validateBindSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/bind.schema.json")
+}
+
+func validateAcceptSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/accept.schema.json")
+}
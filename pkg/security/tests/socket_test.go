@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build functionaltests
+// +build functionaltests
+
+package tests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func TestSocketEvents(t *testing.T) {
+	ruleDefs := []*rules.RuleDefinition{
+		{
+			ID:         "test_bind",
+			Expression: `bind.addr.family == AF_INET && bind.addr.ip == 127.0.0.1 && bind.addr.port == 4241 && process.file.name == "testsuite"`,
+		},
+		{
+			ID:         "test_connect",
+			Expression: `connect.addr.ip in [127.0.0.0/8] && connect.addr.port == 4242 && connect.protocol == IP_PROTO_TCP && process.file.name == "testsuite"`,
+		},
+		{
+			ID:         "test_accept",
+			Expression: `accept.addr.family == AF_INET6 && accept.addr.ip == ::1/128 && process.file.name == "testsuite"`,
+		},
+	}
+
+	test, err := newTestModule(t, nil, ruleDefs, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	t.Run("bind", func(t *testing.T) {
+		test.WaitSignal(t, func() error {
+			listener, err := net.Listen("tcp4", "127.0.0.1:4241")
+			if err != nil {
+				return err
+			}
+			return listener.Close()
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "bind", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(model.AFInet), event.Bind.AddrFamily, "wrong address family")
+			assert.Equal(t, "127.0.0.1", event.Bind.Addr.IPNet.IP.String(), "wrong address")
+			assert.Equal(t, uint16(4241), event.Bind.Addr.Port, "wrong port")
+			assert.Equal(t, uint16(model.IPProtoTCP), event.Bind.Protocol, "wrong protocol")
+
+			if !validateBindSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		listener, err := net.Listen("tcp4", "127.0.0.1:4242")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+
+		test.WaitSignal(t, func() error {
+			conn, err := net.Dial("tcp4", "127.0.0.1:4242")
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "connect", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(model.AFInet), event.Connect.AddrFamily, "wrong address family")
+			assert.Equal(t, "127.0.0.1", event.Connect.Addr.IPNet.IP.String(), "wrong address")
+			assert.Equal(t, uint16(4242), event.Connect.Addr.Port, "wrong port")
+
+			if !validateConnectSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		listener, err := net.Listen("tcp6", "[::1]:4243")
+		if err != nil {
+			t.Skip("IPv6 loopback not available")
+		}
+		defer listener.Close()
+
+		go func() {
+			if conn, err := net.Dial("tcp6", "[::1]:4243"); err == nil {
+				conn.Close()
+			}
+		}()
+
+		test.WaitSignal(t, func() error {
+			conn, err := listener.Accept()
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "accept", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(model.AFInet6), event.Accept.AddrFamily, "wrong address family")
+			assert.Equal(t, "::1", event.Accept.Addr.IPNet.IP.String(), "wrong address")
+			assert.Equal(t, uint16(model.IPProtoTCP), event.Accept.Protocol, "wrong protocol")
+
+			if !validateAcceptSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"bytes"
+
+	"github.com/golang/snappy"
+	"github.com/richardartoul/molecule"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics/labels"
+)
+
+// constants for the protobuf data we will be writing, taken from WriteRequest in
+// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+const (
+	writeRequestTimeseries = 1
+	timeSeriesLabels       = 1
+	timeSeriesSamples      = 2
+	labelName              = 1
+	labelValue             = 2
+	sampleValue            = 1
+	sampleTimestamp        = 2
+)
+
+type sample struct {
+	value float64
+	// timestamp in milliseconds
+	timestamp int64
+}
+
+// timeSeries is a remote-write TimeSeries. `metric` is the Datadog name of
+// the metric, used to select the endpoints the series is sent to.
+type timeSeries struct {
+	metric  string
+	labels  []labels.Label
+	samples []sample
+}
+
+// marshalSplitCompress marshals the given series into snappy compressed
+// WriteRequest payloads holding at most maxSeriesPerPayload series each.
+func marshalSplitCompress(series []*timeSeries, maxSeriesPerPayload int) ([]*[]byte, error) {
+	if maxSeriesPerPayload <= 0 {
+		maxSeriesPerPayload = len(series)
+	}
+
+	var payloads []*[]byte
+	buf := bytes.NewBuffer(nil)
+	ps := molecule.NewProtoStream(buf)
+
+	for start := 0; start < len(series); start += maxSeriesPerPayload {
+		end := start + maxSeriesPerPayload
+		if end > len(series) {
+			end = len(series)
+		}
+
+		buf.Reset()
+		for _, ts := range series[start:end] {
+			if err := marshalTimeSeries(ps, ts); err != nil {
+				return nil, err
+			}
+		}
+
+		payload := snappy.Encode(nil, buf.Bytes())
+		payloads = append(payloads, &payload)
+	}
+
+	return payloads, nil
+}
+
+func marshalTimeSeries(ps *molecule.ProtoStream, ts *timeSeries) error {
+	return ps.Embedded(writeRequestTimeseries, func(ps *molecule.ProtoStream) error {
+		for _, l := range ts.labels {
+			err := ps.Embedded(timeSeriesLabels, func(ps *molecule.ProtoStream) error {
+				if err := ps.String(labelName, l.Name); err != nil {
+					return err
+				}
+				return ps.String(labelValue, l.Value)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, s := range ts.samples {
+			err := ps.Embedded(timeSeriesSamples, func(ps *molecule.ProtoStream) error {
+				if err := ps.Double(sampleValue, s.value); err != nil {
+					return err
+				}
+				return ps.Int64(sampleTimestamp, s.timestamp)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package remotewrite converts the flushed series and sketches into
+// Prometheus remote-write payloads.
+package remotewrite
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/labels"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const metricNameLabel = "__name__"
+
+var (
+	tlmSeriesSent = telemetry.NewCounter("remote_write", "series_sent",
+		[]string{"endpoint"}, "Count of series sent to a remote-write endpoint")
+	tlmErrors = telemetry.NewCounter("remote_write", "errors",
+		[]string{"endpoint"}, "Count of remote-write payloads that could not be submitted")
+)
+
+// EndpointConfig is the configuration of a remote-write endpoint.
+type EndpointConfig struct {
+	Name    string            `mapstructure:"name"`
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	// MetricPrefixes restricts the metrics sent to the endpoint to the ones
+	// starting with one of the prefixes. Every metric is sent when empty.
+	MetricPrefixes []string `mapstructure:"metric_prefixes"`
+}
+
+// matches returns whether the metric must be sent to the endpoint.
+func (e *EndpointConfig) matches(metric string) bool {
+	if len(e.MetricPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range e.MetricPrefixes {
+		if strings.HasPrefix(metric, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointsFromConfig reads the remote-write endpoints from the configuration.
+func EndpointsFromConfig(cfg config.Config) ([]EndpointConfig, error) {
+	var endpoints []EndpointConfig
+	if err := cfg.UnmarshalKey("prometheus_remote_write.endpoints", &endpoints); err != nil {
+		return nil, fmt.Errorf("could not parse prometheus_remote_write.endpoints: %v", err)
+	}
+
+	for i := range endpoints {
+		if endpoints[i].URL == "" {
+			return nil, fmt.Errorf("remote-write endpoint #%d has no url", i)
+		}
+		if endpoints[i].Name == "" {
+			endpoints[i].Name = endpoints[i].URL
+		}
+	}
+	return endpoints, nil
+}
+
+// ForwarderEndpoints returns the forwarder configuration of the given endpoints.
+func ForwarderEndpoints(endpoints []EndpointConfig) []forwarder.RemoteWriteEndpoint {
+	res := make([]forwarder.RemoteWriteEndpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		headers := make(http.Header, len(e.Headers))
+		for k, v := range e.Headers {
+			headers.Set(k, v)
+		}
+		res = append(res, forwarder.RemoteWriteEndpoint{
+			Name:    e.Name,
+			URL:     e.URL,
+			Headers: headers,
+		})
+	}
+	return res
+}
+
+// Forwarder submits remote-write payloads.
+type Forwarder interface {
+	SubmitRemoteWrite(name string, payloads forwarder.Payloads) error
+}
+
+// Sink receives the same series and sketches as the serializer during a
+// flush and sends them to the remote-write endpoints when Flush is called.
+// Sketches are converted to summaries: one series per quantile plus the
+// `_sum` and `_count` series.
+type Sink struct {
+	forwarder           Forwarder
+	endpoints           []EndpointConfig
+	quantiles           []float64
+	maxSeriesPerPayload int
+
+	m       sync.Mutex
+	pending []*timeSeries
+}
+
+// NewSink returns a new Sink.
+func NewSink(fwd Forwarder, endpoints []EndpointConfig, quantiles []float64, maxSeriesPerPayload int) *Sink {
+	return &Sink{
+		forwarder:           fwd,
+		endpoints:           endpoints,
+		quantiles:           quantiles,
+		maxSeriesPerPayload: maxSeriesPerPayload,
+	}
+}
+
+// AppendSerie converts the serie and keeps it until the next Flush.
+func (s *Sink) AppendSerie(serie *metrics.Serie) {
+	if serie == nil || len(serie.Points) == 0 || !s.isSent(serie.Name) {
+		return
+	}
+
+	ts := &timeSeries{
+		metric:  serie.Name,
+		labels:  seriesLabels(serie.Name, "", serie.Tags.ForEach, serie.Host, serie.Device),
+		samples: make([]sample, 0, len(serie.Points)),
+	}
+	for _, p := range serie.Points {
+		ts.samples = append(ts.samples, sample{value: p.Value, timestamp: int64(p.Ts * 1000)})
+	}
+
+	s.m.Lock()
+	s.pending = append(s.pending, ts)
+	s.m.Unlock()
+}
+
+// AppendSketches converts the sketches and keeps them until the next Flush.
+func (s *Sink) AppendSketches(sketches metrics.SketchSeriesList) {
+	cfg := quantile.Default()
+	var series []*timeSeries
+
+	for _, sketch := range sketches {
+		if len(sketch.Points) == 0 || !s.isSent(sketch.Name) {
+			continue
+		}
+
+		quantiles := make([]*timeSeries, len(s.quantiles))
+		for i, q := range s.quantiles {
+			quantiles[i] = &timeSeries{
+				metric: sketch.Name,
+				labels: seriesLabels(sketch.Name, formatQuantile(q), sketch.Tags.ForEach, sketch.Host, ""),
+			}
+		}
+		sum := &timeSeries{metric: sketch.Name, labels: seriesLabels(sketch.Name+"_sum", "", sketch.Tags.ForEach, sketch.Host, "")}
+		count := &timeSeries{metric: sketch.Name, labels: seriesLabels(sketch.Name+"_count", "", sketch.Tags.ForEach, sketch.Host, "")}
+
+		for _, p := range sketch.Points {
+			if p.Sketch == nil {
+				continue
+			}
+			timestamp := p.Ts * 1000
+			for i, q := range s.quantiles {
+				quantiles[i].samples = append(quantiles[i].samples, sample{value: p.Sketch.Quantile(cfg, q), timestamp: timestamp})
+			}
+			sum.samples = append(sum.samples, sample{value: p.Sketch.Basic.Sum, timestamp: timestamp})
+			count.samples = append(count.samples, sample{value: float64(p.Sketch.Basic.Cnt), timestamp: timestamp})
+		}
+
+		series = append(series, quantiles...)
+		series = append(series, sum, count)
+	}
+
+	s.m.Lock()
+	s.pending = append(s.pending, series...)
+	s.m.Unlock()
+}
+
+// Flush sends the series received since the last Flush to the endpoints.
+func (s *Sink) Flush() {
+	s.m.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.m.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	for i := range s.endpoints {
+		endpoint := &s.endpoints[i]
+
+		series := make([]*timeSeries, 0, len(pending))
+		for _, ts := range pending {
+			if endpoint.matches(ts.metric) {
+				series = append(series, ts)
+			}
+		}
+		if len(series) == 0 {
+			continue
+		}
+
+		payloads, err := marshalSplitCompress(series, s.maxSeriesPerPayload)
+		if err == nil {
+			err = s.forwarder.SubmitRemoteWrite(endpoint.Name, payloads)
+		}
+		if err != nil {
+			log.Errorf("Could not send %d series to the remote-write endpoint %q: %v", len(series), endpoint.Name, err)
+			tlmErrors.Inc(endpoint.Name)
+			continue
+		}
+		tlmSeriesSent.Add(float64(len(series)), endpoint.Name)
+	}
+}
+
+// isSent returns whether at least one endpoint receives the metric.
+func (s *Sink) isSent(metric string) bool {
+	for i := range s.endpoints {
+		if s.endpoints[i].matches(metric) {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesLabels returns the sorted remote-write labels of a series.
+func seriesLabels(name string, quantile string, forEachTag func(func(string)), host string, device string) []labels.Label {
+	set := labels.NewLabelSet(true)
+	forEachTag(set.AddTag)
+	if host != "" {
+		set.Add("host", host)
+	}
+	if device != "" {
+		set.Add("device", device)
+	}
+	if quantile != "" {
+		set.Add("quantile", quantile)
+	}
+	// the set sanitizes the label names but not the values, the metric name
+	// is sanitized separately
+	set.Add(metricNameLabel, labels.SanitizeName(name, true))
+	return set.Labels()
+}
+
+func formatQuantile(q float64) string {
+	return strconv.FormatFloat(q, 'g', -1, 64)
+}
@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/richardartoul/molecule"
+	"github.com/richardartoul/molecule/src/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+type fakeForwarder struct {
+	payloads map[string]forwarder.Payloads
+}
+
+func (f *fakeForwarder) SubmitRemoteWrite(name string, payloads forwarder.Payloads) error {
+	if f.payloads == nil {
+		f.payloads = make(map[string]forwarder.Payloads)
+	}
+	f.payloads[name] = append(f.payloads[name], payloads...)
+	return nil
+}
+
+// decode returns the series of a compressed WriteRequest, one string per
+// series formatted as `labels samples`.
+func decode(t *testing.T, payload []byte) []string {
+	raw, err := snappy.Decode(nil, payload)
+	require.NoError(t, err)
+
+	var series []string
+	err = molecule.MessageEach(codec.NewBuffer(raw), func(fieldNum int32, value molecule.Value) (bool, error) {
+		require.Equal(t, int32(writeRequestTimeseries), fieldNum)
+
+		var labels, samples []string
+		err := molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+			var fields []string
+			err := molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+				switch {
+				case fieldNum == 1 && len(value.Bytes) > 0:
+					fields = append(fields, string(value.Bytes))
+				case fieldNum == 1:
+					v, _ := value.AsDouble()
+					fields = append(fields, fmt.Sprint(v))
+				default:
+					if len(value.Bytes) > 0 {
+						fields = append(fields, string(value.Bytes))
+					} else {
+						v, _ := value.AsInt64()
+						fields = append(fields, fmt.Sprint(v))
+					}
+				}
+				return true, nil
+			})
+			if fieldNum == timeSeriesLabels {
+				labels = append(labels, strings.Join(fields, "="))
+			} else {
+				samples = append(samples, strings.Join(fields, "@"))
+			}
+			return true, err
+		})
+		series = append(series, strings.Join(labels, ",")+" "+strings.Join(samples, ","))
+		return true, err
+	})
+	require.NoError(t, err)
+	return series
+}
+
+func decodeAll(t *testing.T, payloads forwarder.Payloads) []string {
+	var series []string
+	for _, p := range payloads {
+		series = append(series, decode(t, *p)...)
+	}
+	sort.Strings(series)
+	return series
+}
+
+func TestSinkSeries(t *testing.T) {
+	fwd := &fakeForwarder{}
+	sink := NewSink(fwd, []EndpointConfig{{Name: "cortex"}}, nil, 0)
+
+	sink.AppendSerie(&metrics.Serie{
+		Name:   "system.load.1",
+		Points: []metrics.Point{{Ts: 10, Value: 1.5}, {Ts: 20, Value: 2}},
+		Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "kube.app:web"}),
+		Host:   "myhost",
+	})
+	sink.Flush()
+
+	require.Len(t, fwd.payloads["cortex"], 1)
+	assert.Equal(t, []string{
+		"__name__=system_load_1,env=prod,host=myhost,kube_app=web 1.5@10000,2@20000",
+	}, decodeAll(t, fwd.payloads["cortex"]))
+
+	// nothing pending
+	fwd.payloads = nil
+	sink.Flush()
+	assert.Nil(t, fwd.payloads)
+}
+
+func TestSinkSketches(t *testing.T) {
+	fwd := &fakeForwarder{}
+	sink := NewSink(fwd, []EndpointConfig{{Name: "cortex"}}, []float64{0, 1}, 0)
+
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1, 2, 3, 4)
+
+	sink.AppendSketches(metrics.SketchSeriesList{
+		{
+			Name:   "my.dist",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+			Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}},
+		},
+	})
+	sink.Flush()
+
+	assert.Equal(t, []string{
+		"__name__=my_dist,env=prod,quantile=0 1@10000",
+		"__name__=my_dist,env=prod,quantile=1 4@10000",
+		"__name__=my_dist_count,env=prod 4@10000",
+		"__name__=my_dist_sum,env=prod 10@10000",
+	}, decodeAll(t, fwd.payloads["cortex"]))
+}
+
+func TestSinkMetricPrefixes(t *testing.T) {
+	fwd := &fakeForwarder{}
+	sink := NewSink(fwd, []EndpointConfig{
+		{Name: "all"},
+		{Name: "system", MetricPrefixes: []string{"system."}},
+		{Name: "none", MetricPrefixes: []string{"nginx."}},
+	}, nil, 0)
+
+	sink.AppendSerie(&metrics.Serie{Name: "system.load.1", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+	sink.AppendSerie(&metrics.Serie{Name: "redis.net.clients", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+	sink.Flush()
+
+	assert.Equal(t, []string{
+		"__name__=redis_net_clients 1@10000",
+		"__name__=system_load_1 1@10000",
+	}, decodeAll(t, fwd.payloads["all"]))
+	assert.Equal(t, []string{
+		"__name__=system_load_1 1@10000",
+	}, decodeAll(t, fwd.payloads["system"]))
+	assert.NotContains(t, fwd.payloads, "none")
+}
+
+func TestSinkSplitPayloads(t *testing.T) {
+	fwd := &fakeForwarder{}
+	sink := NewSink(fwd, []EndpointConfig{{Name: "cortex"}}, nil, 2)
+
+	for i := 0; i < 5; i++ {
+		sink.AppendSerie(&metrics.Serie{Name: fmt.Sprintf("metric.%d", i), Points: []metrics.Point{{Ts: 10, Value: 1}}})
+	}
+	sink.Flush()
+
+	assert.Len(t, fwd.payloads["cortex"], 3)
+	assert.Len(t, decodeAll(t, fwd.payloads["cortex"]), 5)
+}
+
+func TestEndpointsFromConfig(t *testing.T) {
+	cfg := config.NewConfig("test", "DD", strings.NewReplacer(".", "_"))
+	cfg.SetConfigType("yaml")
+	err := cfg.ReadConfig(strings.NewReader(`
+prometheus_remote_write:
+  endpoints:
+    - name: cortex
+      url: http://cortex:9009/api/v1/push
+      headers:
+        X-Scope-OrgID: tenant
+      metric_prefixes:
+        - system.
+    - url: http://mimir/api/v1/push
+`))
+	require.NoError(t, err)
+
+	endpoints, err := EndpointsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []EndpointConfig{
+		{
+			Name:           "cortex",
+			URL:            "http://cortex:9009/api/v1/push",
+			Headers:        map[string]string{"X-Scope-OrgID": "tenant"},
+			MetricPrefixes: []string{"system."},
+		},
+		{
+			Name: "http://mimir/api/v1/push",
+			URL:  "http://mimir/api/v1/push",
+		},
+	}, endpoints)
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package devicestore keeps the metadata of the network devices monitored by the SNMP check in memory, for the other
+// network device sources of the agent, like traps and flows, to enrich their data with it.
+package devicestore
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultTTL is the duration after which a device which wasn't updated is forgotten. The SNMP check reports device
+// metadata at each check run, a device unreachable for this long is considered gone.
+const defaultTTL = time.Hour
+
+// Interface contains the metadata of a network device interface
+type Interface struct {
+	Index       int32
+	IDTags      []string
+	Name        string
+	Alias       string
+	Description string
+	MacAddress  string
+	AdminStatus int32
+	OperStatus  int32
+}
+
+// Device contains the metadata of a network device
+type Device struct {
+	ID          string
+	IPAddress   string
+	Namespace   string
+	Subnet      string
+	Name        string
+	Description string
+	SysObjectID string
+	Vendor      string
+	Model       string
+	Tags        []string
+	Interfaces  map[int32]Interface
+
+	updated time.Time
+}
+
+type deviceKey struct {
+	namespace string
+	ipAddress string
+}
+
+// Store holds the metadata of network devices, by namespace and IP address
+type Store struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	devices map[deviceKey]*Device
+}
+
+// NewStore returns a new empty Store
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		devices: make(map[deviceKey]*Device),
+	}
+}
+
+// Update sets the metadata of a device, replacing its previous metadata
+func (s *Store) Update(device Device) {
+	device.updated = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.devices[deviceKey{device.Namespace, device.IPAddress}] = &device
+	s.expire(device.updated)
+}
+
+// expire drops the devices which weren't updated for longer than the TTL
+func (s *Store) expire(now time.Time) {
+	for key, device := range s.devices {
+		if now.Sub(device.updated) > s.ttl {
+			delete(s.devices, key)
+		}
+	}
+}
+
+// Get returns the metadata of the device with the provided namespace and IP address
+func (s *Store) Get(namespace, ipAddress string) (Device, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	device, ok := s.devices[deviceKey{namespace, ipAddress}]
+	if !ok || time.Since(device.updated) > s.ttl {
+		return Device{}, false
+	}
+	return *device, true
+}
+
+// SetInterfaceStatus updates the admin and oper status of an interface of a device, e.g. when a trap reports a
+// link going down before the next check run polls it. A zero status is left unchanged. It returns the updated
+// device, or false if the device or the interface is unknown.
+func (s *Store) SetInterfaceStatus(namespace, ipAddress string, index int32, adminStatus, operStatus int32) (Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, ok := s.devices[deviceKey{namespace, ipAddress}]
+	if !ok || time.Since(device.updated) > s.ttl {
+		return Device{}, false
+	}
+	networkInterface, ok := device.Interfaces[index]
+	if !ok {
+		return Device{}, false
+	}
+	if adminStatus != 0 {
+		networkInterface.AdminStatus = adminStatus
+	}
+	if operStatus != 0 {
+		networkInterface.OperStatus = operStatus
+	}
+
+	// the interfaces map is shared with the devices returned by Get, it is copied instead of updated in place
+	interfaces := make(map[int32]Interface, len(device.Interfaces))
+	for i, existing := range device.Interfaces {
+		interfaces[i] = existing
+	}
+	interfaces[index] = networkInterface
+	updated := *device
+	updated.Interfaces = interfaces
+	s.devices[deviceKey{namespace, ipAddress}] = &updated
+	return updated, true
+}
+
+// Len returns the number of devices in the store
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.devices)
+}
+
+var defaultStore = NewStore(defaultTTL)
+
+// Update sets the metadata of a device in the global store
+func Update(device Device) {
+	defaultStore.Update(device)
+}
+
+// Get returns the metadata of a device from the global store
+func Get(namespace, ipAddress string) (Device, bool) {
+	return defaultStore.Get(namespace, ipAddress)
+}
+
+// SetInterfaceStatus updates the status of an interface of a device of the global store
+func SetInterfaceStatus(namespace, ipAddress string, index int32, adminStatus, operStatus int32) (Device, bool) {
+	return defaultStore.SetInterfaceStatus(namespace, ipAddress, index, adminStatus, operStatus)
+}
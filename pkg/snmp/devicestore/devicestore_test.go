@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package devicestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(time.Hour)
+
+	store.Update(Device{
+		ID:        "default:10.0.0.1",
+		IPAddress: "10.0.0.1",
+		Namespace: "default",
+		Name:      "router-1",
+		Interfaces: map[int32]Interface{
+			1: {Index: 1, Name: "eth0"},
+		},
+	})
+
+	device, ok := store.Get("default", "10.0.0.1")
+	assert.True(t, ok)
+	assert.Equal(t, "router-1", device.Name)
+	assert.Equal(t, "eth0", device.Interfaces[1].Name)
+
+	_, ok = store.Get("other", "10.0.0.1")
+	assert.False(t, ok)
+	_, ok = store.Get("default", "10.0.0.2")
+	assert.False(t, ok)
+
+	// a new update replaces the device
+	store.Update(Device{IPAddress: "10.0.0.1", Namespace: "default", Name: "router-2"})
+	device, _ = store.Get("default", "10.0.0.1")
+	assert.Equal(t, "router-2", device.Name)
+	assert.Empty(t, device.Interfaces)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestStoreExpiration(t *testing.T) {
+	store := NewStore(time.Minute)
+	store.Update(Device{IPAddress: "10.0.0.1", Namespace: "default"})
+
+	store.devices[deviceKey{"default", "10.0.0.1"}].updated = time.Now().Add(-2 * time.Minute)
+	_, ok := store.Get("default", "10.0.0.1")
+	assert.False(t, ok)
+
+	// expired devices are dropped on the next update
+	store.Update(Device{IPAddress: "10.0.0.2", Namespace: "default"})
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestStoreSetInterfaceStatus(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Update(Device{
+		IPAddress: "10.0.0.1",
+		Namespace: "default",
+		Interfaces: map[int32]Interface{
+			1: {Index: 1, Name: "eth0", AdminStatus: 1, OperStatus: 1},
+			2: {Index: 2, Name: "eth1", AdminStatus: 1, OperStatus: 1},
+		},
+	})
+	before, _ := store.Get("default", "10.0.0.1")
+
+	device, ok := store.SetInterfaceStatus("default", "10.0.0.1", 2, 0, 2)
+	assert.True(t, ok)
+	assert.Equal(t, Interface{Index: 2, Name: "eth1", AdminStatus: 1, OperStatus: 2}, device.Interfaces[2])
+	assert.Equal(t, Interface{Index: 1, Name: "eth0", AdminStatus: 1, OperStatus: 1}, device.Interfaces[1])
+
+	device, _ = store.Get("default", "10.0.0.1")
+	assert.Equal(t, int32(2), device.Interfaces[2].OperStatus)
+	// the devices returned before are not modified
+	assert.Equal(t, int32(1), before.Interfaces[2].OperStatus)
+
+	_, ok = store.SetInterfaceStatus("default", "10.0.0.1", 3, 0, 2)
+	assert.False(t, ok)
+	_, ok = store.SetInterfaceStatus("default", "10.0.0.2", 1, 0, 2)
+	assert.False(t, ok)
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+// builtinModules are the core SMI modules imported by almost all the MIBs. They're always available, even when they
+// aren't in the MIB directories. Only the parts used by the compiler are defined, the macros are left empty.
+var builtinModules = map[string]string{
+	"SNMPv2-SMI": `
+SNMPv2-SMI DEFINITIONS ::= BEGIN
+
+org            OBJECT IDENTIFIER ::= { iso 3 }
+dod            OBJECT IDENTIFIER ::= { org 6 }
+internet       OBJECT IDENTIFIER ::= { dod 1 }
+directory      OBJECT IDENTIFIER ::= { internet 1 }
+mgmt           OBJECT IDENTIFIER ::= { internet 2 }
+mib-2          OBJECT IDENTIFIER ::= { mgmt 1 }
+transmission   OBJECT IDENTIFIER ::= { mib-2 10 }
+experimental   OBJECT IDENTIFIER ::= { internet 3 }
+private        OBJECT IDENTIFIER ::= { internet 4 }
+enterprises    OBJECT IDENTIFIER ::= { private 1 }
+security       OBJECT IDENTIFIER ::= { internet 5 }
+snmpV2         OBJECT IDENTIFIER ::= { internet 6 }
+snmpDomains    OBJECT IDENTIFIER ::= { snmpV2 1 }
+snmpProxys     OBJECT IDENTIFIER ::= { snmpV2 2 }
+snmpModules    OBJECT IDENTIFIER ::= { snmpV2 3 }
+zeroDotZero    OBJECT IDENTIFIER ::= { 0 0 }
+
+MODULE-IDENTITY MACRO ::= BEGIN END
+OBJECT-IDENTITY MACRO ::= BEGIN END
+OBJECT-TYPE MACRO ::= BEGIN END
+NOTIFICATION-TYPE MACRO ::= BEGIN END
+
+ObjectName ::= OBJECT IDENTIFIER
+NotificationName ::= OBJECT IDENTIFIER
+ExtUTCTime ::= OCTET STRING (SIZE(11 | 13))
+Integer32 ::= INTEGER (-2147483648..2147483647)
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter32 ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+Gauge32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+Unsigned32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+TimeTicks ::= [APPLICATION 3] IMPLICIT INTEGER (0..4294967295)
+Opaque ::= [APPLICATION 4] IMPLICIT OCTET STRING
+Counter64 ::= [APPLICATION 6] IMPLICIT INTEGER (0..18446744073709551615)
+
+END
+`,
+	"SNMPv2-TC": `
+SNMPv2-TC DEFINITIONS ::= BEGIN
+
+IMPORTS
+    TimeTicks FROM SNMPv2-SMI;
+
+TEXTUAL-CONVENTION MACRO ::= BEGIN END
+
+DisplayString ::= TEXTUAL-CONVENTION
+    SYNTAX OCTET STRING (SIZE (0..255))
+PhysAddress ::= TEXTUAL-CONVENTION
+    SYNTAX OCTET STRING
+MacAddress ::= TEXTUAL-CONVENTION
+    SYNTAX OCTET STRING (SIZE (6))
+TruthValue ::= TEXTUAL-CONVENTION
+    SYNTAX INTEGER { true(1), false(2) }
+TestAndIncr ::= TEXTUAL-CONVENTION
+    SYNTAX INTEGER (0..2147483647)
+AutonomousType ::= TEXTUAL-CONVENTION
+    SYNTAX OBJECT IDENTIFIER
+InstancePointer ::= TEXTUAL-CONVENTION
+    SYNTAX OBJECT IDENTIFIER
+VariablePointer ::= TEXTUAL-CONVENTION
+    SYNTAX OBJECT IDENTIFIER
+RowPointer ::= TEXTUAL-CONVENTION
+    SYNTAX OBJECT IDENTIFIER
+RowStatus ::= TEXTUAL-CONVENTION
+    SYNTAX INTEGER { active(1), notInService(2), notReady(3), createAndGo(4), createAndWait(5), destroy(6) }
+TimeStamp ::= TEXTUAL-CONVENTION
+    SYNTAX TimeTicks
+TimeInterval ::= TEXTUAL-CONVENTION
+    SYNTAX INTEGER (0..2147483647)
+DateAndTime ::= TEXTUAL-CONVENTION
+    SYNTAX OCTET STRING (SIZE (8 | 11))
+StorageType ::= TEXTUAL-CONVENTION
+    SYNTAX INTEGER { other(1), volatile(2), nonVolatile(3), permanent(4), readOnly(5) }
+TDomain ::= TEXTUAL-CONVENTION
+    SYNTAX OBJECT IDENTIFIER
+TAddress ::= TEXTUAL-CONVENTION
+    SYNTAX OCTET STRING (SIZE (1..255))
+
+END
+`,
+	"SNMPv2-CONF": `
+SNMPv2-CONF DEFINITIONS ::= BEGIN
+
+OBJECT-GROUP MACRO ::= BEGIN END
+NOTIFICATION-GROUP MACRO ::= BEGIN END
+MODULE-COMPLIANCE MACRO ::= BEGIN END
+AGENT-CAPABILITIES MACRO ::= BEGIN END
+
+END
+`,
+	"RFC1155-SMI": `
+RFC1155-SMI DEFINITIONS ::= BEGIN
+
+internet      OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 }
+directory     OBJECT IDENTIFIER ::= { internet 1 }
+mgmt          OBJECT IDENTIFIER ::= { internet 2 }
+experimental  OBJECT IDENTIFIER ::= { internet 3 }
+private       OBJECT IDENTIFIER ::= { internet 4 }
+enterprises   OBJECT IDENTIFIER ::= { private 1 }
+
+OBJECT-TYPE MACRO ::= BEGIN END
+
+ObjectName ::= OBJECT IDENTIFIER
+NetworkAddress ::= CHOICE { internet IpAddress }
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+Gauge ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+TimeTicks ::= [APPLICATION 3] IMPLICIT INTEGER (0..4294967295)
+Opaque ::= [APPLICATION 4] IMPLICIT OCTET STRING
+
+END
+`,
+	"RFC-1212": `
+RFC-1212 DEFINITIONS ::= BEGIN
+
+OBJECT-TYPE MACRO ::= BEGIN END
+
+END
+`,
+	"RFC-1215": `
+RFC-1215 DEFINITIONS ::= BEGIN
+
+TRAP-TYPE MACRO ::= BEGIN END
+
+END
+`,
+}
@@ -0,0 +1,327 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxResolutionDepth bounds the OID and type resolutions, to detect the definition cycles
+const maxResolutionDepth = 64
+
+// mibFileExtensions are the extensions tried when looking for the file of a module in the MIB directories
+var mibFileExtensions = []string{"", ".txt", ".mib", ".my", ".smi"}
+
+// rootOIDs are the OID roots, not defined in any module
+var rootOIDs = map[string]string{
+	"ccitt":           "0",
+	"iso":             "1",
+	"joint-iso-ccitt": "2",
+}
+
+// baseTypes are the types the type resolution stops at
+var baseTypes = map[string]bool{
+	"INTEGER":           true,
+	"OCTET STRING":      true,
+	"OBJECT IDENTIFIER": true,
+	"BITS":              true,
+	"SEQUENCE":          true,
+	"SEQUENCE OF":       true,
+	"CHOICE":            true,
+	"Integer32":         true,
+	"Unsigned32":        true,
+	"Counter32":         true,
+	"Counter64":         true,
+	"Gauge32":           true,
+	"TimeTicks":         true,
+	"IpAddress":         true,
+	"Opaque":            true,
+	"Counter":           true,
+	"Gauge":             true,
+	"NetworkAddress":    true,
+}
+
+// Compiler loads MIB modules and the modules they import, and resolves their OIDs and types
+type Compiler struct {
+	mibDirs []string
+	modules map[string]*Module
+	// moduleFiles maps the module names to their files, it is built when a module isn't found by its file name
+	moduleFiles map[string]string
+	oids        map[*Definition]string
+}
+
+// NewCompiler creates a Compiler looking for the imported modules in mibDirs
+func NewCompiler(mibDirs []string) *Compiler {
+	return &Compiler{
+		mibDirs: mibDirs,
+		modules: make(map[string]*Module),
+		oids:    make(map[*Definition]string),
+	}
+}
+
+// LoadFile loads the modules defined in a MIB file and the modules they import
+func (c *Compiler) LoadFile(path string) ([]*Module, error) {
+	modules, err := c.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		if err := c.loadImports(module); err != nil {
+			return nil, err
+		}
+	}
+	return modules, nil
+}
+
+// LoadModule loads a module, from the builtin modules or from the MIB directories, and the modules it imports
+func (c *Compiler) LoadModule(name string) (*Module, error) {
+	if module, ok := c.modules[name]; ok {
+		return module, nil
+	}
+
+	var modules []*Module
+	var err error
+	if src, ok := builtinModules[name]; ok {
+		modules, err = parseModules(src)
+		if err != nil {
+			return nil, fmt.Errorf("builtin module %s: %s", name, err)
+		}
+		c.register(modules, "")
+	} else {
+		path, err := c.findModuleFile(name)
+		if err != nil {
+			return nil, err
+		}
+		modules, err = c.parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	module, ok := c.modules[name]
+	if !ok {
+		return nil, fmt.Errorf("module %s not found", name)
+	}
+	for _, m := range modules {
+		if err := c.loadImports(m); err != nil {
+			return nil, err
+		}
+	}
+	return module, nil
+}
+
+func (c *Compiler) parseFile(path string) ([]*Module, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read MIB file: %s", err)
+	}
+	modules, err := parseModules(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse MIB file %s: %s", path, err)
+	}
+	c.register(modules, path)
+	return modules, nil
+}
+
+func (c *Compiler) register(modules []*Module, path string) {
+	for _, module := range modules {
+		if _, ok := c.modules[module.Name]; ok {
+			log.Debugf("module %s is defined multiple times, using the definition of %s", module.Name, path)
+		}
+		module.Path = path
+		c.modules[module.Name] = module
+	}
+}
+
+func (c *Compiler) loadImports(module *Module) error {
+	var importedModules []string
+	for _, from := range module.Imports {
+		importedModules = append(importedModules, from)
+	}
+	sort.Strings(importedModules)
+	for _, from := range importedModules {
+		if _, ok := c.modules[from]; ok {
+			continue
+		}
+		if _, err := c.LoadModule(from); err != nil {
+			return fmt.Errorf("unable to load module %s imported by %s: %s", from, module.Name, err)
+		}
+	}
+	for symbol, from := range module.Imports {
+		if _, ok := c.modules[from].Definitions[symbol]; !ok {
+			log.Warnf("module %s imports %s from %s, which doesn't define it", module.Name, symbol, from)
+		}
+	}
+	return nil
+}
+
+// findModuleFile looks for the file of a module in the MIB directories, first by file name, then by
+// parsing the headers of all the files
+func (c *Compiler) findModuleFile(name string) (string, error) {
+	for _, dir := range c.mibDirs {
+		for _, ext := range mibFileExtensions {
+			for _, fileName := range []string{name + ext, strings.ToLower(name) + ext} {
+				path := filepath.Join(dir, fileName)
+				if info, err := os.Stat(path); err == nil && !info.IsDir() {
+					return path, nil
+				}
+			}
+		}
+	}
+
+	if c.moduleFiles == nil {
+		c.moduleFiles = make(map[string]string)
+		for _, dir := range c.mibDirs {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				log.Warnf("unable to read MIB directory %s: %s", dir, err)
+				continue
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					continue
+				}
+				path := filepath.Join(dir, entry.Name())
+				for _, moduleName := range readModuleNames(path) {
+					if _, ok := c.moduleFiles[moduleName]; !ok {
+						c.moduleFiles[moduleName] = path
+					}
+				}
+			}
+		}
+	}
+	if path, ok := c.moduleFiles[name]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("no file defining module %s found in the MIB directories %v", name, c.mibDirs)
+}
+
+// readModuleNames returns the names of the modules defined in a file, without parsing the modules
+func readModuleNames(path string) []string {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].kind == tokenIdentifier && tokens[i].text == "DEFINITIONS" && tokens[i-1].kind == tokenIdentifier {
+			names = append(names, tokens[i-1].text)
+		}
+	}
+	return names
+}
+
+// lookup returns the definition of a symbol of a module, following the imports
+func (c *Compiler) lookup(module *Module, name string) (*Module, *Definition, error) {
+	for depth := 0; depth < maxResolutionDepth; depth++ {
+		if def, ok := module.Definitions[name]; ok {
+			return module, def, nil
+		}
+		from, ok := module.Imports[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s is not defined in module %s", name, module.Name)
+		}
+		if module, ok = c.modules[from]; !ok {
+			return nil, nil, fmt.Errorf("module %s is not loaded", from)
+		}
+	}
+	return nil, nil, fmt.Errorf("%s: import cycle", name)
+}
+
+// ResolveOID returns the OID of a definition of a module
+func (c *Compiler) ResolveOID(module *Module, name string) (string, error) {
+	return c.resolveOID(module, name, 0)
+}
+
+func (c *Compiler) resolveOID(module *Module, name string, depth int) (string, error) {
+	if depth > maxResolutionDepth {
+		return "", fmt.Errorf("%s: OID definition cycle", name)
+	}
+	if oid, ok := rootOIDs[name]; ok {
+		return oid, nil
+	}
+	module, def, err := c.lookup(module, name)
+	if err != nil {
+		return "", err
+	}
+	if oid, ok := c.oids[def]; ok {
+		return oid, nil
+	}
+	if len(def.OID) == 0 {
+		return "", fmt.Errorf("%s of module %s has no OID", name, module.Name)
+	}
+
+	parts := make([]string, 0, len(def.OID))
+	for i, component := range def.OID {
+		switch {
+		case i == 0 && component.Name != "" && (!component.HasNumber || rootOIDs[component.Name] == ""):
+			// the first component is a reference to the parent node, e.g. `{ mib-2 2 }`
+			parentOID, err := c.resolveOID(module, component.Name, depth+1)
+			if err != nil {
+				if !component.HasNumber {
+					return "", fmt.Errorf("unable to resolve %s: %s", name, err)
+				}
+				parentOID = strconv.Itoa(component.Number)
+			}
+			parts = append(parts, parentOID)
+		case component.HasNumber:
+			parts = append(parts, strconv.Itoa(component.Number))
+		default:
+			return "", fmt.Errorf("unable to resolve %s: OID component %s has no number", name, component.Name)
+		}
+	}
+	oid := strings.Join(parts, ".")
+	c.oids[def] = oid
+	return oid, nil
+}
+
+// TrapOID returns the OID of a `NOTIFICATION-TYPE`, or the OID of a `TRAP-TYPE` built from its enterprise and
+// trap number the way the SNMPv1 traps are translated to SNMPv2 (RFC 3584): `<enterprise>.0.<trap number>`
+func (c *Compiler) TrapOID(module *Module, def *Definition) (string, error) {
+	if def.Kind != KindTrapType {
+		return c.ResolveOID(module, def.Name)
+	}
+	if def.Enterprise == "" {
+		return "", fmt.Errorf("trap %s has no ENTERPRISE", def.Name)
+	}
+	enterpriseOID, err := c.ResolveOID(module, def.Enterprise)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.0.%d", enterpriseOID, def.TrapNumber), nil
+}
+
+// ResolveSyntax returns the base type of a syntax, e.g. `OCTET STRING` for `DisplayString`, and its enum values,
+// that are either defined by the syntax or by one of the types it refers to
+func (c *Compiler) ResolveSyntax(module *Module, syntax *Syntax) (string, []NamedNumber) {
+	enum := syntax.Enum
+	for depth := 0; depth < maxResolutionDepth; depth++ {
+		if baseTypes[syntax.Type] {
+			return syntax.Type, enum
+		}
+		typeModule, def, err := c.lookup(module, syntax.Type)
+		if err != nil || def.Kind != KindType || def.Syntax == nil {
+			log.Debugf("unable to resolve type %s of module %s", syntax.Type, module.Name)
+			return syntax.Type, enum
+		}
+		module, syntax = typeModule, def.Syntax
+		if len(enum) == 0 {
+			enum = syntax.Enum
+		}
+	}
+	return syntax.Type, enum
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestModule(t *testing.T, fileName string) (*Compiler, *Module) {
+	compiler := NewCompiler([]string{"testdata"})
+	modules, err := compiler.LoadFile(filepath.Join("testdata", fileName))
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	return compiler, modules[0]
+}
+
+func TestCompiler_ResolveOID(t *testing.T) {
+	compiler, module := loadTestModule(t, "ACME-MIB.txt")
+
+	tests := []struct {
+		name        string
+		expectedOID string
+	}{
+		{"acmeMIB", "1.3.6.1.4.1.99999"},
+		{"acmeTemperature", "1.3.6.1.4.1.99999.1.1"},
+		{"acmeFanStatus", "1.3.6.1.4.1.99999.1.3.1.2"},
+		{"acmeFanFailure", "1.3.6.1.4.1.99999.0.1"},
+		// imported
+		{"enterprises", "1.3.6.1.4.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oid, err := compiler.ResolveOID(module, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOID, oid)
+		})
+	}
+
+	_, err := compiler.ResolveOID(module, "unknown")
+	assert.EqualError(t, err, "unknown is not defined in module ACME-MIB")
+}
+
+func TestCompiler_ResolveSyntax(t *testing.T) {
+	compiler, module := loadTestModule(t, "ACME-MIB.txt")
+
+	baseType, enum := compiler.ResolveSyntax(module, module.Definitions["acmeFanStatus"].Syntax)
+	assert.Equal(t, "INTEGER", baseType)
+	assert.Equal(t, []NamedNumber{{"ok", 1}, {"degraded", 2}, {"failed", 3}}, enum)
+
+	baseType, enum = compiler.ResolveSyntax(module, module.Definitions["acmeName"].Syntax)
+	assert.Equal(t, "OCTET STRING", baseType)
+	assert.Empty(t, enum)
+
+	baseType, _ = compiler.ResolveSyntax(module, module.Definitions["acmeFanFailures"].Syntax)
+	assert.Equal(t, "Counter64", baseType)
+}
+
+func TestCompiler_TrapOID_v1(t *testing.T) {
+	compiler, module := loadTestModule(t, "ACME-V1-MIB.my")
+
+	oid, err := compiler.TrapOID(module, module.Definitions["acmeV1PowerOff"])
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.99998.0.3", oid)
+}
+
+func TestCompiler_LoadFile_missingImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TEST-MIB")
+	require.NoError(t, os.WriteFile(path, []byte("TEST-MIB DEFINITIONS ::= BEGIN\nIMPORTS foo FROM UNKNOWN-MIB;\nEND\n"), 0644))
+
+	_, err := NewCompiler([]string{"testdata"}).LoadFile(path)
+	assert.EqualError(t, err, "unable to load module UNKNOWN-MIB imported by TEST-MIB: no file defining module UNKNOWN-MIB found in the MIB directories [testdata]")
+}
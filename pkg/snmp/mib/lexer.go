@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	tokenBinaryString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return "string"
+	}
+	return "`" + t.text + "`"
+}
+
+// tokenize splits the source of a MIB file into tokens, comments are dropped
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(src[i:], "--"):
+			// a comment ends at the end of the line or at the next `--`
+			i += 2
+			for i < len(src) && src[i] != '\n' {
+				if strings.HasPrefix(src[i:], "--") {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i+1, line
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", startLine)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[start:i], line: startLine})
+			i++
+		case c == '\'':
+			// binary or hexadecimal string: '0101'B or 'ff'H
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end < 0 || i+end+2 >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated binary string", line)
+			}
+			end += i + 1
+			tokens = append(tokens, token{kind: tokenBinaryString, text: src[i : end+2], line: line})
+			i = end + 2
+		case strings.HasPrefix(src[i:], "::="):
+			tokens = append(tokens, token{kind: tokenSymbol, text: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(src[i:], ".."):
+			tokens = append(tokens, token{kind: tokenSymbol, text: "..", line: line})
+			i += 2
+		case isDigit(c) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], line: line})
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i]) || src[i] == '_' || src[i] == '-') {
+				if strings.HasPrefix(src[i:], "--") {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: src[start:i], line: line})
+		case strings.IndexByte("{}(),;|[].:<>", c) >= 0:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), line: line})
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
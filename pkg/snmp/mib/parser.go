@@ -0,0 +1,591 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefinitionKind is the kind of a MIB module definition
+type DefinitionKind int
+
+const (
+	// KindObjectIdentifier is an OID node that doesn't hold a value: `OBJECT IDENTIFIER`, `MODULE-IDENTITY`,
+	// `OBJECT-IDENTITY`, conformance groups, ...
+	KindObjectIdentifier DefinitionKind = iota
+	// KindObjectType is an `OBJECT-TYPE`: scalar, table, table entry or column
+	KindObjectType
+	// KindNotificationType is a SMIv2 `NOTIFICATION-TYPE`
+	KindNotificationType
+	// KindTrapType is a SMIv1 `TRAP-TYPE`
+	KindTrapType
+	// KindType is a type assignment or a `TEXTUAL-CONVENTION`
+	KindType
+	// KindMacro is a `MACRO` definition, only found in the core SMI modules
+	KindMacro
+	// KindValue is any other value assignment
+	KindValue
+)
+
+// NamedNumber is a value of an enumerated INTEGER or of a BITS
+type NamedNumber struct {
+	Name  string
+	Value int
+}
+
+// Syntax is the type of an object or of a type assignment
+type Syntax struct {
+	// Type is either a base type (`INTEGER`, `OCTET STRING`, `OBJECT IDENTIFIER`, `BITS`, `SEQUENCE`, `SEQUENCE OF`,
+	// `CHOICE`) or the name of another type, e.g. `Counter32` or `DisplayString`
+	Type string
+	// Enum holds the values of an enumerated INTEGER or of a BITS
+	Enum []NamedNumber
+	// EntryType is the type of the rows of a `SEQUENCE OF`
+	EntryType string
+}
+
+// OIDComponent is a component of an OID value, e.g. `mib-2`, `ifTable(2)` or `1`
+type OIDComponent struct {
+	Name      string
+	Number    int
+	HasNumber bool
+}
+
+// Definition is a definition of a MIB module
+type Definition struct {
+	Name        string
+	Kind        DefinitionKind
+	Macro       string
+	Description string
+	Syntax      *Syntax
+	Access      string
+	Index       []string
+	Augments    string
+	// Objects are the objects of a `NOTIFICATION-TYPE` or the variables of a `TRAP-TYPE`
+	Objects    []string
+	Enterprise string
+	TrapNumber int
+	OID        []OIDComponent
+	Line       int
+}
+
+// Module is a parsed MIB module
+type Module struct {
+	Name string
+	// Imports maps the imported symbols to the name of the module they're imported from
+	Imports     map[string]string
+	Definitions map[string]*Definition
+	// Order holds the names of the definitions in the order of the source
+	Order []string
+	Path  string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseModules parses all the modules of a MIB file
+func parseModules(src string) ([]*Module, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	var modules []*Module
+	for p.peek().kind != tokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no module found")
+	}
+	return modules, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); t.kind != tokenString && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.kind == tokenString || t.text != text {
+		return fmt.Errorf("line %d: expected `%s`, got %s", t.line, text, t)
+	}
+	return nil
+}
+
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("line %d: expected %s, got %s", t.line, what, t)
+	}
+	return t, nil
+}
+
+// skipBalanced skips a group opened by the current `{`, `(` or `[` token, including the nested groups
+func (p *parser) skipBalanced() error {
+	open := p.next()
+	closing := map[string]string{"{": "}", "(": ")", "[": "]"}[open.text]
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: `%s` is not closed", open.line, open.text)
+		case t.kind == tokenSymbol && t.text == open.text:
+			depth++
+		case t.kind == tokenSymbol && t.text == closing:
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *parser) isGroupStart() bool {
+	t := p.peek()
+	return t.kind == tokenSymbol && (t.text == "{" || t.text == "(" || t.text == "[")
+}
+
+func (p *parser) parseModule() (*Module, error) {
+	name, err := p.expectKind(tokenIdentifier, "module name")
+	if err != nil {
+		return nil, err
+	}
+	module := &Module{
+		Name:        name.text,
+		Imports:     make(map[string]string),
+		Definitions: make(map[string]*Definition),
+	}
+	if p.isGroupStart() {
+		// module OID, e.g. in ASN.1 modules
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	// tagging default, e.g. `IMPLICIT TAGS`
+	for p.peek().kind == tokenIdentifier && p.peek().text != "BEGIN" {
+		p.next()
+	}
+	if err := p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	if p.accept("EXPORTS") {
+		for p.peek().kind != tokenEOF && !p.accept(";") {
+			p.next()
+		}
+	}
+	if p.accept("IMPORTS") {
+		if err := p.parseImports(module); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return nil, fmt.Errorf("line %d: module %s: missing `END`", t.line, module.Name)
+		}
+		if t.kind == tokenIdentifier && t.text == "END" {
+			p.next()
+			return module, nil
+		}
+		def, err := p.parseDefinition()
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %s", module.Name, err)
+		}
+		if _, ok := module.Definitions[def.Name]; !ok {
+			module.Order = append(module.Order, def.Name)
+		}
+		module.Definitions[def.Name] = def
+	}
+}
+
+func (p *parser) parseImports(module *Module) error {
+	var symbols []string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: module %s: unterminated IMPORTS", t.line, module.Name)
+		case t.kind == tokenSymbol && t.text == ";":
+			if len(symbols) > 0 {
+				return fmt.Errorf("line %d: module %s: symbols imported without `FROM`", t.line, module.Name)
+			}
+			return nil
+		case t.kind == tokenSymbol && t.text == ",":
+		case t.kind == tokenIdentifier && t.text == "FROM":
+			from, err := p.expectKind(tokenIdentifier, "module name")
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				module.Imports[symbol] = from.text
+			}
+			symbols = nil
+			if p.isGroupStart() {
+				if err := p.skipBalanced(); err != nil {
+					return err
+				}
+			}
+		case t.kind == tokenIdentifier:
+			symbols = append(symbols, t.text)
+		default:
+			return fmt.Errorf("line %d: module %s: unexpected %s in IMPORTS", t.line, module.Name, t)
+		}
+	}
+}
+
+func (p *parser) parseDefinition() (*Definition, error) {
+	name, err := p.expectKind(tokenIdentifier, "definition name")
+	if err != nil {
+		return nil, err
+	}
+	def := &Definition{Name: name.text, Line: name.line}
+
+	switch {
+	case p.accept("MACRO"):
+		def.Kind = KindMacro
+		if err := p.expect("::="); err != nil {
+			return nil, err
+		}
+		for {
+			t := p.next()
+			if t.kind == tokenEOF {
+				return nil, fmt.Errorf("line %d: macro %s: missing `END`", def.Line, def.Name)
+			}
+			if t.kind == tokenIdentifier && t.text == "END" {
+				return def, nil
+			}
+		}
+	case p.accept("::="):
+		def.Kind = KindType
+		if p.accept("TEXTUAL-CONVENTION") {
+			def.Macro = "TEXTUAL-CONVENTION"
+			if err := p.parseClauses(def); err != nil {
+				return nil, err
+			}
+			if def.Syntax == nil {
+				return nil, fmt.Errorf("line %d: textual convention %s: missing SYNTAX", def.Line, def.Name)
+			}
+			return def, nil
+		}
+		def.Syntax, err = p.parseSyntax()
+		return def, err
+	case p.peek().text == "OBJECT" && p.peekAt(1).text == "IDENTIFIER":
+		p.pos += 2
+		def.Kind = KindObjectIdentifier
+		def.Macro = "OBJECT IDENTIFIER"
+	default:
+		macro := p.peek()
+		switch macro.text {
+		case "OBJECT-TYPE":
+			def.Kind = KindObjectType
+		case "NOTIFICATION-TYPE":
+			def.Kind = KindNotificationType
+		case "TRAP-TYPE":
+			def.Kind = KindTrapType
+		case "MODULE-IDENTITY", "OBJECT-IDENTITY", "OBJECT-GROUP", "NOTIFICATION-GROUP", "MODULE-COMPLIANCE", "AGENT-CAPABILITIES":
+			def.Kind = KindObjectIdentifier
+		default:
+			def.Kind = KindValue
+		}
+		def.Macro = macro.text
+		// the clauses of the other macros and the types of the other values are skipped
+		p.next()
+		if err := p.parseClauses(def); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect("::="); err != nil {
+		return nil, fmt.Errorf("%s (definition %s)", err, def.Name)
+	}
+	switch {
+	case def.Kind == KindTrapType:
+		number, err := p.expectKind(tokenNumber, "trap number")
+		if err != nil {
+			return nil, err
+		}
+		def.TrapNumber, _ = strconv.Atoi(number.text)
+	case p.peek().text == "{" && p.peek().kind == tokenSymbol:
+		def.OID, err = p.parseOIDValue()
+		if err != nil {
+			return nil, fmt.Errorf("%s (definition %s)", err, def.Name)
+		}
+		if def.Kind == KindValue {
+			def.Kind = KindObjectIdentifier
+		}
+	default:
+		// any other value, e.g. a number
+		p.next()
+	}
+	return def, nil
+}
+
+// parseClauses parses the clauses of a macro invocation, up to the `::=` token
+func (p *parser) parseClauses(def *Definition) error {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: definition %s: unexpected end of file", def.Line, def.Name)
+		case t.kind == tokenSymbol && t.text == "::=":
+			return nil
+		case t.kind == tokenSymbol && (t.text == "{" || t.text == "(" || t.text == "["):
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.next()
+		if t.kind != tokenIdentifier {
+			continue
+		}
+		if def.Kind != KindObjectType && def.Kind != KindNotificationType && def.Kind != KindTrapType && def.Kind != KindType {
+			// only the OID of the other macros is used, their clauses are skipped
+			continue
+		}
+
+		var err error
+		switch t.text {
+		case "SYNTAX":
+			def.Syntax, err = p.parseSyntax()
+			if def.Kind == KindType {
+				// SYNTAX is the last clause of a textual convention
+				return err
+			}
+		case "DESCRIPTION":
+			var description token
+			description, err = p.expectKind(tokenString, "description")
+			def.Description = description.text
+		case "MAX-ACCESS", "ACCESS":
+			var access token
+			access, err = p.expectKind(tokenIdentifier, "access")
+			def.Access = access.text
+		case "INDEX":
+			def.Index, err = p.parseNameList()
+		case "AUGMENTS":
+			var augments []string
+			augments, err = p.parseNameList()
+			if len(augments) > 0 {
+				def.Augments = augments[0]
+			}
+		case "OBJECTS", "VARIABLES":
+			def.Objects, err = p.parseNameList()
+		case "ENTERPRISE":
+			var enterprise token
+			enterprise, err = p.expectKind(tokenIdentifier, "enterprise")
+			def.Enterprise = enterprise.text
+		}
+		if err != nil {
+			return fmt.Errorf("%s (definition %s)", err, def.Name)
+		}
+	}
+}
+
+// parseNameList parses a `{ name, name }` list, the `IMPLIED` keywords of INDEX clauses are dropped
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenSymbol && t.text == "}":
+			return names, nil
+		case t.kind == tokenSymbol && t.text == ",":
+		case t.kind == tokenIdentifier && t.text == "IMPLIED":
+		case t.kind == tokenIdentifier:
+			names = append(names, t.text)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s in list", t.line, t)
+		}
+	}
+}
+
+func (p *parser) parseSyntax() (*Syntax, error) {
+	// tags of the core types, e.g. `[APPLICATION 1] IMPLICIT INTEGER`
+	if p.peek().text == "[" && p.peek().kind == tokenSymbol {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	p.accept("IMPLICIT")
+
+	t, err := p.expectKind(tokenIdentifier, "type")
+	if err != nil {
+		return nil, err
+	}
+	syntax := &Syntax{Type: t.text}
+	switch t.text {
+	case "OCTET":
+		if err := p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OCTET STRING"
+	case "OBJECT":
+		if err := p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OBJECT IDENTIFIER"
+	case "SEQUENCE":
+		if p.accept("OF") {
+			entry, err := p.expectKind(tokenIdentifier, "entry type")
+			if err != nil {
+				return nil, err
+			}
+			syntax.Type = "SEQUENCE OF"
+			syntax.EntryType = entry.text
+			return syntax, nil
+		}
+		return syntax, p.skipBalanced()
+	case "CHOICE":
+		return syntax, p.skipBalanced()
+	default:
+		if p.accept(".") {
+			// module qualified type, e.g. `SNMPv2-TC.DisplayString`
+			qualified, err := p.expectKind(tokenIdentifier, "type")
+			if err != nil {
+				return nil, err
+			}
+			syntax.Type = qualified.text
+		}
+	}
+
+	if p.peek().text == "{" && p.peek().kind == tokenSymbol {
+		syntax.Enum, err = p.parseNamedNumbers()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// constraints, e.g. `(SIZE (0..255))` or `(1..10)`
+	for p.peek().text == "(" && p.peek().kind == tokenSymbol {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	return syntax, nil
+}
+
+func (p *parser) parseNamedNumbers() ([]NamedNumber, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var values []NamedNumber
+	for {
+		name := p.next()
+		if name.kind == tokenSymbol && name.text == "}" {
+			return values, nil
+		}
+		if name.kind != tokenIdentifier {
+			return nil, fmt.Errorf("line %d: expected enum name, got %s", name.line, name)
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		number, err := p.expectKind(tokenNumber, "enum value")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		value, err := strconv.Atoi(number.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid enum value %s", number.line, number.text)
+		}
+		values = append(values, NamedNumber{Name: name.text, Value: value})
+		if !p.accept(",") {
+			if err := p.expect("}"); err != nil {
+				return nil, err
+			}
+			return values, nil
+		}
+	}
+}
+
+func (p *parser) parseOIDValue() ([]OIDComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var components []OIDComponent
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokenSymbol:
+			if t.text == "}" {
+				if len(components) == 0 {
+					return nil, fmt.Errorf("line %d: empty OID value", t.line)
+				}
+				return components, nil
+			}
+			return nil, fmt.Errorf("line %d: unexpected %s in OID value", t.line, t)
+		case tokenNumber:
+			number, err := strconv.Atoi(t.text)
+			if err != nil || number < 0 {
+				return nil, fmt.Errorf("line %d: invalid OID component %s", t.line, t.text)
+			}
+			components = append(components, OIDComponent{Number: number, HasNumber: true})
+		case tokenIdentifier:
+			component := OIDComponent{Name: t.text}
+			if p.accept("(") {
+				number, err := p.expectKind(tokenNumber, "OID component number")
+				if err != nil {
+					return nil, err
+				}
+				component.Number, _ = strconv.Atoi(number.text)
+				component.HasNumber = true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			components = append(components, component)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s in OID value", t.line, t)
+		}
+	}
+}
+
+// normalizeDescription collapses the indentation and line breaks of a MIB description
+func normalizeDescription(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
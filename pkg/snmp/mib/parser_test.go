@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_tokenize(t *testing.T) {
+	tokens, err := tokenize(`foo-bar OBJECT IDENTIFIER -- comment
+    ::= { iso 3 } -- comment -- baz "a -- b" 'ff'H (-1..10)`)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, token := range tokens {
+		texts = append(texts, token.text)
+	}
+	assert.Equal(t, []string{"foo-bar", "OBJECT", "IDENTIFIER", "::=", "{", "iso", "3", "}", "baz", "a -- b", "'ff'H", "(", "-1", "..", "10", ")", ""}, texts)
+	assert.Equal(t, 2, tokens[3].line)
+
+	_, err = tokenize(`foo "bar`)
+	assert.EqualError(t, err, "line 1: unterminated string")
+}
+
+func Test_parseModules(t *testing.T) {
+	modules, err := parseModules(`
+TEST-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    OBJECT-TYPE, Integer32, mib-2 FROM SNMPv2-SMI
+    DisplayString FROM SNMPv2-TC;
+
+testObjects OBJECT IDENTIFIER ::= { mib-2 999 }
+
+TestStatus ::= INTEGER { up(1), down(2) }
+
+testTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF TestEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "A table."
+    ::= { testObjects 1 }
+
+testEntry OBJECT-TYPE
+    SYNTAX      TestEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "An entry."
+    INDEX       { testIndex, IMPLIED testName }
+    ::= { testTable 1 }
+
+testNotification NOTIFICATION-TYPE
+    OBJECTS { testStatus }
+    STATUS  current
+    DESCRIPTION "A notification."
+    ::= { testObjects 0 1 }
+
+END
+`)
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	module := modules[0]
+
+	assert.Equal(t, "TEST-MIB", module.Name)
+	assert.Equal(t, map[string]string{
+		"OBJECT-TYPE":   "SNMPv2-SMI",
+		"Integer32":     "SNMPv2-SMI",
+		"mib-2":         "SNMPv2-SMI",
+		"DisplayString": "SNMPv2-TC",
+	}, module.Imports)
+	assert.Equal(t, []string{"testObjects", "TestStatus", "testTable", "testEntry", "testNotification"}, module.Order)
+
+	assert.Equal(t, &Definition{
+		Name:  "testObjects",
+		Kind:  KindObjectIdentifier,
+		Macro: "OBJECT IDENTIFIER",
+		OID:   []OIDComponent{{Name: "mib-2"}, {Number: 999, HasNumber: true}},
+		Line:  8,
+	}, module.Definitions["testObjects"])
+	assert.Equal(t, &Syntax{Type: "INTEGER", Enum: []NamedNumber{{"up", 1}, {"down", 2}}}, module.Definitions["TestStatus"].Syntax)
+	assert.Equal(t, &Syntax{Type: "SEQUENCE OF", EntryType: "TestEntry"}, module.Definitions["testTable"].Syntax)
+	assert.Equal(t, []string{"testIndex", "testName"}, module.Definitions["testEntry"].Index)
+	assert.Equal(t, "An entry.", module.Definitions["testEntry"].Description)
+
+	notification := module.Definitions["testNotification"]
+	assert.Equal(t, KindNotificationType, notification.Kind)
+	assert.Equal(t, []string{"testStatus"}, notification.Objects)
+	assert.Equal(t, []OIDComponent{{Name: "testObjects"}, {Number: 0, HasNumber: true}, {Number: 1, HasNumber: true}}, notification.OID)
+}
+
+func Test_parseModules_errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		expectedErr string
+	}{
+		{
+			name:        "no module",
+			src:         "-- only a comment",
+			expectedErr: "no module found",
+		},
+		{
+			name:        "missing end",
+			src:         "TEST-MIB DEFINITIONS ::= BEGIN\nfoo OBJECT IDENTIFIER ::= { iso 3 }\n",
+			expectedErr: "line 3: module TEST-MIB: missing `END`",
+		},
+		{
+			name:        "missing from",
+			src:         "TEST-MIB DEFINITIONS ::= BEGIN\nIMPORTS foo;\nEND",
+			expectedErr: "line 2: module TEST-MIB: symbols imported without `FROM`",
+		},
+		{
+			name:        "invalid OID value",
+			src:         "TEST-MIB DEFINITIONS ::= BEGIN\nfoo OBJECT IDENTIFIER ::= { iso , 3 }\nEND",
+			expectedErr: "module TEST-MIB: line 2: unexpected `,` in OID value (definition foo)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseModules(tt.src)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// numericTypes are the base types of the objects reported as metrics
+var numericTypes = map[string]bool{
+	"INTEGER":    true,
+	"Integer32":  true,
+	"Unsigned32": true,
+	"Counter32":  true,
+	"Counter64":  true,
+	"Gauge32":    true,
+	"TimeTicks":  true,
+	"Counter":    true,
+	"Gauge":      true,
+}
+
+// Profile is a profile skeleton, in the format of the snmp.d/profiles definition files
+type Profile struct {
+	Metrics []ProfileMetric `yaml:"metrics"`
+}
+
+// ProfileMetric is a scalar or table metric of a profile
+type ProfileMetric struct {
+	MIB        string             `yaml:"MIB"`
+	Symbol     *ProfileSymbol     `yaml:"symbol,omitempty"`
+	Table      *ProfileSymbol     `yaml:"table,omitempty"`
+	Symbols    []ProfileSymbol    `yaml:"symbols,omitempty"`
+	MetricTags []ProfileMetricTag `yaml:"metric_tags,omitempty"`
+}
+
+// ProfileSymbol is an OID and its name
+type ProfileSymbol struct {
+	OID  string `yaml:"OID"`
+	Name string `yaml:"name"`
+}
+
+// ProfileMetricTag is a tag of the rows of a table metric, either from a column or from an index
+type ProfileMetricTag struct {
+	Column  *ProfileSymbol    `yaml:"column,omitempty"`
+	Table   string            `yaml:"table,omitempty"`
+	Index   uint              `yaml:"index,omitempty"`
+	Mapping map[string]string `yaml:"mapping,omitempty"`
+	Tag     string            `yaml:"tag"`
+}
+
+type profileObject struct {
+	module   *Module
+	def      *Definition
+	oid      string
+	baseType string
+	enum     []NamedNumber
+}
+
+// Profile generates a profile skeleton reporting the numeric scalars and table columns of a module.
+// The table rows are tagged with their indexes. The profile doesn't have a `sysobjectid`, it has to be
+// completed with the ones of the devices it applies to.
+func (c *Compiler) Profile(module *Module) (*Profile, error) {
+	objects := make(map[string]*profileObject)
+	var ordered []*profileObject
+	for _, name := range module.Order {
+		def := module.Definitions[name]
+		if def.Kind != KindObjectType {
+			continue
+		}
+		object, err := c.profileObject(module, def)
+		if err != nil {
+			return nil, err
+		}
+		objects[object.oid] = object
+		ordered = append(ordered, object)
+	}
+
+	profile := &Profile{}
+	tables := make(map[string]*ProfileMetric)
+	var tableOIDs []string
+	for _, object := range ordered {
+		if !isReadable(object.def) || !numericTypes[object.baseType] {
+			continue
+		}
+		entryOID := parentOID(object.oid)
+		entry, isColumn := objects[entryOID]
+		if !isColumn || !isEntry(entry) {
+			profile.Metrics = append(profile.Metrics, ProfileMetric{
+				MIB:    module.Name,
+				Symbol: &ProfileSymbol{OID: object.oid + ".0", Name: object.def.Name},
+			})
+			continue
+		}
+
+		if isIndex(entry, object.def.Name) {
+			// the indexes are reported as tags
+			continue
+		}
+		metric, ok := tables[entryOID]
+		if !ok {
+			tableOID := parentOID(entryOID)
+			tableName := tableOID
+			if table, ok := objects[tableOID]; ok {
+				tableName = table.def.Name
+			}
+			metric = &ProfileMetric{
+				MIB:        module.Name,
+				Table:      &ProfileSymbol{OID: tableOID, Name: tableName},
+				MetricTags: c.indexTags(entry, tableName),
+			}
+			tables[entryOID] = metric
+			tableOIDs = append(tableOIDs, entryOID)
+		}
+		metric.Symbols = append(metric.Symbols, ProfileSymbol{OID: object.oid, Name: object.def.Name})
+	}
+	for _, oid := range tableOIDs {
+		profile.Metrics = append(profile.Metrics, *tables[oid])
+	}
+	return profile, nil
+}
+
+func (c *Compiler) profileObject(module *Module, def *Definition) (*profileObject, error) {
+	oid, err := c.ResolveOID(module, def.Name)
+	if err != nil {
+		return nil, err
+	}
+	object := &profileObject{module: module, def: def, oid: oid}
+	if def.Syntax != nil {
+		object.baseType, object.enum = c.ResolveSyntax(module, def.Syntax)
+	}
+	return object, nil
+}
+
+// indexTags returns the metric tags of the indexes of a table entry. The readable indexes are tagged from their
+// column, the not-accessible integer indexes from their position in the row index. The indexes that can't
+// be tagged, e.g. a not-accessible string following other indexes, are skipped.
+func (c *Compiler) indexTags(entry *profileObject, tableName string) []ProfileMetricTag {
+	indexModule, indexes := entry.module, entry.def.Index
+	if entry.def.Augments != "" {
+		// the augmenting tables are indexed like the table they augment
+		augmentedModule, augmented, err := c.lookup(entry.module, entry.def.Augments)
+		if err != nil {
+			return nil
+		}
+		indexModule, indexes = augmentedModule, augmented.Index
+	}
+
+	var tags []ProfileMetricTag
+	fixedPosition := true
+	for i, name := range indexes {
+		object, err := c.lookupProfileObject(indexModule, name)
+		if err != nil {
+			return tags
+		}
+		tag := ProfileMetricTag{Tag: toSnakeCase(name)}
+		if len(object.enum) > 0 && object.baseType != "BITS" {
+			tag.Mapping = make(map[string]string, len(object.enum))
+			for _, value := range object.enum {
+				tag.Mapping[strconv.Itoa(value.Value)] = value.Name
+			}
+		}
+		integer := numericTypes[object.baseType]
+		switch {
+		case isReadable(object.def):
+			tag.Column = &ProfileSymbol{OID: object.oid, Name: name}
+			if columnTable := c.tableName(object); columnTable != "" && columnTable != tableName {
+				tag.Table = columnTable
+			}
+		case integer && fixedPosition:
+			tag.Index = uint(i + 1)
+		default:
+			fixedPosition = fixedPosition && integer
+			continue
+		}
+		fixedPosition = fixedPosition && integer
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func (c *Compiler) lookupProfileObject(module *Module, name string) (*profileObject, error) {
+	objectModule, def, err := c.lookup(module, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.profileObject(objectModule, def)
+}
+
+// tableName returns the name of the table of a column, looking for it in the module of the column
+func (c *Compiler) tableName(column *profileObject) string {
+	tableOID := parentOID(parentOID(column.oid))
+	for _, name := range column.module.Order {
+		def := column.module.Definitions[name]
+		if def.Kind != KindObjectType {
+			continue
+		}
+		if oid, err := c.ResolveOID(column.module, name); err == nil && oid == tableOID {
+			return name
+		}
+	}
+	return ""
+}
+
+func isReadable(def *Definition) bool {
+	return strings.HasPrefix(def.Access, "read-")
+}
+
+func isEntry(object *profileObject) bool {
+	return len(object.def.Index) > 0 || object.def.Augments != ""
+}
+
+func isIndex(entry *profileObject, name string) bool {
+	for _, index := range entry.def.Index {
+		if index == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parentOID(oid string) string {
+	if i := strings.LastIndexByte(oid, '.'); i >= 0 {
+		return oid[:i]
+	}
+	return ""
+}
+
+// toSnakeCase converts a MIB object name to a tag name, e.g. `hrStorageIndex` to `hr_storage_index`
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '-':
+			b.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestCompiler_Profile(t *testing.T) {
+	compiler, module := loadTestModule(t, "ACME-MIB.txt")
+
+	profile, err := compiler.Profile(module)
+	require.NoError(t, err)
+
+	data, err := yaml.Marshal(profile)
+	require.NoError(t, err)
+	// language=yaml
+	assert.Equal(t, `metrics:
+- MIB: ACME-MIB
+  symbol:
+    OID: 1.3.6.1.4.1.99999.1.1.0
+    name: acmeTemperature
+- MIB: ACME-MIB
+  table:
+    OID: 1.3.6.1.4.1.99999.1.3
+    name: acmeFanTable
+  symbols:
+  - OID: 1.3.6.1.4.1.99999.1.3.1.2
+    name: acmeFanStatus
+  - OID: 1.3.6.1.4.1.99999.1.3.1.3
+    name: acmeFanSpeed
+  metric_tags:
+  - index: 1
+    tag: acme_fan_index
+- MIB: ACME-MIB
+  table:
+    OID: 1.3.6.1.4.1.99999.1.4
+    name: acmeFanStatsTable
+  symbols:
+  - OID: 1.3.6.1.4.1.99999.1.4.1.1
+    name: acmeFanFailures
+  - OID: 1.3.6.1.4.1.99999.1.4.1.2
+    name: acmeFanRedundant
+  metric_tags:
+  - index: 1
+    tag: acme_fan_index
+`, string(data))
+}
+
+func Test_toSnakeCase(t *testing.T) {
+	assert.Equal(t, "hr_storage_index", toSnakeCase("hrStorageIndex"))
+	assert.Equal(t, "if_index", toSnakeCase("ifIndex"))
+	assert.Equal(t, "cpm_cpu_total5min_rev", toSnakeCase("cpmCPUTotal5minRev"))
+	assert.Equal(t, "ent_physical_index", toSnakeCase("entPhysicalIndex"))
+	assert.Equal(t, "mib_2", toSnakeCase("mib-2"))
+}
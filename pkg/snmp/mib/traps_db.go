@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
+)
+
+// TrapDB generates the trap db entries of the `NOTIFICATION-TYPE` and `TRAP-TYPE` definitions of modules:
+// the trap OIDs, the names and descriptions of the traps and of their variables, and the enum mappings
+// of the enumerated variables. The result can be loaded from the snmp.d/traps_db directory.
+func (c *Compiler) TrapDB(modules []*Module) (traps.TrapDBFileContent, error) {
+	trapDB := traps.TrapDBFileContent{
+		Traps:     make(traps.TrapSpec),
+		Variables: make(map[string]traps.VariableMetadata),
+	}
+	for _, module := range modules {
+		for _, name := range module.Order {
+			def := module.Definitions[name]
+			if def.Kind != KindNotificationType && def.Kind != KindTrapType {
+				continue
+			}
+			trapOID, err := c.TrapOID(module, def)
+			if err != nil {
+				return traps.TrapDBFileContent{}, fmt.Errorf("module %s: %s", module.Name, err)
+			}
+			trapDB.Traps[trapOID] = traps.TrapMetadata{
+				Name:        def.Name,
+				MIBName:     module.Name,
+				Description: normalizeDescription(def.Description),
+			}
+
+			for _, object := range def.Objects {
+				oid, variable, err := c.trapVariable(module, object)
+				if err != nil {
+					return traps.TrapDBFileContent{}, fmt.Errorf("module %s: trap %s: %s", module.Name, def.Name, err)
+				}
+				trapDB.Variables[oid] = variable
+			}
+		}
+	}
+	return trapDB, nil
+}
+
+func (c *Compiler) trapVariable(module *Module, name string) (string, traps.VariableMetadata, error) {
+	objectModule, def, err := c.lookup(module, name)
+	if err != nil {
+		return "", traps.VariableMetadata{}, err
+	}
+	oid, err := c.ResolveOID(objectModule, name)
+	if err != nil {
+		return "", traps.VariableMetadata{}, err
+	}
+	variable := traps.VariableMetadata{
+		Name:        def.Name,
+		Description: normalizeDescription(def.Description),
+	}
+	if def.Syntax != nil {
+		baseType, enum := c.ResolveSyntax(objectModule, def.Syntax)
+		if baseType != "BITS" && len(enum) > 0 {
+			variable.Enumeration = make(map[int]string, len(enum))
+			for _, value := range enum {
+				variable.Enumeration[value.Value] = value.Name
+			}
+		}
+	}
+	return oid, variable, nil
+}
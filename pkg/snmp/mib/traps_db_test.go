@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package mib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiler_TrapDB(t *testing.T) {
+	compiler := NewCompiler([]string{"testdata"})
+	modules, err := compiler.LoadFile("testdata/ACME-MIB.txt")
+	require.NoError(t, err)
+	v1Modules, err := compiler.LoadFile("testdata/ACME-V1-MIB.my")
+	require.NoError(t, err)
+
+	trapDB, err := compiler.TrapDB(append(modules, v1Modules...))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(trapDB)
+	require.NoError(t, err)
+	// language=json
+	assert.JSONEq(t, `
+{
+  "traps": {
+    "1.3.6.1.4.1.99999.0.1": {"name": "acmeFanFailure", "mib": "ACME-MIB", "descr": "Sent when a fan fails."},
+    "1.3.6.1.4.1.99998.0.3": {"name": "acmeV1PowerOff", "mib": "ACME-V1-MIB", "descr": "Sent when the power is off."}
+  },
+  "vars": {
+    "1.3.6.1.4.1.99999.1.3.1.2": {"name": "acmeFanStatus", "descr": "The status of the fan.", "enum": {"1": "ok", "2": "degraded", "3": "failed"}},
+    "1.3.6.1.4.1.99999.1.3.1.4": {"name": "acmeFanName", "descr": "The name of the fan."},
+    "1.3.6.1.4.1.99998.1.1": {"name": "acmeV1PowerState", "descr": "The power state.", "enum": {"1": "on", "2": "off"}}
+  }
+}`, string(data))
+}
@@ -34,13 +34,48 @@ type UserV3 struct {
 // Config contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type Config struct {
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Port                  uint16     `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3   `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string   `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string     `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int        `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string     `mapstructure:"namespace" yaml:"namespace"`
+	Rules                 []TrapRule `mapstructure:"rules" yaml:"rules,omitempty"`
+	authoritativeEngineID string     `mapstructure:"-" yaml:"-"`
+}
+
+// TrapRule derives metrics from the traps matching a trap OID: a count of the traps, tagged with the configured
+// tags and with the values of some of their variables, and gauges from numeric variables. It can also update the
+// status of an interface of the device sending the trap.
+type TrapRule struct {
+	// TrapOID is the OID of the matched traps, `*` matches all traps and `<prefix>.*` the traps under an OID prefix
+	TrapOID         string               `mapstructure:"trap_oid" yaml:"trap_oid"`
+	Metric          string               `mapstructure:"metric" yaml:"metric,omitempty"`
+	Tags            []string             `mapstructure:"tags" yaml:"tags,omitempty"`
+	VariableTags    []VariableTag        `mapstructure:"variable_tags" yaml:"variable_tags,omitempty"`
+	Gauges          []VariableGauge      `mapstructure:"gauges" yaml:"gauges,omitempty"`
+	InterfaceStatus *InterfaceStatusRule `mapstructure:"interface_status" yaml:"interface_status,omitempty"`
+}
+
+// VariableTag tags the metrics of a trap with the value of one of its variables
+type VariableTag struct {
+	OID string `mapstructure:"oid" yaml:"oid"`
+	Tag string `mapstructure:"tag" yaml:"tag"`
+}
+
+// VariableGauge reports the value of a numeric variable of a trap as a gauge
+type VariableGauge struct {
+	OID    string `mapstructure:"oid" yaml:"oid"`
+	Metric string `mapstructure:"metric" yaml:"metric"`
+}
+
+// InterfaceStatusRule updates the status of the interface of a device when it sends a trap. The interface index is
+// read from a variable, the status is either fixed or read from variables holding IF-MIB statuses.
+type InterfaceStatusRule struct {
+	IndexOID       string `mapstructure:"index_oid" yaml:"index_oid"`
+	OperStatus     string `mapstructure:"oper_status" yaml:"oper_status,omitempty"`
+	OperStatusOID  string `mapstructure:"oper_status_oid" yaml:"oper_status_oid,omitempty"`
+	AdminStatusOID string `mapstructure:"admin_status_oid" yaml:"admin_status_oid,omitempty"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -89,9 +124,61 @@ func ReadConfig(agentHostname string) (*Config, error) {
 		return nil, fmt.Errorf("invalid snmp_traps_config: %w", err)
 	}
 
+	for i := range c.Rules {
+		if err := c.Rules[i].normalize(); err != nil {
+			return nil, fmt.Errorf("invalid snmp_traps_config rule %d: %w", i, err)
+		}
+	}
+
 	return &c, nil
 }
 
+// normalize validates a rule, sets its defaults and converts its OIDs to the relative form
+func (r *TrapRule) normalize() error {
+	if r.TrapOID == "" {
+		return errors.New("`trap_oid` is required")
+	}
+	r.TrapOID = NormalizeOID(r.TrapOID)
+	if r.Metric == "" {
+		r.Metric = defaultTrapsMetric
+	}
+	for i := range r.VariableTags {
+		if r.VariableTags[i].OID == "" || r.VariableTags[i].Tag == "" {
+			return errors.New("`oid` and `tag` are required for variable tags")
+		}
+		r.VariableTags[i].OID = NormalizeOID(r.VariableTags[i].OID)
+	}
+	for i := range r.Gauges {
+		if r.Gauges[i].OID == "" || r.Gauges[i].Metric == "" {
+			return errors.New("`oid` and `metric` are required for gauges")
+		}
+		r.Gauges[i].OID = NormalizeOID(r.Gauges[i].OID)
+	}
+	if status := r.InterfaceStatus; status != nil {
+		if status.IndexOID == "" {
+			return errors.New("`index_oid` is required for interface status")
+		}
+		if status.OperStatus == "" && status.OperStatusOID == "" && status.AdminStatusOID == "" {
+			return errors.New("one of `oper_status`, `oper_status_oid` or `admin_status_oid` is required for interface status")
+		}
+		if _, ok := interfaceStatuses[strings.ToLower(status.OperStatus)]; status.OperStatus != "" && !ok {
+			return fmt.Errorf("invalid interface `oper_status` %q, expected `up` or `down`", status.OperStatus)
+		}
+		status.IndexOID = NormalizeOID(status.IndexOID)
+		status.OperStatusOID = NormalizeOID(status.OperStatusOID)
+		status.AdminStatusOID = NormalizeOID(status.AdminStatusOID)
+	}
+	return nil
+}
+
+// matches returns whether a rule applies to a trap OID
+func (r *TrapRule) matches(trapOID string) bool {
+	if r.TrapOID == "*" || r.TrapOID == trapOID {
+		return true
+	}
+	return strings.HasSuffix(r.TrapOID, ".*") && strings.HasPrefix(trapOID, strings.TrimSuffix(r.TrapOID, "*"))
+}
+
 // Addr returns the host:port address to listen on.
 func (c *Config) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
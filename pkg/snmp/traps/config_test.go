@@ -150,3 +150,63 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestRulesConfig(t *testing.T) {
+	Configure(t, Config{
+		Rules: []TrapRule{
+			{
+				TrapOID:      ".1.3.6.1.4.1.8072.2.3.0.1",
+				Tags:         []string{"team:network"},
+				VariableTags: []VariableTag{{OID: ".1.3.6.1.4.1.8072.2.3.2.2", Tag: "heartbeat_name"}},
+				Gauges:       []VariableGauge{{OID: ".1.3.6.1.4.1.8072.2.3.2.1", Metric: "heartbeat.rate"}},
+			},
+			{
+				TrapOID:         "1.3.6.1.4.1.2636.4.1.1",
+				Metric:          "juniper.link_down",
+				InterfaceStatus: &InterfaceStatusRule{IndexOID: ".1.3.6.1.4.1.2636.3.1.1", OperStatus: "down"},
+			},
+		},
+	})
+	config, err := ReadConfig("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []TrapRule{
+		{
+			TrapOID:      "1.3.6.1.4.1.8072.2.3.0.1",
+			Metric:       "snmp.traps",
+			Tags:         []string{"team:network"},
+			VariableTags: []VariableTag{{OID: "1.3.6.1.4.1.8072.2.3.2.2", Tag: "heartbeat_name"}},
+			Gauges:       []VariableGauge{{OID: "1.3.6.1.4.1.8072.2.3.2.1", Metric: "heartbeat.rate"}},
+		},
+		{
+			TrapOID:         "1.3.6.1.4.1.2636.4.1.1",
+			Metric:          "juniper.link_down",
+			InterfaceStatus: &InterfaceStatusRule{IndexOID: "1.3.6.1.4.1.2636.3.1.1", OperStatus: "down"},
+		},
+	}, config.Rules)
+}
+
+func TestInvalidRulesConfig(t *testing.T) {
+	for name, rule := range map[string]TrapRule{
+		"missing trap OID":         {Metric: "foo"},
+		"missing gauge metric":     {TrapOID: "1.2.3", Gauges: []VariableGauge{{OID: "1.2.3.4"}}},
+		"missing variable tag":     {TrapOID: "1.2.3", VariableTags: []VariableTag{{OID: "1.2.3.4"}}},
+		"missing interface index":  {TrapOID: "1.2.3", InterfaceStatus: &InterfaceStatusRule{OperStatus: "down"}},
+		"missing interface status": {TrapOID: "1.2.3", InterfaceStatus: &InterfaceStatusRule{IndexOID: "1.2.3.4"}},
+		"invalid interface status": {TrapOID: "1.2.3", InterfaceStatus: &InterfaceStatusRule{IndexOID: "1.2.3.4", OperStatus: "testing"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			Configure(t, Config{Rules: []TrapRule{rule}})
+			_, err := ReadConfig("")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	assert.True(t, (&TrapRule{TrapOID: "*"}).matches("1.3.6.1.4.1.9.9.41.2.0.1"))
+	assert.True(t, (&TrapRule{TrapOID: "1.3.6.1.4.1.9.9.41.2.0.1"}).matches("1.3.6.1.4.1.9.9.41.2.0.1"))
+	assert.True(t, (&TrapRule{TrapOID: "1.3.6.1.4.1.9.*"}).matches("1.3.6.1.4.1.9.9.41.2.0.1"))
+	assert.False(t, (&TrapRule{TrapOID: "1.3.6.1.4.1.9.*"}).matches("1.3.6.1.4.1.99.1"))
+	assert.False(t, (&TrapRule{TrapOID: "1.3.6.1.4.1.9"}).matches("1.3.6.1.4.1.9.9.41.2.0.1"))
+}
@@ -11,4 +11,5 @@ const (
 	defaultNamespace   = "default"
 	packetsChanSize    = 100
 	genericTrapOid     = "1.3.6.1.6.3.1.1.5"
+	defaultTrapsMetric = "snmp.traps"
 )
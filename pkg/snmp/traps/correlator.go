@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	linkDownTrapOID     = "1.3.6.1.6.3.1.1.5.3"
+	linkUpTrapOID       = "1.3.6.1.6.3.1.1.5.4"
+	ifIndexOID          = "1.3.6.1.2.1.2.2.1.1"
+	ifAdminStatusOID    = "1.3.6.1.2.1.2.2.1.7"
+	ifOperStatusOID     = "1.3.6.1.2.1.2.2.1.8"
+	interfaceStatusUp   = int32(1)
+	interfaceStatusDown = int32(2)
+	deviceNamespaceTag  = "device_namespace"
+	snmpDeviceTag       = "snmp_device"
+	snmpTrapOIDTag      = "snmp_trap_oid"
+	snmpTrapNameTag     = "snmp_trap_name"
+	statusUp            = "up"
+	statusDown          = "down"
+)
+
+// interfaceStatuses maps the `oper_status` of the interface status rules to the IF-MIB statuses
+var interfaceStatuses = map[string]int32{
+	statusUp:   interfaceStatusUp,
+	statusDown: interfaceStatusDown,
+}
+
+// linkStatusRules are the IF-MIB linkDown and linkUp trap rules, always applied. The traps carry the ifIndex,
+// ifAdminStatus and ifOperStatus of the interface, the oper status defaults to the one implied by the trap.
+var linkStatusRules = map[string]InterfaceStatusRule{
+	linkDownTrapOID: {IndexOID: ifIndexOID, OperStatus: statusDown, OperStatusOID: ifOperStatusOID, AdminStatusOID: ifAdminStatusOID},
+	linkUpTrapOID:   {IndexOID: ifIndexOID, OperStatus: statusUp, OperStatusOID: ifOperStatusOID, AdminStatusOID: ifAdminStatusOID},
+}
+
+// interfaceStatusPayload is a network devices metadata payload reporting the new status of an interface. It has the
+// same format as the payloads of the SNMP check, the interface is reported with all its known metadata.
+type interfaceStatusPayload struct {
+	Subnet           string                    `json:"subnet"`
+	Namespace        string                    `json:"namespace"`
+	Interfaces       []interfaceStatusMetadata `json:"interfaces"`
+	CollectTimestamp int64                     `json:"collect_timestamp"`
+}
+
+type interfaceStatusMetadata struct {
+	DeviceID    string   `json:"device_id"`
+	IDTags      []string `json:"id_tags"`
+	Index       int32    `json:"index"`
+	Name        string   `json:"name,omitempty"`
+	Alias       string   `json:"alias,omitempty"`
+	Description string   `json:"description,omitempty"`
+	MacAddress  string   `json:"mac_address,omitempty"`
+	AdminStatus int32    `json:"admin_status,omitempty"`
+	OperStatus  int32    `json:"oper_status,omitempty"`
+}
+
+// correlator applies the trap rules to the received traps: it sends the metrics derived from the traps and updates
+// the status of the interfaces of the devices in the device store.
+type correlator struct {
+	rules       []TrapRule
+	namespace   string
+	sender      aggregator.Sender
+	oidResolver OIDResolver
+	timeNow     func() time.Time
+}
+
+func newCorrelator(config *Config, sender aggregator.Sender, oidResolver OIDResolver) *correlator {
+	return &correlator{
+		rules:       config.Rules,
+		namespace:   config.Namespace,
+		sender:      sender,
+		oidResolver: oidResolver,
+		timeNow:     time.Now,
+	}
+}
+
+// process applies the rules matching a trap packet
+func (c *correlator) process(packet *SnmpPacket) {
+	trapOID, variables, err := parseTrap(packet.Content)
+	if err != nil {
+		log.Debugf("unable to correlate trap from %s: %s", packet.Addr.IP, err)
+		return
+	}
+	deviceIP := packet.Addr.IP.String()
+
+	if rule, ok := linkStatusRules[trapOID]; ok {
+		c.updateInterfaceStatus(deviceIP, rule, variables)
+	}
+
+	sent := false
+	for i := range c.rules {
+		rule := &c.rules[i]
+		if !rule.matches(trapOID) {
+			continue
+		}
+		tags := c.buildTags(trapOID, deviceIP, rule, variables)
+		c.sender.Count(rule.Metric, 1, "", tags)
+		for _, gauge := range rule.Gauges {
+			variable, ok := findVariable(variables, gauge.OID)
+			if !ok {
+				continue
+			}
+			value, ok := toFloat64(variable.Value)
+			if !ok {
+				log.Debugf("variable %s of trap %s is not numeric: %v", gauge.OID, trapOID, variable.Value)
+				continue
+			}
+			c.sender.Gauge(gauge.Metric, value, "", tags)
+		}
+		sent = true
+
+		if rule.InterfaceStatus != nil {
+			c.updateInterfaceStatus(deviceIP, *rule.InterfaceStatus, variables)
+		}
+	}
+	if sent {
+		c.sender.Commit()
+	}
+}
+
+func (c *correlator) buildTags(trapOID string, deviceIP string, rule *TrapRule, variables []gosnmp.SnmpPDU) []string {
+	tags := []string{
+		snmpTrapOIDTag + ":" + trapOID,
+		snmpDeviceTag + ":" + deviceIP,
+		deviceNamespaceTag + ":" + c.namespace,
+	}
+	if c.oidResolver != nil {
+		if trapMetadata, err := c.oidResolver.GetTrapMetadata(trapOID); err == nil {
+			tags = append(tags, snmpTrapNameTag+":"+trapMetadata.Name)
+		}
+	}
+	tags = append(tags, rule.Tags...)
+	for _, variableTag := range rule.VariableTags {
+		variable, ok := findVariable(variables, variableTag.OID)
+		if !ok {
+			continue
+		}
+		value := formatValue(variable)
+		if c.oidResolver != nil {
+			if varMetadata, err := c.oidResolver.GetVariableMetadata(trapOID, variableTag.OID); err == nil {
+				if name, ok := resolveEnum(value, varMetadata); ok {
+					value = name
+				}
+			}
+		}
+		tags = append(tags, fmt.Sprintf("%s:%v", variableTag.Tag, value))
+	}
+	return tags
+}
+
+// updateInterfaceStatus updates the status of the interface of a trap in the device store, and reports it
+func (c *correlator) updateInterfaceStatus(deviceIP string, rule InterfaceStatusRule, variables []gosnmp.SnmpPDU) {
+	indexVariable, ok := findVariable(variables, rule.IndexOID)
+	if !ok {
+		log.Debugf("interface index variable %s not found in the trap from %s", rule.IndexOID, deviceIP)
+		return
+	}
+	index, ok := toFloat64(indexVariable.Value)
+	if !ok {
+		log.Debugf("interface index of the trap from %s is not numeric: %v", deviceIP, indexVariable.Value)
+		return
+	}
+	operStatus := interfaceStatuses[strings.ToLower(rule.OperStatus)]
+	if status, ok := findStatus(variables, rule.OperStatusOID); ok {
+		operStatus = status
+	}
+	adminStatus, _ := findStatus(variables, rule.AdminStatusOID)
+
+	device, ok := devicestore.SetInterfaceStatus(c.namespace, deviceIP, int32(index), adminStatus, operStatus)
+	if !ok {
+		log.Debugf("interface %d of device %s is unknown, its status is not updated", int32(index), deviceIP)
+		return
+	}
+	trapsInterfaceStatusUpdates.Add(1)
+
+	networkInterface := device.Interfaces[int32(index)]
+	payload := interfaceStatusPayload{
+		Subnet:    device.Subnet,
+		Namespace: device.Namespace,
+		Interfaces: []interfaceStatusMetadata{{
+			DeviceID:    device.ID,
+			IDTags:      networkInterface.IDTags,
+			Index:       networkInterface.Index,
+			Name:        networkInterface.Name,
+			Alias:       networkInterface.Alias,
+			Description: networkInterface.Description,
+			MacAddress:  networkInterface.MacAddress,
+			AdminStatus: networkInterface.AdminStatus,
+			OperStatus:  networkInterface.OperStatus,
+		}},
+		CollectTimestamp: c.timeNow().Unix(),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("Error marshalling interface status metadata: %s", err)
+		return
+	}
+	c.sender.EventPlatformEvent(string(payloadBytes), epforwarder.EventTypeNetworkDevicesMetadata)
+}
+
+// parseTrap returns the OID and the variables of a trap, without the sysUpTime and snmpTrapOID variables of the
+// v2 and v3 traps
+func parseTrap(packet *gosnmp.SnmpPacket) (string, []gosnmp.SnmpPDU, error) {
+	if packet.Version == gosnmp.Version1 {
+		return formatV1TrapOID(packet), packet.Variables, nil
+	}
+	if len(packet.Variables) < 2 {
+		return "", nil, fmt.Errorf("expected at least 2 variables, got %d", len(packet.Variables))
+	}
+	trapOID, err := parseSnmpTrapOID(packet.Variables[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return trapOID, packet.Variables[2:], nil
+}
+
+// findVariable returns the variable of a trap with an OID, with or without an instance suffix
+func findVariable(variables []gosnmp.SnmpPDU, oid string) (gosnmp.SnmpPDU, bool) {
+	if oid == "" {
+		return gosnmp.SnmpPDU{}, false
+	}
+	for _, variable := range variables {
+		name := NormalizeOID(variable.Name)
+		if name == oid || strings.HasPrefix(name, oid+".") {
+			return variable, true
+		}
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+func findStatus(variables []gosnmp.SnmpPDU, oid string) (int32, bool) {
+	variable, ok := findVariable(variables, oid)
+	if !ok {
+		return 0, false
+	}
+	status, ok := toFloat64(variable.Value)
+	return int32(status), ok
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package traps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/snmp/devicestore"
+)
+
+func newTestCorrelator(t *testing.T, rules []TrapRule) (*correlator, *mocksender.MockSender) {
+	for i := range rules {
+		require.NoError(t, rules[i].normalize())
+	}
+	sender := mocksender.NewMockSender("snmp-traps")
+	sender.SetupAcceptAll()
+	resolver := &MockedResolver{content: TrapDBFileContent{
+		Traps: TrapSpec{
+			"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{Name: "netSnmpExampleHeartbeatNotification", MIBName: "NET-SNMP-EXAMPLES-MIB"},
+		},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.7": VariableMetadata{Name: "ifAdminStatus", Enumeration: map[int]string{1: "up", 2: "down"}},
+		},
+	}}
+	c := newCorrelator(&Config{Namespace: "my-ns", Rules: rules}, sender, resolver)
+	c.timeNow = func() time.Time { return time.Unix(1650000000, 0) }
+	return c, sender
+}
+
+func TestCorrelatorRuleMetrics(t *testing.T) {
+	c, sender := newTestCorrelator(t, []TrapRule{
+		{
+			TrapOID:      "1.3.6.1.4.1.8072.2.3.0.1",
+			Tags:         []string{"team:network"},
+			VariableTags: []VariableTag{{OID: ".1.3.6.1.4.1.8072.2.3.2.2", Tag: "heartbeat_name"}},
+			Gauges:       []VariableGauge{{OID: "1.3.6.1.4.1.8072.2.3.2.1", Metric: "snmp.traps.heartbeat_rate"}},
+		},
+		{TrapOID: "*", Metric: "snmp.traps.all"},
+		{TrapOID: "1.3.6.1.4.1.9.*", Metric: "snmp.traps.cisco"},
+	})
+
+	c.process(createTestPacket())
+
+	expectedTags := []string{
+		"snmp_trap_oid:1.3.6.1.4.1.8072.2.3.0.1",
+		"snmp_device:127.0.0.1",
+		"device_namespace:my-ns",
+		"snmp_trap_name:netSnmpExampleHeartbeatNotification",
+		"team:network",
+		"heartbeat_name:test",
+	}
+	sender.AssertMetric(t, "Count", "snmp.traps", 1, "", expectedTags)
+	sender.AssertMetric(t, "Gauge", "snmp.traps.heartbeat_rate", 1024, "", expectedTags)
+	sender.AssertMetric(t, "Count", "snmp.traps.all", 1, "", expectedTags[:4])
+	sender.AssertNotCalled(t, "Count", "snmp.traps.cisco", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestCorrelatorEnumVariableTag(t *testing.T) {
+	c, sender := newTestCorrelator(t, []TrapRule{
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", VariableTags: []VariableTag{{OID: "1.3.6.1.2.1.2.2.1.7", Tag: "admin_status"}}},
+	})
+
+	c.process(createTestV1GenericPacket())
+
+	sender.AssertMetricTaggedWith(t, "Count", "snmp.traps", []string{"snmp_trap_oid:1.3.6.1.6.3.1.1.5.3", "admin_status:up"})
+}
+
+func TestCorrelatorNoMatchingRule(t *testing.T) {
+	c, sender := newTestCorrelator(t, []TrapRule{{TrapOID: "1.3.6.1.4.1.9.9.41.2.0.1"}})
+
+	c.process(createTestPacket())
+
+	sender.AssertNotCalled(t, "Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNotCalled(t, "Commit")
+}
+
+func TestCorrelatorLinkDownUpdatesInterfaceStatus(t *testing.T) {
+	devicestore.Update(devicestore.Device{
+		ID:        "my-ns:127.0.0.1",
+		IPAddress: "127.0.0.1",
+		Namespace: "my-ns",
+		Subnet:    "127.0.0.0/30",
+		Interfaces: map[int32]devicestore.Interface{
+			2: {Index: 2, IDTags: []string{"interface:eth1"}, Name: "eth1", AdminStatus: 1, OperStatus: 1},
+		},
+	})
+	c, sender := newTestCorrelator(t, nil)
+
+	c.process(createTestV1GenericPacket())
+
+	device, ok := devicestore.Get("my-ns", "127.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, int32(1), device.Interfaces[2].AdminStatus)
+	assert.Equal(t, int32(2), device.Interfaces[2].OperStatus)
+	sender.AssertEventPlatformEvent(t, `{"subnet":"127.0.0.0/30","namespace":"my-ns","interfaces":[{"device_id":"my-ns:127.0.0.1","id_tags":["interface:eth1"],"index":2,"name":"eth1","admin_status":1,"oper_status":2}],"collect_timestamp":1650000000}`, "network-devices-metadata")
+}
+
+func TestCorrelatorVendorInterfaceStatus(t *testing.T) {
+	devicestore.Update(devicestore.Device{
+		ID:        "my-ns:127.0.0.1",
+		IPAddress: "127.0.0.1",
+		Namespace: "my-ns",
+		Interfaces: map[int32]devicestore.Interface{
+			7: {Index: 7, Name: "ge-0/0/7", AdminStatus: 1, OperStatus: 1},
+		},
+	})
+	c, _ := newTestCorrelator(t, []TrapRule{{
+		TrapOID:         "1.3.6.1.4.1.2636.4.1.1",
+		InterfaceStatus: &InterfaceStatusRule{IndexOID: "1.3.6.1.4.1.2636.3.1.1", OperStatus: "down"},
+	}})
+
+	packet := createTestPacket()
+	packet.Content.Variables = []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.2636.4.1.1"},
+		{Name: ".1.3.6.1.4.1.2636.3.1.1.7", Type: gosnmp.Integer, Value: 7},
+	}
+	c.process(packet)
+
+	device, _ := devicestore.Get("my-ns", "127.0.0.1")
+	assert.Equal(t, int32(2), device.Interfaces[7].OperStatus)
+}
+
+func TestFindVariable(t *testing.T) {
+	variables := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.1.12", Value: 12},
+		{Name: ".1.3.6.1.2.1.2.2.1.7", Value: 1},
+	}
+
+	variable, ok := findVariable(variables, "1.3.6.1.2.1.2.2.1.1")
+	assert.True(t, ok)
+	assert.Equal(t, 12, variable.Value)
+	variable, ok = findVariable(variables, "1.3.6.1.2.1.2.2.1.7")
+	assert.True(t, ok)
+	assert.Equal(t, 1, variable.Value)
+	_, ok = findVariable(variables, "1.3.6.1.2.1.2.2.1.1.1")
+	assert.False(t, ok)
+	_, ok = findVariable(variables, "1.3.6.1.2.1.2.2.1.8")
+	assert.False(t, ok)
+}
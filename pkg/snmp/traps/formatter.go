@@ -35,6 +35,9 @@ type trapVariable struct {
 const (
 	sysUpTimeInstanceOID = "1.3.6.1.2.1.1.3.0"
 	snmpTrapOID          = "1.3.6.1.6.3.1.1.4.1.0"
+	// enumSuffix is the suffix of the field holding the name of the value of an enumerated variable, next to the
+	// raw value. SMI descriptors can't contain underscores, so the field can't collide with another variable.
+	enumSuffix = "_enum"
 )
 
 // NewJSONFormatter creates a new JSONFormatter instance with an optional OIDResolver variable.
@@ -76,14 +79,7 @@ func (f JSONFormatter) formatV1Trap(packet *gosnmp.SnmpPacket) map[string]interf
 	enterpriseOid := NormalizeOID(packet.Enterprise)
 	genericTrap := packet.GenericTrap
 	specificTrap := packet.SpecificTrap
-	var trapOID string
-	if genericTrap == 6 {
-		// Vendor-specific trap
-		trapOID = fmt.Sprintf("%s.0.%d", enterpriseOid, specificTrap)
-	} else {
-		// Generic trap
-		trapOID = fmt.Sprintf("%s.%d", genericTrapOid, genericTrap+1)
-	}
+	trapOID := formatV1TrapOID(packet)
 	data["snmpTrapOID"] = trapOID
 	trapMetadata, err := f.oidResolver.GetTrapMetadata(trapOID)
 	if err != nil {
@@ -104,6 +100,9 @@ func (f JSONFormatter) formatV1Trap(packet *gosnmp.SnmpPacket) map[string]interf
 			continue
 		}
 		data[varMetadata.Name] = variable.Value
+		if name, ok := resolveEnum(variable.Value, varMetadata); ok {
+			data[varMetadata.Name+enumSuffix] = name
+		}
 	}
 	return data
 }
@@ -150,10 +149,24 @@ func (f JSONFormatter) formatTrap(packet *gosnmp.SnmpPacket) (map[string]interfa
 			continue
 		}
 		data[varMetadata.Name] = variable.Value
+		if name, ok := resolveEnum(variable.Value, varMetadata); ok {
+			data[varMetadata.Name+enumSuffix] = name
+		}
 	}
 	return data, nil
 }
 
+// formatV1TrapOID returns the OID of a v1 trap, built from its enterprise and generic and specific trap numbers the
+// way the v1 traps are translated to v2 (RFC 3584)
+func formatV1TrapOID(packet *gosnmp.SnmpPacket) string {
+	if packet.GenericTrap == 6 {
+		// Vendor-specific trap
+		return fmt.Sprintf("%s.0.%d", NormalizeOID(packet.Enterprise), packet.SpecificTrap)
+	}
+	// Generic trap
+	return fmt.Sprintf("%s.%d", genericTrapOid, packet.GenericTrap+1)
+}
+
 // NormalizeOID convert an OID from the absolute form ".1.2.3..." to a relative form "1.2.3..."
 func NormalizeOID(value string) string {
 	// OIDs can be formatted as ".1.2.3..." ("absolute form") or "1.2.3..." ("relative form").
@@ -235,6 +248,22 @@ func formatValue(variable gosnmp.SnmpPDU) interface{} {
 	}
 }
 
+// resolveEnum returns the name of the value of an enumerated variable, if it's a known enum value
+func resolveEnum(value interface{}, varMetadata VariableMetadata) (string, bool) {
+	if len(varMetadata.Enumeration) == 0 {
+		return "", false
+	}
+	intValue, ok := value.(int)
+	if !ok {
+		return "", false
+	}
+	name, ok := varMetadata.Enumeration[intValue]
+	if !ok {
+		log.Debugf("unable to find enum value %d of variable %s", intValue, varMetadata.Name)
+	}
+	return name, ok
+}
+
 func formatVersion(packet *gosnmp.SnmpPacket) string {
 	switch packet.Version {
 	case gosnmp.Version3:
@@ -267,3 +267,31 @@ func TestFormatterWithResolverAndTrapV1Generic(t *testing.T) {
 		"snmp_device:127.0.0.1",
 	})
 }
+
+func TestFormatterWithResolverAndEnumVariables(t *testing.T) {
+	resolver := &MockedResolver{content: TrapDBFileContent{
+		Traps: TrapSpec{
+			"1.3.6.1.6.3.1.1.5.3": TrapMetadata{Name: "ifDown", MIBName: "IF-MIB"},
+		},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.1": VariableMetadata{Name: "ifIndex"},
+			"1.3.6.1.2.1.2.2.1.7": VariableMetadata{Name: "ifAdminStatus", Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"}},
+			"1.3.6.1.2.1.2.2.1.8": VariableMetadata{Name: "ifOperStatus", Enumeration: map[int]string{1: "up", 3: "testing"}},
+		},
+	}}
+	formatter, err := NewJSONFormatter(resolver)
+	require.NoError(t, err)
+	data, err := formatter.FormatPacket(createTestV1GenericPacket())
+	require.NoError(t, err)
+	content := make(map[string]interface{})
+	json.Unmarshal(data, &content)
+
+	// the raw values are kept, the names of the enum values are added next to them
+	assert.EqualValues(t, 2, content["ifIndex"])
+	assert.NotContains(t, content, "ifIndex_enum")
+	assert.EqualValues(t, 1, content["ifAdminStatus"])
+	assert.EqualValues(t, "up", content["ifAdminStatus_enum"])
+	// unknown enum values have no name
+	assert.EqualValues(t, 2, content["ifOperStatus"])
+	assert.NotContains(t, content, "ifOperStatus_enum")
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package traps
+
+import (
+	"errors"
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxPacketSize is the maximum size of a UDP datagram
+const maxPacketSize = 65535
+
+// trapListener receives the trap packets, validates them, acknowledges the INFORM requests and forwards the packets
+// to the correlator and to the packets channel.
+//
+// It replaces the gosnmp TrapListener, which acknowledges the INFORM requests by modifying the packet it has already
+// handed over, acknowledges the packets failing validation, and stops listening when an acknowledgement fails.
+type trapListener struct {
+	config     *Config
+	params     *gosnmp.GoSNMP
+	conn       *net.UDPConn
+	packets    PacketsChannel
+	correlator *correlator
+	done       chan struct{}
+}
+
+func startSNMPTrapListener(c *Config, packets PacketsChannel, correlator *correlator) (*trapListener, error) {
+	params, err := c.BuildSNMPParams()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := net.ResolveUDPAddr("udp", c.Addr())
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := &trapListener{
+		config:     c,
+		params:     params,
+		conn:       conn,
+		packets:    packets,
+		correlator: correlator,
+		done:       make(chan struct{}),
+	}
+
+	log.Infof("Start listening for traps on %s", c.Addr())
+	go listener.run()
+	return listener, nil
+}
+
+func (l *trapListener) run() {
+	defer close(l.done)
+
+	for {
+		// the unmarshalled packets reference the buffer, a new one is needed for each packet
+		buf := make([]byte, maxPacketSize)
+		n, addr, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warnf("Error reading trap packet on %s: %s", l.config.Addr(), err)
+			continue
+		}
+		l.handlePacket(buf[:n], addr)
+	}
+}
+
+func (l *trapListener) handlePacket(msg []byte, addr *net.UDPAddr) {
+	p := l.params.UnmarshalTrap(msg, false)
+	if p == nil {
+		// the v3 packets which can't be authenticated or decrypted are rejected by UnmarshalTrap
+		log.Warnf("Invalid packet from %s on listener %s, dropping packet", addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		return
+	}
+	if err := validatePacket(p, l.config); err != nil {
+		log.Warnf("Invalid credentials from %s on listener %s, dropping packet", addr.String(), l.config.Addr())
+		trapsPacketsAuthErrors.Add(1)
+		return
+	}
+	log.Debugf("Packet received from %s on listener %s", addr.String(), l.config.Addr())
+	trapsPackets.Add(1)
+
+	if p.PDUType == gosnmp.InformRequest {
+		l.acknowledgeInform(p, addr)
+	}
+
+	packet := &SnmpPacket{Content: p, Addr: addr}
+	if l.correlator != nil {
+		l.correlator.process(packet)
+	}
+	if l.packets != nil {
+		l.packets <- packet
+	}
+}
+
+// acknowledgeInform sends the response of an INFORM request: the request is sent back with the response PDU type,
+// see https://tools.ietf.org/html/rfc3416#section-4.2.7. The response is a copy, the request is left untouched for
+// the consumers of the packets.
+func (l *trapListener) acknowledgeInform(p *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	response := *p
+	if p.SecurityParameters != nil {
+		response.SecurityParameters = p.SecurityParameters.Copy()
+	}
+	response.PDUType = gosnmp.GetResponse
+	response.Error = gosnmp.NoError
+	response.ErrorIndex = 0
+
+	msg, err := response.MarshalMsg()
+	if err != nil {
+		log.Warnf("Error marshalling the response to the INFORM from %s: %s", addr.String(), err)
+		trapsInformsAckErrors.Add(1)
+		return
+	}
+	if _, err := l.conn.WriteToUDP(msg, addr); err != nil {
+		log.Warnf("Error sending the response to the INFORM from %s: %s", addr.String(), err)
+		trapsInformsAckErrors.Add(1)
+		return
+	}
+	trapsInformsAcked.Add(1)
+}
+
+// Close closes the listener socket and waits for the listener to exit
+func (l *trapListener) Close() {
+	l.conn.Close()
+	<-l.done
+}
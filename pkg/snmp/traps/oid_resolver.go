@@ -145,7 +145,7 @@ func (or *MultiFilesOIDResolver) updateFromReader(reader io.Reader, unmarshalMet
 	if err != nil {
 		return err
 	}
-	var trapData trapDBFileContent
+	var trapData TrapDBFileContent
 	err = unmarshalMethod(fileContent, &trapData)
 	if err != nil {
 		return err
@@ -155,7 +155,7 @@ func (or *MultiFilesOIDResolver) updateFromReader(reader io.Reader, unmarshalMet
 	return nil
 }
 
-func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB trapDBFileContent) {
+func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB TrapDBFileContent) {
 	definedVariables := variableSpec{}
 	for variableOID, variableData := range trapDB.Variables {
 		variableOID := NormalizeOID(variableOID)
@@ -169,6 +169,7 @@ func (or *MultiFilesOIDResolver) updateResolverWithData(trapDB trapDBFileContent
 		}
 		or.traps[trapOID] = TrapMetadata{
 			Name:            trapData.Name,
+			MIBName:         trapData.MIBName,
 			Description:     trapData.Description,
 			variableSpecPtr: definedVariables,
 		}
@@ -16,7 +16,7 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-var dummyTrapDB = trapDBFileContent{
+var dummyTrapDB = TrapDBFileContent{
 	Traps: TrapSpec{
 		"1.3.6.1.6.3.1.1.5.3":      TrapMetadata{Name: "ifDown", MIBName: "IF-MIB"},                                             // v1 Trap
 		"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{Name: "netSnmpExampleHeartbeatNotification", MIBName: "NET-SNMP-EXAMPLES-MIB"}, // v2+
@@ -32,7 +32,7 @@ var dummyTrapDB = trapDBFileContent{
 var resolverWithData = &MockedResolver{content: dummyTrapDB}
 
 type MockedResolver struct {
-	content trapDBFileContent
+	content TrapDBFileContent
 }
 
 func (r MockedResolver) GetTrapMetadata(trapOid string) (TrapMetadata, error) {
@@ -73,7 +73,7 @@ func (m MockedDirEntry) Type() fs.FileMode {
 	return 0
 }
 func TestDecoding(t *testing.T) {
-	trapDBFile := &trapDBFileContent{
+	trapDBFile := &TrapDBFileContent{
 		Traps: TrapSpec{
 			"foo": TrapMetadata{
 				Name:    "xx",
@@ -134,7 +134,7 @@ func TestSortFiles(t *testing.T) {
 
 func TestResolverWithNonStandardOIDs(t *testing.T) {
 	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec)}
-	trapData := trapDBFileContent{
+	trapData := TrapDBFileContent{
 		Traps: TrapSpec{"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{Name: "netSnmpExampleHeartbeat"}},
 		Variables: variableSpec{
 			"1.3.6.1.4.1.8072.2.3.2.1": VariableMetadata{
@@ -156,12 +156,35 @@ func TestResolverWithNonStandardOIDs(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "netSnmpExampleHeartbeat", data.Name)
 }
+
+func TestResolverWithMIBNameAndEnumeration(t *testing.T) {
+	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec)}
+	trapData := TrapDBFileContent{
+		Traps: TrapSpec{"1.3.6.1.6.3.1.1.5.3": TrapMetadata{Name: "linkDown", MIBName: "IF-MIB"}},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.7": VariableMetadata{
+				Name:        "ifAdminStatus",
+				Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"},
+			},
+		},
+	}
+	updateResolverWithIntermediateJSONReader(t, resolver, trapData)
+
+	data, err := resolver.GetTrapMetadata("1.3.6.1.6.3.1.1.5.3")
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB", data.MIBName)
+
+	varData, err := resolver.GetVariableMetadata("1.3.6.1.6.3.1.1.5.3", "1.3.6.1.2.1.2.2.1.7")
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "up", 2: "down", 3: "testing"}, varData.Enumeration)
+}
+
 func TestResolverWithConflictingTrapOID(t *testing.T) {
 	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec)}
-	trapDataA := trapDBFileContent{
+	trapDataA := TrapDBFileContent{
 		Traps: TrapSpec{"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{Name: "foo"}},
 	}
-	trapDataB := trapDBFileContent{
+	trapDataB := TrapDBFileContent{
 		Traps: TrapSpec{"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{Name: "bar"}},
 	}
 	updateResolverWithIntermediateJSONReader(t, resolver, trapDataA)
@@ -173,7 +196,7 @@ func TestResolverWithConflictingTrapOID(t *testing.T) {
 
 func TestResolverWithConflictingVariables(t *testing.T) {
 	resolver := &MultiFilesOIDResolver{traps: make(TrapSpec)}
-	trapDataA := trapDBFileContent{
+	trapDataA := TrapDBFileContent{
 		Traps: TrapSpec{"1.3.6.1.4.1.8072.2.3.0.1": TrapMetadata{}},
 		Variables: variableSpec{
 			"1.3.6.1.4.1.8072.2.3.2.1": VariableMetadata{
@@ -181,7 +204,7 @@ func TestResolverWithConflictingVariables(t *testing.T) {
 			},
 		},
 	}
-	trapDataB := trapDBFileContent{
+	trapDataB := TrapDBFileContent{
 		Traps: TrapSpec{"1.3.6.1.4.1.8072.2.3.0.2": TrapMetadata{}},
 		Variables: variableSpec{
 			"1.3.6.1.4.1.8072.2.3.2.1": VariableMetadata{
@@ -201,7 +224,7 @@ func TestResolverWithConflictingVariables(t *testing.T) {
 	require.Equal(t, "netSnmpExampleHeartbeatRate2", data.Name)
 }
 
-func updateResolverWithIntermediateJSONReader(t *testing.T, oidResolver *MultiFilesOIDResolver, trapData trapDBFileContent) {
+func updateResolverWithIntermediateJSONReader(t *testing.T, oidResolver *MultiFilesOIDResolver, trapData TrapDBFileContent) {
 	data, err := json.Marshal(trapData)
 	require.NoError(t, err)
 
@@ -210,7 +233,7 @@ func updateResolverWithIntermediateJSONReader(t *testing.T, oidResolver *MultiFi
 	require.NoError(t, err)
 }
 
-func updateResolverWithIntermediateYAMLReader(t *testing.T, oidResolver *MultiFilesOIDResolver, trapData trapDBFileContent) {
+func updateResolverWithIntermediateYAMLReader(t *testing.T, oidResolver *MultiFilesOIDResolver, trapData TrapDBFileContent) {
 	data, err := yaml.Marshal(trapData)
 	require.NoError(t, err)
 
@@ -9,6 +9,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/gosnmp/gosnmp"
 )
@@ -26,7 +27,7 @@ type PacketsChannel = chan *SnmpPacket
 type TrapServer struct {
 	Addr     string
 	config   *Config
-	listener *gosnmp.TrapListener
+	listener *trapListener
 	packets  PacketsChannel
 }
 
@@ -36,8 +37,8 @@ var (
 )
 
 // StartServer starts the global trap server.
-func StartServer(agentHostname string) error {
-	server, err := NewTrapServer(agentHostname)
+func StartServer(agentHostname string, sender aggregator.Sender) error {
+	server, err := NewTrapServer(agentHostname, sender)
 	serverInstance = server
 	startError = err
 	return err
@@ -70,8 +71,8 @@ func GetNamespace() string {
 	return defaultNamespace
 }
 
-// NewTrapServer configures and returns a running SNMP traps server.
-func NewTrapServer(agentHostname string) (*TrapServer, error) {
+// NewTrapServer configures and returns a running SNMP traps server. The trap rules send their metrics with the sender.
+func NewTrapServer(agentHostname string, sender aggregator.Sender) (*TrapServer, error) {
 	config, err := ReadConfig(agentHostname)
 	if err != nil {
 		return nil, err
@@ -79,7 +80,18 @@ func NewTrapServer(agentHostname string) (*TrapServer, error) {
 
 	packets := make(PacketsChannel, packetsChanSize)
 
-	listener, err := startSNMPTrapListener(config, packets)
+	var trapCorrelator *correlator
+	if sender != nil {
+		var oidResolver OIDResolver
+		if resolver, err := NewMultiFilesOIDResolver(); err == nil {
+			oidResolver = resolver
+		} else {
+			log.Debugf("unable to load traps database, the trap metrics won't be tagged with the trap names: %s", err)
+		}
+		trapCorrelator = newCorrelator(config, sender, oidResolver)
+	}
+
+	listener, err := startSNMPTrapListener(config, packets, trapCorrelator)
 	if err != nil {
 		return nil, err
 	}
@@ -93,51 +105,6 @@ func NewTrapServer(agentHostname string) (*TrapServer, error) {
 	return server, nil
 }
 
-func startSNMPTrapListener(c *Config, packets PacketsChannel) (*gosnmp.TrapListener, error) {
-	var err error
-	listener := gosnmp.NewTrapListener()
-	listener.Params, err = c.BuildSNMPParams()
-	if err != nil {
-		return nil, err
-	}
-
-	listener.OnNewTrap = func(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
-		if err := validatePacket(p, c); err != nil {
-			log.Warnf("Invalid credentials from %s on listener %s, dropping packet", u.String(), c.Addr())
-			trapsPacketsAuthErrors.Add(1)
-			return
-		}
-		log.Debugf("Packet received from %s on listener %s", u.String(), c.Addr())
-		trapsPackets.Add(1)
-		packets <- &SnmpPacket{Content: p, Addr: u}
-	}
-
-	errors := make(chan error, 1)
-
-	// Start actually listening in the background.
-	go func() {
-		log.Infof("Start listening for traps on %s", c.Addr())
-		err := listener.Listen(c.Addr())
-		if err != nil {
-			errors <- err
-		}
-	}()
-
-	select {
-	// Wait for listener to be started and listening to traps.
-	// See: https://godoc.org/github.com/gosnmp/gosnmp#TrapListener.Listening
-	case <-listener.Listening():
-		break
-	// If the listener failed to start (eg because it couldn't bind to a socket),
-	// we'll get an error here.
-	case err := <-errors:
-		close(errors)
-		return nil, err
-	}
-
-	return listener, nil
-}
-
 // Stop stops the TrapServer.
 func (s *TrapServer) Stop() {
 	stopped := make(chan interface{})
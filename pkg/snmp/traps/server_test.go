@@ -9,10 +9,13 @@ import (
 	"net"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 )
 
 var serverPort = getFreePort()
@@ -29,7 +32,7 @@ func getFreePort() uint16 {
 		if err != nil {
 			continue
 		}
-		listener, err := startSNMPTrapListener(&Config{Port: port}, nil)
+		listener, err := startSNMPTrapListener(&Config{Port: port}, nil, nil)
 		if err != nil {
 			continue
 		}
@@ -56,7 +59,7 @@ func TestServerV1GenericTrap(t *testing.T) {
 	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -72,7 +75,7 @@ func TestServerV1SpecificTrap(t *testing.T) {
 	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -87,7 +90,7 @@ func TestServerV2(t *testing.T) {
 	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -98,11 +101,72 @@ func TestServerV2(t *testing.T) {
 	assertVariables(t, packet)
 }
 
+func TestServerV2Inform(t *testing.T) {
+	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
+	Configure(t, config)
+
+	err := StartServer("dummy_hostname", nil)
+	require.NoError(t, err)
+	defer StopServer()
+
+	// SendTrap waits for the acknowledgement of the INFORM requests
+	response := sendTestV2Inform(t, config, "public")
+	require.NotNil(t, response)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	assert.Equal(t, gosnmp.NoError, response.Error)
+
+	// the packet forwarded to the consumers is the request, not the response
+	packet := receivePacket(t)
+	require.NotNil(t, packet)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertVariables(t, packet)
+}
+
+func TestServerV2InformBadCredentials(t *testing.T) {
+	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
+	Configure(t, config)
+
+	err := StartServer("dummy_hostname", nil)
+	require.NoError(t, err)
+	defer StopServer()
+
+	params, err := config.BuildSNMPParams()
+	require.NoError(t, err)
+	params.Community = "wrong-community"
+	params.Timeout = 200 * time.Millisecond
+	params.Retries = 0
+	require.NoError(t, params.Connect())
+	defer params.Conn.Close()
+
+	trap := NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	_, err = params.SendTrap(trap)
+	// the invalid INFORM requests are not acknowledged
+	assert.Error(t, err)
+	assertNoPacketReceived(t)
+}
+
+func TestServerTrapRules(t *testing.T) {
+	config := Config{Port: serverPort, CommunityStrings: []string{"public"}, Rules: []TrapRule{{TrapOID: "*"}}}
+	Configure(t, config)
+
+	sender := mocksender.NewMockSender("snmp-traps-server")
+	sender.SetupAcceptAll()
+	err := StartServer("dummy_hostname", sender)
+	require.NoError(t, err)
+	defer StopServer()
+
+	sendTestV2Trap(t, config, "public")
+	packet := receivePacket(t)
+	require.NotNil(t, packet)
+	sender.AssertMetricTaggedWith(t, "Count", "snmp.traps", []string{"snmp_trap_oid:1.3.6.1.4.1.8072.2.3.0.1", "snmp_device:127.0.0.1"})
+}
+
 func TestServerV2BadCredentials(t *testing.T) {
 	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -115,7 +179,7 @@ func TestServerV3(t *testing.T) {
 	config := Config{Port: serverPort, Users: []UserV3{userV3}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -137,7 +201,7 @@ func TestServerV3BadCredentials(t *testing.T) {
 	config := Config{Port: serverPort, Users: []UserV3{userV3}}
 	Configure(t, config)
 
-	err := StartServer("dummy_hostname")
+	err := StartServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	defer StopServer()
 
@@ -161,12 +225,12 @@ func TestStartFailure(t *testing.T) {
 	config := Config{Port: port, CommunityStrings: []string{"public"}}
 	Configure(t, config)
 
-	sucessServer, err := NewTrapServer("dummy_hostname")
+	sucessServer, err := NewTrapServer("dummy_hostname", nil)
 	require.NoError(t, err)
 	require.NotNil(t, sucessServer)
 	defer sucessServer.Stop()
 
-	failedServer, err := NewTrapServer("dummy_hostname")
+	failedServer, err := NewTrapServer("dummy_hostname", nil)
 	require.Nil(t, failedServer)
 	require.Error(t, err)
 }
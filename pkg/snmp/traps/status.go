@@ -14,11 +14,18 @@ var (
 	trapsExpvars           = expvar.NewMap("snmp_traps")
 	trapsPackets           = expvar.Int{}
 	trapsPacketsAuthErrors = expvar.Int{}
+	trapsInformsAcked      = expvar.Int{}
+	trapsInformsAckErrors  = expvar.Int{}
+
+	trapsInterfaceStatusUpdates = expvar.Int{}
 )
 
 func init() {
 	trapsExpvars.Set("Packets", &trapsPackets)
 	trapsExpvars.Set("PacketsAuthErrors", &trapsPacketsAuthErrors)
+	trapsExpvars.Set("InformsAcked", &trapsInformsAcked)
+	trapsExpvars.Set("InformsAckErrors", &trapsInformsAckErrors)
+	trapsExpvars.Set("InterfaceStatusUpdates", &trapsInterfaceStatusUpdates)
 }
 
 // GetStatus returns key-value data for use in status reporting of the traps server.
@@ -144,6 +144,25 @@ func sendTestV2Trap(t *testing.T, trapConfig Config, community string) *gosnmp.G
 	return params
 }
 
+func sendTestV2Inform(t *testing.T, trapConfig Config, community string) *gosnmp.SnmpPacket {
+	params, err := trapConfig.BuildSNMPParams()
+	require.NoError(t, err)
+	params.Community = community
+	params.Timeout = 1 * time.Second // Must be non-zero when sending traps.
+	params.Retries = 1               // Must be non-zero when sending traps.
+
+	err = params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	response, err := params.SendTrap(trap)
+	require.NoError(t, err)
+
+	return response
+}
+
 func sendTestV3Trap(t *testing.T, trapConfig Config, securityParams *gosnmp.UsmSecurityParameters) *gosnmp.GoSNMP {
 	params, err := trapConfig.BuildSNMPParams()
 	require.NoError(t, err)
@@ -9,6 +9,8 @@ package traps
 type VariableMetadata struct {
 	Name        string `yaml:"name" json:"name"`
 	Description string `yaml:"descr" json:"descr"`
+	// Enumeration maps the integer values of an enumerated variable to their names
+	Enumeration map[int]string `yaml:"enum,omitempty" json:"enum,omitempty"`
 }
 
 // variableSpec contains the variableMetadata for each known variable of a given trap db file
@@ -28,7 +30,8 @@ type TrapMetadata struct {
 // TrapSpec contains the variableMetadata for each known trap in all trap db files
 type TrapSpec map[string]TrapMetadata
 
-type trapDBFileContent struct {
+// TrapDBFileContent is the content of a trap db file, as loaded by MultiFilesOIDResolver
+type TrapDBFileContent struct {
 	Traps     TrapSpec     `yaml:"traps" json:"traps"`
 	Variables variableSpec `yaml:"vars" json:"vars"`
 }
@@ -16,6 +16,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -54,6 +55,7 @@ func FormatStatus(data []byte) (string, error) {
 	systemProbeStats := stats["systemProbeStats"]
 	processAgentStatus := stats["processAgentStatus"]
 	snmpTrapsStats := stats["snmpTrapsStats"]
+	netflowStats := stats["netflowStats"]
 	title := fmt.Sprintf("Agent (v%s)", stats["version"])
 	stats["title"] = title
 
@@ -85,6 +87,11 @@ func FormatStatus(data []byte) (string, error) {
 			renderStatusTemplate(b, "/snmp-traps.tmpl", snmpTrapsStats)
 		}
 	}
+	netflowFunc := func() {
+		if netflow.IsEnabled() {
+			renderStatusTemplate(b, "/netflow.tmpl", netflowStats)
+		}
+	}
 	autodiscoveryFunc := func() {
 		if config.IsContainerized() {
 			renderAutodiscoveryStats(b, stats["adEnabledFeatures"], stats["adConfigErrors"],
@@ -100,7 +107,7 @@ func FormatStatus(data []byte) (string, error) {
 	} else {
 		renderFuncs = []func(){headerFunc, checkStatsFunc, jmxFetchFunc, forwarderFunc, endpointsFunc,
 			logsAgentFunc, systemProbeFunc, processAgentFunc, traceAgentFunc, aggregatorFunc, dogstatsdFunc,
-			clusterAgentFunc, snmpTrapFunc, autodiscoveryFunc}
+			clusterAgentFunc, snmpTrapFunc, netflowFunc, autodiscoveryFunc}
 	}
 
 	renderAgentSections(renderFuncs)
@@ -133,6 +133,10 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
+			case workloadmeta.KindProcess:
+				tagInfos = append(tagInfos, c.handleProcess(ev)...)
+			case workloadmeta.KindNomadAllocation:
+				tagInfos = append(tagInfos, c.handleNomadAllocation(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -370,6 +374,68 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleNomadAllocation(ev workloadmeta.Event) []*TagInfo {
+	alloc := ev.Entity.(*workloadmeta.NomadAllocation)
+
+	allocTags := utils.NewTagList()
+	allocTags.AddLow("nomad_job", alloc.JobName)
+	allocTags.AddLow("nomad_group", alloc.TaskGroup)
+	allocTags.AddLow("nomad_namespace", alloc.Namespace)
+	allocTags.AddOrchestrator("nomad_alloc_id", alloc.ID)
+
+	tagInfos := make([]*TagInfo, 0, len(alloc.Containers))
+	for _, allocContainer := range alloc.Containers {
+		container, err := c.store.GetContainer(allocContainer.ID)
+		if err != nil {
+			log.Debugf("nomad allocation %q has reference to non-existing container %q", alloc.ID, allocContainer.ID)
+			continue
+		}
+
+		c.registerChild(alloc.EntityID, container.EntityID)
+
+		tags := allocTags.Copy()
+		tags.AddLow("nomad_task", allocContainer.Name)
+
+		low, orch, high, standard := tags.Compute()
+		tagInfos = append(tagInfos, &TagInfo{
+			// nomadSource here is not a mistake. the source is
+			// always from the parent resource.
+			Source:               nomadSource,
+			Entity:               buildTaggerEntityID(container.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		})
+	}
+
+	return tagInfos
+}
+
+func (c *WorkloadMetaCollector) handleProcess(ev workloadmeta.Event) []*TagInfo {
+	process := ev.Entity.(*workloadmeta.Process)
+
+	tags := utils.NewTagList()
+	tags.AddLow("process_name", process.Name)
+	tags.AddLow("process_user", process.User)
+
+	if process.ContainerID != "" {
+		tags.AddHigh("container_id", process.ContainerID)
+	}
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               buildTaggerEntityID(process.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
 func (c *WorkloadMetaCollector) handleGardenContainer(container *workloadmeta.Container) []*TagInfo {
 	return []*TagInfo{
 		{
@@ -581,6 +647,10 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return kubelet.PodUIDToTaggerEntityName(entityID.ID)
 	case workloadmeta.KindECSTask:
 		return fmt.Sprintf("ecs_task://%s", entityID.ID)
+	case workloadmeta.KindProcess:
+		return fmt.Sprintf("process://%s", entityID.ID)
+	case workloadmeta.KindNomadAllocation:
+		return fmt.Sprintf("nomad_allocation://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q; trying %s://%s as tagger entity",
 			entityID.ID, entityID.Kind, entityID.ID, entityID.Kind)
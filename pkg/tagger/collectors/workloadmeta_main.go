@@ -26,6 +26,8 @@ const (
 	podSource       = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesPod)
 	taskSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindECSTask)
 	containerSource = workloadmetaCollectorName + "-" + string(workloadmeta.KindContainer)
+	processSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindProcess)
+	nomadSource     = workloadmetaCollectorName + "-" + string(workloadmeta.KindNomadAllocation)
 )
 
 // CollectorPriorities holds collector priorities
@@ -907,6 +907,148 @@ func TestHandleContainer(t *testing.T) {
 	}
 }
 
+func TestHandleNomadAllocation(t *testing.T) {
+	const containerID = "foobarquux"
+
+	store := workloadmetatesting.NewStore()
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   containerID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "redis-5456bd7a",
+		},
+	})
+
+	collector := &WorkloadMetaCollector{
+		store:    store,
+		children: make(map[string]map[string]struct{}),
+	}
+
+	alloc := &workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   "5456bd7a",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "example.cache[0]",
+			Namespace: "default",
+		},
+		JobID:     "example",
+		JobName:   "example",
+		TaskGroup: "cache",
+		Containers: []workloadmeta.OrchestratorContainer{
+			{
+				ID:   containerID,
+				Name: "redis",
+			},
+			{
+				ID:   "deleted",
+				Name: "exporter",
+			},
+		},
+	}
+
+	expected := []*TagInfo{
+		{
+			Source:       nomadSource,
+			Entity:       fmt.Sprintf("container_id://%s", containerID),
+			HighCardTags: []string{},
+			OrchestratorCardTags: []string{
+				"nomad_alloc_id:5456bd7a",
+			},
+			LowCardTags: []string{
+				"nomad_group:cache",
+				"nomad_job:example",
+				"nomad_namespace:default",
+				"nomad_task:redis",
+			},
+			StandardTags: []string{},
+		},
+	}
+
+	actual := collector.handleNomadAllocation(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: alloc,
+	})
+
+	assertTagInfoListEqual(t, expected, actual)
+}
+
+func TestHandleProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		process  workloadmeta.Process
+		expected []*TagInfo
+	}{
+		{
+			name: "host process",
+			process: workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   "42",
+				},
+				PID:  42,
+				Name: "redis-server",
+				User: "redis",
+			},
+			expected: []*TagInfo{
+				{
+					Source:               processSource,
+					Entity:               "process://42",
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"process_name:redis-server",
+						"process_user:redis",
+					},
+					StandardTags: []string{},
+				},
+			},
+		},
+		{
+			name: "containerized process",
+			process: workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   "43",
+				},
+				PID:         43,
+				Name:        "nginx",
+				ContainerID: "foobarquux",
+			},
+			expected: []*TagInfo{
+				{
+					Source: processSource,
+					Entity: "process://43",
+					HighCardTags: []string{
+						"container_id:foobarquux",
+					},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"process_name:nginx",
+					},
+					StandardTags: []string{},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &WorkloadMetaCollector{}
+
+			actual := collector.handleProcess(workloadmeta.Event{
+				Type:   workloadmeta.EventTypeSet,
+				Entity: &tt.process,
+			})
+
+			assertTagInfoListEqual(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestHandleDelete(t *testing.T) {
 	const (
 		podName       = "datadog-agent-foobar"
@@ -51,6 +51,8 @@ func NewTagger(workloadStore workloadmeta.Store) *Tagger {
 func (t *Tagger) Init(ctx context.Context) error {
 	t.ctx, t.cancel = context.WithCancel(ctx)
 
+	t.tagStore.SetRules(tagstore.LoadRules())
+
 	t.collector = collectors.NewWorkloadMetaCollector(
 		t.ctx,
 		t.workloadStore,
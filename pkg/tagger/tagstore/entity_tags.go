@@ -20,16 +20,19 @@ import (
 type EntityTags struct {
 	entityID           string
 	sourceTags         map[string]sourceTags
+	rules              []*Rule
+	ruleTags           map[string][]string // Tags produced by each rule
 	cacheValid         bool
 	cachedAll          tagset.HashedTags // Low + orchestrator + high
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
 }
 
-func newEntityTags(entityID string) *EntityTags {
+func newEntityTags(entityID string, rules []*Rule) *EntityTags {
 	return &EntityTags{
 		entityID:   entityID,
 		sourceTags: make(map[string]sourceTags),
+		rules:      rules,
 		cacheValid: true,
 	}
 }
@@ -123,6 +126,8 @@ func (e *EntityTags) computeCache() {
 		insertWithPriority(source, tags.highCardTags, collectors.HighCardinality)
 	}
 
+	ruleTags := applyRules(e.rules, tagList)
+
 	tags := append(tagList[collectors.LowCardinality], tagList[collectors.OrchestratorCardinality]...)
 	tags = append(tags, tagList[collectors.HighCardinality]...)
 
@@ -133,6 +138,7 @@ func (e *EntityTags) computeCache() {
 
 	// Write cache
 	e.cacheValid = true
+	e.ruleTags = ruleTags
 	e.cachedAll = cached
 	e.cachedLow = cached.Slice(0, lowCardTags)
 	e.cachedOrchestrator = cached.Slice(0, lowCardTags+orchCardTags)
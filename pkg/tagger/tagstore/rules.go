@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Actions of the tag rules
+const (
+	// RuleActionDerive adds a tag whose value is extracted from the value
+	// of another tag with a regular expression
+	RuleActionDerive = "derive"
+	// RuleActionMap adds a tag whose value depends on the prefix of the
+	// value of another tag
+	RuleActionMap = "map"
+	// RuleActionDrop removes the tags matching a regular expression up to
+	// a cardinality
+	RuleActionDrop = "drop"
+)
+
+// RuleConfig is the configuration of a tag rule, as set in the
+// `tagger_rules` config option.
+type RuleConfig struct {
+	Name        string            `mapstructure:"name"`
+	Action      string            `mapstructure:"action"`
+	Tag         string            `mapstructure:"tag"`
+	Pattern     string            `mapstructure:"pattern"`
+	Target      string            `mapstructure:"target"`
+	Value       string            `mapstructure:"value"`
+	Prefixes    map[string]string `mapstructure:"prefixes"`
+	Cardinality string            `mapstructure:"cardinality"`
+}
+
+// Rule is a compiled tag rule, applied by the store when it computes the tags
+// of an entity.
+type Rule struct {
+	name        string
+	action      string
+	tag         string
+	pattern     *regexp.Regexp
+	target      string
+	value       string
+	prefixes    []rulePrefix
+	cardinality collectors.TagCardinality
+}
+
+type rulePrefix struct {
+	prefix string
+	value  string
+}
+
+// NewRule validates and compiles a rule configuration.
+func NewRule(c RuleConfig) (*Rule, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("`name` is required")
+	}
+
+	r := &Rule{
+		name:   c.Name,
+		action: c.Action,
+		tag:    c.Tag,
+		target: c.Target,
+		value:  c.Value,
+	}
+
+	switch c.Action {
+	case RuleActionDerive:
+		if c.Tag == "" || c.Pattern == "" || c.Target == "" {
+			return nil, fmt.Errorf("`tag`, `pattern` and `target` are required by the %s action", c.Action)
+		}
+	case RuleActionMap:
+		if c.Tag == "" || c.Target == "" || len(c.Prefixes) == 0 {
+			return nil, fmt.Errorf("`tag`, `target` and `prefixes` are required by the %s action", c.Action)
+		}
+	case RuleActionDrop:
+		if c.Pattern == "" {
+			return nil, fmt.Errorf("`pattern` is required by the %s action", c.Action)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported action %q", c.Action)
+	}
+
+	if c.Pattern != "" {
+		pattern, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %s", c.Pattern, err)
+		}
+		r.pattern = pattern
+	}
+
+	if c.Action == RuleActionDerive && r.value == "" {
+		r.value = "$0"
+		if r.pattern.NumSubexp() > 0 {
+			r.value = "$1"
+		}
+	}
+
+	for prefix, value := range c.Prefixes {
+		r.prefixes = append(r.prefixes, rulePrefix{prefix: prefix, value: value})
+	}
+	// the longest matching prefix wins
+	sort.Slice(r.prefixes, func(i, j int) bool {
+		if len(r.prefixes[i].prefix) != len(r.prefixes[j].prefix) {
+			return len(r.prefixes[i].prefix) > len(r.prefixes[j].prefix)
+		}
+		return r.prefixes[i].prefix < r.prefixes[j].prefix
+	})
+
+	// derived tags are low cardinality unless told otherwise, while
+	// dropped tags are removed from every cardinality
+	r.cardinality = collectors.LowCardinality
+	if c.Action == RuleActionDrop {
+		r.cardinality = collectors.HighCardinality
+	}
+	if c.Cardinality != "" {
+		cardinality, err := collectors.StringToTagCardinality(c.Cardinality)
+		if err != nil {
+			return nil, err
+		}
+		r.cardinality = cardinality
+	}
+
+	return r, nil
+}
+
+// LoadRules returns the rules of the `tagger_rules` config option. Invalid
+// rules are logged and skipped.
+func LoadRules() []*Rule {
+	var configs []RuleConfig
+	if err := config.Datadog.UnmarshalKey("tagger_rules", &configs); err != nil {
+		log.Errorf("invalid tagger_rules configuration: %s", err)
+		return nil
+	}
+
+	rules := make([]*Rule, 0, len(configs))
+	names := make(map[string]struct{}, len(configs))
+	for i, c := range configs {
+		rule, err := NewRule(c)
+		if err != nil {
+			log.Errorf("skipping invalid tagger rule %d: %s", i, err)
+			continue
+		}
+		if _, ok := names[rule.name]; ok {
+			log.Errorf("skipping tagger rule %d: duplicated name %q", i, rule.name)
+			continue
+		}
+		names[rule.name] = struct{}{}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// deriveValue returns the value of the tag produced by a derive or map rule
+// from the value of its source tag.
+func (r *Rule) deriveValue(value string) (string, bool) {
+	if r.action == RuleActionMap {
+		for _, p := range r.prefixes {
+			if strings.HasPrefix(value, p.prefix) {
+				return p.value, p.value != ""
+			}
+		}
+		return "", false
+	}
+
+	match := r.pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return "", false
+	}
+	derived := string(r.pattern.ExpandString(nil, r.value, value, match))
+	return derived, derived != ""
+}
+
+// applyRules applies the rules, in order, to the tags of an entity indexed
+// by cardinality. It returns the tags produced by each rule.
+//
+// A derived tag is never less cardinal than the rule and the tag it is
+// derived from, so that high cardinality values do not leak into lower
+// cardinalities. Derived tags do not override the tags already reported
+// for the entity. A drop rule moves the matching tags to the cardinality
+// above its own, or removes them at high cardinality.
+func applyRules(rules []*Rule, tagList map[collectors.TagCardinality][]string) map[string][]string {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	cardinalities := []collectors.TagCardinality{
+		collectors.LowCardinality,
+		collectors.OrchestratorCardinality,
+		collectors.HighCardinality,
+	}
+
+	ruleTags := make(map[string][]string)
+	for _, r := range rules {
+		if r.action == RuleActionDrop {
+			for _, cardinality := range cardinalities {
+				if cardinality > r.cardinality {
+					break
+				}
+
+				kept := tagList[cardinality][:0]
+				for _, t := range tagList[cardinality] {
+					if !r.pattern.MatchString(t) {
+						kept = append(kept, t)
+					} else if r.cardinality < collectors.HighCardinality {
+						tagList[r.cardinality+1] = append(tagList[r.cardinality+1], t)
+					}
+				}
+				tagList[cardinality] = kept
+			}
+			continue
+		}
+
+		existing := make(map[string]struct{})
+		produced := make(map[string]collectors.TagCardinality)
+		var order []string
+		for _, cardinality := range cardinalities {
+			for _, t := range tagList[cardinality] {
+				parts := strings.SplitN(t, ":", 2)
+				existing[parts[0]] = struct{}{}
+				if parts[0] != r.tag || len(parts) != 2 {
+					continue
+				}
+
+				value, ok := r.deriveValue(parts[1])
+				if !ok {
+					continue
+				}
+
+				tag := r.target + ":" + value
+				if _, ok := produced[tag]; ok {
+					continue
+				}
+				order = append(order, tag)
+				produced[tag] = cardinality
+				if r.cardinality > cardinality {
+					produced[tag] = r.cardinality
+				}
+			}
+		}
+
+		if _, ok := existing[r.target]; ok {
+			continue
+		}
+
+		for _, tag := range order {
+			cardinality := produced[tag]
+			tagList[cardinality] = append(tagList[cardinality], tag)
+			ruleTags[r.name] = append(ruleTags[r.name], tag)
+		}
+	}
+
+	return ruleTags
+}
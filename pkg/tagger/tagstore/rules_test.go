@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RuleConfig
+		err    string
+	}{
+		{
+			name:   "missing name",
+			config: RuleConfig{Action: RuleActionDrop, Pattern: "^foo:"},
+			err:    "`name` is required",
+		},
+		{
+			name:   "unknown action",
+			config: RuleConfig{Name: "rule", Action: "rename"},
+			err:    `unsupported action "rename"`,
+		},
+		{
+			name:   "derive without target",
+			config: RuleConfig{Name: "rule", Action: RuleActionDerive, Tag: "image_name", Pattern: ".*"},
+			err:    "`tag`, `pattern` and `target` are required by the derive action",
+		},
+		{
+			name:   "map without prefixes",
+			config: RuleConfig{Name: "rule", Action: RuleActionMap, Tag: "kube_namespace", Target: "cost_center"},
+			err:    "`tag`, `target` and `prefixes` are required by the map action",
+		},
+		{
+			name:   "invalid pattern",
+			config: RuleConfig{Name: "rule", Action: RuleActionDrop, Pattern: "("},
+			err:    "invalid pattern \"(\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:   "invalid cardinality",
+			config: RuleConfig{Name: "rule", Action: RuleActionDrop, Pattern: "^foo:", Cardinality: "none"},
+			err:    "unsupported value none received for tag cardinality",
+		},
+		{
+			name:   "valid",
+			config: RuleConfig{Name: "rule", Action: RuleActionDrop, Pattern: "^foo:", Cardinality: "orchestrator"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewRule(test.config)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("tagger_rules", []map[string]interface{}{
+		{"name": "drop_debug", "action": "drop", "pattern": "^debug:"},
+		{"name": "invalid", "action": "drop"},
+		{"name": "drop_debug", "action": "drop", "pattern": "^trace:"},
+		{"name": "cost_center", "action": "map", "tag": "kube_namespace", "target": "cost_center", "prefixes": map[string]string{"web-": "marketing"}},
+	})
+	defer mockConfig.Set("tagger_rules", nil)
+
+	rules := LoadRules()
+	require.Len(t, rules, 2)
+	assert.Equal(t, "drop_debug", rules[0].name)
+	assert.Equal(t, "^debug:", rules[0].pattern.String())
+	assert.Equal(t, collectors.HighCardinality, rules[0].cardinality)
+	assert.Equal(t, "cost_center", rules[1].name)
+	assert.Equal(t, []rulePrefix{{prefix: "web-", value: "marketing"}}, rules[1].prefixes)
+	assert.Equal(t, collectors.LowCardinality, rules[1].cardinality)
+}
+
+func TestApplyRules(t *testing.T) {
+	configs := []RuleConfig{
+		{
+			Name:    "team_from_image",
+			Action:  RuleActionDerive,
+			Tag:     "image_name",
+			Pattern: `^registry\.example\.com/([^/]+)/`,
+			Target:  "team",
+		},
+		{
+			Name:    "version_from_container",
+			Action:  RuleActionDerive,
+			Tag:     "container_name",
+			Pattern: `-v(\d+)$`,
+			Target:  "major_version",
+			Value:   "v$1",
+		},
+		{
+			Name:     "cost_center",
+			Action:   RuleActionMap,
+			Tag:      "kube_namespace",
+			Target:   "cost_center",
+			Prefixes: map[string]string{"payments": "finance", "payments-eu": "finance-eu", "web": "marketing"},
+		},
+		{
+			Name:        "drop_short_image",
+			Action:      RuleActionDrop,
+			Pattern:     "^short_image:",
+			Cardinality: "low",
+		},
+		{
+			Name:    "drop_debug",
+			Action:  RuleActionDrop,
+			Pattern: "^debug:",
+		},
+		{
+			Name:    "existing_env",
+			Action:  RuleActionDerive,
+			Tag:     "kube_namespace",
+			Pattern: `-(prod|staging)$`,
+			Target:  "env",
+		},
+	}
+
+	var rules []*Rule
+	for _, c := range configs {
+		rule, err := NewRule(c)
+		require.NoError(t, err)
+		rules = append(rules, rule)
+	}
+
+	store := NewTagStore()
+	store.SetRules(rules)
+	store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source: "source",
+			Entity: "test",
+			LowCardTags: []string{
+				"image_name:registry.example.com/checkout/api",
+				"short_image:api",
+				"kube_namespace:payments-eu-prod",
+				"env:production",
+				"debug:true",
+			},
+			HighCardTags: []string{"container_name:api-v2"},
+		},
+	})
+
+	entity, err := store.GetEntity("test")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"image_name:registry.example.com/checkout/api",
+		"kube_namespace:payments-eu-prod",
+		"env:production",
+		"team:checkout",
+		"cost_center:finance-eu",
+	}, entity.LowCardinalityTags)
+	assert.ElementsMatch(t, []string{"short_image:api"}, entity.OrchestratorCardinalityTags)
+	assert.ElementsMatch(t, []string{"container_name:api-v2", "major_version:v2"}, entity.HighCardinalityTags)
+
+	list := store.List()
+	assert.Equal(t, map[string][]string{
+		"team_from_image":        {"team:checkout"},
+		"version_from_container": {"major_version:v2"},
+		"cost_center":            {"cost_center:finance-eu"},
+	}, list.Entities["test"].Rules)
+
+	// removing the rules recomputes the tags of the entities
+	store.SetRules(nil)
+
+	entity, err = store.GetEntity("test")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"image_name:registry.example.com/checkout/api",
+		"short_image:api",
+		"kube_namespace:payments-eu-prod",
+		"env:production",
+		"debug:true",
+	}, entity.LowCardinalityTags)
+	assert.Empty(t, store.List().Entities["test"].Rules)
+}
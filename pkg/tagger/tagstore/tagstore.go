@@ -41,6 +41,8 @@ type TagStore struct {
 
 	subscriber *subscriber.Subscriber
 
+	rules []*Rule
+
 	clock clock.Clock
 }
 
@@ -58,6 +60,19 @@ func newTagStoreWithClock(clock clock.Clock) *TagStore {
 	}
 }
 
+// SetRules sets the rules applied when computing the tags of the entities,
+// and recomputes the tags of the entities already in the store.
+func (s *TagStore) SetRules(rules []*Rule) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.rules = rules
+	for _, storedTags := range s.store {
+		storedTags.rules = rules
+		storedTags.cacheValid = false
+	}
+}
+
 // Run performs background maintenance for TagStore.
 func (s *TagStore) Run(ctx context.Context) {
 	pruneTicker := time.NewTicker(1 * time.Minute)
@@ -121,7 +136,7 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*collectors.TagInfo) {
 		eventType := types.EventTypeModified
 		if !exist {
 			eventType = types.EventTypeAdded
-			storedTags = newEntityTags(info.Entity)
+			storedTags = newEntityTags(info.Entity, s.rules)
 			s.store[info.Entity] = storedTags
 		}
 
@@ -325,6 +340,14 @@ func (s *TagStore) List() response.TaggerListResponse {
 			entity.Tags[source] = tags
 		}
 
+		et.computeCache()
+		if len(et.ruleTags) > 0 {
+			entity.Rules = make(map[string][]string, len(et.ruleTags))
+			for rule, tags := range et.ruleTags {
+				entity.Rules[rule] = append([]string(nil), tags...)
+			}
+		}
+
 		r.Entities[entityID] = entity
 	}
 
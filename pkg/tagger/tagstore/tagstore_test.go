@@ -248,7 +248,7 @@ func TestStoreSuite(t *testing.T) {
 }
 
 func TestGetEntityTags(t *testing.T) {
-	etags := newEntityTags("deadbeef")
+	etags := newEntityTags("deadbeef", nil)
 
 	// Get empty tags and make sure cache is now set to valid
 	tags := etags.get(collectors.HighCardinality)
@@ -301,7 +301,7 @@ func (s *StoreTestSuite) TestGetExpiredTags() {
 }
 
 func TestDuplicateSourceTags(t *testing.T) {
-	etags := newEntityTags("deadbeef")
+	etags := newEntityTags("deadbeef", nil)
 
 	// Get empty tags and make sure cache is now set to valid
 	tags := etags.get(collectors.HighCardinality)
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package nomad implements a client of the HTTP API of the local Nomad agent.
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	// DefaultAgentURL is the default address of the HTTP API of the Nomad agent.
+	DefaultAgentURL = "http://127.0.0.1:4646"
+
+	agentSelfPath       = "/agent/self"
+	nodeAllocationsPath = "/node/%s/allocations"
+
+	tokenHeader = "X-Nomad-Token"
+	timeout     = 10 * time.Second
+)
+
+// Client represents a client of the HTTP API of a Nomad agent.
+type Client struct {
+	agentURL string
+	token    string
+}
+
+// NewClient creates a new client for the specified Nomad agent.
+func NewClient(agentURL, token string) *Client {
+	return &Client{
+		agentURL: agentURL,
+		token:    token,
+	}
+}
+
+// NewClientFromConfig creates a new client for the Nomad agent configured
+// with `nomad_agent_url` and `nomad_token`, falling back to the NOMAD_ADDR
+// and NOMAD_TOKEN environment variables used by the Nomad CLI.
+func NewClientFromConfig() *Client {
+	agentURL := config.Datadog.GetString("nomad_agent_url")
+	if agentURL == "" {
+		agentURL = os.Getenv("NOMAD_ADDR")
+	}
+	if agentURL == "" {
+		agentURL = DefaultAgentURL
+	}
+
+	token := config.Datadog.GetString("nomad_token")
+	if token == "" {
+		token = os.Getenv("NOMAD_TOKEN")
+	}
+
+	return NewClient(agentURL, token)
+}
+
+// GetNodeID returns the ID of the client node of the Nomad agent.
+func (c *Client) GetNodeID(ctx context.Context) (string, error) {
+	var self AgentSelf
+	if err := c.get(ctx, agentSelfPath, &self); err != nil {
+		return "", err
+	}
+	if self.Stats.Client.NodeID == "" {
+		return "", fmt.Errorf("the Nomad agent at %s is not a client", c.agentURL)
+	}
+	return self.Stats.Client.NodeID, nil
+}
+
+// GetNodeAllocations returns the allocations of a client node, with their jobs.
+func (c *Client) GetNodeAllocations(ctx context.Context, nodeID string) ([]Allocation, error) {
+	var allocations []Allocation
+	if err := c.get(ctx, fmt.Sprintf(nodeAllocationsPath, url.PathEscape(nodeID)), &allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+func (c *Client) makeURL(requestPath string) (string, error) {
+	u, err := url.Parse(c.agentURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join("/v1", requestPath)
+	return u.String(), nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	client := http.Client{Timeout: timeout}
+	url, err := c.makeURL(path)
+	if err != nil {
+		return fmt.Errorf("Error constructing Nomad API request URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create new request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set(tokenHeader, c.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected HTTP status code in Nomad API reply to %s: %d", path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("Failed to decode Nomad API JSON payload to type %s: %s", reflect.TypeOf(v), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNodeID = "fb2170a8-257d-3c64-b14d-bc06cc94e34c"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/agent/self", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/agent_self.json")
+	})
+	mux.HandleFunc("/v1/node/"+testNodeID+"/allocations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.ServeFile(w, r, "./testdata/allocations.json")
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGetNodeID(t *testing.T) {
+	ts := newTestServer(t)
+
+	nodeID, err := NewClient(ts.URL, "").GetNodeID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testNodeID, nodeID)
+}
+
+func TestGetNodeAllocations(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := NewClient(ts.URL, "").GetNodeAllocations(ctx, testNodeID)
+	assert.EqualError(t, err, "Unexpected HTTP status code in Nomad API reply to /node/"+testNodeID+"/allocations: 403")
+
+	allocations, err := NewClient(ts.URL, "secret").GetNodeAllocations(ctx, testNodeID)
+	require.NoError(t, err)
+	require.Len(t, allocations, 2)
+
+	alloc := allocations[0]
+	assert.Equal(t, "5456bd7a-9fc0-c0dd-6131-cbee77f57577", alloc.ID)
+	assert.Equal(t, "example.cache[0]", alloc.Name)
+	assert.Equal(t, "default", alloc.Namespace)
+	assert.Equal(t, "example", alloc.JobID)
+	assert.Equal(t, AllocClientStatusRunning, alloc.ClientStatus)
+	assert.Equal(t, "running", alloc.TaskStates["redis"].State)
+
+	group := alloc.GetTaskGroup()
+	require.NotNil(t, group)
+	assert.Equal(t, "cache", group.Name)
+	assert.Len(t, group.Tasks, 2)
+	assert.Equal(t, Service{
+		Name:     "redis-cache",
+		TaskName: "redis",
+		Meta: map[string]string{
+			"datadog_ad_check_names":  `["redisdb"]`,
+			"datadog_ad_init_configs": `[{}]`,
+			"datadog_ad_instances":    `[{"host": "%%host%%", "port": "6379"}]`,
+		},
+	}, group.Services[0])
+	assert.Equal(t, map[string]string{"team": "cache"}, group.Tasks[1].Services[0].Meta)
+
+	assert.Equal(t, "complete", allocations[1].ClientStatus)
+	assert.Nil(t, (&Allocation{TaskGroup: "cache"}).GetTaskGroup())
+}
+
+func TestGetNodeIDNotClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"stats": {"nomad": {"leader": "true"}}}`)) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	_, err := NewClient(ts.URL, "").GetNodeID(context.Background())
+	assert.EqualError(t, err, "the Nomad agent at "+ts.URL+" is not a client")
+}
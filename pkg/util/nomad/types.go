@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package nomad
+
+// Allocation client statuses
+const (
+	AllocClientStatusPending = "pending"
+	AllocClientStatusRunning = "running"
+)
+
+// AgentSelf represents the response of the /v1/agent/self endpoint, only the
+// fields used by the agent are decoded.
+type AgentSelf struct {
+	Stats struct {
+		Client struct {
+			NodeID string `json:"node_id"`
+		} `json:"client"`
+	} `json:"stats"`
+}
+
+// Allocation represents an allocation of the Nomad client node.
+type Allocation struct {
+	ID           string               `json:"ID"`
+	Name         string               `json:"Name"`
+	Namespace    string               `json:"Namespace"`
+	NodeID       string               `json:"NodeID"`
+	JobID        string               `json:"JobID"`
+	Job          *Job                 `json:"Job"`
+	TaskGroup    string               `json:"TaskGroup"`
+	ClientStatus string               `json:"ClientStatus"`
+	TaskStates   map[string]TaskState `json:"TaskStates"`
+}
+
+// Job represents the job of an allocation.
+type Job struct {
+	ID         string      `json:"ID"`
+	Name       string      `json:"Name"`
+	Namespace  string      `json:"Namespace"`
+	TaskGroups []TaskGroup `json:"TaskGroups"`
+}
+
+// TaskGroup represents a task group of a job.
+type TaskGroup struct {
+	Name     string    `json:"Name"`
+	Tasks    []Task    `json:"Tasks"`
+	Services []Service `json:"Services"`
+}
+
+// Task represents a task of a task group.
+type Task struct {
+	Name     string    `json:"Name"`
+	Driver   string    `json:"Driver"`
+	Services []Service `json:"Services"`
+}
+
+// Service represents a service registered by a task group or a task. The
+// services of a task group are attached to a task when TaskName is set.
+type Service struct {
+	Name     string            `json:"Name"`
+	TaskName string            `json:"TaskName"`
+	Meta     map[string]string `json:"Meta"`
+}
+
+// TaskState represents the state of a task of an allocation.
+type TaskState struct {
+	State string `json:"State"`
+}
+
+// GetTaskGroup returns the task group of an allocation, or nil if the job of
+// the allocation isn't known.
+func (a *Allocation) GetTaskGroup() *TaskGroup {
+	if a.Job == nil {
+		return nil
+	}
+	for i := range a.Job.TaskGroups {
+		if a.Job.TaskGroups[i].Name == a.TaskGroup {
+			return &a.Job.TaskGroups[i]
+		}
+	}
+	return nil
+}
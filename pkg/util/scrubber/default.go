@@ -76,6 +76,16 @@ func AddDefaultReplacers(scrubber *Scrubber) {
 		Hints: []string{"community_strings"},
 		Repl:  []byte(`$1 ********`),
 	}
+	forwarderStorageKeyReplacer := Replacer{
+		Regex: matchYAMLKey(`(forwarder_storage_encryption_key)`),
+		Hints: []string{"forwarder_storage_encryption_key"},
+		Repl:  []byte(`$1 ********`),
+	}
+	forwarderStorageKeysMultilineReplacer := Replacer{
+		Regex: matchYAMLKeyWithListValue("(forwarder_storage_encryption_previous_keys)"),
+		Hints: []string{"forwarder_storage_encryption_previous_keys"},
+		Repl:  []byte(`$1 ********`),
+	}
 	certReplacer := Replacer{
 		Regex: matchCert(),
 		Hints: []string{"BEGIN"},
@@ -90,6 +100,8 @@ func AddDefaultReplacers(scrubber *Scrubber) {
 	scrubber.AddReplacer(SingleLine, tokenReplacer)
 	scrubber.AddReplacer(SingleLine, snmpReplacer)
 	scrubber.AddReplacer(MultiLine, snmpMultilineReplacer)
+	scrubber.AddReplacer(SingleLine, forwarderStorageKeyReplacer)
+	scrubber.AddReplacer(MultiLine, forwarderStorageKeysMultilineReplacer)
 	scrubber.AddReplacer(MultiLine, certReplacer)
 }
 
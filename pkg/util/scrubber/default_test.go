@@ -346,6 +346,26 @@ other_config_with_list: [abc]
 		`privacy_key: ********`)
 }
 
+func TestForwarderStorageEncryptionKeys(t *testing.T) {
+	assertClean(t,
+		`forwarder_storage_encryption_key: "my-encryption-key"`,
+		`forwarder_storage_encryption_key: ********`)
+	assertClean(t,
+		`forwarder_storage_encryption_key_file: /etc/datadog-agent/forwarder_storage.key`,
+		`forwarder_storage_encryption_key_file: /etc/datadog-agent/forwarder_storage.key`)
+	assertClean(t,
+		`
+forwarder_storage_encryption_previous_keys:
+  - 'key1'
+  - 'key2'
+other_config: 1
+`,
+		`
+forwarder_storage_encryption_previous_keys: ********
+other_config: 1
+`)
+}
+
 func TestYamlConfig(t *testing.T) {
 	contents := `foobar: baz`
 	cleaned, err := ScrubBytes([]byte(contents))
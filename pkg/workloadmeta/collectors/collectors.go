@@ -17,5 +17,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/ecsfargate"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubelet"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubemetadata"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/nomad"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/podman"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/process"
 )
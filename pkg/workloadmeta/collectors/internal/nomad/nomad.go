@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package nomad
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/nomad"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/util"
+)
+
+const (
+	collectorID   = "nomad"
+	componentName = "workloadmeta-nomad"
+	expireFreq    = 15 * time.Second
+
+	// The Nomad docker driver sets these labels on the containers of the
+	// tasks, and all the drivers set the environment variables.
+	allocIDLabel  = "com.hashicorp.nomad.alloc_id"
+	taskNameLabel = "com.hashicorp.nomad.task_name"
+	allocIDEnv    = "NOMAD_ALLOC_ID"
+	taskNameEnv   = "NOMAD_TASK_NAME"
+)
+
+type nomadClient interface {
+	GetNodeID(ctx context.Context) (string, error)
+	GetNodeAllocations(ctx context.Context, nodeID string) ([]nomad.Allocation, error)
+}
+
+type collector struct {
+	client nomadClient
+	store  workloadmeta.Store
+	expire *util.Expire
+	nodeID string
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.IsFeaturePresent(config.Nomad) {
+		return errors.NewDisabled(componentName, "Agent is not running on a Nomad client")
+	}
+
+	c.client = nomad.NewClientFromConfig()
+	c.store = store
+	c.expire = util.NewExpire(expireFreq)
+
+	return nil
+}
+
+func (c *collector) Pull(ctx context.Context) error {
+	// the node ID is fetched at the first successful pull, the Nomad agent
+	// may start after the Datadog agent
+	if c.nodeID == "" {
+		nodeID, err := c.client.GetNodeID(ctx)
+		if err != nil {
+			return err
+		}
+		c.nodeID = nodeID
+	}
+
+	allocations, err := c.client.GetNodeAllocations(ctx, c.nodeID)
+	if err != nil {
+		return err
+	}
+
+	// the containers of the allocations are collected by the container
+	// runtime collectors, the allocations are parsed at each pull to pick
+	// the containers that started since the previous one.
+	containers, err := c.store.ListContainers()
+	if err != nil {
+		return err
+	}
+
+	events := c.parseAllocations(allocations, indexContainers(containers))
+
+	for _, expired := range c.expire.ComputeExpires() {
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceNodeOrchestrator,
+			Entity: expired,
+		})
+	}
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+// taskKey identifies the container of a task of an allocation
+type taskKey struct {
+	allocID  string
+	taskName string
+}
+
+// indexContainers returns the IDs of the containers of the Nomad tasks, from
+// their labels or their environment variables.
+func indexContainers(containers []*workloadmeta.Container) map[taskKey]string {
+	index := make(map[taskKey]string)
+
+	for _, container := range containers {
+		key := taskKey{
+			allocID:  container.Labels[allocIDLabel],
+			taskName: container.Labels[taskNameLabel],
+		}
+		if key.allocID == "" || key.taskName == "" {
+			key = taskKey{
+				allocID:  container.EnvVars[allocIDEnv],
+				taskName: container.EnvVars[taskNameEnv],
+			}
+		}
+		if key.allocID == "" || key.taskName == "" {
+			continue
+		}
+
+		// a restarted task has a new container, prefer the running one
+		if _, found := index[key]; found && !container.State.Running {
+			continue
+		}
+		index[key] = container.ID
+	}
+
+	return index
+}
+
+func (c *collector) parseAllocations(allocations []nomad.Allocation, containers map[taskKey]string) []workloadmeta.CollectorEvent {
+	events := []workloadmeta.CollectorEvent{}
+
+	now := time.Now()
+
+	for i := range allocations {
+		alloc := &allocations[i]
+
+		// We only want to collect the allocations that are running or
+		// starting their tasks.
+		if alloc.ClientStatus != nomad.AllocClientStatusRunning && alloc.ClientStatus != nomad.AllocClientStatusPending {
+			continue
+		}
+
+		entityID := workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   alloc.ID,
+		}
+
+		c.expire.Update(entityID, now)
+
+		entity := &workloadmeta.NomadAllocation{
+			EntityID: entityID,
+			EntityMeta: workloadmeta.EntityMeta{
+				Name:      alloc.Name,
+				Namespace: alloc.Namespace,
+			},
+			JobID:     alloc.JobID,
+			JobName:   alloc.JobID,
+			TaskGroup: alloc.TaskGroup,
+		}
+
+		if alloc.Job != nil && alloc.Job.Name != "" {
+			entity.JobName = alloc.Job.Name
+		}
+
+		if group := alloc.GetTaskGroup(); group != nil {
+			entity.Containers, entity.Services = parseTaskGroup(alloc.ID, group, containers)
+		} else {
+			log.Debugf("job of Nomad allocation %q not found", alloc.ID)
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Source: workloadmeta.SourceNodeOrchestrator,
+			Type:   workloadmeta.EventTypeSet,
+			Entity: entity,
+		})
+	}
+
+	return events
+}
+
+func parseTaskGroup(allocID string, group *nomad.TaskGroup, containers map[taskKey]string) ([]workloadmeta.OrchestratorContainer, []workloadmeta.NomadService) {
+	var (
+		allocContainers []workloadmeta.OrchestratorContainer
+		services        []workloadmeta.NomadService
+	)
+
+	for _, service := range group.Services {
+		services = append(services, workloadmeta.NomadService{
+			Name: service.Name,
+			Task: service.TaskName,
+			Meta: service.Meta,
+		})
+	}
+
+	for _, task := range group.Tasks {
+		if containerID, found := containers[taskKey{allocID: allocID, taskName: task.Name}]; found {
+			allocContainers = append(allocContainers, workloadmeta.OrchestratorContainer{
+				ID:   containerID,
+				Name: task.Name,
+			})
+		}
+
+		for _, service := range task.Services {
+			services = append(services, workloadmeta.NomadService{
+				Name: service.Name,
+				Task: task.Name,
+				Meta: service.Meta,
+			})
+		}
+	}
+
+	sort.Slice(allocContainers, func(i, j int) bool {
+		return allocContainers[i].Name < allocContainers[j].Name
+	})
+
+	return allocContainers, services
+}
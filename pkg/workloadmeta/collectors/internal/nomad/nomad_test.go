@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/nomad"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/util"
+	workloadmetatesting "github.com/DataDog/datadog-agent/pkg/workloadmeta/testing"
+)
+
+type fakeWorkloadmetaStore struct {
+	*workloadmetatesting.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeNomadClient struct {
+	nodeIDErr   error
+	allocations []nomad.Allocation
+}
+
+func (c *fakeNomadClient) GetNodeID(context.Context) (string, error) {
+	if c.nodeIDErr != nil {
+		return "", c.nodeIDErr
+	}
+	return "node-1", nil
+}
+
+func (c *fakeNomadClient) GetNodeAllocations(_ context.Context, nodeID string) ([]nomad.Allocation, error) {
+	if nodeID != "node-1" {
+		return nil, errors.New("unknown node")
+	}
+	return c.allocations, nil
+}
+
+func TestPull(t *testing.T) {
+	store := &fakeWorkloadmetaStore{Store: workloadmetatesting.NewStore()}
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "redis-old"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Labels: map[string]string{allocIDLabel: "alloc-1", taskNameLabel: "redis"},
+		},
+	})
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "redis"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Labels: map[string]string{allocIDLabel: "alloc-1", taskNameLabel: "redis"},
+		},
+		State: workloadmeta.ContainerState{Running: true},
+	})
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "exporter"},
+		EnvVars:  map[string]string{allocIDEnv: "alloc-1", taskNameEnv: "exporter"},
+		State:    workloadmeta.ContainerState{Running: true},
+	})
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "not-nomad"},
+	})
+
+	client := &fakeNomadClient{
+		nodeIDErr: errors.New("connection refused"),
+		allocations: []nomad.Allocation{
+			{
+				ID:        "alloc-1",
+				Name:      "example.cache[0]",
+				Namespace: "default",
+				JobID:     "example",
+				Job: &nomad.Job{
+					ID:   "example",
+					Name: "example",
+					TaskGroups: []nomad.TaskGroup{
+						{Name: "web"},
+						{
+							Name: "cache",
+							Services: []nomad.Service{
+								{Name: "redis-cache", TaskName: "redis", Meta: map[string]string{"datadog_ad_check_names": `["redisdb"]`}},
+							},
+							Tasks: []nomad.Task{
+								{Name: "redis"},
+								{Name: "exporter", Services: []nomad.Service{{Name: "redis-exporter"}}},
+								{Name: "log-shipper"},
+							},
+						},
+					},
+				},
+				TaskGroup:    "cache",
+				ClientStatus: nomad.AllocClientStatusRunning,
+			},
+			{
+				ID:           "alloc-2",
+				Name:         "batch.run[0]",
+				JobID:        "batch",
+				TaskGroup:    "run",
+				ClientStatus: "complete",
+			},
+		},
+	}
+
+	c := &collector{
+		client: client,
+		store:  store,
+		expire: util.NewExpire(expireFreq),
+	}
+
+	// the node ID is fetched until the Nomad agent answers
+	err := c.Pull(context.Background())
+	assert.EqualError(t, err, "connection refused")
+	assert.Empty(t, store.notifiedEvents)
+
+	client.nodeIDErr = nil
+	err = c.Pull(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", c.nodeID)
+
+	expected := []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceNodeOrchestrator,
+			Entity: &workloadmeta.NomadAllocation{
+				EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindNomadAllocation, ID: "alloc-1"},
+				EntityMeta: workloadmeta.EntityMeta{
+					Name:      "example.cache[0]",
+					Namespace: "default",
+				},
+				JobID:     "example",
+				JobName:   "example",
+				TaskGroup: "cache",
+				Containers: []workloadmeta.OrchestratorContainer{
+					{ID: "exporter", Name: "exporter"},
+					{ID: "redis", Name: "redis"},
+				},
+				Services: []workloadmeta.NomadService{
+					{Name: "redis-cache", Task: "redis", Meta: map[string]string{"datadog_ad_check_names": `["redisdb"]`}},
+					{Name: "redis-exporter", Task: "exporter"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, store.notifiedEvents)
+
+	// the allocations that are no longer running expire
+	store.notifiedEvents = nil
+	client.allocations = nil
+	c.expire = util.NewExpire(0)
+	c.expire.Update(workloadmeta.EntityID{Kind: workloadmeta.KindNomadAllocation, ID: "alloc-1"}, time.Now().Add(-time.Second))
+
+	err = c.Pull(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceNodeOrchestrator,
+			Entity: workloadmeta.EntityID{Kind: workloadmeta.KindNomadAllocation, ID: "alloc-1"},
+		},
+	}, store.notifiedEvents)
+}
@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package process
+
+import (
+	"context"
+	"os/user"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics/provider"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+
+	// containerIDCacheValidity is the cache validity of the container ID of a process
+	containerIDCacheValidity = time.Minute
+)
+
+// containerIDForPID returns the container ID of a process, tests override it
+var containerIDForPID = func(pid int) (string, error) {
+	return provider.GetProvider().GetMetaCollector().GetContainerIDForPID(pid, containerIDCacheValidity)
+}
+
+// getListeningSockets returns the TCP sockets the processes listen on, tests override it
+var getListeningSockets = procutil.ListeningSockets
+
+// lookupUsername returns the name of a user, tests override it
+var lookupUsername = func(uid string) (string, error) {
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+type collector struct {
+	store          workloadmeta.Store
+	probe          procutil.Probe
+	interval       time.Duration
+	lastCollection time.Time
+	seen           map[workloadmeta.EntityID]struct{}
+	usernames      map[int32]string
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen:      make(map[workloadmeta.EntityID]struct{}),
+			usernames: make(map[int32]string),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.process_collector.enabled") {
+		return errors.NewDisabled(componentName, "workloadmeta.process_collector.enabled is false")
+	}
+
+	c.store = store
+	c.probe = procutil.NewProcessProbe()
+	c.interval = config.Datadog.GetDuration("workloadmeta.process_collector.interval")
+
+	return nil
+}
+
+// Pull lists the processes of the host at most once per collection interval,
+// and notifies the store of the processes that started or exited.
+func (c *collector) Pull(_ context.Context) error {
+	now := time.Now()
+	if now.Sub(c.lastCollection) < c.interval {
+		return nil
+	}
+	c.lastCollection = now
+
+	procs, err := c.probe.ProcessesByPID(now, false)
+	if err != nil {
+		return err
+	}
+
+	c.store.Notify(c.buildEvents(procs))
+
+	return nil
+}
+
+func (c *collector) buildEvents(procs map[int32]*procutil.Process) []workloadmeta.CollectorEvent {
+	pids := make([]int32, 0, len(procs))
+	for pid := range procs {
+		pids = append(pids, pid)
+	}
+	sockets := getListeningSockets(pids)
+
+	events := make([]workloadmeta.CollectorEvent, 0, len(procs))
+	seen := make(map[workloadmeta.EntityID]struct{}, len(procs))
+	for pid, proc := range procs {
+		entity := c.buildProcess(proc, sockets[pid])
+		seen[entity.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: entity,
+		})
+	}
+
+	for id := range c.seen {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{EntityID: id},
+		})
+	}
+	c.seen = seen
+
+	return events
+}
+
+func (c *collector) buildProcess(proc *procutil.Process, sockets []procutil.ListeningSocket) *workloadmeta.Process {
+	pid := int(proc.Pid)
+
+	process := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(pid),
+		},
+		PID:     pid,
+		PPID:    int(proc.Ppid),
+		Name:    proc.Name,
+		Cmdline: proc.Cmdline,
+		Exe:     proc.Exe,
+		User:    c.username(proc),
+	}
+
+	if proc.Stats != nil && proc.Stats.CreateTime > 0 {
+		process.CreatedAt = time.Unix(0, proc.Stats.CreateTime*int64(time.Millisecond))
+	}
+
+	containerID, err := containerIDForPID(pid)
+	if err != nil {
+		log.Debugf("cannot get the container ID of process %d: %s", pid, err)
+	}
+	process.ContainerID = containerID
+
+	for _, socket := range sockets {
+		process.Ports = append(process.Ports, workloadmeta.ProcessPort{
+			IP:   socket.IP.String(),
+			Port: socket.Port,
+		})
+	}
+	sort.Slice(process.Ports, func(i, j int) bool {
+		if process.Ports[i].Port != process.Ports[j].Port {
+			return process.Ports[i].Port < process.Ports[j].Port
+		}
+		return process.Ports[i].IP < process.Ports[j].IP
+	})
+
+	return process
+}
+
+// username returns the name of the user running a process.  The names are
+// cached by UID since they rarely change during the lifetime of the agent.
+func (c *collector) username(proc *procutil.Process) string {
+	if proc.Username != "" || len(proc.Uids) == 0 {
+		return proc.Username
+	}
+
+	uid := proc.Uids[0]
+	if name, ok := c.usernames[uid]; ok {
+		return name
+	}
+
+	name, err := lookupUsername(strconv.Itoa(int(uid)))
+	if err != nil {
+		log.Debugf("cannot get the name of user %d: %s", uid, err)
+		name = strconv.Itoa(int(uid))
+	}
+	c.usernames[uid] = name
+
+	return name
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package process
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestBuildEvents(t *testing.T) {
+	getListeningSockets = func([]int32) map[int32][]procutil.ListeningSocket {
+		return map[int32][]procutil.ListeningSocket{
+			42: {
+				{IP: net.ParseIP("::"), Port: 6379},
+				{IP: net.ParseIP("0.0.0.0"), Port: 6379},
+			},
+		}
+	}
+	containerIDForPID = func(pid int) (string, error) {
+		if pid == 43 {
+			return "foobarquux", nil
+		}
+		return "", nil
+	}
+	lookups := 0
+	lookupUsername = func(uid string) (string, error) {
+		lookups++
+		if uid == "999" {
+			return "redis", nil
+		}
+		return "", fmt.Errorf("unknown user %s", uid)
+	}
+	defer func() {
+		getListeningSockets = procutil.ListeningSockets
+		containerIDForPID = nil
+		lookupUsername = nil
+	}()
+
+	createTime := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := &collector{
+		seen:      make(map[workloadmeta.EntityID]struct{}),
+		usernames: make(map[int32]string),
+	}
+
+	events := c.buildEvents(map[int32]*procutil.Process{
+		42: {
+			Pid:     42,
+			Ppid:    1,
+			Name:    "redis-server",
+			Cmdline: []string{"redis-server", "*:6379"},
+			Exe:     "/usr/bin/redis-server",
+			Uids:    []int32{999, 999},
+			Stats:   &procutil.Stats{CreateTime: createTime.UnixNano() / int64(time.Millisecond)},
+		},
+		43: {
+			Pid:  43,
+			Ppid: 1,
+			Name: "nginx",
+			Uids: []int32{999},
+		},
+	})
+
+	assert.ElementsMatch(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID:  workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "42"},
+				PID:       42,
+				PPID:      1,
+				Name:      "redis-server",
+				Cmdline:   []string{"redis-server", "*:6379"},
+				Exe:       "/usr/bin/redis-server",
+				User:      "redis",
+				CreatedAt: createTime.Local(),
+				Ports: []workloadmeta.ProcessPort{
+					{IP: "0.0.0.0", Port: 6379},
+					{IP: "::", Port: 6379},
+				},
+			},
+		},
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID:    workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "43"},
+				PID:         43,
+				PPID:        1,
+				Name:        "nginx",
+				User:        "redis",
+				ContainerID: "foobarquux",
+			},
+		},
+	}, events)
+	assert.Equal(t, 1, lookups)
+
+	// the processes that exited are unset
+	events = c.buildEvents(map[int32]*procutil.Process{
+		43: {Pid: 43, Ppid: 1, Name: "nginx", Uids: []int32{1000}},
+	})
+
+	assert.ElementsMatch(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID:    workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "43"},
+				PID:         43,
+				PPID:        1,
+				Name:        "nginx",
+				User:        "1000",
+				ContainerID: "foobarquux",
+			},
+		},
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "42"},
+			},
+		},
+	}, events)
+}
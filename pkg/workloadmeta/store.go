@@ -8,6 +8,7 @@ package workloadmeta
 import (
 	"context"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -302,6 +303,41 @@ func (s *store) GetECSTask(id string) (*ECSTask, error) {
 	return entity.(*ECSTask), nil
 }
 
+// GetNomadAllocation implements Store#GetNomadAllocation
+func (s *store) GetNomadAllocation(id string) (*NomadAllocation, error) {
+	entity, err := s.getEntityByKind(KindNomadAllocation, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*NomadAllocation), nil
+}
+
+// GetProcess implements Store#GetProcess
+func (s *store) GetProcess(pid int) (*Process, error) {
+	entity, err := s.getEntityByKind(KindProcess, strconv.Itoa(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*Process), nil
+}
+
+// ListProcesses implements Store#ListProcesses
+func (s *store) ListProcesses() ([]*Process, error) {
+	entities, err := s.listEntitiesByKind(KindProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]*Process, 0, len(entities))
+	for _, entity := range entities {
+		processes = append(processes, entity.(*Process))
+	}
+
+	return processes, nil
+}
+
 // Notify implements Store#Notify
 func (s *store) Notify(events []CollectorEvent) {
 	if len(events) > 0 {
@@ -70,6 +70,18 @@ type Store interface {
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
 
+	// GetNomadAllocation returns metadata about a Nomad allocation.  It
+	// fetches the entity with kind KindNomadAllocation and the given ID.
+	GetNomadAllocation(id string) (*NomadAllocation, error)
+
+	// GetProcess returns metadata about a process.  It fetches the entity
+	// with kind KindProcess and the given PID.
+	GetProcess(pid int) (*Process, error)
+
+	// ListProcesses returns metadata about all known processes, equivalent
+	// to all entities with kind KindProcess.
+	ListProcesses() ([]*Process, error)
+
 	// Notify notifies the store with a slice of events.  It should only be
 	// used by workloadmeta collectors.
 	Notify(events []CollectorEvent)
@@ -83,9 +95,11 @@ type Kind string
 
 // Defined Kinds
 const (
-	KindContainer     Kind = "container"
-	KindKubernetesPod Kind = "kubernetes_pod"
-	KindECSTask       Kind = "ecs_task"
+	KindContainer       Kind = "container"
+	KindKubernetesPod   Kind = "kubernetes_pod"
+	KindECSTask         Kind = "ecs_task"
+	KindProcess         Kind = "process"
+	KindNomadAllocation Kind = "nomad_allocation"
 )
 
 // Source is the source name of an entity.
@@ -111,6 +125,10 @@ const (
 	// the central component of an orchestrator, or the Datadog Cluster
 	// Agent.  `kube_metadata` and `cloudfoundry` use this.
 	SourceClusterOrchestrator Source = "cluster_orchestrator"
+
+	// SourceHost represents entities detected on the host itself, without
+	// a container runtime or an orchestrator. `process` uses this.
+	SourceHost Source = "host"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -604,6 +622,170 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// NomadService is a service registered by the group or a task of a Nomad
+// allocation.
+type NomadService struct {
+	Name string
+	Task string
+	Meta map[string]string
+}
+
+// String returns a string representation of NomadService.
+func (s NomadService) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Name:", s.Name, "Task:", s.Task)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Meta:", mapToString(s.Meta))
+	}
+
+	return sb.String()
+}
+
+// NomadAllocation is an Entity representing a Nomad allocation.  Its ID is
+// the allocation ID, and its containers are named after their tasks.
+type NomadAllocation struct {
+	EntityID
+	EntityMeta
+	JobID      string
+	JobName    string
+	TaskGroup  string
+	Containers []OrchestratorContainer
+	Services   []NomadService
+}
+
+// GetID implements Entity#GetID.
+func (a NomadAllocation) GetID() EntityID {
+	return a.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (a *NomadAllocation) Merge(e Entity) error {
+	aa, ok := e.(*NomadAllocation)
+	if !ok {
+		return fmt.Errorf("cannot merge NomadAllocation with different kind %T", e)
+	}
+
+	return merge(a, aa)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (a NomadAllocation) DeepCopy() Entity {
+	cp := deepcopy.Copy(a).(NomadAllocation)
+	return &cp
+}
+
+// String implements Entity#String.
+func (a NomadAllocation) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, a.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, a.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Containers -----------")
+	for _, c := range a.Containers {
+		_, _ = fmt.Fprint(&sb, c.String(verbose))
+	}
+
+	_, _ = fmt.Fprintln(&sb, "----------- Allocation Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Job Name:", a.JobName)
+	_, _ = fmt.Fprintln(&sb, "Task Group:", a.TaskGroup)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Job ID:", a.JobID)
+	}
+
+	if len(a.Services) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Services -----------")
+		for _, service := range a.Services {
+			_, _ = fmt.Fprint(&sb, service.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &NomadAllocation{}
+
+// ProcessPort is a TCP port a process listens on.
+type ProcessPort struct {
+	IP   string
+	Port int
+}
+
+// String returns a string representation of ProcessPort.
+func (p ProcessPort) String(_ bool) string {
+	return fmt.Sprintln("IP:", p.IP, "Port:", p.Port)
+}
+
+// Process is an Entity representing a process running on the host.  Its ID
+// is the PID of the process.
+type Process struct {
+	EntityID
+	PID         int
+	PPID        int
+	Name        string
+	Cmdline     []string
+	Exe         string
+	User        string
+	CreatedAt   time.Time
+	ContainerID string
+	Ports       []ProcessPort
+}
+
+// GetID implements Entity#GetID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return merge(p, pp)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String implements Entity#String.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Name:", p.Name)
+	_, _ = fmt.Fprintln(&sb, "PPID:", p.PPID)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Cmdline:", strings.Join(p.Cmdline, " "))
+		_, _ = fmt.Fprintln(&sb, "Exe:", p.Exe)
+		_, _ = fmt.Fprintln(&sb, "User:", p.User)
+		_, _ = fmt.Fprintln(&sb, "Created At:", p.CreatedAt)
+	}
+
+	if len(p.Ports) > 0 && verbose {
+		_, _ = fmt.Fprintln(&sb, "----------- Ports -----------")
+		for _, port := range p.Ports {
+			_, _ = fmt.Fprint(&sb, port.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {